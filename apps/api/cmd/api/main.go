@@ -15,8 +15,12 @@ import (
 	"github.com/lukabrkovic/artemis/internal/config"
 	"github.com/lukabrkovic/artemis/internal/database"
 	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/jobs"
 	"github.com/lukabrkovic/artemis/internal/router"
+	"github.com/lukabrkovic/artemis/internal/service"
 	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/exchange"
+	"github.com/lukabrkovic/artemis/pkg/extract"
 	"github.com/lukabrkovic/artemis/pkg/logger"
 	"github.com/lukabrkovic/artemis/pkg/storage"
 	"github.com/lukabrkovic/artemis/pkg/token"
@@ -65,6 +69,16 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to create token maker")
 	}
 
+	exchangeRates, err := exchange.New(cfg.Exchange)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to load exchange rates")
+	}
+
+	receiptExtractor, err := extract.New(cfg.Receipts)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to create receipt extractor")
+	}
+
 	if cfg.Server.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -96,7 +110,106 @@ func main() {
 		MaxRequestSize:          cfg.Server.MaxRequestSize,
 		EventBus:                eventBus,
 		AuditLogger:             auditLogger,
+		ExchangeRates:           exchangeRates,
+	})
+
+	taskService := service.NewTaskService(st, eventBus, log)
+	recurringTaskService := service.NewRecurringTaskService(st, eventBus, log)
+	timesheetService := service.NewTimesheetService(st, eventBus, log)
+	invoiceService := service.NewInvoiceService(st, minioClient, eventBus, log)
+	recurringInvoiceService := service.NewRecurringInvoiceService(st, eventBus, log)
+	exchangeRateService := service.NewExchangeRateService(st, exchangeRates, log)
+	receiptExtractionService := service.NewReceiptExtractionService(st, minioClient, receiptExtractor, eventBus, log)
+	projectBudgetService := service.NewProjectBudgetService(st, exchangeRates, eventBus, log)
+
+	scheduler := jobs.NewScheduler(log)
+	scheduler.Add(jobs.Job{
+		Name:     "task_rank_rebalance",
+		Interval: 10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := taskService.RebalanceTaskRanks(ctx)
+			return err
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "task_recurrences",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := recurringTaskService.MaterializeDueOccurrences(ctx)
+			return err
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "timesheet_reminders",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			_, err := timesheetService.SendTimesheetReminders(ctx)
+			return err
+		},
 	})
+	scheduler.Add(jobs.Job{
+		Name:     "invoice_recurrences",
+		Interval: time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := recurringInvoiceService.GenerateDueInvoices(ctx)
+			return err
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "invoice_overdue",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			_, err := invoiceService.MarkOverdueInvoices(ctx)
+			return err
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "invoice_reminders",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			_, err := invoiceService.SendInvoiceReminders(ctx)
+			return err
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "exchange_rate_snapshots",
+		Interval: 10 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := exchangeRateService.SnapshotExchangeRates(ctx)
+			return err
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "receipt_extractions",
+		Interval: 5 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := receiptExtractionService.ExtractPendingReceipts(ctx)
+			return err
+		},
+	})
+	scheduler.Add(jobs.Job{
+		Name:     "project_budget_alerts",
+		Interval: 15 * time.Minute,
+		Run: func(ctx context.Context) error {
+			_, err := projectBudgetService.SendBudgetAlerts(ctx)
+			return err
+		},
+	})
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	scheduler.Start(jobsCtx)
+
+	// Receipts are read as they are uploaded; the receipt_extractions job
+	// catches up on any whose event is missed.
+	if eventBus != nil {
+		_, err := eventBus.QueueSubscribe(events.EventExpenseReceiptUploaded, "receipt_extraction", func(event *events.Event) error {
+			return receiptExtractionService.HandleReceiptUploaded(jobsCtx, event)
+		})
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to subscribe to receipt uploads, relying on the receipt_extractions job")
+		}
+	}
 
 	srv := &http.Server{
 		Addr:              ":" + cfg.Server.Port,
@@ -120,6 +233,7 @@ func main() {
 	<-quit
 
 	log.Info().Msg("shutting down server")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -16,6 +16,8 @@ type Config struct {
 	MinIO    MinIOConfig
 	NATS     NATSConfig
 	Token    TokenConfig
+	Exchange ExchangeRateConfig
+	Receipts ReceiptExtractionConfig
 }
 
 type ServerConfig struct {
@@ -72,6 +74,19 @@ type NATSConfig struct {
 	URL string
 }
 
+// ExchangeRateConfig picks the exchange rate provider. The static provider
+// reads File, a JSON table of daily rates; without a file it knows no rates.
+type ExchangeRateConfig struct {
+	Provider string
+	File     string
+}
+
+// ReceiptExtractionConfig picks how expense receipts are read. The heuristic
+// extractor reads text-based PDFs; "none" turns extraction off.
+type ReceiptExtractionConfig struct {
+	Extractor string
+}
+
 func Load() (*Config, error) {
 	viper.SetConfigName(".env")
 	viper.SetConfigType("env")
@@ -106,6 +121,8 @@ func Load() (*Config, error) {
 	viper.SetDefault("ACCESS_TOKEN_DURATION", "15m")
 	viper.SetDefault("REFRESH_TOKEN_DURATION", "168h")
 	viper.SetDefault("NATS_URL", "nats://localhost:4222")
+	viper.SetDefault("EXCHANGE_RATE_PROVIDER", "static")
+	viper.SetDefault("EXCHANGE_RATE_FILE", "")
 
 	_ = viper.ReadInConfig()
 
@@ -169,6 +186,13 @@ func Load() (*Config, error) {
 		NATS: NATSConfig{
 			URL: viper.GetString("NATS_URL"),
 		},
+		Exchange: ExchangeRateConfig{
+			Provider: viper.GetString("EXCHANGE_RATE_PROVIDER"),
+			File:     viper.GetString("EXCHANGE_RATE_FILE"),
+		},
+		Receipts: ReceiptExtractionConfig{
+			Extractor: viper.GetString("RECEIPT_EXTRACTOR"),
+		},
 	}
 
 	if err := cfg.Validate(); err != nil {
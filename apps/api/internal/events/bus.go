@@ -14,15 +14,48 @@ import (
 type EventType string
 
 const (
-	EventUserRegistered     EventType = "user.registered"
-	EventUserLoggedIn       EventType = "user.logged_in"
-	EventUserUpdated        EventType = "user.updated"
-	EventWorkspaceCreated   EventType = "workspace.created"
-	EventWorkspaceUpdated   EventType = "workspace.updated"
-	EventWorkspaceDeleted   EventType = "workspace.deleted"
-	EventMemberAdded        EventType = "member.added"
-	EventMemberRemoved      EventType = "member.removed"
-	EventEmailSendRequested EventType = "email.send_requested"
+	EventUserRegistered           EventType = "user.registered"
+	EventUserLoggedIn             EventType = "user.logged_in"
+	EventUserUpdated              EventType = "user.updated"
+	EventEmailVerificationSent    EventType = "user.email_verification_sent"
+	EventEmailVerified            EventType = "user.email_verified"
+	EventWorkspaceCreated         EventType = "workspace.created"
+	EventWorkspaceUpdated         EventType = "workspace.updated"
+	EventWorkspaceDeleted         EventType = "workspace.deleted"
+	EventWorkspaceSettingsUpdated EventType = "workspace.settings_updated"
+	EventMemberAdded              EventType = "member.added"
+	EventMemberRemoved            EventType = "member.removed"
+	EventMemberLeft               EventType = "member.left"
+	EventMemberDeactivated        EventType = "member.deactivated"
+	EventMemberReactivated        EventType = "member.reactivated"
+	EventMemberContentReassigned  EventType = "member.content_reassigned"
+	EventProjectCreated           EventType = "project.created"
+	EventProjectUpdated           EventType = "project.updated"
+	EventProjectStatusChanged     EventType = "project.status_changed"
+	EventProjectDeleted           EventType = "project.deleted"
+	EventProjectBudgetThreshold   EventType = "project.budget_threshold"
+	EventTaskAssigned             EventType = "task.assigned"
+	EventTaskStatusChanged        EventType = "task.status_changed"
+	EventCommentMentioned         EventType = "comment.mentioned"
+	EventTimesheetSubmitted       EventType = "timesheet.submitted"
+	EventTimesheetApproved        EventType = "timesheet.approved"
+	EventTimesheetRejected        EventType = "timesheet.rejected"
+	EventTimesheetReminderDue     EventType = "timesheet.reminder_due"
+	EventInvoiceSent              EventType = "invoice.sent"
+	EventInvoicePaid              EventType = "invoice.paid"
+	EventInvoiceCancelled         EventType = "invoice.cancelled"
+	EventPaymentRecorded          EventType = "payment.recorded"
+	EventPaymentRefunded          EventType = "payment.refunded"
+	EventInvoiceReminderDue       EventType = "invoice.reminder_due"
+	EventInvoiceViewed            EventType = "invoice.viewed"
+	EventRecurringInvoiceCreated  EventType = "invoice.recurring_created"
+	EventExpenseSubmitted         EventType = "expense.submitted"
+	EventExpenseApproved          EventType = "expense.approved"
+	EventExpenseRejected          EventType = "expense.rejected"
+	EventExpenseReimbursed        EventType = "expense.reimbursed"
+	EventExpenseReceiptUploaded   EventType = "expense.receipt_uploaded"
+	EventExpenseReceiptExtracted  EventType = "expense.receipt_extracted"
+	EventEmailSendRequested       EventType = "email.send_requested"
 )
 
 type Event struct {
@@ -92,7 +125,42 @@ func (b *Bus) Subscribe(eventType EventType, handler func(*Event) error) (*nats.
 	}
 
 	subject := fmt.Sprintf("artemis.%s", eventType)
-	sub, err := b.conn.Subscribe(subject, func(msg *nats.Msg) {
+	sub, err := b.conn.Subscribe(subject, b.handle(handler))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	b.logger.Info().
+		Str("subject", subject).
+		Msg("subscribed to events")
+
+	return sub, nil
+}
+
+// QueueSubscribe is like Subscribe, but each event is handled by only one of
+// the subscribers that share queue, so work is not repeated when several
+// instances run.
+func (b *Bus) QueueSubscribe(eventType EventType, queue string, handler func(*Event) error) (*nats.Subscription, error) {
+	if b == nil || b.conn == nil {
+		return nil, fmt.Errorf("event bus not connected")
+	}
+
+	subject := fmt.Sprintf("artemis.%s", eventType)
+	sub, err := b.conn.QueueSubscribe(subject, queue, b.handle(handler))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	b.logger.Info().
+		Str("subject", subject).
+		Str("queue", queue).
+		Msg("subscribed to events")
+
+	return sub, nil
+}
+
+func (b *Bus) handle(handler func(*Event) error) nats.MsgHandler {
+	return func(msg *nats.Msg) {
 		var event Event
 		if err := json.Unmarshal(msg.Data, &event); err != nil {
 			b.logger.Error().Err(err).Msg("failed to unmarshal event")
@@ -106,15 +174,5 @@ func (b *Bus) Subscribe(eventType EventType, handler func(*Event) error) (*nats.
 				Str("type", string(event.Type)).
 				Msg("event handler failed")
 		}
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to subscribe: %w", err)
 	}
-
-	b.logger.Info().
-		Str("subject", subject).
-		Msg("subscribed to events")
-
-	return sub, nil
 }
@@ -40,6 +40,10 @@ type logoutRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+type verifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
 type authResponse struct {
 	User                  *store.User `json:"user"`
 	AccessToken           string      `json:"access_token"`
@@ -207,6 +211,78 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
 }
 
+// SendEmailVerification godoc
+// @Summary      Send email verification
+// @Description  Email the current user a link that verifies their email address. The link expires after 24 hours.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      202  {object}  map[string]string
+// @Failure      401  {object}  apperr.AppError
+// @Failure      409  {object}  apperr.AppError
+// @Failure      422  {object}  apperr.AppError
+// @Failure      429  {object}  apperr.AppError
+// @Failure      500  {object}  apperr.AppError
+// @Router       /auth/email-verification [post]
+func (h *AuthHandler) SendEmailVerification(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	if err := h.service.SendEmailVerification(c.Request.Context(), userId); err != nil {
+		switch {
+		case errors.Is(err, service.ErrEmailAlreadyVerified):
+			c.Error(apperr.Conflict(err.Error()))
+		case errors.Is(err, service.ErrNoEmail):
+			c.Error(apperr.New(http.StatusUnprocessableEntity, err.Error()))
+		case errors.Is(err, store.ErrUserNotFound):
+			c.Error(apperr.Unauthorized("user not found"))
+		default:
+			c.Error(apperr.Internal(err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "verification email sent"})
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email address
+// @Description  Verify the email address a verification link was sent to
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body verifyEmailRequest true "Verify Email Request"
+// @Success      200  {object}  store.User
+// @Failure      400  {object}  apperr.AppError
+// @Failure      404  {object}  apperr.AppError
+// @Failure      429  {object}  apperr.AppError
+// @Failure      500  {object}  apperr.AppError
+// @Router       /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req verifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	user, err := h.service.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailVerificationInvalid) {
+			c.Error(apperr.NotFound("verification link"))
+			return
+		}
+		c.Error(apperr.Internal(err))
+		return
+	}
+
+	c.Set("user_id", user.ID)
+
+	c.JSON(http.StatusOK, user)
+}
+
 func handleValidationError(c *gin.Context, err error) {
 	var validationErrs v10.ValidationErrors
 	if errors.As(err, &validationErrs) {
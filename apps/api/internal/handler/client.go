@@ -0,0 +1,573 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type ClientHandler struct {
+	service service.Client
+}
+
+func NewClientHandler(service service.Client) *ClientHandler {
+	return &ClientHandler{service: service}
+}
+
+type createClientRequest struct {
+	Name     string     `json:"name" binding:"required" example:"Acme Corp"`
+	Email    *string    `json:"email" example:"billing@acme.com"`
+	Phone    *string    `json:"phone"`
+	Website  *string    `json:"website" example:"https://acme.com"`
+	Address  *string    `json:"address"`
+	Notes    *string    `json:"notes"`
+	Status   *string    `json:"status" example:"lead"`
+	Currency *string    `json:"currency" example:"USD"`
+	Tags     []string   `json:"tags"`
+	OwnerID  *uuid.UUID `json:"owner_id"`
+}
+
+type updateClientRequest struct {
+	Name     *string  `json:"name"`
+	Email    *string  `json:"email"`
+	Phone    *string  `json:"phone"`
+	Website  *string  `json:"website"`
+	Address  *string  `json:"address"`
+	Notes    *string  `json:"notes"`
+	Currency *string  `json:"currency" example:"USD"`
+	Tags     []string `json:"tags"`
+	OwnerID  *string  `json:"owner_id"`
+}
+
+type updateClientStatusRequest struct {
+	Status string  `json:"status" binding:"required" example:"active"`
+	Note   *string `json:"note" example:"Signed the retainer"`
+}
+
+type createClientContactRequest struct {
+	Name      string  `json:"name" binding:"required" example:"Jane Doe"`
+	Email     *string `json:"email" example:"jane@acme.com"`
+	Phone     *string `json:"phone"`
+	Title     *string `json:"title" example:"Head of Marketing"`
+	IsPrimary bool    `json:"is_primary"`
+}
+
+type updateClientContactRequest struct {
+	Name      *string `json:"name"`
+	Email     *string `json:"email"`
+	Phone     *string `json:"phone"`
+	Title     *string `json:"title"`
+	IsPrimary *bool   `json:"is_primary"`
+}
+
+// CreateClient godoc
+// @Summary      Create client
+// @Description  Create a client in the workspace. Clients start as leads, are owned by the caller unless owner_id is given, and are billed in the workspace currency by default.
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string               true  "Workspace ID"
+// @Param        request  body      createClientRequest  true  "Create Client Request"
+// @Success      201      {object}  store.Client
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients [post]
+func (h *ClientHandler) CreateClient(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req createClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateClientInput{
+		Name:     req.Name,
+		Email:    req.Email,
+		Phone:    req.Phone,
+		Website:  req.Website,
+		Address:  req.Address,
+		Notes:    req.Notes,
+		Status:   req.Status,
+		Currency: req.Currency,
+		Tags:     req.Tags,
+		OwnerID:  req.OwnerID,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	client, err := h.service.CreateClient(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, client)
+}
+
+// ListClients godoc
+// @Summary      List clients
+// @Description  List the clients of the workspace with their project counts and revenue roll-ups, with filtering, sorting, and pagination
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id                   path      string  true   "Workspace ID"
+// @Param        limit                query     int     false  "Limit (default 20, max 100)"
+// @Param        offset               query     int     false  "Offset (default 0)"
+// @Param        sort_by              query     string  false  "Sort by: name, status, total_revenue, outstanding, active_projects, created_at, updated_at (default: created_at)"
+// @Param        order                query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search               query     string  false  "Search in client name, email, or contact names"
+// @Param        status               query     string  false  "Filter by comma-separated statuses: lead, active, inactive, churned"
+// @Param        tag                  query     string  false  "Filter by tag"
+// @Param        owner_id             query     string  false  "Filter by owner user ID or 'me'"
+// @Param        has_outstanding      query     bool    false  "Filter by whether the client has an outstanding balance"
+// @Param        has_active_projects  query     bool    false  "Filter by whether the client has active projects"
+// @Success      200                  {object}  store.PaginatedClientsResponse
+// @Failure      400                  {object}  apperr.AppError
+// @Failure      401                  {object}  apperr.AppError
+// @Failure      403                  {object}  apperr.AppError
+// @Failure      500                  {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients [get]
+func (h *ClientHandler) ListClients(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+	bindFilters(c, &filters, "status", "tag", "owner_id", "has_outstanding", "has_active_projects")
+
+	clients, err := h.service.ListClients(c.Request.Context(), userId, workspaceId, filters)
+	if err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, clients)
+}
+
+// GetClient godoc
+// @Summary      Get client
+// @Description  Get a client of the workspace with its contacts
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string  true  "Workspace ID"
+// @Param        client_id  path      string  true  "Client ID"
+// @Success      200        {object}  store.Client
+// @Failure      400        {object}  apperr.AppError
+// @Failure      401        {object}  apperr.AppError
+// @Failure      403        {object}  apperr.AppError
+// @Failure      404        {object}  apperr.AppError
+// @Failure      500        {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients/{client_id} [get]
+func (h *ClientHandler) GetClient(c *gin.Context) {
+	userId, workspaceId, clientId, ok := parseClientParams(c)
+	if !ok {
+		return
+	}
+
+	client, err := h.service.GetClient(c.Request.Context(), userId, workspaceId, clientId)
+	if err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// UpdateClient godoc
+// @Summary      Update client
+// @Description  Update a client. An empty owner_id clears the owner. The status is changed through the status endpoint.
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string               true  "Workspace ID"
+// @Param        client_id  path      string               true  "Client ID"
+// @Param        request    body      updateClientRequest  true  "Update Client Request"
+// @Success      200        {object}  store.Client
+// @Failure      400        {object}  apperr.AppError
+// @Failure      401        {object}  apperr.AppError
+// @Failure      403        {object}  apperr.AppError
+// @Failure      404        {object}  apperr.AppError
+// @Failure      500        {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients/{client_id} [patch]
+func (h *ClientHandler) UpdateClient(c *gin.Context) {
+	userId, workspaceId, clientId, ok := parseClientParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateClientInput{
+		Name:     req.Name,
+		Email:    req.Email,
+		Phone:    req.Phone,
+		Website:  req.Website,
+		Address:  req.Address,
+		Notes:    req.Notes,
+		Currency: req.Currency,
+		Tags:     req.Tags,
+		OwnerID:  req.OwnerID,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	client, err := h.service.UpdateClient(c.Request.Context(), userId, workspaceId, clientId, serviceInput)
+	if err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// UpdateClientStatus godoc
+// @Summary      Update client status
+// @Description  Move a client along the pipeline: lead to active or churned, active to inactive or churned, inactive back to active or churned, and churned back to lead or active. Every change is recorded in the status history.
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string                     true  "Workspace ID"
+// @Param        client_id  path      string                     true  "Client ID"
+// @Param        request    body      updateClientStatusRequest  true  "Update Client Status Request"
+// @Success      200        {object}  store.Client
+// @Failure      400        {object}  apperr.AppError
+// @Failure      401        {object}  apperr.AppError
+// @Failure      403        {object}  apperr.AppError
+// @Failure      404        {object}  apperr.AppError
+// @Failure      409        {object}  apperr.AppError
+// @Failure      500        {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients/{client_id}/status [post]
+func (h *ClientHandler) UpdateClientStatus(c *gin.Context) {
+	userId, workspaceId, clientId, ok := parseClientParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateClientStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateClientStatusInput{
+		Status: req.Status,
+		Note:   req.Note,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	client, err := h.service.UpdateClientStatus(c.Request.Context(), userId, workspaceId, clientId, serviceInput)
+	if err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, client)
+}
+
+// DeleteClient godoc
+// @Summary      Delete client
+// @Description  Delete a client. Requires a workspace admin.
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string  true  "Workspace ID"
+// @Param        client_id  path      string  true  "Client ID"
+// @Success      200        {object}  map[string]string
+// @Failure      400        {object}  apperr.AppError
+// @Failure      401        {object}  apperr.AppError
+// @Failure      403        {object}  apperr.AppError
+// @Failure      404        {object}  apperr.AppError
+// @Failure      500        {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients/{client_id} [delete]
+func (h *ClientHandler) DeleteClient(c *gin.Context) {
+	userId, workspaceId, clientId, ok := parseClientParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteClient(c.Request.Context(), userId, workspaceId, clientId); err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "client deleted"})
+}
+
+// ListClientStatusHistory godoc
+// @Summary      List client status history
+// @Description  List the status changes of a client, newest first
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string  true  "Workspace ID"
+// @Param        client_id  path      string  true  "Client ID"
+// @Success      200        {array}   store.ClientStatusChange
+// @Failure      400        {object}  apperr.AppError
+// @Failure      401        {object}  apperr.AppError
+// @Failure      403        {object}  apperr.AppError
+// @Failure      404        {object}  apperr.AppError
+// @Failure      500        {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients/{client_id}/status-history [get]
+func (h *ClientHandler) ListClientStatusHistory(c *gin.Context) {
+	userId, workspaceId, clientId, ok := parseClientParams(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.service.ListClientStatusHistory(c.Request.Context(), userId, workspaceId, clientId)
+	if err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// ListClientContacts godoc
+// @Summary      List client contacts
+// @Description  List the contacts of a client, primary contact first
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string  true  "Workspace ID"
+// @Param        client_id  path      string  true  "Client ID"
+// @Success      200        {array}   store.ClientContact
+// @Failure      400        {object}  apperr.AppError
+// @Failure      401        {object}  apperr.AppError
+// @Failure      403        {object}  apperr.AppError
+// @Failure      404        {object}  apperr.AppError
+// @Failure      500        {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients/{client_id}/contacts [get]
+func (h *ClientHandler) ListClientContacts(c *gin.Context) {
+	userId, workspaceId, clientId, ok := parseClientParams(c)
+	if !ok {
+		return
+	}
+
+	contacts, err := h.service.ListClientContacts(c.Request.Context(), userId, workspaceId, clientId)
+	if err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, contacts)
+}
+
+// CreateClientContact godoc
+// @Summary      Create client contact
+// @Description  Add a contact person to a client. Making it the primary contact demotes the previous one.
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string                      true  "Workspace ID"
+// @Param        client_id  path      string                      true  "Client ID"
+// @Param        request    body      createClientContactRequest  true  "Create Client Contact Request"
+// @Success      201        {object}  store.ClientContact
+// @Failure      400        {object}  apperr.AppError
+// @Failure      401        {object}  apperr.AppError
+// @Failure      403        {object}  apperr.AppError
+// @Failure      404        {object}  apperr.AppError
+// @Failure      500        {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients/{client_id}/contacts [post]
+func (h *ClientHandler) CreateClientContact(c *gin.Context) {
+	userId, workspaceId, clientId, ok := parseClientParams(c)
+	if !ok {
+		return
+	}
+
+	var req createClientContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateClientContactInput{
+		Name:      req.Name,
+		Email:     req.Email,
+		Phone:     req.Phone,
+		Title:     req.Title,
+		IsPrimary: req.IsPrimary,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	contact, err := h.service.CreateClientContact(c.Request.Context(), userId, workspaceId, clientId, serviceInput)
+	if err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, contact)
+}
+
+// UpdateClientContact godoc
+// @Summary      Update client contact
+// @Description  Update a contact of a client
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                      true  "Workspace ID"
+// @Param        client_id   path      string                      true  "Client ID"
+// @Param        contact_id  path      string                      true  "Contact ID"
+// @Param        request     body      updateClientContactRequest  true  "Update Client Contact Request"
+// @Success      200         {object}  store.ClientContact
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients/{client_id}/contacts/{contact_id} [patch]
+func (h *ClientHandler) UpdateClientContact(c *gin.Context) {
+	userId, workspaceId, clientId, contactId, ok := parseClientContactParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateClientContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateClientContactInput{
+		Name:      req.Name,
+		Email:     req.Email,
+		Phone:     req.Phone,
+		Title:     req.Title,
+		IsPrimary: req.IsPrimary,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	contact, err := h.service.UpdateClientContact(c.Request.Context(), userId, workspaceId, clientId, contactId, serviceInput)
+	if err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, contact)
+}
+
+// DeleteClientContact godoc
+// @Summary      Delete client contact
+// @Description  Remove a contact from a client
+// @Tags         client
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        client_id   path      string  true  "Client ID"
+// @Param        contact_id  path      string  true  "Contact ID"
+// @Success      200         {object}  map[string]string
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients/{client_id}/contacts/{contact_id} [delete]
+func (h *ClientHandler) DeleteClientContact(c *gin.Context) {
+	userId, workspaceId, clientId, contactId, ok := parseClientContactParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteClientContact(c.Request.Context(), userId, workspaceId, clientId, contactId); err != nil {
+		handleClientError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "contact deleted"})
+}
+
+func parseClientParams(c *gin.Context) (userId, workspaceId, clientId uuid.UUID, ok bool) {
+	userId, workspaceId, ok = parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	clientId, err := uuid.Parse(c.Param("client_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid client id"))
+		return userId, workspaceId, clientId, false
+	}
+
+	return userId, workspaceId, clientId, true
+}
+
+func parseClientContactParams(c *gin.Context) (userId, workspaceId, clientId, contactId uuid.UUID, ok bool) {
+	userId, workspaceId, clientId, ok = parseClientParams(c)
+	if !ok {
+		return
+	}
+
+	contactId, err := uuid.Parse(c.Param("contact_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid contact id"))
+		return userId, workspaceId, clientId, contactId, false
+	}
+
+	return userId, workspaceId, clientId, contactId, true
+}
+
+func handleClientError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrClientNotFound):
+		c.Error(apperr.NotFound("client"))
+	case errors.Is(err, service.ErrClientContactNotFound):
+		c.Error(apperr.NotFound("contact"))
+	case errors.Is(err, service.ErrInvalidClientStatusTransition):
+		c.Error(apperr.Conflict(err.Error()))
+	case errors.Is(err, service.ErrNotWorkspaceMember),
+		errors.Is(err, service.ErrInvalidFilter):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+// CommentHandler serves the comment threads of every commentable resource.
+// The resource is taken from the route parameters.
+type CommentHandler struct {
+	service service.Comment
+}
+
+func NewCommentHandler(service service.Comment) *CommentHandler {
+	return &CommentHandler{service: service}
+}
+
+type createCommentRequest struct {
+	Body     string     `json:"body" binding:"required" example:"Looks good, @jane please review the copy."`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+type updateCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CreateComment godoc
+// @Summary      Create comment
+// @Description  Add a markdown comment or, with parent_id, a reply. Mentions are written as @ followed by a member's email address or the part before the @, and notify the mentioned members.
+// @Tags         comment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                true  "Workspace ID"
+// @Param        project_id  path      string                true  "Project ID"
+// @Param        task_id     path      string                true  "Task ID"
+// @Param        request     body      createCommentRequest  true  "Create Comment Request"
+// @Success      201         {object}  store.Comment
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/comments [post]
+// @Router       /workspaces/{id}/projects/{project_id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userId, workspaceId, target, ok := parseCommentParams(c)
+	if !ok {
+		return
+	}
+
+	var req createCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateCommentInput{
+		Body:     req.Body,
+		ParentID: req.ParentID,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	comment, err := h.service.CreateComment(c.Request.Context(), userId, workspaceId, target, serviceInput)
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// ListComments godoc
+// @Summary      List comments
+// @Description  List the top-level comments of a resource with their replies
+// @Tags         comment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true   "Workspace ID"
+// @Param        project_id  path      string  true   "Project ID"
+// @Param        task_id     path      string  true   "Task ID"
+// @Param        limit       query     int     false  "Limit (default 20, max 100)"
+// @Param        offset      query     int     false  "Offset (default 0)"
+// @Param        sort_by     query     string  false  "Sort by: created_at, updated_at (default: created_at)"
+// @Param        order       query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search      query     string  false  "Search in comment body"
+// @Success      200         {object}  store.PaginatedCommentsResponse
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/comments [get]
+// @Router       /workspaces/{id}/projects/{project_id}/comments [get]
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	userId, workspaceId, target, ok := parseCommentParams(c)
+	if !ok {
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+
+	comments, err := h.service.ListComments(c.Request.Context(), userId, workspaceId, target, filters)
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// UpdateComment godoc
+// @Summary      Update comment
+// @Description  Edit a comment. Only the author can edit it; the previous body is kept in the edit history.
+// @Tags         comment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                true  "Workspace ID"
+// @Param        project_id  path      string                true  "Project ID"
+// @Param        task_id     path      string                true  "Task ID"
+// @Param        comment_id  path      string                true  "Comment ID"
+// @Param        request     body      updateCommentRequest  true  "Update Comment Request"
+// @Success      200         {object}  store.Comment
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/comments/{comment_id} [patch]
+// @Router       /workspaces/{id}/projects/{project_id}/comments/{comment_id} [patch]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userId, workspaceId, target, ok := parseCommentParams(c)
+	if !ok {
+		return
+	}
+
+	commentId, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid comment id"))
+		return
+	}
+
+	var req updateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateCommentInput{
+		Body: req.Body,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	comment, err := h.service.UpdateComment(c.Request.Context(), userId, workspaceId, target, commentId, serviceInput)
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment godoc
+// @Summary      Delete comment
+// @Description  Delete a comment and its replies. Authors can delete their own comments, workspace admins any comment.
+// @Tags         comment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Param        task_id     path      string  true  "Task ID"
+// @Param        comment_id  path      string  true  "Comment ID"
+// @Success      200         {object}  map[string]string
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/comments/{comment_id} [delete]
+// @Router       /workspaces/{id}/projects/{project_id}/comments/{comment_id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userId, workspaceId, target, ok := parseCommentParams(c)
+	if !ok {
+		return
+	}
+
+	commentId, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid comment id"))
+		return
+	}
+
+	if err := h.service.DeleteComment(c.Request.Context(), userId, workspaceId, target, commentId); err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "comment deleted"})
+}
+
+// ListCommentRevisions godoc
+// @Summary      List comment edit history
+// @Description  List the previous bodies of a comment, newest first
+// @Tags         comment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Param        task_id     path      string  true  "Task ID"
+// @Param        comment_id  path      string  true  "Comment ID"
+// @Success      200         {array}   store.CommentRevision
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/comments/{comment_id}/history [get]
+// @Router       /workspaces/{id}/projects/{project_id}/comments/{comment_id}/history [get]
+func (h *CommentHandler) ListCommentRevisions(c *gin.Context) {
+	userId, workspaceId, target, ok := parseCommentParams(c)
+	if !ok {
+		return
+	}
+
+	commentId, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid comment id"))
+		return
+	}
+
+	revisions, err := h.service.ListCommentRevisions(c.Request.Context(), userId, workspaceId, target, commentId)
+	if err != nil {
+		handleCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// parseCommentParams resolves the commented resource from the most specific
+// resource parameter present in the route.
+func parseCommentParams(c *gin.Context) (userId, workspaceId uuid.UUID, target service.CommentTarget, ok bool) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	target = service.CommentTarget{Type: service.CommentOnProject, ID: projectId}
+	if c.Param("task_id") != "" {
+		taskId, err := uuid.Parse(c.Param("task_id"))
+		if err != nil {
+			c.Error(apperr.BadRequest("invalid task id"))
+			return userId, workspaceId, target, false
+		}
+		target = service.CommentTarget{Type: service.CommentOnTask, ID: taskId, ProjectID: projectId}
+	}
+
+	return userId, workspaceId, target, true
+}
+
+func handleCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.Error(apperr.NotFound("project"))
+	case errors.Is(err, service.ErrTaskNotFound):
+		c.Error(apperr.NotFound("task"))
+	case errors.Is(err, service.ErrCommentNotFound):
+		c.Error(apperr.NotFound("comment"))
+	case errors.Is(err, service.ErrInvalidCommentParent):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type ExchangeRateHandler struct {
+	service service.ExchangeRate
+}
+
+func NewExchangeRateHandler(service service.ExchangeRate) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: service}
+}
+
+type exchangeRateQuery struct {
+	From string  `form:"from"`
+	To   string  `form:"to"`
+	Date *string `form:"date"`
+}
+
+// GetExchangeRate godoc
+// @Summary      Get exchange rate
+// @Description  Get how many units of one currency a unit of another bought on a date, today in the workspace timezone by default. The rate is stored the first time it is asked for, so it stays the same when asked again.
+// @Tags         exchange-rate
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string  true   "Workspace ID"
+// @Param        from  query     string  true   "Currency converted from, e.g. USD"
+// @Param        to    query     string  true   "Currency converted to, e.g. EUR"
+// @Param        date  query     string  false  "Date of the rate (YYYY-MM-DD)"
+// @Success      200   {object}  store.ExchangeRate
+// @Failure      400   {object}  apperr.AppError
+// @Failure      401   {object}  apperr.AppError
+// @Failure      403   {object}  apperr.AppError
+// @Failure      404   {object}  apperr.AppError
+// @Failure      422   {object}  apperr.AppError
+// @Failure      500   {object}  apperr.AppError
+// @Router       /workspaces/{id}/exchange-rates [get]
+func (h *ExchangeRateHandler) GetExchangeRate(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var query exchangeRateQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.ExchangeRateInput{From: query.From, To: query.To, Date: query.Date}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	rate, err := h.service.GetExchangeRate(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleExchangeRateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+func handleExchangeRateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrSameCurrency):
+		c.Error(apperr.BadRequest(err.Error()))
+	case errors.Is(err, service.ErrExchangeRateUnavailable):
+		c.Error(apperr.New(http.StatusUnprocessableEntity, err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
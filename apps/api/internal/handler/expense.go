@@ -0,0 +1,863 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+const maxReceiptSize = 10 * 1024 * 1024
+
+var receiptContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"image/heic":      true,
+}
+
+type ExpenseHandler struct {
+	service service.Expense
+}
+
+func NewExpenseHandler(service service.Expense) *ExpenseHandler {
+	return &ExpenseHandler{service: service}
+}
+
+type expenseCategoryRequest struct {
+	Name        string  `json:"name" binding:"required" example:"Travel"`
+	Description *string `json:"description"`
+	Color       *string `json:"color" example:"#8b5cf6"`
+}
+
+type updateExpenseCategoryRequest struct {
+	Name        *string `json:"name" example:"Travel"`
+	Description *string `json:"description"`
+	Color       *string `json:"color" example:"#8b5cf6"`
+}
+
+type createExpenseRequest struct {
+	CategoryID    *uuid.UUID `json:"category_id"`
+	ProjectID     *uuid.UUID `json:"project_id"`
+	ClientID      *uuid.UUID `json:"client_id"`
+	Merchant      string     `json:"merchant" binding:"required" example:"Delta Airlines"`
+	Description   *string    `json:"description" example:"Flight to SF - client workshop"`
+	Amount        int64      `json:"amount" binding:"required" example:"45000"`
+	Currency      *string    `json:"currency" example:"USD"`
+	SpentOn       string     `json:"spent_on" binding:"required" example:"2026-01-25"`
+	PaymentMethod string     `json:"payment_method" binding:"required" example:"personal_card"`
+	Reimbursable  *bool      `json:"reimbursable"`
+	Billable      *bool      `json:"billable"`
+	Notes         *string    `json:"notes"`
+}
+
+type updateExpenseRequest struct {
+	CategoryID    *string `json:"category_id"`
+	ProjectID     *string `json:"project_id"`
+	ClientID      *string `json:"client_id"`
+	Merchant      *string `json:"merchant" example:"Delta Airlines"`
+	Description   *string `json:"description"`
+	Amount        *int64  `json:"amount" example:"45000"`
+	Currency      *string `json:"currency" example:"USD"`
+	SpentOn       *string `json:"spent_on" example:"2026-01-25"`
+	PaymentMethod *string `json:"payment_method" example:"company_card"`
+	Reimbursable  *bool   `json:"reimbursable"`
+	Billable      *bool   `json:"billable"`
+	Notes         *string `json:"notes"`
+}
+
+type rejectExpenseRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Missing receipt"`
+}
+
+type reimburseExpenseRequest struct {
+	Reference    *string    `json:"reference" example:"SEPA-2026-0142"`
+	ReimbursedAt *time.Time `json:"reimbursed_at"`
+}
+
+type confirmReceiptSuggestionsRequest struct {
+	Fields []string `json:"fields" example:"merchant,amount"`
+}
+
+// CreateExpenseCategory godoc
+// @Summary      Create expense category
+// @Description  Add an expense category to the workspace. Names are unique per workspace, ignoring case. Requires a workspace admin.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                  true  "Workspace ID"
+// @Param        request  body      expenseCategoryRequest  true  "Expense Category Request"
+// @Success      201      {object}  store.ExpenseCategory
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/expense-categories [post]
+func (h *ExpenseHandler) CreateExpenseCategory(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req expenseCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.ExpenseCategoryInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Color:       req.Color,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	category, err := h.service.CreateExpenseCategory(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// ListExpenseCategories godoc
+// @Summary      List expense categories
+// @Description  List the expense categories of the workspace by name, with how many expenses each has.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      200  {array}   store.ExpenseCategory
+// @Failure      400  {object}  apperr.AppError
+// @Failure      401  {object}  apperr.AppError
+// @Failure      403  {object}  apperr.AppError
+// @Failure      500  {object}  apperr.AppError
+// @Router       /workspaces/{id}/expense-categories [get]
+func (h *ExpenseHandler) ListExpenseCategories(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	categories, err := h.service.ListExpenseCategories(c.Request.Context(), userId, workspaceId)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// UpdateExpenseCategory godoc
+// @Summary      Update expense category
+// @Description  Change the fields that are set. Requires a workspace admin.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id           path      string                        true  "Workspace ID"
+// @Param        category_id  path      string                        true  "Expense category ID"
+// @Param        request      body      updateExpenseCategoryRequest  true  "Update Expense Category Request"
+// @Success      200          {object}  store.ExpenseCategory
+// @Failure      400          {object}  apperr.AppError
+// @Failure      401          {object}  apperr.AppError
+// @Failure      403          {object}  apperr.AppError
+// @Failure      404          {object}  apperr.AppError
+// @Failure      409          {object}  apperr.AppError
+// @Failure      500          {object}  apperr.AppError
+// @Router       /workspaces/{id}/expense-categories/{category_id} [patch]
+func (h *ExpenseHandler) UpdateExpenseCategory(c *gin.Context) {
+	userId, workspaceId, categoryId, ok := parseExpenseCategoryParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateExpenseCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateExpenseCategoryInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Color:       req.Color,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	category, err := h.service.UpdateExpenseCategory(c.Request.Context(), userId, workspaceId, categoryId, serviceInput)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteExpenseCategory godoc
+// @Summary      Delete expense category
+// @Description  Remove an expense category. Its expenses are kept without a category. Requires a workspace admin.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id           path      string  true  "Workspace ID"
+// @Param        category_id  path      string  true  "Expense category ID"
+// @Success      200          {object}  map[string]string
+// @Failure      400          {object}  apperr.AppError
+// @Failure      401          {object}  apperr.AppError
+// @Failure      403          {object}  apperr.AppError
+// @Failure      404          {object}  apperr.AppError
+// @Failure      500          {object}  apperr.AppError
+// @Router       /workspaces/{id}/expense-categories/{category_id} [delete]
+func (h *ExpenseHandler) DeleteExpenseCategory(c *gin.Context) {
+	userId, workspaceId, categoryId, ok := parseExpenseCategoryParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteExpenseCategory(c.Request.Context(), userId, workspaceId, categoryId); err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "expense category deleted"})
+}
+
+// CreateExpense godoc
+// @Summary      Create expense
+// @Description  Submit an expense for review. The amount is in minor units of the currency, which defaults to the workspace currency. payment_method is one of personal_card, company_card, cash, bank_transfer or other; expenses paid with a company card are not reimbursable unless reimbursable says otherwise. Billable expenses are charged to client_id, or to the project's client.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                true  "Workspace ID"
+// @Param        request  body      createExpenseRequest  true  "Create Expense Request"
+// @Success      201      {object}  store.Expense
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses [post]
+func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req createExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateExpenseInput{
+		CategoryID:    req.CategoryID,
+		ProjectID:     req.ProjectID,
+		ClientID:      req.ClientID,
+		Merchant:      req.Merchant,
+		Description:   req.Description,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		SpentOn:       req.SpentOn,
+		PaymentMethod: req.PaymentMethod,
+		Reimbursable:  req.Reimbursable,
+		Billable:      req.Billable,
+		Notes:         req.Notes,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	expense, err := h.service.CreateExpense(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, expense)
+}
+
+// ListExpenses godoc
+// @Summary      List expenses
+// @Description  List expenses with filtering, sorting, and pagination. Members see their own expenses; workspace admins see everyone's.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id            path      string  true   "Workspace ID"
+// @Param        limit         query     int     false  "Limit (default 20, max 100)"
+// @Param        offset        query     int     false  "Offset (default 0)"
+// @Param        sort_by       query     string  false  "Sort by: spent_on, amount, merchant, created_at, updated_at (default: created_at)"
+// @Param        order         query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search        query     string  false  "Search in merchant or description"
+// @Param        user_id       query     string  false  "Filter by submitter ID or 'me'"
+// @Param        status        query     string  false  "Filter by status: pending, approved, rejected, reimbursed (comma-separated)"
+// @Param        category_id   query     string  false  "Filter by category ID"
+// @Param        project_id    query     string  false  "Filter by project ID"
+// @Param        client_id     query     string  false  "Filter by client ID"
+// @Param        currency      query     string  false  "Filter by currency"
+// @Param        billable      query     bool    false  "Filter by billable"
+// @Param        billed        query     bool    false  "Filter by billed"
+// @Param        reimbursable  query     bool    false  "Filter by reimbursable"
+// @Param        from          query     string  false  "Expenses spent on or after this date (YYYY-MM-DD)"
+// @Param        to            query     string  false  "Expenses spent on or before this date (YYYY-MM-DD)"
+// @Success      200           {object}  store.PaginatedExpensesResponse
+// @Failure      400           {object}  apperr.AppError
+// @Failure      401           {object}  apperr.AppError
+// @Failure      403           {object}  apperr.AppError
+// @Failure      500           {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses [get]
+func (h *ExpenseHandler) ListExpenses(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+	bindFilters(c, &filters, "user_id", "status", "category_id", "project_id", "client_id", "currency", "billable", "billed", "reimbursable", "from", "to")
+
+	expenses, err := h.service.ListExpenses(c.Request.Context(), userId, workspaceId, filters)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expenses)
+}
+
+// GetExpense godoc
+// @Summary      Get expense
+// @Description  Get an expense by ID. Members can only read their own expenses.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        expense_id  path      string  true  "Expense ID"
+// @Success      200         {object}  store.Expense
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id} [get]
+func (h *ExpenseHandler) GetExpense(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	expense, err := h.service.GetExpense(c.Request.Context(), userId, workspaceId, expenseId)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// UpdateExpense godoc
+// @Summary      Update expense
+// @Description  Change the fields that are set; an empty category_id, project_id or client_id clears it. The submitter or a workspace admin can change an expense until it is approved. Changing a rejected expense submits it for review again.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                true  "Workspace ID"
+// @Param        expense_id  path      string                true  "Expense ID"
+// @Param        request     body      updateExpenseRequest  true  "Update Expense Request"
+// @Success      200         {object}  store.Expense
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id} [patch]
+func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateExpenseInput{
+		CategoryID:    req.CategoryID,
+		ProjectID:     req.ProjectID,
+		ClientID:      req.ClientID,
+		Merchant:      req.Merchant,
+		Description:   req.Description,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		SpentOn:       req.SpentOn,
+		PaymentMethod: req.PaymentMethod,
+		Reimbursable:  req.Reimbursable,
+		Billable:      req.Billable,
+		Notes:         req.Notes,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	expense, err := h.service.UpdateExpense(c.Request.Context(), userId, workspaceId, expenseId, serviceInput)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// DeleteExpense godoc
+// @Summary      Delete expense
+// @Description  Remove an expense and its receipt. Members can delete their own expenses until they are approved; workspace admins until they are billed or reimbursed.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        expense_id  path      string  true  "Expense ID"
+// @Success      200         {object}  map[string]string
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id} [delete]
+func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteExpense(c.Request.Context(), userId, workspaceId, expenseId); err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "expense deleted"})
+}
+
+// ApproveExpense godoc
+// @Summary      Approve expense
+// @Description  Approve a pending expense. Approved expenses are locked, can be billed to their client and, when the submitter paid, reimbursed. Requires a workspace admin.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        expense_id  path      string  true  "Expense ID"
+// @Success      200         {object}  store.Expense
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id}/approve [post]
+func (h *ExpenseHandler) ApproveExpense(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	expense, err := h.service.ApproveExpense(c.Request.Context(), userId, workspaceId, expenseId)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// RejectExpense godoc
+// @Summary      Reject expense
+// @Description  Send a pending expense back to its submitter with a reason. Changing it submits it again. Requires a workspace admin.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                true  "Workspace ID"
+// @Param        expense_id  path      string                true  "Expense ID"
+// @Param        request     body      rejectExpenseRequest  true  "Reject Expense Request"
+// @Success      200         {object}  store.Expense
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id}/reject [post]
+func (h *ExpenseHandler) RejectExpense(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	var req rejectExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.RejectExpenseInput{
+		Reason: req.Reason,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	expense, err := h.service.RejectExpense(c.Request.Context(), userId, workspaceId, expenseId, serviceInput)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// ReimburseExpense godoc
+// @Summary      Reimburse expense
+// @Description  Record that an approved, reimbursable expense was paid back to its submitter, now unless reimbursed_at is given. Requires a workspace admin.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                   true   "Workspace ID"
+// @Param        expense_id  path      string                   true   "Expense ID"
+// @Param        request     body      reimburseExpenseRequest  false  "Reimburse Expense Request"
+// @Success      200         {object}  store.Expense
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id}/reimburse [post]
+func (h *ExpenseHandler) ReimburseExpense(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	var req reimburseExpenseRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			handleValidationError(c, err)
+			return
+		}
+	}
+
+	serviceInput := service.ReimburseExpenseInput{
+		Reference:    req.Reference,
+		ReimbursedAt: req.ReimbursedAt,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	expense, err := h.service.ReimburseExpense(c.Request.Context(), userId, workspaceId, expenseId, serviceInput)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// UploadExpenseReceipt godoc
+// @Summary      Upload expense receipt
+// @Description  Attach a receipt to an expense, replacing the previous one. Receipts are kept private and count towards the workspace storage quota. They can be changed until the expense is approved. The receipt is then read in the background; receipt_extraction_status turns from pending to completed once the suggested_* fields are filled in for the submitter to confirm, or to unsupported or failed when it could not be read.
+// @Tags         expense
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        expense_id  path      string  true  "Expense ID"
+// @Param        receipt     formData  file    true  "Receipt (pdf, jpeg, png, webp or heic, max 10MB)"
+// @Success      200         {object}  store.Expense
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      402         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id}/receipt [post]
+func (h *ExpenseHandler) UploadExpenseReceipt(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	file, header, err := c.Request.FormFile("receipt")
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid file"))
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxReceiptSize {
+		c.Error(apperr.BadRequest("file size too large (max 10MB)"))
+		return
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	if !receiptContentTypes[contentType] {
+		c.Error(apperr.BadRequest("invalid file type (allowed: pdf, jpeg, png, webp, heic)"))
+		return
+	}
+
+	fileName := filepath.Base(header.Filename)
+	if len(fileName) > 255 {
+		fileName = fileName[len(fileName)-255:]
+	}
+
+	expense, err := h.service.UploadExpenseReceipt(c.Request.Context(), userId, workspaceId, expenseId, fileName, file, header.Size, contentType)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// GetExpenseReceipt godoc
+// @Summary      Get expense receipt
+// @Description  Get a link to the receipt of an expense that expires after 15 minutes.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        expense_id  path      string  true  "Expense ID"
+// @Success      200         {object}  service.ExpenseReceipt
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id}/receipt [get]
+func (h *ExpenseHandler) GetExpenseReceipt(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	receipt, err := h.service.GetExpenseReceipt(c.Request.Context(), userId, workspaceId, expenseId)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+// DeleteExpenseReceipt godoc
+// @Summary      Delete expense receipt
+// @Description  Remove the receipt of an expense that is not approved yet.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        expense_id  path      string  true  "Expense ID"
+// @Success      200         {object}  store.Expense
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id}/receipt [delete]
+func (h *ExpenseHandler) DeleteExpenseReceipt(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	expense, err := h.service.DeleteExpenseReceipt(c.Request.Context(), userId, workspaceId, expenseId)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// ConfirmReceiptSuggestions godoc
+// @Summary      Confirm receipt suggestions
+// @Description  Apply the merchant, amount and date read off the receipt to the expense, or only the fields listed. Confirming the amount applies its currency as well. Only the submitter can confirm, and only until the expense is approved.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                            true   "Workspace ID"
+// @Param        expense_id  path      string                            true   "Expense ID"
+// @Param        request     body      confirmReceiptSuggestionsRequest  false  "Confirm Receipt Suggestions Request"
+// @Success      200         {object}  store.Expense
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id}/receipt/suggestions/confirm [post]
+func (h *ExpenseHandler) ConfirmReceiptSuggestions(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	var req confirmReceiptSuggestionsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			handleValidationError(c, err)
+			return
+		}
+	}
+
+	serviceInput := service.ConfirmReceiptSuggestionsInput{
+		Fields: req.Fields,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	expense, err := h.service.ConfirmReceiptSuggestions(c.Request.Context(), userId, workspaceId, expenseId, serviceInput)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// DismissReceiptSuggestions godoc
+// @Summary      Dismiss receipt suggestions
+// @Description  Discard what was read off the receipt without changing the expense. Only the submitter can dismiss.
+// @Tags         expense
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        expense_id  path      string  true  "Expense ID"
+// @Success      200         {object}  store.Expense
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/expenses/{expense_id}/receipt/suggestions [delete]
+func (h *ExpenseHandler) DismissReceiptSuggestions(c *gin.Context) {
+	userId, workspaceId, expenseId, ok := parseExpenseParams(c)
+	if !ok {
+		return
+	}
+
+	expense, err := h.service.DismissReceiptSuggestions(c.Request.Context(), userId, workspaceId, expenseId)
+	if err != nil {
+		handleExpenseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+func parseExpenseParams(c *gin.Context) (userId, workspaceId, expenseId uuid.UUID, ok bool) {
+	userId, workspaceId, ok = parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	expenseId, err := uuid.Parse(c.Param("expense_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid expense id"))
+		return userId, workspaceId, expenseId, false
+	}
+
+	return userId, workspaceId, expenseId, true
+}
+
+func parseExpenseCategoryParams(c *gin.Context) (userId, workspaceId, categoryId uuid.UUID, ok bool) {
+	userId, workspaceId, ok = parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	categoryId, err := uuid.Parse(c.Param("category_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid expense category id"))
+		return userId, workspaceId, categoryId, false
+	}
+
+	return userId, workspaceId, categoryId, true
+}
+
+func handleExpenseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrQuotaExceeded):
+		c.Error(apperr.QuotaExceeded(err.Error()))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrExpenseNotFound):
+		c.Error(apperr.NotFound("expense"))
+	case errors.Is(err, service.ErrExpenseCategoryNotFound):
+		c.Error(apperr.NotFound("expense category"))
+	case errors.Is(err, service.ErrExpenseReceiptNotFound):
+		c.Error(apperr.NotFound("receipt"))
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.Error(apperr.NotFound("project"))
+	case errors.Is(err, service.ErrClientNotFound):
+		c.Error(apperr.NotFound("client"))
+	case errors.Is(err, service.ErrExpenseCategoryNameTaken),
+		errors.Is(err, service.ErrExpenseNotPending),
+		errors.Is(err, service.ErrExpenseNotReimbursable),
+		errors.Is(err, service.ErrExpenseLocked),
+		errors.Is(err, service.ErrNoReceiptSuggestions):
+		c.Error(apperr.Conflict(err.Error()))
+	case errors.Is(err, service.ErrExpenseClientRequired),
+		errors.Is(err, service.ErrInvoiceProjectClient),
+		errors.Is(err, service.ErrInvalidFilter):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/store"
+)
+
+// bindFilters copies the allowed query parameters into filters.Filters so
+// services can narrow listings by resource-specific fields.
+func bindFilters(c *gin.Context, filters *store.FilterParams, keys ...string) {
+	if filters.Filters == nil {
+		filters.Filters = make(map[string]string)
+	}
+	for _, key := range keys {
+		if value := strings.TrimSpace(c.Query(key)); value != "" {
+			filters.Filters[key] = value
+		}
+	}
+}
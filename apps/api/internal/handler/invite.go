@@ -0,0 +1,375 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type InviteHandler struct {
+	service service.Invite
+}
+
+func NewInviteHandler(service service.Invite) *InviteHandler {
+	return &InviteHandler{service: service}
+}
+
+type createInviteLinkRequest struct {
+	Role      *string    `json:"role" binding:"omitempty,oneof=admin member"`
+	MaxUses   *int32     `json:"max_uses" binding:"omitempty,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type addJoinDomainRequest struct {
+	Domain string  `json:"domain" binding:"required"`
+	Role   *string `json:"role" binding:"omitempty,oneof=admin member"`
+}
+
+type joinWorkspaceRequest struct {
+	Token       *string    `json:"token"`
+	WorkspaceID *uuid.UUID `json:"workspace_id"`
+}
+
+// CreateInviteLink godoc
+// @Summary      Create invite link
+// @Description  Create a shareable invite link. The token is only returned once.
+// @Tags         invite
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                   true  "Workspace ID"
+// @Param        request  body      createInviteLinkRequest  true  "Create Invite Link Request"
+// @Success      201      {object}  service.CreatedInviteLink
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/invite-links [post]
+func (h *InviteHandler) CreateInviteLink(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	var req createInviteLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateInviteLinkInput{
+		Role:      req.Role,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	link, err := h.service.CreateInviteLink(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleInviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// ListInviteLinks godoc
+// @Summary      List invite links
+// @Description  List all invite links of the workspace, including revoked and expired ones
+// @Tags         invite
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      200  {array}   store.InviteLink
+// @Failure      400  {object}  apperr.AppError
+// @Failure      401  {object}  apperr.AppError
+// @Failure      403  {object}  apperr.AppError
+// @Failure      500  {object}  apperr.AppError
+// @Router       /workspaces/{id}/invite-links [get]
+func (h *InviteHandler) ListInviteLinks(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	links, err := h.service.ListInviteLinks(c.Request.Context(), userId, workspaceId)
+	if err != nil {
+		handleInviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// RevokeInviteLink godoc
+// @Summary      Revoke invite link
+// @Description  Revoke an invite link so it can no longer be used
+// @Tags         invite
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string  true  "Workspace ID"
+// @Param        link_id  path      string  true  "Invite Link ID"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/invite-links/{link_id} [delete]
+func (h *InviteHandler) RevokeInviteLink(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	linkId, err := uuid.Parse(c.Param("link_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid invite link id"))
+		return
+	}
+
+	if err := h.service.RevokeInviteLink(c.Request.Context(), userId, workspaceId, linkId); err != nil {
+		handleInviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invite link revoked"})
+}
+
+// AddJoinDomain godoc
+// @Summary      Add join domain
+// @Description  Let users with a verified email on the domain join the workspace on their own
+// @Tags         invite
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                true  "Workspace ID"
+// @Param        request  body      addJoinDomainRequest  true  "Add Join Domain Request"
+// @Success      201      {object}  store.JoinDomain
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/join-domains [post]
+func (h *InviteHandler) AddJoinDomain(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	var req addJoinDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.AddJoinDomainInput{
+		Domain: req.Domain,
+		Role:   req.Role,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	domain, err := h.service.AddJoinDomain(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleInviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, domain)
+}
+
+// ListJoinDomains godoc
+// @Summary      List join domains
+// @Description  List the email domains allowed to auto-join the workspace
+// @Tags         invite
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      200  {array}   store.JoinDomain
+// @Failure      400  {object}  apperr.AppError
+// @Failure      401  {object}  apperr.AppError
+// @Failure      403  {object}  apperr.AppError
+// @Failure      500  {object}  apperr.AppError
+// @Router       /workspaces/{id}/join-domains [get]
+func (h *InviteHandler) ListJoinDomains(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	domains, err := h.service.ListJoinDomains(c.Request.Context(), userId, workspaceId)
+	if err != nil {
+		handleInviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domains)
+}
+
+// RemoveJoinDomain godoc
+// @Summary      Remove join domain
+// @Description  Stop letting users on the domain auto-join the workspace
+// @Tags         invite
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      string  true  "Workspace ID"
+// @Param        domain  path      string  true  "Domain"
+// @Success      200     {object}  map[string]string
+// @Failure      400     {object}  apperr.AppError
+// @Failure      401     {object}  apperr.AppError
+// @Failure      403     {object}  apperr.AppError
+// @Failure      404     {object}  apperr.AppError
+// @Failure      500     {object}  apperr.AppError
+// @Router       /workspaces/{id}/join-domains/{domain} [delete]
+func (h *InviteHandler) RemoveJoinDomain(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	if err := h.service.RemoveJoinDomain(c.Request.Context(), userId, workspaceId, c.Param("domain")); err != nil {
+		handleInviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "join domain removed"})
+}
+
+// Join godoc
+// @Summary      Join workspace
+// @Description  Join a workspace with an invite link token, or by workspace ID when your verified email domain is allowed
+// @Tags         invite
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      joinWorkspaceRequest  true  "Join Workspace Request"
+// @Success      200      {object}  store.WorkspaceMember
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      402      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/join [post]
+func (h *InviteHandler) Join(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	var req joinWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.JoinWorkspaceInput{
+		Token:       req.Token,
+		WorkspaceID: req.WorkspaceID,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	member, err := h.service.Join(c.Request.Context(), userId, serviceInput)
+	if err != nil {
+		handleInviteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func handleInviteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, store.ErrInviteLinkNotFound):
+		c.Error(apperr.NotFound("invite link"))
+	case errors.Is(err, store.ErrJoinDomainNotFound):
+		c.Error(apperr.NotFound("join domain"))
+	case errors.Is(err, store.ErrJoinDomainExists):
+		c.Error(apperr.Conflict("join domain already exists"))
+	case errors.Is(err, service.ErrAlreadyMember):
+		c.Error(apperr.Conflict("you are already a member of this workspace"))
+	case errors.Is(err, service.ErrInviteLinkInvalid):
+		c.Error(apperr.NotFound("invite link"))
+	case errors.Is(err, service.ErrEmailNotVerified),
+		errors.Is(err, service.ErrDomainNotAllowed),
+		errors.Is(err, service.ErrMemberDeactivated):
+		c.Error(apperr.Forbidden(err.Error()))
+	case errors.Is(err, service.ErrInviteExpiryInPast),
+		errors.Is(err, service.ErrPublicEmailDomain),
+		errors.Is(err, service.ErrJoinMethodRequired):
+		c.Error(apperr.BadRequest(err.Error()))
+	case errors.Is(err, service.ErrQuotaExceeded):
+		c.Error(apperr.QuotaExceeded(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
@@ -0,0 +1,470 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+type InvoiceHandler struct {
+	service service.Invoice
+}
+
+func NewInvoiceHandler(service service.Invoice) *InvoiceHandler {
+	return &InvoiceHandler{service: service}
+}
+
+type createInvoiceRequest struct {
+	ClientID        uuid.UUID                  `json:"client_id" binding:"required"`
+	ProjectID       *uuid.UUID                 `json:"project_id"`
+	Currency        *string                    `json:"currency" example:"USD"`
+	IssueDate       *string                    `json:"issue_date" example:"2026-03-01"`
+	DueDate         *string                    `json:"due_date" example:"2026-03-31"`
+	DiscountPercent *money.Decimal             `json:"discount_percent" swaggertype:"number" example:"5"`
+	TaxRate         *money.Decimal             `json:"tax_rate" swaggertype:"number" example:"20"`
+	Notes           *string                    `json:"notes"`
+	Terms           *string                    `json:"terms" example:"Payment within 30 days"`
+	Items           []service.InvoiceItemInput `json:"items"`
+}
+
+type updateInvoiceRequest struct {
+	ClientID        *uuid.UUID                 `json:"client_id"`
+	ProjectID       *string                    `json:"project_id"`
+	Currency        *string                    `json:"currency" example:"USD"`
+	IssueDate       *string                    `json:"issue_date" example:"2026-03-01"`
+	DueDate         *string                    `json:"due_date" example:"2026-03-31"`
+	DiscountPercent *money.Decimal             `json:"discount_percent" swaggertype:"number" example:"5"`
+	TaxRate         *money.Decimal             `json:"tax_rate" swaggertype:"number" example:"20"`
+	Notes           *string                    `json:"notes"`
+	Terms           *string                    `json:"terms"`
+	Items           []service.InvoiceItemInput `json:"items"`
+}
+
+type createInvoiceFromUnbilledRequest struct {
+	ClientID        *uuid.UUID     `json:"client_id"`
+	ProjectID       *uuid.UUID     `json:"project_id"`
+	From            string         `json:"from" binding:"required" example:"2026-03-01"`
+	To              string         `json:"to" binding:"required" example:"2026-03-31"`
+	GroupBy         string         `json:"group_by" example:"task"`
+	HourlyRate      *int64         `json:"hourly_rate" example:"12000"`
+	ApprovedOnly    bool           `json:"approved_only"`
+	IncludeExpenses *bool          `json:"include_expenses"`
+	DueDate         *string        `json:"due_date" example:"2026-04-30"`
+	TaxRate         *money.Decimal `json:"tax_rate" swaggertype:"number" example:"20"`
+	Notes           *string        `json:"notes"`
+}
+
+type updateInvoiceStatusRequest struct {
+	Status string `json:"status" binding:"required" example:"sent"`
+}
+
+// CreateInvoice godoc
+// @Summary      Create invoice
+// @Description  Create a draft invoice for a client. Amounts are in minor units of the currency, which defaults to the client's; quantities, discounts and tax rates are decimals. Each line's discount applies first, then the invoice discount, and tax is charged on the rest. Requires a workspace admin.
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                true  "Workspace ID"
+// @Param        request  body      createInvoiceRequest  true  "Create Invoice Request"
+// @Success      201      {object}  store.Invoice
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices [post]
+func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req createInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateInvoiceInput{
+		ClientID:        req.ClientID,
+		ProjectID:       req.ProjectID,
+		Currency:        req.Currency,
+		IssueDate:       req.IssueDate,
+		DueDate:         req.DueDate,
+		DiscountPercent: req.DiscountPercent,
+		TaxRate:         req.TaxRate,
+		Notes:           req.Notes,
+		Terms:           req.Terms,
+		Items:           req.Items,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	invoice, err := h.service.CreateInvoice(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invoice)
+}
+
+// CreateInvoiceFromUnbilled godoc
+// @Summary      Create invoice from unbilled time and expenses
+// @Description  Draft an invoice from the billable time and expenses of a client, or of one project, that have not been invoiced yet. From and to are inclusive dates in the workspace timezone. Lines group the time by task, person or day within each project and are charged at hourly_rate if given, else the project's hourly rate, else the workspace default rate. Approved billable expenses in the invoice currency follow as one line each unless include_expenses is false. The time and expenses are marked billed in the same transaction and released again when the invoice is deleted or cancelled. Requires a workspace admin.
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                            true  "Workspace ID"
+// @Param        request  body      createInvoiceFromUnbilledRequest  true  "Create Invoice From Unbilled Request"
+// @Success      201      {object}  store.Invoice
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/from-unbilled [post]
+func (h *InvoiceHandler) CreateInvoiceFromUnbilled(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req createInvoiceFromUnbilledRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateInvoiceFromUnbilledInput{
+		ClientID:        req.ClientID,
+		ProjectID:       req.ProjectID,
+		From:            req.From,
+		To:              req.To,
+		GroupBy:         req.GroupBy,
+		HourlyRate:      req.HourlyRate,
+		ApprovedOnly:    req.ApprovedOnly,
+		IncludeExpenses: req.IncludeExpenses,
+		DueDate:         req.DueDate,
+		TaxRate:         req.TaxRate,
+		Notes:           req.Notes,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	invoice, err := h.service.CreateInvoiceFromUnbilled(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, invoice)
+}
+
+// ListInvoices godoc
+// @Summary      List invoices
+// @Description  List the invoices of the workspace with filtering, sorting, and pagination
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string  true   "Workspace ID"
+// @Param        limit          query     int     false  "Limit (default 20, max 100)"
+// @Param        offset         query     int     false  "Offset (default 0)"
+// @Param        sort_by        query     string  false  "Sort by: number, status, issue_date, due_date, total, balance_due, created_at, updated_at (default: created_at)"
+// @Param        order          query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search         query     string  false  "Search in invoice number or client name"
+// @Param        status         query     string  false  "Filter by comma-separated statuses: draft, sent, viewed, paid, overdue, cancelled"
+// @Param        client_id      query     string  false  "Filter by client ID"
+// @Param        project_id     query     string  false  "Filter by project ID"
+// @Param        recurrence_id  query     string  false  "Filter by recurring invoice ID"
+// @Param        issued_from    query     string  false  "Filter by issue date on or after (YYYY-MM-DD)"
+// @Param        issued_to      query     string  false  "Filter by issue date on or before (YYYY-MM-DD)"
+// @Success      200            {object}  store.PaginatedInvoicesResponse
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices [get]
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+	bindFilters(c, &filters, "status", "client_id", "project_id", "recurrence_id", "issued_from", "issued_to")
+
+	invoices, err := h.service.ListInvoices(c.Request.Context(), userId, workspaceId, filters)
+	if err != nil {
+		handleInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+// GetInvoice godoc
+// @Summary      Get invoice
+// @Description  Get an invoice of the workspace with its line items
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        invoice_id  path      string  true  "Invoice ID"
+// @Success      200         {object}  store.Invoice
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id} [get]
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.service.GetInvoice(c.Request.Context(), userId, workspaceId, invoiceId)
+	if err != nil {
+		handleInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// GetInvoicePDF godoc
+// @Summary      Get invoice PDF
+// @Description  Get a short-lived link to the branded PDF of an invoice. The PDF is rendered again when the invoice, its client or the workspace branding changed.
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        invoice_id  path      string  true  "Invoice ID"
+// @Success      200         {object}  service.InvoicePDF
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      402         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id}/pdf [get]
+func (h *InvoiceHandler) GetInvoicePDF(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	document, err := h.service.GetInvoicePDF(c.Request.Context(), userId, workspaceId, invoiceId)
+	if err != nil {
+		handleInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+// UpdateInvoice godoc
+// @Summary      Update invoice
+// @Description  Update a draft invoice and recompute its totals. Items, when given, replace every line. Requires a workspace admin.
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                true  "Workspace ID"
+// @Param        invoice_id  path      string                true  "Invoice ID"
+// @Param        request     body      updateInvoiceRequest  true  "Update Invoice Request"
+// @Success      200         {object}  store.Invoice
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id} [patch]
+func (h *InvoiceHandler) UpdateInvoice(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateInvoiceInput{
+		ClientID:        req.ClientID,
+		ProjectID:       req.ProjectID,
+		Currency:        req.Currency,
+		IssueDate:       req.IssueDate,
+		DueDate:         req.DueDate,
+		DiscountPercent: req.DiscountPercent,
+		TaxRate:         req.TaxRate,
+		Notes:           req.Notes,
+		Terms:           req.Terms,
+		Items:           req.Items,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	invoice, err := h.service.UpdateInvoice(c.Request.Context(), userId, workspaceId, invoiceId, serviceInput)
+	if err != nil {
+		handleInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// UpdateInvoiceStatus godoc
+// @Summary      Update invoice status
+// @Description  Move an invoice through its lifecycle: draft to sent or cancelled; sent to viewed, overdue or cancelled; viewed to overdue or cancelled; overdue to cancelled. Invoices become paid when recorded payments settle them, and cannot be cancelled while payments are applied. Sending assigns the next invoice number, dates the invoice today in the workspace timezone if no issue date was set, and defaults the due date to 30 days later. Requires a workspace admin.
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                      true  "Workspace ID"
+// @Param        invoice_id  path      string                      true  "Invoice ID"
+// @Param        request     body      updateInvoiceStatusRequest  true  "Update Invoice Status Request"
+// @Success      200         {object}  store.Invoice
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id}/status [post]
+func (h *InvoiceHandler) UpdateInvoiceStatus(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateInvoiceStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateInvoiceStatusInput{
+		Status: req.Status,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	invoice, err := h.service.UpdateInvoiceStatus(c.Request.Context(), userId, workspaceId, invoiceId, serviceInput)
+	if err != nil {
+		handleInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invoice)
+}
+
+// DeleteInvoice godoc
+// @Summary      Delete invoice
+// @Description  Delete a draft invoice. Sent invoices keep their number and can only be cancelled. Requires a workspace admin.
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        invoice_id  path      string  true  "Invoice ID"
+// @Success      200         {object}  map[string]string
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id} [delete]
+func (h *InvoiceHandler) DeleteInvoice(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteInvoice(c.Request.Context(), userId, workspaceId, invoiceId); err != nil {
+		handleInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invoice deleted"})
+}
+
+func parseInvoiceParams(c *gin.Context) (userId, workspaceId, invoiceId uuid.UUID, ok bool) {
+	userId, workspaceId, ok = parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	invoiceId, err := uuid.Parse(c.Param("invoice_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid invoice id"))
+		return userId, workspaceId, invoiceId, false
+	}
+
+	return userId, workspaceId, invoiceId, true
+}
+
+func handleInvoiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrInvoiceNotFound):
+		c.Error(apperr.NotFound("invoice"))
+	case errors.Is(err, service.ErrClientNotFound):
+		c.Error(apperr.NotFound("client"))
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.Error(apperr.NotFound("project"))
+	case errors.Is(err, service.ErrQuotaExceeded):
+		c.Error(apperr.QuotaExceeded(err.Error()))
+	case errors.Is(err, service.ErrInvoiceNotEditable),
+		errors.Is(err, service.ErrInvalidInvoiceStatusTransition),
+		errors.Is(err, service.ErrInvoiceEmpty),
+		errors.Is(err, service.ErrInvoiceHasPayments),
+		errors.Is(err, service.ErrNothingToInvoice),
+		errors.Is(err, service.ErrUnbilledChanged):
+		c.Error(apperr.Conflict(err.Error()))
+	case errors.Is(err, service.ErrInvalidInvoiceDates),
+		errors.Is(err, service.ErrInvoiceProjectClient),
+		errors.Is(err, service.ErrInvoiceScopeRequired),
+		errors.Is(err, service.ErrInvoiceClientRequired),
+		errors.Is(err, service.ErrInvalidBillingPeriod),
+		errors.Is(err, service.ErrMissingHourlyRate),
+		errors.Is(err, service.ErrInvoiceCurrencyMismatch),
+		errors.Is(err, service.ErrInvoiceLineTooLarge),
+		errors.Is(err, service.ErrInvalidFilter):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+type RecurringInvoiceHandler struct {
+	service service.RecurringInvoice
+}
+
+func NewRecurringInvoiceHandler(service service.RecurringInvoice) *RecurringInvoiceHandler {
+	return &RecurringInvoiceHandler{service: service}
+}
+
+type createRecurringInvoiceRequest struct {
+	Name            string                     `json:"name" binding:"required" example:"Monthly retainer"`
+	ClientID        uuid.UUID                  `json:"client_id" binding:"required"`
+	ProjectID       *uuid.UUID                 `json:"project_id"`
+	Currency        *string                    `json:"currency" example:"USD"`
+	DiscountPercent *money.Decimal             `json:"discount_percent" swaggertype:"number" example:"0"`
+	TaxRate         *money.Decimal             `json:"tax_rate" swaggertype:"number" example:"20"`
+	Notes           *string                    `json:"notes"`
+	Terms           *string                    `json:"terms" example:"Payment within 14 days"`
+	Items           []service.InvoiceItemInput `json:"items" binding:"required"`
+	RRule           string                     `json:"rrule" binding:"required" example:"FREQ=MONTHLY;BYMONTHDAY=1"`
+	StartDate       *string                    `json:"start_date" example:"2026-01-01"`
+	DueDays         *int                       `json:"due_days" example:"14"`
+	EndDate         *string                    `json:"end_date" example:"2026-12-31"`
+	MaxInvoices     *int32                     `json:"max_invoices" example:"12"`
+	AutoSend        bool                       `json:"auto_send"`
+}
+
+type updateRecurringInvoiceRequest struct {
+	Name            *string                    `json:"name"`
+	ClientID        *uuid.UUID                 `json:"client_id"`
+	ProjectID       *string                    `json:"project_id"`
+	Currency        *string                    `json:"currency" example:"USD"`
+	DiscountPercent *money.Decimal             `json:"discount_percent" swaggertype:"number" example:"0"`
+	TaxRate         *money.Decimal             `json:"tax_rate" swaggertype:"number" example:"20"`
+	Notes           *string                    `json:"notes"`
+	Terms           *string                    `json:"terms"`
+	Items           []service.InvoiceItemInput `json:"items"`
+	RRule           *string                    `json:"rrule" example:"FREQ=MONTHLY;BYMONTHDAY=-1"`
+	StartDate       *string                    `json:"start_date" example:"2026-01-01"`
+	DueDays         *int                       `json:"due_days" example:"30"`
+	EndDate         *string                    `json:"end_date" example:"2027-06-30"`
+	MaxInvoices     *int32                     `json:"max_invoices" example:"0"`
+	AutoSend        *bool                      `json:"auto_send"`
+}
+
+// CreateRecurringInvoice godoc
+// @Summary      Create recurring invoice
+// @Description  Create an invoice template issued on an iCalendar RRULE, such as FREQ=MONTHLY;BYMONTHDAY=1 for a monthly retainer. Dates are read in the workspace timezone and the rule starts today unless start_date is given. Every occurrence creates an invoice issued on that date and due due_days later (default 30); with auto_send it is sent right away, otherwise it stays a draft for review. The schedule ends after end_date or once max_invoices invoices were created. Requires a workspace admin.
+// @Tags         recurring-invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                         true  "Workspace ID"
+// @Param        request  body      createRecurringInvoiceRequest  true  "Create Recurring Invoice Request"
+// @Success      201      {object}  store.InvoiceRecurrence
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/recurring-invoices [post]
+func (h *RecurringInvoiceHandler) CreateRecurringInvoice(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req createRecurringInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateRecurringInvoiceInput{
+		Name:            req.Name,
+		ClientID:        req.ClientID,
+		ProjectID:       req.ProjectID,
+		Currency:        req.Currency,
+		DiscountPercent: req.DiscountPercent,
+		TaxRate:         req.TaxRate,
+		Notes:           req.Notes,
+		Terms:           req.Terms,
+		Items:           req.Items,
+		RRule:           req.RRule,
+		StartDate:       req.StartDate,
+		DueDays:         req.DueDays,
+		EndDate:         req.EndDate,
+		MaxInvoices:     req.MaxInvoices,
+		AutoSend:        req.AutoSend,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	recurrence, err := h.service.CreateRecurringInvoice(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleRecurringInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, recurrence)
+}
+
+// ListRecurringInvoices godoc
+// @Summary      List recurring invoices
+// @Description  List the recurring invoice templates of the workspace. Requires a workspace admin.
+// @Tags         recurring-invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string  true   "Workspace ID"
+// @Param        limit      query     int     false  "Limit (default 20, max 100)"
+// @Param        offset     query     int     false  "Offset (default 0)"
+// @Param        sort_by    query     string  false  "Sort by: name, next_date, created_at, updated_at (default: created_at)"
+// @Param        order      query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search     query     string  false  "Search in name or client name"
+// @Param        client_id  query     string  false  "Filter by client ID"
+// @Param        active     query     bool    false  "Filter by whether the schedule still creates invoices"
+// @Success      200        {object}  store.PaginatedInvoiceRecurrencesResponse
+// @Failure      400        {object}  apperr.AppError
+// @Failure      401        {object}  apperr.AppError
+// @Failure      403        {object}  apperr.AppError
+// @Failure      500        {object}  apperr.AppError
+// @Router       /workspaces/{id}/recurring-invoices [get]
+func (h *RecurringInvoiceHandler) ListRecurringInvoices(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+	bindFilters(c, &filters, "client_id", "active")
+
+	recurrences, err := h.service.ListRecurringInvoices(c.Request.Context(), userId, workspaceId, filters)
+	if err != nil {
+		handleRecurringInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrences)
+}
+
+// GetRecurringInvoice godoc
+// @Summary      Get recurring invoice
+// @Description  Get a recurring invoice template with its line items, next issue date and the number of invoices created so far. Requires a workspace admin.
+// @Tags         recurring-invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string  true  "Workspace ID"
+// @Param        recurrence_id  path      string  true  "Recurring Invoice ID"
+// @Success      200            {object}  store.InvoiceRecurrence
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      404            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/recurring-invoices/{recurrence_id} [get]
+func (h *RecurringInvoiceHandler) GetRecurringInvoice(c *gin.Context) {
+	userId, workspaceId, recurrenceId, ok := parseRecurringInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	recurrence, err := h.service.GetRecurringInvoice(c.Request.Context(), userId, workspaceId, recurrenceId)
+	if err != nil {
+		handleRecurringInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrence)
+}
+
+// UpdateRecurringInvoice godoc
+// @Summary      Update recurring invoice
+// @Description  Update a recurring invoice template. Changes apply to invoices that have not been created yet. A new rrule, start_date, end_date or max_invoices reschedules the next invoice; an empty project_id or end_date clears it, max_invoices 0 removes the limit, and items replaces every line. Requires a workspace admin.
+// @Tags         recurring-invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string                         true  "Workspace ID"
+// @Param        recurrence_id  path      string                         true  "Recurring Invoice ID"
+// @Param        request        body      updateRecurringInvoiceRequest  true  "Update Recurring Invoice Request"
+// @Success      200            {object}  store.InvoiceRecurrence
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      404            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/recurring-invoices/{recurrence_id} [patch]
+func (h *RecurringInvoiceHandler) UpdateRecurringInvoice(c *gin.Context) {
+	userId, workspaceId, recurrenceId, ok := parseRecurringInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateRecurringInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateRecurringInvoiceInput{
+		Name:            req.Name,
+		ClientID:        req.ClientID,
+		ProjectID:       req.ProjectID,
+		Currency:        req.Currency,
+		DiscountPercent: req.DiscountPercent,
+		TaxRate:         req.TaxRate,
+		Notes:           req.Notes,
+		Terms:           req.Terms,
+		Items:           req.Items,
+		RRule:           req.RRule,
+		StartDate:       req.StartDate,
+		DueDays:         req.DueDays,
+		EndDate:         req.EndDate,
+		MaxInvoices:     req.MaxInvoices,
+		AutoSend:        req.AutoSend,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	recurrence, err := h.service.UpdateRecurringInvoice(c.Request.Context(), userId, workspaceId, recurrenceId, serviceInput)
+	if err != nil {
+		handleRecurringInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrence)
+}
+
+// DeleteRecurringInvoice godoc
+// @Summary      Delete recurring invoice
+// @Description  Stop a recurring invoice. Invoices already created are kept. Requires a workspace admin.
+// @Tags         recurring-invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string  true  "Workspace ID"
+// @Param        recurrence_id  path      string  true  "Recurring Invoice ID"
+// @Success      200            {object}  map[string]string
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      404            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/recurring-invoices/{recurrence_id} [delete]
+func (h *RecurringInvoiceHandler) DeleteRecurringInvoice(c *gin.Context) {
+	userId, workspaceId, recurrenceId, ok := parseRecurringInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRecurringInvoice(c.Request.Context(), userId, workspaceId, recurrenceId); err != nil {
+		handleRecurringInvoiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recurring invoice deleted"})
+}
+
+func parseRecurringInvoiceParams(c *gin.Context) (userId, workspaceId, recurrenceId uuid.UUID, ok bool) {
+	userId, workspaceId, ok = parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	recurrenceId, err := uuid.Parse(c.Param("recurrence_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid recurring invoice id"))
+		return userId, workspaceId, recurrenceId, false
+	}
+
+	return userId, workspaceId, recurrenceId, true
+}
+
+func handleRecurringInvoiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrRecurringInvoiceNotFound):
+		c.Error(apperr.NotFound("recurring invoice"))
+	case errors.Is(err, service.ErrClientNotFound):
+		c.Error(apperr.NotFound("client"))
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.Error(apperr.NotFound("project"))
+	case errors.Is(err, service.ErrInvoiceProjectClient),
+		errors.Is(err, service.ErrInvalidRecurringInvoiceEnd),
+		errors.Is(err, service.ErrInvalidRecurrenceRule),
+		errors.Is(err, service.ErrUnsupportedRecurrenceRule),
+		errors.Is(err, service.ErrNoUpcomingOccurrences),
+		errors.Is(err, service.ErrInvalidFilter):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
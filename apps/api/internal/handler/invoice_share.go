@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type InvoiceShareHandler struct {
+	service service.InvoiceShare
+}
+
+func NewInvoiceShareHandler(service service.InvoiceShare) *InvoiceShareHandler {
+	return &InvoiceShareHandler{service: service}
+}
+
+type createInvoiceShareLinkRequest struct {
+	ExpiresAt *time.Time `json:"expires_at" example:"2026-04-30T00:00:00Z"`
+}
+
+// CreateShareLink godoc
+// @Summary      Create invoice share link
+// @Description  Create a link that lets the client open a sent invoice without an account. Links expire after 30 days unless expires_at, at most a year away, says otherwise. Requires a workspace admin.
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                         true   "Workspace ID"
+// @Param        invoice_id  path      string                         true   "Invoice ID"
+// @Param        request     body      createInvoiceShareLinkRequest  false  "Create Share Link Request"
+// @Success      201         {object}  service.InvoiceShareLink
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id}/share-links [post]
+func (h *InvoiceShareHandler) CreateShareLink(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	var req createInvoiceShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateInvoiceShareLinkInput{ExpiresAt: req.ExpiresAt}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	link, err := h.service.CreateShareLink(c.Request.Context(), userId, workspaceId, invoiceId, serviceInput)
+	if err != nil {
+		handleInvoiceShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// ListShareLinks godoc
+// @Summary      List invoice share links
+// @Description  List the share links of an invoice, newest first, with how often each was opened. Requires a workspace admin.
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        invoice_id  path      string  true  "Invoice ID"
+// @Success      200         {array}   service.InvoiceShareLink
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id}/share-links [get]
+func (h *InvoiceShareHandler) ListShareLinks(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	links, err := h.service.ListShareLinks(c.Request.Context(), userId, workspaceId, invoiceId)
+	if err != nil {
+		handleInvoiceShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// RevokeShareLink godoc
+// @Summary      Revoke invoice share link
+// @Description  Revoke a share link so the client can no longer open the invoice with it. Requires a workspace admin.
+// @Tags         invoice
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        invoice_id  path      string  true  "Invoice ID"
+// @Param        link_id     path      string  true  "Share Link ID"
+// @Success      200         {object}  map[string]string
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id}/share-links/{link_id} [delete]
+func (h *InvoiceShareHandler) RevokeShareLink(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+	linkId, err := uuid.Parse(c.Param("link_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid share link id"))
+		return
+	}
+
+	if err := h.service.RevokeShareLink(c.Request.Context(), userId, workspaceId, invoiceId, linkId); err != nil {
+		handleInvoiceShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "share link revoked"})
+}
+
+// ViewSharedInvoice godoc
+// @Summary      View shared invoice
+// @Description  Show an invoice to its client as a web page, through a share link. No account is needed. Opening a sent invoice marks it viewed.
+// @Tags         invoice
+// @Produce      html
+// @Param        token  path      string  true  "Share Token"
+// @Success      200    {string}  string
+// @Failure      404    {object}  apperr.AppError
+// @Failure      429    {object}  apperr.AppError
+// @Failure      500    {object}  apperr.AppError
+// @Router       /public/invoices/{token} [get]
+func (h *InvoiceShareHandler) ViewSharedInvoice(c *gin.Context) {
+	invoice, err := h.service.ViewSharedInvoice(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		handleInvoiceShareError(c, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := sharedInvoiceTemplate.Execute(&buf, invoice); err != nil {
+		c.Error(apperr.Internal(err))
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
+
+// GetSharedInvoicePDF godoc
+// @Summary      Download shared invoice PDF
+// @Description  Redirect to a short-lived link to the invoice PDF, through a share link. No account is needed. Opening a sent invoice marks it viewed.
+// @Tags         invoice
+// @Param        token  path      string  true  "Share Token"
+// @Success      302
+// @Failure      404    {object}  apperr.AppError
+// @Failure      429    {object}  apperr.AppError
+// @Failure      500    {object}  apperr.AppError
+// @Router       /public/invoices/{token}/pdf [get]
+func (h *InvoiceShareHandler) GetSharedInvoicePDF(c *gin.Context) {
+	pdf, err := h.service.GetSharedInvoicePDF(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		handleInvoiceShareError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, pdf.URL)
+}
+
+func handleInvoiceShareError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrInvoiceNotFound):
+		c.Error(apperr.NotFound("invoice"))
+	case errors.Is(err, service.ErrShareLinkNotFound):
+		c.Error(apperr.NotFound("share link"))
+	case errors.Is(err, service.ErrShareLinkInvalid):
+		c.Error(apperr.NotFound("invoice"))
+	case errors.Is(err, service.ErrInvoiceNotShareable):
+		c.Error(apperr.Conflict(err.Error()))
+	case errors.Is(err, service.ErrInvalidShareLinkExpiry):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
+
+var sharedInvoiceTemplate = template.Must(template.New("shared_invoice").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Invoice {{.Number}} · {{.WorkspaceName}}</title>
+<style>
+body { margin: 0; background: #f3f4f6; color: #1f2937; font: 15px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; }
+main { max-width: 760px; margin: 32px auto; background: #fff; border-top: 6px solid {{.BrandColor}}; padding: 40px; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+header { display: flex; justify-content: space-between; align-items: flex-start; gap: 24px; }
+header img { max-height: 56px; max-width: 200px; }
+h1 { margin: 0; color: {{.BrandColor}}; font-size: 28px; letter-spacing: .04em; }
+.status { display: inline-block; margin-top: 6px; padding: 2px 8px; border-radius: 4px; background: #f3f4f6; font-size: 12px; font-weight: 600; text-transform: uppercase; }
+.muted { color: #6b7280; }
+.details { display: flex; justify-content: space-between; gap: 24px; margin: 32px 0; }
+table { width: 100%; border-collapse: collapse; }
+th { text-align: left; font-size: 13px; padding: 8px; background: #f9fafb; }
+td { padding: 8px; border-bottom: 1px solid #e5e7eb; vertical-align: top; }
+.num { text-align: right; white-space: nowrap; }
+.totals { margin: 16px 0 0 auto; width: 320px; }
+.totals td { border: 0; padding: 4px 8px; }
+.balance td { background: {{.BrandColor}}; color: #fff; font-weight: 600; padding: 8px; }
+.pdf { display: inline-block; margin-top: 24px; color: {{.BrandColor}}; font-weight: 600; }
+section { margin-top: 24px; white-space: pre-line; }
+</style>
+</head>
+<body>
+<main>
+<header>
+<div>{{if .LogoURL}}<img src="{{.LogoURL}}" alt="{{.WorkspaceName}}">{{else}}<strong>{{.WorkspaceName}}</strong>{{end}}</div>
+<div class="num">
+<h1>INVOICE</h1>
+<div>{{.Number}}</div>
+{{if ne .Status "sent"}}{{if ne .Status "viewed"}}<div class="status">{{.Status}}</div>{{end}}{{end}}
+</div>
+</header>
+<div class="details">
+<div><div class="muted">Bill to</div><strong>{{.ClientName}}</strong></div>
+<div class="num">
+<div><span class="muted">Issue date</span> {{.IssueDate}}</div>
+<div><span class="muted">Due date</span> {{.DueDate}}</div>
+{{with .ProjectName}}<div><span class="muted">Project</span> {{.}}</div>{{end}}
+</div>
+</div>
+<table>
+<thead><tr><th>Description</th><th class="num">Qty</th><th class="num">Unit price</th><th class="num">Amount</th></tr></thead>
+<tbody>
+{{range .Items}}<tr><td>{{.Description}}</td><td class="num">{{.Quantity}}</td><td class="num">{{.UnitPrice}}</td><td class="num">{{.Amount}}</td></tr>
+{{end}}</tbody>
+</table>
+<table class="totals">
+<tr><td class="muted">Subtotal</td><td class="num">{{.Subtotal}}</td></tr>
+{{with .Discount}}<tr><td class="muted">Discount</td><td class="num">{{.}}</td></tr>{{end}}
+<tr><td class="muted">Tax</td><td class="num">{{.Tax}}</td></tr>
+<tr><td class="muted">Total</td><td class="num">{{.Total}}</td></tr>
+{{with .AmountPaid}}<tr><td class="muted">Paid</td><td class="num">{{.}}</td></tr>{{end}}
+<tr class="balance"><td>Balance due</td><td class="num">{{.BalanceDue}}</td></tr>
+</table>
+{{with .Notes}}<section><div class="muted">Notes</div>{{.}}</section>{{end}}
+{{with .Terms}}<section><div class="muted">Terms</div>{{.}}</section>{{end}}
+<a class="pdf" href="{{.Token}}/pdf">Download PDF</a>
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type PaymentHandler struct {
+	service service.Payment
+}
+
+func NewPaymentHandler(service service.Payment) *PaymentHandler {
+	return &PaymentHandler{service: service}
+}
+
+type recordPaymentRequest struct {
+	Amount    int64   `json:"amount" binding:"required" example:"50000"`
+	Currency  *string `json:"currency" example:"USD"`
+	Method    string  `json:"method" binding:"required" example:"bank_transfer"`
+	Reference *string `json:"reference" example:"WIRE-20260315-001"`
+	PaidOn    *string `json:"paid_on" example:"2026-03-15"`
+	Notes     *string `json:"notes"`
+}
+
+type applyClientCreditRequest struct {
+	Amount *int64 `json:"amount" example:"2500"`
+}
+
+type refundPaymentRequest struct {
+	Amount    int64   `json:"amount" binding:"required" example:"10000"`
+	Method    *string `json:"method" example:"bank_transfer"`
+	Reference *string `json:"reference"`
+	PaidOn    *string `json:"paid_on" example:"2026-03-20"`
+	Notes     *string `json:"notes"`
+}
+
+// RecordPayment godoc
+// @Summary      Record payment
+// @Description  Record money received for a sent, viewed or overdue invoice, in minor units of the invoice currency. Partial payments reduce the balance due; the invoice becomes paid when nothing is left to pay. Whatever exceeds the balance due is kept as client credit. Requires a workspace admin.
+// @Tags         payment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                true  "Workspace ID"
+// @Param        invoice_id  path      string                true  "Invoice ID"
+// @Param        request     body      recordPaymentRequest  true  "Record Payment Request"
+// @Success      201         {object}  store.Payment
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id}/payments [post]
+func (h *PaymentHandler) RecordPayment(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	var req recordPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.RecordPaymentInput{
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		Method:    req.Method,
+		Reference: req.Reference,
+		PaidOn:    req.PaidOn,
+		Notes:     req.Notes,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	payment, err := h.service.RecordPayment(c.Request.Context(), userId, workspaceId, invoiceId, serviceInput)
+	if err != nil {
+		handlePaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, payment)
+}
+
+// ApplyClientCredit godoc
+// @Summary      Apply client credit
+// @Description  Pay an invoice out of the client's credit in the invoice currency. Without an amount, as much credit as covers the balance due is applied. Requires a workspace admin.
+// @Tags         payment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                    true   "Workspace ID"
+// @Param        invoice_id  path      string                    true   "Invoice ID"
+// @Param        request     body      applyClientCreditRequest  false  "Apply Client Credit Request"
+// @Success      201         {object}  store.Payment
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id}/apply-credit [post]
+func (h *PaymentHandler) ApplyClientCredit(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	var req applyClientCreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.ApplyClientCreditInput{Amount: req.Amount}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	payment, err := h.service.ApplyClientCredit(c.Request.Context(), userId, workspaceId, invoiceId, serviceInput)
+	if err != nil {
+		handlePaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, payment)
+}
+
+// RefundPayment godoc
+// @Summary      Refund payment
+// @Description  Return part or all of a payment. The refund first gives back what the payment left as client credit, while the client still holds it, and takes the rest off the invoice, which reopens a paid invoice. Refunds of credit payments put the credit back. Requires a workspace admin.
+// @Tags         payment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                true  "Workspace ID"
+// @Param        invoice_id  path      string                true  "Invoice ID"
+// @Param        payment_id  path      string                true  "Payment ID"
+// @Param        request     body      refundPaymentRequest  true  "Refund Payment Request"
+// @Success      201         {object}  store.Payment
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id}/payments/{payment_id}/refunds [post]
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+	paymentId, err := uuid.Parse(c.Param("payment_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid payment id"))
+		return
+	}
+
+	var req refundPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.RefundPaymentInput{
+		Amount:    req.Amount,
+		Method:    req.Method,
+		Reference: req.Reference,
+		PaidOn:    req.PaidOn,
+		Notes:     req.Notes,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	refund, err := h.service.RefundPayment(c.Request.Context(), userId, workspaceId, invoiceId, paymentId, serviceInput)
+	if err != nil {
+		handlePaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, refund)
+}
+
+// ListInvoicePayments godoc
+// @Summary      List invoice payments
+// @Description  List the payments and refunds of an invoice, oldest first
+// @Tags         payment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        invoice_id  path      string  true  "Invoice ID"
+// @Success      200         {array}   store.Payment
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/invoices/{invoice_id}/payments [get]
+func (h *PaymentHandler) ListInvoicePayments(c *gin.Context) {
+	userId, workspaceId, invoiceId, ok := parseInvoiceParams(c)
+	if !ok {
+		return
+	}
+
+	payments, err := h.service.ListInvoicePayments(c.Request.Context(), userId, workspaceId, invoiceId)
+	if err != nil {
+		handlePaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+// GetClientCredit godoc
+// @Summary      Get client credit
+// @Description  Get the credit a client holds in each currency and the ledger of overpayments, applications and refunds behind it
+// @Tags         payment
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string  true  "Workspace ID"
+// @Param        client_id  path      string  true  "Client ID"
+// @Success      200        {object}  service.ClientCreditSummary
+// @Failure      400        {object}  apperr.AppError
+// @Failure      401        {object}  apperr.AppError
+// @Failure      403        {object}  apperr.AppError
+// @Failure      404        {object}  apperr.AppError
+// @Failure      500        {object}  apperr.AppError
+// @Router       /workspaces/{id}/clients/{client_id}/credit [get]
+func (h *PaymentHandler) GetClientCredit(c *gin.Context) {
+	userId, workspaceId, clientId, ok := parseClientParams(c)
+	if !ok {
+		return
+	}
+
+	credit, err := h.service.GetClientCredit(c.Request.Context(), userId, workspaceId, clientId)
+	if err != nil {
+		handlePaymentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, credit)
+}
+
+func handlePaymentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrInvoiceNotFound):
+		c.Error(apperr.NotFound("invoice"))
+	case errors.Is(err, service.ErrPaymentNotFound):
+		c.Error(apperr.NotFound("payment"))
+	case errors.Is(err, service.ErrClientNotFound):
+		c.Error(apperr.NotFound("client"))
+	case errors.Is(err, service.ErrInvoiceNotPayable),
+		errors.Is(err, service.ErrInvoiceSettled),
+		errors.Is(err, service.ErrNoClientCredit),
+		errors.Is(err, service.ErrRefundExceedsPayment),
+		errors.Is(err, service.ErrRefundOfRefund):
+		c.Error(apperr.Conflict(err.Error()))
+	case errors.Is(err, service.ErrPaymentCurrencyMismatch),
+		errors.Is(err, service.ErrCreditExceedsAvailable):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
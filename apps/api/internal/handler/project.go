@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+type ProjectHandler struct {
+	service service.Project
+}
+
+func NewProjectHandler(service service.Project) *ProjectHandler {
+	return &ProjectHandler{service: service}
+}
+
+type createProjectRequest struct {
+	Name        string         `json:"name" binding:"required"`
+	Description *string        `json:"description"`
+	Status      *string        `json:"status"`
+	Priority    *string        `json:"priority"`
+	StartDate   *string        `json:"start_date" example:"2026-01-15"`
+	DueDate     *string        `json:"due_date" example:"2026-03-01"`
+	Budget      *int64         `json:"budget" example:"2500000"`
+	BudgetType  *string        `json:"budget_type" example:"fixed_fee"`
+	BudgetHours *money.Decimal `json:"budget_hours" swaggertype:"number" example:"200"`
+	HourlyRate  *int64         `json:"hourly_rate" example:"12000"`
+	Currency    *string        `json:"currency" example:"USD"`
+	Color       *string        `json:"color" example:"#2563eb"`
+	Tags        []string       `json:"tags"`
+	TeamID      *uuid.UUID     `json:"team_id"`
+	OwnerID     *uuid.UUID     `json:"owner_id"`
+	ClientID    *uuid.UUID     `json:"client_id"`
+}
+
+type updateProjectRequest struct {
+	Name        *string        `json:"name"`
+	Description *string        `json:"description"`
+	Status      *string        `json:"status"`
+	Priority    *string        `json:"priority"`
+	StartDate   *string        `json:"start_date" example:"2026-01-15"`
+	DueDate     *string        `json:"due_date" example:"2026-03-01"`
+	Budget      *int64         `json:"budget" example:"2500000"`
+	BudgetType  *string        `json:"budget_type" example:"fixed_fee"`
+	BudgetHours *money.Decimal `json:"budget_hours" swaggertype:"number" example:"200"`
+	HourlyRate  *int64         `json:"hourly_rate" example:"12000"`
+	Currency    *string        `json:"currency" example:"USD"`
+	Color       *string        `json:"color" example:"#2563eb"`
+	Tags        []string       `json:"tags"`
+	TeamID      *string        `json:"team_id"`
+	OwnerID     *uuid.UUID     `json:"owner_id"`
+	ClientID    *string        `json:"client_id"`
+}
+
+// CreateProject godoc
+// @Summary      Create project
+// @Description  Create a project in the workspace. Budget and hourly rate are in minor units of the currency, which defaults to the workspace currency. Billable time is invoiced at the hourly rate, or the workspace default rate when it is not set. A fixed_fee budget (the default) is burned by approved time and expenses against budget, an hourly budget by approved hours against budget_hours.
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                true  "Workspace ID"
+// @Param        request  body      createProjectRequest  true  "Create Project Request"
+// @Success      201      {object}  store.Project
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      402      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects [post]
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	var req createProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateProjectInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+		Priority:    req.Priority,
+		StartDate:   req.StartDate,
+		DueDate:     req.DueDate,
+		Budget:      req.Budget,
+		BudgetType:  req.BudgetType,
+		BudgetHours: req.BudgetHours,
+		HourlyRate:  req.HourlyRate,
+		Currency:    req.Currency,
+		Color:       req.Color,
+		Tags:        req.Tags,
+		TeamID:      req.TeamID,
+		OwnerID:     req.OwnerID,
+		ClientID:    req.ClientID,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	project, err := h.service.CreateProject(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+// ListProjects godoc
+// @Summary      List projects
+// @Description  List the projects of the workspace with filtering, sorting, and pagination
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      string  true   "Workspace ID"
+// @Param        limit      query     int     false  "Limit (default 20, max 100)"
+// @Param        offset     query     int     false  "Offset (default 0)"
+// @Param        sort_by    query     string  false  "Sort by: name, status, priority, start_date, due_date, budget, created_at, updated_at (default: created_at)"
+// @Param        order      query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search     query     string  false  "Search in project name or description"
+// @Param        status     query     string  false  "Filter by status: planning, in-progress, review, completed, on-hold"
+// @Param        priority   query     string  false  "Filter by priority: low, medium, high"
+// @Param        tag        query     string  false  "Filter by tag"
+// @Param        team_id    query     string  false  "Filter by team ID"
+// @Param        owner_id   query     string  false  "Filter by owner user ID"
+// @Param        client_id  query     string  false  "Filter by client ID"
+// @Success      200        {object}  store.PaginatedProjectsResponse
+// @Failure      400        {object}  apperr.AppError
+// @Failure      401        {object}  apperr.AppError
+// @Failure      403        {object}  apperr.AppError
+// @Failure      500        {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects [get]
+func (h *ProjectHandler) ListProjects(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+	bindFilters(c, &filters, "status", "priority", "tag", "team_id", "owner_id", "client_id")
+
+	projects, err := h.service.ListProjects(c.Request.Context(), userId, workspaceId, filters)
+	if err != nil {
+		handleProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+// GetProject godoc
+// @Summary      Get project
+// @Description  Get a project of the workspace
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Success      200         {object}  store.Project
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id} [get]
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.service.GetProject(c.Request.Context(), userId, workspaceId, projectId)
+	if err != nil {
+		handleProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// UpdateProject godoc
+// @Summary      Update project
+// @Description  Update a project. Only workspace admins and the project owner can change it.
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                true  "Workspace ID"
+// @Param        project_id  path      string                true  "Project ID"
+// @Param        request     body      updateProjectRequest  true  "Update Project Request"
+// @Success      200         {object}  store.Project
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id} [patch]
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateProjectInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Status:      req.Status,
+		Priority:    req.Priority,
+		StartDate:   req.StartDate,
+		DueDate:     req.DueDate,
+		Budget:      req.Budget,
+		BudgetType:  req.BudgetType,
+		BudgetHours: req.BudgetHours,
+		HourlyRate:  req.HourlyRate,
+		Currency:    req.Currency,
+		Color:       req.Color,
+		Tags:        req.Tags,
+		TeamID:      req.TeamID,
+		OwnerID:     req.OwnerID,
+		ClientID:    req.ClientID,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	project, err := h.service.UpdateProject(c.Request.Context(), userId, workspaceId, projectId, serviceInput)
+	if err != nil {
+		handleProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// DeleteProject godoc
+// @Summary      Delete project
+// @Description  Delete a project. Only workspace admins and the project owner can delete it.
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Success      200         {object}  map[string]string
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id} [delete]
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteProject(c.Request.Context(), userId, workspaceId, projectId); err != nil {
+		handleProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "project deleted"})
+}
+
+func parseProjectParams(c *gin.Context) (userId, workspaceId, projectId uuid.UUID, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	projectId, err = uuid.Parse(c.Param("project_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid project id"))
+		return
+	}
+
+	return userId, workspaceId, projectId, true
+}
+
+func handleProjectError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.Error(apperr.NotFound("project"))
+	case errors.Is(err, service.ErrTeamNotFound):
+		c.Error(apperr.NotFound("team"))
+	case errors.Is(err, service.ErrClientNotFound):
+		c.Error(apperr.NotFound("client"))
+	case errors.Is(err, service.ErrNotWorkspaceMember),
+		errors.Is(err, service.ErrInvalidProjectDates),
+		errors.Is(err, service.ErrInvalidFilter):
+		c.Error(apperr.BadRequest(err.Error()))
+	case errors.Is(err, service.ErrQuotaExceeded):
+		c.Error(apperr.QuotaExceeded(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/service"
+)
+
+type ProjectBudgetHandler struct {
+	service service.ProjectBudget
+}
+
+func NewProjectBudgetHandler(service service.ProjectBudget) *ProjectBudgetHandler {
+	return &ProjectBudgetHandler{service: service}
+}
+
+// GetProjectBudget godoc
+// @Summary      Get project budget
+// @Description  Report how much of the project budget is used, in minor units of the project currency. Approved time is priced at the task rate, else the member rate, else the project rate, else the workspace default rate, and approved expenses are added on top; rates and expenses in another currency are converted at the rate of their own date. Fixed-fee budgets burn by money spent, hourly budgets by approved hours. Visible to workspace owners and admins and the project owner.
+// @Tags         project
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Success      200         {object}  service.ProjectBudgetBurn
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/budget [get]
+func (h *ProjectBudgetHandler) GetProjectBudget(c *gin.Context) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	burn, err := h.service.GetProjectBudget(c.Request.Context(), userId, workspaceId, projectId)
+	if err != nil {
+		handleProjectError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, burn)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type ReportHandler struct {
+	service service.Report
+}
+
+func NewReportHandler(service service.Report) *ReportHandler {
+	return &ReportHandler{service: service}
+}
+
+type revenueReportQuery struct {
+	From string `form:"from"`
+	To   string `form:"to"`
+}
+
+// GetRevenueReport godoc
+// @Summary      Get revenue report
+// @Description  Sum the invoices issued, the money received and the approved expenses between from and to, inclusive, in the workspace base currency, which is its default currency. Every invoice is converted at the exchange rate of its issue date, every payment at the rate of the day it was made and every expense at the rate of the day it was spent; totals are also broken down by currency, client and month. Requires a workspace admin.
+// @Tags         report
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string  true  "Workspace ID"
+// @Param        from  query     string  true  "First day (YYYY-MM-DD)"
+// @Param        to    query     string  true  "Last day (YYYY-MM-DD), at most 366 days after from"
+// @Success      200   {object}  service.RevenueReport
+// @Failure      400   {object}  apperr.AppError
+// @Failure      401   {object}  apperr.AppError
+// @Failure      403   {object}  apperr.AppError
+// @Failure      404   {object}  apperr.AppError
+// @Failure      422   {object}  apperr.AppError
+// @Failure      500   {object}  apperr.AppError
+// @Router       /workspaces/{id}/reports/revenue [get]
+func (h *ReportHandler) GetRevenueReport(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var query revenueReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.RevenueReportInput{From: query.From, To: query.To}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := h.service.GetRevenueReport(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleReportError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func handleReportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrInvalidReportPeriod):
+		c.Error(apperr.BadRequest(err.Error()))
+	case errors.Is(err, service.ErrExchangeRateUnavailable):
+		c.Error(apperr.New(http.StatusUnprocessableEntity, err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
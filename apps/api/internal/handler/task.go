@@ -0,0 +1,739 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type TaskHandler struct {
+	service service.Task
+}
+
+func NewTaskHandler(service service.Task) *TaskHandler {
+	return &TaskHandler{service: service}
+}
+
+type createTaskRequest struct {
+	Title          string     `json:"title" binding:"required"`
+	Description    *string    `json:"description"`
+	Status         *string    `json:"status" example:"todo"`
+	Priority       *string    `json:"priority" example:"medium"`
+	AssigneeID     *uuid.UUID `json:"assignee_id"`
+	DueDate        *string    `json:"due_date" example:"2026-03-01"`
+	EstimatedHours *float64   `json:"estimated_hours" example:"4.5"`
+	HourlyRate     *int64     `json:"hourly_rate" example:"9000"`
+	Tags           []string   `json:"tags"`
+}
+
+type updateTaskRequest struct {
+	Title          *string  `json:"title"`
+	Description    *string  `json:"description"`
+	Priority       *string  `json:"priority" example:"high"`
+	AssigneeID     *string  `json:"assignee_id"`
+	DueDate        *string  `json:"due_date" example:"2026-03-01"`
+	EstimatedHours *float64 `json:"estimated_hours" example:"4.5"`
+	HourlyRate     *int64   `json:"hourly_rate" example:"9000"`
+	Tags           []string `json:"tags"`
+}
+
+type updateTaskStatusRequest struct {
+	Status string `json:"status" binding:"required" example:"in-progress"`
+}
+
+type moveTaskRequest struct {
+	Status   *string    `json:"status" example:"review"`
+	BeforeID *uuid.UUID `json:"before_id"`
+	AfterID  *uuid.UUID `json:"after_id"`
+}
+
+type moveTasksRequest struct {
+	TaskIDs  []uuid.UUID `json:"task_ids" binding:"required"`
+	Status   *string     `json:"status" example:"review"`
+	BeforeID *uuid.UUID  `json:"before_id"`
+	AfterID  *uuid.UUID  `json:"after_id"`
+}
+
+type taskTagsRequest struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+type createSubtaskRequest struct {
+	Title string `json:"title" binding:"required"`
+}
+
+type updateSubtaskRequest struct {
+	Title     *string `json:"title"`
+	Completed *bool   `json:"completed"`
+}
+
+// CreateTask godoc
+// @Summary      Create task
+// @Description  Create a task in the project. The assignee must be an active workspace member. hourly_rate prices time on the task for the project budget and can only be set by workspace owners and admins and the project owner.
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string             true  "Workspace ID"
+// @Param        project_id  path      string             true  "Project ID"
+// @Param        request     body      createTaskRequest  true  "Create Task Request"
+// @Success      201         {object}  store.Task
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks [post]
+func (h *TaskHandler) CreateTask(c *gin.Context) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	var req createTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateTaskInput{
+		Title:          req.Title,
+		Description:    req.Description,
+		Status:         req.Status,
+		Priority:       req.Priority,
+		AssigneeID:     req.AssigneeID,
+		DueDate:        req.DueDate,
+		EstimatedHours: req.EstimatedHours,
+		HourlyRate:     req.HourlyRate,
+		Tags:           req.Tags,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	task, err := h.service.CreateTask(c.Request.Context(), userId, workspaceId, projectId, serviceInput)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
+
+// ListTasks godoc
+// @Summary      List tasks
+// @Description  List the tasks of a project with filtering, sorting, and pagination
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id           path      string  true   "Workspace ID"
+// @Param        project_id   path      string  true   "Project ID"
+// @Param        limit        query     int     false  "Limit (default 20, max 100)"
+// @Param        offset       query     int     false  "Offset (default 0)"
+// @Param        sort_by      query     string  false  "Sort by: title, status, priority, rank, due_date, created_at, updated_at (default: created_at). Use rank with order=asc for board order"
+// @Param        order        query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search       query     string  false  "Search in task title or description"
+// @Param        status       query     string  false  "Filter by status: todo, in-progress, review, done"
+// @Param        priority     query     string  false  "Filter by priority: low, medium, high, urgent"
+// @Param        tag          query     string  false  "Filter by tag"
+// @Param        assignee_id  query     string  false  "Filter by assignee user ID, 'me' or 'none'"
+// @Param        blocked      query     bool    false  "Filter by whether the task waits on an unfinished dependency"
+// @Success      200          {object}  store.PaginatedTasksResponse
+// @Failure      400          {object}  apperr.AppError
+// @Failure      401          {object}  apperr.AppError
+// @Failure      403          {object}  apperr.AppError
+// @Failure      404          {object}  apperr.AppError
+// @Failure      500          {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks [get]
+func (h *TaskHandler) ListTasks(c *gin.Context) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+	bindFilters(c, &filters, "status", "priority", "tag", "assignee_id", "blocked")
+
+	tasks, err := h.service.ListTasks(c.Request.Context(), userId, workspaceId, projectId, filters)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// GetTask godoc
+// @Summary      Get task
+// @Description  Get a task with its subtasks
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Param        task_id     path      string  true  "Task ID"
+// @Success      200         {object}  store.Task
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id} [get]
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	task, err := h.service.GetTask(c.Request.Context(), userId, workspaceId, projectId, taskId)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// UpdateTask godoc
+// @Summary      Update task
+// @Description  Update a task. Empty assignee_id or due_date values clear them. Use the status endpoint to change the status. Only workspace owners and admins and the project owner can change hourly_rate.
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string             true  "Workspace ID"
+// @Param        project_id  path      string             true  "Project ID"
+// @Param        task_id     path      string             true  "Task ID"
+// @Param        request     body      updateTaskRequest  true  "Update Task Request"
+// @Success      200         {object}  store.Task
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id} [patch]
+func (h *TaskHandler) UpdateTask(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateTaskInput{
+		Title:          req.Title,
+		Description:    req.Description,
+		Priority:       req.Priority,
+		AssigneeID:     req.AssigneeID,
+		DueDate:        req.DueDate,
+		EstimatedHours: req.EstimatedHours,
+		HourlyRate:     req.HourlyRate,
+		Tags:           req.Tags,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	task, err := h.service.UpdateTask(c.Request.Context(), userId, workspaceId, projectId, taskId, serviceInput)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// UpdateTaskStatus godoc
+// @Summary      Change task status
+// @Description  Move a task along the workflow: todo → in-progress → review → done. Tasks can move back to in-progress, and from in-progress back to todo.
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                   true  "Workspace ID"
+// @Param        project_id  path      string                   true  "Project ID"
+// @Param        task_id     path      string                   true  "Task ID"
+// @Param        request     body      updateTaskStatusRequest  true  "Update Task Status Request"
+// @Success      200         {object}  store.Task
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/status [post]
+func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateTaskStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateTaskStatusInput{
+		Status: req.Status,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	task, err := h.service.UpdateTaskStatus(c.Request.Context(), userId, workspaceId, projectId, taskId, serviceInput)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// DeleteTask godoc
+// @Summary      Delete task
+// @Description  Delete a task. Only workspace admins, the project owner and the task creator can delete it.
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Param        task_id     path      string  true  "Task ID"
+// @Success      200         {object}  map[string]string
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id} [delete]
+func (h *TaskHandler) DeleteTask(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTask(c.Request.Context(), userId, workspaceId, projectId, taskId); err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "task deleted"})
+}
+
+// MoveTask godoc
+// @Summary      Move task on the board
+// @Description  Place a task between two neighbours of a board column, optionally changing its status. before_id is the task that ends up directly above it and after_id the one directly below. With one neighbour the other is looked up; with none the task goes to the bottom of the column.
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string           true  "Workspace ID"
+// @Param        project_id  path      string           true  "Project ID"
+// @Param        task_id     path      string           true  "Task ID"
+// @Param        request     body      moveTaskRequest  true  "Move Task Request"
+// @Success      200         {object}  store.Task
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/move [post]
+func (h *TaskHandler) MoveTask(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	var req moveTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.MoveTaskInput{
+		Status:   req.Status,
+		BeforeID: req.BeforeID,
+		AfterID:  req.AfterID,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	task, err := h.service.MoveTask(c.Request.Context(), userId, workspaceId, projectId, taskId, serviceInput)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// MoveTasks godoc
+// @Summary      Move several tasks on the board
+// @Description  Move up to 100 tasks as one block between two neighbours of a board column, keeping the order of task_ids. Neighbours work as for a single move.
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string            true  "Workspace ID"
+// @Param        project_id  path      string            true  "Project ID"
+// @Param        request     body      moveTasksRequest  true  "Move Tasks Request"
+// @Success      200         {array}   store.Task
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/move [post]
+func (h *TaskHandler) MoveTasks(c *gin.Context) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	var req moveTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.MoveTasksInput{
+		TaskIDs:  req.TaskIDs,
+		Status:   req.Status,
+		BeforeID: req.BeforeID,
+		AfterID:  req.AfterID,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	tasks, err := h.service.MoveTasks(c.Request.Context(), userId, workspaceId, projectId, serviceInput)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// AddTaskTags godoc
+// @Summary      Add task tags
+// @Description  Add tags to a task. Tags the task already has are ignored regardless of case.
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string           true  "Workspace ID"
+// @Param        project_id  path      string           true  "Project ID"
+// @Param        task_id     path      string           true  "Task ID"
+// @Param        request     body      taskTagsRequest  true  "Task Tags Request"
+// @Success      200         {object}  store.Task
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/tags [post]
+func (h *TaskHandler) AddTaskTags(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	var req taskTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.TaskTagsInput{
+		Tags: req.Tags,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	task, err := h.service.AddTaskTags(c.Request.Context(), userId, workspaceId, projectId, taskId, serviceInput)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// RemoveTaskTag godoc
+// @Summary      Remove task tag
+// @Description  Remove a tag from a task, ignoring case
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Param        task_id     path      string  true  "Task ID"
+// @Param        tag         path      string  true  "Tag"
+// @Success      200         {object}  store.Task
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/tags/{tag} [delete]
+func (h *TaskHandler) RemoveTaskTag(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	task, err := h.service.RemoveTaskTag(c.Request.Context(), userId, workspaceId, projectId, taskId, c.Param("tag"))
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// ListTaskTags godoc
+// @Summary      List task tags
+// @Description  List the tags used on the workspace's tasks with their usage counts, most used first
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      200  {array}   store.TagUsage
+// @Failure      400  {object}  apperr.AppError
+// @Failure      401  {object}  apperr.AppError
+// @Failure      403  {object}  apperr.AppError
+// @Failure      500  {object}  apperr.AppError
+// @Router       /workspaces/{id}/task-tags [get]
+func (h *TaskHandler) ListTaskTags(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	tags, err := h.service.ListTaskTags(c.Request.Context(), userId, workspaceId)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// CreateSubtask godoc
+// @Summary      Create subtask
+// @Description  Append a checklist item to a task
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                true  "Workspace ID"
+// @Param        project_id  path      string                true  "Project ID"
+// @Param        task_id     path      string                true  "Task ID"
+// @Param        request     body      createSubtaskRequest  true  "Create Subtask Request"
+// @Success      201         {object}  store.Subtask
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/subtasks [post]
+func (h *TaskHandler) CreateSubtask(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	var req createSubtaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateSubtaskInput{
+		Title: req.Title,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	subtask, err := h.service.CreateSubtask(c.Request.Context(), userId, workspaceId, projectId, taskId, serviceInput)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subtask)
+}
+
+// UpdateSubtask godoc
+// @Summary      Update subtask
+// @Description  Rename a checklist item or check it off
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                true  "Workspace ID"
+// @Param        project_id  path      string                true  "Project ID"
+// @Param        task_id     path      string                true  "Task ID"
+// @Param        subtask_id  path      string                true  "Subtask ID"
+// @Param        request     body      updateSubtaskRequest  true  "Update Subtask Request"
+// @Success      200         {object}  store.Subtask
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/subtasks/{subtask_id} [patch]
+func (h *TaskHandler) UpdateSubtask(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	subtaskId, err := uuid.Parse(c.Param("subtask_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid subtask id"))
+		return
+	}
+
+	var req updateSubtaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateSubtaskInput{
+		Title:     req.Title,
+		Completed: req.Completed,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	subtask, err := h.service.UpdateSubtask(c.Request.Context(), userId, workspaceId, projectId, taskId, subtaskId, serviceInput)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subtask)
+}
+
+// DeleteSubtask godoc
+// @Summary      Delete subtask
+// @Description  Remove a checklist item from a task
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Param        task_id     path      string  true  "Task ID"
+// @Param        subtask_id  path      string  true  "Subtask ID"
+// @Success      200         {object}  map[string]string
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/subtasks/{subtask_id} [delete]
+func (h *TaskHandler) DeleteSubtask(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	subtaskId, err := uuid.Parse(c.Param("subtask_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid subtask id"))
+		return
+	}
+
+	if err := h.service.DeleteSubtask(c.Request.Context(), userId, workspaceId, projectId, taskId, subtaskId); err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "subtask deleted"})
+}
+
+func parseTaskParams(c *gin.Context) (userId, workspaceId, projectId, taskId uuid.UUID, ok bool) {
+	userId, workspaceId, projectId, ok = parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	taskId, err := uuid.Parse(c.Param("task_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid task id"))
+		return userId, workspaceId, projectId, taskId, false
+	}
+
+	return userId, workspaceId, projectId, taskId, true
+}
+
+func handleTaskError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.Error(apperr.NotFound("project"))
+	case errors.Is(err, service.ErrTaskNotFound):
+		c.Error(apperr.NotFound("task"))
+	case errors.Is(err, service.ErrSubtaskNotFound):
+		c.Error(apperr.NotFound("subtask"))
+	case errors.Is(err, service.ErrDependencyNotFound):
+		c.Error(apperr.NotFound("dependency"))
+	case errors.Is(err, service.ErrTaskStatusConflict),
+		errors.Is(err, service.ErrDependencyExists):
+		c.Error(apperr.Conflict(err.Error()))
+	case errors.Is(err, service.ErrNotWorkspaceMember),
+		errors.Is(err, service.ErrDependencyCycle),
+		errors.Is(err, service.ErrInvalidDependency),
+		errors.Is(err, service.ErrInvalidStatusTransition),
+		errors.Is(err, service.ErrInvalidMove),
+		errors.Is(err, service.ErrTooManyTags),
+		errors.Is(err, service.ErrInvalidFilter):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type addTaskDependencyRequest struct {
+	DependsOnID uuid.UUID `json:"depends_on_id" binding:"required"`
+}
+
+// ListTaskDependencies godoc
+// @Summary      List task dependencies
+// @Description  List the tasks a task is blocked by and the tasks it blocks
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Param        task_id     path      string  true  "Task ID"
+// @Success      200         {object}  service.TaskDependencies
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/dependencies [get]
+func (h *TaskHandler) ListTaskDependencies(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	dependencies, err := h.service.ListTaskDependencies(c.Request.Context(), userId, workspaceId, projectId, taskId)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dependencies)
+}
+
+// AddTaskDependency godoc
+// @Summary      Add task dependency
+// @Description  Mark the task as blocked by another task of the same project. The task stays blocked until that task is done. Dependencies that would create a cycle are rejected.
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                    true  "Workspace ID"
+// @Param        project_id  path      string                    true  "Project ID"
+// @Param        task_id     path      string                    true  "Task ID"
+// @Param        request     body      addTaskDependencyRequest  true  "Add Task Dependency Request"
+// @Success      201         {object}  store.TaskDependency
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      409         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/dependencies [post]
+func (h *TaskHandler) AddTaskDependency(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	var req addTaskDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.AddTaskDependencyInput{
+		DependsOnID: req.DependsOnID,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	dependency, err := h.service.AddTaskDependency(c.Request.Context(), userId, workspaceId, projectId, taskId, serviceInput)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dependency)
+}
+
+// RemoveTaskDependency godoc
+// @Summary      Remove task dependency
+// @Description  Remove a dependency of a task
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string  true  "Workspace ID"
+// @Param        project_id     path      string  true  "Project ID"
+// @Param        task_id        path      string  true  "Task ID"
+// @Param        depends_on_id  path      string  true  "ID of the blocking task"
+// @Success      200            {object}  map[string]string
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      404            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/tasks/{task_id}/dependencies/{depends_on_id} [delete]
+func (h *TaskHandler) RemoveTaskDependency(c *gin.Context) {
+	userId, workspaceId, projectId, taskId, ok := parseTaskParams(c)
+	if !ok {
+		return
+	}
+
+	dependsOnId, err := uuid.Parse(c.Param("depends_on_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid task id"))
+		return
+	}
+
+	if err := h.service.RemoveTaskDependency(c.Request.Context(), userId, workspaceId, projectId, taskId, dependsOnId); err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "dependency removed"})
+}
+
+// GetProjectSchedule godoc
+// @Summary      Get project schedule
+// @Description  Compute the critical path and earliest finish date of the project's open tasks from their remaining estimated hours. Work starts at the later of the project start date and today, at the workspace's working hours per day, Monday to Friday. Tasks without an estimate count as zero hours.
+// @Tags         task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Workspace ID"
+// @Param        project_id  path      string  true  "Project ID"
+// @Success      200         {object}  service.ProjectSchedule
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/schedule [get]
+func (h *TaskHandler) GetProjectSchedule(c *gin.Context) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.service.GetProjectSchedule(c.Request.Context(), userId, workspaceId, projectId)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
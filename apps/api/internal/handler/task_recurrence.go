@@ -0,0 +1,432 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type RecurringTaskHandler struct {
+	service service.RecurringTask
+}
+
+func NewRecurringTaskHandler(service service.RecurringTask) *RecurringTaskHandler {
+	return &RecurringTaskHandler{service: service}
+}
+
+type createRecurringTaskRequest struct {
+	Title          string     `json:"title" binding:"required"`
+	Description    *string    `json:"description"`
+	Priority       *string    `json:"priority" example:"medium"`
+	AssigneeID     *uuid.UUID `json:"assignee_id"`
+	EstimatedHours *float64   `json:"estimated_hours" example:"2"`
+	Tags           []string   `json:"tags"`
+	RRule          string     `json:"rrule" binding:"required" example:"FREQ=MONTHLY;BYMONTHDAY=-1"`
+	StartDate      *string    `json:"start_date" example:"2026-01-01"`
+}
+
+type updateRecurringTaskRequest struct {
+	Title          *string  `json:"title"`
+	Description    *string  `json:"description"`
+	Priority       *string  `json:"priority" example:"high"`
+	AssigneeID     *string  `json:"assignee_id"`
+	EstimatedHours *float64 `json:"estimated_hours" example:"2"`
+	Tags           []string `json:"tags"`
+	RRule          *string  `json:"rrule" example:"FREQ=MONTHLY;BYDAY=1MO"`
+	StartDate      *string  `json:"start_date" example:"2026-01-01"`
+}
+
+type updateOccurrenceRequest struct {
+	Skipped        bool       `json:"skipped"`
+	Title          *string    `json:"title"`
+	Description    *string    `json:"description"`
+	Priority       *string    `json:"priority" example:"urgent"`
+	AssigneeID     *uuid.UUID `json:"assignee_id"`
+	EstimatedHours *float64   `json:"estimated_hours" example:"3"`
+	DueDate        *string    `json:"due_date" example:"2026-03-02"`
+}
+
+type listOccurrencesQuery struct {
+	Limit int `form:"limit"`
+}
+
+// CreateRecurringTask godoc
+// @Summary      Create recurring task
+// @Description  Create a task template repeated by an iCalendar RRULE, such as FREQ=MONTHLY;BYMONTHDAY=-1 or FREQ=WEEKLY;BYDAY=MO,TH. Dates are read in the workspace timezone and the rule starts today unless start_date is given. The first occurrence is created right away; each following one when the previous one is done or when its date arrives.
+// @Tags         recurring-task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                      true  "Workspace ID"
+// @Param        project_id  path      string                      true  "Project ID"
+// @Param        request     body      createRecurringTaskRequest  true  "Create Recurring Task Request"
+// @Success      201         {object}  store.TaskRecurrence
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/recurring-tasks [post]
+func (h *RecurringTaskHandler) CreateRecurringTask(c *gin.Context) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	var req createRecurringTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateRecurringTaskInput{
+		Title:          req.Title,
+		Description:    req.Description,
+		Priority:       req.Priority,
+		AssigneeID:     req.AssigneeID,
+		EstimatedHours: req.EstimatedHours,
+		Tags:           req.Tags,
+		RRule:          req.RRule,
+		StartDate:      req.StartDate,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	recurrence, err := h.service.CreateRecurringTask(c.Request.Context(), userId, workspaceId, projectId, serviceInput)
+	if err != nil {
+		handleRecurringTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, recurrence)
+}
+
+// ListRecurringTasks godoc
+// @Summary      List recurring tasks
+// @Description  List the recurring task templates of a project
+// @Tags         recurring-task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true   "Workspace ID"
+// @Param        project_id  path      string  true   "Project ID"
+// @Param        limit       query     int     false  "Limit (default 20, max 100)"
+// @Param        offset      query     int     false  "Offset (default 0)"
+// @Param        sort_by     query     string  false  "Sort by: title, priority, next_date, created_at, updated_at (default: created_at)"
+// @Param        order       query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search      query     string  false  "Search in title or description"
+// @Success      200         {object}  store.PaginatedTaskRecurrencesResponse
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/recurring-tasks [get]
+func (h *RecurringTaskHandler) ListRecurringTasks(c *gin.Context) {
+	userId, workspaceId, projectId, ok := parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+
+	recurrences, err := h.service.ListRecurringTasks(c.Request.Context(), userId, workspaceId, projectId, filters)
+	if err != nil {
+		handleRecurringTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrences)
+}
+
+// GetRecurringTask godoc
+// @Summary      Get recurring task
+// @Description  Get a recurring task template with its next occurrence date
+// @Tags         recurring-task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string  true  "Workspace ID"
+// @Param        project_id     path      string  true  "Project ID"
+// @Param        recurrence_id  path      string  true  "Recurring Task ID"
+// @Success      200            {object}  store.TaskRecurrence
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      404            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/recurring-tasks/{recurrence_id} [get]
+func (h *RecurringTaskHandler) GetRecurringTask(c *gin.Context) {
+	userId, workspaceId, projectId, recurrenceId, ok := parseRecurringTaskParams(c)
+	if !ok {
+		return
+	}
+
+	recurrence, err := h.service.GetRecurringTask(c.Request.Context(), userId, workspaceId, projectId, recurrenceId)
+	if err != nil {
+		handleRecurringTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrence)
+}
+
+// UpdateRecurringTask godoc
+// @Summary      Update recurring task
+// @Description  Update a recurring task template. Changes apply to occurrences that have not been created yet. A new rrule or start_date reschedules the next occurrence; an empty assignee_id clears it.
+// @Tags         recurring-task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string                      true  "Workspace ID"
+// @Param        project_id     path      string                      true  "Project ID"
+// @Param        recurrence_id  path      string                      true  "Recurring Task ID"
+// @Param        request        body      updateRecurringTaskRequest  true  "Update Recurring Task Request"
+// @Success      200            {object}  store.TaskRecurrence
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      404            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/recurring-tasks/{recurrence_id} [patch]
+func (h *RecurringTaskHandler) UpdateRecurringTask(c *gin.Context) {
+	userId, workspaceId, projectId, recurrenceId, ok := parseRecurringTaskParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateRecurringTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateRecurringTaskInput{
+		Title:          req.Title,
+		Description:    req.Description,
+		Priority:       req.Priority,
+		AssigneeID:     req.AssigneeID,
+		EstimatedHours: req.EstimatedHours,
+		Tags:           req.Tags,
+		RRule:          req.RRule,
+		StartDate:      req.StartDate,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	recurrence, err := h.service.UpdateRecurringTask(c.Request.Context(), userId, workspaceId, projectId, recurrenceId, serviceInput)
+	if err != nil {
+		handleRecurringTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recurrence)
+}
+
+// DeleteRecurringTask godoc
+// @Summary      Delete recurring task
+// @Description  Stop a recurring task. Tasks already created are kept. Limited to workspace admins, the project owner and the creator.
+// @Tags         recurring-task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string  true  "Workspace ID"
+// @Param        project_id     path      string  true  "Project ID"
+// @Param        recurrence_id  path      string  true  "Recurring Task ID"
+// @Success      200            {object}  map[string]string
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      404            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/recurring-tasks/{recurrence_id} [delete]
+func (h *RecurringTaskHandler) DeleteRecurringTask(c *gin.Context) {
+	userId, workspaceId, projectId, recurrenceId, ok := parseRecurringTaskParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRecurringTask(c.Request.Context(), userId, workspaceId, projectId, recurrenceId); err != nil {
+		handleRecurringTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "recurring task deleted"})
+}
+
+// ListOccurrences godoc
+// @Summary      List upcoming occurrences
+// @Description  Preview the occurrences of a recurring task that have not been created yet, with skipped and edited occurrences marked
+// @Tags         recurring-task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string  true   "Workspace ID"
+// @Param        project_id     path      string  true   "Project ID"
+// @Param        recurrence_id  path      string  true   "Recurring Task ID"
+// @Param        limit          query     int     false  "Number of occurrences (default 10, max 50)"
+// @Success      200            {array}   service.RecurrenceOccurrence
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      404            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/recurring-tasks/{recurrence_id}/occurrences [get]
+func (h *RecurringTaskHandler) ListOccurrences(c *gin.Context) {
+	userId, workspaceId, projectId, recurrenceId, ok := parseRecurringTaskParams(c)
+	if !ok {
+		return
+	}
+
+	var query listOccurrencesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(apperr.BadRequest("invalid limit"))
+		return
+	}
+
+	occurrences, err := h.service.ListOccurrences(c.Request.Context(), userId, workspaceId, projectId, recurrenceId, query.Limit)
+	if err != nil {
+		handleRecurringTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, occurrences)
+}
+
+// UpdateOccurrence godoc
+// @Summary      Skip or edit an occurrence
+// @Description  Skip a single upcoming occurrence, or override the task it will create. Replaces any previous override of that occurrence. Occurrences that were already created are edited as tasks.
+// @Tags         recurring-task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string                   true  "Workspace ID"
+// @Param        project_id     path      string                   true  "Project ID"
+// @Param        recurrence_id  path      string                   true  "Recurring Task ID"
+// @Param        date           path      string                   true  "Occurrence date (YYYY-MM-DD)"
+// @Param        request        body      updateOccurrenceRequest  true  "Update Occurrence Request"
+// @Success      200            {object}  store.RecurrenceException
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      404            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/recurring-tasks/{recurrence_id}/occurrences/{date} [put]
+func (h *RecurringTaskHandler) UpdateOccurrence(c *gin.Context) {
+	userId, workspaceId, projectId, recurrenceId, ok := parseRecurringTaskParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateOccurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateOccurrenceInput{
+		Skipped:        req.Skipped,
+		Title:          req.Title,
+		Description:    req.Description,
+		Priority:       req.Priority,
+		AssigneeID:     req.AssigneeID,
+		EstimatedHours: req.EstimatedHours,
+		DueDate:        req.DueDate,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	exception, err := h.service.UpdateOccurrence(c.Request.Context(), userId, workspaceId, projectId, recurrenceId, c.Param("date"), serviceInput)
+	if err != nil {
+		handleRecurringTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, exception)
+}
+
+// ResetOccurrence godoc
+// @Summary      Reset an occurrence
+// @Description  Remove the skip or override of an upcoming occurrence so it follows the template again
+// @Tags         recurring-task
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id             path      string  true  "Workspace ID"
+// @Param        project_id     path      string  true  "Project ID"
+// @Param        recurrence_id  path      string  true  "Recurring Task ID"
+// @Param        date           path      string  true  "Occurrence date (YYYY-MM-DD)"
+// @Success      200            {object}  map[string]string
+// @Failure      400            {object}  apperr.AppError
+// @Failure      401            {object}  apperr.AppError
+// @Failure      403            {object}  apperr.AppError
+// @Failure      404            {object}  apperr.AppError
+// @Failure      500            {object}  apperr.AppError
+// @Router       /workspaces/{id}/projects/{project_id}/recurring-tasks/{recurrence_id}/occurrences/{date} [delete]
+func (h *RecurringTaskHandler) ResetOccurrence(c *gin.Context) {
+	userId, workspaceId, projectId, recurrenceId, ok := parseRecurringTaskParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ResetOccurrence(c.Request.Context(), userId, workspaceId, projectId, recurrenceId, c.Param("date")); err != nil {
+		handleRecurringTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "occurrence reset"})
+}
+
+func parseRecurringTaskParams(c *gin.Context) (userId, workspaceId, projectId, recurrenceId uuid.UUID, ok bool) {
+	userId, workspaceId, projectId, ok = parseProjectParams(c)
+	if !ok {
+		return
+	}
+
+	recurrenceId, err := uuid.Parse(c.Param("recurrence_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid recurring task id"))
+		return userId, workspaceId, projectId, recurrenceId, false
+	}
+
+	return userId, workspaceId, projectId, recurrenceId, true
+}
+
+func handleRecurringTaskError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.Error(apperr.NotFound("project"))
+	case errors.Is(err, service.ErrRecurrenceNotFound):
+		c.Error(apperr.NotFound("recurring task"))
+	case errors.Is(err, service.ErrOccurrenceOverrideNotFound):
+		c.Error(apperr.NotFound("occurrence override"))
+	case errors.Is(err, service.ErrNotWorkspaceMember),
+		errors.Is(err, service.ErrInvalidRecurrenceRule),
+		errors.Is(err, service.ErrUnsupportedRecurrenceRule),
+		errors.Is(err, service.ErrNoUpcomingOccurrences),
+		errors.Is(err, service.ErrInvalidOccurrence),
+		errors.Is(err, service.ErrInvalidFilter):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
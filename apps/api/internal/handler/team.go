@@ -0,0 +1,391 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type TeamHandler struct {
+	service service.Team
+}
+
+func NewTeamHandler(service service.Team) *TeamHandler {
+	return &TeamHandler{service: service}
+}
+
+type createTeamRequest struct {
+	Name        string  `json:"name" binding:"required"`
+	Description *string `json:"description"`
+	RoleGrant   *string `json:"role_grant"`
+}
+
+type updateTeamRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	RoleGrant   *string `json:"role_grant"`
+}
+
+type addTeamMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// CreateTeam godoc
+// @Summary      Create team
+// @Description  Create a team inside the workspace. A role grant raises the workspace role of every team member.
+// @Tags         team
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string             true  "Workspace ID"
+// @Param        request  body      createTeamRequest  true  "Create Team Request"
+// @Success      201      {object}  store.Team
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/teams [post]
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	var req createTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateTeamInput{
+		Name:        req.Name,
+		Description: req.Description,
+		RoleGrant:   req.RoleGrant,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	team, err := h.service.CreateTeam(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, team)
+}
+
+// ListTeams godoc
+// @Summary      List teams
+// @Description  List the teams of the workspace with pagination and search
+// @Tags         team
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string  true   "Workspace ID"
+// @Param        limit    query     int     false  "Limit (default 20, max 100)"
+// @Param        offset   query     int     false  "Offset (default 0)"
+// @Param        sort_by  query     string  false  "Sort by: name, created_at, updated_at (default: created_at)"
+// @Param        order    query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search   query     string  false  "Search in team name or description"
+// @Success      200      {object}  store.PaginatedTeamsResponse
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/teams [get]
+func (h *TeamHandler) ListTeams(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+
+	teams, err := h.service.ListTeams(c.Request.Context(), userId, workspaceId, filters)
+	if err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, teams)
+}
+
+// GetTeam godoc
+// @Summary      Get team
+// @Description  Get a team of the workspace
+// @Tags         team
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string  true  "Workspace ID"
+// @Param        team_id  path      string  true  "Team ID"
+// @Success      200      {object}  store.Team
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/teams/{team_id} [get]
+func (h *TeamHandler) GetTeam(c *gin.Context) {
+	userId, workspaceId, teamId, ok := parseTeamParams(c)
+	if !ok {
+		return
+	}
+
+	team, err := h.service.GetTeam(c.Request.Context(), userId, workspaceId, teamId)
+	if err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// UpdateTeam godoc
+// @Summary      Update team
+// @Description  Update a team. Send an empty role_grant to remove the team's grant.
+// @Tags         team
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string             true  "Workspace ID"
+// @Param        team_id  path      string             true  "Team ID"
+// @Param        request  body      updateTeamRequest  true  "Update Team Request"
+// @Success      200      {object}  store.Team
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/teams/{team_id} [patch]
+func (h *TeamHandler) UpdateTeam(c *gin.Context) {
+	userId, workspaceId, teamId, ok := parseTeamParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateTeamInput{
+		Name:        req.Name,
+		Description: req.Description,
+		RoleGrant:   req.RoleGrant,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	team, err := h.service.UpdateTeam(c.Request.Context(), userId, workspaceId, teamId, serviceInput)
+	if err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, team)
+}
+
+// DeleteTeam godoc
+// @Summary      Delete team
+// @Description  Delete a team and all of its memberships
+// @Tags         team
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string  true  "Workspace ID"
+// @Param        team_id  path      string  true  "Team ID"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/teams/{team_id} [delete]
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	userId, workspaceId, teamId, ok := parseTeamParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTeam(c.Request.Context(), userId, workspaceId, teamId); err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "team deleted"})
+}
+
+// ListTeamMembers godoc
+// @Summary      List team members
+// @Description  List the members of a team
+// @Tags         team
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string  true  "Workspace ID"
+// @Param        team_id  path      string  true  "Team ID"
+// @Success      200      {array}   store.TeamMember
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/teams/{team_id}/members [get]
+func (h *TeamHandler) ListTeamMembers(c *gin.Context) {
+	userId, workspaceId, teamId, ok := parseTeamParams(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.service.ListTeamMembers(c.Request.Context(), userId, workspaceId, teamId)
+	if err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddTeamMember godoc
+// @Summary      Add team member
+// @Description  Add a workspace member to a team
+// @Tags         team
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                true  "Workspace ID"
+// @Param        team_id  path      string                true  "Team ID"
+// @Param        request  body      addTeamMemberRequest  true  "Add Team Member Request"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/teams/{team_id}/members [post]
+func (h *TeamHandler) AddTeamMember(c *gin.Context) {
+	userId, workspaceId, teamId, ok := parseTeamParams(c)
+	if !ok {
+		return
+	}
+
+	var req addTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	if err := h.service.AddTeamMember(c.Request.Context(), userId, workspaceId, teamId, req.UserID); err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "team member added"})
+}
+
+// RemoveTeamMember godoc
+// @Summary      Remove team member
+// @Description  Remove a user from a team
+// @Tags         team
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string  true  "Workspace ID"
+// @Param        team_id  path      string  true  "Team ID"
+// @Param        user_id  path      string  true  "User ID"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/teams/{team_id}/members/{user_id} [delete]
+func (h *TeamHandler) RemoveTeamMember(c *gin.Context) {
+	userId, workspaceId, teamId, ok := parseTeamParams(c)
+	if !ok {
+		return
+	}
+
+	targetUserId, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid user id"))
+		return
+	}
+
+	if err := h.service.RemoveTeamMember(c.Request.Context(), userId, workspaceId, teamId, targetUserId); err != nil {
+		handleTeamError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "team member removed"})
+}
+
+func parseTeamParams(c *gin.Context) (userId, workspaceId, teamId uuid.UUID, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	teamId, err = uuid.Parse(c.Param("team_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid team id"))
+		return
+	}
+
+	return userId, workspaceId, teamId, true
+}
+
+func handleTeamError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrTeamNotFound):
+		c.Error(apperr.NotFound("team"))
+	case errors.Is(err, store.ErrNotTeamMember):
+		c.Error(apperr.NotFound("team member"))
+	case errors.Is(err, service.ErrTeamNameTaken):
+		c.Error(apperr.Conflict(err.Error()))
+	case errors.Is(err, service.ErrNotWorkspaceMember):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
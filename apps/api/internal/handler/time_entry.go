@@ -0,0 +1,434 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type TimeEntryHandler struct {
+	service service.TimeEntry
+}
+
+func NewTimeEntryHandler(service service.TimeEntry) *TimeEntryHandler {
+	return &TimeEntryHandler{service: service}
+}
+
+type startTimerRequest struct {
+	ProjectID   uuid.UUID  `json:"project_id" binding:"required"`
+	TaskID      *uuid.UUID `json:"task_id"`
+	Description *string    `json:"description"`
+	Billable    *bool      `json:"billable" example:"true"`
+	Tags        []string   `json:"tags"`
+}
+
+type createTimeEntryRequest struct {
+	ProjectID   uuid.UUID  `json:"project_id" binding:"required"`
+	TaskID      *uuid.UUID `json:"task_id"`
+	Description *string    `json:"description"`
+	StartedAt   time.Time  `json:"started_at" binding:"required" example:"2026-03-02T09:00:00Z"`
+	EndedAt     time.Time  `json:"ended_at" binding:"required" example:"2026-03-02T11:30:00Z"`
+	Billable    *bool      `json:"billable" example:"true"`
+	Tags        []string   `json:"tags"`
+}
+
+type updateTimeEntryRequest struct {
+	ProjectID   *uuid.UUID `json:"project_id"`
+	TaskID      *string    `json:"task_id"`
+	Description *string    `json:"description"`
+	StartedAt   *time.Time `json:"started_at" example:"2026-03-02T09:00:00Z"`
+	EndedAt     *time.Time `json:"ended_at" example:"2026-03-02T11:30:00Z"`
+	Billable    *bool      `json:"billable" example:"false"`
+	Tags        []string   `json:"tags"`
+}
+
+// StartTimer godoc
+// @Summary      Start timer
+// @Description  Start tracking time on a project and optionally one of its tasks. A user can only have one running timer at a time.
+// @Tags         time-entry
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string             true  "Workspace ID"
+// @Param        request  body      startTimerRequest  true  "Start Timer Request"
+// @Success      201      {object}  store.TimeEntry
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/time-entries/timer/start [post]
+func (h *TimeEntryHandler) StartTimer(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req startTimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.StartTimerInput{
+		ProjectID:   req.ProjectID,
+		TaskID:      req.TaskID,
+		Description: req.Description,
+		Billable:    req.Billable,
+		Tags:        req.Tags,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	entry, err := h.service.StartTimer(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleTimeEntryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// StopTimer godoc
+// @Summary      Stop timer
+// @Description  Stop the running timer. The duration is rounded by the workspace rounding rules and added to the task's logged hours.
+// @Tags         time-entry
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      200  {object}  store.TimeEntry
+// @Failure      400  {object}  apperr.AppError
+// @Failure      401  {object}  apperr.AppError
+// @Failure      403  {object}  apperr.AppError
+// @Failure      404  {object}  apperr.AppError
+// @Failure      500  {object}  apperr.AppError
+// @Router       /workspaces/{id}/time-entries/timer/stop [post]
+func (h *TimeEntryHandler) StopTimer(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.StopTimer(c.Request.Context(), userId, workspaceId)
+	if err != nil {
+		handleTimeEntryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetRunningTimer godoc
+// @Summary      Get running timer
+// @Description  Get the caller's running timer in the workspace
+// @Tags         time-entry
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      200  {object}  store.TimeEntry
+// @Failure      400  {object}  apperr.AppError
+// @Failure      401  {object}  apperr.AppError
+// @Failure      403  {object}  apperr.AppError
+// @Failure      404  {object}  apperr.AppError
+// @Failure      500  {object}  apperr.AppError
+// @Router       /workspaces/{id}/time-entries/timer [get]
+func (h *TimeEntryHandler) GetRunningTimer(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.GetRunningTimer(c.Request.Context(), userId, workspaceId)
+	if err != nil {
+		handleTimeEntryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// CreateTimeEntry godoc
+// @Summary      Create time entry
+// @Description  Log finished work manually. An entry lasts at most 24 hours and may not overlap the caller's other entries or running timer.
+// @Tags         time-entry
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                  true  "Workspace ID"
+// @Param        request  body      createTimeEntryRequest  true  "Create Time Entry Request"
+// @Success      201      {object}  store.TimeEntry
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/time-entries [post]
+func (h *TimeEntryHandler) CreateTimeEntry(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req createTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.CreateTimeEntryInput{
+		ProjectID:   req.ProjectID,
+		TaskID:      req.TaskID,
+		Description: req.Description,
+		StartedAt:   req.StartedAt,
+		EndedAt:     req.EndedAt,
+		Billable:    req.Billable,
+		Tags:        req.Tags,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	entry, err := h.service.CreateTimeEntry(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleTimeEntryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// ListTimeEntries godoc
+// @Summary      List time entries
+// @Description  List time entries with filtering, sorting, and pagination. Members see their own entries; workspace admins see everyone's.
+// @Tags         time-entry
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true   "Workspace ID"
+// @Param        limit       query     int     false  "Limit (default 20, max 100)"
+// @Param        offset      query     int     false  "Offset (default 0)"
+// @Param        sort_by     query     string  false  "Sort by: started_at, duration_seconds, created_at, updated_at (default: created_at)"
+// @Param        order       query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search      query     string  false  "Search in description"
+// @Param        user_id     query     string  false  "Filter by user ID or 'me'"
+// @Param        project_id  query     string  false  "Filter by project ID"
+// @Param        task_id     query     string  false  "Filter by task ID"
+// @Param        billable    query     bool    false  "Filter by billable"
+// @Param        billed      query     bool    false  "Filter by billed"
+// @Param        running     query     bool    false  "Filter by running timers"
+// @Param        from        query     string  false  "Entries started on or after this date (YYYY-MM-DD, workspace timezone)"
+// @Param        to          query     string  false  "Entries started on or before this date (YYYY-MM-DD, workspace timezone)"
+// @Success      200         {object}  store.PaginatedTimeEntriesResponse
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/time-entries [get]
+func (h *TimeEntryHandler) ListTimeEntries(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+	bindFilters(c, &filters, "user_id", "project_id", "task_id", "billable", "billed", "running", "from", "to")
+
+	entries, err := h.service.ListTimeEntries(c.Request.Context(), userId, workspaceId, filters)
+	if err != nil {
+		handleTimeEntryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetTimeEntry godoc
+// @Summary      Get time entry
+// @Description  Get a time entry by ID
+// @Tags         time-entry
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string  true  "Workspace ID"
+// @Param        entry_id  path      string  true  "Time Entry ID"
+// @Success      200       {object}  store.TimeEntry
+// @Failure      400       {object}  apperr.AppError
+// @Failure      401       {object}  apperr.AppError
+// @Failure      403       {object}  apperr.AppError
+// @Failure      404       {object}  apperr.AppError
+// @Failure      500       {object}  apperr.AppError
+// @Router       /workspaces/{id}/time-entries/{entry_id} [get]
+func (h *TimeEntryHandler) GetTimeEntry(c *gin.Context) {
+	userId, workspaceId, entryId, ok := parseTimeEntryParams(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.GetTimeEntry(c.Request.Context(), userId, workspaceId, entryId)
+	if err != nil {
+		handleTimeEntryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// UpdateTimeEntry godoc
+// @Summary      Update time entry
+// @Description  Update a time entry. Changed times are rounded again and checked for overlaps; an empty task_id clears the task. Billed entries cannot be changed.
+// @Tags         time-entry
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string                  true  "Workspace ID"
+// @Param        entry_id  path      string                  true  "Time Entry ID"
+// @Param        request   body      updateTimeEntryRequest  true  "Update Time Entry Request"
+// @Success      200       {object}  store.TimeEntry
+// @Failure      400       {object}  apperr.AppError
+// @Failure      401       {object}  apperr.AppError
+// @Failure      403       {object}  apperr.AppError
+// @Failure      404       {object}  apperr.AppError
+// @Failure      409       {object}  apperr.AppError
+// @Failure      500       {object}  apperr.AppError
+// @Router       /workspaces/{id}/time-entries/{entry_id} [patch]
+func (h *TimeEntryHandler) UpdateTimeEntry(c *gin.Context) {
+	userId, workspaceId, entryId, ok := parseTimeEntryParams(c)
+	if !ok {
+		return
+	}
+
+	var req updateTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateTimeEntryInput{
+		ProjectID:   req.ProjectID,
+		TaskID:      req.TaskID,
+		Description: req.Description,
+		StartedAt:   req.StartedAt,
+		EndedAt:     req.EndedAt,
+		Billable:    req.Billable,
+		Tags:        req.Tags,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	entry, err := h.service.UpdateTimeEntry(c.Request.Context(), userId, workspaceId, entryId, serviceInput)
+	if err != nil {
+		handleTimeEntryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteTimeEntry godoc
+// @Summary      Delete time entry
+// @Description  Delete a time entry and remove it from the task's logged hours. Billed entries cannot be deleted.
+// @Tags         time-entry
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string  true  "Workspace ID"
+// @Param        entry_id  path      string  true  "Time Entry ID"
+// @Success      200       {object}  map[string]string
+// @Failure      400       {object}  apperr.AppError
+// @Failure      401       {object}  apperr.AppError
+// @Failure      403       {object}  apperr.AppError
+// @Failure      404       {object}  apperr.AppError
+// @Failure      409       {object}  apperr.AppError
+// @Failure      500       {object}  apperr.AppError
+// @Router       /workspaces/{id}/time-entries/{entry_id} [delete]
+func (h *TimeEntryHandler) DeleteTimeEntry(c *gin.Context) {
+	userId, workspaceId, entryId, ok := parseTimeEntryParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTimeEntry(c.Request.Context(), userId, workspaceId, entryId); err != nil {
+		handleTimeEntryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "time entry deleted"})
+}
+
+func parseWorkspaceParams(c *gin.Context) (userId, workspaceId uuid.UUID, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	return userId, workspaceId, true
+}
+
+func parseTimeEntryParams(c *gin.Context) (userId, workspaceId, entryId uuid.UUID, ok bool) {
+	userId, workspaceId, ok = parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	entryId, err := uuid.Parse(c.Param("entry_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid time entry id"))
+		return userId, workspaceId, entryId, false
+	}
+
+	return userId, workspaceId, entryId, true
+}
+
+func handleTimeEntryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrProjectNotFound):
+		c.Error(apperr.NotFound("project"))
+	case errors.Is(err, service.ErrTaskNotFound):
+		c.Error(apperr.NotFound("task"))
+	case errors.Is(err, service.ErrTimeEntryNotFound):
+		c.Error(apperr.NotFound("time entry"))
+	case errors.Is(err, service.ErrNoRunningTimer):
+		c.Error(apperr.NotFound("running timer"))
+	case errors.Is(err, service.ErrTimerRunning),
+		errors.Is(err, service.ErrTimeEntryOverlap),
+		errors.Is(err, service.ErrTimeEntryLocked),
+		errors.Is(err, service.ErrTimesheetLocked):
+		c.Error(apperr.Conflict(err.Error()))
+	case errors.Is(err, service.ErrInvalidTimeRange),
+		errors.Is(err, service.ErrInvalidFilter):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type TimesheetHandler struct {
+	service service.Timesheet
+}
+
+func NewTimesheetHandler(service service.Timesheet) *TimesheetHandler {
+	return &TimesheetHandler{service: service}
+}
+
+type submitTimesheetRequest struct {
+	WeekStart string `json:"week_start" binding:"required" example:"2026-03-02"`
+}
+
+type rejectTimesheetRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Thursday is missing the client call"`
+}
+
+// SubmitTimesheet godoc
+// @Summary      Submit timesheet
+// @Description  Submit the caller's week for approval. Weeks start on Monday in the workspace timezone. The week's time entries are locked until the timesheet is rejected, and no timer may be running in it.
+// @Tags         timesheet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                  true  "Workspace ID"
+// @Param        request  body      submitTimesheetRequest  true  "Submit Timesheet Request"
+// @Success      200      {object}  store.Timesheet
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/timesheets/submit [post]
+func (h *TimesheetHandler) SubmitTimesheet(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	var req submitTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.SubmitTimesheetInput{
+		WeekStart: req.WeekStart,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	timesheet, err := h.service.SubmitTimesheet(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		handleTimesheetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timesheet)
+}
+
+// ListTimesheets godoc
+// @Summary      List timesheets
+// @Description  List timesheets with filtering, sorting, and pagination. Members see their own; workspace admins and timesheet approvers see everyone's.
+// @Tags         timesheet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true   "Workspace ID"
+// @Param        limit       query     int     false  "Limit (default 20, max 100)"
+// @Param        offset      query     int     false  "Offset (default 0)"
+// @Param        sort_by     query     string  false  "Sort by: week_start, status, updated_at (default: week_start)"
+// @Param        order       query     string  false  "Order: asc, desc (default: desc)"
+// @Param        search      query     string  false  "Search in member name"
+// @Param        user_id     query     string  false  "Filter by user ID or 'me'"
+// @Param        status      query     string  false  "Filter by status: open, submitted, approved, rejected"
+// @Param        week_start  query     string  false  "Filter by week (YYYY-MM-DD, a Monday)"
+// @Success      200         {object}  store.PaginatedTimesheetsResponse
+// @Failure      400         {object}  apperr.AppError
+// @Failure      401         {object}  apperr.AppError
+// @Failure      403         {object}  apperr.AppError
+// @Failure      404         {object}  apperr.AppError
+// @Failure      500         {object}  apperr.AppError
+// @Router       /workspaces/{id}/timesheets [get]
+func (h *TimesheetHandler) ListTimesheets(c *gin.Context) {
+	userId, workspaceId, ok := parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	filters := store.DefaultFilter()
+	if err := c.ShouldBindQuery(&filters); err == nil {
+		filters.Normalize()
+	}
+	bindFilters(c, &filters, "user_id", "status", "week_start")
+
+	timesheets, err := h.service.ListTimesheets(c.Request.Context(), userId, workspaceId, filters)
+	if err != nil {
+		handleTimesheetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timesheets)
+}
+
+// GetTimesheet godoc
+// @Summary      Get timesheet
+// @Description  Get a timesheet by ID. Its entries are listed by the time entries endpoint filtered by user and week.
+// @Tags         timesheet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id            path      string  true  "Workspace ID"
+// @Param        timesheet_id  path      string  true  "Timesheet ID"
+// @Success      200           {object}  store.Timesheet
+// @Failure      400           {object}  apperr.AppError
+// @Failure      401           {object}  apperr.AppError
+// @Failure      403           {object}  apperr.AppError
+// @Failure      404           {object}  apperr.AppError
+// @Failure      500           {object}  apperr.AppError
+// @Router       /workspaces/{id}/timesheets/{timesheet_id} [get]
+func (h *TimesheetHandler) GetTimesheet(c *gin.Context) {
+	userId, workspaceId, timesheetId, ok := parseTimesheetParams(c)
+	if !ok {
+		return
+	}
+
+	timesheet, err := h.service.GetTimesheet(c.Request.Context(), userId, workspaceId, timesheetId)
+	if err != nil {
+		handleTimesheetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timesheet)
+}
+
+// ApproveTimesheet godoc
+// @Summary      Approve timesheet
+// @Description  Approve a submitted timesheet. Its time entries are marked approved and can no longer be changed. Requires a workspace admin or a member listed in the timesheet_approver_ids setting.
+// @Tags         timesheet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id            path      string  true  "Workspace ID"
+// @Param        timesheet_id  path      string  true  "Timesheet ID"
+// @Success      200           {object}  store.Timesheet
+// @Failure      400           {object}  apperr.AppError
+// @Failure      401           {object}  apperr.AppError
+// @Failure      403           {object}  apperr.AppError
+// @Failure      404           {object}  apperr.AppError
+// @Failure      409           {object}  apperr.AppError
+// @Failure      500           {object}  apperr.AppError
+// @Router       /workspaces/{id}/timesheets/{timesheet_id}/approve [post]
+func (h *TimesheetHandler) ApproveTimesheet(c *gin.Context) {
+	userId, workspaceId, timesheetId, ok := parseTimesheetParams(c)
+	if !ok {
+		return
+	}
+
+	timesheet, err := h.service.ApproveTimesheet(c.Request.Context(), userId, workspaceId, timesheetId)
+	if err != nil {
+		handleTimesheetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timesheet)
+}
+
+// RejectTimesheet godoc
+// @Summary      Reject timesheet
+// @Description  Send a submitted timesheet back to its owner with a reason, unlocking its time entries. Requires a workspace admin or a member listed in the timesheet_approver_ids setting.
+// @Tags         timesheet
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id            path      string                  true  "Workspace ID"
+// @Param        timesheet_id  path      string                  true  "Timesheet ID"
+// @Param        request       body      rejectTimesheetRequest  true  "Reject Timesheet Request"
+// @Success      200           {object}  store.Timesheet
+// @Failure      400           {object}  apperr.AppError
+// @Failure      401           {object}  apperr.AppError
+// @Failure      403           {object}  apperr.AppError
+// @Failure      404           {object}  apperr.AppError
+// @Failure      409           {object}  apperr.AppError
+// @Failure      500           {object}  apperr.AppError
+// @Router       /workspaces/{id}/timesheets/{timesheet_id}/reject [post]
+func (h *TimesheetHandler) RejectTimesheet(c *gin.Context) {
+	userId, workspaceId, timesheetId, ok := parseTimesheetParams(c)
+	if !ok {
+		return
+	}
+
+	var req rejectTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.RejectTimesheetInput{
+		Reason: req.Reason,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	timesheet, err := h.service.RejectTimesheet(c.Request.Context(), userId, workspaceId, timesheetId, serviceInput)
+	if err != nil {
+		handleTimesheetError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timesheet)
+}
+
+func parseTimesheetParams(c *gin.Context) (userId, workspaceId, timesheetId uuid.UUID, ok bool) {
+	userId, workspaceId, ok = parseWorkspaceParams(c)
+	if !ok {
+		return
+	}
+
+	timesheetId, err := uuid.Parse(c.Param("timesheet_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid timesheet id"))
+		return userId, workspaceId, timesheetId, false
+	}
+
+	return userId, workspaceId, timesheetId, true
+}
+
+func handleTimesheetError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrWorkspaceNotFound):
+		c.Error(apperr.NotFound("workspace"))
+	case errors.Is(err, service.ErrTimesheetNotFound):
+		c.Error(apperr.NotFound("timesheet"))
+	case errors.Is(err, service.ErrTimesheetNotEditable),
+		errors.Is(err, service.ErrTimesheetNotSubmitted),
+		errors.Is(err, service.ErrTimerRunning):
+		c.Error(apperr.Conflict(err.Error()))
+	case errors.Is(err, service.ErrInvalidWeekStart),
+		errors.Is(err, service.ErrInvalidFilter):
+		c.Error(apperr.BadRequest(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
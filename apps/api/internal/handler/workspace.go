@@ -258,6 +258,7 @@ func (h *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
 // @Success      200      {object}  map[string]string
 // @Failure      400      {object}  apperr.AppError
 // @Failure      401      {object}  apperr.AppError
+// @Failure      402      {object}  apperr.AppError
 // @Failure      403      {object}  apperr.AppError
 // @Failure      500      {object}  apperr.AppError
 // @Router       /workspaces/{id}/members [post]
@@ -290,6 +291,10 @@ func (h *WorkspaceHandler) AddMember(c *gin.Context) {
 			c.Error(apperr.BadRequest("invalid role"))
 			return
 		}
+		if errors.Is(err, service.ErrQuotaExceeded) {
+			c.Error(apperr.QuotaExceeded(err.Error()))
+			return
+		}
 		if err.Error() == "user not found" || err == service.ErrWorkspaceNotFound { // store.ErrUserNotFound check
 		}
 		if err.Error() == "user not found" { // store.ErrUserNotFound
@@ -316,6 +321,7 @@ func (h *WorkspaceHandler) AddMember(c *gin.Context) {
 // @Failure      400      {object}  apperr.AppError
 // @Failure      401      {object}  apperr.AppError
 // @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
 // @Failure      500      {object}  apperr.AppError
 // @Router       /workspaces/{id}/members/{user_id} [delete]
 func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
@@ -338,11 +344,7 @@ func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
 	}
 
 	if err := h.service.RemoveMember(c.Request.Context(), userId, workspaceId, targetUserId); err != nil {
-		if errors.Is(err, service.ErrForbidden) {
-			c.Error(apperr.Forbidden("access denied"))
-			return
-		}
-		c.Error(apperr.Internal(err))
+		handleMemberError(c, err)
 		return
 	}
 
@@ -409,6 +411,7 @@ func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
 // @Success      200     {object}  map[string]string
 // @Failure      400     {object}  apperr.AppError
 // @Failure      401     {object}  apperr.AppError
+// @Failure      402     {object}  apperr.AppError
 // @Failure      403     {object}  apperr.AppError
 // @Failure      500     {object}  apperr.AppError
 // @Router       /workspaces/{id}/avatar [post]
@@ -449,9 +452,210 @@ func (h *WorkspaceHandler) UploadAvatar(c *gin.Context) {
 			c.Error(apperr.Forbidden("access denied"))
 			return
 		}
+		if errors.Is(err, service.ErrQuotaExceeded) {
+			c.Error(apperr.QuotaExceeded(err.Error()))
+			return
+		}
 		c.Error(apperr.Internal(err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"avatar_url": avatarURL})
 }
+
+type updateSettingsRequest struct {
+	Version                   *int        `json:"version"`
+	DefaultCurrency           *string     `json:"default_currency"`
+	Timezone                  *string     `json:"timezone"`
+	Locale                    *string     `json:"locale"`
+	DateFormat                *string     `json:"date_format"`
+	FiscalYearStartMonth      *int        `json:"fiscal_year_start_month"`
+	InvoicePrefix             *string     `json:"invoice_prefix"`
+	BrandColor                *string     `json:"brand_color"`
+	LogoURL                   *string     `json:"logo_url"`
+	DefaultJoinRole           *string     `json:"default_join_role"`
+	WorkingHoursPerDay        *float64    `json:"working_hours_per_day" example:"8"`
+	TimeRoundingMinutes       *int        `json:"time_rounding_minutes" example:"15"`
+	TimeRoundingMode          *string     `json:"time_rounding_mode" example:"up"`
+	TimesheetApproverIDs      []uuid.UUID `json:"timesheet_approver_ids"`
+	DefaultHourlyRate         *int64      `json:"default_hourly_rate" example:"12000"`
+	InvoiceReminderDays       []int       `json:"invoice_reminder_days" example:"-3,0"`
+	InvoiceReminderRepeatDays *int        `json:"invoice_reminder_repeat_days" example:"7"`
+	BudgetAlertThresholds     []int       `json:"budget_alert_thresholds" example:"50,80,100"`
+}
+
+// GetSettings godoc
+// @Summary      Get workspace settings
+// @Description  Get the workspace settings document, falling back to defaults if never saved
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      200  {object}  store.WorkspaceSettings
+// @Failure      400  {object}  apperr.AppError
+// @Failure      401  {object}  apperr.AppError
+// @Failure      403  {object}  apperr.AppError
+// @Failure      404  {object}  apperr.AppError
+// @Failure      500  {object}  apperr.AppError
+// @Router       /workspaces/{id}/settings [get]
+func (h *WorkspaceHandler) GetSettings(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	settings, err := h.service.GetSettings(c.Request.Context(), userId, workspaceId)
+	if err != nil {
+		if errors.Is(err, service.ErrWorkspaceNotFound) {
+			c.Error(apperr.NotFound("workspace not found"))
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.Error(apperr.Forbidden("access denied"))
+			return
+		}
+		c.Error(apperr.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings godoc
+// @Summary      Update workspace settings
+// @Description  Partially update the workspace settings. Pass the current version to guard against concurrent edits.
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                 true  "Workspace ID"
+// @Param        request  body      updateSettingsRequest  true  "Update Settings Request"
+// @Success      200      {object}  store.WorkspaceSettings
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/settings [patch]
+func (h *WorkspaceHandler) UpdateSettings(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	var req updateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.UpdateWorkspaceSettingsInput{
+		Version:                   req.Version,
+		DefaultCurrency:           req.DefaultCurrency,
+		Timezone:                  req.Timezone,
+		Locale:                    req.Locale,
+		DateFormat:                req.DateFormat,
+		FiscalYearStartMonth:      req.FiscalYearStartMonth,
+		InvoicePrefix:             req.InvoicePrefix,
+		BrandColor:                req.BrandColor,
+		LogoURL:                   req.LogoURL,
+		DefaultJoinRole:           req.DefaultJoinRole,
+		WorkingHoursPerDay:        req.WorkingHoursPerDay,
+		TimeRoundingMinutes:       req.TimeRoundingMinutes,
+		TimeRoundingMode:          req.TimeRoundingMode,
+		TimesheetApproverIDs:      req.TimesheetApproverIDs,
+		DefaultHourlyRate:         req.DefaultHourlyRate,
+		InvoiceReminderDays:       req.InvoiceReminderDays,
+		InvoiceReminderRepeatDays: req.InvoiceReminderRepeatDays,
+		BudgetAlertThresholds:     req.BudgetAlertThresholds,
+	}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(c.Request.Context(), userId, workspaceId, serviceInput)
+	if err != nil {
+		if errors.Is(err, service.ErrWorkspaceNotFound) {
+			c.Error(apperr.NotFound("workspace not found"))
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.Error(apperr.Forbidden("access denied"))
+			return
+		}
+		if errors.Is(err, store.ErrSettingsVersionConflict) {
+			c.Error(apperr.Conflict("settings were changed by someone else, reload and try again"))
+			return
+		}
+		if errors.Is(err, service.ErrNotWorkspaceMember) {
+			c.Error(apperr.BadRequest("timesheet approvers must be workspace members"))
+			return
+		}
+		c.Error(apperr.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// GetUsage godoc
+// @Summary      Get workspace usage
+// @Description  Get the workspace plan together with current usage against each of its limits
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Workspace ID"
+// @Success      200  {object}  service.WorkspaceUsageReport
+// @Failure      400  {object}  apperr.AppError
+// @Failure      401  {object}  apperr.AppError
+// @Failure      403  {object}  apperr.AppError
+// @Failure      404  {object}  apperr.AppError
+// @Failure      500  {object}  apperr.AppError
+// @Router       /workspaces/{id}/usage [get]
+func (h *WorkspaceHandler) GetUsage(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	usage, err := h.service.GetUsage(c.Request.Context(), userId, workspaceId)
+	if err != nil {
+		if errors.Is(err, service.ErrWorkspaceNotFound) {
+			c.Error(apperr.NotFound("workspace not found"))
+			return
+		}
+		if errors.Is(err, service.ErrForbidden) {
+			c.Error(apperr.Forbidden("access denied"))
+			return
+		}
+		c.Error(apperr.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/service"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+)
+
+type offboardMemberRequest struct {
+	ReassignTo *uuid.UUID `json:"reassign_to"`
+}
+
+type reassignContentRequest struct {
+	ToUserID uuid.UUID `json:"to_user_id" binding:"required"`
+}
+
+type setMemberRateRequest struct {
+	HourlyRate *int64 `json:"hourly_rate" example:"9000"`
+}
+
+// LeaveWorkspace godoc
+// @Summary      Leave workspace
+// @Description  Leave the workspace, optionally handing your assigned content over to another member. The body is optional.
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                 true   "Workspace ID"
+// @Param        request  body      offboardMemberRequest  false  "Leave Workspace Request"
+// @Success      200      {object}  map[string]string
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/leave [post]
+func (h *WorkspaceHandler) LeaveWorkspace(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	var req offboardMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		handleValidationError(c, err)
+		return
+	}
+
+	input := service.OffboardMemberInput{ReassignTo: req.ReassignTo}
+	if err := h.service.LeaveWorkspace(c.Request.Context(), userId, workspaceId, input); err != nil {
+		handleMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "left workspace"})
+}
+
+// DeactivateMember godoc
+// @Summary      Deactivate member
+// @Description  Block a member's access while keeping their history, optionally reassigning their content. The body is optional.
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                 true   "Workspace ID"
+// @Param        user_id  path      string                 true   "User ID to deactivate"
+// @Param        request  body      offboardMemberRequest  false  "Deactivate Member Request"
+// @Success      200      {object}  store.WorkspaceMember
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/members/{user_id}/deactivate [post]
+func (h *WorkspaceHandler) DeactivateMember(c *gin.Context) {
+	userId, workspaceId, targetUserId, ok := parseMemberParams(c)
+	if !ok {
+		return
+	}
+
+	var req offboardMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		handleValidationError(c, err)
+		return
+	}
+
+	input := service.OffboardMemberInput{ReassignTo: req.ReassignTo}
+	member, err := h.service.DeactivateMember(c.Request.Context(), userId, workspaceId, targetUserId, input)
+	if err != nil {
+		handleMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// ReactivateMember godoc
+// @Summary      Reactivate member
+// @Description  Restore access for a deactivated member. The member takes up a seat again.
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string  true  "Workspace ID"
+// @Param        user_id  path      string  true  "User ID to reactivate"
+// @Success      200      {object}  store.WorkspaceMember
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      402      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      409      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/members/{user_id}/reactivate [post]
+func (h *WorkspaceHandler) ReactivateMember(c *gin.Context) {
+	userId, workspaceId, targetUserId, ok := parseMemberParams(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.service.ReactivateMember(c.Request.Context(), userId, workspaceId, targetUserId)
+	if err != nil {
+		handleMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// ReassignMemberContent godoc
+// @Summary      Reassign member content
+// @Description  Transfer everything assigned to or owned by a member to another active member
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                  true  "Workspace ID"
+// @Param        user_id  path      string                  true  "User ID to reassign content from"
+// @Param        request  body      reassignContentRequest  true  "Reassign Content Request"
+// @Success      200      {object}  service.ContentReassignment
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/members/{user_id}/reassign [post]
+func (h *WorkspaceHandler) ReassignMemberContent(c *gin.Context) {
+	userId, workspaceId, fromUserId, ok := parseMemberParams(c)
+	if !ok {
+		return
+	}
+
+	var req reassignContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.ReassignContentInput{ToUserID: req.ToUserID}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	reassignment, err := h.service.ReassignMemberContent(c.Request.Context(), userId, workspaceId, fromUserId, serviceInput)
+	if err != nil {
+		handleMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reassignment)
+}
+
+// SetMemberRate godoc
+// @Summary      Set member rate
+// @Description  Set the hourly rate a member's time is priced at in project budgets, in minor units of the workspace default currency. Task rates take precedence. A null rate clears it. Only workspace owners and admins can set rates.
+// @Tags         workspace
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                true  "Workspace ID"
+// @Param        user_id  path      string                true  "User ID"
+// @Param        request  body      setMemberRateRequest  true  "Set Member Rate Request"
+// @Success      200      {object}  store.WorkspaceMember
+// @Failure      400      {object}  apperr.AppError
+// @Failure      401      {object}  apperr.AppError
+// @Failure      403      {object}  apperr.AppError
+// @Failure      404      {object}  apperr.AppError
+// @Failure      500      {object}  apperr.AppError
+// @Router       /workspaces/{id}/members/{user_id}/rate [put]
+func (h *WorkspaceHandler) SetMemberRate(c *gin.Context) {
+	userId, workspaceId, targetUserId, ok := parseMemberParams(c)
+	if !ok {
+		return
+	}
+
+	var req setMemberRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleValidationError(c, err)
+		return
+	}
+
+	serviceInput := service.SetMemberRateInput{HourlyRate: req.HourlyRate}
+	if err := validator.Struct(&serviceInput); err != nil {
+		c.Error(err)
+		return
+	}
+
+	member, err := h.service.SetMemberRate(c.Request.Context(), userId, workspaceId, targetUserId, serviceInput)
+	if err != nil {
+		handleMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func parseMemberParams(c *gin.Context) (userId, workspaceId, targetUserId uuid.UUID, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		c.Error(apperr.Unauthorized(err.Error()))
+		return
+	}
+
+	workspaceId, err = uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid workspace id"))
+		return
+	}
+
+	targetUserId, err = uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.Error(apperr.BadRequest("invalid user id"))
+		return
+	}
+
+	return userId, workspaceId, targetUserId, true
+}
+
+func handleMemberError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		c.Error(apperr.Forbidden("access denied"))
+	case errors.Is(err, service.ErrNotWorkspaceMember):
+		c.Error(apperr.NotFound("member"))
+	case errors.Is(err, service.ErrMemberDeactivated),
+		errors.Is(err, service.ErrMemberNotDeactivated):
+		c.Error(apperr.Conflict(err.Error()))
+	case errors.Is(err, service.ErrOwnerCannotLeave),
+		errors.Is(err, service.ErrCannotDeactivateSelf),
+		errors.Is(err, service.ErrInvalidReassignTarget):
+		c.Error(apperr.BadRequest(err.Error()))
+	case errors.Is(err, service.ErrQuotaExceeded):
+		c.Error(apperr.QuotaExceeded(err.Error()))
+	default:
+		c.Error(apperr.Internal(err))
+	}
+}
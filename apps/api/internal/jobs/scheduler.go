@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Job is a unit of background work run on a fixed interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	jobs   []Job
+	logger zerolog.Logger
+}
+
+func NewScheduler(logger zerolog.Logger) *Scheduler {
+	return &Scheduler{
+		logger: logger.With().Str("component", "scheduler").Logger(),
+	}
+}
+
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start runs every job in its own goroutine until ctx is cancelled. A job runs
+// once on start and then after every interval; a failed run is logged and
+// retried on the next tick.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.run(ctx, job)
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error().Err(err).Str("job", job.Name).Msg("job failed")
+		} else {
+			s.logger.Debug().Str("job", job.Name).Dur("duration", time.Since(start)).Msg("job finished")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/pkg/apperr"
+	"github.com/rs/zerolog"
+)
+
+type APIUsageLimiter interface {
+	ReserveAPICall(ctx context.Context, workspaceID uuid.UUID) (bool, error)
+	ReleaseAPICall(ctx context.Context, workspaceID uuid.UUID) error
+}
+
+// WorkspaceAPIUsage meters requests made against a workspace's routes towards
+// its monthly API call allowance, and refuses them with 402 once the plan's
+// limit is reached. A call is reserved before the handler runs and given back
+// if the request does not succeed, so only successful requests are counted.
+// Routes outside /workspaces/:id act for a user or a public link rather than
+// a workspace, and are not metered.
+func WorkspaceAPIUsage(limiter APIUsageLimiter, logger zerolog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.FullPath(), "/workspaces/:id") {
+			c.Next()
+			return
+		}
+		workspaceID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		allowed, err := limiter.ReserveAPICall(c.Request.Context(), workspaceID)
+		if err != nil {
+			// Metering must not take the API down with it.
+			logger.Warn().Err(err).Str("workspace_id", workspaceID.String()).Msg("failed to record api usage")
+			c.Next()
+			return
+		}
+		if !allowed {
+			c.Error(apperr.QuotaExceeded("plan limit reached: monthly api calls are used up"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+
+		// Errors are only written out by ErrorHandler once this returns.
+		if len(c.Errors) == 0 && c.Writer.Status() >= 200 && c.Writer.Status() < 300 {
+			return
+		}
+		if err := limiter.ReleaseAPICall(context.WithoutCancel(c.Request.Context()), workspaceID); err != nil {
+			logger.Warn().Err(err).Str("workspace_id", workspaceID.String()).Msg("failed to release api usage")
+		}
+	}
+}
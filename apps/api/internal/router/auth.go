@@ -1,17 +1,22 @@
 package router
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/lukabrkovic/artemis/internal/handler"
 	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
 )
 
-func RegisterAuthRoutes(r *gin.RouterGroup, h *handler.AuthHandler) {
+func RegisterAuthRoutes(r *gin.RouterGroup, h *handler.AuthHandler, tokenMaker token.Maker) {
 	auth := r.Group("/auth")
 	{
 		auth.POST("/register", middleware.RateLimiterForAuth(), h.Register)
 		auth.POST("/login", middleware.RateLimiterForAuth(), h.Login)
 		auth.POST("/refresh", h.Refresh)
 		auth.POST("/logout", h.Logout)
+		auth.POST("/verify-email", middleware.RateLimiterForAuth(), h.VerifyEmail)
+		auth.POST("/email-verification", middleware.Auth(tokenMaker), middleware.RateLimiterWithUserID(5, time.Hour), h.SendEmailVerification)
 	}
 }
@@ -0,0 +1,26 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterClientRoutes(r *gin.RouterGroup, h *handler.ClientHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("/:id/clients", h.CreateClient)
+		protected.GET("/:id/clients", h.ListClients)
+		protected.GET("/:id/clients/:client_id", h.GetClient)
+		protected.PATCH("/:id/clients/:client_id", h.UpdateClient)
+		protected.DELETE("/:id/clients/:client_id", h.DeleteClient)
+		protected.POST("/:id/clients/:client_id/status", h.UpdateClientStatus)
+		protected.GET("/:id/clients/:client_id/status-history", h.ListClientStatusHistory)
+		protected.GET("/:id/clients/:client_id/contacts", h.ListClientContacts)
+		protected.POST("/:id/clients/:client_id/contacts", h.CreateClientContact)
+		protected.PATCH("/:id/clients/:client_id/contacts/:contact_id", h.UpdateClientContact)
+		protected.DELETE("/:id/clients/:client_id/contacts/:contact_id", h.DeleteClientContact)
+	}
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+// commentPaths are the comment thread roots of every commentable resource.
+var commentPaths = []string{
+	"/:id/projects/:project_id/comments",
+	"/:id/projects/:project_id/tasks/:task_id/comments",
+}
+
+func RegisterCommentRoutes(r *gin.RouterGroup, h *handler.CommentHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		for _, path := range commentPaths {
+			protected.POST(path, h.CreateComment)
+			protected.GET(path, h.ListComments)
+			protected.PATCH(path+"/:comment_id", h.UpdateComment)
+			protected.DELETE(path+"/:comment_id", h.DeleteComment)
+			protected.GET(path+"/:comment_id/history", h.ListCommentRevisions)
+		}
+	}
+}
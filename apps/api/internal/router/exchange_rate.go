@@ -0,0 +1,16 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterExchangeRateRoutes(r *gin.RouterGroup, h *handler.ExchangeRateHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.GET("/:id/exchange-rates", h.GetExchangeRate)
+	}
+}
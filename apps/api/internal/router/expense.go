@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterExpenseRoutes(r *gin.RouterGroup, h *handler.ExpenseHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("/:id/expense-categories", h.CreateExpenseCategory)
+		protected.GET("/:id/expense-categories", h.ListExpenseCategories)
+		protected.PATCH("/:id/expense-categories/:category_id", h.UpdateExpenseCategory)
+		protected.DELETE("/:id/expense-categories/:category_id", h.DeleteExpenseCategory)
+
+		protected.POST("/:id/expenses", h.CreateExpense)
+		protected.GET("/:id/expenses", h.ListExpenses)
+		protected.GET("/:id/expenses/:expense_id", h.GetExpense)
+		protected.PATCH("/:id/expenses/:expense_id", h.UpdateExpense)
+		protected.DELETE("/:id/expenses/:expense_id", h.DeleteExpense)
+		protected.POST("/:id/expenses/:expense_id/approve", h.ApproveExpense)
+		protected.POST("/:id/expenses/:expense_id/reject", h.RejectExpense)
+		protected.POST("/:id/expenses/:expense_id/reimburse", h.ReimburseExpense)
+		protected.POST("/:id/expenses/:expense_id/receipt", h.UploadExpenseReceipt)
+		protected.GET("/:id/expenses/:expense_id/receipt", h.GetExpenseReceipt)
+		protected.DELETE("/:id/expenses/:expense_id/receipt", h.DeleteExpenseReceipt)
+		protected.POST("/:id/expenses/:expense_id/receipt/suggestions/confirm", h.ConfirmReceiptSuggestions)
+		protected.DELETE("/:id/expenses/:expense_id/receipt/suggestions", h.DismissReceiptSuggestions)
+	}
+}
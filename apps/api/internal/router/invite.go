@@ -0,0 +1,26 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterInviteRoutes(r *gin.RouterGroup, h *handler.InviteHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("/join", middleware.RateLimiterWithUserID(10, time.Minute), h.Join)
+
+		protected.POST("/:id/invite-links", h.CreateInviteLink)
+		protected.GET("/:id/invite-links", h.ListInviteLinks)
+		protected.DELETE("/:id/invite-links/:link_id", h.RevokeInviteLink)
+
+		protected.POST("/:id/join-domains", h.AddJoinDomain)
+		protected.GET("/:id/join-domains", h.ListJoinDomains)
+		protected.DELETE("/:id/join-domains/:domain", h.RemoveJoinDomain)
+	}
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterInvoiceRoutes(r *gin.RouterGroup, h *handler.InvoiceHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("/:id/invoices", h.CreateInvoice)
+		protected.POST("/:id/invoices/from-unbilled", h.CreateInvoiceFromUnbilled)
+		protected.GET("/:id/invoices", h.ListInvoices)
+		protected.GET("/:id/invoices/:invoice_id", h.GetInvoice)
+		protected.GET("/:id/invoices/:invoice_id/pdf", h.GetInvoicePDF)
+		protected.PATCH("/:id/invoices/:invoice_id", h.UpdateInvoice)
+		protected.DELETE("/:id/invoices/:invoice_id", h.DeleteInvoice)
+		protected.POST("/:id/invoices/:invoice_id/status", h.UpdateInvoiceStatus)
+	}
+}
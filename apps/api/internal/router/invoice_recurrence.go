@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterRecurringInvoiceRoutes(r *gin.RouterGroup, h *handler.RecurringInvoiceHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("/:id/recurring-invoices", h.CreateRecurringInvoice)
+		protected.GET("/:id/recurring-invoices", h.ListRecurringInvoices)
+		protected.GET("/:id/recurring-invoices/:recurrence_id", h.GetRecurringInvoice)
+		protected.PATCH("/:id/recurring-invoices/:recurrence_id", h.UpdateRecurringInvoice)
+		protected.DELETE("/:id/recurring-invoices/:recurrence_id", h.DeleteRecurringInvoice)
+	}
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterInvoiceShareRoutes(r *gin.RouterGroup, h *handler.InvoiceShareHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("/:id/invoices/:invoice_id/share-links", h.CreateShareLink)
+		protected.GET("/:id/invoices/:invoice_id/share-links", h.ListShareLinks)
+		protected.DELETE("/:id/invoices/:invoice_id/share-links/:link_id", h.RevokeShareLink)
+	}
+
+	// Share links are opened without an account, so each token gets its own
+	// budget on top of the per-IP limit.
+	limiter := middleware.NewMemoryRateLimiter(middleware.RateLimiterConfig{
+		Requests: 30,
+		Window:   time.Minute,
+		KeyFunc: func(c *gin.Context) string {
+			return "invoice-share:" + c.Param("token")
+		},
+	})
+	public := r.Group("/public/invoices")
+	public.Use(middleware.RateLimiter(limiter))
+	{
+		public.GET("/:token", h.ViewSharedInvoice)
+		public.GET("/:token/pdf", h.GetSharedInvoicePDF)
+	}
+}
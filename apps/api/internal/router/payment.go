@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterPaymentRoutes(r *gin.RouterGroup, h *handler.PaymentHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("/:id/invoices/:invoice_id/payments", h.RecordPayment)
+		protected.GET("/:id/invoices/:invoice_id/payments", h.ListInvoicePayments)
+		protected.POST("/:id/invoices/:invoice_id/payments/:payment_id/refunds", h.RefundPayment)
+		protected.POST("/:id/invoices/:invoice_id/apply-credit", h.ApplyClientCredit)
+		protected.GET("/:id/clients/:client_id/credit", h.GetClientCredit)
+	}
+}
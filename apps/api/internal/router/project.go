@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterProjectRoutes(r *gin.RouterGroup, h *handler.ProjectHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces/:id/projects")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("", h.CreateProject)
+		protected.GET("", h.ListProjects)
+		protected.GET("/:project_id", h.GetProject)
+		protected.PATCH("/:project_id", h.UpdateProject)
+		protected.DELETE("/:project_id", h.DeleteProject)
+	}
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterProjectBudgetRoutes(r *gin.RouterGroup, h *handler.ProjectBudgetHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces/:id/projects")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.GET("/:project_id/budget", h.GetProjectBudget)
+	}
+}
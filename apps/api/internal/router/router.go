@@ -11,6 +11,7 @@ import (
 	"github.com/lukabrkovic/artemis/internal/middleware"
 	"github.com/lukabrkovic/artemis/internal/service"
 	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/exchange"
 	"github.com/lukabrkovic/artemis/pkg/storage"
 	"github.com/lukabrkovic/artemis/pkg/token"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -31,6 +32,7 @@ type Config struct {
 	MaxRequestSize          int64
 	EventBus                *events.Bus
 	AuditLogger             *audit.Logger
+	ExchangeRates           exchange.Provider
 }
 
 func New(cfg Config) *gin.Engine {
@@ -63,20 +65,72 @@ func New(cfg Config) *gin.Engine {
 	authService := service.NewAuthService(cfg.Store, cfg.Cache, cfg.TokenMaker, cfg.TokenConfig, cfg.EventBus, cfg.Logger)
 	userService := service.NewUserService(cfg.Store, cfg.Cache, cfg.Storage, cfg.Logger)
 	workspaceService := service.NewWorkspaceService(cfg.Store, cfg.Storage, cfg.EventBus, cfg.Logger)
+	inviteService := service.NewInviteService(cfg.Store, cfg.EventBus, cfg.Logger)
+	teamService := service.NewTeamService(cfg.Store, cfg.Logger)
+	projectService := service.NewProjectService(cfg.Store, cfg.EventBus, cfg.Logger)
+	projectBudgetService := service.NewProjectBudgetService(cfg.Store, cfg.ExchangeRates, cfg.EventBus, cfg.Logger)
+	taskService := service.NewTaskService(cfg.Store, cfg.EventBus, cfg.Logger)
+	recurringTaskService := service.NewRecurringTaskService(cfg.Store, cfg.EventBus, cfg.Logger)
+	commentService := service.NewCommentService(cfg.Store, cfg.EventBus, cfg.Logger)
+	timeEntryService := service.NewTimeEntryService(cfg.Store, cfg.EventBus, cfg.Logger)
+	timesheetService := service.NewTimesheetService(cfg.Store, cfg.EventBus, cfg.Logger)
+	clientService := service.NewClientService(cfg.Store, cfg.EventBus, cfg.Logger)
+	invoiceService := service.NewInvoiceService(cfg.Store, cfg.Storage, cfg.EventBus, cfg.Logger)
+	paymentService := service.NewPaymentService(cfg.Store, cfg.EventBus, cfg.Logger)
+	invoiceShareService := service.NewInvoiceShareService(cfg.Store, cfg.Storage, cfg.EventBus, cfg.TokenConfig.SymmetricKey, cfg.Logger)
+	recurringInvoiceService := service.NewRecurringInvoiceService(cfg.Store, cfg.EventBus, cfg.Logger)
+	exchangeRateService := service.NewExchangeRateService(cfg.Store, cfg.ExchangeRates, cfg.Logger)
+	reportService := service.NewReportService(cfg.Store, cfg.ExchangeRates, cfg.Logger)
+	expenseService := service.NewExpenseService(cfg.Store, cfg.Storage, cfg.EventBus, cfg.Logger)
 
 	authHandler := handler.NewAuthHandler(authService)
 	userHandler := handler.NewUserHandler(userService)
 	workspaceHandler := handler.NewWorkspaceHandler(workspaceService)
+	inviteHandler := handler.NewInviteHandler(inviteService)
+	teamHandler := handler.NewTeamHandler(teamService)
+	projectHandler := handler.NewProjectHandler(projectService)
+	projectBudgetHandler := handler.NewProjectBudgetHandler(projectBudgetService)
+	taskHandler := handler.NewTaskHandler(taskService)
+	recurringTaskHandler := handler.NewRecurringTaskHandler(recurringTaskService)
+	commentHandler := handler.NewCommentHandler(commentService)
+	timeEntryHandler := handler.NewTimeEntryHandler(timeEntryService)
+	timesheetHandler := handler.NewTimesheetHandler(timesheetService)
+	clientHandler := handler.NewClientHandler(clientService)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceService)
+	paymentHandler := handler.NewPaymentHandler(paymentService)
+	invoiceShareHandler := handler.NewInvoiceShareHandler(invoiceShareService)
+	recurringInvoiceHandler := handler.NewRecurringInvoiceHandler(recurringInvoiceService)
+	exchangeRateHandler := handler.NewExchangeRateHandler(exchangeRateService)
+	reportHandler := handler.NewReportHandler(reportService)
+	expenseHandler := handler.NewExpenseHandler(expenseService)
 
 	router.GET("/health", handler.Health)
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	api := router.Group("/api/v1")
+	api.Use(middleware.WorkspaceAPIUsage(cfg.Store.Usage, cfg.Logger))
 	{
-		RegisterAuthRoutes(api, authHandler)
+		RegisterAuthRoutes(api, authHandler, cfg.TokenMaker)
 		RegisterUserRoutes(api, userHandler, cfg.TokenMaker)
 		RegisterWorkspaceRoutes(api, workspaceHandler, cfg.TokenMaker)
+		RegisterInviteRoutes(api, inviteHandler, cfg.TokenMaker)
+		RegisterTeamRoutes(api, teamHandler, cfg.TokenMaker)
+		RegisterProjectRoutes(api, projectHandler, cfg.TokenMaker)
+		RegisterProjectBudgetRoutes(api, projectBudgetHandler, cfg.TokenMaker)
+		RegisterTaskRoutes(api, taskHandler, cfg.TokenMaker)
+		RegisterRecurringTaskRoutes(api, recurringTaskHandler, cfg.TokenMaker)
+		RegisterCommentRoutes(api, commentHandler, cfg.TokenMaker)
+		RegisterTimeEntryRoutes(api, timeEntryHandler, cfg.TokenMaker)
+		RegisterTimesheetRoutes(api, timesheetHandler, cfg.TokenMaker)
+		RegisterClientRoutes(api, clientHandler, cfg.TokenMaker)
+		RegisterInvoiceRoutes(api, invoiceHandler, cfg.TokenMaker)
+		RegisterPaymentRoutes(api, paymentHandler, cfg.TokenMaker)
+		RegisterInvoiceShareRoutes(api, invoiceShareHandler, cfg.TokenMaker)
+		RegisterRecurringInvoiceRoutes(api, recurringInvoiceHandler, cfg.TokenMaker)
+		RegisterExchangeRateRoutes(api, exchangeRateHandler, cfg.TokenMaker)
+		RegisterReportRoutes(api, reportHandler, cfg.TokenMaker)
+		RegisterExpenseRoutes(api, expenseHandler, cfg.TokenMaker)
 	}
 
 	return router
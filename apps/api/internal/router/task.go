@@ -0,0 +1,37 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterTaskRoutes(r *gin.RouterGroup, h *handler.TaskHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.GET("/:id/task-tags", h.ListTaskTags)
+
+		protected.POST("/:id/projects/:project_id/tasks", h.CreateTask)
+		protected.GET("/:id/projects/:project_id/tasks", h.ListTasks)
+		protected.GET("/:id/projects/:project_id/tasks/:task_id", h.GetTask)
+		protected.PATCH("/:id/projects/:project_id/tasks/:task_id", h.UpdateTask)
+		protected.DELETE("/:id/projects/:project_id/tasks/:task_id", h.DeleteTask)
+		protected.POST("/:id/projects/:project_id/tasks/:task_id/status", h.UpdateTaskStatus)
+		protected.POST("/:id/projects/:project_id/tasks/:task_id/move", h.MoveTask)
+		protected.POST("/:id/projects/:project_id/tasks/move", h.MoveTasks)
+
+		protected.POST("/:id/projects/:project_id/tasks/:task_id/tags", h.AddTaskTags)
+		protected.DELETE("/:id/projects/:project_id/tasks/:task_id/tags/:tag", h.RemoveTaskTag)
+
+		protected.POST("/:id/projects/:project_id/tasks/:task_id/subtasks", h.CreateSubtask)
+		protected.PATCH("/:id/projects/:project_id/tasks/:task_id/subtasks/:subtask_id", h.UpdateSubtask)
+		protected.DELETE("/:id/projects/:project_id/tasks/:task_id/subtasks/:subtask_id", h.DeleteSubtask)
+
+		protected.GET("/:id/projects/:project_id/tasks/:task_id/dependencies", h.ListTaskDependencies)
+		protected.POST("/:id/projects/:project_id/tasks/:task_id/dependencies", h.AddTaskDependency)
+		protected.DELETE("/:id/projects/:project_id/tasks/:task_id/dependencies/:depends_on_id", h.RemoveTaskDependency)
+		protected.GET("/:id/projects/:project_id/schedule", h.GetProjectSchedule)
+	}
+}
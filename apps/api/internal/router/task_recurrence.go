@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterRecurringTaskRoutes(r *gin.RouterGroup, h *handler.RecurringTaskHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("/:id/projects/:project_id/recurring-tasks", h.CreateRecurringTask)
+		protected.GET("/:id/projects/:project_id/recurring-tasks", h.ListRecurringTasks)
+		protected.GET("/:id/projects/:project_id/recurring-tasks/:recurrence_id", h.GetRecurringTask)
+		protected.PATCH("/:id/projects/:project_id/recurring-tasks/:recurrence_id", h.UpdateRecurringTask)
+		protected.DELETE("/:id/projects/:project_id/recurring-tasks/:recurrence_id", h.DeleteRecurringTask)
+
+		protected.GET("/:id/projects/:project_id/recurring-tasks/:recurrence_id/occurrences", h.ListOccurrences)
+		protected.PUT("/:id/projects/:project_id/recurring-tasks/:recurrence_id/occurrences/:date", h.UpdateOccurrence)
+		protected.DELETE("/:id/projects/:project_id/recurring-tasks/:recurrence_id/occurrences/:date", h.ResetOccurrence)
+	}
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterTeamRoutes(r *gin.RouterGroup, h *handler.TeamHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces/:id/teams")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("", h.CreateTeam)
+		protected.GET("", h.ListTeams)
+		protected.GET("/:team_id", h.GetTeam)
+		protected.PATCH("/:team_id", h.UpdateTeam)
+		protected.DELETE("/:team_id", h.DeleteTeam)
+
+		protected.GET("/:team_id/members", h.ListTeamMembers)
+		protected.POST("/:team_id/members", h.AddTeamMember)
+		protected.DELETE("/:team_id/members/:user_id", h.RemoveTeamMember)
+	}
+}
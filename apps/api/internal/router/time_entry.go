@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterTimeEntryRoutes(r *gin.RouterGroup, h *handler.TimeEntryHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("/:id/time-entries/timer/start", h.StartTimer)
+		protected.POST("/:id/time-entries/timer/stop", h.StopTimer)
+		protected.GET("/:id/time-entries/timer", h.GetRunningTimer)
+
+		protected.POST("/:id/time-entries", h.CreateTimeEntry)
+		protected.GET("/:id/time-entries", h.ListTimeEntries)
+		protected.GET("/:id/time-entries/:entry_id", h.GetTimeEntry)
+		protected.PATCH("/:id/time-entries/:entry_id", h.UpdateTimeEntry)
+		protected.DELETE("/:id/time-entries/:entry_id", h.DeleteTimeEntry)
+	}
+}
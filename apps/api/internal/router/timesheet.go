@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lukabrkovic/artemis/internal/handler"
+	"github.com/lukabrkovic/artemis/internal/middleware"
+	"github.com/lukabrkovic/artemis/pkg/token"
+)
+
+func RegisterTimesheetRoutes(r *gin.RouterGroup, h *handler.TimesheetHandler, tokenMaker token.Maker) {
+	protected := r.Group("/workspaces")
+	protected.Use(middleware.Auth(tokenMaker))
+	{
+		protected.POST("/:id/timesheets/submit", h.SubmitTimesheet)
+		protected.GET("/:id/timesheets", h.ListTimesheets)
+		protected.GET("/:id/timesheets/:timesheet_id", h.GetTimesheet)
+		protected.POST("/:id/timesheets/:timesheet_id/approve", h.ApproveTimesheet)
+		protected.POST("/:id/timesheets/:timesheet_id/reject", h.RejectTimesheet)
+	}
+}
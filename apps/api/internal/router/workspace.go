@@ -20,6 +20,15 @@ func RegisterWorkspaceRoutes(r *gin.RouterGroup, h *handler.WorkspaceHandler, to
 		protected.POST("/:id/members", h.AddMember)
 		protected.GET("/:id/members", h.ListMembers)
 		protected.DELETE("/:id/members/:user_id", h.RemoveMember)
+		protected.POST("/:id/members/:user_id/deactivate", h.DeactivateMember)
+		protected.POST("/:id/members/:user_id/reactivate", h.ReactivateMember)
+		protected.POST("/:id/members/:user_id/reassign", h.ReassignMemberContent)
+		protected.PUT("/:id/members/:user_id/rate", h.SetMemberRate)
+		protected.POST("/:id/leave", h.LeaveWorkspace)
 		protected.POST("/:id/avatar", h.UploadAvatar)
+
+		protected.GET("/:id/settings", h.GetSettings)
+		protected.PATCH("/:id/settings", h.UpdateSettings)
+		protected.GET("/:id/usage", h.GetUsage)
 	}
 }
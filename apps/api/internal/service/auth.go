@@ -25,6 +25,8 @@ type Auth interface {
 	Login(ctx context.Context, input LoginInput, ip, userAgent string) (*AuthResult, error)
 	Refresh(ctx context.Context, refreshToken, ip, userAgent string) (*TokenResult, error)
 	Logout(ctx context.Context, refreshToken string) error
+	SendEmailVerification(ctx context.Context, userID uuid.UUID) error
+	VerifyEmail(ctx context.Context, token string) (*store.User, error)
 }
 
 type AuthService struct {
@@ -136,6 +138,12 @@ func (s *AuthService) Register(ctx context.Context, input RegisterInput, ip, use
 		})
 	}
 
+	if err == nil && result.User.Email != nil {
+		if verifyErr := s.SendEmailVerification(ctx, result.User.ID); verifyErr != nil {
+			s.logger.Warn().Err(verifyErr).Str("user_id", result.User.ID.String()).Msg("failed to send email verification after registration")
+		}
+	}
+
 	return result, err
 }
 
@@ -0,0 +1,498 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrClientNotFound                = errors.New("client not found")
+	ErrClientContactNotFound         = errors.New("client contact not found")
+	ErrInvalidClientStatusTransition = errors.New("invalid client status transition")
+)
+
+// clientTransitions lists the statuses a client may move to from each status.
+// Leads become active or are lost, and churned clients can be won back.
+var clientTransitions = map[string][]string{
+	"lead":     {"active", "churned"},
+	"active":   {"inactive", "churned"},
+	"inactive": {"active", "churned"},
+	"churned":  {"lead", "active"},
+}
+
+type CreateClientInput struct {
+	Name     string     `json:"name" validate:"required,min=2,max=255"`
+	Email    *string    `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Phone    *string    `json:"phone,omitempty" validate:"omitempty,max=50"`
+	Website  *string    `json:"website,omitempty" validate:"omitempty,url,max=500"`
+	Address  *string    `json:"address,omitempty" validate:"omitempty,max=1000"`
+	Notes    *string    `json:"notes,omitempty" validate:"omitempty,max=5000"`
+	Status   *string    `json:"status,omitempty" validate:"omitempty,oneof=lead active inactive churned"`
+	Currency *string    `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	Tags     []string   `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+	OwnerID  *uuid.UUID `json:"owner_id,omitempty"`
+}
+
+// UpdateClientInput only changes the fields that are set. An empty owner_id
+// clears it. The status is changed through UpdateClientStatus.
+type UpdateClientInput struct {
+	Name     *string  `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
+	Email    *string  `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Phone    *string  `json:"phone,omitempty" validate:"omitempty,max=50"`
+	Website  *string  `json:"website,omitempty" validate:"omitempty,url,max=500"`
+	Address  *string  `json:"address,omitempty" validate:"omitempty,max=1000"`
+	Notes    *string  `json:"notes,omitempty" validate:"omitempty,max=5000"`
+	Currency *string  `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	Tags     []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+	OwnerID  *string  `json:"owner_id,omitempty" validate:"omitempty,uuid"`
+}
+
+type UpdateClientStatusInput struct {
+	Status string  `json:"status" validate:"required,oneof=lead active inactive churned"`
+	Note   *string `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+type CreateClientContactInput struct {
+	Name      string  `json:"name" validate:"required,min=1,max=255"`
+	Email     *string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Phone     *string `json:"phone,omitempty" validate:"omitempty,max=50"`
+	Title     *string `json:"title,omitempty" validate:"omitempty,max=255"`
+	IsPrimary bool    `json:"is_primary"`
+}
+
+type UpdateClientContactInput struct {
+	Name      *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Email     *string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Phone     *string `json:"phone,omitempty" validate:"omitempty,max=50"`
+	Title     *string `json:"title,omitempty" validate:"omitempty,max=255"`
+	IsPrimary *bool   `json:"is_primary,omitempty"`
+}
+
+type Client interface {
+	CreateClient(ctx context.Context, userID, workspaceID uuid.UUID, input CreateClientInput) (*store.Client, error)
+	GetClient(ctx context.Context, userID, workspaceID, clientID uuid.UUID) (*store.Client, error)
+	ListClients(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Client], error)
+	UpdateClient(ctx context.Context, userID, workspaceID, clientID uuid.UUID, input UpdateClientInput) (*store.Client, error)
+	UpdateClientStatus(ctx context.Context, userID, workspaceID, clientID uuid.UUID, input UpdateClientStatusInput) (*store.Client, error)
+	DeleteClient(ctx context.Context, userID, workspaceID, clientID uuid.UUID) error
+	ListClientStatusHistory(ctx context.Context, userID, workspaceID, clientID uuid.UUID) ([]store.ClientStatusChange, error)
+
+	ListClientContacts(ctx context.Context, userID, workspaceID, clientID uuid.UUID) ([]store.ClientContact, error)
+	CreateClientContact(ctx context.Context, userID, workspaceID, clientID uuid.UUID, input CreateClientContactInput) (*store.ClientContact, error)
+	UpdateClientContact(ctx context.Context, userID, workspaceID, clientID, contactID uuid.UUID, input UpdateClientContactInput) (*store.ClientContact, error)
+	DeleteClientContact(ctx context.Context, userID, workspaceID, clientID, contactID uuid.UUID) error
+}
+
+type ClientService struct {
+	store    *store.Store
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewClientService(store *store.Store, eventBus EventPublisher, logger zerolog.Logger) *ClientService {
+	return &ClientService{
+		store:    store,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "client_service").Logger(),
+	}
+}
+
+var _ Client = (*ClientService)(nil)
+
+// CreateClient adds a client to the workspace, owned by the caller unless
+// owner_id is given. New clients start as leads.
+func (s *ClientService) CreateClient(ctx context.Context, userID, workspaceID uuid.UUID, input CreateClientInput) (*store.Client, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := store.CreateClientParams{
+		WorkspaceID: workspaceID,
+		Name:        strings.TrimSpace(input.Name),
+		Email:       input.Email,
+		Phone:       input.Phone,
+		Website:     input.Website,
+		Address:     input.Address,
+		Notes:       input.Notes,
+		Status:      "lead",
+		Currency:    settings.Settings.DefaultCurrency,
+		Tags:        normalizeTags(input.Tags),
+		OwnerID:     &userID,
+		CreatedBy:   userID,
+	}
+	if input.Status != nil {
+		params.Status = *input.Status
+	}
+	if input.Currency != nil {
+		params.Currency = strings.ToUpper(*input.Currency)
+	}
+	if input.OwnerID != nil {
+		params.OwnerID = input.OwnerID
+	}
+
+	if err := checkAssignee(ctx, s.store, workspaceID, params.OwnerID); err != nil {
+		return nil, err
+	}
+
+	var client *store.Client
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		var err error
+		if client, err = tx.Clients.CreateClient(ctx, params); err != nil {
+			return err
+		}
+		return tx.Clients.CreateClientStatusChange(ctx, client.ID, nil, client.Status, nil, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// GetClient returns the client with its contacts.
+func (s *ClientService) GetClient(ctx context.Context, userID, workspaceID, clientID uuid.UUID) (*store.Client, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	client, err := s.getClient(ctx, workspaceID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Contacts, err = s.store.Clients.ListClientContacts(ctx, client.ID); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (s *ClientService) ListClients(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Client], error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	filter := store.ClientFilter{
+		Tag: filters.Filters["tag"],
+	}
+	if value := filters.Filters["status"]; value != "" {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.TrimSpace(status)
+			if clientTransitions[status] == nil {
+				return nil, ErrInvalidFilter
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	var err error
+	switch value := filters.Filters["owner_id"]; value {
+	case "me":
+		filter.OwnerID = &userID
+	default:
+		if filter.OwnerID, err = parseUUIDFilter(value); err != nil {
+			return nil, err
+		}
+	}
+	if filter.HasOutstanding, err = parseBoolFilter(filters.Filters["has_outstanding"]); err != nil {
+		return nil, err
+	}
+	if filter.HasActiveProjects, err = parseBoolFilter(filters.Filters["has_active_projects"]); err != nil {
+		return nil, err
+	}
+
+	clients, total, err := s.store.Clients.ListClients(ctx, workspaceID, filter, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.BuildFilterResponse(clients, total, filters), nil
+}
+
+func (s *ClientService) UpdateClient(ctx context.Context, userID, workspaceID, clientID uuid.UUID, input UpdateClientInput) (*store.Client, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	client, err := s.getClient(ctx, workspaceID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := clientUpdateParams(client)
+	if input.Name != nil {
+		params.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Email != nil {
+		params.Email = input.Email
+	}
+	if input.Phone != nil {
+		params.Phone = input.Phone
+	}
+	if input.Website != nil {
+		params.Website = input.Website
+	}
+	if input.Address != nil {
+		params.Address = input.Address
+	}
+	if input.Notes != nil {
+		params.Notes = input.Notes
+	}
+	if input.Currency != nil {
+		params.Currency = strings.ToUpper(*input.Currency)
+	}
+	if input.Tags != nil {
+		params.Tags = normalizeTags(input.Tags)
+	}
+	if input.OwnerID != nil {
+		if params.OwnerID, err = parseUUIDFilter(*input.OwnerID); err != nil {
+			return nil, err
+		}
+		if err := checkAssignee(ctx, s.store, workspaceID, params.OwnerID); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.store.Clients.UpdateClient(ctx, params)
+	if err != nil {
+		if errors.Is(err, store.ErrClientNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// UpdateClientStatus moves a client along the pipeline and records the change
+// in its status history.
+func (s *ClientService) UpdateClientStatus(ctx context.Context, userID, workspaceID, clientID uuid.UUID, input UpdateClientStatusInput) (*store.Client, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	client, err := s.getClient(ctx, workspaceID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client.Status == input.Status {
+		return client, nil
+	}
+	if !slices.Contains(clientTransitions[client.Status], input.Status) {
+		return nil, ErrInvalidClientStatusTransition
+	}
+
+	params := clientUpdateParams(client)
+	params.Status = input.Status
+
+	var updated *store.Client
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		var err error
+		if updated, err = tx.Clients.UpdateClient(ctx, params); err != nil {
+			return err
+		}
+		return tx.Clients.CreateClientStatusChange(ctx, client.ID, &client.Status, input.Status, trimDescription(input.Note), userID)
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrClientNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// DeleteClient is restricted to workspace admins. Projects keep pointing at
+// the deleted client.
+func (s *ClientService) DeleteClient(ctx context.Context, userID, workspaceID, clientID uuid.UUID) error {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+
+	if err := s.store.Clients.DeleteClient(ctx, workspaceID, clientID); err != nil {
+		if errors.Is(err, store.ErrClientNotFound) {
+			return ErrClientNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *ClientService) ListClientStatusHistory(ctx context.Context, userID, workspaceID, clientID uuid.UUID) ([]store.ClientStatusChange, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getClient(ctx, workspaceID, clientID); err != nil {
+		return nil, err
+	}
+
+	return s.store.Clients.ListClientStatusChanges(ctx, clientID)
+}
+
+func (s *ClientService) ListClientContacts(ctx context.Context, userID, workspaceID, clientID uuid.UUID) ([]store.ClientContact, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getClient(ctx, workspaceID, clientID); err != nil {
+		return nil, err
+	}
+
+	return s.store.Clients.ListClientContacts(ctx, clientID)
+}
+
+// CreateClientContact adds a contact person. Making it the primary contact
+// demotes the previous one.
+func (s *ClientService) CreateClientContact(ctx context.Context, userID, workspaceID, clientID uuid.UUID, input CreateClientContactInput) (*store.ClientContact, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getClient(ctx, workspaceID, clientID); err != nil {
+		return nil, err
+	}
+
+	params := store.ClientContactParams{
+		ClientID:  clientID,
+		Name:      strings.TrimSpace(input.Name),
+		Email:     input.Email,
+		Phone:     input.Phone,
+		Title:     input.Title,
+		IsPrimary: input.IsPrimary,
+	}
+
+	var contact *store.ClientContact
+	err := s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if params.IsPrimary {
+			if err := tx.Clients.ClearPrimaryClientContact(ctx, clientID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		contact, err = tx.Clients.CreateClientContact(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+func (s *ClientService) UpdateClientContact(ctx context.Context, userID, workspaceID, clientID, contactID uuid.UUID, input UpdateClientContactInput) (*store.ClientContact, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getClient(ctx, workspaceID, clientID); err != nil {
+		return nil, err
+	}
+
+	contact, err := s.store.Clients.GetClientContact(ctx, clientID, contactID)
+	if err != nil {
+		if errors.Is(err, store.ErrClientContactNotFound) {
+			return nil, ErrClientContactNotFound
+		}
+		return nil, err
+	}
+
+	params := store.ClientContactParams{
+		ID:        contact.ID,
+		ClientID:  clientID,
+		Name:      contact.Name,
+		Email:     contact.Email,
+		Phone:     contact.Phone,
+		Title:     contact.Title,
+		IsPrimary: contact.IsPrimary,
+	}
+	if input.Name != nil {
+		params.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Email != nil {
+		params.Email = input.Email
+	}
+	if input.Phone != nil {
+		params.Phone = input.Phone
+	}
+	if input.Title != nil {
+		params.Title = input.Title
+	}
+	if input.IsPrimary != nil {
+		params.IsPrimary = *input.IsPrimary
+	}
+
+	var updated *store.ClientContact
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if params.IsPrimary && !contact.IsPrimary {
+			if err := tx.Clients.ClearPrimaryClientContact(ctx, clientID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		updated, err = tx.Clients.UpdateClientContact(ctx, params)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrClientContactNotFound) {
+			return nil, ErrClientContactNotFound
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *ClientService) DeleteClientContact(ctx context.Context, userID, workspaceID, clientID, contactID uuid.UUID) error {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+	if _, err := s.getClient(ctx, workspaceID, clientID); err != nil {
+		return err
+	}
+
+	if err := s.store.Clients.DeleteClientContact(ctx, clientID, contactID); err != nil {
+		if errors.Is(err, store.ErrClientContactNotFound) {
+			return ErrClientContactNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *ClientService) getClient(ctx context.Context, workspaceID, clientID uuid.UUID) (*store.Client, error) {
+	client, err := s.store.Clients.GetClient(ctx, workspaceID, clientID)
+	if err != nil {
+		if errors.Is(err, store.ErrClientNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+	return client, nil
+}
+
+func clientUpdateParams(client *store.Client) store.UpdateClientParams {
+	return store.UpdateClientParams{
+		ID:          client.ID,
+		WorkspaceID: client.WorkspaceID,
+		Name:        client.Name,
+		Email:       client.Email,
+		Phone:       client.Phone,
+		Website:     client.Website,
+		Address:     client.Address,
+		Notes:       client.Notes,
+		Status:      client.Status,
+		Currency:    client.Currency,
+		Tags:        client.Tags,
+		OwnerID:     client.OwnerID,
+	}
+}
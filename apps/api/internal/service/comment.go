@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrCommentNotFound      = errors.New("comment not found")
+	ErrInvalidCommentParent = errors.New("parent comment must belong to the same resource")
+)
+
+const (
+	CommentOnTask    = "task"
+	CommentOnProject = "project"
+)
+
+const commentExcerptLength = 140
+
+// CommentTarget identifies the resource a comment thread belongs to. ProjectID
+// is set for resources nested in a project.
+type CommentTarget struct {
+	Type      string
+	ID        uuid.UUID
+	ProjectID uuid.UUID
+}
+
+// commentTargets checks that a comment target exists in the workspace, keyed by
+// resource type. Commentable resources register themselves here.
+var commentTargets = map[string]func(ctx context.Context, st *store.Store, workspaceID uuid.UUID, target CommentTarget) error{
+	CommentOnProject: func(ctx context.Context, st *store.Store, workspaceID uuid.UUID, target CommentTarget) error {
+		if _, err := st.Projects.GetProject(ctx, workspaceID, target.ID); err != nil {
+			if errors.Is(err, store.ErrProjectNotFound) {
+				return ErrProjectNotFound
+			}
+			return err
+		}
+		return nil
+	},
+	CommentOnTask: func(ctx context.Context, st *store.Store, workspaceID uuid.UUID, target CommentTarget) error {
+		if _, err := st.Projects.GetProject(ctx, workspaceID, target.ProjectID); err != nil {
+			if errors.Is(err, store.ErrProjectNotFound) {
+				return ErrProjectNotFound
+			}
+			return err
+		}
+		task, err := st.Tasks.GetTask(ctx, workspaceID, target.ID)
+		if err != nil {
+			if errors.Is(err, store.ErrTaskNotFound) {
+				return ErrTaskNotFound
+			}
+			return err
+		}
+		if task.ProjectID != target.ProjectID {
+			return ErrTaskNotFound
+		}
+		return nil
+	},
+}
+
+type CreateCommentInput struct {
+	Body     string     `json:"body" validate:"required,min=1,max=20000"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+type UpdateCommentInput struct {
+	Body string `json:"body" validate:"required,min=1,max=20000"`
+}
+
+type Comment interface {
+	CreateComment(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget, input CreateCommentInput) (*store.Comment, error)
+	ListComments(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget, filters store.FilterParams) (*store.PaginatedResponse[store.Comment], error)
+	UpdateComment(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget, commentID uuid.UUID, input UpdateCommentInput) (*store.Comment, error)
+	DeleteComment(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget, commentID uuid.UUID) error
+	ListCommentRevisions(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget, commentID uuid.UUID) ([]store.CommentRevision, error)
+}
+
+type CommentService struct {
+	store    *store.Store
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewCommentService(store *store.Store, eventBus EventPublisher, logger zerolog.Logger) *CommentService {
+	return &CommentService{
+		store:    store,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "comment_service").Logger(),
+	}
+}
+
+var _ Comment = (*CommentService)(nil)
+
+// CreateComment adds a comment or a reply. Replies to a reply join the thread
+// of the top-level comment, so threads are one level deep.
+func (s *CommentService) CreateComment(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget, input CreateCommentInput) (*store.Comment, error) {
+	if err := s.checkAccess(ctx, userID, workspaceID, target); err != nil {
+		return nil, err
+	}
+
+	params := store.CreateCommentParams{
+		WorkspaceID:  workspaceID,
+		ResourceType: target.Type,
+		ResourceID:   target.ID,
+		AuthorID:     userID,
+		Body:         strings.TrimSpace(input.Body),
+	}
+
+	if input.ParentID != nil {
+		parent, err := s.getComment(ctx, workspaceID, target, *input.ParentID)
+		if err != nil {
+			if errors.Is(err, ErrCommentNotFound) {
+				return nil, ErrInvalidCommentParent
+			}
+			return nil, err
+		}
+		params.ParentID = &parent.ID
+		if parent.ParentID != nil {
+			params.ParentID = parent.ParentID
+		}
+	}
+
+	var err error
+	if params.Mentions, err = resolveMentions(ctx, s.store, workspaceID, params.Body); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.store.Comments.CreateComment(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishMentions(ctx, userID, target, comment, comment.Mentions)
+
+	return comment, nil
+}
+
+// ListComments returns the top-level comments of the resource, each with its
+// replies oldest first.
+func (s *CommentService) ListComments(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget, filters store.FilterParams) (*store.PaginatedResponse[store.Comment], error) {
+	if err := s.checkAccess(ctx, userID, workspaceID, target); err != nil {
+		return nil, err
+	}
+
+	comments, total, err := s.store.Comments.ListComments(ctx, workspaceID, target.Type, target.ID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(comments) > 0 {
+		ids := make([]uuid.UUID, len(comments))
+		index := make(map[uuid.UUID]int, len(comments))
+		for i, comment := range comments {
+			ids[i] = comment.ID
+			index[comment.ID] = i
+		}
+
+		replies, err := s.store.Comments.ListReplies(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, reply := range replies {
+			i := index[*reply.ParentID]
+			comments[i].Replies = append(comments[i].Replies, reply)
+		}
+	}
+
+	return store.BuildFilterResponse(comments, total, filters), nil
+}
+
+// UpdateComment lets the author change the body. The previous body is kept in
+// the edit history and only newly mentioned members are notified.
+func (s *CommentService) UpdateComment(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget, commentID uuid.UUID, input UpdateCommentInput) (*store.Comment, error) {
+	if err := s.checkAccess(ctx, userID, workspaceID, target); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.getComment(ctx, workspaceID, target, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if !sameUUID(comment.AuthorID, &userID) {
+		return nil, ErrForbidden
+	}
+
+	body := strings.TrimSpace(input.Body)
+	if body == comment.Body {
+		return comment, nil
+	}
+
+	mentions, err := resolveMentions(ctx, s.store, workspaceID, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *store.Comment
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := tx.Comments.CreateCommentRevision(ctx, comment.ID, comment.Body, userID); err != nil {
+			return err
+		}
+		var err error
+		updated, err = tx.Comments.UpdateComment(ctx, workspaceID, comment.ID, body, mentions)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrCommentNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+
+	previous := make(map[uuid.UUID]bool, len(comment.Mentions))
+	for _, id := range comment.Mentions {
+		previous[id] = true
+	}
+	var added store.UUIDs
+	for _, id := range updated.Mentions {
+		if !previous[id] {
+			added = append(added, id)
+		}
+	}
+	s.publishMentions(ctx, userID, target, updated, added)
+
+	return updated, nil
+}
+
+// DeleteComment removes a comment and its replies. Authors can delete their
+// own comments, workspace admins any comment.
+func (s *CommentService) DeleteComment(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget, commentID uuid.UUID) error {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkTarget(ctx, workspaceID, target); err != nil {
+		return err
+	}
+
+	comment, err := s.getComment(ctx, workspaceID, target, commentID)
+	if err != nil {
+		return err
+	}
+	if role != "owner" && role != "admin" && !sameUUID(comment.AuthorID, &userID) {
+		return ErrForbidden
+	}
+
+	if err := s.store.Comments.DeleteComment(ctx, workspaceID, comment.ID); err != nil {
+		if errors.Is(err, store.ErrCommentNotFound) {
+			return ErrCommentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ListCommentRevisions returns the previous bodies of a comment, newest first.
+func (s *CommentService) ListCommentRevisions(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget, commentID uuid.UUID) ([]store.CommentRevision, error) {
+	if err := s.checkAccess(ctx, userID, workspaceID, target); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.getComment(ctx, workspaceID, target, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.store.Comments.ListCommentRevisions(ctx, comment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		revisions = []store.CommentRevision{}
+	}
+	return revisions, nil
+}
+
+func (s *CommentService) checkAccess(ctx context.Context, userID, workspaceID uuid.UUID, target CommentTarget) error {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+	return s.checkTarget(ctx, workspaceID, target)
+}
+
+func (s *CommentService) checkTarget(ctx context.Context, workspaceID uuid.UUID, target CommentTarget) error {
+	check, ok := commentTargets[target.Type]
+	if !ok {
+		return ErrCommentNotFound
+	}
+	return check(ctx, s.store, workspaceID, target)
+}
+
+// getComment loads a comment of the target. Comments addressed through another
+// resource are reported as not found.
+func (s *CommentService) getComment(ctx context.Context, workspaceID uuid.UUID, target CommentTarget, commentID uuid.UUID) (*store.Comment, error) {
+	comment, err := s.store.Comments.GetComment(ctx, workspaceID, commentID)
+	if err != nil {
+		if errors.Is(err, store.ErrCommentNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	if comment.ResourceType != target.Type || comment.ResourceID != target.ID {
+		return nil, ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+// publishMentions notifies the mentioned members except the author.
+func (s *CommentService) publishMentions(ctx context.Context, userID uuid.UUID, target CommentTarget, comment *store.Comment, mentioned store.UUIDs) {
+	if s.eventBus == nil {
+		return
+	}
+
+	excerpt := []rune(comment.Body)
+	if len(excerpt) > commentExcerptLength {
+		excerpt = append(excerpt[:commentExcerptLength], '…')
+	}
+
+	for _, mentionedID := range mentioned {
+		if mentionedID == userID {
+			continue
+		}
+		payload := map[string]any{
+			"workspace_id":      comment.WorkspaceID,
+			"comment_id":        comment.ID,
+			"resource_type":     comment.ResourceType,
+			"resource_id":       comment.ResourceID,
+			"mentioned_user_id": mentionedID,
+			"author_id":         userID,
+			"excerpt":           string(excerpt),
+		}
+		if target.ProjectID != uuid.Nil {
+			payload["project_id"] = target.ProjectID
+		}
+		s.eventBus.Publish(ctx, events.EventCommentMentioned, userID, payload)
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+)
+
+// emailVerificationExpiry is how long a verification link can be used.
+const emailVerificationExpiry = 24 * time.Hour
+
+var (
+	ErrNoEmail                  = errors.New("account has no email address")
+	ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+	ErrEmailVerificationInvalid = errors.New("email verification link is invalid or expired")
+)
+
+// SendEmailVerification emails the user a link that verifies their current
+// email address. Verified addresses can join workspaces by email domain.
+func (s *AuthService) SendEmailVerification(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.store.Users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Email == nil {
+		return ErrNoEmail
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := generateLinkToken()
+	if err != nil {
+		return err
+	}
+	verification, err := s.store.EmailVerifications.CreateEmailVerification(ctx, store.CreateEmailVerificationParams{
+		TokenHash: hashLinkToken(token),
+		UserID:    user.ID,
+		Email:     *user.Email,
+		ExpiresAt: time.Now().Add(emailVerificationExpiry),
+	})
+	if err != nil {
+		return err
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventEmailVerificationSent, user.ID, map[string]any{
+			"user_id":    user.ID,
+			"name":       user.Name,
+			"email":      verification.Email,
+			"token":      token,
+			"expires_at": verification.ExpiresAt,
+		})
+	}
+	return nil
+}
+
+// VerifyEmail marks the address a verification link was sent to as verified.
+// A link sent before the user changed their email verifies nothing.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) (*store.User, error) {
+	var userID uuid.UUID
+	err := s.store.ExecTx(ctx, func(tx *store.Store) error {
+		verification, err := tx.EmailVerifications.ConsumeEmailVerification(ctx, hashLinkToken(token))
+		if err != nil {
+			return err
+		}
+		userID = verification.UserID
+		return tx.EmailVerifications.MarkEmailVerified(ctx, verification.UserID, verification.Email)
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrEmailVerificationInvalid) {
+			return nil, ErrEmailVerificationInvalid
+		}
+		return nil, err
+	}
+
+	if cacheErr := s.cache.DeleteUser(ctx, userID); cacheErr != nil {
+		s.logger.Warn().Err(cacheErr).Str("user_id", userID.String()).Msg("failed to drop user from cache after email verification")
+	}
+
+	user, err := s.store.Users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventEmailVerified, user.ID, map[string]any{
+			"user_id": user.ID,
+			"email":   user.Email,
+		})
+	}
+	return user, nil
+}
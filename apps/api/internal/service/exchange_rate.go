@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/exchange"
+	"github.com/lukabrkovic/artemis/pkg/money"
+	"github.com/lukabrkovic/artemis/pkg/rrule"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrExchangeRateUnavailable = errors.New("exchange rate unavailable")
+	ErrSameCurrency            = errors.New("from and to must be different currencies")
+)
+
+// exchangeRateBatchSize is how many documents a snapshot run reads at a time.
+const exchangeRateBatchSize = 500
+
+type ExchangeRateInput struct {
+	From string  `json:"from" validate:"required,iso4217"`
+	To   string  `json:"to" validate:"required,iso4217"`
+	Date *string `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type ExchangeRate interface {
+	GetExchangeRate(ctx context.Context, userID, workspaceID uuid.UUID, input ExchangeRateInput) (*store.ExchangeRate, error)
+	SnapshotExchangeRates(ctx context.Context) (int, error)
+}
+
+type ExchangeRateService struct {
+	store    *store.Store
+	provider exchange.Provider
+	logger   zerolog.Logger
+}
+
+func NewExchangeRateService(store *store.Store, provider exchange.Provider, logger zerolog.Logger) *ExchangeRateService {
+	return &ExchangeRateService{
+		store:    store,
+		provider: provider,
+		logger:   logger.With().Str("component", "exchange_rate_service").Logger(),
+	}
+}
+
+var _ ExchangeRate = (*ExchangeRateService)(nil)
+
+// GetExchangeRate returns the rate between two currencies on a date, today in
+// the workspace timezone by default. It returns the stored rate when there is
+// one and asks the provider otherwise, without storing its answer.
+func (s *ExchangeRateService) GetExchangeRate(ctx context.Context, userID, workspaceID uuid.UUID, input ExchangeRateInput) (*store.ExchangeRate, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	from, to := strings.ToUpper(input.From), strings.ToUpper(input.To)
+	if from == to {
+		return nil, ErrSameCurrency
+	}
+
+	date, err := parseDate(input.Date)
+	if err != nil {
+		return nil, err
+	}
+	if date == nil {
+		settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		today := workspaceToday(settings.Settings.Timezone)
+		date = &today
+	}
+
+	return s.quote(ctx, from, to, *date)
+}
+
+// SnapshotExchangeRates records on every issued invoice, payment and expense
+// the rate from its currency to the workspace base currency on the document
+// date. Documents whose rate is not available or not final yet are tried
+// again on the next run. It is safe to run on several replicas at once.
+func (s *ExchangeRateService) SnapshotExchangeRates(ctx context.Context) (int, error) {
+	bases := map[uuid.UUID]string{}
+
+	invoices, err := s.snapshot(ctx, bases, s.store.ExchangeRates.ListUnratedInvoices, s.store.ExchangeRates.SetInvoiceExchangeRate)
+	if err != nil {
+		return invoices, err
+	}
+	payments, err := s.snapshot(ctx, bases, s.store.ExchangeRates.ListUnratedPayments, s.store.ExchangeRates.SetPaymentExchangeRate)
+	if err != nil {
+		return invoices + payments, err
+	}
+	expenses, err := s.snapshot(ctx, bases, s.store.ExchangeRates.ListUnratedExpenses, s.store.ExchangeRates.SetExpenseExchangeRate)
+	if err != nil {
+		return invoices + payments + expenses, err
+	}
+
+	if invoices+payments+expenses > 0 {
+		s.logger.Info().Int("invoices", invoices).Int("payments", payments).Int("expenses", expenses).Msg("recorded exchange rates")
+	}
+	return invoices + payments + expenses, nil
+}
+
+type listUnratedFunc func(ctx context.Context, after uuid.UUID, limit int) ([]store.UnratedDocument, error)
+
+type setRateFunc func(ctx context.Context, id uuid.UUID, baseCurrency string, rate money.Rate) error
+
+func (s *ExchangeRateService) snapshot(ctx context.Context, bases map[uuid.UUID]string, list listUnratedFunc, set setRateFunc) (int, error) {
+	// A rate the provider lacks is asked for only once per run.
+	unavailable := map[string]bool{}
+
+	rated := 0
+	after := uuid.Nil
+	for {
+		documents, err := list(ctx, after, exchangeRateBatchSize)
+		if err != nil {
+			return rated, err
+		}
+
+		for _, document := range documents {
+			if err := ctx.Err(); err != nil {
+				return rated, err
+			}
+			after = document.ID
+
+			base, ok := bases[document.WorkspaceID]
+			if !ok {
+				settings, err := getWorkspaceSettings(ctx, s.store, document.WorkspaceID)
+				if err != nil {
+					if errors.Is(err, ErrWorkspaceNotFound) {
+						continue
+					}
+					return rated, err
+				}
+				base = strings.ToUpper(settings.Settings.DefaultCurrency)
+				bases[document.WorkspaceID] = base
+			}
+
+			key := fmt.Sprintf("%s/%s/%s", document.Currency, base, document.Date.Format(dateLayout))
+			if unavailable[key] {
+				continue
+			}
+			rate, final, err := s.finalRate(ctx, document.Currency, base, document.Date)
+			if err != nil {
+				if errors.Is(err, ErrExchangeRateUnavailable) {
+					unavailable[key] = true
+					continue
+				}
+				return rated, err
+			}
+			if !final {
+				unavailable[key] = true
+				continue
+			}
+
+			if err := set(ctx, document.ID, base, rate); err != nil {
+				return rated, err
+			}
+			rated++
+		}
+
+		if len(documents) < exchangeRateBatchSize {
+			return rated, nil
+		}
+	}
+}
+
+// rate returns the units of to that one unit of from bought on date.
+func (s *ExchangeRateService) rate(ctx context.Context, from, to string, date time.Time) (money.Rate, error) {
+	rate, _, err := s.finalRate(ctx, from, to, date)
+	return rate, err
+}
+
+// finalRate is rate that also reports whether the rate is final. A rate that
+// is not final may still change and is not stored.
+func (s *ExchangeRateService) finalRate(ctx context.Context, from, to string, date time.Time) (money.Rate, bool, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return money.OneRate, true, nil
+	}
+	stored, err := s.lookup(ctx, from, to, date)
+	if err != nil {
+		return 0, false, err
+	}
+	return stored.Rate, stored.ID != uuid.Nil, nil
+}
+
+// lookup returns the rate for the date, asking the provider and storing its
+// answer the first time. A provider answer that is not final is returned
+// unstored, with a zero ID.
+func (s *ExchangeRateService) lookup(ctx context.Context, from, to string, date time.Time) (*store.ExchangeRate, error) {
+	rate, err := s.quote(ctx, from, to, date)
+	if err != nil || rate.ID != uuid.Nil || !finalQuote(rate, rrule.Date(time.Now().UTC())) {
+		return rate, err
+	}
+
+	return s.store.ExchangeRates.SaveExchangeRate(ctx, store.SaveExchangeRateParams{
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		RateDate:      rate.RateDate,
+		Rate:          rate.Rate,
+		PublishedOn:   rate.PublishedOn,
+		Source:        rate.Source,
+	})
+}
+
+// quote returns the stored rate for the date, else the answer of the
+// provider without storing it.
+func (s *ExchangeRateService) quote(ctx context.Context, from, to string, date time.Time) (*store.ExchangeRate, error) {
+	stored, err := s.store.ExchangeRates.GetExchangeRate(ctx, from, to, date)
+	if err == nil {
+		return stored, nil
+	}
+	if !errors.Is(err, store.ErrExchangeRateNotFound) {
+		return nil, err
+	}
+
+	quote, err := s.provider.Rate(ctx, from, to, date)
+	if err != nil {
+		if errors.Is(err, exchange.ErrRateUnavailable) {
+			return nil, fmt.Errorf("%w: %s to %s on %s", ErrExchangeRateUnavailable, from, to, date.Format(dateLayout))
+		}
+		return nil, err
+	}
+
+	return &store.ExchangeRate{
+		BaseCurrency:  from,
+		QuoteCurrency: to,
+		RateDate:      date,
+		Rate:          quote.Rate,
+		PublishedOn:   quote.Date,
+		Source:        quote.Source,
+	}, nil
+}
+
+// finalQuote reports whether a provider answer can be stored for good. Rates
+// for dates after today are never final. A rate published before its date,
+// as on weekends or before the day's rate is out, is final only once the date
+// is more than a day past, when no rate of its own will be published any more.
+func finalQuote(rate *store.ExchangeRate, today time.Time) bool {
+	if rate.RateDate.After(today) {
+		return false
+	}
+	return !rate.PublishedOn.Before(rate.RateDate) || rate.RateDate.Before(today.AddDate(0, 0, -1))
+}
@@ -0,0 +1,925 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	pkgstorage "github.com/lukabrkovic/artemis/pkg/storage"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrExpenseNotFound          = errors.New("expense not found")
+	ErrExpenseCategoryNotFound  = errors.New("expense category not found")
+	ErrExpenseCategoryNameTaken = errors.New("expense category name already taken")
+	ErrExpenseNotPending        = errors.New("expense is not awaiting review")
+	ErrExpenseNotReimbursable   = errors.New("only approved expenses paid by the submitter can be reimbursed")
+	ErrExpenseLocked            = errors.New("approved, reimbursed and billed expenses cannot be changed")
+	ErrExpenseClientRequired    = errors.New("a billable expense needs a client; pass client_id or a project with a client")
+	ErrExpenseReceiptNotFound   = errors.New("expense has no receipt")
+	ErrNoReceiptSuggestions     = errors.New("expense has no receipt suggestions waiting to be confirmed")
+)
+
+const expenseReceiptURLExpiry = 15 * time.Minute
+
+type ExpenseCategoryInput struct {
+	Name        string  `json:"name" validate:"required,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Color       *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+}
+
+// UpdateExpenseCategoryInput only changes the fields that are set.
+type UpdateExpenseCategoryInput struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Color       *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
+}
+
+// CreateExpenseInput records money spent. Currency defaults to the workspace
+// currency and client_id to the project's client. Expenses paid with a
+// company card are not reimbursable unless reimbursable says otherwise.
+type CreateExpenseInput struct {
+	CategoryID    *uuid.UUID `json:"category_id,omitempty"`
+	ProjectID     *uuid.UUID `json:"project_id,omitempty"`
+	ClientID      *uuid.UUID `json:"client_id,omitempty"`
+	Merchant      string     `json:"merchant" validate:"required,min=1,max=255"`
+	Description   *string    `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Amount        int64      `json:"amount" validate:"required,min=1"`
+	Currency      *string    `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	SpentOn       string     `json:"spent_on" validate:"required,datetime=2006-01-02"`
+	PaymentMethod string     `json:"payment_method" validate:"required,oneof=personal_card company_card cash bank_transfer other"`
+	Reimbursable  *bool      `json:"reimbursable,omitempty"`
+	Billable      *bool      `json:"billable,omitempty"`
+	Notes         *string    `json:"notes,omitempty" validate:"omitempty,max=5000"`
+}
+
+// UpdateExpenseInput only changes the fields that are set. An empty
+// category_id, project_id or client_id clears it.
+type UpdateExpenseInput struct {
+	CategoryID    *string `json:"category_id,omitempty" validate:"omitempty,uuid"`
+	ProjectID     *string `json:"project_id,omitempty" validate:"omitempty,uuid"`
+	ClientID      *string `json:"client_id,omitempty" validate:"omitempty,uuid"`
+	Merchant      *string `json:"merchant,omitempty" validate:"omitempty,min=1,max=255"`
+	Description   *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Amount        *int64  `json:"amount,omitempty" validate:"omitempty,min=1"`
+	Currency      *string `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	SpentOn       *string `json:"spent_on,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	PaymentMethod *string `json:"payment_method,omitempty" validate:"omitempty,oneof=personal_card company_card cash bank_transfer other"`
+	Reimbursable  *bool   `json:"reimbursable,omitempty"`
+	Billable      *bool   `json:"billable,omitempty"`
+	Notes         *string `json:"notes,omitempty" validate:"omitempty,max=5000"`
+}
+
+type RejectExpenseInput struct {
+	Reason string `json:"reason" validate:"required,min=1,max=1000"`
+}
+
+// ReimburseExpenseInput records a reimbursement, made now unless
+// reimbursed_at says otherwise.
+type ReimburseExpenseInput struct {
+	Reference    *string    `json:"reference,omitempty" validate:"omitempty,max=255"`
+	ReimbursedAt *time.Time `json:"reimbursed_at,omitempty"`
+}
+
+// ConfirmReceiptSuggestionsInput picks the suggestions read off the receipt
+// to apply, all of them when Fields is empty. Confirming the amount applies
+// its currency as well.
+type ConfirmReceiptSuggestionsInput struct {
+	Fields []string `json:"fields,omitempty" validate:"omitempty,dive,oneof=merchant amount spent_on"`
+}
+
+// ExpenseReceipt is a short-lived link to the receipt of an expense.
+type ExpenseReceipt struct {
+	URL         string    `json:"url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+}
+
+type Expense interface {
+	CreateExpenseCategory(ctx context.Context, userID, workspaceID uuid.UUID, input ExpenseCategoryInput) (*store.ExpenseCategory, error)
+	ListExpenseCategories(ctx context.Context, userID, workspaceID uuid.UUID) ([]store.ExpenseCategory, error)
+	UpdateExpenseCategory(ctx context.Context, userID, workspaceID, categoryID uuid.UUID, input UpdateExpenseCategoryInput) (*store.ExpenseCategory, error)
+	DeleteExpenseCategory(ctx context.Context, userID, workspaceID, categoryID uuid.UUID) error
+
+	CreateExpense(ctx context.Context, userID, workspaceID uuid.UUID, input CreateExpenseInput) (*store.Expense, error)
+	GetExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*store.Expense, error)
+	ListExpenses(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Expense], error)
+	UpdateExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, input UpdateExpenseInput) (*store.Expense, error)
+	DeleteExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) error
+
+	ApproveExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*store.Expense, error)
+	RejectExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, input RejectExpenseInput) (*store.Expense, error)
+	ReimburseExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, input ReimburseExpenseInput) (*store.Expense, error)
+
+	UploadExpenseReceipt(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, fileName string, reader io.Reader, size int64, contentType string) (*store.Expense, error)
+	GetExpenseReceipt(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*ExpenseReceipt, error)
+	DeleteExpenseReceipt(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*store.Expense, error)
+	ConfirmReceiptSuggestions(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, input ConfirmReceiptSuggestionsInput) (*store.Expense, error)
+	DismissReceiptSuggestions(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*store.Expense, error)
+}
+
+type ExpenseService struct {
+	store    *store.Store
+	storage  pkgstorage.Provider
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewExpenseService(store *store.Store, storage pkgstorage.Provider, eventBus EventPublisher, logger zerolog.Logger) *ExpenseService {
+	return &ExpenseService{
+		store:    store,
+		storage:  storage,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "expense_service").Logger(),
+	}
+}
+
+var _ Expense = (*ExpenseService)(nil)
+
+func (s *ExpenseService) CreateExpenseCategory(ctx context.Context, userID, workspaceID uuid.UUID, input ExpenseCategoryInput) (*store.ExpenseCategory, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	category, err := s.store.Expenses.CreateExpenseCategory(ctx, store.ExpenseCategoryParams{
+		WorkspaceID: workspaceID,
+		Name:        strings.TrimSpace(input.Name),
+		Description: trimDescription(input.Description),
+		Color:       input.Color,
+	})
+	if err != nil {
+		return nil, mapExpenseError(err)
+	}
+	return category, nil
+}
+
+// ListExpenseCategories returns the categories of the workspace by name.
+func (s *ExpenseService) ListExpenseCategories(ctx context.Context, userID, workspaceID uuid.UUID) ([]store.ExpenseCategory, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	return s.store.Expenses.ListExpenseCategories(ctx, workspaceID)
+}
+
+func (s *ExpenseService) UpdateExpenseCategory(ctx context.Context, userID, workspaceID, categoryID uuid.UUID, input UpdateExpenseCategoryInput) (*store.ExpenseCategory, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	category, err := s.store.Expenses.GetExpenseCategory(ctx, workspaceID, categoryID)
+	if err != nil {
+		return nil, mapExpenseError(err)
+	}
+
+	params := store.ExpenseCategoryParams{
+		ID:          category.ID,
+		WorkspaceID: workspaceID,
+		Name:        category.Name,
+		Description: category.Description,
+		Color:       category.Color,
+	}
+	if input.Name != nil {
+		params.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Description != nil {
+		params.Description = trimDescription(input.Description)
+	}
+	if input.Color != nil {
+		params.Color = input.Color
+	}
+
+	updated, err := s.store.Expenses.UpdateExpenseCategory(ctx, params)
+	if err != nil {
+		return nil, mapExpenseError(err)
+	}
+	return updated, nil
+}
+
+// DeleteExpenseCategory removes a category. Its expenses are kept without a
+// category.
+func (s *ExpenseService) DeleteExpenseCategory(ctx context.Context, userID, workspaceID, categoryID uuid.UUID) error {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+	return mapExpenseError(s.store.Expenses.DeleteExpenseCategory(ctx, workspaceID, categoryID))
+}
+
+// CreateExpense submits an expense of the caller for review.
+func (s *ExpenseService) CreateExpense(ctx context.Context, userID, workspaceID uuid.UUID, input CreateExpenseInput) (*store.Expense, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	spentOn, err := time.Parse(dateLayout, input.SpentOn)
+	if err != nil {
+		return nil, err
+	}
+
+	params := store.CreateExpenseParams{
+		WorkspaceID:   workspaceID,
+		UserID:        userID,
+		CategoryID:    input.CategoryID,
+		ProjectID:     input.ProjectID,
+		ClientID:      input.ClientID,
+		Merchant:      strings.TrimSpace(input.Merchant),
+		Description:   trimDescription(input.Description),
+		Amount:        input.Amount,
+		SpentOn:       spentOn,
+		PaymentMethod: input.PaymentMethod,
+		Reimbursable:  input.PaymentMethod != "company_card",
+		Notes:         trimDescription(input.Notes),
+	}
+	if input.Reimbursable != nil {
+		params.Reimbursable = *input.Reimbursable
+	}
+	if input.Billable != nil {
+		params.Billable = *input.Billable
+	}
+
+	if input.Currency != nil {
+		params.Currency = strings.ToUpper(*input.Currency)
+	} else {
+		settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		params.Currency = strings.ToUpper(settings.Settings.DefaultCurrency)
+	}
+
+	if params.ClientID, err = s.checkExpenseReferences(ctx, workspaceID, params.CategoryID, params.ProjectID, params.ClientID, params.Billable); err != nil {
+		return nil, err
+	}
+
+	expense, err := s.store.Expenses.CreateExpense(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, events.EventExpenseSubmitted, userID, expense)
+
+	return expense, nil
+}
+
+// GetExpense returns an expense of the caller. Workspace admins can read
+// every member's expenses.
+func (s *ExpenseService) GetExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*store.Expense, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.getExpense(ctx, userID, workspaceID, expenseID, role)
+}
+
+// ListExpenses lists the caller's expenses. Workspace admins see every
+// member's expenses and can narrow them with the user_id filter.
+func (s *ExpenseService) ListExpenses(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Expense], error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter store.ExpenseFilter
+	switch value := filters.Filters["user_id"]; value {
+	case "me":
+		filter.UserID = &userID
+	default:
+		if filter.UserID, err = parseUUIDFilter(value); err != nil {
+			return nil, err
+		}
+	}
+	if role != "owner" && role != "admin" {
+		if filter.UserID != nil && *filter.UserID != userID {
+			return nil, ErrForbidden
+		}
+		filter.UserID = &userID
+	}
+
+	if value := filters.Filters["status"]; value != "" {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.TrimSpace(status)
+			switch status {
+			case "pending", "approved", "rejected", "reimbursed":
+				filter.Statuses = append(filter.Statuses, status)
+			default:
+				return nil, ErrInvalidFilter
+			}
+		}
+	}
+	if value := filters.Filters["currency"]; value != "" {
+		if len(value) != 3 {
+			return nil, ErrInvalidFilter
+		}
+		filter.Currency = strings.ToUpper(value)
+	}
+
+	if filter.CategoryID, err = parseUUIDFilter(filters.Filters["category_id"]); err != nil {
+		return nil, err
+	}
+	if filter.ProjectID, err = parseUUIDFilter(filters.Filters["project_id"]); err != nil {
+		return nil, err
+	}
+	if filter.ClientID, err = parseUUIDFilter(filters.Filters["client_id"]); err != nil {
+		return nil, err
+	}
+	if filter.Billable, err = parseBoolFilter(filters.Filters["billable"]); err != nil {
+		return nil, err
+	}
+	if filter.Billed, err = parseBoolFilter(filters.Filters["billed"]); err != nil {
+		return nil, err
+	}
+	if filter.Reimbursable, err = parseBoolFilter(filters.Filters["reimbursable"]); err != nil {
+		return nil, err
+	}
+	if filter.From, err = parseDateFilter(filters.Filters["from"], time.UTC); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseDateFilter(filters.Filters["to"], time.UTC); err != nil {
+		return nil, err
+	}
+
+	expenses, total, err := s.store.Expenses.ListExpenses(ctx, workspaceID, filter, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.BuildFilterResponse(expenses, total, filters), nil
+}
+
+// UpdateExpense lets the submitter or a workspace admin change an expense
+// until it is approved. Changing a rejected expense submits it again.
+func (s *ExpenseService) UpdateExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, input UpdateExpenseInput) (*store.Expense, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	expense, err := s.getExpense(ctx, userID, workspaceID, expenseID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !expenseEditable(expense) {
+		return nil, ErrExpenseLocked
+	}
+
+	params := store.UpdateExpenseParams{
+		ID:            expense.ID,
+		WorkspaceID:   workspaceID,
+		CategoryID:    expense.CategoryID,
+		ProjectID:     expense.ProjectID,
+		ClientID:      expense.ClientID,
+		Merchant:      expense.Merchant,
+		Description:   expense.Description,
+		Amount:        expense.Amount,
+		Currency:      expense.Currency,
+		SpentOn:       expense.SpentOn,
+		PaymentMethod: expense.PaymentMethod,
+		Reimbursable:  expense.Reimbursable,
+		Billable:      expense.Billable,
+		Notes:         expense.Notes,
+	}
+	if input.CategoryID != nil {
+		if params.CategoryID, err = parseUUIDFilter(*input.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+	if input.ProjectID != nil {
+		if params.ProjectID, err = parseUUIDFilter(*input.ProjectID); err != nil {
+			return nil, err
+		}
+		// The client followed the old project unless it is set as well.
+		if !sameUUID(params.ProjectID, expense.ProjectID) && input.ClientID == nil {
+			params.ClientID = nil
+		}
+	}
+	if input.ClientID != nil {
+		if params.ClientID, err = parseUUIDFilter(*input.ClientID); err != nil {
+			return nil, err
+		}
+	}
+	if input.Merchant != nil {
+		params.Merchant = strings.TrimSpace(*input.Merchant)
+	}
+	if input.Description != nil {
+		params.Description = trimDescription(input.Description)
+	}
+	if input.Amount != nil {
+		params.Amount = *input.Amount
+	}
+	if input.Currency != nil {
+		params.Currency = strings.ToUpper(*input.Currency)
+	}
+	if input.SpentOn != nil {
+		if params.SpentOn, err = time.Parse(dateLayout, *input.SpentOn); err != nil {
+			return nil, err
+		}
+	}
+	if input.PaymentMethod != nil {
+		params.PaymentMethod = *input.PaymentMethod
+		if input.Reimbursable == nil {
+			params.Reimbursable = *input.PaymentMethod != "company_card"
+		}
+	}
+	if input.Reimbursable != nil {
+		params.Reimbursable = *input.Reimbursable
+	}
+	if input.Billable != nil {
+		params.Billable = *input.Billable
+	}
+	if input.Notes != nil {
+		params.Notes = trimDescription(input.Notes)
+	}
+
+	if params.ClientID, err = s.checkExpenseReferences(ctx, workspaceID, params.CategoryID, params.ProjectID, params.ClientID, params.Billable); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.store.Expenses.UpdateExpense(ctx, params)
+	if err != nil {
+		if errors.Is(err, store.ErrExpenseNotPending) {
+			return nil, ErrExpenseLocked
+		}
+		return nil, err
+	}
+
+	if expense.Status == "rejected" {
+		s.publish(ctx, events.EventExpenseSubmitted, userID, updated)
+	}
+
+	return updated, nil
+}
+
+// DeleteExpense lets the submitter or a workspace admin remove an expense
+// and its receipt. Billed and reimbursed expenses are kept.
+func (s *ExpenseService) DeleteExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) error {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	expense, err := s.getExpense(ctx, userID, workspaceID, expenseID, role)
+	if err != nil {
+		return err
+	}
+	if expense.Billed || expense.Status == "reimbursed" {
+		return ErrExpenseLocked
+	}
+	if expense.Status == "approved" && role != "owner" && role != "admin" {
+		return ErrExpenseLocked
+	}
+
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		current, err := tx.Expenses.LockExpense(ctx, workspaceID, expense.ID)
+		if err != nil {
+			return err
+		}
+		if current.Billed || current.Status == "reimbursed" {
+			return ErrExpenseLocked
+		}
+		if err := tx.Expenses.DeleteExpense(ctx, workspaceID, expense.ID); err != nil {
+			return err
+		}
+		if current.ReceiptObject != nil {
+			return untrackStoredObject(ctx, tx, workspaceID, *current.ReceiptObject)
+		}
+		return nil
+	})
+	if err != nil {
+		return mapExpenseError(err)
+	}
+
+	if expense.ReceiptObject != nil {
+		if err := s.storage.Delete(ctx, pkgstorage.BucketPrivate, *expense.ReceiptObject); err != nil {
+			s.logger.Warn().Err(err).Str("expense_id", expense.ID.String()).Msg("failed to delete expense receipt")
+		}
+	}
+	return nil
+}
+
+// ApproveExpense approves a pending expense. Approved expenses can be billed
+// and, when the submitter paid, reimbursed.
+func (s *ExpenseService) ApproveExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*store.Expense, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	expense, err := s.store.Expenses.ReviewExpense(ctx, store.ReviewExpenseParams{
+		ID:          expenseID,
+		WorkspaceID: workspaceID,
+		Status:      "approved",
+		ReviewedBy:  userID,
+	})
+	if err != nil {
+		return nil, mapExpenseError(err)
+	}
+
+	s.publish(ctx, events.EventExpenseApproved, userID, expense)
+
+	return expense, nil
+}
+
+// RejectExpense sends a pending expense back to its submitter, who can change
+// it to submit it again.
+func (s *ExpenseService) RejectExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, input RejectExpenseInput) (*store.Expense, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(input.Reason)
+	expense, err := s.store.Expenses.ReviewExpense(ctx, store.ReviewExpenseParams{
+		ID:              expenseID,
+		WorkspaceID:     workspaceID,
+		Status:          "rejected",
+		ReviewedBy:      userID,
+		RejectionReason: &reason,
+	})
+	if err != nil {
+		return nil, mapExpenseError(err)
+	}
+
+	s.publish(ctx, events.EventExpenseRejected, userID, expense)
+
+	return expense, nil
+}
+
+// ReimburseExpense records that an approved expense paid by its submitter
+// was paid back.
+func (s *ExpenseService) ReimburseExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, input ReimburseExpenseInput) (*store.Expense, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	params := store.ReimburseExpenseParams{
+		ID:           expenseID,
+		WorkspaceID:  workspaceID,
+		ReimbursedBy: userID,
+		ReimbursedAt: time.Now(),
+		Reference:    trimDescription(input.Reference),
+	}
+	if input.ReimbursedAt != nil {
+		params.ReimbursedAt = *input.ReimbursedAt
+	}
+
+	expense, err := s.store.Expenses.ReimburseExpense(ctx, params)
+	if err != nil {
+		return nil, mapExpenseError(err)
+	}
+
+	s.publish(ctx, events.EventExpenseReimbursed, userID, expense)
+
+	return expense, nil
+}
+
+// UploadExpenseReceipt stores the receipt of an expense in the private
+// bucket, replacing the previous one. Receipts count towards the workspace
+// storage quota and can be changed until the expense is approved. The new
+// receipt is queued to be read for suggestions.
+func (s *ExpenseService) UploadExpenseReceipt(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, fileName string, reader io.Reader, size int64, contentType string) (*store.Expense, error) {
+	if err := store.CheckContext(ctx); err != nil {
+		return nil, err
+	}
+
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.getExpense(ctx, userID, workspaceID, expenseID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if !expenseEditable(expense) {
+		return nil, ErrExpenseLocked
+	}
+
+	// Every upload gets an object of its own, so the receipt on the expense
+	// is untouched until the new one is recorded. The upload runs between
+	// reserving its storage and recording it, outside any transaction.
+	objectName := expenseReceiptObject(workspaceID, expense.ID, uuid.New())
+	reserved, err := reserveStoredObject(ctx, s.store, workspaceID, objectName, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var replaced *string
+	uploaded := false
+	_, err = s.storage.Upload(ctx, pkgstorage.BucketPrivate, objectName, reader, size, contentType)
+	if err == nil {
+		uploaded = true
+		err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+			current, err := tx.Expenses.LockExpense(ctx, workspaceID, expense.ID)
+			if err != nil {
+				return err
+			}
+			if !expenseEditable(current) {
+				return ErrExpenseLocked
+			}
+
+			if err := recordStoredObject(ctx, tx, workspaceID, objectName, size, reserved); err != nil {
+				return err
+			}
+			if replaced = current.ReceiptObject; replaced != nil {
+				if err := untrackStoredObject(ctx, tx, workspaceID, *replaced); err != nil {
+					return err
+				}
+			}
+			return tx.Expenses.SetExpenseReceipt(ctx, workspaceID, expense.ID, &store.ExpenseReceipt{
+				Object:      objectName,
+				Name:        fileName,
+				ContentType: contentType,
+				Size:        size,
+			})
+		})
+	}
+	if err != nil {
+		if releaseErr := releaseStoredObject(ctx, s.store, workspaceID, reserved); releaseErr != nil {
+			s.logger.Warn().Err(releaseErr).Str("expense_id", expense.ID.String()).Msg("failed to release receipt storage reservation")
+		}
+		if uploaded {
+			if deleteErr := s.storage.Delete(context.WithoutCancel(ctx), pkgstorage.BucketPrivate, objectName); deleteErr != nil {
+				s.logger.Warn().Err(deleteErr).Str("expense_id", expense.ID.String()).Msg("failed to delete unrecorded expense receipt")
+			}
+		}
+		return nil, mapExpenseError(err)
+	}
+
+	if replaced != nil {
+		if err := s.storage.Delete(ctx, pkgstorage.BucketPrivate, *replaced); err != nil {
+			s.logger.Warn().Err(err).Str("expense_id", expense.ID.String()).Msg("failed to delete replaced expense receipt")
+		}
+	}
+
+	updated, err := s.store.Expenses.GetExpense(ctx, workspaceID, expense.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extraction picks the receipt up from here; see ReceiptExtractionService.
+	s.publish(ctx, events.EventExpenseReceiptUploaded, userID, updated)
+
+	return updated, nil
+}
+
+// GetExpenseReceipt returns a presigned link to the receipt of an expense.
+func (s *ExpenseService) GetExpenseReceipt(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*ExpenseReceipt, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.getExpense(ctx, userID, workspaceID, expenseID, role)
+	if err != nil {
+		return nil, err
+	}
+	if expense.ReceiptObject == nil {
+		return nil, ErrExpenseReceiptNotFound
+	}
+
+	url, err := s.storage.GetPresignedURL(ctx, *expense.ReceiptObject, expenseReceiptURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExpenseReceipt{
+		URL:         url,
+		ExpiresAt:   time.Now().Add(expenseReceiptURLExpiry),
+		Name:        *expense.ReceiptName,
+		ContentType: *expense.ReceiptContentType,
+		Size:        *expense.ReceiptSize,
+	}, nil
+}
+
+// DeleteExpenseReceipt removes the receipt of an expense that is not approved
+// yet.
+func (s *ExpenseService) DeleteExpenseReceipt(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*store.Expense, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.getExpense(ctx, userID, workspaceID, expenseID, role)
+	if err != nil {
+		return nil, err
+	}
+	if expense.ReceiptObject == nil {
+		return nil, ErrExpenseReceiptNotFound
+	}
+
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		current, err := tx.Expenses.LockExpense(ctx, workspaceID, expense.ID)
+		if err != nil {
+			return err
+		}
+		if !expenseEditable(current) {
+			return ErrExpenseLocked
+		}
+		if current.ReceiptObject == nil {
+			return ErrExpenseReceiptNotFound
+		}
+		if err := untrackStoredObject(ctx, tx, workspaceID, *current.ReceiptObject); err != nil {
+			return err
+		}
+		return tx.Expenses.SetExpenseReceipt(ctx, workspaceID, expense.ID, nil)
+	})
+	if err != nil {
+		return nil, mapExpenseError(err)
+	}
+
+	if err := s.storage.Delete(ctx, pkgstorage.BucketPrivate, *expense.ReceiptObject); err != nil {
+		s.logger.Warn().Err(err).Str("expense_id", expense.ID.String()).Msg("failed to delete expense receipt")
+	}
+
+	return s.store.Expenses.GetExpense(ctx, workspaceID, expense.ID)
+}
+
+// ConfirmReceiptSuggestions applies what was read off the receipt to the
+// expense, as an update by its submitter. Only the submitter can confirm.
+func (s *ExpenseService) ConfirmReceiptSuggestions(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, input ConfirmReceiptSuggestionsInput) (*store.Expense, error) {
+	expense, err := s.getSubmittedExpense(ctx, userID, workspaceID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if expense.ExtractionStatus == nil || *expense.ExtractionStatus != "completed" {
+		return nil, ErrNoReceiptSuggestions
+	}
+
+	fields := input.Fields
+	if len(fields) == 0 {
+		fields = []string{"merchant", "amount", "spent_on"}
+	}
+
+	var update UpdateExpenseInput
+	applied := false
+	for _, field := range fields {
+		switch field {
+		case "merchant":
+			if expense.SuggestedMerchant != nil {
+				update.Merchant, applied = expense.SuggestedMerchant, true
+			}
+		case "amount":
+			if expense.SuggestedAmount != nil && expense.SuggestedCurrency != nil {
+				update.Amount, update.Currency, applied = expense.SuggestedAmount, expense.SuggestedCurrency, true
+			}
+		case "spent_on":
+			if expense.SuggestedSpentOn != nil {
+				spentOn := expense.SuggestedSpentOn.Format(dateLayout)
+				update.SpentOn, applied = &spentOn, true
+			}
+		}
+	}
+	if !applied {
+		return nil, ErrNoReceiptSuggestions
+	}
+
+	if _, err := s.UpdateExpense(ctx, userID, workspaceID, expense.ID, update); err != nil {
+		return nil, err
+	}
+	// A receipt uploaded in the meantime replaced the suggestions; the
+	// update the submitter asked for still stands.
+	if err := s.store.Expenses.ResolveReceiptSuggestions(ctx, workspaceID, expense.ID, "confirmed"); err != nil && !errors.Is(err, store.ErrNoReceiptSuggestions) {
+		return nil, err
+	}
+
+	return s.store.Expenses.GetExpense(ctx, workspaceID, expense.ID)
+}
+
+// DismissReceiptSuggestions discards what was read off the receipt without
+// changing the expense. Only the submitter can dismiss.
+func (s *ExpenseService) DismissReceiptSuggestions(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*store.Expense, error) {
+	expense, err := s.getSubmittedExpense(ctx, userID, workspaceID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Expenses.ResolveReceiptSuggestions(ctx, workspaceID, expense.ID, "dismissed"); err != nil {
+		return nil, mapExpenseError(err)
+	}
+	return s.store.Expenses.GetExpense(ctx, workspaceID, expense.ID)
+}
+
+// getSubmittedExpense loads an expense the caller submitted.
+func (s *ExpenseService) getSubmittedExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID) (*store.Expense, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	expense, err := s.getExpense(ctx, userID, workspaceID, expenseID, role)
+	if err != nil {
+		return nil, err
+	}
+	if expense.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return expense, nil
+}
+
+// getExpense loads an expense the caller may see. Other members' expenses
+// are reported as not found to non-admins.
+func (s *ExpenseService) getExpense(ctx context.Context, userID, workspaceID, expenseID uuid.UUID, role string) (*store.Expense, error) {
+	expense, err := s.store.Expenses.GetExpense(ctx, workspaceID, expenseID)
+	if err != nil {
+		return nil, mapExpenseError(err)
+	}
+	if expense.UserID != userID && role != "owner" && role != "admin" {
+		return nil, ErrExpenseNotFound
+	}
+	return expense, nil
+}
+
+// checkExpenseReferences checks that the category, project and client belong
+// to the workspace and returns the client the expense is charged to, the
+// project's client when none is given. Billable expenses need a client.
+func (s *ExpenseService) checkExpenseReferences(ctx context.Context, workspaceID uuid.UUID, categoryID, projectID, clientID *uuid.UUID, billable bool) (*uuid.UUID, error) {
+	if categoryID != nil {
+		if _, err := s.store.Expenses.GetExpenseCategory(ctx, workspaceID, *categoryID); err != nil {
+			return nil, mapExpenseError(err)
+		}
+	}
+
+	if projectID != nil {
+		project, err := s.store.Projects.GetProject(ctx, workspaceID, *projectID)
+		if err != nil {
+			if errors.Is(err, store.ErrProjectNotFound) {
+				return nil, ErrProjectNotFound
+			}
+			return nil, err
+		}
+		if project.ClientID != nil {
+			if clientID != nil && *clientID != *project.ClientID {
+				return nil, ErrInvoiceProjectClient
+			}
+			clientID = project.ClientID
+		}
+	}
+
+	if clientID != nil {
+		if _, err := s.store.Clients.GetClient(ctx, workspaceID, *clientID); err != nil {
+			if errors.Is(err, store.ErrClientNotFound) {
+				return nil, ErrClientNotFound
+			}
+			return nil, err
+		}
+	} else if billable {
+		return nil, ErrExpenseClientRequired
+	}
+
+	return clientID, nil
+}
+
+func (s *ExpenseService) publish(ctx context.Context, eventType events.EventType, userID uuid.UUID, expense *store.Expense) {
+	if s.eventBus == nil {
+		return
+	}
+	payload := map[string]any{
+		"workspace_id": expense.WorkspaceID,
+		"expense_id":   expense.ID,
+		"user_id":      expense.UserID,
+		"merchant":     expense.Merchant,
+		"amount":       expense.Amount,
+		"currency":     expense.Currency,
+		"spent_on":     expense.SpentOn.Format(dateLayout),
+		"status":       expense.Status,
+	}
+	if expense.RejectionReason != nil {
+		payload["reason"] = *expense.RejectionReason
+	}
+	if expense.ReimbursementReference != nil {
+		payload["reference"] = *expense.ReimbursementReference
+	}
+	s.eventBus.Publish(ctx, eventType, userID, payload)
+}
+
+// expenseEditable reports whether an expense can still be changed by its
+// submitter.
+func expenseEditable(expense *store.Expense) bool {
+	return !expense.Billed && (expense.Status == "pending" || expense.Status == "rejected")
+}
+
+// expenseReceiptObject names one upload of the receipt of an expense.
+func expenseReceiptObject(workspaceID, expenseID, uploadID uuid.UUID) string {
+	return fmt.Sprintf("receipts/%s/%s/%s", workspaceID, expenseID, uploadID)
+}
+
+func mapExpenseError(err error) error {
+	switch {
+	case errors.Is(err, store.ErrExpenseNotFound):
+		return ErrExpenseNotFound
+	case errors.Is(err, store.ErrExpenseCategoryNotFound):
+		return ErrExpenseCategoryNotFound
+	case errors.Is(err, store.ErrExpenseCategoryNameTaken):
+		return ErrExpenseCategoryNameTaken
+	case errors.Is(err, store.ErrExpenseNotPending):
+		return ErrExpenseNotPending
+	case errors.Is(err, store.ErrExpenseNotReimbursable):
+		return ErrExpenseNotReimbursable
+	case errors.Is(err, store.ErrNoReceiptSuggestions):
+		return ErrNoReceiptSuggestions
+	default:
+		return err
+	}
+}
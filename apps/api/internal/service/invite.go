@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInviteLinkInvalid  = errors.New("invite link is invalid, expired or used up")
+	ErrInviteExpiryInPast = errors.New("expires_at must be in the future")
+	ErrEmailNotVerified   = errors.New("email address is not verified")
+	ErrDomainNotAllowed   = errors.New("email domain is not allowed to join this workspace")
+	ErrPublicEmailDomain  = errors.New("public email domains cannot be used for auto-join")
+	ErrAlreadyMember      = errors.New("user is already a member")
+	ErrJoinMethodRequired = errors.New("either token or workspace_id is required")
+)
+
+// publicEmailDomains cannot be used as auto-join rules, otherwise anyone with a
+// free mailbox could join the workspace.
+var publicEmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"yahoo.com":      true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"aol.com":        true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"gmx.com":        true,
+}
+
+type CreateInviteLinkInput struct {
+	Role      *string    `json:"role,omitempty" validate:"omitempty,oneof=admin member"`
+	MaxUses   *int32     `json:"max_uses,omitempty" validate:"omitempty,min=1,max=10000"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type AddJoinDomainInput struct {
+	Domain string  `json:"domain" validate:"required,fqdn,max=255"`
+	Role   *string `json:"role,omitempty" validate:"omitempty,oneof=admin member"`
+}
+
+type JoinWorkspaceInput struct {
+	Token       *string    `json:"token,omitempty" validate:"omitempty,min=16,max=128"`
+	WorkspaceID *uuid.UUID `json:"workspace_id,omitempty"`
+}
+
+// CreatedInviteLink carries the plaintext token, which is only available at creation time.
+type CreatedInviteLink struct {
+	store.InviteLink
+	Token string `json:"token"`
+}
+
+type Invite interface {
+	CreateInviteLink(ctx context.Context, userID, workspaceID uuid.UUID, input CreateInviteLinkInput) (*CreatedInviteLink, error)
+	ListInviteLinks(ctx context.Context, userID, workspaceID uuid.UUID) ([]store.InviteLink, error)
+	RevokeInviteLink(ctx context.Context, userID, workspaceID, linkID uuid.UUID) error
+	AddJoinDomain(ctx context.Context, userID, workspaceID uuid.UUID, input AddJoinDomainInput) (*store.JoinDomain, error)
+	ListJoinDomains(ctx context.Context, userID, workspaceID uuid.UUID) ([]store.JoinDomain, error)
+	RemoveJoinDomain(ctx context.Context, userID, workspaceID uuid.UUID, domain string) error
+	Join(ctx context.Context, userID uuid.UUID, input JoinWorkspaceInput) (*store.WorkspaceMember, error)
+}
+
+type InviteService struct {
+	store    *store.Store
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewInviteService(store *store.Store, eventBus EventPublisher, logger zerolog.Logger) *InviteService {
+	return &InviteService{
+		store:    store,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "invite_service").Logger(),
+	}
+}
+
+var _ Invite = (*InviteService)(nil)
+
+func (s *InviteService) CreateInviteLink(ctx context.Context, userID, workspaceID uuid.UUID, input CreateInviteLinkInput) (*CreatedInviteLink, error) {
+	if err := s.requireAdmin(ctx, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, ErrInviteExpiryInPast
+	}
+
+	role, err := s.resolveRole(ctx, workspaceID, input.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateLinkToken()
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.store.Invites.CreateInviteLink(ctx, store.CreateInviteLinkParams{
+		WorkspaceID: workspaceID,
+		TokenHash:   hashLinkToken(token),
+		Role:        role,
+		MaxUses:     input.MaxUses,
+		ExpiresAt:   input.ExpiresAt,
+		CreatedBy:   userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreatedInviteLink{InviteLink: *link, Token: token}, nil
+}
+
+func (s *InviteService) ListInviteLinks(ctx context.Context, userID, workspaceID uuid.UUID) ([]store.InviteLink, error) {
+	if err := s.requireAdmin(ctx, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.store.Invites.ListInviteLinks(ctx, workspaceID)
+}
+
+func (s *InviteService) RevokeInviteLink(ctx context.Context, userID, workspaceID, linkID uuid.UUID) error {
+	if err := s.requireAdmin(ctx, workspaceID, userID); err != nil {
+		return err
+	}
+
+	return s.store.Invites.RevokeInviteLink(ctx, workspaceID, linkID)
+}
+
+func (s *InviteService) AddJoinDomain(ctx context.Context, userID, workspaceID uuid.UUID, input AddJoinDomainInput) (*store.JoinDomain, error) {
+	if err := s.requireAdmin(ctx, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	domain := normalizeDomain(input.Domain)
+	if publicEmailDomains[domain] {
+		return nil, ErrPublicEmailDomain
+	}
+
+	role, err := s.resolveRole(ctx, workspaceID, input.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.Invites.AddJoinDomain(ctx, workspaceID, domain, role, userID)
+}
+
+func (s *InviteService) ListJoinDomains(ctx context.Context, userID, workspaceID uuid.UUID) ([]store.JoinDomain, error) {
+	if err := s.requireAdmin(ctx, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.store.Invites.ListJoinDomains(ctx, workspaceID)
+}
+
+func (s *InviteService) RemoveJoinDomain(ctx context.Context, userID, workspaceID uuid.UUID, domain string) error {
+	if err := s.requireAdmin(ctx, workspaceID, userID); err != nil {
+		return err
+	}
+
+	return s.store.Invites.DeleteJoinDomain(ctx, workspaceID, normalizeDomain(domain))
+}
+
+// Join adds the caller to a workspace either through an invite link token or,
+// when only a workspace ID is given, through a matching verified email domain.
+func (s *InviteService) Join(ctx context.Context, userID uuid.UUID, input JoinWorkspaceInput) (*store.WorkspaceMember, error) {
+	if input.Token == nil && input.WorkspaceID == nil {
+		return nil, ErrJoinMethodRequired
+	}
+
+	user, err := s.store.Users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		workspaceID uuid.UUID
+		role        string
+		via         string
+	)
+
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if input.Token != nil {
+			link, err := tx.Invites.ConsumeInviteLink(ctx, hashLinkToken(*input.Token))
+			if err != nil {
+				if errors.Is(err, store.ErrInviteLinkInvalid) {
+					return ErrInviteLinkInvalid
+				}
+				return err
+			}
+			workspaceID, role, via = link.WorkspaceID, link.Role, "invite_link"
+		} else {
+			if user.Email == nil || user.EmailVerifiedAt == nil {
+				return ErrEmailNotVerified
+			}
+			rule, err := tx.Invites.GetJoinDomain(ctx, *input.WorkspaceID, emailDomain(*user.Email))
+			if err != nil {
+				if errors.Is(err, store.ErrJoinDomainNotFound) {
+					return ErrDomainNotAllowed
+				}
+				return err
+			}
+			workspaceID, role, via = rule.WorkspaceID, rule.Role, "email_domain"
+		}
+
+		// Joining through a link and a domain at once must not take two
+		// seats, so the membership check runs under the usage lock.
+		if err := tx.Usage.LockUsage(ctx, workspaceID); err != nil {
+			return err
+		}
+
+		existing, err := tx.Workspaces.GetWorkspaceMember(ctx, workspaceID, userID)
+		if err == nil {
+			// Only an admin can undo a deactivation, an invite link must not.
+			if existing.DeactivatedAt != nil {
+				return ErrMemberDeactivated
+			}
+			return ErrAlreadyMember
+		}
+		if !errors.Is(err, store.ErrNotMember) {
+			return err
+		}
+
+		if err := reserveQuota(ctx, tx, workspaceID, store.UsageMembers, 1); err != nil {
+			return err
+		}
+
+		return tx.Workspaces.AddWorkspaceMember(ctx, workspaceID, userID, role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	member := &store.WorkspaceMember{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Role:        role,
+		JoinedAt:    time.Now().UTC(),
+		Name:        user.Name,
+		AvatarURL:   user.AvatarURL,
+	}
+	if user.Email != nil {
+		member.Email = *user.Email
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventMemberAdded, userID, map[string]any{
+			"workspace_id": workspaceID,
+			"user_id":      userID,
+			"email":        user.Email,
+			"role":         role,
+			"via":          via,
+		})
+	}
+
+	return member, nil
+}
+
+func (s *InviteService) requireAdmin(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	return requireWorkspaceAdmin(ctx, s.store, workspaceID, userID)
+}
+
+// resolveRole falls back to the workspace's configured default join role.
+func (s *InviteService) resolveRole(ctx context.Context, workspaceID uuid.UUID, role *string) (string, error) {
+	if role != nil {
+		return *role, nil
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return "", err
+	}
+	return settings.Settings.DefaultJoinRole, nil
+}
+
+// generateLinkToken returns a random token for a link handed out by email or
+// by an admin, such as an invite link. Only its hash is stored.
+func generateLinkToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return normalizeDomain(email[at+1:])
+}
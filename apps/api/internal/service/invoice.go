@@ -0,0 +1,632 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+	"github.com/lukabrkovic/artemis/pkg/money"
+	pkgstorage "github.com/lukabrkovic/artemis/pkg/storage"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvoiceNotFound                = errors.New("invoice not found")
+	ErrInvoiceNotEditable             = errors.New("only draft invoices can be changed")
+	ErrInvalidInvoiceStatusTransition = errors.New("invalid invoice status transition")
+	ErrInvoiceEmpty                   = errors.New("an invoice needs at least one line item to be sent")
+	ErrInvalidInvoiceDates            = errors.New("due_date must not be before issue_date")
+	ErrInvoiceProjectClient           = errors.New("the project belongs to a different client")
+	ErrInvoiceHasPayments             = errors.New("refund the payments on this invoice before cancelling it")
+	ErrInvoiceLineTooLarge            = fmt.Errorf("an invoice line must come to at most %d", validator.MaxLineAmount)
+)
+
+// defaultInvoiceDueDays is the payment term applied when an invoice is sent
+// without a due date.
+const defaultInvoiceDueDays = 30
+
+// invoiceTransitions lists the statuses an invoice may move to from each
+// status. Paid and cancelled invoices are final; only a refund reopens a paid
+// invoice.
+var invoiceTransitions = map[string][]string{
+	"draft":     {"sent", "cancelled"},
+	"sent":      {"viewed", "paid", "overdue", "cancelled"},
+	"viewed":    {"paid", "overdue", "cancelled"},
+	"overdue":   {"paid", "cancelled"},
+	"paid":      {},
+	"cancelled": {},
+}
+
+// InvoiceItemInput is a line of an invoice. UnitPrice is in minor units; a
+// line without a tax rate uses the invoice rate.
+type InvoiceItemInput struct {
+	Description     string         `json:"description" validate:"required,max=1000"`
+	Quantity        money.Decimal  `json:"quantity" validate:"gt=0" swaggertype:"number"`
+	UnitPrice       int64          `json:"unit_price" validate:"min=0,line_amount"`
+	DiscountPercent money.Decimal  `json:"discount_percent" validate:"percent" swaggertype:"number"`
+	TaxRate         *money.Decimal `json:"tax_rate,omitempty" validate:"omitempty,percent" swaggertype:"number"`
+}
+
+type CreateInvoiceInput struct {
+	ClientID        uuid.UUID          `json:"client_id" validate:"required"`
+	ProjectID       *uuid.UUID         `json:"project_id,omitempty"`
+	Currency        *string            `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	IssueDate       *string            `json:"issue_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	DueDate         *string            `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	DiscountPercent *money.Decimal     `json:"discount_percent,omitempty" validate:"omitempty,percent" swaggertype:"number"`
+	TaxRate         *money.Decimal     `json:"tax_rate,omitempty" validate:"omitempty,percent" swaggertype:"number"`
+	Notes           *string            `json:"notes,omitempty" validate:"omitempty,max=5000"`
+	Terms           *string            `json:"terms,omitempty" validate:"omitempty,max=5000"`
+	Items           []InvoiceItemInput `json:"items,omitempty" validate:"omitempty,max=200,dive"`
+}
+
+// UpdateInvoiceInput only changes the fields that are set. An empty
+// project_id clears it, and items replaces every line when present.
+type UpdateInvoiceInput struct {
+	ClientID        *uuid.UUID         `json:"client_id,omitempty"`
+	ProjectID       *string            `json:"project_id,omitempty" validate:"omitempty,uuid"`
+	Currency        *string            `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	IssueDate       *string            `json:"issue_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	DueDate         *string            `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	DiscountPercent *money.Decimal     `json:"discount_percent,omitempty" validate:"omitempty,percent" swaggertype:"number"`
+	TaxRate         *money.Decimal     `json:"tax_rate,omitempty" validate:"omitempty,percent" swaggertype:"number"`
+	Notes           *string            `json:"notes,omitempty" validate:"omitempty,max=5000"`
+	Terms           *string            `json:"terms,omitempty" validate:"omitempty,max=5000"`
+	Items           []InvoiceItemInput `json:"items,omitempty" validate:"omitempty,max=200,dive"`
+}
+
+type UpdateInvoiceStatusInput struct {
+	Status string `json:"status" validate:"required,oneof=sent viewed overdue cancelled"`
+}
+
+type Invoice interface {
+	CreateInvoice(ctx context.Context, userID, workspaceID uuid.UUID, input CreateInvoiceInput) (*store.Invoice, error)
+	GetInvoice(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID) (*store.Invoice, error)
+	ListInvoices(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Invoice], error)
+	UpdateInvoice(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID, input UpdateInvoiceInput) (*store.Invoice, error)
+	UpdateInvoiceStatus(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID, input UpdateInvoiceStatusInput) (*store.Invoice, error)
+	DeleteInvoice(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID) error
+	CreateInvoiceFromUnbilled(ctx context.Context, userID, workspaceID uuid.UUID, input CreateInvoiceFromUnbilledInput) (*store.Invoice, error)
+	GetInvoicePDF(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID) (*InvoicePDF, error)
+}
+
+type InvoiceService struct {
+	store    *store.Store
+	storage  pkgstorage.Provider
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewInvoiceService(store *store.Store, storage pkgstorage.Provider, eventBus EventPublisher, logger zerolog.Logger) *InvoiceService {
+	return &InvoiceService{
+		store:    store,
+		storage:  storage,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "invoice_service").Logger(),
+	}
+}
+
+var _ Invoice = (*InvoiceService)(nil)
+
+// CreateInvoice starts a draft for a client, billed in the client's currency
+// unless another is given. Only workspace admins manage invoices.
+func (s *InvoiceService) CreateInvoice(ctx context.Context, userID, workspaceID uuid.UUID, input CreateInvoiceInput) (*store.Invoice, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	client, err := s.checkInvoiceReferences(ctx, workspaceID, input.ClientID, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := store.CreateInvoiceParams{
+		WorkspaceID: workspaceID,
+		ClientID:    client.ID,
+		ProjectID:   input.ProjectID,
+		Currency:    client.Currency,
+		Notes:       trimDescription(input.Notes),
+		Terms:       trimDescription(input.Terms),
+		CreatedBy:   &userID,
+	}
+	if input.Currency != nil {
+		params.Currency = strings.ToUpper(*input.Currency)
+	}
+	if input.DiscountPercent != nil {
+		params.DiscountPercent = *input.DiscountPercent
+	}
+	if input.TaxRate != nil {
+		params.TaxRate = *input.TaxRate
+	}
+	if params.IssueDate, err = parseDate(input.IssueDate); err != nil {
+		return nil, err
+	}
+	if params.DueDate, err = parseDate(input.DueDate); err != nil {
+		return nil, err
+	}
+	if params.DueDate != nil && params.IssueDate != nil && params.DueDate.Before(*params.IssueDate) {
+		return nil, ErrInvalidInvoiceDates
+	}
+
+	items, totals, err := priceInvoice(params.DiscountPercent, params.TaxRate, input.Items)
+	if err != nil {
+		return nil, err
+	}
+	params.Totals = totals
+
+	var invoice *store.Invoice
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		var err error
+		if invoice, err = tx.Invoices.CreateInvoice(ctx, params); err != nil {
+			return err
+		}
+		if err := tx.Invoices.ReplaceInvoiceItems(ctx, invoice.ID, items); err != nil {
+			return err
+		}
+		invoice.Items, err = tx.Invoices.ListInvoiceItems(ctx, invoice.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// GetInvoice returns the invoice with its line items.
+func (s *InvoiceService) GetInvoice(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID) (*store.Invoice, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.getInvoice(ctx, workspaceID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice.Items, err = s.store.Invoices.ListInvoiceItems(ctx, invoice.ID); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (s *InvoiceService) ListInvoices(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Invoice], error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	var filter store.InvoiceFilter
+	if value := filters.Filters["status"]; value != "" {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.TrimSpace(status)
+			if invoiceTransitions[status] == nil {
+				return nil, ErrInvalidFilter
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	var err error
+	if filter.ClientID, err = parseUUIDFilter(filters.Filters["client_id"]); err != nil {
+		return nil, err
+	}
+	if filter.ProjectID, err = parseUUIDFilter(filters.Filters["project_id"]); err != nil {
+		return nil, err
+	}
+	if filter.RecurrenceID, err = parseUUIDFilter(filters.Filters["recurrence_id"]); err != nil {
+		return nil, err
+	}
+	if filter.IssuedFrom, err = parseDateFilter(filters.Filters["issued_from"], time.UTC); err != nil {
+		return nil, err
+	}
+	if filter.IssuedTo, err = parseDateFilter(filters.Filters["issued_to"], time.UTC); err != nil {
+		return nil, err
+	}
+
+	invoices, total, err := s.store.Invoices.ListInvoices(ctx, workspaceID, filter, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.BuildFilterResponse(invoices, total, filters), nil
+}
+
+// UpdateInvoice changes a draft and reprices it. Sent invoices are fixed;
+// they can only move through their statuses.
+func (s *InvoiceService) UpdateInvoice(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID, input UpdateInvoiceInput) (*store.Invoice, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.getInvoice(ctx, workspaceID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status != "draft" {
+		return nil, ErrInvoiceNotEditable
+	}
+
+	params := store.UpdateInvoiceParams{
+		ID:              invoice.ID,
+		WorkspaceID:     workspaceID,
+		ClientID:        invoice.ClientID,
+		ProjectID:       invoice.ProjectID,
+		Currency:        invoice.Currency,
+		IssueDate:       invoice.IssueDate,
+		DueDate:         invoice.DueDate,
+		DiscountPercent: invoice.DiscountPercent,
+		TaxRate:         invoice.TaxRate,
+		Notes:           invoice.Notes,
+		Terms:           invoice.Terms,
+	}
+	if input.ClientID != nil {
+		params.ClientID = *input.ClientID
+	}
+	if input.ProjectID != nil {
+		if params.ProjectID, err = parseUUIDFilter(*input.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	if input.ClientID != nil || input.ProjectID != nil {
+		if _, err := s.checkInvoiceReferences(ctx, workspaceID, params.ClientID, params.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	if input.Currency != nil {
+		params.Currency = strings.ToUpper(*input.Currency)
+	}
+	if input.IssueDate != nil {
+		if params.IssueDate, err = parseDate(input.IssueDate); err != nil {
+			return nil, err
+		}
+	}
+	if input.DueDate != nil {
+		if params.DueDate, err = parseDate(input.DueDate); err != nil {
+			return nil, err
+		}
+	}
+	if params.DueDate != nil && params.IssueDate != nil && params.DueDate.Before(*params.IssueDate) {
+		return nil, ErrInvalidInvoiceDates
+	}
+	if input.DiscountPercent != nil {
+		params.DiscountPercent = *input.DiscountPercent
+	}
+	if input.TaxRate != nil {
+		params.TaxRate = *input.TaxRate
+	}
+	if input.Notes != nil {
+		params.Notes = trimDescription(input.Notes)
+	}
+	if input.Terms != nil {
+		params.Terms = trimDescription(input.Terms)
+	}
+
+	lines := input.Items
+	if lines == nil {
+		current, err := s.store.Invoices.ListInvoiceItems(ctx, invoice.ID)
+		if err != nil {
+			return nil, err
+		}
+		lines = invoiceItemInputs(current)
+	}
+
+	items, totals, err := priceInvoice(params.DiscountPercent, params.TaxRate, lines)
+	if err != nil {
+		return nil, err
+	}
+	params.Totals = totals
+
+	var updated *store.Invoice
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if _, err := tx.Invoices.LockInvoice(ctx, workspaceID, invoice.ID); err != nil {
+			return err
+		}
+
+		var err error
+		if updated, err = tx.Invoices.UpdateInvoice(ctx, params); err != nil {
+			// The invoice was sent while this change was being prepared.
+			if errors.Is(err, store.ErrInvoiceNotFound) {
+				return ErrInvoiceNotEditable
+			}
+			return err
+		}
+		if err := tx.Invoices.ReplaceInvoiceItems(ctx, invoice.ID, items); err != nil {
+			return err
+		}
+		updated.Items, err = tx.Invoices.ListInvoiceItems(ctx, invoice.ID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrInvoiceNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// UpdateInvoiceStatus moves an invoice through its lifecycle. Sending a draft
+// assigns the next invoice number and fills in missing dates. Invoices become
+// paid by recording payments, never by hand.
+func (s *InvoiceService) UpdateInvoiceStatus(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID, input UpdateInvoiceStatusInput) (*store.Invoice, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var invoice *store.Invoice
+	var changed bool
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		current, err := tx.Invoices.LockInvoice(ctx, workspaceID, invoiceID)
+		if err != nil {
+			return err
+		}
+
+		invoice, changed, err = transitionInvoice(ctx, tx, current, input.Status, settings.Settings)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrInvoiceNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+
+	if changed {
+		publishInvoiceStatus(ctx, s.eventBus, userID, invoice)
+	}
+	return invoice, nil
+}
+
+// DeleteInvoice removes a draft, its PDF and releases the time and expenses
+// billed on it.
+// Sent invoices keep their number and are cancelled instead.
+func (s *InvoiceService) DeleteInvoice(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID) error {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+
+	invoice, err := s.getInvoice(ctx, workspaceID, invoiceID)
+	if err != nil {
+		return err
+	}
+	if invoice.Status != "draft" {
+		return ErrInvoiceNotEditable
+	}
+
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := tx.Invoices.DeleteInvoice(ctx, workspaceID, invoiceID); err != nil {
+			return err
+		}
+		if invoice.PDFObject != nil {
+			if err := untrackStoredObject(ctx, tx, workspaceID, *invoice.PDFObject); err != nil {
+				return err
+			}
+		}
+		if err := tx.TimeEntries.ReleaseInvoiceTimeEntries(ctx, invoiceID); err != nil {
+			return err
+		}
+		return tx.Expenses.ReleaseInvoiceExpenses(ctx, invoiceID)
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrInvoiceNotFound) {
+			return ErrInvoiceNotEditable
+		}
+		return err
+	}
+
+	if invoice.PDFObject != nil {
+		if err := s.storage.Delete(ctx, pkgstorage.BucketPrivate, *invoice.PDFObject); err != nil {
+			s.logger.Warn().Err(err).Str("invoice_id", invoiceID.String()).Msg("failed to delete invoice pdf")
+		}
+	}
+	return nil
+}
+
+func (s *InvoiceService) getInvoice(ctx context.Context, workspaceID, invoiceID uuid.UUID) (*store.Invoice, error) {
+	invoice, err := s.store.Invoices.GetInvoice(ctx, workspaceID, invoiceID)
+	if err != nil {
+		if errors.Is(err, store.ErrInvoiceNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// checkInvoiceReferences returns the invoiced client after checking that it
+// and the project belong to the workspace, and that a project with a client
+// is billed to that client.
+func (s *InvoiceService) checkInvoiceReferences(ctx context.Context, workspaceID, clientID uuid.UUID, projectID *uuid.UUID) (*store.Client, error) {
+	client, err := s.store.Clients.GetClient(ctx, workspaceID, clientID)
+	if err != nil {
+		if errors.Is(err, store.ErrClientNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+
+	if projectID != nil {
+		project, err := s.store.Projects.GetProject(ctx, workspaceID, *projectID)
+		if err != nil {
+			if errors.Is(err, store.ErrProjectNotFound) {
+				return nil, ErrProjectNotFound
+			}
+			return nil, err
+		}
+		if project.ClientID != nil && *project.ClientID != client.ID {
+			return nil, ErrInvoiceProjectClient
+		}
+	}
+
+	return client, nil
+}
+
+// transitionInvoice moves a locked invoice to status. It reports whether
+// anything changed; asking for the current status is a no-op. Cancelling an
+// invoice releases the time and expenses billed on it.
+func transitionInvoice(ctx context.Context, tx *store.Store, invoice *store.Invoice, status string, settings store.SettingsDocument) (*store.Invoice, bool, error) {
+	if invoice.Status == status {
+		return invoice, false, nil
+	}
+	if !slices.Contains(invoiceTransitions[invoice.Status], status) {
+		return nil, false, ErrInvalidInvoiceStatusTransition
+	}
+
+	if status == "cancelled" {
+		if invoice.AmountPaid != 0 {
+			return nil, false, ErrInvoiceHasPayments
+		}
+		if err := tx.TimeEntries.ReleaseInvoiceTimeEntries(ctx, invoice.ID); err != nil {
+			return nil, false, err
+		}
+		if err := tx.Expenses.ReleaseInvoiceExpenses(ctx, invoice.ID); err != nil {
+			return nil, false, err
+		}
+	}
+	if status != "sent" {
+		updated, err := tx.Invoices.SetInvoiceStatus(ctx, invoice.WorkspaceID, invoice.ID, status, time.Now())
+		return updated, err == nil, err
+	}
+
+	items, err := tx.Invoices.ListInvoiceItems(ctx, invoice.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(items) == 0 {
+		return nil, false, ErrInvoiceEmpty
+	}
+
+	issueDate := workspaceToday(settings.Timezone)
+	if invoice.IssueDate != nil {
+		issueDate = *invoice.IssueDate
+	}
+	dueDate := issueDate.AddDate(0, 0, defaultInvoiceDueDays)
+	if invoice.DueDate != nil {
+		dueDate = *invoice.DueDate
+	}
+	if dueDate.Before(issueDate) {
+		return nil, false, ErrInvalidInvoiceDates
+	}
+
+	sequence, err := tx.Invoices.NextInvoiceNumber(ctx, invoice.WorkspaceID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	updated, err := tx.Invoices.IssueInvoice(ctx, store.IssueInvoiceParams{
+		ID:             invoice.ID,
+		WorkspaceID:    invoice.WorkspaceID,
+		SequenceNumber: sequence,
+		Number:         fmt.Sprintf("%s%04d", settings.InvoicePrefix, sequence),
+		IssueDate:      issueDate,
+		DueDate:        dueDate,
+	})
+	return updated, err == nil, err
+}
+
+// priceInvoice computes every line and the invoice totals. Each line's
+// discount comes off first, then the invoice discount, and tax is charged on
+// what remains. Every step is rounded per line, so the totals are exactly the
+// sums of the lines. Lines that come to more than validator.MaxLineAmount,
+// which only generated lines can, fail with ErrInvoiceLineTooLarge.
+func priceInvoice(discountPercent, taxRate money.Decimal, lines []InvoiceItemInput) ([]store.InvoiceItemParams, store.InvoiceTotals, error) {
+	items := make([]store.InvoiceItemParams, 0, len(lines))
+	var totals store.InvoiceTotals
+
+	for _, line := range lines {
+		amount, err := money.Mul(line.UnitPrice, line.Quantity)
+		if err != nil || amount > validator.MaxLineAmount || amount < -validator.MaxLineAmount {
+			return nil, store.InvoiceTotals{}, ErrInvoiceLineTooLarge
+		}
+		// With the amount bounded, percentages of it cannot overflow.
+		lineDiscount, _ := money.Percent(amount, line.DiscountPercent)
+		net := amount - lineDiscount
+		invoiceDiscount, _ := money.Percent(net, discountPercent)
+		taxable := net - invoiceDiscount
+
+		rate := taxRate
+		if line.TaxRate != nil {
+			rate = *line.TaxRate
+		}
+		tax, _ := money.Percent(taxable, rate)
+
+		item := store.InvoiceItemParams{
+			Description:     strings.TrimSpace(line.Description),
+			Quantity:        line.Quantity,
+			UnitPrice:       line.UnitPrice,
+			DiscountPercent: line.DiscountPercent,
+			TaxRate:         line.TaxRate,
+			Amount:          amount,
+			DiscountAmount:  amount - taxable,
+			TaxAmount:       tax,
+			Total:           taxable + tax,
+		}
+		items = append(items, item)
+
+		totals.Subtotal += item.Amount
+		totals.DiscountTotal += item.DiscountAmount
+		totals.TaxTotal += item.TaxAmount
+		totals.Total += item.Total
+	}
+
+	return items, totals, nil
+}
+
+func invoiceItemInputs(items []store.InvoiceItem) []InvoiceItemInput {
+	lines := make([]InvoiceItemInput, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, InvoiceItemInput{
+			Description:     item.Description,
+			Quantity:        item.Quantity,
+			UnitPrice:       item.UnitPrice,
+			DiscountPercent: item.DiscountPercent,
+			TaxRate:         item.TaxRate,
+		})
+	}
+	return lines
+}
+
+// publishInvoiceStatus announces the statuses the client or the sender of
+// the invoice hears about.
+func publishInvoiceStatus(ctx context.Context, eventBus EventPublisher, userID uuid.UUID, invoice *store.Invoice) {
+	if eventBus == nil {
+		return
+	}
+
+	var eventType events.EventType
+	switch invoice.Status {
+	case "sent":
+		eventType = events.EventInvoiceSent
+	case "viewed":
+		eventType = events.EventInvoiceViewed
+	case "paid":
+		eventType = events.EventInvoicePaid
+	case "cancelled":
+		eventType = events.EventInvoiceCancelled
+	default:
+		return
+	}
+
+	eventBus.Publish(ctx, eventType, userID, map[string]any{
+		"workspace_id": invoice.WorkspaceID,
+		"invoice_id":   invoice.ID,
+		"client_id":    invoice.ClientID,
+		"number":       invoice.Number,
+		"currency":     invoice.Currency,
+		"total":        invoice.Total,
+		"balance_due":  invoice.BalanceDue,
+	})
+}
@@ -0,0 +1,563 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/money"
+	"github.com/lukabrkovic/artemis/pkg/pdf"
+	pkgstorage "github.com/lukabrkovic/artemis/pkg/storage"
+)
+
+const (
+	// invoicePDFLayout is part of every PDF fingerprint. Bump it when the
+	// layout changes so stored PDFs are rendered again.
+	invoicePDFLayout = 1
+
+	invoicePDFURLExpiry = 15 * time.Minute
+	maxInvoiceLogoBytes = 2 << 20
+)
+
+var errLogoAddress = errors.New("logo host is not a public address")
+
+// logoClient fetches workspace logos. A logo that cannot be fetched in time
+// is left off the PDF rather than failing it. Logo URLs are set by workspace
+// admins, so the client only connects to public addresses, checked on the
+// resolved address of every connection including redirects, and ignores
+// proxy settings.
+var logoClient = &http.Client{
+	Timeout: 5 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: dialPublicOnly,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		ForceAttemptHTTP2:   true,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "https" {
+			return errors.New("logo redirected away from https")
+		}
+		if len(via) >= 3 {
+			return errors.New("logo redirected too many times")
+		}
+		return nil
+	},
+}
+
+// nonPublicPrefixes lists the address ranges a logo is never fetched from:
+// special-purpose IPv4 and IPv6 ranges, and the IPv6 forms that embed an
+// IPv4 address and could lead back into one of them. IPv4-mapped addresses
+// are unmapped before the check.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local, cloud metadata
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, broadcast
+	netip.MustParsePrefix("::/96"),           // unspecified, loopback, IPv4-compatible
+	netip.MustParsePrefix("::ffff:0:0:0/96"), // IPv4-translated
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/32"),       // Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4
+	netip.MustParsePrefix("fc00::/7"),        // unique local
+	netip.MustParsePrefix("fe80::/10"),       // link-local
+	netip.MustParsePrefix("fec0::/10"),       // site-local
+	netip.MustParsePrefix("ff00::/8"),        // multicast
+}
+
+// dialPublicOnly refuses connections to any address in nonPublicPrefixes.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(ip) {
+		return errLogoAddress
+	}
+	return nil
+}
+
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.WithZone("").Unmap()
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// InvoicePDF is a short-lived link to the rendered PDF of an invoice.
+type InvoicePDF struct {
+	URL        string    `json:"url"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	RenderedAt time.Time `json:"rendered_at"`
+}
+
+// GetInvoicePDF returns a presigned link to the invoice PDF. The PDF is kept
+// in the private bucket and rendered again whenever the invoice, its client
+// or the workspace branding changed since it was last rendered.
+func (s *InvoiceService) GetInvoicePDF(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID) (*InvoicePDF, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.getInvoice(ctx, workspaceID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	objectName, renderedAt, err := s.renderInvoicePDF(ctx, invoice, true)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := s.storage.GetPresignedURL(ctx, objectName, invoicePDFURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InvoicePDF{
+		URL:        url,
+		ExpiresAt:  time.Now().Add(invoicePDFURLExpiry),
+		RenderedAt: renderedAt,
+	}, nil
+}
+
+// renderInvoicePDF makes sure the stored PDF of invoice is current and
+// returns its object name and when it was rendered. Without chargeQuota the
+// PDF is stored even past the workspace storage limit; its bytes are still
+// counted once uploaded.
+func (s *InvoiceService) renderInvoicePDF(ctx context.Context, invoice *store.Invoice, chargeQuota bool) (string, time.Time, error) {
+	workspaceID := invoice.WorkspaceID
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	workspace, err := s.store.Workspaces.GetWorkspaceByID(ctx, workspaceID)
+	if err != nil {
+		if errors.Is(err, store.ErrWorkspaceNotFound) {
+			return "", time.Time{}, ErrWorkspaceNotFound
+		}
+		return "", time.Time{}, err
+	}
+	client, err := s.store.Clients.GetClient(ctx, workspaceID, invoice.ClientID)
+	if err != nil {
+		if errors.Is(err, store.ErrClientNotFound) {
+			return "", time.Time{}, ErrClientNotFound
+		}
+		return "", time.Time{}, err
+	}
+
+	fingerprint := invoicePDFFingerprint(invoice, client, workspace, settings)
+	if invoice.PDFObject != nil && invoice.PDFFingerprint != nil && *invoice.PDFFingerprint == fingerprint {
+		return *invoice.PDFObject, *invoice.PDFRenderedAt, nil
+	}
+
+	items, err := s.store.Invoices.ListInvoiceItems(ctx, invoice.ID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var logo []byte
+	if settings.Settings.LogoURL != nil {
+		if logo, err = fetchLogo(ctx, *settings.Settings.LogoURL); err != nil {
+			s.logger.Warn().Err(err).Str("workspace_id", workspaceID.String()).Msg("rendering invoice without logo")
+		}
+	}
+
+	var buf bytes.Buffer
+	doc := layoutInvoicePDF(invoice, items, client, workspace.Name, settings.Settings, logo)
+	if _, err := doc.WriteTo(&buf); err != nil {
+		return "", time.Time{}, err
+	}
+
+	// The PDF is uploaded between reserving its storage and recording it, so
+	// neither the invoice nor the usage counters are locked during the
+	// transfer.
+	objectName := invoicePDFObject(workspaceID, invoice.ID)
+	size := int64(buf.Len())
+	var reserved int64
+	if chargeQuota {
+		if reserved, err = reserveStoredObject(ctx, s.store, workspaceID, objectName, size); err != nil {
+			return "", time.Time{}, err
+		}
+	}
+
+	var renderedAt time.Time
+	recorded := false
+	_, err = s.storage.Upload(ctx, pkgstorage.BucketPrivate, objectName, &buf, size, "application/pdf")
+	if err == nil {
+		err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+			current, err := tx.Invoices.LockInvoice(ctx, workspaceID, invoice.ID)
+			if err != nil {
+				return err
+			}
+			// Another request rendered the same PDF in the meantime.
+			if current.PDFFingerprint != nil && *current.PDFFingerprint == fingerprint {
+				renderedAt = *current.PDFRenderedAt
+				return nil
+			}
+
+			if err := recordStoredObject(ctx, tx, workspaceID, objectName, size, reserved); err != nil {
+				return err
+			}
+			if err := tx.Invoices.SetInvoicePDF(ctx, workspaceID, invoice.ID, objectName, fingerprint); err != nil {
+				return err
+			}
+
+			updated, err := tx.Invoices.GetInvoice(ctx, workspaceID, invoice.ID)
+			if err != nil {
+				return err
+			}
+			renderedAt = *updated.PDFRenderedAt
+			recorded = true
+			return nil
+		})
+	}
+	if err != nil || !recorded {
+		if releaseErr := releaseStoredObject(ctx, s.store, workspaceID, reserved); releaseErr != nil {
+			s.logger.Warn().Err(releaseErr).Str("invoice_id", invoice.ID.String()).Msg("failed to release invoice PDF storage reservation")
+		}
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrInvoiceNotFound) {
+			return "", time.Time{}, ErrInvoiceNotFound
+		}
+		return "", time.Time{}, err
+	}
+
+	return objectName, renderedAt, nil
+}
+
+func invoicePDFObject(workspaceID, invoiceID uuid.UUID) string {
+	return fmt.Sprintf("invoices/%s/%s.pdf", workspaceID, invoiceID)
+}
+
+// invoicePDFFingerprint identifies everything a PDF is rendered from. Line
+// items are replaced together with the invoice, so its updated_at covers them.
+func invoicePDFFingerprint(invoice *store.Invoice, client *store.Client, workspace *store.Workspace, settings *store.WorkspaceSettings) string {
+	projectName := ""
+	if invoice.ProjectName != nil {
+		projectName = *invoice.ProjectName
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n%s\n%s\n%s\n%s",
+		invoicePDFLayout,
+		invoice.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		client.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		workspace.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		settings.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		projectName,
+	)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func fetchLogo(ctx context.Context, logoURL string) ([]byte, error) {
+	u, err := url.Parse(logoURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, errors.New("logo URL is not https")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := logoClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching logo: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxInvoiceLogoBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxInvoiceLogoBytes {
+		return nil, errors.New("logo is larger than 2 MB")
+	}
+	return data, nil
+}
+
+// formatWorkspaceDate writes a date in one of the workspace date formats,
+// such as DD.MM.YYYY.
+func formatWorkspaceDate(t time.Time, format string) string {
+	layout := strings.NewReplacer("YYYY", "2006", "MM", "01", "DD", "02").Replace(format)
+	return t.Format(layout)
+}
+
+// Invoice PDF geometry, in points.
+const (
+	pdfMargin     = 48.0
+	pdfBottom     = pdf.PageHeight - 56
+	pdfLineHeight = 13.0
+	pdfBodySize   = 9.5
+)
+
+// invoiceColumn is a column of the line item table. Columns after the first
+// are right-aligned and end at right.
+type invoiceColumn struct {
+	title string
+	right float64
+}
+
+var invoiceColumns = []invoiceColumn{
+	{title: "Description", right: 300},
+	{title: "Qty", right: 350},
+	{title: "Unit price", right: 425},
+	{title: "Discount", right: 470},
+	{title: "Tax", right: 505},
+	{title: "Amount", right: pdf.PageWidth - pdfMargin},
+}
+
+var (
+	pdfText  = pdf.Color{R: 31, G: 41, B: 55}
+	pdfMuted = pdf.Color{R: 107, G: 114, B: 128}
+	pdfRule  = pdf.Color{R: 229, G: 231, B: 235}
+)
+
+// layoutInvoicePDF draws the invoice. Amounts, quantities and dates follow
+// the workspace locale and date format; the header uses the brand color and
+// the logo when there is one.
+func layoutInvoicePDF(invoice *store.Invoice, items []store.InvoiceItem, client *store.Client, workspaceName string, settings store.SettingsDocument, logo []byte) *pdf.Document {
+	brand, err := pdf.ParseHexColor(settings.BrandColor)
+	if err != nil {
+		brand, _ = pdf.ParseHexColor(store.DefaultSettingsDocument().BrandColor)
+	}
+	numbers := money.FormatFor(settings.Locale)
+	date := func(t *time.Time) string {
+		if t == nil {
+			return "—"
+		}
+		return formatWorkspaceDate(*t, settings.DateFormat)
+	}
+	amount := func(v int64) string {
+		return numbers.Amount(v, invoice.Currency)
+	}
+
+	title := "Invoice"
+	if invoice.Number != nil {
+		title += " " + *invoice.Number
+	}
+	doc := pdf.New(title)
+	page := doc.AddPage()
+	pages := []*pdf.Page{page}
+	right := pdf.PageWidth - pdfMargin
+
+	page.Rect(0, 0, pdf.PageWidth, 6, brand)
+
+	// Header: the logo or workspace name on the left, the title on the right.
+	y := pdfMargin
+	drewLogo := false
+	if logo != nil {
+		if img, err := doc.AddImage(logo); err == nil {
+			w, h := img.Size()
+			scale := min(180/float64(w), 56/float64(h))
+			page.Image(img, pdfMargin, y, float64(w)*scale, float64(h)*scale)
+			drewLogo = true
+		}
+	}
+	if !drewLogo {
+		page.Text(pdfMargin, y+18, pdf.HelveticaBold, 18, brand, workspaceName)
+	}
+
+	page.TextRight(right, y+20, pdf.HelveticaBold, 24, brand, "INVOICE")
+	number := "Draft"
+	if invoice.Number != nil {
+		number = *invoice.Number
+	}
+	page.TextRight(right, y+38, pdf.Helvetica, 11, pdfText, number)
+	if invoice.Status != "sent" && invoice.Status != "viewed" {
+		label := strings.ToUpper(invoice.Status)
+		width := pdf.TextWidth(pdf.HelveticaBold, 8, label) + 12
+		page.Rect(right-width, y+46, width, 14, brand.Lighten(0.85))
+		page.TextRight(right-6, y+56, pdf.HelveticaBold, 8, brand, label)
+	}
+
+	// Billing details: the client on the left, the dates on the right.
+	y += 96
+	page.Text(pdfMargin, y, pdf.HelveticaBold, 8, pdfMuted, "BILL TO")
+	y += 16
+	page.Text(pdfMargin, y, pdf.HelveticaBold, 11, pdfText, client.Name)
+	clientY := y
+	var details []string
+	if client.Address != nil {
+		details = append(details, pdf.WrapText(pdf.Helvetica, pdfBodySize, *client.Address, 240)...)
+	}
+	if client.Email != nil {
+		details = append(details, *client.Email)
+	}
+	for _, line := range details {
+		clientY += pdfLineHeight
+		page.Text(pdfMargin, clientY, pdf.Helvetica, pdfBodySize, pdfText, line)
+	}
+
+	meta := [][2]string{
+		{"Issue date", date(invoice.IssueDate)},
+		{"Due date", date(invoice.DueDate)},
+	}
+	if invoice.ProjectName != nil {
+		meta = append(meta, [2]string{"Project", *invoice.ProjectName})
+	}
+	metaY := y - 16
+	for _, row := range meta {
+		page.Text(360, metaY, pdf.Helvetica, pdfBodySize, pdfMuted, row[0])
+		page.TextRight(right, metaY, pdf.Helvetica, pdfBodySize, pdfText, row[1])
+		metaY += pdfLineHeight + 2
+	}
+	y = max(clientY, metaY) + 28
+
+	// Line items, continued on further pages as needed.
+	tableHeader := func(page *pdf.Page, y float64) float64 {
+		page.Rect(pdfMargin, y, right-pdfMargin, 20, brand.Lighten(0.88))
+		for i, col := range invoiceColumns {
+			if i == 0 {
+				page.Text(pdfMargin+6, y+13.5, pdf.HelveticaBold, 8.5, pdfText, col.title)
+				continue
+			}
+			page.TextRight(col.right-6, y+13.5, pdf.HelveticaBold, 8.5, pdfText, col.title)
+		}
+		return y + 20
+	}
+	y = tableHeader(page, y)
+
+	for _, item := range items {
+		lines := pdf.WrapText(pdf.Helvetica, pdfBodySize, item.Description, invoiceColumns[0].right-pdfMargin-18)
+		height := float64(len(lines))*pdfLineHeight + 10
+		if y+height > pdfBottom {
+			page = doc.AddPage()
+			pages = append(pages, page)
+			y = tableHeader(page, pdfMargin)
+		}
+
+		rate := invoice.TaxRate
+		if item.TaxRate != nil {
+			rate = *item.TaxRate
+		}
+		discount := ""
+		if item.DiscountPercent != 0 {
+			discount = numbers.Number(item.DiscountPercent) + "%"
+		}
+		cells := []string{
+			numbers.Number(item.Quantity),
+			amount(item.UnitPrice),
+			discount,
+			numbers.Number(rate) + "%",
+			amount(item.Amount),
+		}
+
+		baseline := y + pdfLineHeight + 1
+		for i, line := range lines {
+			page.Text(pdfMargin+6, baseline+float64(i)*pdfLineHeight, pdf.Helvetica, pdfBodySize, pdfText, line)
+		}
+		for i, cell := range cells {
+			page.TextRight(invoiceColumns[i+1].right-6, baseline, pdf.Helvetica, pdfBodySize, pdfText, cell)
+		}
+		y += height
+		page.Line(pdfMargin, y, right, y, 0.5, pdfRule)
+	}
+
+	// Totals, kept together under the table.
+	totals := [][2]string{{"Subtotal", amount(invoice.Subtotal)}}
+	if invoice.DiscountTotal != 0 {
+		totals = append(totals, [2]string{"Discount", amount(-invoice.DiscountTotal)})
+	}
+	totals = append(totals,
+		[2]string{"Tax", amount(invoice.TaxTotal)},
+		[2]string{"Total", amount(invoice.Total)},
+	)
+	if invoice.AmountPaid != 0 {
+		totals = append(totals, [2]string{"Paid", amount(-invoice.AmountPaid)})
+	}
+	if y+float64(len(totals)+1)*(pdfLineHeight+4)+24 > pdfBottom {
+		page = doc.AddPage()
+		pages = append(pages, page)
+		y = pdfMargin
+	}
+	y += 12
+	for _, row := range totals {
+		y += pdfLineHeight + 4
+		page.Text(360, y, pdf.Helvetica, pdfBodySize, pdfMuted, row[0])
+		page.TextRight(right-6, y, pdf.Helvetica, pdfBodySize, pdfText, row[1])
+	}
+	y += 10
+	page.Rect(350, y, right-350, 26, brand)
+	page.Text(360, y+17, pdf.HelveticaBold, 10.5, pdf.White, "Balance due")
+	page.TextRight(right-6, y+17, pdf.HelveticaBold, 10.5, pdf.White, amount(invoice.BalanceDue))
+	y += 26
+
+	// Notes and terms follow the totals and may run onto a new page.
+	for _, block := range []struct {
+		title string
+		text  *string
+	}{{"Notes", invoice.Notes}, {"Terms", invoice.Terms}} {
+		if block.text == nil {
+			continue
+		}
+		y += 28
+		if y+2*pdfLineHeight > pdfBottom {
+			page = doc.AddPage()
+			pages = append(pages, page)
+			y = pdfMargin
+		}
+		page.Text(pdfMargin, y, pdf.HelveticaBold, 8, pdfMuted, strings.ToUpper(block.title))
+		for _, line := range pdf.WrapText(pdf.Helvetica, pdfBodySize, *block.text, right-pdfMargin) {
+			y += pdfLineHeight
+			if y > pdfBottom {
+				page = doc.AddPage()
+				pages = append(pages, page)
+				y = pdfMargin
+			}
+			page.Text(pdfMargin, y, pdf.Helvetica, pdfBodySize, pdfText, line)
+		}
+	}
+
+	for i, page := range pages {
+		footerY := pdf.PageHeight - 28
+		page.Line(pdfMargin, footerY-14, right, footerY-14, 0.5, pdfRule)
+		page.Text(pdfMargin, footerY, pdf.Helvetica, 8, pdfMuted, workspaceName+" · "+number)
+		page.TextRight(right, footerY, pdf.Helvetica, 8, pdfMuted, fmt.Sprintf("Page %d of %d", i+1, len(pages)))
+	}
+
+	return doc
+}
@@ -0,0 +1,632 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/money"
+	"github.com/lukabrkovic/artemis/pkg/rrule"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrRecurringInvoiceNotFound   = errors.New("recurring invoice not found")
+	ErrInvalidRecurringInvoiceEnd = errors.New("end_date must not be before start_date")
+)
+
+type CreateRecurringInvoiceInput struct {
+	Name            string             `json:"name" validate:"required,min=1,max=255"`
+	ClientID        uuid.UUID          `json:"client_id" validate:"required"`
+	ProjectID       *uuid.UUID         `json:"project_id,omitempty"`
+	Currency        *string            `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	DiscountPercent *money.Decimal     `json:"discount_percent,omitempty" validate:"omitempty,percent" swaggertype:"number"`
+	TaxRate         *money.Decimal     `json:"tax_rate,omitempty" validate:"omitempty,percent" swaggertype:"number"`
+	Notes           *string            `json:"notes,omitempty" validate:"omitempty,max=5000"`
+	Terms           *string            `json:"terms,omitempty" validate:"omitempty,max=5000"`
+	Items           []InvoiceItemInput `json:"items" validate:"required,min=1,max=200,dive"`
+	RRule           string             `json:"rrule" validate:"required,max=500"`
+	StartDate       *string            `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	DueDays         *int               `json:"due_days,omitempty" validate:"omitempty,min=0,max=365"`
+	EndDate         *string            `json:"end_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	MaxInvoices     *int32             `json:"max_invoices,omitempty" validate:"omitempty,min=1"`
+	AutoSend        bool               `json:"auto_send"`
+}
+
+// UpdateRecurringInvoiceInput only changes the fields that are set and applies
+// to invoices that have not been created yet. An empty project_id or end_date
+// clears it, max_invoices 0 removes the limit, and items replaces every line
+// when present.
+type UpdateRecurringInvoiceInput struct {
+	Name            *string            `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	ClientID        *uuid.UUID         `json:"client_id,omitempty"`
+	ProjectID       *string            `json:"project_id,omitempty" validate:"omitempty,uuid"`
+	Currency        *string            `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	DiscountPercent *money.Decimal     `json:"discount_percent,omitempty" validate:"omitempty,percent" swaggertype:"number"`
+	TaxRate         *money.Decimal     `json:"tax_rate,omitempty" validate:"omitempty,percent" swaggertype:"number"`
+	Notes           *string            `json:"notes,omitempty" validate:"omitempty,max=5000"`
+	Terms           *string            `json:"terms,omitempty" validate:"omitempty,max=5000"`
+	Items           []InvoiceItemInput `json:"items,omitempty" validate:"omitempty,max=200,dive"`
+	RRule           *string            `json:"rrule,omitempty" validate:"omitempty,max=500"`
+	StartDate       *string            `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	DueDays         *int               `json:"due_days,omitempty" validate:"omitempty,min=0,max=365"`
+	EndDate         *string            `json:"end_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	MaxInvoices     *int32             `json:"max_invoices,omitempty" validate:"omitempty,min=0"`
+	AutoSend        *bool              `json:"auto_send,omitempty"`
+}
+
+type RecurringInvoice interface {
+	CreateRecurringInvoice(ctx context.Context, userID, workspaceID uuid.UUID, input CreateRecurringInvoiceInput) (*store.InvoiceRecurrence, error)
+	GetRecurringInvoice(ctx context.Context, userID, workspaceID, recurrenceID uuid.UUID) (*store.InvoiceRecurrence, error)
+	ListRecurringInvoices(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.InvoiceRecurrence], error)
+	UpdateRecurringInvoice(ctx context.Context, userID, workspaceID, recurrenceID uuid.UUID, input UpdateRecurringInvoiceInput) (*store.InvoiceRecurrence, error)
+	DeleteRecurringInvoice(ctx context.Context, userID, workspaceID, recurrenceID uuid.UUID) error
+
+	GenerateDueInvoices(ctx context.Context) (int, error)
+}
+
+type RecurringInvoiceService struct {
+	store    *store.Store
+	invoices *InvoiceService
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewRecurringInvoiceService(store *store.Store, eventBus EventPublisher, logger zerolog.Logger) *RecurringInvoiceService {
+	return &RecurringInvoiceService{
+		store:    store,
+		invoices: NewInvoiceService(store, nil, eventBus, logger),
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "recurring_invoice_service").Logger(),
+	}
+}
+
+var _ RecurringInvoice = (*RecurringInvoiceService)(nil)
+
+// CreateRecurringInvoice stores the template and creates its invoice right
+// away when the first occurrence is today. Without a start date the rule
+// starts today in the workspace timezone, and earlier occurrences are never
+// invoiced.
+func (s *RecurringInvoiceService) CreateRecurringInvoice(ctx context.Context, userID, workspaceID uuid.UUID, input CreateRecurringInvoiceInput) (*store.InvoiceRecurrence, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	client, err := s.invoices.checkInvoiceReferences(ctx, workspaceID, input.ClientID, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := rrule.Parse(input.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	today, err := s.today(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := store.CreateInvoiceRecurrenceParams{
+		WorkspaceID: workspaceID,
+		ClientID:    client.ID,
+		ProjectID:   input.ProjectID,
+		Name:        strings.TrimSpace(input.Name),
+		Currency:    client.Currency,
+		Notes:       trimDescription(input.Notes),
+		Terms:       trimDescription(input.Terms),
+		RRule:       rule.String(),
+		StartDate:   today,
+		DueDays:     defaultInvoiceDueDays,
+		MaxInvoices: input.MaxInvoices,
+		AutoSend:    input.AutoSend,
+		CreatedBy:   userID,
+	}
+	if input.Currency != nil {
+		params.Currency = strings.ToUpper(*input.Currency)
+	}
+	if input.DiscountPercent != nil {
+		params.DiscountPercent = *input.DiscountPercent
+	}
+	if input.TaxRate != nil {
+		params.TaxRate = *input.TaxRate
+	}
+	if input.DueDays != nil {
+		params.DueDays = *input.DueDays
+	}
+	if input.StartDate != nil {
+		parsed, err := parseDate(input.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		if parsed != nil {
+			params.StartDate = *parsed
+		}
+	}
+	if params.EndDate, err = parseDate(input.EndDate); err != nil {
+		return nil, err
+	}
+	if params.EndDate != nil && params.EndDate.Before(params.StartDate) {
+		return nil, ErrInvalidRecurringInvoiceEnd
+	}
+
+	next, ok := rule.After(params.StartDate, later(params.StartDate, today))
+	if !ok || (params.EndDate != nil && next.After(*params.EndDate)) {
+		return nil, ErrNoUpcomingOccurrences
+	}
+	params.NextDate = &next
+
+	var recurrence *store.InvoiceRecurrence
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		var err error
+		if recurrence, err = tx.InvoiceRecurrences.CreateInvoiceRecurrence(ctx, params); err != nil {
+			return err
+		}
+		return tx.InvoiceRecurrences.ReplaceInvoiceRecurrenceItems(ctx, recurrence.ID, recurrenceItemParams(input.Items))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.generateAndReload(ctx, recurrence)
+}
+
+// GetRecurringInvoice returns the template with its line items.
+func (s *RecurringInvoiceService) GetRecurringInvoice(ctx context.Context, userID, workspaceID, recurrenceID uuid.UUID) (*store.InvoiceRecurrence, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	recurrence, err := s.getRecurrence(ctx, workspaceID, recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+	if recurrence.Items, err = s.store.InvoiceRecurrences.ListInvoiceRecurrenceItems(ctx, recurrence.ID); err != nil {
+		return nil, err
+	}
+	return recurrence, nil
+}
+
+// ListRecurringInvoices lists the templates without their line items. It can
+// be narrowed to a client, and to schedules that are still running
+// (active=true) or have ended.
+func (s *RecurringInvoiceService) ListRecurringInvoices(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.InvoiceRecurrence], error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	var filter store.InvoiceRecurrenceFilter
+	var err error
+	if filter.ClientID, err = parseUUIDFilter(filters.Filters["client_id"]); err != nil {
+		return nil, err
+	}
+	switch filters.Filters["active"] {
+	case "true":
+		active := true
+		filter.Active = &active
+	case "false":
+		active := false
+		filter.Active = &active
+	}
+
+	recurrences, total, err := s.store.InvoiceRecurrences.ListInvoiceRecurrences(ctx, workspaceID, filter, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.BuildFilterResponse(recurrences, total, filters), nil
+}
+
+// UpdateRecurringInvoice changes the template. A new rule, start date or end
+// condition reschedules the next invoice to the first date of the new schedule
+// after the last invoice created, and never before today. A schedule that has
+// ended runs again once its end conditions allow it. The recurrence is locked
+// while it changes, so the scheduler cannot move its next date underneath.
+func (s *RecurringInvoiceService) UpdateRecurringInvoice(ctx context.Context, userID, workspaceID, recurrenceID uuid.UUID, input UpdateRecurringInvoiceInput) (*store.InvoiceRecurrence, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	var updated *store.InvoiceRecurrence
+	err := s.store.ExecTx(ctx, func(tx *store.Store) error {
+		recurrence, err := tx.InvoiceRecurrences.GetInvoiceRecurrenceForUpdate(ctx, workspaceID, recurrenceID)
+		if err != nil {
+			return err
+		}
+
+		params := store.UpdateInvoiceRecurrenceParams{
+			ID:              recurrence.ID,
+			WorkspaceID:     workspaceID,
+			ClientID:        recurrence.ClientID,
+			ProjectID:       recurrence.ProjectID,
+			Name:            recurrence.Name,
+			Currency:        recurrence.Currency,
+			DiscountPercent: recurrence.DiscountPercent,
+			TaxRate:         recurrence.TaxRate,
+			Notes:           recurrence.Notes,
+			Terms:           recurrence.Terms,
+			RRule:           recurrence.RRule,
+			StartDate:       recurrence.StartDate,
+			DueDays:         recurrence.DueDays,
+			EndDate:         recurrence.EndDate,
+			MaxInvoices:     recurrence.MaxInvoices,
+			AutoSend:        recurrence.AutoSend,
+		}
+		if input.Name != nil {
+			params.Name = strings.TrimSpace(*input.Name)
+		}
+		if input.ClientID != nil {
+			params.ClientID = *input.ClientID
+		}
+		if input.ProjectID != nil {
+			if params.ProjectID, err = parseUUIDFilter(*input.ProjectID); err != nil {
+				return err
+			}
+		}
+		if input.ClientID != nil || input.ProjectID != nil {
+			if _, err := s.invoices.checkInvoiceReferences(ctx, workspaceID, params.ClientID, params.ProjectID); err != nil {
+				return err
+			}
+		}
+		if input.Currency != nil {
+			params.Currency = strings.ToUpper(*input.Currency)
+		}
+		if input.DiscountPercent != nil {
+			params.DiscountPercent = *input.DiscountPercent
+		}
+		if input.TaxRate != nil {
+			params.TaxRate = *input.TaxRate
+		}
+		if input.Notes != nil {
+			params.Notes = trimDescription(input.Notes)
+		}
+		if input.Terms != nil {
+			params.Terms = trimDescription(input.Terms)
+		}
+		if input.DueDays != nil {
+			params.DueDays = *input.DueDays
+		}
+		if input.AutoSend != nil {
+			params.AutoSend = *input.AutoSend
+		}
+		if input.MaxInvoices != nil {
+			params.MaxInvoices = input.MaxInvoices
+			if *input.MaxInvoices == 0 {
+				params.MaxInvoices = nil
+			}
+		}
+		if input.EndDate != nil {
+			if params.EndDate, err = parseDate(input.EndDate); err != nil {
+				return err
+			}
+		}
+
+		if input.RRule != nil || input.StartDate != nil || input.EndDate != nil || input.MaxInvoices != nil {
+			rule, err := rrule.Parse(params.RRule)
+			if input.RRule != nil {
+				rule, err = rrule.Parse(*input.RRule)
+			}
+			if err != nil {
+				return err
+			}
+			params.RRule = rule.String()
+
+			if input.StartDate != nil {
+				parsed, err := parseDate(input.StartDate)
+				if err != nil {
+					return err
+				}
+				if parsed != nil {
+					params.StartDate = *parsed
+				}
+			}
+			if params.EndDate != nil && params.EndDate.Before(params.StartDate) {
+				return ErrInvalidRecurringInvoiceEnd
+			}
+
+			from, err := s.today(ctx, workspaceID)
+			if err != nil {
+				return err
+			}
+			from = later(from, params.StartDate)
+			if recurrence.LastOccurrenceDate != nil {
+				from = later(from, recurrence.LastOccurrenceDate.AddDate(0, 0, 1))
+			}
+			next, ok := rule.After(params.StartDate, from)
+			if !ok {
+				return ErrNoUpcomingOccurrences
+			}
+			var nextDate *time.Time
+			if recurringInvoiceContinues(params.EndDate, params.MaxInvoices, next, recurrence.InvoiceCount) {
+				nextDate = &next
+			}
+			if err := tx.InvoiceRecurrences.SetInvoiceRecurrenceNextDate(ctx, recurrence.ID, nextDate); err != nil {
+				return err
+			}
+		}
+
+		if updated, err = tx.InvoiceRecurrences.UpdateInvoiceRecurrence(ctx, params); err != nil {
+			return err
+		}
+		if len(input.Items) == 0 {
+			return nil
+		}
+		return tx.InvoiceRecurrences.ReplaceInvoiceRecurrenceItems(ctx, updated.ID, recurrenceItemParams(input.Items))
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrInvoiceRecurrenceNotFound) {
+			return nil, ErrRecurringInvoiceNotFound
+		}
+		return nil, err
+	}
+
+	return s.generateAndReload(ctx, updated)
+}
+
+// DeleteRecurringInvoice stops the schedule and keeps the invoices already
+// created.
+func (s *RecurringInvoiceService) DeleteRecurringInvoice(ctx context.Context, userID, workspaceID, recurrenceID uuid.UUID) error {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+
+	if err := s.store.InvoiceRecurrences.DeleteInvoiceRecurrence(ctx, workspaceID, recurrenceID); err != nil {
+		if errors.Is(err, store.ErrInvoiceRecurrenceNotFound) {
+			return ErrRecurringInvoiceNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GenerateDueInvoices creates the invoices of every recurrence whose next
+// issue date has arrived in its workspace timezone, and sends those marked
+// auto_send. It is safe to run on several replicas at once and to rerun after
+// a restart: a recurrence is locked while its invoices are created, its next
+// date moves in the same transaction, and an occurrence already invoiced is
+// skipped.
+func (s *RecurringInvoiceService) GenerateDueInvoices(ctx context.Context) (int, error) {
+	// Workspaces ahead of UTC may already be on the next day.
+	through := rrule.Date(time.Now().UTC()).AddDate(0, 0, 1)
+
+	ids, err := s.store.InvoiceRecurrences.ListDueInvoiceRecurrenceIDs(ctx, through, recurrenceBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return created, err
+		}
+		n, err := s.generate(ctx, id)
+		if err != nil {
+			s.logger.Error().Err(err).Str("recurrence_id", id.String()).Msg("failed to create recurring invoices")
+			continue
+		}
+		created += n
+	}
+
+	if created > 0 {
+		s.logger.Info().Int("invoices", created).Msg("created recurring invoices")
+	}
+	return created, nil
+}
+
+// generate creates the due invoices of one recurrence and advances its next
+// date. A recurrence locked by another replica is left to that replica, and
+// one whose client is gone ends.
+func (s *RecurringInvoiceService) generate(ctx context.Context, recurrenceID uuid.UUID) (int, error) {
+	var created []*store.Invoice
+	var actorID uuid.UUID
+	var recurrence *store.InvoiceRecurrence
+
+	err := s.store.ExecTx(ctx, func(tx *store.Store) error {
+		var err error
+		recurrence, err = tx.InvoiceRecurrences.LockInvoiceRecurrence(ctx, recurrenceID)
+		if err != nil {
+			if errors.Is(err, store.ErrInvoiceRecurrenceNotFound) {
+				return nil
+			}
+			return err
+		}
+		if recurrence.NextDate == nil {
+			return nil
+		}
+		if _, err := tx.Clients.GetClient(ctx, recurrence.WorkspaceID, recurrence.ClientID); err != nil {
+			if errors.Is(err, store.ErrClientNotFound) {
+				return tx.InvoiceRecurrences.SetInvoiceRecurrenceNextDate(ctx, recurrence.ID, nil)
+			}
+			return err
+		}
+		if recurrence.CreatedBy != nil {
+			actorID = *recurrence.CreatedBy
+		}
+
+		settings, err := getWorkspaceSettings(ctx, tx, recurrence.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		today := workspaceToday(settings.Settings.Timezone)
+
+		rule, err := rrule.Parse(recurrence.RRule)
+		if err != nil {
+			return err
+		}
+
+		templateItems, err := tx.InvoiceRecurrences.ListInvoiceRecurrenceItems(ctx, recurrence.ID)
+		if err != nil {
+			return err
+		}
+		items, totals, err := priceInvoice(recurrence.DiscountPercent, recurrence.TaxRate, recurrenceItemInputs(templateItems))
+		if err != nil {
+			return err
+		}
+
+		count := recurrence.InvoiceCount
+		next := recurrence.NextDate
+		for n := 0; n < maxOccurrencesPerRun && next != nil && !next.After(today); n++ {
+			if !recurringInvoiceContinues(recurrence.EndDate, recurrence.MaxInvoices, *next, count) {
+				next = nil
+				break
+			}
+
+			// An occurrence already invoiced, say because the schedule was
+			// moved back onto it, is counted already and only skipped.
+			exists, err := tx.InvoiceRecurrences.InvoiceRecurrenceOccurrenceExists(ctx, recurrence.ID, *next)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				invoice, err := createRecurringInvoice(ctx, tx, recurrence, *next, items, totals)
+				if err != nil {
+					return err
+				}
+				if recurrence.AutoSend {
+					if invoice, _, err = transitionInvoice(ctx, tx, invoice, "sent", settings.Settings); err != nil {
+						return err
+					}
+				}
+				created = append(created, invoice)
+				count++
+			}
+
+			following, ok := rule.After(recurrence.StartDate, next.AddDate(0, 0, 1))
+			next = nil
+			if ok && recurringInvoiceContinues(recurrence.EndDate, recurrence.MaxInvoices, following, count) {
+				next = &following
+			}
+		}
+
+		if sameDate(next, recurrence.NextDate) {
+			return nil
+		}
+		return tx.InvoiceRecurrences.SetInvoiceRecurrenceNextDate(ctx, recurrence.ID, next)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, invoice := range created {
+		if invoice.Status == "sent" {
+			publishInvoiceStatus(ctx, s.eventBus, actorID, invoice)
+			continue
+		}
+		if s.eventBus != nil {
+			s.eventBus.Publish(ctx, events.EventRecurringInvoiceCreated, actorID, map[string]any{
+				"workspace_id":    invoice.WorkspaceID,
+				"invoice_id":      invoice.ID,
+				"recurrence_id":   recurrence.ID,
+				"recurrence_name": recurrence.Name,
+				"client_id":       invoice.ClientID,
+				"currency":        invoice.Currency,
+				"total":           invoice.Total,
+			})
+		}
+	}
+	return len(created), nil
+}
+
+func (s *RecurringInvoiceService) generateAndReload(ctx context.Context, recurrence *store.InvoiceRecurrence) (*store.InvoiceRecurrence, error) {
+	if _, err := s.generate(ctx, recurrence.ID); err != nil {
+		return nil, err
+	}
+
+	reloaded, err := s.store.InvoiceRecurrences.GetInvoiceRecurrence(ctx, recurrence.WorkspaceID, recurrence.ID)
+	if err != nil {
+		return nil, err
+	}
+	if reloaded.Items, err = s.store.InvoiceRecurrences.ListInvoiceRecurrenceItems(ctx, reloaded.ID); err != nil {
+		return nil, err
+	}
+	return reloaded, nil
+}
+
+// createRecurringInvoice adds the draft invoice of one occurrence, issued on
+// the occurrence date and due due_days later.
+func createRecurringInvoice(ctx context.Context, tx *store.Store, recurrence *store.InvoiceRecurrence, date time.Time, items []store.InvoiceItemParams, totals store.InvoiceTotals) (*store.Invoice, error) {
+	dueDate := date.AddDate(0, 0, recurrence.DueDays)
+	invoice, err := tx.Invoices.CreateInvoice(ctx, store.CreateInvoiceParams{
+		WorkspaceID:     recurrence.WorkspaceID,
+		ClientID:        recurrence.ClientID,
+		ProjectID:       recurrence.ProjectID,
+		Currency:        recurrence.Currency,
+		IssueDate:       &date,
+		DueDate:         &dueDate,
+		DiscountPercent: recurrence.DiscountPercent,
+		TaxRate:         recurrence.TaxRate,
+		Notes:           recurrence.Notes,
+		Terms:           recurrence.Terms,
+		Totals:          totals,
+		RecurrenceID:    &recurrence.ID,
+		OccurrenceDate:  &date,
+		CreatedBy:       recurrence.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Invoices.ReplaceInvoiceItems(ctx, invoice.ID, items); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// recurringInvoiceContinues reports whether the schedule still invoices date
+// after count invoices were created.
+func recurringInvoiceContinues(endDate *time.Time, maxInvoices *int32, date time.Time, count int64) bool {
+	if endDate != nil && date.After(*endDate) {
+		return false
+	}
+	return maxInvoices == nil || count < int64(*maxInvoices)
+}
+
+func recurrenceItemParams(lines []InvoiceItemInput) []store.InvoiceRecurrenceItemParams {
+	items := make([]store.InvoiceRecurrenceItemParams, 0, len(lines))
+	for _, line := range lines {
+		items = append(items, store.InvoiceRecurrenceItemParams{
+			Description:     strings.TrimSpace(line.Description),
+			Quantity:        line.Quantity,
+			UnitPrice:       line.UnitPrice,
+			DiscountPercent: line.DiscountPercent,
+			TaxRate:         line.TaxRate,
+		})
+	}
+	return items
+}
+
+func recurrenceItemInputs(items []store.InvoiceRecurrenceItem) []InvoiceItemInput {
+	lines := make([]InvoiceItemInput, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, InvoiceItemInput{
+			Description:     item.Description,
+			Quantity:        item.Quantity,
+			UnitPrice:       item.UnitPrice,
+			DiscountPercent: item.DiscountPercent,
+			TaxRate:         item.TaxRate,
+		})
+	}
+	return lines
+}
+
+func (s *RecurringInvoiceService) getRecurrence(ctx context.Context, workspaceID, recurrenceID uuid.UUID) (*store.InvoiceRecurrence, error) {
+	recurrence, err := s.store.InvoiceRecurrences.GetInvoiceRecurrence(ctx, workspaceID, recurrenceID)
+	if err != nil {
+		if errors.Is(err, store.ErrInvoiceRecurrenceNotFound) {
+			return nil, ErrRecurringInvoiceNotFound
+		}
+		return nil, err
+	}
+	return recurrence, nil
+}
+
+func (s *RecurringInvoiceService) today(ctx context.Context, workspaceID uuid.UUID) (time.Time, error) {
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return workspaceToday(settings.Settings.Timezone), nil
+}
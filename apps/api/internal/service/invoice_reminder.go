@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+const invoiceReminderBatchSize = 500
+
+// MarkOverdueInvoices moves sent and viewed invoices whose due date has passed
+// to overdue. Invoices are only ever flipped once, even with several replicas
+// running the job.
+func (s *InvoiceService) MarkOverdueInvoices(ctx context.Context) (int, error) {
+	invoices, err := s.store.Invoices.MarkOverdueInvoices(ctx, invoiceReminderBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(invoices) > 0 {
+		s.logger.Info().Int("invoices", len(invoices)).Msg("marked invoices overdue")
+	}
+	return len(invoices), nil
+}
+
+// SendInvoiceReminders publishes the payment reminders that fell due today,
+// going by each workspace's reminder days and repeat interval. Each reminder
+// is sent once, even with several replicas running the job.
+func (s *InvoiceService) SendInvoiceReminders(ctx context.Context) (int, error) {
+	reminders, err := s.store.Invoices.ClaimInvoiceReminders(ctx, invoiceReminderBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	settings := map[uuid.UUID]store.SettingsDocument{}
+	for _, reminder := range reminders {
+		if s.eventBus == nil {
+			break
+		}
+
+		workspaceSettings, ok := settings[reminder.WorkspaceID]
+		if !ok {
+			record, err := getWorkspaceSettings(ctx, s.store, reminder.WorkspaceID)
+			if err != nil {
+				s.logger.Warn().Err(err).Str("workspace_id", reminder.WorkspaceID.String()).Msg("failed to load settings for invoice reminders")
+				workspaceSettings = store.DefaultSettingsDocument()
+			} else {
+				workspaceSettings = record.Settings
+			}
+			settings[reminder.WorkspaceID] = workspaceSettings
+		}
+
+		var userID uuid.UUID
+		if reminder.CreatedBy != nil {
+			userID = *reminder.CreatedBy
+		}
+		s.eventBus.Publish(ctx, events.EventInvoiceReminderDue, userID, map[string]any{
+			"workspace_id":   reminder.WorkspaceID,
+			"workspace_name": reminder.WorkspaceName,
+			"invoice_id":     reminder.InvoiceID,
+			"client_id":      reminder.ClientID,
+			"client_name":    reminder.ClientName,
+			"client_email":   reminder.ClientEmail,
+			"number":         reminder.Number,
+			"status":         reminder.Status,
+			"currency":       reminder.Currency,
+			"balance_due":    reminder.BalanceDue,
+			"amount_due":     money.FormatFor(workspaceSettings.Locale).Amount(reminder.BalanceDue, reminder.Currency),
+			"due_date":       reminder.DueDate.Format(dateLayout),
+			"due_on":         formatWorkspaceDate(reminder.DueDate, workspaceSettings.DateFormat),
+			"days_from_due":  reminder.DaysFromDue,
+		})
+	}
+
+	if len(reminders) > 0 {
+		s.logger.Info().Int("invoices", len(reminders)).Msg("sent invoice reminders")
+	}
+	return len(reminders), nil
+}
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/money"
+	pkgstorage "github.com/lukabrkovic/artemis/pkg/storage"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrInvoiceNotShareable    = errors.New("only sent invoices can be shared")
+	ErrShareLinkNotFound      = errors.New("share link not found")
+	ErrShareLinkInvalid       = errors.New("share link is invalid, expired or revoked")
+	ErrInvalidShareLinkExpiry = errors.New("expires_at must be in the future and at most a year away")
+)
+
+const (
+	defaultShareLinkExpiry = 30 * 24 * time.Hour
+	maxShareLinkExpiry     = 365 * 24 * time.Hour
+
+	// shareKeyLabel is the HKDF info the share link key is derived with.
+	shareKeyLabel = "invoice-share"
+)
+
+type CreateInvoiceShareLinkInput struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// InvoiceShareLink is a share link with the token that opens it.
+type InvoiceShareLink struct {
+	store.InvoiceShareLink
+	Token string `json:"token"`
+}
+
+// SharedInvoice is an invoice as its client sees it through a share link,
+// formatted for the workspace locale and date format.
+type SharedInvoice struct {
+	Token         string
+	WorkspaceName string
+	LogoURL       *string
+	BrandColor    string
+	Number        string
+	Status        string
+	ClientName    string
+	ProjectName   *string
+	IssueDate     string
+	DueDate       string
+	Items         []SharedInvoiceItem
+	Subtotal      string
+	Discount      string
+	Tax           string
+	Total         string
+	AmountPaid    string
+	BalanceDue    string
+	Notes         *string
+	Terms         *string
+}
+
+type SharedInvoiceItem struct {
+	Description string
+	Quantity    string
+	UnitPrice   string
+	Amount      string
+}
+
+type InvoiceShare interface {
+	CreateShareLink(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID, input CreateInvoiceShareLinkInput) (*InvoiceShareLink, error)
+	ListShareLinks(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID) ([]InvoiceShareLink, error)
+	RevokeShareLink(ctx context.Context, userID, workspaceID, invoiceID, linkID uuid.UUID) error
+	ViewSharedInvoice(ctx context.Context, token string) (*SharedInvoice, error)
+	GetSharedInvoicePDF(ctx context.Context, token string) (*InvoicePDF, error)
+}
+
+type InvoiceShareService struct {
+	store      *store.Store
+	invoices   *InvoiceService
+	eventBus   EventPublisher
+	signingKey []byte
+	logger     zerolog.Logger
+}
+
+// NewInvoiceShareService signs share tokens with a key derived from secret,
+// so changing the secret invalidates every link handed out. The derived key
+// is only used for share links, so a share token can never pass for an auth
+// token made from the same secret, or the other way round.
+func NewInvoiceShareService(store *store.Store, storage pkgstorage.Provider, eventBus EventPublisher, secret string, logger zerolog.Logger) *InvoiceShareService {
+	return &InvoiceShareService{
+		store:      store,
+		invoices:   NewInvoiceService(store, storage, eventBus, logger),
+		eventBus:   eventBus,
+		signingKey: shareSigningKey(secret),
+		logger:     logger.With().Str("component", "invoice_share_service").Logger(),
+	}
+}
+
+// shareSigningKey derives the share link key from secret with HKDF-SHA256.
+func shareSigningKey(secret string) []byte {
+	key, err := hkdf.Key(sha256.New, []byte(secret), nil, shareKeyLabel, sha256.Size)
+	if err != nil {
+		// Only keys longer than 255 hashes cannot be derived.
+		panic(err)
+	}
+	return key
+}
+
+var _ InvoiceShare = (*InvoiceShareService)(nil)
+
+// CreateShareLink creates a link to a sent invoice for its client, valid for
+// 30 days unless expires_at says otherwise.
+func (s *InvoiceShareService) CreateShareLink(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID, input CreateInvoiceShareLinkInput) (*InvoiceShareLink, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.invoices.getInvoice(ctx, workspaceID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status == "draft" || invoice.Status == "cancelled" {
+		return nil, ErrInvoiceNotShareable
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(defaultShareLinkExpiry)
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(now) || input.ExpiresAt.Sub(now) > maxShareLinkExpiry {
+			return nil, ErrInvalidShareLinkExpiry
+		}
+		expiresAt = *input.ExpiresAt
+	}
+
+	link, err := s.store.InvoiceShares.CreateInvoiceShareLink(ctx, store.CreateInvoiceShareLinkParams{
+		WorkspaceID: workspaceID,
+		InvoiceID:   invoiceID,
+		// The token carries the expiry in whole seconds.
+		ExpiresAt: expiresAt.Truncate(time.Second),
+		CreatedBy: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &InvoiceShareLink{InvoiceShareLink: *link, Token: s.signShareToken(link.ID, link.ExpiresAt)}, nil
+}
+
+func (s *InvoiceShareService) ListShareLinks(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID) ([]InvoiceShareLink, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.invoices.getInvoice(ctx, workspaceID, invoiceID); err != nil {
+		return nil, err
+	}
+
+	links, err := s.store.InvoiceShares.ListInvoiceShareLinks(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := make([]InvoiceShareLink, 0, len(links))
+	for _, link := range links {
+		shared = append(shared, InvoiceShareLink{InvoiceShareLink: link, Token: s.signShareToken(link.ID, link.ExpiresAt)})
+	}
+	return shared, nil
+}
+
+func (s *InvoiceShareService) RevokeShareLink(ctx context.Context, userID, workspaceID, invoiceID, linkID uuid.UUID) error {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+	if _, err := s.invoices.getInvoice(ctx, workspaceID, invoiceID); err != nil {
+		return err
+	}
+
+	if err := s.store.InvoiceShares.RevokeInvoiceShareLink(ctx, invoiceID, linkID); err != nil {
+		if errors.Is(err, store.ErrInvoiceShareLinkNotFound) {
+			return ErrShareLinkNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ViewSharedInvoice opens an invoice through a share link. The first time a
+// client opens a sent invoice it becomes viewed.
+func (s *InvoiceShareService) ViewSharedInvoice(ctx context.Context, token string) (*SharedInvoice, error) {
+	invoice, err := s.openShareLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, invoice.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := s.store.Workspaces.GetWorkspaceByID(ctx, invoice.WorkspaceID)
+	if err != nil {
+		if errors.Is(err, store.ErrWorkspaceNotFound) {
+			return nil, ErrShareLinkInvalid
+		}
+		return nil, err
+	}
+	items, err := s.store.Invoices.ListInvoiceItems(ctx, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sharedInvoiceView(token, invoice, items, workspace.Name, settings.Settings), nil
+}
+
+// GetSharedInvoicePDF opens an invoice through a share link and returns a
+// short-lived link to its PDF.
+func (s *InvoiceShareService) GetSharedInvoicePDF(ctx context.Context, token string) (*InvoicePDF, error) {
+	invoice, err := s.openShareLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The client opening the link cannot do anything about the workspace's
+	// storage limit, so the PDF is never refused over it.
+	objectName, renderedAt, err := s.invoices.renderInvoicePDF(ctx, invoice, false)
+	if err != nil {
+		return nil, err
+	}
+	url, err := s.invoices.storage.GetPresignedURL(ctx, objectName, invoicePDFURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InvoicePDF{
+		URL:        url,
+		ExpiresAt:  time.Now().Add(invoicePDFURLExpiry),
+		RenderedAt: renderedAt,
+	}, nil
+}
+
+// openShareLink checks a token, counts the view and returns the shared
+// invoice, moving a sent invoice to viewed. Bad, expired and revoked tokens
+// are all reported as ErrShareLinkInvalid so a token reveals nothing about
+// the invoice behind it.
+func (s *InvoiceShareService) openShareLink(ctx context.Context, token string) (*store.Invoice, error) {
+	linkID, ok := s.verifyShareToken(token)
+	if !ok {
+		return nil, ErrShareLinkInvalid
+	}
+
+	var invoice *store.Invoice
+	viewed := false
+	err := s.store.ExecTx(ctx, func(tx *store.Store) error {
+		link, err := tx.InvoiceShares.RecordInvoiceShareLinkView(ctx, linkID)
+		if err != nil {
+			return err
+		}
+		invoice, err = tx.Invoices.LockInvoice(ctx, link.WorkspaceID, link.InvoiceID)
+		if err != nil {
+			return err
+		}
+		if invoice.Status != "sent" {
+			return nil
+		}
+
+		settings, err := getWorkspaceSettings(ctx, tx, invoice.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		invoice, viewed, err = transitionInvoice(ctx, tx, invoice, "viewed", settings.Settings)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrInvoiceShareLinkInvalid) || errors.Is(err, store.ErrInvoiceNotFound) {
+			return nil, ErrShareLinkInvalid
+		}
+		return nil, err
+	}
+
+	if viewed {
+		var userID uuid.UUID
+		if invoice.CreatedBy != nil {
+			userID = *invoice.CreatedBy
+		}
+		publishInvoiceStatus(ctx, s.eventBus, userID, invoice)
+	}
+	return invoice, nil
+}
+
+// signShareToken encodes the link id and expiry and signs them, so tokens
+// that were tampered with or have expired are turned away before the
+// database is asked about them.
+func (s *InvoiceShareService) signShareToken(linkID uuid.UUID, expiresAt time.Time) string {
+	payload := make([]byte, 24)
+	copy(payload, linkID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expiresAt.Unix()))
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(s.shareTokenMAC(payload))
+}
+
+func (s *InvoiceShareService) verifyShareToken(token string) (uuid.UUID, bool) {
+	encodedPayload, encodedMAC, found := strings.Cut(token, ".")
+	if !found {
+		return uuid.Nil, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, s.shareTokenMAC(payload)) {
+		return uuid.Nil, false
+	}
+
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	if !time.Now().Before(expiresAt) {
+		return uuid.Nil, false
+	}
+
+	linkID, err := uuid.FromBytes(payload[:16])
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return linkID, true
+}
+
+func (s *InvoiceShareService) shareTokenMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte("invoice-share:"))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func sharedInvoiceView(token string, invoice *store.Invoice, items []store.InvoiceItem, workspaceName string, settings store.SettingsDocument) *SharedInvoice {
+	numbers := money.FormatFor(settings.Locale)
+	amount := func(v int64) string {
+		return numbers.Amount(v, invoice.Currency)
+	}
+	date := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return formatWorkspaceDate(*t, settings.DateFormat)
+	}
+
+	view := &SharedInvoice{
+		Token:         token,
+		WorkspaceName: workspaceName,
+		LogoURL:       settings.LogoURL,
+		BrandColor:    settings.BrandColor,
+		Status:        invoice.Status,
+		ClientName:    invoice.ClientName,
+		ProjectName:   invoice.ProjectName,
+		IssueDate:     date(invoice.IssueDate),
+		DueDate:       date(invoice.DueDate),
+		Items:         make([]SharedInvoiceItem, 0, len(items)),
+		Subtotal:      amount(invoice.Subtotal),
+		Tax:           amount(invoice.TaxTotal),
+		Total:         amount(invoice.Total),
+		BalanceDue:    amount(invoice.BalanceDue),
+		Notes:         invoice.Notes,
+		Terms:         invoice.Terms,
+	}
+	if invoice.Number != nil {
+		view.Number = *invoice.Number
+	}
+	if invoice.DiscountTotal != 0 {
+		view.Discount = amount(-invoice.DiscountTotal)
+	}
+	if invoice.AmountPaid != 0 {
+		view.AmountPaid = amount(-invoice.AmountPaid)
+	}
+	for _, item := range items {
+		view.Items = append(view.Items, SharedInvoiceItem{
+			Description: item.Description,
+			Quantity:    numbers.Number(item.Quantity),
+			UnitPrice:   amount(item.UnitPrice),
+			Amount:      amount(item.Amount),
+		})
+	}
+	return view
+}
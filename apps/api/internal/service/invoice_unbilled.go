@@ -0,0 +1,332 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+var (
+	ErrInvoiceScopeRequired    = errors.New("client_id or project_id is required")
+	ErrInvoiceClientRequired   = errors.New("the project has no client; pass client_id")
+	ErrInvalidBillingPeriod    = errors.New("to must not be before from")
+	ErrNothingToInvoice        = errors.New("there is no unbilled billable time or expenses in this period")
+	ErrMissingHourlyRate       = errors.New("no hourly rate is set on the project or the workspace; pass hourly_rate")
+	ErrInvoiceCurrencyMismatch = errors.New("the hourly rate is in a different currency than the invoice; pass hourly_rate")
+	ErrUnbilledChanged         = errors.New("some of the time or expenses were billed or changed concurrently; try again")
+)
+
+// CreateInvoiceFromUnbilledInput selects the unbilled time and expenses of a
+// client or a single project. From and To are inclusive dates in the
+// workspace timezone. Expenses are included unless include_expenses is false.
+type CreateInvoiceFromUnbilledInput struct {
+	ClientID        *uuid.UUID     `json:"client_id,omitempty"`
+	ProjectID       *uuid.UUID     `json:"project_id,omitempty"`
+	From            string         `json:"from" validate:"required,datetime=2006-01-02"`
+	To              string         `json:"to" validate:"required,datetime=2006-01-02"`
+	GroupBy         string         `json:"group_by,omitempty" validate:"omitempty,oneof=task person day"`
+	HourlyRate      *int64         `json:"hourly_rate,omitempty" validate:"omitempty,min=0"`
+	ApprovedOnly    bool           `json:"approved_only"`
+	IncludeExpenses *bool          `json:"include_expenses,omitempty"`
+	DueDate         *string        `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	TaxRate         *money.Decimal `json:"tax_rate,omitempty" validate:"omitempty,percent" swaggertype:"number"`
+	Notes           *string        `json:"notes,omitempty" validate:"omitempty,max=5000"`
+}
+
+// unbilledLine accumulates the time of one invoice line. Time without a
+// task, or of a person whose name is unknown, has an empty label and is
+// described by the project name.
+type unbilledLine struct {
+	project store.Project
+	label   string
+	seconds int64
+}
+
+// CreateInvoiceFromUnbilled drafts an invoice from unbilled billable time and
+// expenses and marks them billed in the same transaction, so the same work can
+// never land on two invoices. Lines group the time by task, person or day
+// within each project and are charged at the project's hourly rate. Every
+// approved billable expense in the invoice currency follows as a line of its
+// own; expenses in other currencies stay unbilled.
+func (s *InvoiceService) CreateInvoiceFromUnbilled(ctx context.Context, userID, workspaceID uuid.UUID, input CreateInvoiceFromUnbilledInput) (*store.Invoice, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if input.ClientID == nil && input.ProjectID == nil {
+		return nil, ErrInvoiceScopeRequired
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := input.ClientID
+	if clientID == nil {
+		project, err := s.store.Projects.GetProject(ctx, workspaceID, *input.ProjectID)
+		if err != nil {
+			if errors.Is(err, store.ErrProjectNotFound) {
+				return nil, ErrProjectNotFound
+			}
+			return nil, err
+		}
+		if project.ClientID == nil {
+			return nil, ErrInvoiceClientRequired
+		}
+		clientID = project.ClientID
+	}
+
+	client, err := s.checkInvoiceReferences(ctx, workspaceID, *clientID, input.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := workspaceLocation(settings.Settings.Timezone)
+	from, _ := time.ParseInLocation(dateLayout, input.From, loc)
+	to, _ := time.ParseInLocation(dateLayout, input.To, loc)
+	if to.Before(from) {
+		return nil, ErrInvalidBillingPeriod
+	}
+
+	groupBy := input.GroupBy
+	if groupBy == "" {
+		groupBy = "task"
+	}
+
+	params := store.CreateInvoiceParams{
+		WorkspaceID: workspaceID,
+		ClientID:    client.ID,
+		ProjectID:   input.ProjectID,
+		Currency:    client.Currency,
+		Notes:       trimDescription(input.Notes),
+		CreatedBy:   &userID,
+	}
+	if input.TaxRate != nil {
+		params.TaxRate = *input.TaxRate
+	}
+	if params.DueDate, err = parseDate(input.DueDate); err != nil {
+		return nil, err
+	}
+
+	filter := store.UnbilledTimeEntryFilter{
+		From:         from,
+		To:           to.AddDate(0, 0, 1),
+		ApprovedOnly: input.ApprovedOnly,
+	}
+	expenseFilter := store.UnbilledExpenseFilter{
+		Currency: client.Currency,
+	}
+	expenseFilter.From, _ = time.Parse(dateLayout, input.From)
+	expenseFilter.To, _ = time.Parse(dateLayout, input.To)
+	if input.ProjectID != nil {
+		filter.ProjectID = input.ProjectID
+		expenseFilter.ProjectID = input.ProjectID
+	} else {
+		filter.ClientID = &client.ID
+		expenseFilter.ClientID = &client.ID
+	}
+	includeExpenses := input.IncludeExpenses == nil || *input.IncludeExpenses
+
+	var invoice *store.Invoice
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		entries, err := tx.TimeEntries.ListUnbilledTimeEntries(ctx, workspaceID, filter)
+		if err != nil {
+			return err
+		}
+		expenses := []store.Expense{}
+		if includeExpenses {
+			if expenses, err = tx.Expenses.ListUnbilledExpenses(ctx, workspaceID, expenseFilter); err != nil {
+				return err
+			}
+		}
+		if len(entries) == 0 && len(expenses) == 0 {
+			return ErrNothingToInvoice
+		}
+
+		lines, err := groupUnbilledTime(ctx, tx, entries, groupBy, loc)
+		if err != nil {
+			return err
+		}
+
+		multiProject := slices.ContainsFunc(lines, func(line *unbilledLine) bool {
+			return line.project.ID != lines[0].project.ID
+		})
+
+		inputs := make([]InvoiceItemInput, 0, len(lines)+len(expenses))
+		for _, line := range lines {
+			rate, err := billingRate(line.project, input.HourlyRate, client.Currency, settings.Settings)
+			if err != nil {
+				return err
+			}
+
+			description := line.project.Name
+			switch {
+			case line.label != "" && multiProject:
+				description += " — " + line.label
+			case line.label != "":
+				description = line.label
+			}
+			hours, err := money.Ratio(line.seconds, 3600)
+			if err != nil {
+				return ErrInvoiceLineTooLarge
+			}
+			inputs = append(inputs, InvoiceItemInput{
+				Description: description,
+				Quantity:    hours,
+				UnitPrice:   rate,
+			})
+		}
+		for _, expense := range expenses {
+			inputs = append(inputs, InvoiceItemInput{
+				Description: expenseLineDescription(expense),
+				Quantity:    money.NewDecimal(1),
+				UnitPrice:   expense.Amount,
+			})
+		}
+
+		items, totals, err := priceInvoice(params.DiscountPercent, params.TaxRate, inputs)
+		if err != nil {
+			return err
+		}
+		params.Totals = totals
+
+		if invoice, err = tx.Invoices.CreateInvoice(ctx, params); err != nil {
+			return err
+		}
+		if err := tx.Invoices.ReplaceInvoiceItems(ctx, invoice.ID, items); err != nil {
+			return err
+		}
+
+		entryIDs := make([]uuid.UUID, len(entries))
+		for i, entry := range entries {
+			entryIDs[i] = entry.ID
+		}
+		marked, err := tx.TimeEntries.MarkTimeEntriesBilled(ctx, invoice.ID, entryIDs)
+		if err != nil {
+			return err
+		}
+		if marked != int64(len(entryIDs)) {
+			return ErrUnbilledChanged
+		}
+
+		expenseIDs := make([]uuid.UUID, len(expenses))
+		for i, expense := range expenses {
+			expenseIDs[i] = expense.ID
+		}
+		marked, err = tx.Expenses.MarkExpensesBilled(ctx, invoice.ID, expenseIDs)
+		if err != nil {
+			return err
+		}
+		if marked != int64(len(expenseIDs)) {
+			return ErrUnbilledChanged
+		}
+
+		invoice.Items, err = tx.Invoices.ListInvoiceItems(ctx, invoice.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// groupUnbilledTime groups entries into invoice lines, ordered by project and
+// then by task title, person or day.
+func groupUnbilledTime(ctx context.Context, tx *store.Store, entries []store.TimeEntry, groupBy string, loc *time.Location) ([]*unbilledLine, error) {
+	type lineKey struct {
+		projectID uuid.UUID
+		group     string
+	}
+
+	projects := map[uuid.UUID]store.Project{}
+	byKey := map[lineKey]*unbilledLine{}
+	var lines []*unbilledLine
+
+	for _, entry := range entries {
+		project, ok := projects[entry.ProjectID]
+		if !ok {
+			p, err := tx.Projects.GetProject(ctx, entry.WorkspaceID, entry.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			project = *p
+			projects[project.ID] = project
+		}
+
+		var group, label string
+		switch groupBy {
+		case "person":
+			group = entry.UserID.String()
+			if entry.UserName != nil {
+				label = *entry.UserName
+			}
+		case "day":
+			label = entry.StartedAt.In(loc).Format(dateLayout)
+			group = label
+		default:
+			if entry.TaskID != nil {
+				group = entry.TaskID.String()
+				if entry.TaskTitle != nil {
+					label = *entry.TaskTitle
+				}
+			}
+		}
+
+		key := lineKey{projectID: project.ID, group: group}
+		line, ok := byKey[key]
+		if !ok {
+			line = &unbilledLine{project: project, label: label}
+			byKey[key] = line
+			lines = append(lines, line)
+		}
+		line.seconds += entry.DurationSeconds
+	}
+
+	slices.SortStableFunc(lines, func(a, b *unbilledLine) int {
+		return cmp.Or(
+			strings.Compare(a.project.Name, b.project.Name),
+			cmp.Compare(a.project.ID.String(), b.project.ID.String()),
+			strings.Compare(a.label, b.label),
+		)
+	})
+	return lines, nil
+}
+
+// expenseLineDescription describes a billed expense by its merchant, what it
+// was for and the day it was spent.
+func expenseLineDescription(expense store.Expense) string {
+	description := expense.Merchant
+	if expense.Description != nil {
+		description += " — " + *expense.Description
+	}
+	return description + " (" + expense.SpentOn.Format(dateLayout) + ")"
+}
+
+// billingRate picks the hourly rate for a project's time: the rate passed in,
+// the project's own rate, or the workspace default. A stored rate has to be in
+// the invoice currency to be used.
+func billingRate(project store.Project, override *int64, currency string, settings store.SettingsDocument) (int64, error) {
+	if override != nil {
+		return *override, nil
+	}
+
+	rate, rateCurrency := settings.DefaultHourlyRate, settings.DefaultCurrency
+	if project.HourlyRate != nil {
+		rate, rateCurrency = *project.HourlyRate, project.Currency
+	}
+	if rate == 0 {
+		return 0, ErrMissingHourlyRate
+	}
+	if rateCurrency != currency {
+		return 0, ErrInvoiceCurrencyMismatch
+	}
+	return rate, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+)
+
+var (
+	codeBlockPattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+	// A mention is an @ that does not follow a word character, so plain email
+	// addresses in the text are not mistaken for mentions.
+	mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9][A-Za-z0-9._%+-]*(?:@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)+)?)`)
+)
+
+// parseMentionHandles returns the lowercase handles mentioned in a markdown
+// body, in order of first appearance. Mentions inside code are ignored.
+func parseMentionHandles(body string) []string {
+	body = codeBlockPattern.ReplaceAllString(body, " ")
+
+	var handles []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(strings.TrimRight(match[1], "._-"))
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		handles = append(handles, handle)
+	}
+	return handles
+}
+
+// resolveMentions maps the mentions in body to active workspace members. A
+// handle matches a member's full email address, or the part before the @ when
+// exactly one member has that local part. Unknown handles are ignored.
+func resolveMentions(ctx context.Context, st *store.Store, workspaceID uuid.UUID, body string) (store.UUIDs, error) {
+	handles := parseMentionHandles(body)
+	if len(handles) == 0 {
+		return store.UUIDs{}, nil
+	}
+
+	members, err := st.Workspaces.ListMembersByEmailHandle(ctx, workspaceID, handles)
+	if err != nil {
+		return nil, err
+	}
+
+	byEmail := make(map[string]uuid.UUID, len(members))
+	byLocalPart := make(map[string][]uuid.UUID, len(members))
+	for _, member := range members {
+		email := strings.ToLower(member.Email)
+		byEmail[email] = member.UserID
+		localPart, _, _ := strings.Cut(email, "@")
+		byLocalPart[localPart] = append(byLocalPart[localPart], member.UserID)
+	}
+
+	mentions := store.UUIDs{}
+	seen := make(map[uuid.UUID]bool)
+	for _, handle := range handles {
+		userID, ok := byEmail[handle]
+		if !ok {
+			if matches := byLocalPart[handle]; len(matches) == 1 {
+				userID, ok = matches[0], true
+			}
+		}
+		if ok && !seen[userID] {
+			seen[userID] = true
+			mentions = append(mentions, userID)
+		}
+	}
+	return mentions, nil
+}
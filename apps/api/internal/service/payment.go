@@ -0,0 +1,498 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrPaymentNotFound         = errors.New("payment not found")
+	ErrInvoiceNotPayable       = errors.New("only sent, viewed or overdue invoices take payments")
+	ErrInvoiceSettled          = errors.New("the invoice has no balance due")
+	ErrPaymentCurrencyMismatch = errors.New("payments must be in the invoice currency")
+	ErrNoClientCredit          = errors.New("the client has no credit in the invoice currency")
+	ErrCreditExceedsAvailable  = errors.New("the amount is more than the client's credit or the balance due")
+	ErrRefundExceedsPayment    = errors.New("the refund is more than what is left of the payment")
+	ErrRefundOfRefund          = errors.New("a refund cannot be refunded")
+)
+
+// payableStatuses are the invoice statuses that take payments.
+var payableStatuses = []string{"sent", "viewed", "overdue"}
+
+// RecordPaymentInput is money received for an invoice. Amount is in minor
+// units of the invoice currency; whatever exceeds the balance due becomes
+// client credit.
+type RecordPaymentInput struct {
+	Amount    int64   `json:"amount" validate:"gt=0"`
+	Currency  *string `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	Method    string  `json:"method" validate:"required,oneof=bank_transfer card cash check other"`
+	Reference *string `json:"reference,omitempty" validate:"omitempty,max=255"`
+	PaidOn    *string `json:"paid_on,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Notes     *string `json:"notes,omitempty" validate:"omitempty,max=5000"`
+}
+
+// ApplyClientCreditInput spends client credit on an invoice. Without an
+// amount, as much as covers the balance due is applied.
+type ApplyClientCreditInput struct {
+	Amount *int64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+}
+
+// RefundPaymentInput returns part or all of a payment. Without a method the
+// refund goes back the way the payment came.
+type RefundPaymentInput struct {
+	Amount    int64   `json:"amount" validate:"gt=0"`
+	Method    *string `json:"method,omitempty" validate:"omitempty,oneof=bank_transfer card cash check other"`
+	Reference *string `json:"reference,omitempty" validate:"omitempty,max=255"`
+	PaidOn    *string `json:"paid_on,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Notes     *string `json:"notes,omitempty" validate:"omitempty,max=5000"`
+}
+
+// ClientCreditSummary is what a client holds in credit, per currency, and
+// the ledger behind it.
+type ClientCreditSummary struct {
+	Balances []store.CreditBalance `json:"balances"`
+	Entries  []store.ClientCredit  `json:"entries"`
+}
+
+type Payment interface {
+	RecordPayment(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID, input RecordPaymentInput) (*store.Payment, error)
+	ApplyClientCredit(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID, input ApplyClientCreditInput) (*store.Payment, error)
+	RefundPayment(ctx context.Context, userID, workspaceID, invoiceID, paymentID uuid.UUID, input RefundPaymentInput) (*store.Payment, error)
+	ListInvoicePayments(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID) ([]store.Payment, error)
+	GetClientCredit(ctx context.Context, userID, workspaceID, clientID uuid.UUID) (*ClientCreditSummary, error)
+}
+
+type PaymentService struct {
+	store    *store.Store
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewPaymentService(store *store.Store, eventBus EventPublisher, logger zerolog.Logger) *PaymentService {
+	return &PaymentService{
+		store:    store,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "payment_service").Logger(),
+	}
+}
+
+var _ Payment = (*PaymentService)(nil)
+
+// RecordPayment books money received for an invoice. The part of the amount
+// that exceeds the balance due is kept as client credit, and an invoice whose
+// balance reaches zero becomes paid.
+func (s *PaymentService) RecordPayment(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID, input RecordPaymentInput) (*store.Payment, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	paidOn, err := paymentDate(input.PaidOn, settings.Settings.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var payment *store.Payment
+	var invoice *store.Invoice
+	var settled bool
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		current, err := tx.Invoices.LockInvoice(ctx, workspaceID, invoiceID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(payableStatuses, current.Status) {
+			return ErrInvoiceNotPayable
+		}
+		if input.Currency != nil && !strings.EqualFold(*input.Currency, current.Currency) {
+			return ErrPaymentCurrencyMismatch
+		}
+
+		applied := min(input.Amount, max(current.BalanceDue, 0))
+		payment, err = tx.Payments.CreatePayment(ctx, store.CreatePaymentParams{
+			WorkspaceID:   workspaceID,
+			ClientID:      current.ClientID,
+			InvoiceID:     current.ID,
+			Kind:          "payment",
+			Method:        input.Method,
+			Amount:        input.Amount,
+			AppliedAmount: applied,
+			Currency:      current.Currency,
+			Reference:     trimDescription(input.Reference),
+			PaidOn:        paidOn,
+			Notes:         trimDescription(input.Notes),
+			CreatedBy:     userID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if excess := input.Amount - applied; excess > 0 {
+			err := tx.Payments.CreateClientCredit(ctx, store.CreateClientCreditParams{
+				WorkspaceID: workspaceID,
+				ClientID:    current.ClientID,
+				Currency:    current.Currency,
+				Amount:      excess,
+				PaymentID:   &payment.ID,
+				InvoiceID:   &current.ID,
+				Description: "Overpayment of " + invoiceLabel(current),
+				CreatedBy:   userID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		invoice, settled, err = settleInvoice(ctx, tx, current, settings.Settings)
+		return err
+	})
+	if err != nil {
+		return nil, mapPaymentError(err)
+	}
+
+	s.publishPayment(ctx, events.EventPaymentRecorded, userID, payment, invoice, settled)
+	return payment, nil
+}
+
+// ApplyClientCredit pays an invoice out of the client's credit in the
+// invoice currency.
+func (s *PaymentService) ApplyClientCredit(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID, input ApplyClientCreditInput) (*store.Payment, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var payment *store.Payment
+	var invoice *store.Invoice
+	var settled bool
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		current, err := tx.Invoices.LockInvoice(ctx, workspaceID, invoiceID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(payableStatuses, current.Status) {
+			return ErrInvoiceNotPayable
+		}
+		if current.BalanceDue <= 0 {
+			return ErrInvoiceSettled
+		}
+
+		if err := tx.Payments.LockClientCredit(ctx, workspaceID, current.ClientID); err != nil {
+			return err
+		}
+		available, err := tx.Payments.GetClientCreditBalance(ctx, current.ClientID, current.Currency)
+		if err != nil {
+			return err
+		}
+		if available <= 0 {
+			return ErrNoClientCredit
+		}
+
+		amount := min(available, current.BalanceDue)
+		if input.Amount != nil {
+			if *input.Amount > amount {
+				return ErrCreditExceedsAvailable
+			}
+			amount = *input.Amount
+		}
+
+		payment, err = tx.Payments.CreatePayment(ctx, store.CreatePaymentParams{
+			WorkspaceID:   workspaceID,
+			ClientID:      current.ClientID,
+			InvoiceID:     current.ID,
+			Kind:          "payment",
+			Method:        "credit",
+			Amount:        amount,
+			AppliedAmount: amount,
+			Currency:      current.Currency,
+			PaidOn:        workspaceToday(settings.Settings.Timezone),
+			CreatedBy:     userID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = tx.Payments.CreateClientCredit(ctx, store.CreateClientCreditParams{
+			WorkspaceID: workspaceID,
+			ClientID:    current.ClientID,
+			Currency:    current.Currency,
+			Amount:      -amount,
+			PaymentID:   &payment.ID,
+			InvoiceID:   &current.ID,
+			Description: "Applied to " + invoiceLabel(current),
+			CreatedBy:   userID,
+		})
+		if err != nil {
+			return err
+		}
+
+		invoice, settled, err = settleInvoice(ctx, tx, current, settings.Settings)
+		return err
+	})
+	if err != nil {
+		return nil, mapPaymentError(err)
+	}
+
+	s.publishPayment(ctx, events.EventPaymentRecorded, userID, payment, invoice, settled)
+	return payment, nil
+}
+
+// RefundPayment returns money from a payment. A refund first gives back what
+// the payment left as client credit, as far as that credit is unspent, and
+// takes the rest off the invoice, which reopens a paid invoice. Refunding a
+// credit payment puts the credit back.
+func (s *PaymentService) RefundPayment(ctx context.Context, userID, workspaceID, invoiceID, paymentID uuid.UUID, input RefundPaymentInput) (*store.Payment, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	paidOn, err := paymentDate(input.PaidOn, settings.Settings.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var refund *store.Payment
+	var invoice *store.Invoice
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		current, err := tx.Invoices.LockInvoice(ctx, workspaceID, invoiceID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Payments.LockClientCredit(ctx, workspaceID, current.ClientID); err != nil {
+			return err
+		}
+
+		payment, err := tx.Payments.GetPayment(ctx, workspaceID, paymentID)
+		if err != nil {
+			return err
+		}
+		if payment.InvoiceID != current.ID {
+			return ErrPaymentNotFound
+		}
+		if payment.Kind == "refund" {
+			return ErrRefundOfRefund
+		}
+		if input.Amount > payment.Amount-payment.RefundedAmount {
+			return ErrRefundExceedsPayment
+		}
+
+		// A credit payment is refunded to credit. Otherwise the refund comes
+		// out of the overpayment the payment left as credit, while the client
+		// still holds it, and then off the invoice.
+		var fromCredit int64
+		method := payment.Method
+		if payment.Method != "credit" {
+			available, err := tx.Payments.GetClientCreditBalance(ctx, current.ClientID, current.Currency)
+			if err != nil {
+				return err
+			}
+			unrefundedCredit := (payment.Amount - payment.AppliedAmount) - (payment.RefundedAmount - payment.RefundedApplied)
+			fromCredit = max(min(input.Amount, unrefundedCredit, available), 0)
+			if input.Method != nil {
+				method = *input.Method
+			}
+		}
+		fromInvoice := input.Amount - fromCredit
+		if fromInvoice > payment.AppliedAmount-payment.RefundedApplied {
+			return ErrRefundExceedsPayment
+		}
+
+		refund, err = tx.Payments.CreatePayment(ctx, store.CreatePaymentParams{
+			WorkspaceID:   workspaceID,
+			ClientID:      current.ClientID,
+			InvoiceID:     current.ID,
+			Kind:          "refund",
+			RefundOf:      &payment.ID,
+			Method:        method,
+			Amount:        input.Amount,
+			AppliedAmount: fromInvoice,
+			Currency:      current.Currency,
+			Reference:     trimDescription(input.Reference),
+			PaidOn:        paidOn,
+			Notes:         trimDescription(input.Notes),
+			CreatedBy:     userID,
+		})
+		if err != nil {
+			return err
+		}
+
+		credit := -fromCredit
+		description := "Refund of overpayment on " + invoiceLabel(current)
+		if payment.Method == "credit" {
+			credit = input.Amount
+			description = "Credit returned from " + invoiceLabel(current)
+		}
+		if credit != 0 {
+			err := tx.Payments.CreateClientCredit(ctx, store.CreateClientCreditParams{
+				WorkspaceID: workspaceID,
+				ClientID:    current.ClientID,
+				Currency:    current.Currency,
+				Amount:      credit,
+				PaymentID:   &refund.ID,
+				InvoiceID:   &current.ID,
+				Description: description,
+				CreatedBy:   userID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		invoice, _, err = settleInvoice(ctx, tx, current, settings.Settings)
+		return err
+	})
+	if err != nil {
+		return nil, mapPaymentError(err)
+	}
+
+	s.publishPayment(ctx, events.EventPaymentRefunded, userID, refund, invoice, false)
+	return refund, nil
+}
+
+// ListInvoicePayments returns the payments and refunds of an invoice.
+func (s *PaymentService) ListInvoicePayments(ctx context.Context, userID, workspaceID, invoiceID uuid.UUID) ([]store.Payment, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.Invoices.GetInvoice(ctx, workspaceID, invoiceID); err != nil {
+		return nil, mapPaymentError(err)
+	}
+	return s.store.Payments.ListInvoicePayments(ctx, invoiceID)
+}
+
+// GetClientCredit returns the client's credit balances and ledger.
+func (s *PaymentService) GetClientCredit(ctx context.Context, userID, workspaceID, clientID uuid.UUID) (*ClientCreditSummary, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.Clients.GetClient(ctx, workspaceID, clientID); err != nil {
+		return nil, mapPaymentError(err)
+	}
+
+	balances, err := s.store.Payments.ListClientCreditBalances(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.store.Payments.ListClientCredits(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientCreditSummary{Balances: balances, Entries: entries}, nil
+}
+
+// settleInvoice brings the amount paid on a locked invoice in line with its
+// payments. An invoice with nothing left to pay becomes paid, and a paid
+// invoice that a refund left a balance on goes back to overdue, viewed or
+// sent. It reports whether the invoice became paid.
+func settleInvoice(ctx context.Context, tx *store.Store, invoice *store.Invoice, settings store.SettingsDocument) (*store.Invoice, bool, error) {
+	if err := tx.Payments.SyncInvoiceAmountPaid(ctx, invoice.ID); err != nil {
+		return nil, false, err
+	}
+	updated, err := tx.Invoices.GetInvoice(ctx, invoice.WorkspaceID, invoice.ID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch {
+	case updated.BalanceDue <= 0 && slices.Contains(payableStatuses, updated.Status):
+		return transitionInvoice(ctx, tx, updated, "paid", settings)
+	case updated.BalanceDue > 0 && updated.Status == "paid":
+		status, at := "sent", time.Now()
+		switch {
+		case updated.DueDate != nil && updated.DueDate.Before(workspaceToday(settings.Timezone)):
+			status = "overdue"
+		case updated.ViewedAt != nil:
+			status, at = "viewed", *updated.ViewedAt
+		}
+		updated, err = tx.Invoices.SetInvoiceStatus(ctx, updated.WorkspaceID, updated.ID, status, at)
+		return updated, false, err
+	}
+	return updated, false, nil
+}
+
+// paymentDate parses the date money changed hands, today in the workspace
+// timezone when it is not given.
+func paymentDate(value *string, timezone string) (time.Time, error) {
+	date, err := parseDate(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date == nil {
+		return workspaceToday(timezone), nil
+	}
+	return *date, nil
+}
+
+func invoiceLabel(invoice *store.Invoice) string {
+	if invoice.Number != nil {
+		return "invoice " + *invoice.Number
+	}
+	return fmt.Sprintf("invoice %s", invoice.ID)
+}
+
+func mapPaymentError(err error) error {
+	switch {
+	case errors.Is(err, store.ErrInvoiceNotFound):
+		return ErrInvoiceNotFound
+	case errors.Is(err, store.ErrPaymentNotFound):
+		return ErrPaymentNotFound
+	case errors.Is(err, store.ErrClientNotFound):
+		return ErrClientNotFound
+	}
+	return err
+}
+
+// publishPayment announces a payment or refund with the balances it changed,
+// and the invoice becoming paid when it did.
+func (s *PaymentService) publishPayment(ctx context.Context, eventType events.EventType, userID uuid.UUID, payment *store.Payment, invoice *store.Invoice, settled bool) {
+	if s.eventBus == nil {
+		return
+	}
+
+	payload := map[string]any{
+		"workspace_id":   payment.WorkspaceID,
+		"invoice_id":     payment.InvoiceID,
+		"payment_id":     payment.ID,
+		"client_id":      payment.ClientID,
+		"number":         invoice.Number,
+		"method":         payment.Method,
+		"amount":         payment.Amount,
+		"applied_amount": payment.AppliedAmount,
+		"currency":       payment.Currency,
+		"invoice_status": invoice.Status,
+		"balance_due":    invoice.BalanceDue,
+	}
+	if client, err := s.store.Clients.GetClient(ctx, payment.WorkspaceID, payment.ClientID); err == nil {
+		payload["client_outstanding"] = client.Outstanding
+		payload["client_credit"] = client.Credit
+		payload["client_currency"] = client.Currency
+	} else {
+		s.logger.Warn().Err(err).Str("client_id", payment.ClientID.String()).Msg("failed to load client balances for payment event")
+	}
+	s.eventBus.Publish(ctx, eventType, userID, payload)
+
+	if settled {
+		publishInvoiceStatus(ctx, s.eventBus, userID, invoice)
+	}
+}
@@ -0,0 +1,513 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/money"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrProjectNotFound     = errors.New("project not found")
+	ErrInvalidProjectDates = errors.New("due_date must not be before start_date")
+	ErrInvalidFilter       = errors.New("invalid filter value")
+)
+
+const dateLayout = "2006-01-02"
+
+var projectStatuses = map[string]bool{
+	"planning":    true,
+	"in-progress": true,
+	"review":      true,
+	"completed":   true,
+	"on-hold":     true,
+}
+
+var priorityLevels = map[string]bool{
+	"low":    true,
+	"medium": true,
+	"high":   true,
+}
+
+type CreateProjectInput struct {
+	Name        string         `json:"name" validate:"required,min=2,max=255"`
+	Description *string        `json:"description,omitempty" validate:"omitempty,max=5000"`
+	Status      *string        `json:"status,omitempty" validate:"omitempty,oneof=planning in-progress review completed on-hold"`
+	Priority    *string        `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+	StartDate   *string        `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	DueDate     *string        `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Budget      *int64         `json:"budget,omitempty" validate:"omitempty,min=0"`
+	BudgetType  *string        `json:"budget_type,omitempty" validate:"omitempty,oneof=fixed_fee hourly"`
+	BudgetHours *money.Decimal `json:"budget_hours,omitempty" validate:"omitempty,min=0,max=999999990000" swaggertype:"number"`
+	HourlyRate  *int64         `json:"hourly_rate,omitempty" validate:"omitempty,min=0"`
+	Currency    *string        `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	Color       *string        `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Tags        []string       `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+	TeamID      *uuid.UUID     `json:"team_id,omitempty"`
+	OwnerID     *uuid.UUID     `json:"owner_id,omitempty"`
+	ClientID    *uuid.UUID     `json:"client_id,omitempty"`
+}
+
+// UpdateProjectInput only changes the fields that are set. Empty start_date,
+// due_date, team_id or client_id values clear them, and an empty tags list
+// removes all tags.
+type UpdateProjectInput struct {
+	Name        *string        `json:"name,omitempty" validate:"omitempty,min=2,max=255"`
+	Description *string        `json:"description,omitempty" validate:"omitempty,max=5000"`
+	Status      *string        `json:"status,omitempty" validate:"omitempty,oneof=planning in-progress review completed on-hold"`
+	Priority    *string        `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+	StartDate   *string        `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	DueDate     *string        `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Budget      *int64         `json:"budget,omitempty" validate:"omitempty,min=0"`
+	BudgetType  *string        `json:"budget_type,omitempty" validate:"omitempty,oneof=fixed_fee hourly"`
+	BudgetHours *money.Decimal `json:"budget_hours,omitempty" validate:"omitempty,min=0,max=999999990000" swaggertype:"number"`
+	HourlyRate  *int64         `json:"hourly_rate,omitempty" validate:"omitempty,min=0"`
+	Currency    *string        `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	Color       *string        `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	Tags        []string       `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+	TeamID      *string        `json:"team_id,omitempty" validate:"omitempty,uuid"`
+	OwnerID     *uuid.UUID     `json:"owner_id,omitempty"`
+	ClientID    *string        `json:"client_id,omitempty" validate:"omitempty,uuid"`
+}
+
+type Project interface {
+	CreateProject(ctx context.Context, userID, workspaceID uuid.UUID, input CreateProjectInput) (*store.Project, error)
+	GetProject(ctx context.Context, userID, workspaceID, projectID uuid.UUID) (*store.Project, error)
+	ListProjects(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Project], error)
+	UpdateProject(ctx context.Context, userID, workspaceID, projectID uuid.UUID, input UpdateProjectInput) (*store.Project, error)
+	DeleteProject(ctx context.Context, userID, workspaceID, projectID uuid.UUID) error
+}
+
+type ProjectService struct {
+	store    *store.Store
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewProjectService(store *store.Store, eventBus EventPublisher, logger zerolog.Logger) *ProjectService {
+	return &ProjectService{
+		store:    store,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "project_service").Logger(),
+	}
+}
+
+var _ Project = (*ProjectService)(nil)
+
+func (s *ProjectService) CreateProject(ctx context.Context, userID, workspaceID uuid.UUID, input CreateProjectInput) (*store.Project, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := store.CreateProjectParams{
+		WorkspaceID: workspaceID,
+		Name:        input.Name,
+		Description: input.Description,
+		Status:      "planning",
+		Priority:    "medium",
+		Budget:      input.Budget,
+		BudgetType:  "fixed_fee",
+		BudgetHours: input.BudgetHours,
+		HourlyRate:  input.HourlyRate,
+		Currency:    settings.Settings.DefaultCurrency,
+		Color:       settings.Settings.BrandColor,
+		Tags:        normalizeTags(input.Tags),
+		TeamID:      input.TeamID,
+		OwnerID:     &userID,
+		ClientID:    input.ClientID,
+		CreatedBy:   userID,
+	}
+	if input.Status != nil {
+		params.Status = *input.Status
+	}
+	if input.Priority != nil {
+		params.Priority = *input.Priority
+	}
+	if input.BudgetType != nil {
+		params.BudgetType = *input.BudgetType
+	}
+	if input.Currency != nil {
+		params.Currency = strings.ToUpper(*input.Currency)
+	}
+	if input.Color != nil {
+		params.Color = strings.ToLower(*input.Color)
+	}
+	if input.OwnerID != nil {
+		params.OwnerID = input.OwnerID
+	}
+	if params.StartDate, err = parseDate(input.StartDate); err != nil {
+		return nil, err
+	}
+	if params.DueDate, err = parseDate(input.DueDate); err != nil {
+		return nil, err
+	}
+	if err := validateDateRange(params.StartDate, params.DueDate); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkReferences(ctx, workspaceID, params.TeamID, params.OwnerID, params.ClientID); err != nil {
+		return nil, err
+	}
+
+	var project *store.Project
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := reserveQuota(ctx, tx, workspaceID, store.UsageProjects, 1); err != nil {
+			return err
+		}
+
+		var err error
+		project, err = tx.Projects.CreateProject(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventProjectCreated, userID, map[string]any{
+			"workspace_id": workspaceID,
+			"project_id":   project.ID,
+			"name":         project.Name,
+			"owner_id":     project.OwnerID,
+		})
+	}
+
+	return project, nil
+}
+
+func (s *ProjectService) GetProject(ctx context.Context, userID, workspaceID, projectID uuid.UUID) (*store.Project, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.getProject(ctx, workspaceID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	hideProjectSpent(role, project, userID)
+	return project, nil
+}
+
+func (s *ProjectService) ListProjects(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Project], error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := store.ProjectFilter{
+		Status:   filters.Filters["status"],
+		Priority: filters.Filters["priority"],
+		Tag:      filters.Filters["tag"],
+	}
+	if (filter.Status != "" && !projectStatuses[filter.Status]) || (filter.Priority != "" && !priorityLevels[filter.Priority]) {
+		return nil, ErrInvalidFilter
+	}
+
+	if filter.TeamID, err = parseUUIDFilter(filters.Filters["team_id"]); err != nil {
+		return nil, err
+	}
+	if filter.OwnerID, err = parseUUIDFilter(filters.Filters["owner_id"]); err != nil {
+		return nil, err
+	}
+	if filter.ClientID, err = parseUUIDFilter(filters.Filters["client_id"]); err != nil {
+		return nil, err
+	}
+
+	projects, total, err := s.store.Projects.ListProjects(ctx, workspaceID, filter, filters)
+	if err != nil {
+		return nil, err
+	}
+	for i := range projects {
+		hideProjectSpent(role, &projects[i], userID)
+	}
+
+	return store.BuildFilterResponse(projects, total, filters), nil
+}
+
+func (s *ProjectService) UpdateProject(ctx context.Context, userID, workspaceID, projectID uuid.UUID, input UpdateProjectInput) (*store.Project, error) {
+	project, err := s.getManagedProject(ctx, userID, workspaceID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := store.UpdateProjectParams{
+		ID:          project.ID,
+		WorkspaceID: workspaceID,
+		Name:        project.Name,
+		Description: project.Description,
+		Status:      project.Status,
+		Priority:    project.Priority,
+		StartDate:   project.StartDate,
+		DueDate:     project.DueDate,
+		Budget:      project.Budget,
+		BudgetType:  project.BudgetType,
+		BudgetHours: project.BudgetHours,
+		HourlyRate:  project.HourlyRate,
+		Currency:    project.Currency,
+		Color:       project.Color,
+		Tags:        project.Tags,
+		TeamID:      project.TeamID,
+		OwnerID:     project.OwnerID,
+		ClientID:    project.ClientID,
+	}
+	if input.Name != nil {
+		params.Name = *input.Name
+	}
+	if input.Description != nil {
+		params.Description = input.Description
+	}
+	if input.Status != nil {
+		params.Status = *input.Status
+	}
+	if input.Priority != nil {
+		params.Priority = *input.Priority
+	}
+	if input.StartDate != nil {
+		if params.StartDate, err = parseDate(input.StartDate); err != nil {
+			return nil, err
+		}
+	}
+	if input.DueDate != nil {
+		if params.DueDate, err = parseDate(input.DueDate); err != nil {
+			return nil, err
+		}
+	}
+	if input.Budget != nil {
+		params.Budget = input.Budget
+	}
+	if input.BudgetType != nil {
+		params.BudgetType = *input.BudgetType
+	}
+	if input.BudgetHours != nil {
+		params.BudgetHours = input.BudgetHours
+	}
+	if input.HourlyRate != nil {
+		params.HourlyRate = input.HourlyRate
+	}
+	if input.Currency != nil {
+		params.Currency = strings.ToUpper(*input.Currency)
+	}
+	if input.Color != nil {
+		params.Color = strings.ToLower(*input.Color)
+	}
+	if input.Tags != nil {
+		params.Tags = normalizeTags(input.Tags)
+	}
+	if input.TeamID != nil {
+		if params.TeamID, err = parseUUIDFilter(*input.TeamID); err != nil {
+			return nil, err
+		}
+	}
+	if input.OwnerID != nil {
+		params.OwnerID = input.OwnerID
+	}
+	if input.ClientID != nil {
+		if params.ClientID, err = parseUUIDFilter(*input.ClientID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateDateRange(params.StartDate, params.DueDate); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkReferences(ctx, workspaceID, params.TeamID, params.OwnerID, params.ClientID); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.store.Projects.UpdateProject(ctx, params)
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventProjectUpdated, userID, map[string]any{
+			"workspace_id": workspaceID,
+			"project_id":   updated.ID,
+			"name":         updated.Name,
+		})
+		if updated.Status != project.Status {
+			s.eventBus.Publish(ctx, events.EventProjectStatusChanged, userID, map[string]any{
+				"workspace_id": workspaceID,
+				"project_id":   updated.ID,
+				"name":         updated.Name,
+				"from":         project.Status,
+				"to":           updated.Status,
+				"owner_id":     updated.OwnerID,
+			})
+		}
+	}
+
+	return updated, nil
+}
+
+func (s *ProjectService) DeleteProject(ctx context.Context, userID, workspaceID, projectID uuid.UUID) error {
+	project, err := s.getManagedProject(ctx, userID, workspaceID, projectID)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := tx.Projects.DeleteProject(ctx, workspaceID, projectID); err != nil {
+			return err
+		}
+		return releaseQuota(ctx, tx, workspaceID, store.UsageProjects, 1)
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventProjectDeleted, userID, map[string]any{
+			"workspace_id": workspaceID,
+			"project_id":   projectID,
+			"name":         project.Name,
+		})
+	}
+
+	return nil
+}
+
+func (s *ProjectService) getProject(ctx context.Context, workspaceID, projectID uuid.UUID) (*store.Project, error) {
+	project, err := s.store.Projects.GetProject(ctx, workspaceID, projectID)
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+// getManagedProject returns the project if the caller may change it: workspace
+// owners and admins can change any project, members only the ones they own.
+func (s *ProjectService) getManagedProject(ctx context.Context, userID, workspaceID, projectID uuid.UUID) (*store.Project, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.getProject(ctx, workspaceID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != "owner" && role != "admin" && (project.OwnerID == nil || *project.OwnerID != userID) {
+		return nil, ErrForbidden
+	}
+	return project, nil
+}
+
+// hideProjectSpent blanks the spent of a project for members who may not see
+// its budget burn, as it is priced from the rates of other members.
+func hideProjectSpent(role string, project *store.Project, userID uuid.UUID) {
+	if !canSeeProjectBudget(role, project, userID) {
+		project.Spent = nil
+		project.SpentAt = nil
+	}
+}
+
+func (s *ProjectService) checkReferences(ctx context.Context, workspaceID uuid.UUID, teamID, ownerID, clientID *uuid.UUID) error {
+	if teamID != nil {
+		if _, err := s.store.Teams.GetTeam(ctx, workspaceID, *teamID); err != nil {
+			return mapTeamError(err)
+		}
+	}
+
+	if ownerID != nil {
+		if _, err := s.store.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, *ownerID); err != nil {
+			if errors.Is(err, store.ErrNotMember) {
+				return ErrNotWorkspaceMember
+			}
+			return err
+		}
+	}
+
+	if clientID != nil {
+		if _, err := s.store.Clients.GetClient(ctx, workspaceID, *clientID); err != nil {
+			if errors.Is(err, store.ErrClientNotFound) {
+				return ErrClientNotFound
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeTags trims tags and drops empty and case-insensitive duplicates,
+// keeping the first spelling.
+func normalizeTags(tags []string) store.Tags {
+	result := make(store.Tags, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// parseDate parses an optional YYYY-MM-DD value. Nil and empty values yield nil.
+func parseDate(value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(dateLayout, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func validateDateRange(start, due *time.Time) error {
+	if start != nil && due != nil && due.Before(*start) {
+		return ErrInvalidProjectDates
+	}
+	return nil
+}
+
+// parseUUIDFilter parses an optional UUID. An empty value yields nil.
+func parseUUIDFilter(value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, ErrInvalidFilter
+	}
+	return &id, nil
+}
+
+func parseBoolFilter(value string) (*bool, error) {
+	switch value {
+	case "":
+		return nil, nil
+	case "true", "false":
+		b := value == "true"
+		return &b, nil
+	default:
+		return nil, ErrInvalidFilter
+	}
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/exchange"
+	"github.com/lukabrkovic/artemis/pkg/money"
+	"github.com/rs/zerolog"
+)
+
+// projectBudgetBatchSize is how many projects an alert run loads at a time.
+const projectBudgetBatchSize = 200
+
+// ProjectBudgetBurn is how much of a project budget has been used. Amounts
+// are in minor units of the project currency: approved time is priced at the
+// task rate, else the member rate, else the project rate, else the workspace
+// default rate, and approved expenses are added on top. Rates and expenses in
+// another currency are converted at the rate of their own date.
+type ProjectBudgetBurn struct {
+	ProjectID   uuid.UUID      `json:"project_id"`
+	BudgetType  string         `json:"budget_type" example:"fixed_fee"`
+	Currency    string         `json:"currency" example:"EUR"`
+	Budget      *int64         `json:"budget"`
+	BudgetHours *money.Decimal `json:"budget_hours" swaggertype:"number"`
+	// ApprovedHours counts all approved time, priced or not.
+	ApprovedHours  money.Decimal  `json:"approved_hours" swaggertype:"number" example:"42.5"`
+	TimeCost       int64          `json:"time_cost"`
+	Expenses       int64          `json:"expenses"`
+	Spent          int64          `json:"spent"`
+	Remaining      *int64         `json:"remaining"`
+	RemainingHours *money.Decimal `json:"remaining_hours" swaggertype:"number"`
+	// PercentUsed is spent against budget for fixed-fee budgets and approved
+	// hours against budget_hours for hourly ones; nil without a budget.
+	PercentUsed *money.Decimal `json:"percent_used" swaggertype:"number" example:"63.4"`
+	// UnpricedHours is approved time without any rate, or with a rate that
+	// could not be converted, and is left out of time_cost.
+	UnpricedHours money.Decimal `json:"unpriced_hours" swaggertype:"number"`
+	// ExcludedExpenses counts expenses that could not be converted to the
+	// project currency and are left out of expenses.
+	ExcludedExpenses int   `json:"excluded_expenses"`
+	Thresholds       []int `json:"thresholds" example:"50,80,100"`
+	AlertedPercent   *int  `json:"alerted_percent" example:"50"`
+
+	// used and total are the exact quantities PercentUsed is rounded from:
+	// minor units for fixed-fee budgets, seconds for hourly ones.
+	used, total int64
+}
+
+type ProjectBudget interface {
+	GetProjectBudget(ctx context.Context, userID, workspaceID, projectID uuid.UUID) (*ProjectBudgetBurn, error)
+	SendBudgetAlerts(ctx context.Context) (int, error)
+}
+
+type ProjectBudgetService struct {
+	store    *store.Store
+	rates    *ExchangeRateService
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewProjectBudgetService(store *store.Store, provider exchange.Provider, eventBus EventPublisher, logger zerolog.Logger) *ProjectBudgetService {
+	return &ProjectBudgetService{
+		store:    store,
+		rates:    NewExchangeRateService(store, provider, logger),
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "project_budget_service").Logger(),
+	}
+}
+
+var _ ProjectBudget = (*ProjectBudgetService)(nil)
+
+// GetProjectBudget reports the burn of a project budget. As it prices the
+// time of individual members, only workspace owners and admins and the
+// project owner see it.
+func (s *ProjectBudgetService) GetProjectBudget(ctx context.Context, userID, workspaceID, projectID uuid.UUID) (*ProjectBudgetBurn, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.store.Projects.GetProject(ctx, workspaceID, projectID)
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	if !canSeeProjectBudget(role, project, userID) {
+		return nil, ErrForbidden
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	burn, err := s.burn(ctx, project, settings.Settings)
+	if err != nil {
+		return nil, err
+	}
+	s.recordSpent(ctx, project, burn)
+	return burn, nil
+}
+
+// canSeeProjectBudget reports whether a member with the given role may see
+// what a project has burned.
+func canSeeProjectBudget(role string, project *store.Project, userID uuid.UUID) bool {
+	return role == "owner" || role == "admin" || sameUUID(project.OwnerID, &userID)
+}
+
+// recordSpent keeps the spent shown on the project in step with its burn.
+// Failing to record it does not fail the caller.
+func (s *ProjectBudgetService) recordSpent(ctx context.Context, project *store.Project, burn *ProjectBudgetBurn) {
+	if project.Spent != nil && *project.Spent == burn.Spent {
+		return
+	}
+	if err := s.store.Projects.SetProjectBudgetSpent(ctx, project.ID, burn.Spent); err != nil {
+		s.logger.Warn().Err(err).Str("project_id", project.ID.String()).Msg("failed to record project spend")
+	}
+}
+
+// SendBudgetAlerts warns project owners when the burn of their project
+// crosses one of the workspace budget thresholds. Only the highest newly
+// crossed threshold is alerted, and each one only once, even with several
+// replicas running the job. When the burn drops back, for example because the
+// budget was raised, the threshold is alerted again the next time it is
+// crossed.
+func (s *ProjectBudgetService) SendBudgetAlerts(ctx context.Context) (int, error) {
+	settings := map[uuid.UUID]store.SettingsDocument{}
+	sent := 0
+	after := uuid.Nil
+	for {
+		projects, err := s.store.Projects.ListBudgetedProjects(ctx, after, projectBudgetBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, project := range projects {
+			if err := store.CheckContext(ctx); err != nil {
+				return sent, err
+			}
+			after = project.ID
+
+			workspaceSettings, ok := settings[project.WorkspaceID]
+			if !ok {
+				record, err := getWorkspaceSettings(ctx, s.store, project.WorkspaceID)
+				if err != nil {
+					s.logger.Warn().Err(err).Str("workspace_id", project.WorkspaceID.String()).Msg("failed to load settings for budget alerts")
+					continue
+				}
+				workspaceSettings = record.Settings
+				settings[project.WorkspaceID] = workspaceSettings
+			}
+
+			alerted, err := s.alertBudget(ctx, &project, workspaceSettings)
+			if err != nil {
+				s.logger.Warn().Err(err).Str("project_id", project.ID.String()).Msg("failed to check project budget")
+				continue
+			}
+			if alerted {
+				sent++
+			}
+		}
+
+		if len(projects) < projectBudgetBatchSize {
+			break
+		}
+	}
+
+	if sent > 0 {
+		s.logger.Info().Int("projects", sent).Msg("sent project budget alerts")
+	}
+	return sent, nil
+}
+
+// alertBudget moves the alerted threshold of a project to the highest one
+// its burn has reached and publishes an alert when it moved up.
+func (s *ProjectBudgetService) alertBudget(ctx context.Context, project *store.Project, settings store.SettingsDocument) (bool, error) {
+	burn, err := s.burn(ctx, project, settings)
+	if err != nil {
+		return false, err
+	}
+	s.recordSpent(ctx, project, burn)
+
+	reached := 0
+	if burn.PercentUsed != nil {
+		for _, threshold := range burn.Thresholds {
+			if reachesPercent(burn.used, burn.total, threshold) {
+				reached = max(reached, threshold)
+			}
+		}
+	}
+
+	alerted := 0
+	if project.BudgetAlertedPercent != nil {
+		alerted = *project.BudgetAlertedPercent
+	}
+	if reached == alerted {
+		return false, nil
+	}
+
+	var to *int
+	if reached > 0 {
+		to = &reached
+	}
+	moved, err := s.store.Projects.SetProjectBudgetAlert(ctx, project.ID, project.BudgetAlertedPercent, to)
+	if err != nil || !moved || reached < alerted {
+		return false, err
+	}
+
+	if s.eventBus != nil {
+		var userID uuid.UUID
+		if project.OwnerID != nil {
+			userID = *project.OwnerID
+		}
+		s.eventBus.Publish(ctx, events.EventProjectBudgetThreshold, userID, map[string]any{
+			"workspace_id":   project.WorkspaceID,
+			"project_id":     project.ID,
+			"name":           project.Name,
+			"owner_id":       project.OwnerID,
+			"threshold":      reached,
+			"percent_used":   burn.PercentUsed,
+			"budget_type":    burn.BudgetType,
+			"budget":         burn.Budget,
+			"budget_hours":   burn.BudgetHours,
+			"approved_hours": burn.ApprovedHours,
+			"spent":          burn.Spent,
+			"amount_spent":   money.FormatFor(settings.Locale).Amount(burn.Spent, burn.Currency),
+			"currency":       burn.Currency,
+		})
+	}
+	return true, nil
+}
+
+// burn prices the approved time and expenses of a project against its
+// budget.
+func (s *ProjectBudgetService) burn(ctx context.Context, project *store.Project, settings store.SettingsDocument) (*ProjectBudgetBurn, error) {
+	currency := strings.ToUpper(project.Currency)
+	burn := &ProjectBudgetBurn{
+		ProjectID:      project.ID,
+		BudgetType:     project.BudgetType,
+		Currency:       currency,
+		Budget:         project.Budget,
+		BudgetHours:    project.BudgetHours,
+		Thresholds:     settings.BudgetAlertThresholds,
+		AlertedPercent: project.BudgetAlertedPercent,
+	}
+	if burn.Thresholds == nil {
+		burn.Thresholds = []int{}
+	}
+
+	entries, err := s.store.Projects.ListProjectBudgetTime(ctx, project.WorkspaceID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	var seconds, unpricedSeconds int64
+	for _, entry := range entries {
+		seconds += entry.Seconds
+
+		rate, rateCurrency := entry.Rate, currency
+		switch entry.RateSource {
+		case "member":
+			rateCurrency = settings.DefaultCurrency
+		case "workspace":
+			if settings.DefaultHourlyRate > 0 {
+				rate, rateCurrency = &settings.DefaultHourlyRate, settings.DefaultCurrency
+			}
+		}
+		if rate == nil {
+			unpricedSeconds += entry.Seconds
+			continue
+		}
+
+		cost, err := timeCost(entry.Seconds, *rate)
+		if err != nil {
+			return nil, err
+		}
+		cost, ok, err := s.convert(ctx, cost, rateCurrency, currency, entry.WorkDate)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			unpricedSeconds += entry.Seconds
+			continue
+		}
+		burn.TimeCost += cost
+	}
+	if burn.ApprovedHours, err = money.Ratio(seconds, 3600); err != nil {
+		return nil, err
+	}
+	if burn.UnpricedHours, err = money.Ratio(unpricedSeconds, 3600); err != nil {
+		return nil, err
+	}
+
+	expenses, err := s.store.Projects.ListProjectBudgetExpenses(ctx, project.WorkspaceID, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, expense := range expenses {
+		amount, ok := expense.Amount, true
+		if !strings.EqualFold(expense.Currency, currency) {
+			if expense.ExchangeRate != nil && expense.BaseCurrency != nil && strings.EqualFold(*expense.BaseCurrency, currency) {
+				amount = money.Convert(expense.Amount, expense.Currency, currency, *expense.ExchangeRate)
+			} else if amount, ok, err = s.convert(ctx, expense.Amount, expense.Currency, currency, expense.SpentOn); err != nil {
+				return nil, err
+			}
+		}
+		if !ok {
+			burn.ExcludedExpenses++
+			continue
+		}
+		burn.Expenses += amount
+	}
+	burn.Spent = burn.TimeCost + burn.Expenses
+
+	if project.Budget != nil {
+		remaining := *project.Budget - burn.Spent
+		burn.Remaining = &remaining
+	}
+	if project.BudgetHours != nil {
+		remaining := *project.BudgetHours - burn.ApprovedHours
+		burn.RemainingHours = &remaining
+	}
+
+	switch {
+	case project.BudgetType == "fixed_fee" && project.Budget != nil && *project.Budget > 0:
+		burn.used, burn.total = burn.Spent, *project.Budget
+	case project.BudgetType == "hourly" && project.BudgetHours != nil && *project.BudgetHours > 0:
+		// Both sides in ten-thousandths of a second, the scale of budget_hours.
+		burn.used, burn.total = int64(money.NewDecimal(seconds)), int64(*project.BudgetHours)*3600
+	default:
+		return burn, nil
+	}
+	fraction, err := money.Ratio(burn.used, burn.total)
+	if err != nil {
+		return nil, err
+	}
+	percent := fraction * 100
+	burn.PercentUsed = &percent
+	return burn, nil
+}
+
+// convert converts an amount at the rate of its date. It reports false when
+// no rate is available for the date.
+func (s *ProjectBudgetService) convert(ctx context.Context, amount int64, from, to string, date time.Time) (int64, bool, error) {
+	rate, err := s.rates.rate(ctx, from, to, date)
+	if err != nil {
+		if errors.Is(err, ErrExchangeRateUnavailable) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return money.Convert(amount, from, to, rate), true, nil
+}
+
+// timeCost prices seconds of time at an hourly rate the way invoice lines
+// price it: the hours are rounded to a Decimal, then multiplied.
+func timeCost(seconds, rate int64) (int64, error) {
+	hours, err := money.Ratio(seconds, 3600)
+	if err != nil {
+		return 0, err
+	}
+	return money.Mul(rate, hours)
+}
+
+// reachesPercent reports whether used is at least percent of total, compared
+// exactly rather than on the rounded percentage.
+func reachesPercent(used, total int64, percent int) bool {
+	lhs := new(big.Int).Mul(big.NewInt(used), big.NewInt(100))
+	rhs := new(big.Int).Mul(big.NewInt(total), big.NewInt(int64(percent)))
+	return lhs.Cmp(rhs) >= 0
+}
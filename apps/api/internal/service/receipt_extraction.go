@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/extract"
+	"github.com/lukabrkovic/artemis/pkg/money"
+	pkgstorage "github.com/lukabrkovic/artemis/pkg/storage"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// receiptExtractionTimeout bounds reading one receipt.
+	receiptExtractionTimeout = time.Minute
+	// receiptExtractionDelay is how long an upload is left to its event
+	// before the sweep reads it instead.
+	receiptExtractionDelay = 2 * time.Minute
+	// receiptExtractionRetryWindow is how long a receipt that cannot be
+	// downloaded is retried before its extraction is marked failed.
+	receiptExtractionRetryWindow = time.Hour
+	// receiptExtractionBatchSize is how many receipts a sweep reads.
+	receiptExtractionBatchSize = 50
+	// maxReceiptExtractionAttempts is how many times reading a receipt is
+	// started before it is marked failed, so a receipt that crashes the
+	// process is not retried after every restart.
+	maxReceiptExtractionAttempts = 3
+)
+
+// ReceiptExtraction reads uploaded receipts into suggestions on their
+// expenses. Uploads are read as their expense.receipt_uploaded events
+// arrive; a periodic sweep reads those whose event was missed.
+type ReceiptExtraction interface {
+	HandleReceiptUploaded(ctx context.Context, event *events.Event) error
+	ExtractReceipt(ctx context.Context, workspaceID, expenseID uuid.UUID) error
+	ExtractPendingReceipts(ctx context.Context) (int, error)
+}
+
+type ReceiptExtractionService struct {
+	store     *store.Store
+	storage   pkgstorage.Provider
+	extractor extract.ReceiptExtractor
+	eventBus  EventPublisher
+	logger    zerolog.Logger
+}
+
+func NewReceiptExtractionService(store *store.Store, storage pkgstorage.Provider, extractor extract.ReceiptExtractor, eventBus EventPublisher, logger zerolog.Logger) *ReceiptExtractionService {
+	return &ReceiptExtractionService{
+		store:     store,
+		storage:   storage,
+		extractor: extractor,
+		eventBus:  eventBus,
+		logger:    logger.With().Str("component", "receipt_extraction_service").Logger(),
+	}
+}
+
+var _ ReceiptExtraction = (*ReceiptExtractionService)(nil)
+
+// HandleReceiptUploaded reads the receipt named by an
+// expense.receipt_uploaded event.
+func (s *ReceiptExtractionService) HandleReceiptUploaded(ctx context.Context, event *events.Event) error {
+	data, err := json.Marshal(event.Payload)
+	if err != nil {
+		return err
+	}
+	var payload struct {
+		WorkspaceID uuid.UUID `json:"workspace_id"`
+		ExpenseID   uuid.UUID `json:"expense_id"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+	return s.ExtractReceipt(ctx, payload.WorkspaceID, payload.ExpenseID)
+}
+
+// ExtractReceipt reads the receipt of an expense and stores what it found
+// as suggestions. Receipts that were already read, replaced or removed are
+// skipped, so repeated events are harmless. A receipt that cannot be
+// downloaded is left pending for the sweep to retry; one that was
+// downloaded is read at most maxReceiptExtractionAttempts times.
+func (s *ReceiptExtractionService) ExtractReceipt(ctx context.Context, workspaceID, expenseID uuid.UUID) error {
+	expense, err := s.store.Expenses.GetExpense(ctx, workspaceID, expenseID)
+	if err != nil {
+		if errors.Is(err, store.ErrExpenseNotFound) {
+			return nil
+		}
+		return err
+	}
+	if expense.ExtractionStatus == nil || *expense.ExtractionStatus != "pending" ||
+		expense.ReceiptObject == nil || expense.ReceiptUploadedAt == nil {
+		return nil
+	}
+
+	extractor := s.extractor.Name()
+	params := store.SaveReceiptExtractionParams{
+		WorkspaceID: workspaceID,
+		ExpenseID:   expense.ID,
+		UploadedAt:  *expense.ReceiptUploadedAt,
+		Status:      "completed",
+		Extractor:   &extractor,
+	}
+
+	extractCtx, cancel := context.WithTimeout(ctx, receiptExtractionTimeout)
+	defer cancel()
+
+	var receipt *extract.Receipt
+	object, err := s.storage.Download(extractCtx, pkgstorage.BucketPrivate, *expense.ReceiptObject)
+	if err == nil {
+		receipt, err = s.extractReceipt(extractCtx, expense, object)
+		object.Close()
+	}
+
+	switch {
+	case errors.Is(err, errReceiptSkipped):
+		return nil
+	case err == nil:
+		receiptSuggestions(&params, receipt, expense.Currency)
+	case errors.Is(err, extract.ErrUnsupported):
+		message := err.Error()
+		params.Status, params.Error = "unsupported", &message
+	case errors.Is(err, errReceiptAttemptsExhausted):
+		message := err.Error()
+		params.Status, params.Error = "failed", &message
+	case ctx.Err() != nil || time.Since(*expense.ReceiptUploadedAt) < receiptExtractionRetryWindow:
+		return err
+	default:
+		message := err.Error()
+		params.Status, params.Error = "failed", &message
+	}
+
+	saved, err := s.store.Expenses.SaveReceiptExtraction(ctx, params)
+	if err != nil || !saved {
+		return err
+	}
+
+	if params.Status == "completed" && s.eventBus != nil {
+		payload := map[string]any{
+			"workspace_id":       workspaceID,
+			"expense_id":         expense.ID,
+			"user_id":            expense.UserID,
+			"merchant":           expense.Merchant,
+			"suggested_merchant": params.Merchant,
+			"suggested_amount":   params.Amount,
+			"suggested_currency": params.Currency,
+		}
+		if params.SpentOn != nil {
+			payload["suggested_spent_on"] = params.SpentOn.Format(dateLayout)
+		}
+		s.eventBus.Publish(ctx, events.EventExpenseReceiptExtracted, expense.UserID, payload)
+	}
+	return nil
+}
+
+var (
+	errReceiptSkipped           = errors.New("receipt was replaced, removed or already read")
+	errReceiptAttemptsExhausted = fmt.Errorf("gave up after %d attempts to read the receipt", maxReceiptExtractionAttempts)
+)
+
+// extractReceipt counts an attempt at reading a downloaded receipt before
+// reading it, and refuses once the attempts are used up.
+func (s *ReceiptExtractionService) extractReceipt(ctx context.Context, expense *store.Expense, object io.Reader) (*extract.Receipt, error) {
+	attempts, err := s.store.Expenses.StartReceiptExtraction(ctx, expense.WorkspaceID, expense.ID, *expense.ReceiptUploadedAt)
+	if err != nil {
+		return nil, err
+	}
+	if attempts == 0 {
+		return nil, errReceiptSkipped
+	}
+	if attempts > maxReceiptExtractionAttempts {
+		return nil, errReceiptAttemptsExhausted
+	}
+	return s.extractor.Extract(ctx, object, *expense.ReceiptContentType)
+}
+
+// ExtractPendingReceipts reads receipts whose upload event was lost or
+// failed, such as those uploaded while NATS was unreachable.
+func (s *ReceiptExtractionService) ExtractPendingReceipts(ctx context.Context) (int, error) {
+	expenses, err := s.store.Expenses.ListPendingReceiptExtractions(ctx, time.Now().Add(-receiptExtractionDelay), receiptExtractionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	read := 0
+	for _, expense := range expenses {
+		if err := store.CheckContext(ctx); err != nil {
+			return read, err
+		}
+		if err := s.ExtractReceipt(ctx, expense.WorkspaceID, expense.ID); err != nil {
+			s.logger.Warn().Err(err).Str("expense_id", expense.ID.String()).Msg("failed to read expense receipt")
+			continue
+		}
+		read++
+	}
+
+	if read > 0 {
+		s.logger.Info().Int("receipts", read).Msg("read pending expense receipts")
+	}
+	return read, nil
+}
+
+// receiptSuggestions copies what was read off a receipt into params. The
+// amount is kept in the currency found on the receipt, or in the expense
+// currency when the receipt names none.
+func receiptSuggestions(params *store.SaveReceiptExtractionParams, receipt *extract.Receipt, expenseCurrency string) {
+	params.Merchant = receipt.Merchant
+	params.SpentOn = receipt.Date
+	if receipt.Total == nil {
+		return
+	}
+
+	currency := expenseCurrency
+	if receipt.Currency != nil {
+		currency = *receipt.Currency
+	}
+	if amount := money.ToMinor(*receipt.Total, currency); amount > 0 {
+		params.Amount, params.Currency = &amount, &currency
+	}
+}
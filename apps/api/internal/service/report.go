@@ -0,0 +1,250 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/exchange"
+	"github.com/lukabrkovic/artemis/pkg/money"
+	"github.com/rs/zerolog"
+)
+
+var ErrInvalidReportPeriod = errors.New("to must not be before from, and a report covers at most 366 days")
+
+// maxReportDays is the longest period a report covers.
+const maxReportDays = 366
+
+type RevenueReportInput struct {
+	From string `json:"from" validate:"required,datetime=2006-01-02"`
+	To   string `json:"to" validate:"required,datetime=2006-01-02"`
+}
+
+// RevenueReport sums what was invoiced, collected and spent in a period.
+// Amounts without a currency are in minor units of the base currency, the
+// workspace default currency, each document converted at the rate of its own
+// date.
+type RevenueReport struct {
+	BaseCurrency string              `json:"base_currency" example:"EUR"`
+	From         string              `json:"from" example:"2026-01-01"`
+	To           string              `json:"to" example:"2026-03-31"`
+	Invoiced     int64               `json:"invoiced"`
+	Collected    int64               `json:"collected"`
+	Outstanding  int64               `json:"outstanding"`
+	Expenses     int64               `json:"expenses"`
+	Currencies   []RevenueByCurrency `json:"currencies"`
+	Clients      []RevenueByClient   `json:"clients"`
+	Months       []RevenueByMonth    `json:"months"`
+}
+
+// RevenueByCurrency holds the totals of the documents in one currency, both
+// as they were written and converted to the base currency.
+type RevenueByCurrency struct {
+	Currency        string `json:"currency" example:"USD"`
+	Invoiced        int64  `json:"invoiced"`
+	Collected       int64  `json:"collected"`
+	Outstanding     int64  `json:"outstanding"`
+	Expenses        int64  `json:"expenses"`
+	InvoicedBase    int64  `json:"invoiced_base"`
+	CollectedBase   int64  `json:"collected_base"`
+	OutstandingBase int64  `json:"outstanding_base"`
+	ExpensesBase    int64  `json:"expenses_base"`
+}
+
+type RevenueByClient struct {
+	ClientID    uuid.UUID `json:"client_id"`
+	ClientName  string    `json:"client_name"`
+	Invoiced    int64     `json:"invoiced"`
+	Collected   int64     `json:"collected"`
+	Outstanding int64     `json:"outstanding"`
+}
+
+type RevenueByMonth struct {
+	Month     string `json:"month" example:"2026-03"`
+	Invoiced  int64  `json:"invoiced"`
+	Collected int64  `json:"collected"`
+	Expenses  int64  `json:"expenses"`
+}
+
+type Report interface {
+	GetRevenueReport(ctx context.Context, userID, workspaceID uuid.UUID, input RevenueReportInput) (*RevenueReport, error)
+}
+
+type ReportService struct {
+	store  *store.Store
+	rates  *ExchangeRateService
+	logger zerolog.Logger
+}
+
+func NewReportService(store *store.Store, provider exchange.Provider, logger zerolog.Logger) *ReportService {
+	return &ReportService{
+		store:  store,
+		rates:  NewExchangeRateService(store, provider, logger),
+		logger: logger.With().Str("component", "report_service").Logger(),
+	}
+}
+
+var _ Report = (*ReportService)(nil)
+
+// GetRevenueReport sums the invoices issued, the money received and the
+// approved expenses between from and to, inclusive. Every document counts at
+// the rate of its own date, so a report never changes when rates move later.
+// Only workspace admins see revenue.
+func (s *ReportService) GetRevenueReport(ctx context.Context, userID, workspaceID uuid.UUID, input RevenueReportInput) (*RevenueReport, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	from, err := time.Parse(dateLayout, input.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.Parse(dateLayout, input.To)
+	if err != nil {
+		return nil, err
+	}
+	if to.Before(from) || to.Sub(from) >= maxReportDays*24*time.Hour {
+		return nil, ErrInvalidReportPeriod
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	base := strings.ToUpper(settings.Settings.DefaultCurrency)
+
+	invoices, err := s.store.Reports.ListRevenueInvoices(ctx, workspaceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.store.Reports.ListRevenuePayments(ctx, workspaceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.store.Reports.ListRevenueExpenses(ctx, workspaceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RevenueReport{
+		BaseCurrency: base,
+		From:         input.From,
+		To:           input.To,
+		Currencies:   []RevenueByCurrency{},
+		Clients:      []RevenueByClient{},
+		Months:       []RevenueByMonth{},
+	}
+	currencies := map[string]*RevenueByCurrency{}
+	clients := map[uuid.UUID]*RevenueByClient{}
+	months := map[string]*RevenueByMonth{}
+
+	byCurrency := func(currency string) *RevenueByCurrency {
+		if _, ok := currencies[currency]; !ok {
+			currencies[currency] = &RevenueByCurrency{Currency: currency}
+		}
+		return currencies[currency]
+	}
+	byClient := func(document store.RevenueDocument) *RevenueByClient {
+		if _, ok := clients[document.ClientID]; !ok {
+			clients[document.ClientID] = &RevenueByClient{ClientID: document.ClientID, ClientName: document.ClientName}
+		}
+		return clients[document.ClientID]
+	}
+	byMonth := func(date time.Time) *RevenueByMonth {
+		month := date.Format("2006-01")
+		if _, ok := months[month]; !ok {
+			months[month] = &RevenueByMonth{Month: month}
+		}
+		return months[month]
+	}
+
+	for _, invoice := range invoices {
+		rate, err := s.documentRate(ctx, invoice, base)
+		if err != nil {
+			return nil, err
+		}
+		amount := money.Convert(invoice.Amount, invoice.Currency, base, rate)
+		outstanding := money.Convert(invoice.Outstanding, invoice.Currency, base, rate)
+
+		report.Invoiced += amount
+		report.Outstanding += outstanding
+
+		totals := byCurrency(invoice.Currency)
+		totals.Invoiced += invoice.Amount
+		totals.Outstanding += invoice.Outstanding
+		totals.InvoicedBase += amount
+		totals.OutstandingBase += outstanding
+
+		client := byClient(invoice)
+		client.Invoiced += amount
+		client.Outstanding += outstanding
+
+		byMonth(invoice.Date).Invoiced += amount
+	}
+
+	for _, payment := range payments {
+		rate, err := s.documentRate(ctx, payment, base)
+		if err != nil {
+			return nil, err
+		}
+		amount := money.Convert(payment.Amount, payment.Currency, base, rate)
+
+		report.Collected += amount
+
+		totals := byCurrency(payment.Currency)
+		totals.Collected += payment.Amount
+		totals.CollectedBase += amount
+
+		byClient(payment).Collected += amount
+		byMonth(payment.Date).Collected += amount
+	}
+
+	for _, expense := range expenses {
+		rate, err := s.documentRate(ctx, expense, base)
+		if err != nil {
+			return nil, err
+		}
+		amount := money.Convert(expense.Amount, expense.Currency, base, rate)
+
+		report.Expenses += amount
+
+		totals := byCurrency(expense.Currency)
+		totals.Expenses += expense.Amount
+		totals.ExpensesBase += amount
+
+		byMonth(expense.Date).Expenses += amount
+	}
+
+	for _, totals := range currencies {
+		report.Currencies = append(report.Currencies, *totals)
+	}
+	slices.SortFunc(report.Currencies, func(a, b RevenueByCurrency) int { return cmp.Compare(a.Currency, b.Currency) })
+
+	for _, client := range clients {
+		report.Clients = append(report.Clients, *client)
+	}
+	slices.SortFunc(report.Clients, func(a, b RevenueByClient) int {
+		return cmp.Or(cmp.Compare(b.Invoiced, a.Invoiced), cmp.Compare(a.ClientName, b.ClientName))
+	})
+
+	for _, month := range months {
+		report.Months = append(report.Months, *month)
+	}
+	slices.SortFunc(report.Months, func(a, b RevenueByMonth) int { return cmp.Compare(a.Month, b.Month) })
+
+	return report, nil
+}
+
+// documentRate is the rate recorded on the document when it was recorded
+// against the current base currency, else the rate on the document date.
+func (s *ReportService) documentRate(ctx context.Context, document store.RevenueDocument, base string) (money.Rate, error) {
+	if document.ExchangeRate != nil && document.BaseCurrency != nil && strings.EqualFold(*document.BaseCurrency, base) {
+		return *document.ExchangeRate, nil
+	}
+	return s.rates.rate(ctx, document.Currency, base, document.Date)
+}
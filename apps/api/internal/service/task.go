@@ -0,0 +1,631 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrTaskNotFound            = errors.New("task not found")
+	ErrSubtaskNotFound         = errors.New("subtask not found")
+	ErrInvalidStatusTransition = errors.New("invalid task status transition")
+	ErrTaskStatusConflict      = errors.New("task status was changed concurrently")
+	ErrTooManyTags             = errors.New("a task can have at most 20 tags")
+)
+
+const maxTaskTags = 20
+
+// taskTransitions lists the statuses a task may move to from each status.
+var taskTransitions = map[string][]string{
+	"todo":        {"in-progress"},
+	"in-progress": {"todo", "review", "done"},
+	"review":      {"in-progress", "done"},
+	"done":        {"in-progress"},
+}
+
+var taskPriorities = map[string]bool{
+	"low":    true,
+	"medium": true,
+	"high":   true,
+	"urgent": true,
+}
+
+type CreateTaskInput struct {
+	Title          string     `json:"title" validate:"required,min=1,max=255"`
+	Description    *string    `json:"description,omitempty" validate:"omitempty,max=10000"`
+	Status         *string    `json:"status,omitempty" validate:"omitempty,oneof=todo in-progress review done"`
+	Priority       *string    `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	AssigneeID     *uuid.UUID `json:"assignee_id,omitempty"`
+	DueDate        *string    `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	EstimatedHours *float64   `json:"estimated_hours,omitempty" validate:"omitempty,min=0,max=999999"`
+	HourlyRate     *int64     `json:"hourly_rate,omitempty" validate:"omitempty,min=0"`
+	Tags           []string   `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+}
+
+// UpdateTaskInput only changes the fields that are set. Empty assignee_id or
+// due_date values clear them. The status is changed through UpdateTaskStatus.
+type UpdateTaskInput struct {
+	Title          *string  `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	Description    *string  `json:"description,omitempty" validate:"omitempty,max=10000"`
+	Priority       *string  `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	AssigneeID     *string  `json:"assignee_id,omitempty" validate:"omitempty,uuid"`
+	DueDate        *string  `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	EstimatedHours *float64 `json:"estimated_hours,omitempty" validate:"omitempty,min=0,max=999999"`
+	HourlyRate     *int64   `json:"hourly_rate,omitempty" validate:"omitempty,min=0"`
+	Tags           []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+}
+
+type UpdateTaskStatusInput struct {
+	Status string `json:"status" validate:"required,oneof=todo in-progress review done"`
+}
+
+type TaskTagsInput struct {
+	Tags []string `json:"tags" validate:"required,min=1,max=20,dive,required,max=50"`
+}
+
+type CreateSubtaskInput struct {
+	Title string `json:"title" validate:"required,min=1,max=255"`
+}
+
+type UpdateSubtaskInput struct {
+	Title     *string `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	Completed *bool   `json:"completed,omitempty"`
+}
+
+type Task interface {
+	CreateTask(ctx context.Context, userID, workspaceID, projectID uuid.UUID, input CreateTaskInput) (*store.Task, error)
+	GetTask(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID) (*store.Task, error)
+	ListTasks(ctx context.Context, userID, workspaceID, projectID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Task], error)
+	UpdateTask(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input UpdateTaskInput) (*store.Task, error)
+	UpdateTaskStatus(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input UpdateTaskStatusInput) (*store.Task, error)
+	DeleteTask(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID) error
+	MoveTask(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input MoveTaskInput) (*store.Task, error)
+	MoveTasks(ctx context.Context, userID, workspaceID, projectID uuid.UUID, input MoveTasksInput) ([]store.Task, error)
+
+	AddTaskTags(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input TaskTagsInput) (*store.Task, error)
+	RemoveTaskTag(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, tag string) (*store.Task, error)
+	ListTaskTags(ctx context.Context, userID, workspaceID uuid.UUID) ([]store.TagUsage, error)
+
+	CreateSubtask(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input CreateSubtaskInput) (*store.Subtask, error)
+	UpdateSubtask(ctx context.Context, userID, workspaceID, projectID, taskID, subtaskID uuid.UUID, input UpdateSubtaskInput) (*store.Subtask, error)
+	DeleteSubtask(ctx context.Context, userID, workspaceID, projectID, taskID, subtaskID uuid.UUID) error
+
+	ListTaskDependencies(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID) (*TaskDependencies, error)
+	AddTaskDependency(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input AddTaskDependencyInput) (*store.TaskDependency, error)
+	RemoveTaskDependency(ctx context.Context, userID, workspaceID, projectID, taskID, dependsOnID uuid.UUID) error
+	GetProjectSchedule(ctx context.Context, userID, workspaceID, projectID uuid.UUID) (*ProjectSchedule, error)
+}
+
+type TaskService struct {
+	store    *store.Store
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewTaskService(store *store.Store, eventBus EventPublisher, logger zerolog.Logger) *TaskService {
+	return &TaskService{
+		store:    store,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "task_service").Logger(),
+	}
+}
+
+var _ Task = (*TaskService)(nil)
+
+func (s *TaskService) CreateTask(ctx context.Context, userID, workspaceID, projectID uuid.UUID, input CreateTaskInput) (*store.Task, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkProject(ctx, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+	if input.HourlyRate != nil {
+		if err := s.checkRateManager(ctx, role, userID, workspaceID, projectID); err != nil {
+			return nil, err
+		}
+	}
+
+	params := store.CreateTaskParams{
+		WorkspaceID:    workspaceID,
+		ProjectID:      projectID,
+		Title:          strings.TrimSpace(input.Title),
+		Description:    input.Description,
+		Status:         "todo",
+		Priority:       "medium",
+		AssigneeID:     input.AssigneeID,
+		EstimatedHours: input.EstimatedHours,
+		HourlyRate:     input.HourlyRate,
+		Tags:           normalizeTags(input.Tags),
+		CreatedBy:      &userID,
+	}
+	if input.Status != nil {
+		params.Status = *input.Status
+	}
+	if input.Priority != nil {
+		params.Priority = *input.Priority
+	}
+	if params.Status == "done" {
+		now := time.Now()
+		params.CompletedAt = &now
+	}
+
+	if params.DueDate, err = parseDate(input.DueDate); err != nil {
+		return nil, err
+	}
+	if err := checkAssignee(ctx, s.store, workspaceID, params.AssigneeID); err != nil {
+		return nil, err
+	}
+
+	var task *store.Task
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		var err error
+		if params.Rank, err = appendTaskRank(ctx, tx, projectID, params.Status); err != nil {
+			return err
+		}
+		task, err = tx.Tasks.CreateTask(ctx, params)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if task.AssigneeID != nil {
+		s.publishAssigned(ctx, userID, task)
+	}
+
+	return task, nil
+}
+
+func (s *TaskService) GetTask(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID) (*store.Task, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	task, err := s.getTask(ctx, workspaceID, projectID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Subtasks, err = s.store.Tasks.ListSubtasks(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (s *TaskService) ListTasks(ctx context.Context, userID, workspaceID, projectID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Task], error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkProject(ctx, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+
+	filter := store.TaskFilter{
+		Status:   filters.Filters["status"],
+		Priority: filters.Filters["priority"],
+		Tag:      filters.Filters["tag"],
+	}
+	if (filter.Status != "" && taskTransitions[filter.Status] == nil) || (filter.Priority != "" && !taskPriorities[filter.Priority]) {
+		return nil, ErrInvalidFilter
+	}
+
+	var err error
+	if filter.Blocked, err = parseBoolFilter(filters.Filters["blocked"]); err != nil {
+		return nil, err
+	}
+
+	switch assignee := filters.Filters["assignee_id"]; assignee {
+	case "none":
+		filter.Unassigned = true
+	case "me":
+		filter.AssigneeID = &userID
+	default:
+		if filter.AssigneeID, err = parseUUIDFilter(assignee); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks, total, err := s.store.Tasks.ListTasks(ctx, workspaceID, projectID, filter, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.BuildFilterResponse(tasks, total, filters), nil
+}
+
+func (s *TaskService) UpdateTask(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input UpdateTaskInput) (*store.Task, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := s.getTask(ctx, workspaceID, projectID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := taskUpdateParams(task)
+	if input.Title != nil {
+		params.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.Description != nil {
+		params.Description = input.Description
+	}
+	if input.Priority != nil {
+		params.Priority = *input.Priority
+	}
+	if input.AssigneeID != nil {
+		if params.AssigneeID, err = parseUUIDFilter(*input.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+	if input.DueDate != nil {
+		if params.DueDate, err = parseDate(input.DueDate); err != nil {
+			return nil, err
+		}
+	}
+	if input.EstimatedHours != nil {
+		params.EstimatedHours = input.EstimatedHours
+	}
+	if input.HourlyRate != nil && (task.HourlyRate == nil || *task.HourlyRate != *input.HourlyRate) {
+		if err := s.checkRateManager(ctx, role, userID, workspaceID, projectID); err != nil {
+			return nil, err
+		}
+		params.HourlyRate = input.HourlyRate
+	}
+	if input.Tags != nil {
+		params.Tags = normalizeTags(input.Tags)
+	}
+
+	assigneeChanged := !sameUUID(task.AssigneeID, params.AssigneeID)
+	if assigneeChanged {
+		if err := checkAssignee(ctx, s.store, workspaceID, params.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.updateTask(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if assigneeChanged && updated.AssigneeID != nil {
+		s.publishAssigned(ctx, userID, updated)
+	}
+
+	return updated, nil
+}
+
+// UpdateTaskStatus moves the task along the status workflow and to the bottom
+// of its new board column. Moving to the current status is a no-op.
+func (s *TaskService) UpdateTaskStatus(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input UpdateTaskStatusInput) (*store.Task, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	task, err := s.getTask(ctx, workspaceID, projectID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status == input.Status {
+		return task, nil
+	}
+	if !canTransitionTask(task.Status, input.Status) {
+		return nil, ErrInvalidStatusTransition
+	}
+
+	var updated *store.Task
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		rank, err := appendTaskRank(ctx, tx, projectID, input.Status)
+		if err != nil {
+			return err
+		}
+		updated, err = tx.Tasks.UpdateTaskStatus(ctx, workspaceID, taskID, task.Status, input.Status, rank)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrTaskStatusConflict):
+			return nil, ErrTaskStatusConflict
+		case errors.Is(err, store.ErrTaskNotFound):
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventTaskStatusChanged, userID, map[string]any{
+			"workspace_id": workspaceID,
+			"project_id":   updated.ProjectID,
+			"task_id":      updated.ID,
+			"title":        updated.Title,
+			"from":         task.Status,
+			"to":           updated.Status,
+			"assignee_id":  updated.AssigneeID,
+		})
+	}
+
+	return updated, nil
+}
+
+// DeleteTask is limited to workspace admins, the project owner and the task
+// creator.
+func (s *TaskService) DeleteTask(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID) error {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.store.Projects.GetProject(ctx, workspaceID, projectID)
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+
+	task, err := s.getTask(ctx, workspaceID, projectID, taskID)
+	if err != nil {
+		return err
+	}
+
+	if role != "owner" && role != "admin" && !sameUUID(project.OwnerID, &userID) && !sameUUID(task.CreatedBy, &userID) {
+		return ErrForbidden
+	}
+
+	if err := s.store.Tasks.DeleteTask(ctx, workspaceID, taskID); err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *TaskService) AddTaskTags(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input TaskTagsInput) (*store.Task, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	task, err := s.getTask(ctx, workspaceID, projectID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := taskUpdateParams(task)
+	params.Tags = normalizeTags(append(append([]string{}, task.Tags...), input.Tags...))
+	if len(params.Tags) > maxTaskTags {
+		return nil, ErrTooManyTags
+	}
+	if len(params.Tags) == len(task.Tags) {
+		return task, nil
+	}
+
+	return s.updateTask(ctx, params)
+}
+
+// RemoveTaskTag removes the tag regardless of case. Removing a tag the task
+// does not have is a no-op.
+func (s *TaskService) RemoveTaskTag(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, tag string) (*store.Task, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	task, err := s.getTask(ctx, workspaceID, projectID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := taskUpdateParams(task)
+	params.Tags = make(store.Tags, 0, len(task.Tags))
+	for _, existing := range task.Tags {
+		if !strings.EqualFold(existing, strings.TrimSpace(tag)) {
+			params.Tags = append(params.Tags, existing)
+		}
+	}
+	if len(params.Tags) == len(task.Tags) {
+		return task, nil
+	}
+
+	return s.updateTask(ctx, params)
+}
+
+// ListTaskTags returns the tags used across the workspace's tasks with their
+// usage counts, most used first.
+func (s *TaskService) ListTaskTags(ctx context.Context, userID, workspaceID uuid.UUID) ([]store.TagUsage, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	tags, err := s.store.Tasks.ListWorkspaceTaskTags(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []store.TagUsage{}
+	}
+	return tags, nil
+}
+
+func (s *TaskService) CreateSubtask(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input CreateSubtaskInput) (*store.Subtask, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getTask(ctx, workspaceID, projectID, taskID); err != nil {
+		return nil, err
+	}
+
+	return s.store.Tasks.CreateSubtask(ctx, taskID, strings.TrimSpace(input.Title))
+}
+
+func (s *TaskService) UpdateSubtask(ctx context.Context, userID, workspaceID, projectID, taskID, subtaskID uuid.UUID, input UpdateSubtaskInput) (*store.Subtask, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getTask(ctx, workspaceID, projectID, taskID); err != nil {
+		return nil, err
+	}
+
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		input.Title = &title
+	}
+
+	subtask, err := s.store.Tasks.UpdateSubtask(ctx, taskID, subtaskID, input.Title, input.Completed)
+	if err != nil {
+		if errors.Is(err, store.ErrSubtaskNotFound) {
+			return nil, ErrSubtaskNotFound
+		}
+		return nil, err
+	}
+	return subtask, nil
+}
+
+func (s *TaskService) DeleteSubtask(ctx context.Context, userID, workspaceID, projectID, taskID, subtaskID uuid.UUID) error {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+	if _, err := s.getTask(ctx, workspaceID, projectID, taskID); err != nil {
+		return err
+	}
+
+	if err := s.store.Tasks.DeleteSubtask(ctx, taskID, subtaskID); err != nil {
+		if errors.Is(err, store.ErrSubtaskNotFound) {
+			return ErrSubtaskNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *TaskService) checkProject(ctx context.Context, workspaceID, projectID uuid.UUID) error {
+	if _, err := s.store.Projects.GetProject(ctx, workspaceID, projectID); err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// checkRateManager lets the same people who set the project's own rate set
+// task rates: workspace owners and admins, and the project owner.
+func (s *TaskService) checkRateManager(ctx context.Context, role string, userID, workspaceID, projectID uuid.UUID) error {
+	if role == "owner" || role == "admin" {
+		return nil
+	}
+	project, err := s.store.Projects.GetProject(ctx, workspaceID, projectID)
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	if !sameUUID(project.OwnerID, &userID) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// getTask loads a task of a live project. Tasks of deleted projects and tasks
+// addressed through another project are reported as not found.
+func (s *TaskService) getTask(ctx context.Context, workspaceID, projectID, taskID uuid.UUID) (*store.Task, error) {
+	if err := s.checkProject(ctx, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+
+	task, err := s.store.Tasks.GetTask(ctx, workspaceID, taskID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	if task.ProjectID != projectID {
+		return nil, ErrTaskNotFound
+	}
+	return task, nil
+}
+
+func (s *TaskService) updateTask(ctx context.Context, params store.UpdateTaskParams) (*store.Task, error) {
+	task, err := s.store.Tasks.UpdateTask(ctx, params)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// checkAssignee requires the assignee to be an active workspace member.
+func checkAssignee(ctx context.Context, st *store.Store, workspaceID uuid.UUID, assigneeID *uuid.UUID) error {
+	if assigneeID == nil {
+		return nil
+	}
+	if _, err := st.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, *assigneeID); err != nil {
+		if errors.Is(err, store.ErrNotMember) {
+			return ErrNotWorkspaceMember
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *TaskService) publishAssigned(ctx context.Context, userID uuid.UUID, task *store.Task) {
+	publishTaskAssigned(ctx, s.eventBus, userID, task)
+}
+
+func publishTaskAssigned(ctx context.Context, eventBus EventPublisher, userID uuid.UUID, task *store.Task) {
+	if eventBus == nil {
+		return
+	}
+	eventBus.Publish(ctx, events.EventTaskAssigned, userID, map[string]any{
+		"workspace_id": task.WorkspaceID,
+		"project_id":   task.ProjectID,
+		"task_id":      task.ID,
+		"title":        task.Title,
+		"assignee_id":  task.AssigneeID,
+		"due_date":     task.DueDate,
+	})
+}
+
+func taskUpdateParams(task *store.Task) store.UpdateTaskParams {
+	return store.UpdateTaskParams{
+		ID:             task.ID,
+		WorkspaceID:    task.WorkspaceID,
+		Title:          task.Title,
+		Description:    task.Description,
+		Priority:       task.Priority,
+		AssigneeID:     task.AssigneeID,
+		DueDate:        task.DueDate,
+		EstimatedHours: task.EstimatedHours,
+		HourlyRate:     task.HourlyRate,
+		Tags:           task.Tags,
+	}
+}
+
+func canTransitionTask(from, to string) bool {
+	for _, next := range taskTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func sameUUID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+)
+
+var (
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+	ErrDependencyExists   = errors.New("dependency already exists")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrInvalidDependency  = errors.New("a task can only depend on another task of the same project")
+)
+
+type AddTaskDependencyInput struct {
+	DependsOnID uuid.UUID `json:"depends_on_id" validate:"required"`
+}
+
+// TaskDependencies lists the tasks a task is blocked by and the tasks it blocks.
+type TaskDependencies struct {
+	TaskID    uuid.UUID    `json:"task_id"`
+	Blocked   bool         `json:"blocked"`
+	BlockedBy []store.Task `json:"blocked_by"`
+	Blocking  []store.Task `json:"blocking"`
+}
+
+// ScheduledTask is an open task placed on the project schedule. Hours are
+// counted from the schedule start in working hours.
+type ScheduledTask struct {
+	TaskID         uuid.UUID   `json:"task_id"`
+	Title          string      `json:"title"`
+	Status         string      `json:"status"`
+	RemainingHours float64     `json:"remaining_hours"`
+	Estimated      bool        `json:"estimated"`
+	DependsOn      []uuid.UUID `json:"depends_on"`
+	EarliestStart  float64     `json:"earliest_start_hours"`
+	EarliestFinish float64     `json:"earliest_finish_hours"`
+	FinishDate     string      `json:"earliest_finish_date" example:"2026-03-01"`
+	Slack          float64     `json:"slack_hours"`
+	Critical       bool        `json:"critical"`
+}
+
+// ProjectSchedule is the critical path of the open tasks of a project.
+type ProjectSchedule struct {
+	ProjectID          uuid.UUID       `json:"project_id"`
+	StartDate          string          `json:"start_date" example:"2026-01-15"`
+	WorkingHoursPerDay float64         `json:"working_hours_per_day"`
+	TotalHours         float64         `json:"total_hours"`
+	DurationHours      float64         `json:"duration_hours"`
+	EarliestFinish     string          `json:"earliest_finish_date" example:"2026-03-01"`
+	CriticalPath       []uuid.UUID     `json:"critical_path"`
+	UnestimatedTasks   int             `json:"unestimated_tasks"`
+	Tasks              []ScheduledTask `json:"tasks"`
+}
+
+func (s *TaskService) ListTaskDependencies(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID) (*TaskDependencies, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	task, err := s.getTask(ctx, workspaceID, projectID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	blockedBy, err := s.store.Tasks.ListBlockingTasks(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	blocking, err := s.store.Tasks.ListDependentTasks(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TaskDependencies{
+		TaskID:    task.ID,
+		Blocked:   task.Blocked,
+		BlockedBy: blockedBy,
+		Blocking:  blocking,
+	}
+	if result.BlockedBy == nil {
+		result.BlockedBy = []store.Task{}
+	}
+	if result.Blocking == nil {
+		result.Blocking = []store.Task{}
+	}
+	return result, nil
+}
+
+// AddTaskDependency marks the task as blocked by another task of the same
+// project. Dependencies that would close a cycle are rejected.
+func (s *TaskService) AddTaskDependency(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input AddTaskDependencyInput) (*store.TaskDependency, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	task, err := s.getTask(ctx, workspaceID, projectID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if input.DependsOnID == task.ID {
+		return nil, ErrDependencyCycle
+	}
+	if _, err := s.getTask(ctx, workspaceID, projectID, input.DependsOnID); err != nil {
+		if errors.Is(err, ErrTaskNotFound) {
+			return nil, ErrInvalidDependency
+		}
+		return nil, err
+	}
+
+	var dependency *store.TaskDependency
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := tx.Tasks.LockProjectDependencies(ctx, projectID); err != nil {
+			return err
+		}
+
+		cycle, err := tx.Tasks.DependencyCreatesCycle(ctx, task.ID, input.DependsOnID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrDependencyCycle
+		}
+
+		dependency, err = tx.Tasks.AddTaskDependency(ctx, projectID, task.ID, input.DependsOnID, userID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrDependencyExists) {
+			return nil, ErrDependencyExists
+		}
+		return nil, err
+	}
+
+	return dependency, nil
+}
+
+func (s *TaskService) RemoveTaskDependency(ctx context.Context, userID, workspaceID, projectID, taskID, dependsOnID uuid.UUID) error {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+	if _, err := s.getTask(ctx, workspaceID, projectID, taskID); err != nil {
+		return err
+	}
+
+	if err := s.store.Tasks.RemoveTaskDependency(ctx, taskID, dependsOnID); err != nil {
+		if errors.Is(err, store.ErrDependencyNotFound) {
+			return ErrDependencyNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetProjectSchedule computes the critical path of the open tasks of the
+// project from their remaining estimated hours. Work starts at the later of the
+// project start date and today in the workspace timezone, and a day holds the
+// workspace's working hours, Monday to Friday.
+func (s *TaskService) GetProjectSchedule(ctx context.Context, userID, workspaceID, projectID uuid.UUID) (*ProjectSchedule, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	project, err := s.store.Projects.GetProject(ctx, workspaceID, projectID)
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.store.Tasks.ListOpenProjectTasks(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := s.store.Tasks.ListProjectDependencies(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := workspaceToday(settings.Settings.Timezone)
+	if project.StartDate != nil && project.StartDate.After(start) {
+		start = *project.StartDate
+	}
+
+	schedule := scheduleTasks(tasks, dependencies)
+	schedule.ProjectID = projectID
+	schedule.StartDate = start.Format(dateLayout)
+	schedule.WorkingHoursPerDay = settings.Settings.WorkingHoursPerDay
+
+	hoursPerDay := settings.Settings.WorkingHoursPerDay
+	schedule.EarliestFinish = addWorkingHours(start, schedule.DurationHours, hoursPerDay).Format(dateLayout)
+	for i := range schedule.Tasks {
+		schedule.Tasks[i].FinishDate = addWorkingHours(start, schedule.Tasks[i].EarliestFinish, hoursPerDay).Format(dateLayout)
+	}
+
+	return schedule, nil
+}
+
+// scheduleTasks runs the critical path method over the tasks. Durations are
+// handled in hundredths of an hour so slack comparisons are exact. Blockers
+// that are already done are not part of tasks and impose no constraint.
+func scheduleTasks(tasks []store.Task, dependencies []store.TaskDependency) *ProjectSchedule {
+	index := make(map[uuid.UUID]int, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+
+	n := len(tasks)
+	duration := make([]int64, n)
+	preds := make([][]int, n)
+	succs := make([][]int, n)
+	schedule := &ProjectSchedule{
+		CriticalPath: []uuid.UUID{},
+		Tasks:        make([]ScheduledTask, n),
+	}
+
+	for i, task := range tasks {
+		remaining := 0.0
+		if task.EstimatedHours != nil {
+			remaining = math.Max(*task.EstimatedHours-task.LoggedHours, 0)
+		} else {
+			schedule.UnestimatedTasks++
+		}
+		duration[i] = int64(math.Round(remaining * 100))
+		schedule.TotalHours += remaining
+		schedule.Tasks[i] = ScheduledTask{
+			TaskID:         task.ID,
+			Title:          task.Title,
+			Status:         task.Status,
+			RemainingHours: remaining,
+			Estimated:      task.EstimatedHours != nil,
+			DependsOn:      []uuid.UUID{},
+		}
+	}
+
+	for _, dependency := range dependencies {
+		from, okFrom := index[dependency.DependsOnID]
+		to, okTo := index[dependency.TaskID]
+		if !okFrom || !okTo {
+			continue
+		}
+		preds[to] = append(preds[to], from)
+		succs[from] = append(succs[from], to)
+		schedule.Tasks[to].DependsOn = append(schedule.Tasks[to].DependsOn, dependency.DependsOnID)
+	}
+
+	// Kahn's algorithm; cycles are rejected on insert so every task is reached.
+	order := make([]int, 0, n)
+	inDegree := make([]int, n)
+	for i := range tasks {
+		inDegree[i] = len(preds[i])
+		if inDegree[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	for head := 0; head < len(order); head++ {
+		for _, next := range succs[order[head]] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				order = append(order, next)
+			}
+		}
+	}
+
+	earliestStart := make([]int64, n)
+	earliestFinish := make([]int64, n)
+	var finish int64
+	for _, i := range order {
+		for _, p := range preds[i] {
+			earliestStart[i] = max(earliestStart[i], earliestFinish[p])
+		}
+		earliestFinish[i] = earliestStart[i] + duration[i]
+		finish = max(finish, earliestFinish[i])
+	}
+
+	latestFinish := make([]int64, n)
+	for k := len(order) - 1; k >= 0; k-- {
+		i := order[k]
+		latestFinish[i] = finish
+		for _, next := range succs[i] {
+			latestFinish[i] = min(latestFinish[i], latestFinish[next]-duration[next])
+		}
+	}
+
+	end := -1
+	for _, i := range order {
+		slack := latestFinish[i] - earliestFinish[i]
+		schedule.Tasks[i].EarliestStart = float64(earliestStart[i]) / 100
+		schedule.Tasks[i].EarliestFinish = float64(earliestFinish[i]) / 100
+		schedule.Tasks[i].Slack = float64(slack) / 100
+		schedule.Tasks[i].Critical = slack == 0
+		if end < 0 && earliestFinish[i] == finish && len(succs[i]) == 0 {
+			end = i
+		}
+	}
+	schedule.DurationHours = float64(finish) / 100
+
+	// Walk back from the last critical task through critical predecessors that
+	// finish exactly when it starts.
+	var path []int
+	for current := end; current >= 0; {
+		path = append(path, current)
+		next := -1
+		for _, p := range preds[current] {
+			if schedule.Tasks[p].Critical && earliestFinish[p] == earliestStart[current] {
+				next = p
+				break
+			}
+		}
+		current = next
+	}
+	for k := len(path) - 1; k >= 0; k-- {
+		schedule.CriticalPath = append(schedule.CriticalPath, tasks[path[k]].ID)
+	}
+
+	return schedule
+}
+
+// addWorkingHours returns the working day on which hours of work starting on
+// start are finished. Work that fits in the first day finishes on start.
+func addWorkingHours(start time.Time, hours, hoursPerDay float64) time.Time {
+	day := start
+	for isWeekend(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	if hoursPerDay <= 0 {
+		return day
+	}
+
+	days := int(math.Ceil(hours/hoursPerDay - 1e-9))
+	for counted := 1; counted < days; {
+		day = day.AddDate(0, 0, 1)
+		if !isWeekend(day) {
+			counted++
+		}
+	}
+	return day
+}
+
+func isWeekend(day time.Time) bool {
+	return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+}
+
+// workspaceToday returns the current date in the workspace timezone as a UTC
+// midnight, matching how DATE columns are scanned.
+func workspaceToday(timezone string) time.Time {
+	now := time.Now().In(workspaceLocation(timezone))
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// workspaceLocation loads the workspace timezone, falling back to UTC for
+// names the host does not know.
+func workspaceLocation(timezone string) *time.Location {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
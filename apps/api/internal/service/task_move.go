@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/rank"
+)
+
+var ErrInvalidMove = errors.New("invalid task move")
+
+// maxTaskRankLength is the rank length above which a board column is
+// rebalanced. Repeated moves into the same gap grow a rank by about one
+// character every six moves.
+const maxTaskRankLength = 16
+
+// MoveTaskInput places a task in a board column. BeforeID is the task that
+// should end up directly above it and AfterID the one directly below it. With
+// only one neighbour the other side is looked up, and with neither the task
+// goes to the bottom of the column. Status defaults to the task's current one.
+type MoveTaskInput struct {
+	Status   *string    `json:"status,omitempty" validate:"omitempty,oneof=todo in-progress review done"`
+	BeforeID *uuid.UUID `json:"before_id,omitempty"`
+	AfterID  *uuid.UUID `json:"after_id,omitempty"`
+}
+
+// MoveTasksInput moves several tasks as one block, keeping the order of TaskIDs.
+// Status defaults to the status of the first task.
+type MoveTasksInput struct {
+	TaskIDs  []uuid.UUID `json:"task_ids" validate:"required,min=1,max=100,unique"`
+	Status   *string     `json:"status,omitempty" validate:"omitempty,oneof=todo in-progress review done"`
+	BeforeID *uuid.UUID  `json:"before_id,omitempty"`
+	AfterID  *uuid.UUID  `json:"after_id,omitempty"`
+}
+
+func (s *TaskService) MoveTask(ctx context.Context, userID, workspaceID, projectID, taskID uuid.UUID, input MoveTaskInput) (*store.Task, error) {
+	tasks, err := s.MoveTasks(ctx, userID, workspaceID, projectID, MoveTasksInput{
+		TaskIDs:  []uuid.UUID{taskID},
+		Status:   input.Status,
+		BeforeID: input.BeforeID,
+		AfterID:  input.AfterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tasks[0], nil
+}
+
+// MoveTasks gives every moved task a new rank between the neighbours, so each
+// task is a single row update and the rest of the column is left alone. Status
+// changes follow the same workflow as UpdateTaskStatus.
+func (s *TaskService) MoveTasks(ctx context.Context, userID, workspaceID, projectID uuid.UUID, input MoveTasksInput) ([]store.Task, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	moving := make([]*store.Task, len(input.TaskIDs))
+	for i, id := range input.TaskIDs {
+		task, err := s.getTask(ctx, workspaceID, projectID, id)
+		if err != nil {
+			return nil, err
+		}
+		moving[i] = task
+	}
+
+	status := moving[0].Status
+	if input.Status != nil {
+		status = *input.Status
+	}
+	for _, task := range moving {
+		if task.Status != status && !canTransitionTask(task.Status, status) {
+			return nil, ErrInvalidStatusTransition
+		}
+	}
+
+	moved := make([]store.Task, 0, len(moving))
+	err := s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := tx.Tasks.LockTaskColumn(ctx, projectID, status); err != nil {
+			return err
+		}
+
+		lower, upper, err := moveBounds(ctx, tx, workspaceID, projectID, status, input)
+		if err != nil {
+			return err
+		}
+
+		ranks, err := rank.NBetween(lower, upper, len(moving))
+		if err != nil {
+			return ErrInvalidMove
+		}
+
+		for i, task := range moving {
+			updated, err := tx.Tasks.MoveTask(ctx, workspaceID, task.ID, status, ranks[i])
+			if err != nil {
+				return err
+			}
+			moved = append(moved, *updated)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		for i, task := range moving {
+			if task.Status == status {
+				continue
+			}
+			s.eventBus.Publish(ctx, events.EventTaskStatusChanged, userID, map[string]any{
+				"workspace_id": workspaceID,
+				"project_id":   projectID,
+				"task_id":      task.ID,
+				"title":        task.Title,
+				"from":         task.Status,
+				"to":           status,
+				"assignee_id":  moved[i].AssigneeID,
+			})
+		}
+	}
+
+	return moved, nil
+}
+
+// RebalanceTaskRanks rewrites every board column holding an overly long rank
+// with short, evenly spaced ranks that keep the current order. It returns the
+// number of columns rebalanced.
+func (s *TaskService) RebalanceTaskRanks(ctx context.Context) (int, error) {
+	columns, err := s.store.Tasks.ListLongRankColumns(ctx, maxTaskRankLength)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, column := range columns {
+		err := s.store.ExecTx(ctx, func(tx *store.Store) error {
+			if err := tx.Tasks.LockTaskColumn(ctx, column.ProjectID, column.Status); err != nil {
+				return err
+			}
+			ids, err := tx.Tasks.ListColumnTaskIDs(ctx, column.ProjectID, column.Status)
+			if err != nil {
+				return err
+			}
+			return tx.Tasks.SetTaskRanks(ctx, ids, rank.Spread(len(ids)))
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		s.logger.Info().
+			Str("project_id", column.ProjectID.String()).
+			Str("status", column.Status).
+			Msg("rebalanced task ranks")
+	}
+
+	return len(columns), nil
+}
+
+// moveBounds resolves the ranks the moved tasks go between. An empty upper
+// bound means the bottom of the column.
+func moveBounds(ctx context.Context, tx *store.Store, workspaceID, projectID uuid.UUID, status string, input MoveTasksInput) (lower, upper string, err error) {
+	if input.BeforeID == nil && input.AfterID == nil {
+		lower, err = tx.Tasks.LastTaskRank(ctx, projectID, status, input.TaskIDs)
+		return lower, "", err
+	}
+
+	if input.BeforeID != nil {
+		if lower, err = neighbourRank(ctx, tx, workspaceID, projectID, status, *input.BeforeID, input.TaskIDs); err != nil {
+			return "", "", err
+		}
+	}
+	if input.AfterID != nil {
+		if upper, err = neighbourRank(ctx, tx, workspaceID, projectID, status, *input.AfterID, input.TaskIDs); err != nil {
+			return "", "", err
+		}
+	}
+
+	switch {
+	case input.AfterID == nil:
+		upper, err = tx.Tasks.AdjacentTaskRank(ctx, projectID, status, lower, false, input.TaskIDs)
+	case input.BeforeID == nil:
+		lower, err = tx.Tasks.AdjacentTaskRank(ctx, projectID, status, upper, true, input.TaskIDs)
+	case lower >= upper:
+		err = ErrInvalidMove
+	}
+	return lower, upper, err
+}
+
+// neighbourRank returns the rank of a neighbour, which must sit in the target
+// column and not be one of the moved tasks.
+func neighbourRank(ctx context.Context, tx *store.Store, workspaceID, projectID uuid.UUID, status string, neighbourID uuid.UUID, moving []uuid.UUID) (string, error) {
+	for _, id := range moving {
+		if id == neighbourID {
+			return "", ErrInvalidMove
+		}
+	}
+
+	neighbour, err := tx.Tasks.GetTask(ctx, workspaceID, neighbourID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			return "", ErrInvalidMove
+		}
+		return "", err
+	}
+	if neighbour.ProjectID != projectID || neighbour.Status != status {
+		return "", ErrInvalidMove
+	}
+	return neighbour.Rank, nil
+}
+
+// appendTaskRank locks the column and returns a rank below its last task. It
+// must run inside a transaction.
+func appendTaskRank(ctx context.Context, tx *store.Store, projectID uuid.UUID, status string) (string, error) {
+	if err := tx.Tasks.LockTaskColumn(ctx, projectID, status); err != nil {
+		return "", err
+	}
+	last, err := tx.Tasks.LastTaskRank(ctx, projectID, status, nil)
+	if err != nil {
+		return "", err
+	}
+	return rank.Between(last, "")
+}
@@ -0,0 +1,745 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/rrule"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrRecurrenceNotFound         = errors.New("recurring task not found")
+	ErrInvalidRecurrenceRule      = rrule.ErrInvalidRule
+	ErrUnsupportedRecurrenceRule  = rrule.ErrUnsupportedRule
+	ErrNoUpcomingOccurrences      = errors.New("recurrence rule has no upcoming occurrences")
+	ErrInvalidOccurrence          = errors.New("date is not an upcoming occurrence of the recurring task")
+	ErrOccurrenceOverrideNotFound = errors.New("occurrence override not found")
+)
+
+const (
+	// maxOccurrencesPerRun caps how many overdue occurrences of one recurrence
+	// are created in a single pass, so a long outage does not flood a board.
+	maxOccurrencesPerRun = 31
+	recurrenceBatchSize  = 500
+
+	defaultOccurrencePreview = 10
+	maxOccurrencePreview     = 50
+)
+
+type CreateRecurringTaskInput struct {
+	Title          string     `json:"title" validate:"required,min=1,max=255"`
+	Description    *string    `json:"description,omitempty" validate:"omitempty,max=10000"`
+	Priority       *string    `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	AssigneeID     *uuid.UUID `json:"assignee_id,omitempty"`
+	EstimatedHours *float64   `json:"estimated_hours,omitempty" validate:"omitempty,min=0,max=999999"`
+	Tags           []string   `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+	RRule          string     `json:"rrule" validate:"required,max=500"`
+	StartDate      *string    `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// UpdateRecurringTaskInput only changes the fields that are set and applies to
+// occurrences that have not been created yet. An empty assignee_id clears it.
+type UpdateRecurringTaskInput struct {
+	Title          *string  `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	Description    *string  `json:"description,omitempty" validate:"omitempty,max=10000"`
+	Priority       *string  `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	AssigneeID     *string  `json:"assignee_id,omitempty" validate:"omitempty,uuid"`
+	EstimatedHours *float64 `json:"estimated_hours,omitempty" validate:"omitempty,min=0,max=999999"`
+	Tags           []string `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+	RRule          *string  `json:"rrule,omitempty" validate:"omitempty,max=500"`
+	StartDate      *string  `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// UpdateOccurrenceInput skips a single upcoming occurrence or overrides the
+// task it will create. Unset fields keep the template's value.
+type UpdateOccurrenceInput struct {
+	Skipped        bool       `json:"skipped"`
+	Title          *string    `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	Description    *string    `json:"description,omitempty" validate:"omitempty,max=10000"`
+	Priority       *string    `json:"priority,omitempty" validate:"omitempty,oneof=low medium high urgent"`
+	AssigneeID     *uuid.UUID `json:"assignee_id,omitempty"`
+	EstimatedHours *float64   `json:"estimated_hours,omitempty" validate:"omitempty,min=0,max=999999"`
+	DueDate        *string    `json:"due_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// RecurrenceOccurrence is an upcoming occurrence with its override applied.
+type RecurrenceOccurrence struct {
+	Date           string     `json:"date" example:"2026-03-01"`
+	DueDate        string     `json:"due_date" example:"2026-03-01"`
+	Skipped        bool       `json:"skipped"`
+	Modified       bool       `json:"modified"`
+	Title          string     `json:"title"`
+	Priority       string     `json:"priority"`
+	AssigneeID     *uuid.UUID `json:"assignee_id"`
+	EstimatedHours *float64   `json:"estimated_hours"`
+}
+
+type RecurringTask interface {
+	CreateRecurringTask(ctx context.Context, userID, workspaceID, projectID uuid.UUID, input CreateRecurringTaskInput) (*store.TaskRecurrence, error)
+	GetRecurringTask(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID) (*store.TaskRecurrence, error)
+	ListRecurringTasks(ctx context.Context, userID, workspaceID, projectID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.TaskRecurrence], error)
+	UpdateRecurringTask(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID, input UpdateRecurringTaskInput) (*store.TaskRecurrence, error)
+	DeleteRecurringTask(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID) error
+
+	ListOccurrences(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID, limit int) ([]RecurrenceOccurrence, error)
+	UpdateOccurrence(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID, date string, input UpdateOccurrenceInput) (*store.RecurrenceException, error)
+	ResetOccurrence(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID, date string) error
+
+	MaterializeDueOccurrences(ctx context.Context) (int, error)
+}
+
+type RecurringTaskService struct {
+	store    *store.Store
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewRecurringTaskService(store *store.Store, eventBus EventPublisher, logger zerolog.Logger) *RecurringTaskService {
+	return &RecurringTaskService{
+		store:    store,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "recurring_task_service").Logger(),
+	}
+}
+
+var _ RecurringTask = (*RecurringTaskService)(nil)
+
+// CreateRecurringTask stores the template and creates its first occurrence.
+// Without a start date the rule starts today in the workspace timezone.
+func (s *RecurringTaskService) CreateRecurringTask(ctx context.Context, userID, workspaceID, projectID uuid.UUID, input CreateRecurringTaskInput) (*store.TaskRecurrence, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkProject(ctx, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+
+	rule, err := rrule.Parse(input.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	today, err := s.today(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	start := today
+	if input.StartDate != nil {
+		parsed, err := parseDate(input.StartDate)
+		if err != nil {
+			return nil, err
+		}
+		if parsed != nil {
+			start = *parsed
+		}
+	}
+
+	next, ok := rule.After(start, later(start, today))
+	if !ok {
+		return nil, ErrNoUpcomingOccurrences
+	}
+
+	params := store.CreateTaskRecurrenceParams{
+		WorkspaceID:    workspaceID,
+		ProjectID:      projectID,
+		Title:          strings.TrimSpace(input.Title),
+		Description:    input.Description,
+		Priority:       "medium",
+		AssigneeID:     input.AssigneeID,
+		EstimatedHours: input.EstimatedHours,
+		Tags:           normalizeTags(input.Tags),
+		RRule:          rule.String(),
+		StartDate:      start,
+		NextDate:       &next,
+		CreatedBy:      userID,
+	}
+	if input.Priority != nil {
+		params.Priority = *input.Priority
+	}
+	if err := checkAssignee(ctx, s.store, workspaceID, params.AssigneeID); err != nil {
+		return nil, err
+	}
+
+	recurrence, err := s.store.TaskRecurrences.CreateRecurrence(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.materializeAndReload(ctx, recurrence)
+}
+
+func (s *RecurringTaskService) GetRecurringTask(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID) (*store.TaskRecurrence, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	return s.getRecurrence(ctx, workspaceID, projectID, recurrenceID)
+}
+
+func (s *RecurringTaskService) ListRecurringTasks(ctx context.Context, userID, workspaceID, projectID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.TaskRecurrence], error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkProject(ctx, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+
+	recurrences, total, err := s.store.TaskRecurrences.ListRecurrences(ctx, workspaceID, projectID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.BuildFilterResponse(recurrences, total, filters), nil
+}
+
+// UpdateRecurringTask changes the template. A new rule or start date
+// reschedules the next occurrence to the first date of the new schedule after
+// the last created occurrence, and never before today. The recurrence is
+// locked while it changes, so the scheduler cannot move its next date
+// underneath.
+func (s *RecurringTaskService) UpdateRecurringTask(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID, input UpdateRecurringTaskInput) (*store.TaskRecurrence, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkProject(ctx, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+
+	var updated *store.TaskRecurrence
+	err := s.store.ExecTx(ctx, func(tx *store.Store) error {
+		recurrence, err := tx.TaskRecurrences.GetRecurrenceForUpdate(ctx, workspaceID, recurrenceID)
+		if err != nil {
+			return err
+		}
+		if recurrence.ProjectID != projectID {
+			return store.ErrRecurrenceNotFound
+		}
+
+		params := store.UpdateTaskRecurrenceParams{
+			ID:             recurrence.ID,
+			WorkspaceID:    workspaceID,
+			Title:          recurrence.Title,
+			Description:    recurrence.Description,
+			Priority:       recurrence.Priority,
+			AssigneeID:     recurrence.AssigneeID,
+			EstimatedHours: recurrence.EstimatedHours,
+			Tags:           recurrence.Tags,
+			RRule:          recurrence.RRule,
+			StartDate:      recurrence.StartDate,
+		}
+		if input.Title != nil {
+			params.Title = strings.TrimSpace(*input.Title)
+		}
+		if input.Description != nil {
+			params.Description = input.Description
+		}
+		if input.Priority != nil {
+			params.Priority = *input.Priority
+		}
+		if input.AssigneeID != nil {
+			if params.AssigneeID, err = parseUUIDFilter(*input.AssigneeID); err != nil {
+				return err
+			}
+			if err := checkAssignee(ctx, tx, workspaceID, params.AssigneeID); err != nil {
+				return err
+			}
+		}
+		if input.EstimatedHours != nil {
+			params.EstimatedHours = input.EstimatedHours
+		}
+		if input.Tags != nil {
+			params.Tags = normalizeTags(input.Tags)
+		}
+
+		if input.RRule != nil || input.StartDate != nil {
+			rule, err := rrule.Parse(params.RRule)
+			if input.RRule != nil {
+				rule, err = rrule.Parse(*input.RRule)
+			}
+			if err != nil {
+				return err
+			}
+			params.RRule = rule.String()
+
+			if input.StartDate != nil {
+				parsed, err := parseDate(input.StartDate)
+				if err != nil {
+					return err
+				}
+				if parsed != nil {
+					params.StartDate = *parsed
+				}
+			}
+
+			from, err := s.today(ctx, workspaceID)
+			if err != nil {
+				return err
+			}
+			from = later(from, params.StartDate)
+			if recurrence.LastOccurrenceDate != nil {
+				from = later(from, recurrence.LastOccurrenceDate.AddDate(0, 0, 1))
+			}
+			next, ok := rule.After(params.StartDate, from)
+			if !ok {
+				return ErrNoUpcomingOccurrences
+			}
+			if err := tx.TaskRecurrences.SetRecurrenceNextDate(ctx, recurrence.ID, &next); err != nil {
+				return err
+			}
+		}
+
+		updated, err = tx.TaskRecurrences.UpdateRecurrence(ctx, params)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrRecurrenceNotFound) {
+			return nil, ErrRecurrenceNotFound
+		}
+		return nil, err
+	}
+
+	return s.materializeAndReload(ctx, updated)
+}
+
+// DeleteRecurringTask stops the schedule and keeps the tasks already created.
+// It is limited to workspace admins, the project owner and the creator.
+func (s *RecurringTaskService) DeleteRecurringTask(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID) error {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.store.Projects.GetProject(ctx, workspaceID, projectID)
+	if err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+
+	recurrence, err := s.getRecurrence(ctx, workspaceID, projectID, recurrenceID)
+	if err != nil {
+		return err
+	}
+
+	if role != "owner" && role != "admin" && !sameUUID(project.OwnerID, &userID) && !sameUUID(recurrence.CreatedBy, &userID) {
+		return ErrForbidden
+	}
+
+	if err := s.store.TaskRecurrences.DeleteRecurrence(ctx, workspaceID, recurrence.ID); err != nil {
+		if errors.Is(err, store.ErrRecurrenceNotFound) {
+			return ErrRecurrenceNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ListOccurrences previews the occurrences that have not been created yet,
+// including skipped ones.
+func (s *RecurringTaskService) ListOccurrences(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID, limit int) ([]RecurrenceOccurrence, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	recurrence, err := s.getRecurrence(ctx, workspaceID, projectID, recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := []RecurrenceOccurrence{}
+	if recurrence.NextDate == nil {
+		return occurrences, nil
+	}
+	if limit <= 0 {
+		limit = defaultOccurrencePreview
+	}
+	limit = min(limit, maxOccurrencePreview)
+
+	rule, err := rrule.Parse(recurrence.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := s.store.TaskRecurrences.ListRecurrenceExceptions(ctx, recurrence.ID, *recurrence.NextDate)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[time.Time]*store.RecurrenceException, len(exceptions))
+	for i := range exceptions {
+		byDate[rrule.Date(exceptions[i].OccurrenceDate)] = &exceptions[i]
+	}
+
+	it := rule.Iterator(recurrence.StartDate)
+	for len(occurrences) < limit {
+		date, ok := it.Next()
+		if !ok {
+			break
+		}
+		if date.Before(*recurrence.NextDate) {
+			continue
+		}
+
+		exception := byDate[date]
+		params := occurrenceTaskParams(recurrence, date, exception)
+		occurrence := RecurrenceOccurrence{
+			Date:           date.Format(dateLayout),
+			DueDate:        params.DueDate.Format(dateLayout),
+			Modified:       exception != nil,
+			Title:          params.Title,
+			Priority:       params.Priority,
+			AssigneeID:     params.AssigneeID,
+			EstimatedHours: params.EstimatedHours,
+		}
+		if exception != nil {
+			occurrence.Skipped = exception.Skipped
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	return occurrences, nil
+}
+
+// UpdateOccurrence skips or edits a single occurrence that has not been created
+// yet. Occurrences that already exist are edited as tasks.
+func (s *RecurringTaskService) UpdateOccurrence(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID, date string, input UpdateOccurrenceInput) (*store.RecurrenceException, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	recurrence, err := s.getRecurrence(ctx, workspaceID, projectID, recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrenceDate, err := s.upcomingOccurrence(recurrence, date)
+	if err != nil {
+		return nil, err
+	}
+
+	params := store.UpsertRecurrenceExceptionParams{
+		RecurrenceID:   recurrence.ID,
+		OccurrenceDate: occurrenceDate,
+		Skipped:        input.Skipped,
+		Description:    input.Description,
+		Priority:       input.Priority,
+		AssigneeID:     input.AssigneeID,
+		EstimatedHours: input.EstimatedHours,
+		CreatedBy:      userID,
+	}
+	if input.Title != nil {
+		title := strings.TrimSpace(*input.Title)
+		params.Title = &title
+	}
+	if params.DueDate, err = parseDate(input.DueDate); err != nil {
+		return nil, err
+	}
+	if err := checkAssignee(ctx, s.store, workspaceID, params.AssigneeID); err != nil {
+		return nil, err
+	}
+
+	exception, err := s.store.TaskRecurrences.UpsertRecurrenceException(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skipping the next occurrence may make the one after it due right away.
+	if _, err := s.materialize(ctx, recurrence.ID); err != nil {
+		return nil, err
+	}
+
+	return exception, nil
+}
+
+// ResetOccurrence drops the override of an upcoming occurrence, restoring it to
+// the template.
+func (s *RecurringTaskService) ResetOccurrence(ctx context.Context, userID, workspaceID, projectID, recurrenceID uuid.UUID, date string) error {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+
+	recurrence, err := s.getRecurrence(ctx, workspaceID, projectID, recurrenceID)
+	if err != nil {
+		return err
+	}
+
+	occurrenceDate, err := s.upcomingOccurrence(recurrence, date)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.TaskRecurrences.DeleteRecurrenceException(ctx, recurrence.ID, occurrenceDate); err != nil {
+		if errors.Is(err, store.ErrRecurrenceExceptionNotFound) {
+			return ErrOccurrenceOverrideNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// MaterializeDueOccurrences creates the tasks of every recurrence whose next
+// occurrence date has arrived in its workspace timezone, or whose previous
+// occurrences are all done. It is safe to run on several replicas at once.
+func (s *RecurringTaskService) MaterializeDueOccurrences(ctx context.Context) (int, error) {
+	// Workspaces ahead of UTC may already be on the next day.
+	through := rrule.Date(time.Now().UTC()).AddDate(0, 0, 1)
+
+	ids, err := s.store.TaskRecurrences.ListDueRecurrenceIDs(ctx, through, recurrenceBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return created, err
+		}
+		n, err := s.materialize(ctx, id)
+		if err != nil {
+			s.logger.Error().Err(err).Str("recurrence_id", id.String()).Msg("failed to create recurring task occurrences")
+			continue
+		}
+		created += n
+	}
+
+	if created > 0 {
+		s.logger.Info().Int("tasks", created).Msg("created recurring task occurrences")
+	}
+	return created, nil
+}
+
+// materialize creates the due occurrences of one recurrence and advances its
+// next date. A recurrence locked by another replica is left to that replica.
+func (s *RecurringTaskService) materialize(ctx context.Context, recurrenceID uuid.UUID) (int, error) {
+	var created []store.Task
+	var actorID uuid.UUID
+
+	err := s.store.ExecTx(ctx, func(tx *store.Store) error {
+		recurrence, err := tx.TaskRecurrences.LockRecurrence(ctx, recurrenceID)
+		if err != nil {
+			if errors.Is(err, store.ErrRecurrenceNotFound) {
+				return nil
+			}
+			return err
+		}
+		if recurrence.NextDate == nil {
+			return nil
+		}
+		if _, err := tx.Projects.GetProject(ctx, recurrence.WorkspaceID, recurrence.ProjectID); err != nil {
+			if errors.Is(err, store.ErrProjectNotFound) {
+				return nil
+			}
+			return err
+		}
+		if recurrence.CreatedBy != nil {
+			actorID = *recurrence.CreatedBy
+		}
+
+		settings, err := getWorkspaceSettings(ctx, tx, recurrence.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		today := workspaceToday(settings.Settings.Timezone)
+
+		rule, err := rrule.Parse(recurrence.RRule)
+		if err != nil {
+			return err
+		}
+
+		open := recurrence.OpenOccurrences > 0
+		next := recurrence.NextDate
+		for n := 0; n < maxOccurrencesPerRun && next != nil; n++ {
+			if open && next.After(today) {
+				break
+			}
+
+			exception, err := tx.TaskRecurrences.GetRecurrenceException(ctx, recurrence.ID, *next)
+			if err != nil && !errors.Is(err, store.ErrRecurrenceExceptionNotFound) {
+				return err
+			}
+			// An occurrence that already has a task, say because the
+			// schedule was moved back onto it, is only skipped.
+			exists, err := tx.TaskRecurrences.RecurrenceOccurrenceExists(ctx, recurrence.ID, *next)
+			if err != nil {
+				return err
+			}
+			if !exists && (exception == nil || !exception.Skipped) {
+				task, err := createOccurrence(ctx, tx, recurrence, *next, exception)
+				if err != nil {
+					return err
+				}
+				created = append(created, *task)
+				open = true
+			}
+
+			following, ok := rule.After(recurrence.StartDate, next.AddDate(0, 0, 1))
+			next = nil
+			if ok {
+				next = &following
+			}
+		}
+
+		if sameDate(next, recurrence.NextDate) {
+			return nil
+		}
+		return tx.TaskRecurrences.SetRecurrenceNextDate(ctx, recurrence.ID, next)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range created {
+		if created[i].AssigneeID != nil {
+			publishTaskAssigned(ctx, s.eventBus, actorID, &created[i])
+		}
+	}
+	return len(created), nil
+}
+
+func (s *RecurringTaskService) materializeAndReload(ctx context.Context, recurrence *store.TaskRecurrence) (*store.TaskRecurrence, error) {
+	n, err := s.materialize(ctx, recurrence.ID)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return recurrence, nil
+	}
+	return s.store.TaskRecurrences.GetRecurrence(ctx, recurrence.WorkspaceID, recurrence.ID)
+}
+
+// createOccurrence adds the task for one occurrence at the bottom of the todo
+// column. An assignee who has left the workspace is dropped.
+func createOccurrence(ctx context.Context, tx *store.Store, recurrence *store.TaskRecurrence, date time.Time, exception *store.RecurrenceException) (*store.Task, error) {
+	params := occurrenceTaskParams(recurrence, date, exception)
+	if err := checkAssignee(ctx, tx, recurrence.WorkspaceID, params.AssigneeID); err != nil {
+		if !errors.Is(err, ErrNotWorkspaceMember) {
+			return nil, err
+		}
+		params.AssigneeID = nil
+	}
+
+	var err error
+	if params.Rank, err = appendTaskRank(ctx, tx, recurrence.ProjectID, params.Status); err != nil {
+		return nil, err
+	}
+	return tx.Tasks.CreateTask(ctx, params)
+}
+
+// occurrenceTaskParams builds the task of an occurrence from the template and
+// the occurrence's override. The task is due on the occurrence date unless the
+// override moves it.
+func occurrenceTaskParams(recurrence *store.TaskRecurrence, date time.Time, exception *store.RecurrenceException) store.CreateTaskParams {
+	dueDate := date
+	params := store.CreateTaskParams{
+		WorkspaceID:    recurrence.WorkspaceID,
+		ProjectID:      recurrence.ProjectID,
+		Title:          recurrence.Title,
+		Description:    recurrence.Description,
+		Status:         "todo",
+		Priority:       recurrence.Priority,
+		AssigneeID:     recurrence.AssigneeID,
+		DueDate:        &dueDate,
+		EstimatedHours: recurrence.EstimatedHours,
+		Tags:           recurrence.Tags,
+		CreatedBy:      recurrence.CreatedBy,
+		RecurrenceID:   &recurrence.ID,
+		OccurrenceDate: &date,
+	}
+	if exception == nil {
+		return params
+	}
+
+	if exception.Title != nil {
+		params.Title = *exception.Title
+	}
+	if exception.Description != nil {
+		params.Description = exception.Description
+	}
+	if exception.Priority != nil {
+		params.Priority = *exception.Priority
+	}
+	if exception.AssigneeID != nil {
+		params.AssigneeID = exception.AssigneeID
+	}
+	if exception.EstimatedHours != nil {
+		params.EstimatedHours = exception.EstimatedHours
+	}
+	if exception.DueDate != nil {
+		params.DueDate = exception.DueDate
+	}
+	return params
+}
+
+// upcomingOccurrence parses date and requires it to be an occurrence of the
+// rule that has not been created yet.
+func (s *RecurringTaskService) upcomingOccurrence(recurrence *store.TaskRecurrence, date string) (time.Time, error) {
+	parsed, err := time.Parse(dateLayout, date)
+	if err != nil || recurrence.NextDate == nil || parsed.Before(*recurrence.NextDate) {
+		return time.Time{}, ErrInvalidOccurrence
+	}
+
+	rule, err := rrule.Parse(recurrence.RRule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !rule.Includes(recurrence.StartDate, parsed) {
+		return time.Time{}, ErrInvalidOccurrence
+	}
+	return parsed, nil
+}
+
+func (s *RecurringTaskService) checkProject(ctx context.Context, workspaceID, projectID uuid.UUID) error {
+	if _, err := s.store.Projects.GetProject(ctx, workspaceID, projectID); err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// getRecurrence loads a recurrence of a live project. Recurrences addressed
+// through another project are reported as not found.
+func (s *RecurringTaskService) getRecurrence(ctx context.Context, workspaceID, projectID, recurrenceID uuid.UUID) (*store.TaskRecurrence, error) {
+	if err := s.checkProject(ctx, workspaceID, projectID); err != nil {
+		return nil, err
+	}
+
+	recurrence, err := s.store.TaskRecurrences.GetRecurrence(ctx, workspaceID, recurrenceID)
+	if err != nil {
+		if errors.Is(err, store.ErrRecurrenceNotFound) {
+			return nil, ErrRecurrenceNotFound
+		}
+		return nil, err
+	}
+	if recurrence.ProjectID != projectID {
+		return nil, ErrRecurrenceNotFound
+	}
+	return recurrence, nil
+}
+
+func (s *RecurringTaskService) today(ctx context.Context, workspaceID uuid.UUID) (time.Time, error) {
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return workspaceToday(settings.Settings.Timezone), nil
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
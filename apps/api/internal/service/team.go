@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrTeamNotFound       = errors.New("team not found")
+	ErrTeamNameTaken      = errors.New("team name already taken")
+	ErrNotWorkspaceMember = errors.New("user is not a member of the workspace")
+)
+
+type CreateTeamInput struct {
+	Name        string  `json:"name" validate:"required,min=2,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	RoleGrant   *string `json:"role_grant,omitempty" validate:"omitempty,oneof=admin member"`
+}
+
+// UpdateTeamInput only changes the fields that are set. An empty role_grant
+// removes the team's grant.
+type UpdateTeamInput struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	RoleGrant   *string `json:"role_grant,omitempty" validate:"omitempty,oneof=admin member"`
+}
+
+type Team interface {
+	CreateTeam(ctx context.Context, userID, workspaceID uuid.UUID, input CreateTeamInput) (*store.Team, error)
+	GetTeam(ctx context.Context, userID, workspaceID, teamID uuid.UUID) (*store.Team, error)
+	ListTeams(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Team], error)
+	UpdateTeam(ctx context.Context, userID, workspaceID, teamID uuid.UUID, input UpdateTeamInput) (*store.Team, error)
+	DeleteTeam(ctx context.Context, userID, workspaceID, teamID uuid.UUID) error
+	ListTeamMembers(ctx context.Context, userID, workspaceID, teamID uuid.UUID) ([]store.TeamMember, error)
+	AddTeamMember(ctx context.Context, userID, workspaceID, teamID, targetUserID uuid.UUID) error
+	RemoveTeamMember(ctx context.Context, userID, workspaceID, teamID, targetUserID uuid.UUID) error
+}
+
+type TeamService struct {
+	store  *store.Store
+	logger zerolog.Logger
+}
+
+func NewTeamService(store *store.Store, logger zerolog.Logger) *TeamService {
+	return &TeamService{
+		store:  store,
+		logger: logger.With().Str("component", "team_service").Logger(),
+	}
+}
+
+var _ Team = (*TeamService)(nil)
+
+func (s *TeamService) CreateTeam(ctx context.Context, userID, workspaceID uuid.UUID, input CreateTeamInput) (*store.Team, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	team, err := s.store.Teams.CreateTeam(ctx, store.CreateTeamParams{
+		WorkspaceID: workspaceID,
+		Name:        input.Name,
+		Description: input.Description,
+		RoleGrant:   input.RoleGrant,
+	})
+	if err != nil {
+		return nil, mapTeamError(err)
+	}
+	return team, nil
+}
+
+func (s *TeamService) GetTeam(ctx context.Context, userID, workspaceID, teamID uuid.UUID) (*store.Team, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	team, err := s.store.Teams.GetTeam(ctx, workspaceID, teamID)
+	if err != nil {
+		return nil, mapTeamError(err)
+	}
+	return team, nil
+}
+
+func (s *TeamService) ListTeams(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Team], error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	teams, total, err := s.store.Teams.ListTeams(ctx, workspaceID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.BuildFilterResponse(teams, total, filters), nil
+}
+
+func (s *TeamService) UpdateTeam(ctx context.Context, userID, workspaceID, teamID uuid.UUID, input UpdateTeamInput) (*store.Team, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	team, err := s.store.Teams.GetTeam(ctx, workspaceID, teamID)
+	if err != nil {
+		return nil, mapTeamError(err)
+	}
+
+	params := store.UpdateTeamParams{
+		ID:          team.ID,
+		WorkspaceID: workspaceID,
+		Name:        team.Name,
+		Description: team.Description,
+		RoleGrant:   team.RoleGrant,
+	}
+	if input.Name != nil {
+		params.Name = *input.Name
+	}
+	if input.Description != nil {
+		params.Description = input.Description
+	}
+	if input.RoleGrant != nil {
+		params.RoleGrant = input.RoleGrant
+		if *input.RoleGrant == "" {
+			params.RoleGrant = nil
+		}
+	}
+
+	team, err = s.store.Teams.UpdateTeam(ctx, params)
+	if err != nil {
+		return nil, mapTeamError(err)
+	}
+	return team, nil
+}
+
+func (s *TeamService) DeleteTeam(ctx context.Context, userID, workspaceID, teamID uuid.UUID) error {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+
+	return mapTeamError(s.store.Teams.DeleteTeam(ctx, workspaceID, teamID))
+}
+
+func (s *TeamService) ListTeamMembers(ctx context.Context, userID, workspaceID, teamID uuid.UUID) ([]store.TeamMember, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.store.Teams.GetTeam(ctx, workspaceID, teamID); err != nil {
+		return nil, mapTeamError(err)
+	}
+
+	return s.store.Teams.ListTeamMembers(ctx, teamID)
+}
+
+func (s *TeamService) AddTeamMember(ctx context.Context, userID, workspaceID, teamID, targetUserID uuid.UUID) error {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+
+	if _, err := s.store.Teams.GetTeam(ctx, workspaceID, teamID); err != nil {
+		return mapTeamError(err)
+	}
+
+	if _, err := s.store.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, targetUserID); err != nil {
+		if errors.Is(err, store.ErrNotMember) {
+			return ErrNotWorkspaceMember
+		}
+		return err
+	}
+
+	return s.store.Teams.AddTeamMember(ctx, teamID, targetUserID)
+}
+
+func (s *TeamService) RemoveTeamMember(ctx context.Context, userID, workspaceID, teamID, targetUserID uuid.UUID) error {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return err
+	}
+
+	if _, err := s.store.Teams.GetTeam(ctx, workspaceID, teamID); err != nil {
+		return mapTeamError(err)
+	}
+
+	return s.store.Teams.RemoveTeamMember(ctx, teamID, targetUserID)
+}
+
+func mapTeamError(err error) error {
+	switch {
+	case errors.Is(err, store.ErrTeamNotFound):
+		return ErrTeamNotFound
+	case errors.Is(err, store.ErrTeamNameTaken):
+		return ErrTeamNameTaken
+	}
+	return err
+}
+
+var roleRank = map[string]int{
+	"member": 1,
+	"admin":  2,
+	"owner":  3,
+}
+
+// workspaceRole returns the caller's effective role in the workspace: their
+// member role, raised by the strongest role granted through any of their teams.
+func workspaceRole(ctx context.Context, st *store.Store, workspaceID, userID uuid.UUID) (string, error) {
+	role, err := st.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotMember) {
+			return "", ErrForbidden
+		}
+		return "", err
+	}
+
+	return withTeamGrant(ctx, st, workspaceID, userID, role)
+}
+
+// withTeamGrant raises role to the strongest grant of the user's teams.
+func withTeamGrant(ctx context.Context, st *store.Store, workspaceID, userID uuid.UUID, role string) (string, error) {
+	grant, err := st.Teams.GetTeamRoleGrant(ctx, workspaceID, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if roleRank[grant] > roleRank[role] {
+		return grant, nil
+	}
+	return role, nil
+}
+
+func requireWorkspaceAdmin(ctx context.Context, st *store.Store, workspaceID, userID uuid.UUID) error {
+	role, err := workspaceRole(ctx, st, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	if role != "owner" && role != "admin" {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,637 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrTimeEntryNotFound = errors.New("time entry not found")
+	ErrTimerRunning      = errors.New("a timer is already running")
+	ErrNoRunningTimer    = errors.New("no timer is running")
+	ErrTimeEntryOverlap  = errors.New("time entry overlaps another entry")
+	ErrInvalidTimeRange  = errors.New("a time entry must end after it starts and last at most 24 hours")
+	ErrTimeEntryLocked   = errors.New("billed time entries cannot be changed")
+)
+
+const maxTimeEntryDuration = 24 * time.Hour
+
+type StartTimerInput struct {
+	ProjectID   uuid.UUID  `json:"project_id" validate:"required"`
+	TaskID      *uuid.UUID `json:"task_id,omitempty"`
+	Description *string    `json:"description,omitempty" validate:"omitempty,max=1000"`
+	Billable    *bool      `json:"billable,omitempty"`
+	Tags        []string   `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+}
+
+type CreateTimeEntryInput struct {
+	ProjectID   uuid.UUID  `json:"project_id" validate:"required"`
+	TaskID      *uuid.UUID `json:"task_id,omitempty"`
+	Description *string    `json:"description,omitempty" validate:"omitempty,max=1000"`
+	StartedAt   time.Time  `json:"started_at" validate:"required"`
+	EndedAt     time.Time  `json:"ended_at" validate:"required"`
+	Billable    *bool      `json:"billable,omitempty"`
+	Tags        []string   `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+}
+
+// UpdateTimeEntryInput only changes the fields that are set. An empty task_id
+// clears it. The end of a running timer is set by stopping it.
+type UpdateTimeEntryInput struct {
+	ProjectID   *uuid.UUID `json:"project_id,omitempty"`
+	TaskID      *string    `json:"task_id,omitempty" validate:"omitempty,uuid"`
+	Description *string    `json:"description,omitempty" validate:"omitempty,max=1000"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+	Billable    *bool      `json:"billable,omitempty"`
+	Tags        []string   `json:"tags,omitempty" validate:"omitempty,max=20,dive,required,max=50"`
+}
+
+type TimeEntry interface {
+	StartTimer(ctx context.Context, userID, workspaceID uuid.UUID, input StartTimerInput) (*store.TimeEntry, error)
+	StopTimer(ctx context.Context, userID, workspaceID uuid.UUID) (*store.TimeEntry, error)
+	GetRunningTimer(ctx context.Context, userID, workspaceID uuid.UUID) (*store.TimeEntry, error)
+
+	CreateTimeEntry(ctx context.Context, userID, workspaceID uuid.UUID, input CreateTimeEntryInput) (*store.TimeEntry, error)
+	GetTimeEntry(ctx context.Context, userID, workspaceID, entryID uuid.UUID) (*store.TimeEntry, error)
+	ListTimeEntries(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.TimeEntry], error)
+	UpdateTimeEntry(ctx context.Context, userID, workspaceID, entryID uuid.UUID, input UpdateTimeEntryInput) (*store.TimeEntry, error)
+	DeleteTimeEntry(ctx context.Context, userID, workspaceID, entryID uuid.UUID) error
+}
+
+type TimeEntryService struct {
+	store    *store.Store
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewTimeEntryService(store *store.Store, eventBus EventPublisher, logger zerolog.Logger) *TimeEntryService {
+	return &TimeEntryService{
+		store:    store,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "time_entry_service").Logger(),
+	}
+}
+
+var _ TimeEntry = (*TimeEntryService)(nil)
+
+// StartTimer starts a running entry for the user. A user can only have one
+// running timer, across all workspaces.
+func (s *TimeEntryService) StartTimer(ctx context.Context, userID, workspaceID uuid.UUID, input StartTimerInput) (*store.TimeEntry, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkTarget(ctx, workspaceID, input.ProjectID, input.TaskID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := store.CreateTimeEntryParams{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		ProjectID:   input.ProjectID,
+		TaskID:      input.TaskID,
+		Description: trimDescription(input.Description),
+		StartedAt:   time.Now(),
+		Billable:    true,
+		Tags:        normalizeTags(input.Tags),
+	}
+	if input.Billable != nil {
+		params.Billable = *input.Billable
+	}
+
+	var entry *store.TimeEntry
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := tx.TimeEntries.LockUserTimeEntries(ctx, userID); err != nil {
+			return err
+		}
+		if err := checkTimesheetOpen(ctx, tx, workspaceID, userID, workspaceLocation(settings.Settings.Timezone), params.StartedAt); err != nil {
+			return err
+		}
+		if err := checkTimeEntryOverlap(ctx, tx, userID, params.StartedAt, nil, nil); err != nil {
+			if errors.Is(err, ErrTimeEntryOverlap) {
+				if _, runErr := tx.TimeEntries.GetRunningTimeEntry(ctx, userID); runErr == nil {
+					return ErrTimerRunning
+				}
+			}
+			return err
+		}
+
+		var err error
+		entry, err = tx.TimeEntries.CreateTimeEntry(ctx, params)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrTimerRunning) {
+			return nil, ErrTimerRunning
+		}
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// StopTimer ends the user's running timer in the workspace, rounding its
+// duration by the workspace rules and adding it to the task's logged hours.
+func (s *TimeEntryService) StopTimer(ctx context.Context, userID, workspaceID uuid.UUID) (*store.TimeEntry, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *store.TimeEntry
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := tx.TimeEntries.LockUserTimeEntries(ctx, userID); err != nil {
+			return err
+		}
+
+		running, err := tx.TimeEntries.GetRunningTimeEntry(ctx, userID)
+		if err != nil {
+			if errors.Is(err, store.ErrTimeEntryNotFound) {
+				return ErrNoRunningTimer
+			}
+			return err
+		}
+		if running.WorkspaceID != workspaceID {
+			return ErrNoRunningTimer
+		}
+
+		params := timeEntryUpdateParams(running)
+		now := time.Now()
+		params.EndedAt = &now
+		params.DurationSeconds = roundTimeEntry(now.Sub(running.StartedAt), settings.Settings)
+
+		if entry, err = tx.TimeEntries.UpdateTimeEntry(ctx, params); err != nil {
+			return err
+		}
+		return recalculateLoggedHours(ctx, tx, running.TaskID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *TimeEntryService) GetRunningTimer(ctx context.Context, userID, workspaceID uuid.UUID) (*store.TimeEntry, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.store.TimeEntries.GetRunningTimeEntry(ctx, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrTimeEntryNotFound) {
+			return nil, ErrNoRunningTimer
+		}
+		return nil, err
+	}
+	if entry.WorkspaceID != workspaceID {
+		return nil, ErrNoRunningTimer
+	}
+	return entry, nil
+}
+
+// CreateTimeEntry records finished work manually. Entries may not overlap the
+// user's other entries, including a running timer.
+func (s *TimeEntryService) CreateTimeEntry(ctx context.Context, userID, workspaceID uuid.UUID, input CreateTimeEntryInput) (*store.TimeEntry, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+	if err := validateTimeRange(input.StartedAt, input.EndedAt); err != nil {
+		return nil, err
+	}
+	if err := s.checkTarget(ctx, workspaceID, input.ProjectID, input.TaskID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := store.CreateTimeEntryParams{
+		WorkspaceID:     workspaceID,
+		UserID:          userID,
+		ProjectID:       input.ProjectID,
+		TaskID:          input.TaskID,
+		Description:     trimDescription(input.Description),
+		StartedAt:       input.StartedAt,
+		EndedAt:         &input.EndedAt,
+		DurationSeconds: roundTimeEntry(input.EndedAt.Sub(input.StartedAt), settings.Settings),
+		Billable:        true,
+		Tags:            normalizeTags(input.Tags),
+	}
+	if input.Billable != nil {
+		params.Billable = *input.Billable
+	}
+
+	var entry *store.TimeEntry
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := tx.TimeEntries.LockUserTimeEntries(ctx, userID); err != nil {
+			return err
+		}
+		if err := checkTimesheetOpen(ctx, tx, workspaceID, userID, workspaceLocation(settings.Settings.Timezone), params.StartedAt); err != nil {
+			return err
+		}
+		if err := checkTimeEntryOverlap(ctx, tx, userID, params.StartedAt, params.EndedAt, nil); err != nil {
+			return err
+		}
+
+		var err error
+		if entry, err = tx.TimeEntries.CreateTimeEntry(ctx, params); err != nil {
+			return err
+		}
+		return recalculateLoggedHours(ctx, tx, entry.TaskID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// GetTimeEntry returns an entry of the caller. Workspace admins can read every
+// member's entries.
+func (s *TimeEntryService) GetTimeEntry(ctx context.Context, userID, workspaceID, entryID uuid.UUID) (*store.TimeEntry, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.getTimeEntry(ctx, userID, workspaceID, entryID, role)
+}
+
+// ListTimeEntries lists the caller's entries. Workspace admins see every
+// member's entries and can narrow them with the user_id filter.
+func (s *TimeEntryService) ListTimeEntries(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.TimeEntry], error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter store.TimeEntryFilter
+	switch value := filters.Filters["user_id"]; value {
+	case "me":
+		filter.UserID = &userID
+	default:
+		if filter.UserID, err = parseUUIDFilter(value); err != nil {
+			return nil, err
+		}
+	}
+	if role != "owner" && role != "admin" {
+		if filter.UserID != nil && *filter.UserID != userID {
+			return nil, ErrForbidden
+		}
+		filter.UserID = &userID
+	}
+
+	if filter.ProjectID, err = parseUUIDFilter(filters.Filters["project_id"]); err != nil {
+		return nil, err
+	}
+	if filter.TaskID, err = parseUUIDFilter(filters.Filters["task_id"]); err != nil {
+		return nil, err
+	}
+	if filter.Billable, err = parseBoolFilter(filters.Filters["billable"]); err != nil {
+		return nil, err
+	}
+	if filter.Billed, err = parseBoolFilter(filters.Filters["billed"]); err != nil {
+		return nil, err
+	}
+	if filter.Running, err = parseBoolFilter(filters.Filters["running"]); err != nil {
+		return nil, err
+	}
+
+	if filters.Filters["from"] != "" || filters.Filters["to"] != "" {
+		settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		loc := workspaceLocation(settings.Settings.Timezone)
+		if filter.From, err = parseDateFilter(filters.Filters["from"], loc); err != nil {
+			return nil, err
+		}
+		if filter.To, err = parseDateFilter(filters.Filters["to"], loc); err != nil {
+			return nil, err
+		}
+		if filter.To != nil {
+			end := filter.To.AddDate(0, 0, 1)
+			filter.To = &end
+		}
+	}
+
+	entries, total, err := s.store.TimeEntries.ListTimeEntries(ctx, workspaceID, filter, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.BuildFilterResponse(entries, total, filters), nil
+}
+
+// UpdateTimeEntry lets the entry's owner or a workspace admin edit it. Changed
+// times are rounded again and checked for overlaps. Billed entries and entries
+// in a submitted or approved week are locked.
+func (s *TimeEntryService) UpdateTimeEntry(ctx context.Context, userID, workspaceID, entryID uuid.UUID, input UpdateTimeEntryInput) (*store.TimeEntry, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.getTimeEntry(ctx, userID, workspaceID, entryID, role)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Billed {
+		return nil, ErrTimeEntryLocked
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := timeEntryUpdateParams(entry)
+	if input.ProjectID != nil {
+		params.ProjectID = *input.ProjectID
+		if *input.ProjectID != entry.ProjectID && input.TaskID == nil {
+			params.TaskID = nil
+		}
+	}
+	if input.TaskID != nil {
+		if params.TaskID, err = parseUUIDFilter(*input.TaskID); err != nil {
+			return nil, err
+		}
+	}
+	if input.Description != nil {
+		params.Description = trimDescription(input.Description)
+	}
+	if input.Billable != nil {
+		params.Billable = *input.Billable
+	}
+	if input.Tags != nil {
+		params.Tags = normalizeTags(input.Tags)
+	}
+
+	timesChanged := input.StartedAt != nil || input.EndedAt != nil
+	if input.StartedAt != nil {
+		params.StartedAt = *input.StartedAt
+	}
+	if input.EndedAt != nil {
+		if entry.EndedAt == nil {
+			return nil, ErrInvalidTimeRange
+		}
+		params.EndedAt = input.EndedAt
+	}
+
+	if params.ProjectID != entry.ProjectID || !sameUUID(params.TaskID, entry.TaskID) {
+		if err := s.checkTarget(ctx, workspaceID, params.ProjectID, params.TaskID); err != nil {
+			return nil, err
+		}
+	}
+
+	if timesChanged {
+		if params.EndedAt != nil {
+			if err := validateTimeRange(params.StartedAt, *params.EndedAt); err != nil {
+				return nil, err
+			}
+			params.DurationSeconds = roundTimeEntry(params.EndedAt.Sub(params.StartedAt), settings.Settings)
+		} else if params.StartedAt.After(time.Now()) {
+			return nil, ErrInvalidTimeRange
+		}
+	}
+
+	var updated *store.TimeEntry
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := tx.TimeEntries.LockUserTimeEntries(ctx, entry.UserID); err != nil {
+			return err
+		}
+		if err := checkTimeEntryUnbilled(ctx, tx, workspaceID, entry.ID); err != nil {
+			return err
+		}
+		if err := checkTimesheetOpen(ctx, tx, workspaceID, entry.UserID, workspaceLocation(settings.Settings.Timezone), entry.StartedAt, params.StartedAt); err != nil {
+			return err
+		}
+		if timesChanged {
+			if err := checkTimeEntryOverlap(ctx, tx, entry.UserID, params.StartedAt, params.EndedAt, &entry.ID); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if updated, err = tx.TimeEntries.UpdateTimeEntry(ctx, params); err != nil {
+			return err
+		}
+		if err := recalculateLoggedHours(ctx, tx, entry.TaskID); err != nil {
+			return err
+		}
+		if !sameUUID(entry.TaskID, updated.TaskID) {
+			return recalculateLoggedHours(ctx, tx, updated.TaskID)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrTimeEntryNotFound) {
+			return nil, ErrTimeEntryNotFound
+		}
+		if errors.Is(err, store.ErrTimeEntryBilled) {
+			return nil, ErrTimeEntryLocked
+		}
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// DeleteTimeEntry lets the entry's owner or a workspace admin remove it.
+// Billed entries and entries in a submitted or approved week are locked.
+func (s *TimeEntryService) DeleteTimeEntry(ctx context.Context, userID, workspaceID, entryID uuid.UUID) error {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+
+	entry, err := s.getTimeEntry(ctx, userID, workspaceID, entryID, role)
+	if err != nil {
+		return err
+	}
+	if entry.Billed {
+		return ErrTimeEntryLocked
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return err
+	}
+
+	return s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := tx.TimeEntries.LockUserTimeEntries(ctx, entry.UserID); err != nil {
+			return err
+		}
+		if err := checkTimeEntryUnbilled(ctx, tx, workspaceID, entry.ID); err != nil {
+			return err
+		}
+		if err := checkTimesheetOpen(ctx, tx, workspaceID, entry.UserID, workspaceLocation(settings.Settings.Timezone), entry.StartedAt); err != nil {
+			return err
+		}
+		if err := tx.TimeEntries.DeleteTimeEntry(ctx, workspaceID, entry.ID); err != nil {
+			if errors.Is(err, store.ErrTimeEntryNotFound) {
+				return ErrTimeEntryNotFound
+			}
+			if errors.Is(err, store.ErrTimeEntryBilled) {
+				return ErrTimeEntryLocked
+			}
+			return err
+		}
+		return recalculateLoggedHours(ctx, tx, entry.TaskID)
+	})
+}
+
+// checkTimeEntryUnbilled reloads an entry under the user's time entry lock
+// and refuses it when an invoice billed it since it was first read.
+func checkTimeEntryUnbilled(ctx context.Context, tx *store.Store, workspaceID, entryID uuid.UUID) error {
+	current, err := tx.TimeEntries.GetTimeEntry(ctx, workspaceID, entryID)
+	if err != nil {
+		if errors.Is(err, store.ErrTimeEntryNotFound) {
+			return ErrTimeEntryNotFound
+		}
+		return err
+	}
+	if current.Billed {
+		return ErrTimeEntryLocked
+	}
+	return nil
+}
+
+// getTimeEntry loads an entry the caller may see. Other members' entries are
+// reported as not found to non-admins.
+func (s *TimeEntryService) getTimeEntry(ctx context.Context, userID, workspaceID, entryID uuid.UUID, role string) (*store.TimeEntry, error) {
+	entry, err := s.store.TimeEntries.GetTimeEntry(ctx, workspaceID, entryID)
+	if err != nil {
+		if errors.Is(err, store.ErrTimeEntryNotFound) {
+			return nil, ErrTimeEntryNotFound
+		}
+		return nil, err
+	}
+	if entry.UserID != userID && role != "owner" && role != "admin" {
+		return nil, ErrTimeEntryNotFound
+	}
+	return entry, nil
+}
+
+// checkTarget requires a live project of the workspace and, when set, a task
+// of that project.
+func (s *TimeEntryService) checkTarget(ctx context.Context, workspaceID, projectID uuid.UUID, taskID *uuid.UUID) error {
+	if _, err := s.store.Projects.GetProject(ctx, workspaceID, projectID); err != nil {
+		if errors.Is(err, store.ErrProjectNotFound) {
+			return ErrProjectNotFound
+		}
+		return err
+	}
+	if taskID == nil {
+		return nil
+	}
+
+	task, err := s.store.Tasks.GetTask(ctx, workspaceID, *taskID)
+	if err != nil {
+		if errors.Is(err, store.ErrTaskNotFound) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+	if task.ProjectID != projectID {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+func checkTimeEntryOverlap(ctx context.Context, tx *store.Store, userID uuid.UUID, start time.Time, end *time.Time, exclude *uuid.UUID) error {
+	overlaps, err := tx.TimeEntries.HasOverlappingTimeEntry(ctx, userID, start, end, exclude)
+	if err != nil {
+		return err
+	}
+	if overlaps {
+		return ErrTimeEntryOverlap
+	}
+	return nil
+}
+
+func recalculateLoggedHours(ctx context.Context, tx *store.Store, taskID *uuid.UUID) error {
+	if taskID == nil {
+		return nil
+	}
+	return tx.TimeEntries.RecalculateTaskLoggedHours(ctx, *taskID)
+}
+
+func validateTimeRange(start, end time.Time) error {
+	if !end.After(start) || end.Sub(start) > maxTimeEntryDuration {
+		return ErrInvalidTimeRange
+	}
+	return nil
+}
+
+// roundTimeEntry applies the workspace rounding rule to a duration, in
+// seconds. Without a rounding increment the exact duration is kept.
+func roundTimeEntry(d time.Duration, settings store.SettingsDocument) int64 {
+	seconds := int64(d / time.Second)
+	step := int64(settings.TimeRoundingMinutes) * 60
+	if step <= 0 {
+		return seconds
+	}
+
+	switch settings.TimeRoundingMode {
+	case "up":
+		return (seconds + step - 1) / step * step
+	case "down":
+		return seconds / step * step
+	default:
+		return (seconds + step/2) / step * step
+	}
+}
+
+func parseDateFilter(value string, loc *time.Location) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(dateLayout, value, loc)
+	if err != nil {
+		return nil, ErrInvalidFilter
+	}
+	return &t, nil
+}
+
+func trimDescription(description *string) *string {
+	if description == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*description)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func timeEntryUpdateParams(entry *store.TimeEntry) store.UpdateTimeEntryParams {
+	return store.UpdateTimeEntryParams{
+		ID:              entry.ID,
+		WorkspaceID:     entry.WorkspaceID,
+		ProjectID:       entry.ProjectID,
+		TaskID:          entry.TaskID,
+		Description:     entry.Description,
+		StartedAt:       entry.StartedAt,
+		EndedAt:         entry.EndedAt,
+		DurationSeconds: entry.DurationSeconds,
+		Billable:        entry.Billable,
+		Tags:            entry.Tags,
+	}
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/pkg/rrule"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrTimesheetNotFound     = errors.New("timesheet not found")
+	ErrTimesheetNotEditable  = errors.New("timesheet has already been submitted")
+	ErrTimesheetNotSubmitted = errors.New("timesheet is not awaiting review")
+	ErrTimesheetLocked       = errors.New("the timesheet for this week has been submitted or approved")
+	ErrInvalidWeekStart      = errors.New("week_start must be a Monday that is not in the future")
+)
+
+const timesheetReminderBatchSize = 500
+
+type SubmitTimesheetInput struct {
+	WeekStart string `json:"week_start" validate:"required,datetime=2006-01-02"`
+}
+
+type RejectTimesheetInput struct {
+	Reason string `json:"reason" validate:"required,min=1,max=1000"`
+}
+
+type Timesheet interface {
+	SubmitTimesheet(ctx context.Context, userID, workspaceID uuid.UUID, input SubmitTimesheetInput) (*store.Timesheet, error)
+	ApproveTimesheet(ctx context.Context, userID, workspaceID, timesheetID uuid.UUID) (*store.Timesheet, error)
+	RejectTimesheet(ctx context.Context, userID, workspaceID, timesheetID uuid.UUID, input RejectTimesheetInput) (*store.Timesheet, error)
+	GetTimesheet(ctx context.Context, userID, workspaceID, timesheetID uuid.UUID) (*store.Timesheet, error)
+	ListTimesheets(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Timesheet], error)
+
+	SendTimesheetReminders(ctx context.Context) (int, error)
+}
+
+type TimesheetService struct {
+	store    *store.Store
+	eventBus EventPublisher
+	logger   zerolog.Logger
+}
+
+func NewTimesheetService(store *store.Store, eventBus EventPublisher, logger zerolog.Logger) *TimesheetService {
+	return &TimesheetService{
+		store:    store,
+		eventBus: eventBus,
+		logger:   logger.With().Str("component", "timesheet_service").Logger(),
+	}
+}
+
+var _ Timesheet = (*TimesheetService)(nil)
+
+// SubmitTimesheet submits the caller's week for review. The week's totals are
+// captured and its entries are locked until the timesheet is rejected.
+func (s *TimesheetService) SubmitTimesheet(ctx context.Context, userID, workspaceID uuid.UUID, input SubmitTimesheetInput) (*store.Timesheet, error) {
+	if _, err := workspaceRole(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	loc := workspaceLocation(settings.Settings.Timezone)
+
+	weekStart, err := time.Parse(dateLayout, input.WeekStart)
+	if err != nil || weekStart.Weekday() != time.Monday || weekStart.After(workspaceToday(settings.Settings.Timezone)) {
+		return nil, ErrInvalidWeekStart
+	}
+	from, to := timesheetRange(weekStart, loc)
+
+	var timesheet *store.Timesheet
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		// Entries of the week cannot change while it is being submitted.
+		if err := tx.TimeEntries.LockUserTimeEntries(ctx, userID); err != nil {
+			return err
+		}
+
+		totals, err := tx.Timesheets.SumTimesheetEntries(ctx, workspaceID, userID, from, to)
+		if err != nil {
+			return err
+		}
+		if totals.Running > 0 {
+			return ErrTimerRunning
+		}
+
+		timesheet, err = tx.Timesheets.SubmitTimesheet(ctx, store.SubmitTimesheetParams{
+			WorkspaceID:     workspaceID,
+			UserID:          userID,
+			WeekStart:       weekStart,
+			TotalSeconds:    totals.TotalSeconds,
+			BillableSeconds: totals.BillableSeconds,
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrTimesheetNotEditable) {
+			return nil, ErrTimesheetNotEditable
+		}
+		return nil, err
+	}
+
+	s.publish(ctx, events.EventTimesheetSubmitted, userID, timesheet)
+
+	return timesheet, nil
+}
+
+// ApproveTimesheet approves a submitted timesheet and marks its entries as
+// approved. Approved entries can no longer be changed.
+func (s *TimesheetService) ApproveTimesheet(ctx context.Context, userID, workspaceID, timesheetID uuid.UUID) (*store.Timesheet, error) {
+	settings, err := s.requireApprover(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var timesheet *store.Timesheet
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		current, err := tx.Timesheets.GetTimesheet(ctx, workspaceID, timesheetID)
+		if err != nil {
+			return err
+		}
+		if err := tx.TimeEntries.LockUserTimeEntries(ctx, current.UserID); err != nil {
+			return err
+		}
+
+		timesheet, err = tx.Timesheets.ReviewTimesheet(ctx, store.ReviewTimesheetParams{
+			ID:          timesheetID,
+			WorkspaceID: workspaceID,
+			Status:      "approved",
+			ReviewedBy:  userID,
+		})
+		if err != nil {
+			return err
+		}
+
+		from, to := timesheetRange(timesheet.WeekStart, workspaceLocation(settings.Timezone))
+		return tx.Timesheets.ApproveTimesheetEntries(ctx, workspaceID, timesheet.UserID, from, to)
+	})
+	if err != nil {
+		return nil, mapTimesheetError(err)
+	}
+
+	s.publish(ctx, events.EventTimesheetApproved, userID, timesheet)
+
+	return timesheet, nil
+}
+
+// RejectTimesheet sends a submitted timesheet back to its owner, unlocking
+// the week's entries for changes and resubmission.
+func (s *TimesheetService) RejectTimesheet(ctx context.Context, userID, workspaceID, timesheetID uuid.UUID, input RejectTimesheetInput) (*store.Timesheet, error) {
+	if _, err := s.requireApprover(ctx, userID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(input.Reason)
+	timesheet, err := s.store.Timesheets.ReviewTimesheet(ctx, store.ReviewTimesheetParams{
+		ID:              timesheetID,
+		WorkspaceID:     workspaceID,
+		Status:          "rejected",
+		ReviewedBy:      userID,
+		RejectionReason: &reason,
+	})
+	if err != nil {
+		return nil, mapTimesheetError(err)
+	}
+
+	s.publish(ctx, events.EventTimesheetRejected, userID, timesheet)
+
+	return timesheet, nil
+}
+
+// GetTimesheet returns a timesheet to its owner or to a timesheet approver.
+func (s *TimesheetService) GetTimesheet(ctx context.Context, userID, workspaceID, timesheetID uuid.UUID) (*store.Timesheet, error) {
+	approver, err := s.isApprover(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	timesheet, err := s.store.Timesheets.GetTimesheet(ctx, workspaceID, timesheetID)
+	if err != nil {
+		return nil, mapTimesheetError(err)
+	}
+	if timesheet.UserID != userID && !approver {
+		return nil, ErrTimesheetNotFound
+	}
+	return timesheet, nil
+}
+
+// ListTimesheets lists the caller's timesheets. Timesheet approvers see every
+// member's and can narrow them with the user_id filter.
+func (s *TimesheetService) ListTimesheets(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.Timesheet], error) {
+	approver, err := s.isApprover(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter store.TimesheetFilter
+	switch value := filters.Filters["user_id"]; value {
+	case "me":
+		filter.UserID = &userID
+	default:
+		if filter.UserID, err = parseUUIDFilter(value); err != nil {
+			return nil, err
+		}
+	}
+	if !approver {
+		if filter.UserID != nil && *filter.UserID != userID {
+			return nil, ErrForbidden
+		}
+		filter.UserID = &userID
+	}
+
+	switch status := filters.Filters["status"]; status {
+	case "", "open", "submitted", "approved", "rejected":
+		filter.Status = status
+	default:
+		return nil, ErrInvalidFilter
+	}
+	if filter.WeekStart, err = parseDateFilter(filters.Filters["week_start"], time.UTC); err != nil {
+		return nil, err
+	}
+
+	timesheets, total, err := s.store.Timesheets.ListTimesheets(ctx, workspaceID, filter, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return store.BuildFilterResponse(timesheets, total, filters), nil
+}
+
+// SendTimesheetReminders publishes a reminder for every finished week in
+// which a member logged time without submitting the timesheet. Each week is
+// reminded once, even with several replicas running the job.
+func (s *TimesheetService) SendTimesheetReminders(ctx context.Context) (int, error) {
+	reminders, err := s.store.Timesheets.ClaimTimesheetReminders(ctx, timesheetReminderBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, reminder := range reminders {
+		if s.eventBus == nil {
+			break
+		}
+		s.eventBus.Publish(ctx, events.EventTimesheetReminderDue, reminder.UserID, map[string]any{
+			"workspace_id": reminder.WorkspaceID,
+			"timesheet_id": reminder.TimesheetID,
+			"user_id":      reminder.UserID,
+			"week_start":   reminder.WeekStart.Format(dateLayout),
+		})
+	}
+
+	if len(reminders) > 0 {
+		s.logger.Info().Int("timesheets", len(reminders)).Msg("sent timesheet reminders")
+	}
+	return len(reminders), nil
+}
+
+// requireApprover allows workspace admins and the members listed as timesheet
+// approvers in the workspace settings.
+func (s *TimesheetService) requireApprover(ctx context.Context, userID, workspaceID uuid.UUID) (store.SettingsDocument, error) {
+	settings, approver, err := s.approverSettings(ctx, userID, workspaceID)
+	if err != nil {
+		return store.SettingsDocument{}, err
+	}
+	if !approver {
+		return store.SettingsDocument{}, ErrForbidden
+	}
+	return settings, nil
+}
+
+func (s *TimesheetService) isApprover(ctx context.Context, userID, workspaceID uuid.UUID) (bool, error) {
+	_, approver, err := s.approverSettings(ctx, userID, workspaceID)
+	return approver, err
+}
+
+func (s *TimesheetService) approverSettings(ctx context.Context, userID, workspaceID uuid.UUID) (store.SettingsDocument, bool, error) {
+	role, err := workspaceRole(ctx, s.store, workspaceID, userID)
+	if err != nil {
+		return store.SettingsDocument{}, false, err
+	}
+
+	settings, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return store.SettingsDocument{}, false, err
+	}
+	return settings.Settings, canApproveTimesheets(role, userID, settings.Settings), nil
+}
+
+func (s *TimesheetService) publish(ctx context.Context, eventType events.EventType, userID uuid.UUID, timesheet *store.Timesheet) {
+	if s.eventBus == nil {
+		return
+	}
+	payload := map[string]any{
+		"workspace_id":  timesheet.WorkspaceID,
+		"timesheet_id":  timesheet.ID,
+		"user_id":       timesheet.UserID,
+		"week_start":    timesheet.WeekStart.Format(dateLayout),
+		"total_seconds": timesheet.TotalSeconds,
+	}
+	if timesheet.RejectionReason != nil {
+		payload["reason"] = *timesheet.RejectionReason
+	}
+	s.eventBus.Publish(ctx, eventType, userID, payload)
+}
+
+func canApproveTimesheets(role string, userID uuid.UUID, settings store.SettingsDocument) bool {
+	return role == "owner" || role == "admin" || slices.Contains(settings.TimesheetApproverIDs, userID)
+}
+
+// checkTimesheetOpen fails when any of the times falls in one of the user's
+// submitted or approved weeks.
+func checkTimesheetOpen(ctx context.Context, st *store.Store, workspaceID, userID uuid.UUID, loc *time.Location, times ...time.Time) error {
+	for _, t := range times {
+		timesheet, err := st.Timesheets.GetTimesheetForWeek(ctx, workspaceID, userID, timesheetWeek(t, loc))
+		if err != nil {
+			if errors.Is(err, store.ErrTimesheetNotFound) {
+				continue
+			}
+			return err
+		}
+		if timesheet.Status == "submitted" || timesheet.Status == "approved" {
+			return ErrTimesheetLocked
+		}
+	}
+	return nil
+}
+
+// timesheetWeek returns the Monday of the week t falls in, in loc, as a date.
+func timesheetWeek(t time.Time, loc *time.Location) time.Time {
+	day := rrule.Date(t.In(loc))
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// timesheetRange returns the instants bounding the week starting on weekStart
+// in loc, the end being exclusive.
+func timesheetRange(weekStart time.Time, loc *time.Location) (time.Time, time.Time) {
+	from := time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, loc)
+	return from, from.AddDate(0, 0, 7)
+}
+
+func mapTimesheetError(err error) error {
+	switch {
+	case errors.Is(err, store.ErrTimesheetNotFound):
+		return ErrTimesheetNotFound
+	case errors.Is(err, store.ErrTimesheetNotSubmitted):
+		return ErrTimesheetNotSubmitted
+	default:
+		return err
+	}
+}
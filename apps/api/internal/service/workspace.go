@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/google/uuid"
@@ -34,6 +35,14 @@ type Workspace interface {
 	RemoveMember(ctx context.Context, requesterID, workspaceID, targetUserID uuid.UUID) error
 	GetMembers(ctx context.Context, userID, workspaceID uuid.UUID, filters store.FilterParams) (*store.PaginatedResponse[store.WorkspaceMember], error)
 	UploadAvatar(ctx context.Context, userID, workspaceID uuid.UUID, reader io.Reader, size int64, contentType string) (string, error)
+	GetSettings(ctx context.Context, userID, workspaceID uuid.UUID) (*store.WorkspaceSettings, error)
+	UpdateSettings(ctx context.Context, userID, workspaceID uuid.UUID, input UpdateWorkspaceSettingsInput) (*store.WorkspaceSettings, error)
+	GetUsage(ctx context.Context, userID, workspaceID uuid.UUID) (*WorkspaceUsageReport, error)
+	LeaveWorkspace(ctx context.Context, userID, workspaceID uuid.UUID, input OffboardMemberInput) error
+	DeactivateMember(ctx context.Context, requesterID, workspaceID, targetUserID uuid.UUID, input OffboardMemberInput) (*store.WorkspaceMember, error)
+	ReactivateMember(ctx context.Context, requesterID, workspaceID, targetUserID uuid.UUID) (*store.WorkspaceMember, error)
+	ReassignMemberContent(ctx context.Context, requesterID, workspaceID, fromUserID uuid.UUID, input ReassignContentInput) (*ContentReassignment, error)
+	SetMemberRate(ctx context.Context, requesterID, workspaceID, targetUserID uuid.UUID, input SetMemberRateInput) (*store.WorkspaceMember, error)
 }
 
 type WorkspaceService struct {
@@ -66,7 +75,11 @@ func (s *WorkspaceService) CreateWorkspace(ctx context.Context, userID uuid.UUID
 			return err
 		}
 
-		return tx.Workspaces.AddWorkspaceMember(ctx, workspace.ID, userID, "owner")
+		if err := tx.Workspaces.AddWorkspaceMember(ctx, workspace.ID, userID, "owner"); err != nil {
+			return err
+		}
+
+		return tx.Usage.IncrementUsage(ctx, workspace.ID, store.UsageMembers, 1, nil)
 	})
 
 	if err == nil && s.eventBus != nil {
@@ -101,18 +114,10 @@ func (s *WorkspaceService) GetMyWorkspaces(ctx context.Context, userID uuid.UUID
 }
 
 func (s *WorkspaceService) UpdateWorkspace(ctx context.Context, userID, workspaceID uuid.UUID, name string) (*store.Workspace, error) {
-	role, err := s.store.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, userID)
-	if err != nil {
-		if errors.Is(err, store.ErrNotMember) {
-			return nil, ErrForbidden
-		}
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
 		return nil, err
 	}
 
-	if role != "owner" && role != "admin" {
-		return nil, ErrForbidden
-	}
-
 	return s.store.Workspaces.UpdateWorkspace(ctx, workspaceID, name)
 }
 
@@ -141,23 +146,35 @@ func (s *WorkspaceService) DeleteWorkspace(ctx context.Context, userID, workspac
 }
 
 func (s *WorkspaceService) AddMember(ctx context.Context, requesterID, workspaceID, targetUserID uuid.UUID, role string) (*store.WorkspaceMember, error) {
-	requesterRole, err := s.store.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, requesterID)
-	if err != nil {
-		if errors.Is(err, store.ErrNotMember) {
-			return nil, ErrForbidden
-		}
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, requesterID); err != nil {
 		return nil, err
 	}
 
-	if requesterRole != "owner" && requesterRole != "admin" {
-		return nil, ErrForbidden
-	}
-
 	if role != "admin" && role != "member" {
 		return nil, ErrInvalidRole
 	}
 
-	err = s.store.Workspaces.AddWorkspaceMember(ctx, workspaceID, targetUserID, role)
+	err := s.store.ExecTx(ctx, func(tx *store.Store) error {
+		// The usage lock serialises member changes, so two admins adding the
+		// same user cannot both see a new member and both take a seat.
+		if err := tx.Usage.LockUsage(ctx, workspaceID); err != nil {
+			return err
+		}
+
+		// Changing the role of an existing member does not consume a seat.
+		_, err := tx.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, targetUserID)
+		if err == nil {
+			return tx.Workspaces.AddWorkspaceMember(ctx, workspaceID, targetUserID, role)
+		}
+		if !errors.Is(err, store.ErrNotMember) {
+			return err
+		}
+
+		if err := reserveQuota(ctx, tx, workspaceID, store.UsageMembers, 1); err != nil {
+			return err
+		}
+		return tx.Workspaces.AddWorkspaceMember(ctx, workspaceID, targetUserID, role)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -202,34 +219,44 @@ func (s *WorkspaceService) AddMemberByEmail(ctx context.Context, requesterID, wo
 }
 
 func (s *WorkspaceService) RemoveMember(ctx context.Context, requesterID, workspaceID, targetUserID uuid.UUID) error {
-	requesterRole, err := s.store.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, requesterID)
+	if requesterID == targetUserID {
+		return s.LeaveWorkspace(ctx, requesterID, workspaceID, OffboardMemberInput{})
+	}
+
+	requesterRole, err := workspaceRole(ctx, s.store, workspaceID, requesterID)
 	if err != nil {
-		if errors.Is(err, store.ErrNotMember) {
-			return ErrForbidden
-		}
 		return err
 	}
 
-	if requesterID == targetUserID {
-		if requesterRole == "owner" {
-			return errors.New("owner cannot leave workspace, delete it instead")
-		}
-	} else {
-		if requesterRole == "member" {
-			return ErrForbidden
-		}
+	target, err := s.getMember(ctx, workspaceID, targetUserID)
+	if err != nil {
+		return err
+	}
 
-		targetRole, err := s.store.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, targetUserID)
+	if err := canManageMember(ctx, s.store, requesterRole, target); err != nil {
+		return err
+	}
+
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		// A deactivation may have committed since target was read, and it
+		// gave the seat back already, so decide from the locked row.
+		current, err := tx.Workspaces.LockWorkspaceMember(ctx, workspaceID, targetUserID)
 		if err != nil {
+			if errors.Is(err, store.ErrNotMember) {
+				return ErrNotWorkspaceMember
+			}
 			return err
 		}
-
-		if requesterRole == "admin" && (targetRole == "admin" || targetRole == "owner") {
-			return ErrForbidden
+		if err := tx.Workspaces.RemoveWorkspaceMember(ctx, workspaceID, targetUserID); err != nil {
+			return err
 		}
-	}
-
-	if err := s.store.Workspaces.RemoveWorkspaceMember(ctx, workspaceID, targetUserID); err != nil {
+		// Deactivated members already gave their seat back.
+		if current.DeactivatedAt != nil {
+			return nil
+		}
+		return releaseQuota(ctx, tx, workspaceID, store.UsageMembers, 1)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -265,31 +292,43 @@ func (s *WorkspaceService) UploadAvatar(ctx context.Context, userID, workspaceID
 		return "", err
 	}
 
-	role, err := s.store.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, userID)
-	if err != nil {
-		if errors.Is(err, store.ErrNotMember) {
-			return "", ErrForbidden
-		}
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
 		return "", err
 	}
 
-	if role != "owner" && role != "admin" {
-		return "", ErrForbidden
-	}
-
-	// Upload to storage first
-	avatarURL, err := s.storage.UploadAvatar(ctx, workspaceID.String(), reader, size, contentType)
+	// Reserve the quota before uploading and record the object after, so the
+	// usage counters are not locked while the file is transferred. A failed
+	// upload gives the reservation back.
+	objectName := workspaceAvatarObject(workspaceID)
+	reserved, err := reserveStoredObject(ctx, s.store, workspaceID, objectName, size)
 	if err != nil {
 		return "", err
 	}
 
-	// Update database - if this fails, we need to clean up the uploaded file
-	_, err = s.store.Workspaces.UpdateWorkspaceAvatar(ctx, workspaceID, avatarURL)
+	avatarURL, err := s.storage.UploadAvatar(ctx, workspaceID.String(), reader, size, contentType)
+	if err == nil {
+		err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+			if err := recordStoredObject(ctx, tx, workspaceID, objectName, size, reserved); err != nil {
+				return err
+			}
+			_, err := tx.Workspaces.UpdateWorkspaceAvatar(ctx, workspaceID, avatarURL)
+			return err
+		})
+	}
 	if err != nil {
-		// Best effort cleanup of orphaned file
-		_ = s.storage.DeleteAvatar(ctx, workspaceID.String())
+		if releaseErr := releaseStoredObject(ctx, s.store, workspaceID, reserved); releaseErr != nil {
+			s.logger.Warn().Err(releaseErr).Str("workspace_id", workspaceID.String()).Msg("failed to release avatar storage reservation")
+		}
+		if avatarURL != "" {
+			// Best effort cleanup of orphaned file
+			_ = s.storage.DeleteAvatar(context.WithoutCancel(ctx), workspaceID.String())
+		}
 		return "", err
 	}
 
 	return avatarURL, nil
 }
+
+func workspaceAvatarObject(workspaceID uuid.UUID) string {
+	return fmt.Sprintf("avatars/%s", workspaceID)
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+)
+
+var (
+	ErrOwnerCannotLeave      = errors.New("owner cannot leave workspace, delete it instead")
+	ErrCannotDeactivateSelf  = errors.New("you cannot deactivate yourself, leave the workspace instead")
+	ErrMemberDeactivated     = errors.New("member is deactivated")
+	ErrMemberNotDeactivated  = errors.New("member is not deactivated")
+	ErrInvalidReassignTarget = errors.New("content can only be reassigned to another active member")
+)
+
+// OffboardMemberInput optionally hands the departing member's content over to
+// another active member in the same transaction.
+type OffboardMemberInput struct {
+	ReassignTo *uuid.UUID `json:"reassign_to,omitempty"`
+}
+
+type ReassignContentInput struct {
+	ToUserID uuid.UUID `json:"to_user_id" validate:"required"`
+}
+
+// SetMemberRateInput sets the hourly rate a member's time is priced at in
+// project budgets, in minor units of the workspace default currency. A nil
+// rate clears it.
+type SetMemberRateInput struct {
+	HourlyRate *int64 `json:"hourly_rate" validate:"omitempty,min=0"`
+}
+
+type ContentReassignment struct {
+	WorkspaceID uuid.UUID        `json:"workspace_id"`
+	FromUserID  uuid.UUID        `json:"from_user_id"`
+	ToUserID    uuid.UUID        `json:"to_user_id"`
+	Moved       map[string]int64 `json:"moved"`
+}
+
+// LeaveWorkspace removes the caller from the workspace. Their history stays in
+// place unless they hand their content over to another member.
+func (s *WorkspaceService) LeaveWorkspace(ctx context.Context, userID, workspaceID uuid.UUID, input OffboardMemberInput) error {
+	role, err := s.store.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotMember) {
+			return ErrForbidden
+		}
+		return err
+	}
+
+	if role == "owner" {
+		return ErrOwnerCannotLeave
+	}
+
+	var reassignment *ContentReassignment
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		// A deactivation may have committed since the role check; it took
+		// the member's access and gave their seat back.
+		current, err := tx.Workspaces.LockWorkspaceMember(ctx, workspaceID, userID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotMember) {
+				return ErrForbidden
+			}
+			return err
+		}
+		if current.DeactivatedAt != nil {
+			return ErrForbidden
+		}
+
+		if input.ReassignTo != nil {
+			reassignment, err = reassignMemberContent(ctx, tx, workspaceID, userID, *input.ReassignTo)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Workspaces.RemoveWorkspaceMember(ctx, workspaceID, userID); err != nil {
+			return err
+		}
+		return releaseQuota(ctx, tx, workspaceID, store.UsageMembers, 1)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.publishReassignment(ctx, userID, reassignment)
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventMemberLeft, userID, map[string]any{
+			"workspace_id": workspaceID,
+			"user_id":      userID,
+		})
+	}
+
+	return nil
+}
+
+// DeactivateMember blocks the member's access while keeping their membership
+// and everything they created. A deactivated member does not occupy a seat.
+func (s *WorkspaceService) DeactivateMember(ctx context.Context, requesterID, workspaceID, targetUserID uuid.UUID, input OffboardMemberInput) (*store.WorkspaceMember, error) {
+	if requesterID == targetUserID {
+		return nil, ErrCannotDeactivateSelf
+	}
+
+	requesterRole, err := workspaceRole(ctx, s.store, workspaceID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.getMember(ctx, workspaceID, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	if target.DeactivatedAt != nil {
+		return nil, ErrMemberDeactivated
+	}
+
+	if err := canManageMember(ctx, s.store, requesterRole, target); err != nil {
+		return nil, err
+	}
+
+	var reassignment *ContentReassignment
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if input.ReassignTo != nil {
+			var err error
+			reassignment, err = reassignMemberContent(ctx, tx, workspaceID, targetUserID, *input.ReassignTo)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Workspaces.DeactivateWorkspaceMember(ctx, workspaceID, targetUserID, requesterID); err != nil {
+			return err
+		}
+		return releaseQuota(ctx, tx, workspaceID, store.UsageMembers, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishReassignment(ctx, requesterID, reassignment)
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventMemberDeactivated, requesterID, map[string]any{
+			"workspace_id": workspaceID,
+			"user_id":      targetUserID,
+			"email":        target.Email,
+		})
+	}
+
+	return s.getMember(ctx, workspaceID, targetUserID)
+}
+
+func (s *WorkspaceService) ReactivateMember(ctx context.Context, requesterID, workspaceID, targetUserID uuid.UUID) (*store.WorkspaceMember, error) {
+	requesterRole, err := workspaceRole(ctx, s.store, workspaceID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.getMember(ctx, workspaceID, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	if target.DeactivatedAt == nil {
+		return nil, ErrMemberNotDeactivated
+	}
+
+	if err := canManageMember(ctx, s.store, requesterRole, target); err != nil {
+		return nil, err
+	}
+
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		if err := reserveQuota(ctx, tx, workspaceID, store.UsageMembers, 1); err != nil {
+			return err
+		}
+		return tx.Workspaces.ReactivateWorkspaceMember(ctx, workspaceID, targetUserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventMemberReactivated, requesterID, map[string]any{
+			"workspace_id": workspaceID,
+			"user_id":      targetUserID,
+			"email":        target.Email,
+		})
+	}
+
+	return s.getMember(ctx, workspaceID, targetUserID)
+}
+
+// SetMemberRate sets the hourly rate of a member. Only workspace owners and
+// admins manage rates; deactivated members keep theirs for the time they
+// already logged.
+func (s *WorkspaceService) SetMemberRate(ctx context.Context, requesterID, workspaceID, targetUserID uuid.UUID, input SetMemberRateInput) (*store.WorkspaceMember, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, requesterID); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Workspaces.SetWorkspaceMemberRate(ctx, workspaceID, targetUserID, input.HourlyRate); err != nil {
+		if errors.Is(err, store.ErrNotMember) {
+			return nil, ErrNotWorkspaceMember
+		}
+		return nil, err
+	}
+
+	return s.getMember(ctx, workspaceID, targetUserID)
+}
+
+// ReassignMemberContent transfers everything assigned to or owned by one member
+// to another. The source member may be active or deactivated.
+func (s *WorkspaceService) ReassignMemberContent(ctx context.Context, requesterID, workspaceID, fromUserID uuid.UUID, input ReassignContentInput) (*ContentReassignment, error) {
+	requesterRole, err := workspaceRole(ctx, s.store, workspaceID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.getMember(ctx, workspaceID, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if requesterID != fromUserID {
+		if err := canManageMember(ctx, s.store, requesterRole, from); err != nil {
+			return nil, err
+		}
+	}
+
+	var reassignment *ContentReassignment
+	err = s.store.ExecTx(ctx, func(tx *store.Store) error {
+		var err error
+		reassignment, err = reassignMemberContent(ctx, tx, workspaceID, fromUserID, input.ToUserID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishReassignment(ctx, requesterID, reassignment)
+
+	return reassignment, nil
+}
+
+func (s *WorkspaceService) getMember(ctx context.Context, workspaceID, userID uuid.UUID) (*store.WorkspaceMember, error) {
+	member, err := s.store.Workspaces.GetWorkspaceMember(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotMember) {
+			return nil, ErrNotWorkspaceMember
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *WorkspaceService) publishReassignment(ctx context.Context, actorID uuid.UUID, reassignment *ContentReassignment) {
+	if reassignment == nil || s.eventBus == nil {
+		return
+	}
+
+	s.eventBus.Publish(ctx, events.EventMemberContentReassigned, actorID, map[string]any{
+		"workspace_id": reassignment.WorkspaceID,
+		"from_user_id": reassignment.FromUserID,
+		"to_user_id":   reassignment.ToUserID,
+		"moved":        reassignment.Moved,
+	})
+}
+
+// canManageMember applies the same hierarchy as member removal: only owners and
+// admins manage members, and admins cannot act on other admins or the owner.
+func canManageMember(ctx context.Context, st *store.Store, requesterRole string, target *store.WorkspaceMember) error {
+	if requesterRole != "owner" && requesterRole != "admin" {
+		return ErrForbidden
+	}
+
+	targetRole, err := withTeamGrant(ctx, st, target.WorkspaceID, target.UserID, target.Role)
+	if err != nil {
+		return err
+	}
+
+	if targetRole == "owner" || (requesterRole == "admin" && targetRole == "admin") {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func reassignMemberContent(ctx context.Context, tx *store.Store, workspaceID, fromUserID, toUserID uuid.UUID) (*ContentReassignment, error) {
+	if fromUserID == toUserID {
+		return nil, ErrInvalidReassignTarget
+	}
+
+	if _, err := tx.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, toUserID); err != nil {
+		if errors.Is(err, store.ErrNotMember) {
+			return nil, ErrInvalidReassignTarget
+		}
+		return nil, err
+	}
+
+	moved, err := tx.Workspaces.ReassignMemberContent(ctx, workspaceID, fromUserID, toUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContentReassignment{
+		WorkspaceID: workspaceID,
+		FromUserID:  fromUserID,
+		ToUserID:    toUserID,
+		Moved:       moved,
+	}, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/events"
+	"github.com/lukabrkovic/artemis/internal/store"
+)
+
+type UpdateWorkspaceSettingsInput struct {
+	Version                   *int        `json:"version,omitempty" validate:"omitempty,min=0"`
+	DefaultCurrency           *string     `json:"default_currency,omitempty" validate:"omitempty,iso4217"`
+	Timezone                  *string     `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Locale                    *string     `json:"locale,omitempty" validate:"omitempty,bcp47_language_tag"`
+	DateFormat                *string     `json:"date_format,omitempty" validate:"omitempty,date_format"`
+	FiscalYearStartMonth      *int        `json:"fiscal_year_start_month,omitempty" validate:"omitempty,min=1,max=12"`
+	InvoicePrefix             *string     `json:"invoice_prefix,omitempty" validate:"omitempty,invoice_prefix"`
+	BrandColor                *string     `json:"brand_color,omitempty" validate:"omitempty,hexcolor"`
+	LogoURL                   *string     `json:"logo_url,omitempty" validate:"omitempty,max=500,https_url_or_empty"`
+	DefaultJoinRole           *string     `json:"default_join_role,omitempty" validate:"omitempty,oneof=admin member"`
+	WorkingHoursPerDay        *float64    `json:"working_hours_per_day,omitempty" validate:"omitempty,gt=0,max=24"`
+	TimeRoundingMinutes       *int        `json:"time_rounding_minutes,omitempty" validate:"omitempty,oneof=0 1 5 6 10 15 30 60"`
+	TimeRoundingMode          *string     `json:"time_rounding_mode,omitempty" validate:"omitempty,oneof=nearest up down"`
+	TimesheetApproverIDs      []uuid.UUID `json:"timesheet_approver_ids,omitempty" validate:"omitempty,max=50"`
+	DefaultHourlyRate         *int64      `json:"default_hourly_rate,omitempty" validate:"omitempty,min=0"`
+	InvoiceReminderDays       []int       `json:"invoice_reminder_days,omitempty" validate:"omitempty,max=10,dive,min=-60,max=365"`
+	InvoiceReminderRepeatDays *int        `json:"invoice_reminder_repeat_days,omitempty" validate:"omitempty,min=0,max=365"`
+	BudgetAlertThresholds     []int       `json:"budget_alert_thresholds,omitempty" validate:"omitempty,max=10,dive,min=1,max=1000"`
+}
+
+func (s *WorkspaceService) GetSettings(ctx context.Context, userID, workspaceID uuid.UUID) (*store.WorkspaceSettings, error) {
+	_, err := s.store.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotMember) {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+
+	return getWorkspaceSettings(ctx, s.store, workspaceID)
+}
+
+func (s *WorkspaceService) UpdateSettings(ctx context.Context, userID, workspaceID uuid.UUID, input UpdateWorkspaceSettingsInput) (*store.WorkspaceSettings, error) {
+	if err := requireWorkspaceAdmin(ctx, s.store, workspaceID, userID); err != nil {
+		return nil, err
+	}
+
+	current, err := getWorkspaceSettings(ctx, s.store, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without an explicit version the caller accepts last-write-wins semantics.
+	expectedVersion := current.Version
+	if input.Version != nil {
+		expectedVersion = *input.Version
+	}
+
+	doc := current.Settings
+	if input.DefaultCurrency != nil {
+		doc.DefaultCurrency = *input.DefaultCurrency
+	}
+	if input.Timezone != nil {
+		doc.Timezone = *input.Timezone
+	}
+	if input.Locale != nil {
+		doc.Locale = *input.Locale
+	}
+	if input.DateFormat != nil {
+		doc.DateFormat = *input.DateFormat
+	}
+	if input.FiscalYearStartMonth != nil {
+		doc.FiscalYearStartMonth = *input.FiscalYearStartMonth
+	}
+	if input.InvoicePrefix != nil {
+		doc.InvoicePrefix = *input.InvoicePrefix
+	}
+	if input.BrandColor != nil {
+		doc.BrandColor = *input.BrandColor
+	}
+	applyLogoURL(&doc, input.LogoURL)
+	if input.DefaultJoinRole != nil {
+		doc.DefaultJoinRole = *input.DefaultJoinRole
+	}
+	if input.WorkingHoursPerDay != nil {
+		doc.WorkingHoursPerDay = *input.WorkingHoursPerDay
+	}
+	if input.TimeRoundingMinutes != nil {
+		doc.TimeRoundingMinutes = *input.TimeRoundingMinutes
+	}
+	if input.TimeRoundingMode != nil {
+		doc.TimeRoundingMode = *input.TimeRoundingMode
+	}
+	if input.TimesheetApproverIDs != nil {
+		approvers := make([]uuid.UUID, 0, len(input.TimesheetApproverIDs))
+		for _, approverID := range input.TimesheetApproverIDs {
+			if slices.Contains(approvers, approverID) {
+				continue
+			}
+			if err := checkAssignee(ctx, s.store, workspaceID, &approverID); err != nil {
+				return nil, err
+			}
+			approvers = append(approvers, approverID)
+		}
+		doc.TimesheetApproverIDs = approvers
+	}
+	if input.DefaultHourlyRate != nil {
+		doc.DefaultHourlyRate = *input.DefaultHourlyRate
+	}
+	if input.InvoiceReminderDays != nil {
+		days := slices.Clone(input.InvoiceReminderDays)
+		slices.Sort(days)
+		doc.InvoiceReminderDays = slices.Compact(days)
+	}
+	if input.InvoiceReminderRepeatDays != nil {
+		doc.InvoiceReminderRepeatDays = *input.InvoiceReminderRepeatDays
+	}
+	if input.BudgetAlertThresholds != nil {
+		thresholds := slices.Clone(input.BudgetAlertThresholds)
+		slices.Sort(thresholds)
+		doc.BudgetAlertThresholds = slices.Compact(thresholds)
+	}
+
+	settings, err := s.store.WorkspaceSettings.UpsertWorkspaceSettings(ctx, store.UpsertWorkspaceSettingsParams{
+		WorkspaceID:     workspaceID,
+		Settings:        doc,
+		ExpectedVersion: expectedVersion,
+		UpdatedBy:       userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.eventBus != nil {
+		s.eventBus.Publish(ctx, events.EventWorkspaceSettingsUpdated, userID, map[string]any{
+			"workspace_id": workspaceID,
+			"version":      settings.Version,
+		})
+	}
+
+	return settings, nil
+}
+
+// applyLogoURL sets the logo of doc to logoURL, leaves it alone when logoURL
+// is nil and clears it when logoURL is empty.
+func applyLogoURL(doc *store.SettingsDocument, logoURL *string) {
+	switch {
+	case logoURL == nil:
+	case *logoURL == "":
+		doc.LogoURL = nil
+	default:
+		doc.LogoURL = logoURL
+	}
+}
+
+// getWorkspaceSettings returns the stored settings, falling back to defaults
+// for workspaces that have never saved any. Version 0 marks an unsaved document.
+func getWorkspaceSettings(ctx context.Context, st *store.Store, workspaceID uuid.UUID) (*store.WorkspaceSettings, error) {
+	settings, err := st.WorkspaceSettings.GetWorkspaceSettings(ctx, workspaceID)
+	if err == nil {
+		return settings, nil
+	}
+	if !errors.Is(err, store.ErrWorkspaceSettingsNotFound) {
+		return nil, err
+	}
+
+	if _, err := st.Workspaces.GetWorkspaceByID(ctx, workspaceID); err != nil {
+		if errors.Is(err, store.ErrWorkspaceNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	return &store.WorkspaceSettings{
+		WorkspaceID: workspaceID,
+		Version:     0,
+		Settings:    store.DefaultSettingsDocument(),
+	}, nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/lukabrkovic/artemis/internal/store"
+	"github.com/lukabrkovic/artemis/internal/validator"
+)
+
+func TestUpdateWorkspaceSettingsLogoURL(t *testing.T) {
+	logo, empty := "https://cdn.example.com/logo.png", ""
+	insecure, relative := "http://cdn.example.com/logo.png", "logo.png"
+	tests := []struct {
+		name    string
+		logoURL *string
+		current *string
+		valid   bool
+		want    *string
+	}{
+		{name: "unchanged", logoURL: nil, current: &logo, valid: true, want: &logo},
+		{name: "set", logoURL: &logo, current: nil, valid: true, want: &logo},
+		{name: "empty clears", logoURL: &empty, current: &logo, valid: true, want: nil},
+		{name: "plain http", logoURL: &insecure, valid: false},
+		{name: "not a url", logoURL: &relative, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := UpdateWorkspaceSettingsInput{LogoURL: tt.logoURL}
+			err := validator.Struct(input)
+			if tt.valid != (err == nil) {
+				t.Fatalf("validator.Struct() error = %v, want valid %v", err, tt.valid)
+			}
+			if !tt.valid {
+				return
+			}
+
+			doc := store.SettingsDocument{LogoURL: tt.current}
+			applyLogoURL(&doc, input.LogoURL)
+			switch {
+			case tt.want == nil && doc.LogoURL != nil:
+				t.Errorf("LogoURL = %q, want nil", *doc.LogoURL)
+			case tt.want != nil && (doc.LogoURL == nil || *doc.LogoURL != *tt.want):
+				t.Errorf("LogoURL = %v, want %q", doc.LogoURL, *tt.want)
+			}
+		})
+	}
+}
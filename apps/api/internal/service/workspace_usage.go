@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/internal/store"
+)
+
+var ErrQuotaExceeded = errors.New("plan limit reached")
+
+// QuotaExceededError reports which limit of the workspace plan blocked the operation.
+type QuotaExceededError struct {
+	Metric store.UsageMetric
+	Limit  int64
+	PlanID string
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("plan limit reached: %s is limited to %d on the %s plan", e.Metric, e.Limit, e.PlanID)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+type UsageMetricReport struct {
+	Used  int64  `json:"used"`
+	Limit *int64 `json:"limit"`
+}
+
+type WorkspaceUsageReport struct {
+	Plan         *store.Plan       `json:"plan"`
+	Members      UsageMetricReport `json:"members"`
+	StorageBytes UsageMetricReport `json:"storage_bytes"`
+	Projects     UsageMetricReport `json:"projects"`
+	APICalls     UsageMetricReport `json:"api_calls"`
+	PeriodStart  time.Time         `json:"period_start"`
+}
+
+func (s *WorkspaceService) GetUsage(ctx context.Context, userID, workspaceID uuid.UUID) (*WorkspaceUsageReport, error) {
+	_, err := s.store.Workspaces.GetWorkspaceMemberRole(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotMember) {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+
+	plan, err := s.store.Plans.GetWorkspacePlan(ctx, workspaceID)
+	if err != nil {
+		if errors.Is(err, store.ErrWorkspaceNotFound) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	usage, err := s.store.Usage.GetWorkspaceUsage(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WorkspaceUsageReport{
+		Plan:         plan,
+		Members:      UsageMetricReport{Used: usage.Members, Limit: plan.MaxMembers},
+		StorageBytes: UsageMetricReport{Used: usage.StorageBytes, Limit: plan.MaxStorageBytes},
+		Projects:     UsageMetricReport{Used: usage.Projects, Limit: plan.MaxProjects},
+		APICalls:     UsageMetricReport{Used: usage.APICalls, Limit: plan.MaxAPICalls},
+		PeriodStart:  usage.PeriodStart,
+	}, nil
+}
+
+// reserveQuota increments a usage counter, failing with a QuotaExceededError if
+// the workspace plan does not allow it. Call it inside the transaction that
+// creates the entity so the counter and the rows cannot drift apart.
+func reserveQuota(ctx context.Context, tx *store.Store, workspaceID uuid.UUID, metric store.UsageMetric, delta int64) error {
+	plan, err := tx.Plans.GetWorkspacePlan(ctx, workspaceID)
+	if err != nil {
+		if errors.Is(err, store.ErrWorkspaceNotFound) {
+			return ErrWorkspaceNotFound
+		}
+		return err
+	}
+
+	limit := plan.Limit(metric)
+	err = tx.Usage.IncrementUsage(ctx, workspaceID, metric, delta, limit)
+	if errors.Is(err, store.ErrUsageLimitReached) {
+		return &QuotaExceededError{Metric: metric, Limit: *limit, PlanID: plan.ID}
+	}
+	return err
+}
+
+func releaseQuota(ctx context.Context, tx *store.Store, workspaceID uuid.UUID, metric store.UsageMetric, delta int64) error {
+	return tx.Usage.IncrementUsage(ctx, workspaceID, metric, -delta, nil)
+}
+
+// reserveStoredObject reserves storage quota for an object about to be
+// written on behalf of a workspace, and returns the bytes reserved.
+// Overwriting an object only reserves the size difference. The reservation
+// runs in a transaction of its own, so the usage counters are not held
+// locked while the object is uploaded; recordStoredObject settles it once
+// the upload is recorded, and releaseStoredObject gives it back otherwise.
+func reserveStoredObject(ctx context.Context, st *store.Store, workspaceID uuid.UUID, objectName string, size int64) (int64, error) {
+	var reserved int64
+	err := st.ExecTx(ctx, func(tx *store.Store) error {
+		previous, err := tx.Usage.GetStorageObjectSize(ctx, workspaceID, objectName)
+		if err != nil {
+			return err
+		}
+		reserved = max(size-previous, 0)
+		if reserved == 0 {
+			return nil
+		}
+		return reserveQuota(ctx, tx, workspaceID, store.UsageStorageBytes, reserved)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return reserved, nil
+}
+
+// recordStoredObject accounts for an uploaded object against the bytes
+// reserved for it. A shrinking object frees the difference, and writes of
+// the same object that landed since the reservation are settled here.
+func recordStoredObject(ctx context.Context, tx *store.Store, workspaceID uuid.UUID, objectName string, size, reserved int64) error {
+	previous, err := tx.Usage.GetStorageObjectSize(ctx, workspaceID, objectName)
+	if err != nil {
+		return err
+	}
+
+	if delta := size - previous - reserved; delta != 0 {
+		if err := tx.Usage.IncrementUsage(ctx, workspaceID, store.UsageStorageBytes, delta, nil); err != nil {
+			return err
+		}
+	}
+	return tx.Usage.UpsertStorageObject(ctx, workspaceID, objectName, size)
+}
+
+// releaseStoredObject gives back the reservation of an upload that failed or
+// was not recorded. It runs even when ctx was cancelled by the upload.
+func releaseStoredObject(ctx context.Context, st *store.Store, workspaceID uuid.UUID, reserved int64) error {
+	if reserved == 0 {
+		return nil
+	}
+	return releaseQuota(context.WithoutCancel(ctx), st, workspaceID, store.UsageStorageBytes, reserved)
+}
+
+func untrackStoredObject(ctx context.Context, tx *store.Store, workspaceID uuid.UUID, objectName string) error {
+	size, err := tx.Usage.DeleteStorageObject(ctx, workspaceID, objectName)
+	if err != nil || size == 0 {
+		return err
+	}
+	return releaseQuota(ctx, tx, workspaceID, store.UsageStorageBytes, size)
+}
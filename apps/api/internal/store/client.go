@@ -0,0 +1,407 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrClientNotFound        = errors.New("client not found")
+	ErrClientContactNotFound = errors.New("client contact not found")
+)
+
+// Client is a customer of the workspace. Project counts and billing figures
+// are computed when the client is read; amounts are in minor units of the
+// client's currency.
+type Client struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	WorkspaceID    uuid.UUID       `json:"workspace_id" db:"workspace_id"`
+	Name           string          `json:"name" db:"name"`
+	Email          *string         `json:"email" db:"email"`
+	Phone          *string         `json:"phone" db:"phone"`
+	Website        *string         `json:"website" db:"website"`
+	Address        *string         `json:"address" db:"address"`
+	Notes          *string         `json:"notes" db:"notes"`
+	Status         string          `json:"status" db:"status"`
+	Currency       string          `json:"currency" db:"currency"`
+	Tags           Tags            `json:"tags" db:"tags"`
+	OwnerID        *uuid.UUID      `json:"owner_id" db:"owner_id"`
+	CreatedBy      *uuid.UUID      `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+	DeletedAt      *time.Time      `json:"-" db:"deleted_at"`
+	ActiveProjects int64           `json:"active_projects" db:"active_projects"`
+	TotalProjects  int64           `json:"total_projects" db:"total_projects"`
+	TotalRevenue   int64           `json:"total_revenue" db:"total_revenue"`
+	Outstanding    int64           `json:"outstanding" db:"outstanding"`
+	Credit         int64           `json:"credit" db:"credit"`
+	PrimaryContact *string         `json:"primary_contact" db:"primary_contact"`
+	Contacts       []ClientContact `json:"contacts,omitempty" db:"-"`
+}
+
+type ClientContact struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	ClientID  uuid.UUID `json:"client_id" db:"client_id"`
+	Name      string    `json:"name" db:"name"`
+	Email     *string   `json:"email" db:"email"`
+	Phone     *string   `json:"phone" db:"phone"`
+	Title     *string   `json:"title" db:"title"`
+	IsPrimary bool      `json:"is_primary" db:"is_primary"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type ClientStatusChange struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	ClientID      uuid.UUID  `json:"client_id" db:"client_id"`
+	FromStatus    *string    `json:"from_status" db:"from_status"`
+	ToStatus      string     `json:"to_status" db:"to_status"`
+	Note          *string    `json:"note" db:"note"`
+	ChangedBy     *uuid.UUID `json:"changed_by" db:"changed_by"`
+	ChangedByName *string    `json:"changed_by_name" db:"changed_by_name"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CreateClientParams struct {
+	WorkspaceID uuid.UUID
+	Name        string
+	Email       *string
+	Phone       *string
+	Website     *string
+	Address     *string
+	Notes       *string
+	Status      string
+	Currency    string
+	Tags        Tags
+	OwnerID     *uuid.UUID
+	CreatedBy   uuid.UUID
+}
+
+type UpdateClientParams struct {
+	ID          uuid.UUID
+	WorkspaceID uuid.UUID
+	Name        string
+	Email       *string
+	Phone       *string
+	Website     *string
+	Address     *string
+	Notes       *string
+	Status      string
+	Currency    string
+	Tags        Tags
+	OwnerID     *uuid.UUID
+}
+
+type ClientContactParams struct {
+	ID        uuid.UUID
+	ClientID  uuid.UUID
+	Name      string
+	Email     *string
+	Phone     *string
+	Title     *string
+	IsPrimary bool
+}
+
+// ClientFilter narrows a client listing. Empty fields are ignored.
+type ClientFilter struct {
+	Statuses          []string
+	Tag               string
+	OwnerID           *uuid.UUID
+	HasOutstanding    *bool
+	HasActiveProjects *bool
+}
+
+type ClientRepository interface {
+	CreateClient(ctx context.Context, arg CreateClientParams) (*Client, error)
+	GetClient(ctx context.Context, workspaceID, clientID uuid.UUID) (*Client, error)
+	ListClients(ctx context.Context, workspaceID uuid.UUID, filter ClientFilter, filters FilterParams) ([]Client, int64, error)
+	UpdateClient(ctx context.Context, arg UpdateClientParams) (*Client, error)
+	DeleteClient(ctx context.Context, workspaceID, clientID uuid.UUID) error
+
+	CreateClientStatusChange(ctx context.Context, clientID uuid.UUID, fromStatus *string, toStatus string, note *string, changedBy uuid.UUID) error
+	ListClientStatusChanges(ctx context.Context, clientID uuid.UUID) ([]ClientStatusChange, error)
+
+	CreateClientContact(ctx context.Context, arg ClientContactParams) (*ClientContact, error)
+	GetClientContact(ctx context.Context, clientID, contactID uuid.UUID) (*ClientContact, error)
+	ListClientContacts(ctx context.Context, clientID uuid.UUID) ([]ClientContact, error)
+	UpdateClientContact(ctx context.Context, arg ClientContactParams) (*ClientContact, error)
+	DeleteClientContact(ctx context.Context, clientID, contactID uuid.UUID) error
+	ClearPrimaryClientContact(ctx context.Context, clientID uuid.UUID) error
+}
+
+type clientRepository struct {
+	db DBTX
+}
+
+func NewClientRepository(db DBTX) ClientRepository {
+	return &clientRepository{db: db}
+}
+
+// Projects in planning, in progress or in review count as active.
+const clientActiveProjects = `(SELECT COUNT(*) FROM projects p WHERE p.client_id = c.id AND p.deleted_at IS NULL AND p.status IN ('planning', 'in-progress', 'review'))`
+
+// Revenue is what the client has paid on its invoices; the outstanding
+// balance is what is still owed on invoices that have been sent. Credit is
+// unspent overpayment. All three only count amounts in the client's currency;
+// invoices in other currencies are left to the revenue report, which converts
+// them.
+const (
+	clientTotalRevenue = `(SELECT COALESCE(SUM(i.amount_paid), 0)::bigint FROM invoices i WHERE i.client_id = c.id AND i.currency = c.currency AND i.deleted_at IS NULL)`
+	clientOutstanding  = `(SELECT COALESCE(SUM(i.balance_due), 0)::bigint FROM invoices i WHERE i.client_id = c.id AND i.currency = c.currency AND i.deleted_at IS NULL AND i.status IN ('sent', 'viewed', 'overdue'))`
+	clientCredit       = `(SELECT COALESCE(SUM(cc.amount), 0)::bigint FROM client_credits cc WHERE cc.client_id = c.id AND cc.currency = c.currency)`
+)
+
+const clientColumns = `
+	c.*,
+	` + clientActiveProjects + ` AS active_projects,
+	(SELECT COUNT(*) FROM projects p WHERE p.client_id = c.id AND p.deleted_at IS NULL) AS total_projects,
+	` + clientTotalRevenue + ` AS total_revenue,
+	` + clientOutstanding + ` AS outstanding,
+	` + clientCredit + ` AS credit,
+	(SELECT cc.name FROM client_contacts cc WHERE cc.client_id = c.id AND cc.is_primary) AS primary_contact
+`
+
+func (r *clientRepository) CreateClient(ctx context.Context, arg CreateClientParams) (*Client, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO clients (
+			workspace_id, name, email, phone, website, address, notes,
+			status, currency, tags, owner_id, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query,
+		arg.WorkspaceID, arg.Name, arg.Email, arg.Phone, arg.Website, arg.Address, arg.Notes,
+		arg.Status, arg.Currency, arg.Tags, arg.OwnerID, arg.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetClient(ctx, arg.WorkspaceID, id)
+}
+
+func (r *clientRepository) GetClient(ctx context.Context, workspaceID, clientID uuid.UUID) (*Client, error) {
+	var client Client
+	query := `SELECT ` + clientColumns + ` FROM clients c WHERE c.id = $1 AND c.workspace_id = $2 AND c.deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &client, query, clientID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrClientNotFound
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *clientRepository) ListClients(ctx context.Context, workspaceID uuid.UUID, filter ClientFilter, filters FilterParams) ([]Client, int64, error) {
+	var clients []Client
+	var args []any
+	argPos := 1
+
+	where := fmt.Sprintf(` WHERE c.workspace_id = $%d AND c.deleted_at IS NULL`, argPos)
+	args = append(args, workspaceID)
+	argPos++
+
+	if len(filter.Statuses) > 0 {
+		where += fmt.Sprintf(` AND c.status::text = ANY($%d::text[])`, argPos)
+		args = append(args, filter.Statuses)
+		argPos++
+	}
+	if filter.Tag != "" {
+		where += fmt.Sprintf(` AND c.tags @> jsonb_build_array($%d::text)`, argPos)
+		args = append(args, filter.Tag)
+		argPos++
+	}
+	if filter.OwnerID != nil {
+		where += fmt.Sprintf(` AND c.owner_id = $%d`, argPos)
+		args = append(args, *filter.OwnerID)
+		argPos++
+	}
+	if filter.HasOutstanding != nil {
+		if *filter.HasOutstanding {
+			where += ` AND ` + clientOutstanding + ` > 0`
+		} else {
+			where += ` AND ` + clientOutstanding + ` = 0`
+		}
+	}
+	if filter.HasActiveProjects != nil {
+		if *filter.HasActiveProjects {
+			where += ` AND ` + clientActiveProjects + ` > 0`
+		} else {
+			where += ` AND ` + clientActiveProjects + ` = 0`
+		}
+	}
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND (c.name ILIKE $%d OR c.email ILIKE $%d OR EXISTS (
+			SELECT 1 FROM client_contacts cc WHERE cc.client_id = c.id AND (cc.name ILIKE $%d OR cc.email ILIKE $%d)
+		))`, argPos, argPos, argPos, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := filters.SortBy
+	switch sortBy {
+	case "name", "status", "updated_at":
+		sortBy = "c." + sortBy
+	case "total_revenue", "outstanding", "active_projects":
+	default:
+		sortBy = "c.created_at"
+	}
+
+	query := `SELECT ` + clientColumns + ` FROM clients c` + where +
+		fmt.Sprintf(` ORDER BY %s %s NULLS LAST, c.id LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &clients, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM clients c`+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return clients, total, nil
+}
+
+func (r *clientRepository) UpdateClient(ctx context.Context, arg UpdateClientParams) (*Client, error) {
+	query := `
+		UPDATE clients
+		SET name = $1, email = $2, phone = $3, website = $4, address = $5, notes = $6,
+			status = $7, currency = $8, tags = $9, owner_id = $10, updated_at = NOW()
+		WHERE id = $11 AND workspace_id = $12 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		arg.Name, arg.Email, arg.Phone, arg.Website, arg.Address, arg.Notes,
+		arg.Status, arg.Currency, arg.Tags, arg.OwnerID, arg.ID, arg.WorkspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrClientNotFound
+	}
+	return r.GetClient(ctx, arg.WorkspaceID, arg.ID)
+}
+
+// DeleteClient soft-deletes the client. Its projects keep the reference so
+// their history stays intact.
+func (r *clientRepository) DeleteClient(ctx context.Context, workspaceID, clientID uuid.UUID) error {
+	query := `UPDATE clients SET deleted_at = NOW() WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, clientID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrClientNotFound
+	}
+	return nil
+}
+
+func (r *clientRepository) CreateClientStatusChange(ctx context.Context, clientID uuid.UUID, fromStatus *string, toStatus string, note *string, changedBy uuid.UUID) error {
+	query := `
+		INSERT INTO client_status_changes (client_id, from_status, to_status, note, changed_by)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.db.ExecContext(ctx, query, clientID, fromStatus, toStatus, note, changedBy)
+	return err
+}
+
+func (r *clientRepository) ListClientStatusChanges(ctx context.Context, clientID uuid.UUID) ([]ClientStatusChange, error) {
+	changes := []ClientStatusChange{}
+	query := `
+		SELECT sc.*, u.name AS changed_by_name
+		FROM client_status_changes sc
+		LEFT JOIN users u ON u.id = sc.changed_by
+		WHERE sc.client_id = $1
+		ORDER BY sc.created_at DESC, sc.id
+	`
+	err := r.db.SelectContext(ctx, &changes, query, clientID)
+	return changes, err
+}
+
+func (r *clientRepository) CreateClientContact(ctx context.Context, arg ClientContactParams) (*ClientContact, error) {
+	contact := &ClientContact{}
+	query := `
+		INSERT INTO client_contacts (client_id, name, email, phone, title, is_primary)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, contact, query, arg.ClientID, arg.Name, arg.Email, arg.Phone, arg.Title, arg.IsPrimary)
+	if err != nil {
+		return nil, err
+	}
+	return contact, nil
+}
+
+func (r *clientRepository) GetClientContact(ctx context.Context, clientID, contactID uuid.UUID) (*ClientContact, error) {
+	var contact ClientContact
+	query := `SELECT * FROM client_contacts WHERE id = $1 AND client_id = $2`
+	err := r.db.GetContext(ctx, &contact, query, contactID, clientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrClientContactNotFound
+		}
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// ListClientContacts returns the primary contact first, then the others by name.
+func (r *clientRepository) ListClientContacts(ctx context.Context, clientID uuid.UUID) ([]ClientContact, error) {
+	contacts := []ClientContact{}
+	query := `SELECT * FROM client_contacts WHERE client_id = $1 ORDER BY is_primary DESC, name, id`
+	err := r.db.SelectContext(ctx, &contacts, query, clientID)
+	return contacts, err
+}
+
+func (r *clientRepository) UpdateClientContact(ctx context.Context, arg ClientContactParams) (*ClientContact, error) {
+	contact := &ClientContact{}
+	query := `
+		UPDATE client_contacts
+		SET name = $1, email = $2, phone = $3, title = $4, is_primary = $5, updated_at = NOW()
+		WHERE id = $6 AND client_id = $7
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, contact, query, arg.Name, arg.Email, arg.Phone, arg.Title, arg.IsPrimary, arg.ID, arg.ClientID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrClientContactNotFound
+		}
+		return nil, err
+	}
+	return contact, nil
+}
+
+func (r *clientRepository) DeleteClientContact(ctx context.Context, clientID, contactID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM client_contacts WHERE id = $1 AND client_id = $2`, contactID, clientID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrClientContactNotFound
+	}
+	return nil
+}
+
+func (r *clientRepository) ClearPrimaryClientContact(ctx context.Context, clientID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE client_contacts SET is_primary = FALSE, updated_at = NOW() WHERE client_id = $1 AND is_primary`, clientID)
+	return err
+}
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrCommentNotFound = errors.New("comment not found")
+
+type Comment struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID     uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	ResourceType    string     `json:"resource_type" db:"resource_type"`
+	ResourceID      uuid.UUID  `json:"resource_id" db:"resource_id"`
+	ParentID        *uuid.UUID `json:"parent_id" db:"parent_id"`
+	AuthorID        *uuid.UUID `json:"author_id" db:"author_id"`
+	AuthorName      *string    `json:"author_name" db:"author_name"`
+	AuthorAvatarURL *string    `json:"author_avatar_url" db:"author_avatar_url"`
+	Body            string     `json:"body" db:"body"`
+	Mentions        UUIDs      `json:"mentions" db:"mentions"`
+	EditedAt        *time.Time `json:"edited_at" db:"edited_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time `json:"-" db:"deleted_at"`
+	ReplyCount      int64      `json:"reply_count" db:"reply_count"`
+	Replies         []Comment  `json:"replies,omitempty" db:"-"`
+}
+
+type CommentRevision struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	CommentID uuid.UUID  `json:"comment_id" db:"comment_id"`
+	Body      string     `json:"body" db:"body"`
+	EditedBy  *uuid.UUID `json:"edited_by" db:"edited_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CreateCommentParams struct {
+	WorkspaceID  uuid.UUID
+	ResourceType string
+	ResourceID   uuid.UUID
+	ParentID     *uuid.UUID
+	AuthorID     uuid.UUID
+	Body         string
+	Mentions     UUIDs
+}
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, arg CreateCommentParams) (*Comment, error)
+	GetComment(ctx context.Context, workspaceID, commentID uuid.UUID) (*Comment, error)
+	ListComments(ctx context.Context, workspaceID uuid.UUID, resourceType string, resourceID uuid.UUID, filters FilterParams) ([]Comment, int64, error)
+	ListReplies(ctx context.Context, parentIDs []uuid.UUID) ([]Comment, error)
+	UpdateComment(ctx context.Context, workspaceID, commentID uuid.UUID, body string, mentions UUIDs) (*Comment, error)
+	DeleteComment(ctx context.Context, workspaceID, commentID uuid.UUID) error
+	CreateCommentRevision(ctx context.Context, commentID uuid.UUID, body string, editedBy uuid.UUID) error
+	ListCommentRevisions(ctx context.Context, commentID uuid.UUID) ([]CommentRevision, error)
+}
+
+type commentRepository struct {
+	db DBTX
+}
+
+func NewCommentRepository(db DBTX) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+const commentColumns = `
+	c.*,
+	u.name AS author_name,
+	u.avatar_url AS author_avatar_url,
+	(SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id AND r.deleted_at IS NULL) AS reply_count
+`
+
+func (r *commentRepository) CreateComment(ctx context.Context, arg CreateCommentParams) (*Comment, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO comments (workspace_id, resource_type, resource_id, parent_id, author_id, body, mentions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query,
+		arg.WorkspaceID, arg.ResourceType, arg.ResourceID, arg.ParentID, arg.AuthorID, arg.Body, arg.Mentions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetComment(ctx, arg.WorkspaceID, id)
+}
+
+func (r *commentRepository) GetComment(ctx context.Context, workspaceID, commentID uuid.UUID) (*Comment, error) {
+	var comment Comment
+	query := `
+		SELECT ` + commentColumns + `
+		FROM comments c
+		LEFT JOIN users u ON c.author_id = u.id
+		WHERE c.id = $1 AND c.workspace_id = $2 AND c.deleted_at IS NULL
+	`
+	err := r.db.GetContext(ctx, &comment, query, commentID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListComments returns the top-level comments of a resource. Replies are
+// loaded separately with ListReplies.
+func (r *commentRepository) ListComments(ctx context.Context, workspaceID uuid.UUID, resourceType string, resourceID uuid.UUID, filters FilterParams) ([]Comment, int64, error) {
+	var comments []Comment
+	args := []any{workspaceID, resourceType, resourceID}
+	argPos := 4
+
+	where := ` WHERE c.workspace_id = $1 AND c.resource_type = $2 AND c.resource_id = $3 AND c.parent_id IS NULL AND c.deleted_at IS NULL`
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND c.body ILIKE $%d`, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := "c.created_at"
+	if filters.SortBy == "updated_at" {
+		sortBy = "c.updated_at"
+	}
+
+	query := `SELECT ` + commentColumns + ` FROM comments c LEFT JOIN users u ON c.author_id = u.id` + where +
+		fmt.Sprintf(` ORDER BY %s %s, c.id LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &comments, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM comments c`+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// ListReplies returns the replies to all given comments, oldest first.
+func (r *commentRepository) ListReplies(ctx context.Context, parentIDs []uuid.UUID) ([]Comment, error) {
+	var replies []Comment
+	query := `
+		SELECT ` + commentColumns + `
+		FROM comments c
+		LEFT JOIN users u ON c.author_id = u.id
+		WHERE c.parent_id::text = ANY($1::text[]) AND c.deleted_at IS NULL
+		ORDER BY c.created_at ASC, c.id
+	`
+	err := r.db.SelectContext(ctx, &replies, query, uuidStrings(parentIDs))
+	return replies, err
+}
+
+func (r *commentRepository) UpdateComment(ctx context.Context, workspaceID, commentID uuid.UUID, body string, mentions UUIDs) (*Comment, error) {
+	query := `
+		UPDATE comments
+		SET body = $1, mentions = $2, edited_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, body, mentions, commentID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrCommentNotFound
+	}
+	return r.GetComment(ctx, workspaceID, commentID)
+}
+
+// DeleteComment soft-deletes the comment together with its replies.
+func (r *commentRepository) DeleteComment(ctx context.Context, workspaceID, commentID uuid.UUID) error {
+	query := `
+		UPDATE comments
+		SET deleted_at = NOW()
+		WHERE (id = $1 OR parent_id = $1) AND workspace_id = $2 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, commentID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+func (r *commentRepository) CreateCommentRevision(ctx context.Context, commentID uuid.UUID, body string, editedBy uuid.UUID) error {
+	query := `INSERT INTO comment_revisions (comment_id, body, edited_by) VALUES ($1, $2, $3)`
+	_, err := r.db.ExecContext(ctx, query, commentID, body, editedBy)
+	return err
+}
+
+func (r *commentRepository) ListCommentRevisions(ctx context.Context, commentID uuid.UUID) ([]CommentRevision, error) {
+	var revisions []CommentRevision
+	query := `SELECT * FROM comment_revisions WHERE comment_id = $1 ORDER BY created_at DESC, id`
+	err := r.db.SelectContext(ctx, &revisions, query, commentID)
+	return revisions, err
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrEmailVerificationInvalid = errors.New("email verification link is invalid or expired")
+
+type EmailVerification struct {
+	TokenHash string    `json:"-" db:"token_hash"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Email     string    `json:"email" db:"email"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateEmailVerificationParams struct {
+	TokenHash string
+	UserID    uuid.UUID
+	Email     string
+	ExpiresAt time.Time
+}
+
+type EmailVerificationRepository interface {
+	CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) (*EmailVerification, error)
+	ConsumeEmailVerification(ctx context.Context, tokenHash string) (*EmailVerification, error)
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) error
+}
+
+type emailVerificationRepository struct {
+	db DBTX
+}
+
+func NewEmailVerificationRepository(db DBTX) EmailVerificationRepository {
+	return &emailVerificationRepository{db: db}
+}
+
+// CreateEmailVerification stores a new link and drops the expired links of
+// the user.
+func (r *emailVerificationRepository) CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) (*EmailVerification, error) {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM email_verifications WHERE user_id = $1 AND expires_at <= NOW()`, arg.UserID); err != nil {
+		return nil, err
+	}
+
+	verification := &EmailVerification{}
+	query := `
+		INSERT INTO email_verifications (token_hash, user_id, email, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, verification, query, arg.TokenHash, arg.UserID, arg.Email, arg.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return verification, nil
+}
+
+// ConsumeEmailVerification deletes a link that has not expired and returns
+// it, so each link verifies at most once.
+func (r *emailVerificationRepository) ConsumeEmailVerification(ctx context.Context, tokenHash string) (*EmailVerification, error) {
+	var verification EmailVerification
+	query := `DELETE FROM email_verifications WHERE token_hash = $1 AND expires_at > NOW() RETURNING *`
+	err := r.db.GetContext(ctx, &verification, query, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEmailVerificationInvalid
+		}
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// MarkEmailVerified records that the user owns email. It fails when the user
+// has changed their email since the link was sent, and drops the other links
+// of the user once it succeeds.
+func (r *emailVerificationRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) error {
+	query := `
+		UPDATE users
+		SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+		WHERE id = $1 AND email = $2 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, userID, email)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrEmailVerificationInvalid
+	}
+
+	_, err = r.db.ExecContext(ctx, `DELETE FROM email_verifications WHERE user_id = $1`, userID)
+	return err
+}
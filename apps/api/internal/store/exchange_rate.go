@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+var ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+// ExchangeRate is the units of QuoteCurrency one unit of BaseCurrency bought
+// on RateDate, as a provider reported it.
+type ExchangeRate struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	BaseCurrency  string     `json:"base_currency" db:"base_currency"`
+	QuoteCurrency string     `json:"quote_currency" db:"quote_currency"`
+	RateDate      time.Time  `json:"rate_date" db:"rate_date"`
+	Rate          money.Rate `json:"rate" db:"rate" swaggertype:"number"`
+	PublishedOn   time.Time  `json:"published_on" db:"published_on"`
+	Source        string     `json:"source" db:"source"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type SaveExchangeRateParams struct {
+	BaseCurrency  string
+	QuoteCurrency string
+	RateDate      time.Time
+	Rate          money.Rate
+	PublishedOn   time.Time
+	Source        string
+}
+
+// UnratedDocument is an invoice, payment or expense that has no exchange rate to the
+// workspace base currency yet. Date is the document date the rate is taken
+// on.
+type UnratedDocument struct {
+	ID          uuid.UUID `db:"id"`
+	WorkspaceID uuid.UUID `db:"workspace_id"`
+	Currency    string    `db:"currency"`
+	Date        time.Time `db:"document_date"`
+}
+
+type ExchangeRateRepository interface {
+	GetExchangeRate(ctx context.Context, base, quote string, date time.Time) (*ExchangeRate, error)
+	SaveExchangeRate(ctx context.Context, arg SaveExchangeRateParams) (*ExchangeRate, error)
+
+	ListUnratedInvoices(ctx context.Context, after uuid.UUID, limit int) ([]UnratedDocument, error)
+	ListUnratedPayments(ctx context.Context, after uuid.UUID, limit int) ([]UnratedDocument, error)
+	ListUnratedExpenses(ctx context.Context, after uuid.UUID, limit int) ([]UnratedDocument, error)
+	SetInvoiceExchangeRate(ctx context.Context, invoiceID uuid.UUID, baseCurrency string, rate money.Rate) error
+	SetPaymentExchangeRate(ctx context.Context, paymentID uuid.UUID, baseCurrency string, rate money.Rate) error
+	SetExpenseExchangeRate(ctx context.Context, expenseID uuid.UUID, baseCurrency string, rate money.Rate) error
+}
+
+type exchangeRateRepository struct {
+	db DBTX
+}
+
+func NewExchangeRateRepository(db DBTX) ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+func (r *exchangeRateRepository) GetExchangeRate(ctx context.Context, base, quote string, date time.Time) (*ExchangeRate, error) {
+	var rate ExchangeRate
+	query := `SELECT * FROM exchange_rates WHERE base_currency = $1 AND quote_currency = $2 AND rate_date = $3`
+	err := r.db.GetContext(ctx, &rate, query, base, quote, date)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExchangeRateNotFound
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// SaveExchangeRate stores a rate unless one is already stored for the same
+// currencies and date, and returns the stored rate. The first rate saved
+// wins, so concurrent lookups agree on one rate.
+func (r *exchangeRateRepository) SaveExchangeRate(ctx context.Context, arg SaveExchangeRateParams) (*ExchangeRate, error) {
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate_date, rate, published_on, source)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (base_currency, quote_currency, rate_date) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, arg.BaseCurrency, arg.QuoteCurrency, arg.RateDate, arg.Rate, arg.PublishedOn, arg.Source)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetExchangeRate(ctx, arg.BaseCurrency, arg.QuoteCurrency, arg.RateDate)
+}
+
+// ListUnratedInvoices returns issued invoices without a rate, by id after
+// after, so callers can page past invoices whose rate is not available.
+func (r *exchangeRateRepository) ListUnratedInvoices(ctx context.Context, after uuid.UUID, limit int) ([]UnratedDocument, error) {
+	var documents []UnratedDocument
+	query := `
+		SELECT id, workspace_id, currency, issue_date AS document_date
+		FROM invoices
+		WHERE exchange_rate IS NULL AND issue_date IS NOT NULL AND status <> 'draft' AND deleted_at IS NULL AND id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+	err := r.db.SelectContext(ctx, &documents, query, after, limit)
+	return documents, err
+}
+
+// ListUnratedPayments returns payments and refunds without a rate, by id
+// after after.
+func (r *exchangeRateRepository) ListUnratedPayments(ctx context.Context, after uuid.UUID, limit int) ([]UnratedDocument, error) {
+	var documents []UnratedDocument
+	query := `
+		SELECT id, workspace_id, currency, paid_on AS document_date
+		FROM payments
+		WHERE exchange_rate IS NULL AND id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+	err := r.db.SelectContext(ctx, &documents, query, after, limit)
+	return documents, err
+}
+
+// ListUnratedExpenses returns expenses without a rate, by id after after.
+func (r *exchangeRateRepository) ListUnratedExpenses(ctx context.Context, after uuid.UUID, limit int) ([]UnratedDocument, error) {
+	var documents []UnratedDocument
+	query := `
+		SELECT id, workspace_id, currency, spent_on AS document_date
+		FROM expenses
+		WHERE exchange_rate IS NULL AND deleted_at IS NULL AND id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+	err := r.db.SelectContext(ctx, &documents, query, after, limit)
+	return documents, err
+}
+
+// SetInvoiceExchangeRate records the rate of an invoice once; a rate that is
+// already set is kept.
+func (r *exchangeRateRepository) SetInvoiceExchangeRate(ctx context.Context, invoiceID uuid.UUID, baseCurrency string, rate money.Rate) error {
+	query := `UPDATE invoices SET base_currency = $1, exchange_rate = $2 WHERE id = $3 AND exchange_rate IS NULL`
+	_, err := r.db.ExecContext(ctx, query, baseCurrency, rate, invoiceID)
+	return err
+}
+
+// SetPaymentExchangeRate records the rate of a payment once.
+func (r *exchangeRateRepository) SetPaymentExchangeRate(ctx context.Context, paymentID uuid.UUID, baseCurrency string, rate money.Rate) error {
+	query := `UPDATE payments SET base_currency = $1, exchange_rate = $2 WHERE id = $3 AND exchange_rate IS NULL`
+	_, err := r.db.ExecContext(ctx, query, baseCurrency, rate, paymentID)
+	return err
+}
+
+// SetExpenseExchangeRate records the rate of an expense once.
+func (r *exchangeRateRepository) SetExpenseExchangeRate(ctx context.Context, expenseID uuid.UUID, baseCurrency string, rate money.Rate) error {
+	query := `UPDATE expenses SET base_currency = $1, exchange_rate = $2 WHERE id = $3 AND exchange_rate IS NULL`
+	_, err := r.db.ExecContext(ctx, query, baseCurrency, rate, expenseID)
+	return err
+}
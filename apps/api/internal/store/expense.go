@@ -0,0 +1,699 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+var (
+	ErrExpenseNotFound          = errors.New("expense not found")
+	ErrExpenseCategoryNotFound  = errors.New("expense category not found")
+	ErrExpenseCategoryNameTaken = errors.New("expense category name already taken")
+	ErrExpenseNotPending        = errors.New("expense is not awaiting review")
+	ErrExpenseNotReimbursable   = errors.New("expense is not approved for reimbursement")
+	ErrNoReceiptSuggestions     = errors.New("expense has no receipt suggestions")
+)
+
+type ExpenseCategory struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	WorkspaceID  uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	Name         string    `json:"name" db:"name"`
+	Description  *string   `json:"description" db:"description"`
+	Color        *string   `json:"color" db:"color"`
+	ExpenseCount int64     `json:"expense_count" db:"expense_count"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type ExpenseCategoryParams struct {
+	ID          uuid.UUID
+	WorkspaceID uuid.UUID
+	Name        string
+	Description *string
+	Color       *string
+}
+
+// Expense is money a member spent for the workspace, in minor units of its
+// currency. The receipt is kept in the private bucket and only handed out as
+// a presigned link.
+type Expense struct {
+	ID                     uuid.UUID   `json:"id" db:"id"`
+	WorkspaceID            uuid.UUID   `json:"workspace_id" db:"workspace_id"`
+	UserID                 uuid.UUID   `json:"user_id" db:"user_id"`
+	CategoryID             *uuid.UUID  `json:"category_id" db:"category_id"`
+	ProjectID              *uuid.UUID  `json:"project_id" db:"project_id"`
+	ClientID               *uuid.UUID  `json:"client_id" db:"client_id"`
+	Merchant               string      `json:"merchant" db:"merchant"`
+	Description            *string     `json:"description" db:"description"`
+	Amount                 int64       `json:"amount" db:"amount"`
+	Currency               string      `json:"currency" db:"currency"`
+	SpentOn                time.Time   `json:"spent_on" db:"spent_on"`
+	PaymentMethod          string      `json:"payment_method" db:"payment_method"`
+	Reimbursable           bool        `json:"reimbursable" db:"reimbursable"`
+	Billable               bool        `json:"billable" db:"billable"`
+	Billed                 bool        `json:"billed" db:"billed"`
+	InvoiceID              *uuid.UUID  `json:"invoice_id" db:"invoice_id"`
+	Notes                  *string     `json:"notes" db:"notes"`
+	Status                 string      `json:"status" db:"status"`
+	ReviewedBy             *uuid.UUID  `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt             *time.Time  `json:"reviewed_at" db:"reviewed_at"`
+	RejectionReason        *string     `json:"rejection_reason" db:"rejection_reason"`
+	ReimbursedBy           *uuid.UUID  `json:"reimbursed_by" db:"reimbursed_by"`
+	ReimbursedAt           *time.Time  `json:"reimbursed_at" db:"reimbursed_at"`
+	ReimbursementReference *string     `json:"reimbursement_reference" db:"reimbursement_reference"`
+	ReceiptObject          *string     `json:"-" db:"receipt_object"`
+	ReceiptName            *string     `json:"receipt_name" db:"receipt_name"`
+	ReceiptContentType     *string     `json:"receipt_content_type" db:"receipt_content_type"`
+	ReceiptSize            *int64      `json:"receipt_size" db:"receipt_size"`
+	ReceiptUploadedAt      *time.Time  `json:"receipt_uploaded_at" db:"receipt_uploaded_at"`
+	ExtractionStatus       *string     `json:"receipt_extraction_status" db:"receipt_extraction_status"`
+	ExtractionError        *string     `json:"receipt_extraction_error" db:"receipt_extraction_error"`
+	ExtractedAt            *time.Time  `json:"receipt_extracted_at" db:"receipt_extracted_at"`
+	Extractor              *string     `json:"-" db:"receipt_extractor"`
+	ExtractionAttempts     int         `json:"-" db:"receipt_extraction_attempts"`
+	SuggestedMerchant      *string     `json:"suggested_merchant" db:"suggested_merchant"`
+	SuggestedAmount        *int64      `json:"suggested_amount" db:"suggested_amount"`
+	SuggestedCurrency      *string     `json:"suggested_currency" db:"suggested_currency"`
+	SuggestedSpentOn       *time.Time  `json:"suggested_spent_on" db:"suggested_spent_on"`
+	BaseCurrency           *string     `json:"base_currency" db:"base_currency"`
+	ExchangeRate           *money.Rate `json:"exchange_rate" db:"exchange_rate" swaggertype:"number"`
+	CreatedAt              time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time   `json:"updated_at" db:"updated_at"`
+	DeletedAt              *time.Time  `json:"-" db:"deleted_at"`
+	UserName               *string     `json:"user_name" db:"user_name"`
+	CategoryName           *string     `json:"category_name" db:"category_name"`
+	ProjectName            *string     `json:"project_name" db:"project_name"`
+	ClientName             *string     `json:"client_name" db:"client_name"`
+}
+
+type CreateExpenseParams struct {
+	WorkspaceID   uuid.UUID
+	UserID        uuid.UUID
+	CategoryID    *uuid.UUID
+	ProjectID     *uuid.UUID
+	ClientID      *uuid.UUID
+	Merchant      string
+	Description   *string
+	Amount        int64
+	Currency      string
+	SpentOn       time.Time
+	PaymentMethod string
+	Reimbursable  bool
+	Billable      bool
+	Notes         *string
+}
+
+type UpdateExpenseParams struct {
+	ID            uuid.UUID
+	WorkspaceID   uuid.UUID
+	CategoryID    *uuid.UUID
+	ProjectID     *uuid.UUID
+	ClientID      *uuid.UUID
+	Merchant      string
+	Description   *string
+	Amount        int64
+	Currency      string
+	SpentOn       time.Time
+	PaymentMethod string
+	Reimbursable  bool
+	Billable      bool
+	Notes         *string
+}
+
+type ReviewExpenseParams struct {
+	ID              uuid.UUID
+	WorkspaceID     uuid.UUID
+	Status          string
+	ReviewedBy      uuid.UUID
+	RejectionReason *string
+}
+
+type ReimburseExpenseParams struct {
+	ID           uuid.UUID
+	WorkspaceID  uuid.UUID
+	ReimbursedBy uuid.UUID
+	ReimbursedAt time.Time
+	Reference    *string
+}
+
+// ExpenseReceipt describes a stored receipt file.
+type ExpenseReceipt struct {
+	Object      string
+	Name        string
+	ContentType string
+	Size        int64
+}
+
+// SaveReceiptExtractionParams is the outcome of reading the receipt that was
+// uploaded at UploadedAt. Suggestions that were not found are nil.
+type SaveReceiptExtractionParams struct {
+	WorkspaceID uuid.UUID
+	ExpenseID   uuid.UUID
+	UploadedAt  time.Time
+	Status      string
+	Error       *string
+	Extractor   *string
+	Merchant    *string
+	Amount      *int64
+	Currency    *string
+	SpentOn     *time.Time
+}
+
+// ExpenseFilter narrows an expense listing. From and To bound the day the
+// money was spent, both inclusive. Empty fields are ignored.
+type ExpenseFilter struct {
+	UserID       *uuid.UUID
+	CategoryID   *uuid.UUID
+	ProjectID    *uuid.UUID
+	ClientID     *uuid.UUID
+	Statuses     []string
+	Currency     string
+	Billable     *bool
+	Billed       *bool
+	Reimbursable *bool
+	From         *time.Time
+	To           *time.Time
+}
+
+// UnbilledExpenseFilter selects the approved billable expenses of a client or
+// project, in the invoice currency, that have not been invoiced yet. From and
+// To bound the day the money was spent, both inclusive.
+type UnbilledExpenseFilter struct {
+	ClientID  *uuid.UUID
+	ProjectID *uuid.UUID
+	Currency  string
+	From      time.Time
+	To        time.Time
+}
+
+type ExpenseRepository interface {
+	CreateExpenseCategory(ctx context.Context, arg ExpenseCategoryParams) (*ExpenseCategory, error)
+	GetExpenseCategory(ctx context.Context, workspaceID, categoryID uuid.UUID) (*ExpenseCategory, error)
+	ListExpenseCategories(ctx context.Context, workspaceID uuid.UUID) ([]ExpenseCategory, error)
+	UpdateExpenseCategory(ctx context.Context, arg ExpenseCategoryParams) (*ExpenseCategory, error)
+	DeleteExpenseCategory(ctx context.Context, workspaceID, categoryID uuid.UUID) error
+
+	CreateExpense(ctx context.Context, arg CreateExpenseParams) (*Expense, error)
+	GetExpense(ctx context.Context, workspaceID, expenseID uuid.UUID) (*Expense, error)
+	LockExpense(ctx context.Context, workspaceID, expenseID uuid.UUID) (*Expense, error)
+	ListExpenses(ctx context.Context, workspaceID uuid.UUID, filter ExpenseFilter, filters FilterParams) ([]Expense, int64, error)
+	UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (*Expense, error)
+	DeleteExpense(ctx context.Context, workspaceID, expenseID uuid.UUID) error
+	ReviewExpense(ctx context.Context, arg ReviewExpenseParams) (*Expense, error)
+	ReimburseExpense(ctx context.Context, arg ReimburseExpenseParams) (*Expense, error)
+	SetExpenseReceipt(ctx context.Context, workspaceID, expenseID uuid.UUID, receipt *ExpenseReceipt) error
+	ListPendingReceiptExtractions(ctx context.Context, uploadedBefore time.Time, limit int) ([]Expense, error)
+	StartReceiptExtraction(ctx context.Context, workspaceID, expenseID uuid.UUID, uploadedAt time.Time) (int, error)
+	SaveReceiptExtraction(ctx context.Context, arg SaveReceiptExtractionParams) (bool, error)
+	ResolveReceiptSuggestions(ctx context.Context, workspaceID, expenseID uuid.UUID, status string) error
+
+	ListUnbilledExpenses(ctx context.Context, workspaceID uuid.UUID, filter UnbilledExpenseFilter) ([]Expense, error)
+	MarkExpensesBilled(ctx context.Context, invoiceID uuid.UUID, expenseIDs []uuid.UUID) (int64, error)
+	ReleaseInvoiceExpenses(ctx context.Context, invoiceID uuid.UUID) error
+}
+
+type expenseRepository struct {
+	db DBTX
+}
+
+func NewExpenseRepository(db DBTX) ExpenseRepository {
+	return &expenseRepository{db: db}
+}
+
+const expenseCategoryColumns = `
+	ec.*,
+	(SELECT COUNT(*) FROM expenses e WHERE e.category_id = ec.id AND e.deleted_at IS NULL) AS expense_count
+`
+
+func (r *expenseRepository) CreateExpenseCategory(ctx context.Context, arg ExpenseCategoryParams) (*ExpenseCategory, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO expense_categories (workspace_id, name, description, color)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query, arg.WorkspaceID, arg.Name, arg.Description, arg.Color)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrExpenseCategoryNameTaken
+		}
+		return nil, err
+	}
+	return r.GetExpenseCategory(ctx, arg.WorkspaceID, id)
+}
+
+func (r *expenseRepository) GetExpenseCategory(ctx context.Context, workspaceID, categoryID uuid.UUID) (*ExpenseCategory, error) {
+	var category ExpenseCategory
+	query := `SELECT ` + expenseCategoryColumns + ` FROM expense_categories ec WHERE ec.id = $1 AND ec.workspace_id = $2`
+	err := r.db.GetContext(ctx, &category, query, categoryID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExpenseCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// ListExpenseCategories returns every category of the workspace by name.
+func (r *expenseRepository) ListExpenseCategories(ctx context.Context, workspaceID uuid.UUID) ([]ExpenseCategory, error) {
+	categories := []ExpenseCategory{}
+	query := `SELECT ` + expenseCategoryColumns + ` FROM expense_categories ec WHERE ec.workspace_id = $1 ORDER BY LOWER(ec.name)`
+	err := r.db.SelectContext(ctx, &categories, query, workspaceID)
+	return categories, err
+}
+
+func (r *expenseRepository) UpdateExpenseCategory(ctx context.Context, arg ExpenseCategoryParams) (*ExpenseCategory, error) {
+	query := `
+		UPDATE expense_categories
+		SET name = $1, description = $2, color = $3, updated_at = NOW()
+		WHERE id = $4 AND workspace_id = $5
+	`
+	result, err := r.db.ExecContext(ctx, query, arg.Name, arg.Description, arg.Color, arg.ID, arg.WorkspaceID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrExpenseCategoryNameTaken
+		}
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrExpenseCategoryNotFound
+	}
+	return r.GetExpenseCategory(ctx, arg.WorkspaceID, arg.ID)
+}
+
+// DeleteExpenseCategory removes a category. Its expenses are kept without a
+// category.
+func (r *expenseRepository) DeleteExpenseCategory(ctx context.Context, workspaceID, categoryID uuid.UUID) error {
+	query := `DELETE FROM expense_categories WHERE id = $1 AND workspace_id = $2`
+	result, err := r.db.ExecContext(ctx, query, categoryID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrExpenseCategoryNotFound
+	}
+	return nil
+}
+
+const expenseColumns = `
+	e.*, u.name AS user_name, ec.name AS category_name, p.name AS project_name, c.name AS client_name
+`
+
+const expenseJoins = `
+	FROM expenses e
+	LEFT JOIN users u ON u.id = e.user_id
+	LEFT JOIN expense_categories ec ON ec.id = e.category_id
+	LEFT JOIN projects p ON p.id = e.project_id
+	LEFT JOIN clients c ON c.id = e.client_id
+`
+
+func (r *expenseRepository) CreateExpense(ctx context.Context, arg CreateExpenseParams) (*Expense, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO expenses (
+			workspace_id, user_id, category_id, project_id, client_id, merchant, description,
+			amount, currency, spent_on, payment_method, reimbursable, billable, notes
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query,
+		arg.WorkspaceID, arg.UserID, arg.CategoryID, arg.ProjectID, arg.ClientID, arg.Merchant, arg.Description,
+		arg.Amount, arg.Currency, arg.SpentOn, arg.PaymentMethod, arg.Reimbursable, arg.Billable, arg.Notes,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetExpense(ctx, arg.WorkspaceID, id)
+}
+
+func (r *expenseRepository) GetExpense(ctx context.Context, workspaceID, expenseID uuid.UUID) (*Expense, error) {
+	var expense Expense
+	query := `SELECT ` + expenseColumns + expenseJoins + ` WHERE e.id = $1 AND e.workspace_id = $2 AND e.deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &expense, query, expenseID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExpenseNotFound
+		}
+		return nil, err
+	}
+	return &expense, nil
+}
+
+// LockExpense returns the expense and locks it until the surrounding
+// transaction ends.
+func (r *expenseRepository) LockExpense(ctx context.Context, workspaceID, expenseID uuid.UUID) (*Expense, error) {
+	var expense Expense
+	query := `SELECT ` + expenseColumns + expenseJoins + ` WHERE e.id = $1 AND e.workspace_id = $2 AND e.deleted_at IS NULL FOR UPDATE OF e`
+	err := r.db.GetContext(ctx, &expense, query, expenseID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExpenseNotFound
+		}
+		return nil, err
+	}
+	return &expense, nil
+}
+
+func (r *expenseRepository) ListExpenses(ctx context.Context, workspaceID uuid.UUID, filter ExpenseFilter, filters FilterParams) ([]Expense, int64, error) {
+	var expenses []Expense
+	var args []any
+	argPos := 1
+
+	where := fmt.Sprintf(` WHERE e.workspace_id = $%d AND e.deleted_at IS NULL`, argPos)
+	args = append(args, workspaceID)
+	argPos++
+
+	if filter.UserID != nil {
+		where += fmt.Sprintf(` AND e.user_id = $%d`, argPos)
+		args = append(args, *filter.UserID)
+		argPos++
+	}
+	if filter.CategoryID != nil {
+		where += fmt.Sprintf(` AND e.category_id = $%d`, argPos)
+		args = append(args, *filter.CategoryID)
+		argPos++
+	}
+	if filter.ProjectID != nil {
+		where += fmt.Sprintf(` AND e.project_id = $%d`, argPos)
+		args = append(args, *filter.ProjectID)
+		argPos++
+	}
+	if filter.ClientID != nil {
+		where += fmt.Sprintf(` AND e.client_id = $%d`, argPos)
+		args = append(args, *filter.ClientID)
+		argPos++
+	}
+	if len(filter.Statuses) > 0 {
+		where += fmt.Sprintf(` AND e.status::text = ANY($%d::text[])`, argPos)
+		args = append(args, filter.Statuses)
+		argPos++
+	}
+	if filter.Currency != "" {
+		where += fmt.Sprintf(` AND e.currency = $%d`, argPos)
+		args = append(args, filter.Currency)
+		argPos++
+	}
+	if filter.Billable != nil {
+		where += fmt.Sprintf(` AND e.billable = $%d`, argPos)
+		args = append(args, *filter.Billable)
+		argPos++
+	}
+	if filter.Billed != nil {
+		where += fmt.Sprintf(` AND e.billed = $%d`, argPos)
+		args = append(args, *filter.Billed)
+		argPos++
+	}
+	if filter.Reimbursable != nil {
+		where += fmt.Sprintf(` AND e.reimbursable = $%d`, argPos)
+		args = append(args, *filter.Reimbursable)
+		argPos++
+	}
+	if filter.From != nil {
+		where += fmt.Sprintf(` AND e.spent_on >= $%d`, argPos)
+		args = append(args, *filter.From)
+		argPos++
+	}
+	if filter.To != nil {
+		where += fmt.Sprintf(` AND e.spent_on <= $%d`, argPos)
+		args = append(args, *filter.To)
+		argPos++
+	}
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND (e.merchant ILIKE $%d OR e.description ILIKE $%d)`, argPos, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := filters.SortBy
+	switch sortBy {
+	case "spent_on", "amount", "merchant", "updated_at":
+		sortBy = "e." + sortBy
+	default:
+		sortBy = "e.created_at"
+	}
+
+	query := `SELECT ` + expenseColumns + expenseJoins + where +
+		fmt.Sprintf(` ORDER BY %s %s NULLS LAST, e.id LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &expenses, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM expenses e`+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return expenses, total, nil
+}
+
+// UpdateExpense changes an expense that has not been approved yet. Editing a
+// rejected expense submits it for review again.
+func (r *expenseRepository) UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (*Expense, error) {
+	query := `
+		UPDATE expenses
+		SET category_id = $1, project_id = $2, client_id = $3, merchant = $4, description = $5,
+			amount = $6, currency = $7, spent_on = $8, payment_method = $9, reimbursable = $10,
+			billable = $11, notes = $12,
+			status = 'pending', reviewed_by = NULL, reviewed_at = NULL, rejection_reason = NULL,
+			base_currency = CASE WHEN currency = $7 AND spent_on = $8 THEN base_currency END,
+			exchange_rate = CASE WHEN currency = $7 AND spent_on = $8 THEN exchange_rate END,
+			updated_at = NOW()
+		WHERE id = $13 AND workspace_id = $14 AND deleted_at IS NULL AND status IN ('pending', 'rejected')
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		arg.CategoryID, arg.ProjectID, arg.ClientID, arg.Merchant, arg.Description,
+		arg.Amount, arg.Currency, arg.SpentOn, arg.PaymentMethod, arg.Reimbursable,
+		arg.Billable, arg.Notes, arg.ID, arg.WorkspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrExpenseNotPending
+	}
+	return r.GetExpense(ctx, arg.WorkspaceID, arg.ID)
+}
+
+func (r *expenseRepository) DeleteExpense(ctx context.Context, workspaceID, expenseID uuid.UUID) error {
+	query := `UPDATE expenses SET deleted_at = NOW() WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, expenseID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrExpenseNotFound
+	}
+	return nil
+}
+
+// ReviewExpense approves or rejects a pending expense.
+func (r *expenseRepository) ReviewExpense(ctx context.Context, arg ReviewExpenseParams) (*Expense, error) {
+	query := `
+		UPDATE expenses
+		SET status = $1, reviewed_by = $2, reviewed_at = NOW(), rejection_reason = $3, updated_at = NOW()
+		WHERE id = $4 AND workspace_id = $5 AND deleted_at IS NULL AND status = 'pending'
+	`
+	result, err := r.db.ExecContext(ctx, query, arg.Status, arg.ReviewedBy, arg.RejectionReason, arg.ID, arg.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		if _, err := r.GetExpense(ctx, arg.WorkspaceID, arg.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrExpenseNotPending
+	}
+	return r.GetExpense(ctx, arg.WorkspaceID, arg.ID)
+}
+
+// ReimburseExpense records that an approved, reimbursable expense was paid
+// back to its submitter.
+func (r *expenseRepository) ReimburseExpense(ctx context.Context, arg ReimburseExpenseParams) (*Expense, error) {
+	query := `
+		UPDATE expenses
+		SET status = 'reimbursed', reimbursed_by = $1, reimbursed_at = $2, reimbursement_reference = $3, updated_at = NOW()
+		WHERE id = $4 AND workspace_id = $5 AND deleted_at IS NULL AND status = 'approved' AND reimbursable
+	`
+	result, err := r.db.ExecContext(ctx, query, arg.ReimbursedBy, arg.ReimbursedAt, arg.Reference, arg.ID, arg.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		if _, err := r.GetExpense(ctx, arg.WorkspaceID, arg.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrExpenseNotReimbursable
+	}
+	return r.GetExpense(ctx, arg.WorkspaceID, arg.ID)
+}
+
+// SetExpenseReceipt records the receipt of an expense and queues its
+// extraction, discarding the suggestions read off the previous receipt. A nil
+// receipt clears it.
+func (r *expenseRepository) SetExpenseReceipt(ctx context.Context, workspaceID, expenseID uuid.UUID, receipt *ExpenseReceipt) error {
+	var object, name, contentType *string
+	var size *int64
+	if receipt != nil {
+		object, name, contentType, size = &receipt.Object, &receipt.Name, &receipt.ContentType, &receipt.Size
+	}
+
+	query := `
+		UPDATE expenses
+		SET receipt_object = $1, receipt_name = $2, receipt_content_type = $3, receipt_size = $4,
+			receipt_uploaded_at = CASE WHEN $1::text IS NULL THEN NULL ELSE NOW() END,
+			receipt_extraction_status = CASE WHEN $1::text IS NULL THEN NULL ELSE 'pending'::receipt_extraction_status END,
+			receipt_extraction_error = NULL, receipt_extracted_at = NULL, receipt_extractor = NULL,
+			receipt_extraction_attempts = 0,
+			suggested_merchant = NULL, suggested_amount = NULL, suggested_currency = NULL, suggested_spent_on = NULL,
+			updated_at = NOW()
+		WHERE id = $5 AND workspace_id = $6 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, object, name, contentType, size, expenseID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrExpenseNotFound
+	}
+	return nil
+}
+
+// ListPendingReceiptExtractions returns expenses, across workspaces, whose
+// receipt was uploaded before uploadedBefore and has not been read yet.
+func (r *expenseRepository) ListPendingReceiptExtractions(ctx context.Context, uploadedBefore time.Time, limit int) ([]Expense, error) {
+	expenses := []Expense{}
+	query := `SELECT ` + expenseColumns + expenseJoins + `
+		WHERE e.receipt_extraction_status = 'pending' AND e.receipt_uploaded_at < $1 AND e.deleted_at IS NULL
+		ORDER BY e.receipt_uploaded_at
+		LIMIT $2
+	`
+	err := r.db.SelectContext(ctx, &expenses, query, uploadedBefore, limit)
+	return expenses, err
+}
+
+// StartReceiptExtraction counts an attempt at reading the receipt uploaded
+// at uploadedAt and returns the attempts made so far, including this one. It
+// returns 0 when the receipt was replaced or removed since, or was already
+// read.
+func (r *expenseRepository) StartReceiptExtraction(ctx context.Context, workspaceID, expenseID uuid.UUID, uploadedAt time.Time) (int, error) {
+	var attempts int
+	query := `
+		UPDATE expenses
+		SET receipt_extraction_attempts = receipt_extraction_attempts + 1
+		WHERE id = $1 AND workspace_id = $2 AND receipt_uploaded_at = $3
+			AND receipt_extraction_status = 'pending' AND deleted_at IS NULL
+		RETURNING receipt_extraction_attempts
+	`
+	err := r.db.GetContext(ctx, &attempts, query, expenseID, workspaceID, uploadedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return attempts, err
+}
+
+// SaveReceiptExtraction stores what was read off a receipt. It reports false
+// without changing anything when the receipt was replaced or removed since,
+// or was already read.
+func (r *expenseRepository) SaveReceiptExtraction(ctx context.Context, arg SaveReceiptExtractionParams) (bool, error) {
+	query := `
+		UPDATE expenses
+		SET receipt_extraction_status = $1, receipt_extraction_error = $2, receipt_extractor = $3,
+			suggested_merchant = $4, suggested_amount = $5, suggested_currency = $6, suggested_spent_on = $7,
+			receipt_extracted_at = NOW()
+		WHERE id = $8 AND workspace_id = $9 AND receipt_uploaded_at = $10
+			AND receipt_extraction_status = 'pending' AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		arg.Status, arg.Error, arg.Extractor,
+		arg.Merchant, arg.Amount, arg.Currency, arg.SpentOn,
+		arg.ExpenseID, arg.WorkspaceID, arg.UploadedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// ResolveReceiptSuggestions marks the suggestions of an expense confirmed or
+// dismissed. It returns ErrNoReceiptSuggestions when there are none waiting.
+func (r *expenseRepository) ResolveReceiptSuggestions(ctx context.Context, workspaceID, expenseID uuid.UUID, status string) error {
+	query := `
+		UPDATE expenses
+		SET receipt_extraction_status = $1, updated_at = NOW()
+		WHERE id = $2 AND workspace_id = $3 AND receipt_extraction_status = 'completed' AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, status, expenseID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrNoReceiptSuggestions
+	}
+	return nil
+}
+
+// ListUnbilledExpenses returns the approved, billable, unbilled expenses
+// matching filter and locks them until the surrounding transaction ends.
+// Reimbursed expenses were approved first and are billed as well.
+func (r *expenseRepository) ListUnbilledExpenses(ctx context.Context, workspaceID uuid.UUID, filter UnbilledExpenseFilter) ([]Expense, error) {
+	expenses := []Expense{}
+	query := `SELECT ` + expenseColumns + expenseJoins + `
+		WHERE e.workspace_id = $1 AND e.deleted_at IS NULL
+			AND e.billable AND NOT e.billed AND e.status IN ('approved', 'reimbursed')
+			AND e.spent_on BETWEEN $2 AND $3
+			AND e.currency = $4
+			AND ($5::uuid IS NULL OR e.client_id = $5)
+			AND ($6::uuid IS NULL OR e.project_id = $6)
+		ORDER BY e.spent_on, e.id
+		FOR UPDATE OF e
+	`
+	err := r.db.SelectContext(ctx, &expenses, query, workspaceID, filter.From, filter.To, filter.Currency, filter.ClientID, filter.ProjectID)
+	return expenses, err
+}
+
+// MarkExpensesBilled charges the expenses to the invoice. It returns how many
+// were marked; expenses billed in the meantime are skipped.
+func (r *expenseRepository) MarkExpensesBilled(ctx context.Context, invoiceID uuid.UUID, expenseIDs []uuid.UUID) (int64, error) {
+	query := `
+		UPDATE expenses
+		SET billed = TRUE, invoice_id = $1, updated_at = NOW()
+		WHERE id = ANY($2::uuid[]) AND NOT billed AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, invoiceID, uuidStrings(expenseIDs))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ReleaseInvoiceExpenses makes the expenses charged to the invoice unbilled
+// again.
+func (r *expenseRepository) ReleaseInvoiceExpenses(ctx context.Context, invoiceID uuid.UUID) error {
+	query := `UPDATE expenses SET billed = FALSE, invoice_id = NULL, updated_at = NOW() WHERE invoice_id = $1`
+	_, err := r.db.ExecContext(ctx, query, invoiceID)
+	return err
+}
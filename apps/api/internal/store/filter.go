@@ -85,6 +85,27 @@ func sanitizeSortColumn(column string) string {
 		"joined_at":    true,
 
 		"expires_at": true,
+
+		"status":     true,
+		"priority":   true,
+		"start_date": true,
+		"due_date":   true,
+		"budget":     true,
+		"title":      true,
+		"rank":       true,
+		"next_date":  true,
+		"started_at": true,
+
+		"duration_seconds": true,
+
+		"total_revenue":   true,
+		"outstanding":     true,
+		"active_projects": true,
+
+		"number":      true,
+		"issue_date":  true,
+		"total":       true,
+		"balance_due": true,
 	}
 
 	column = strings.ToLower(strings.TrimSpace(column))
@@ -199,3 +220,135 @@ type PaginatedMembersResponse struct {
 	// Applied filters
 	Filters FilterInfo `json:"filters"`
 }
+
+// PaginatedTeamsResponse represents a paginated list of teams
+// @Description Paginated response containing team data
+// swagger:model PaginatedTeamsResponse
+type PaginatedTeamsResponse struct {
+	// List of teams
+	Data []Team `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
+
+// PaginatedProjectsResponse represents a paginated list of projects
+// @Description Paginated response containing project data
+// swagger:model PaginatedProjectsResponse
+type PaginatedProjectsResponse struct {
+	// List of projects
+	Data []Project `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
+
+// PaginatedTasksResponse represents a paginated list of tasks
+// @Description Paginated response containing task data
+// swagger:model PaginatedTasksResponse
+type PaginatedTasksResponse struct {
+	// List of tasks
+	Data []Task `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
+
+// PaginatedCommentsResponse represents a paginated list of comments
+// @Description Paginated response containing comment data
+// swagger:model PaginatedCommentsResponse
+type PaginatedCommentsResponse struct {
+	// List of comments
+	Data []Comment `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
+
+// PaginatedTaskRecurrencesResponse represents a paginated list of recurring tasks
+// @Description Paginated response containing recurring task data
+// swagger:model PaginatedTaskRecurrencesResponse
+type PaginatedTaskRecurrencesResponse struct {
+	// List of recurring tasks
+	Data []TaskRecurrence `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
+
+// PaginatedTimeEntriesResponse represents a paginated list of time entries
+// @Description Paginated response containing time entry data
+// swagger:model PaginatedTimeEntriesResponse
+type PaginatedTimeEntriesResponse struct {
+	// List of time entries
+	Data []TimeEntry `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
+
+// PaginatedTimesheetsResponse represents a paginated list of timesheets
+// @Description Paginated response containing timesheet data
+// swagger:model PaginatedTimesheetsResponse
+type PaginatedTimesheetsResponse struct {
+	// List of timesheets
+	Data []Timesheet `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
+
+// PaginatedClientsResponse represents a paginated list of clients
+// @Description Paginated response containing client data
+// swagger:model PaginatedClientsResponse
+type PaginatedClientsResponse struct {
+	// List of clients
+	Data []Client `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
+
+// PaginatedInvoicesResponse represents a paginated list of invoices
+// @Description Paginated response containing invoice data
+// swagger:model PaginatedInvoicesResponse
+type PaginatedInvoicesResponse struct {
+	// List of invoices
+	Data []Invoice `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
+
+// PaginatedInvoiceRecurrencesResponse represents a paginated list of recurring invoices
+// @Description Paginated response containing recurring invoice data
+// swagger:model PaginatedInvoiceRecurrencesResponse
+type PaginatedInvoiceRecurrencesResponse struct {
+	// List of recurring invoices
+	Data []InvoiceRecurrence `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
+
+// PaginatedExpensesResponse represents a paginated list of expenses
+// @Description Paginated response containing expense data
+// swagger:model PaginatedExpensesResponse
+type PaginatedExpensesResponse struct {
+	// List of expenses
+	Data []Expense `json:"data"`
+	// Pagination metadata
+	Pagination PaginationInfo `json:"pagination"`
+	// Applied filters
+	Filters FilterInfo `json:"filters"`
+}
@@ -0,0 +1,176 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInviteLinkNotFound = errors.New("invite link not found")
+	ErrInviteLinkInvalid  = errors.New("invite link is invalid, expired or used up")
+	ErrJoinDomainNotFound = errors.New("join domain not found")
+	ErrJoinDomainExists   = errors.New("join domain already exists")
+)
+
+type InviteLink struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	Role        string     `json:"role" db:"role"`
+	MaxUses     *int32     `json:"max_uses" db:"max_uses"`
+	UseCount    int32      `json:"use_count" db:"use_count"`
+	ExpiresAt   *time.Time `json:"expires_at" db:"expires_at"`
+	CreatedBy   *uuid.UUID `json:"created_by" db:"created_by"`
+	RevokedAt   *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type JoinDomain struct {
+	WorkspaceID uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	Domain      string     `json:"domain" db:"domain"`
+	Role        string     `json:"role" db:"role"`
+	CreatedBy   *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CreateInviteLinkParams struct {
+	WorkspaceID uuid.UUID
+	TokenHash   string
+	Role        string
+	MaxUses     *int32
+	ExpiresAt   *time.Time
+	CreatedBy   uuid.UUID
+}
+
+type InviteRepository interface {
+	CreateInviteLink(ctx context.Context, arg CreateInviteLinkParams) (*InviteLink, error)
+	ListInviteLinks(ctx context.Context, workspaceID uuid.UUID) ([]InviteLink, error)
+	RevokeInviteLink(ctx context.Context, workspaceID, linkID uuid.UUID) error
+	ConsumeInviteLink(ctx context.Context, tokenHash string) (*InviteLink, error)
+	AddJoinDomain(ctx context.Context, workspaceID uuid.UUID, domain, role string, createdBy uuid.UUID) (*JoinDomain, error)
+	ListJoinDomains(ctx context.Context, workspaceID uuid.UUID) ([]JoinDomain, error)
+	GetJoinDomain(ctx context.Context, workspaceID uuid.UUID, domain string) (*JoinDomain, error)
+	DeleteJoinDomain(ctx context.Context, workspaceID uuid.UUID, domain string) error
+}
+
+type inviteRepository struct {
+	db DBTX
+}
+
+func NewInviteRepository(db DBTX) InviteRepository {
+	return &inviteRepository{db: db}
+}
+
+func (r *inviteRepository) CreateInviteLink(ctx context.Context, arg CreateInviteLinkParams) (*InviteLink, error) {
+	link := &InviteLink{}
+	query := `
+		INSERT INTO workspace_invite_links (workspace_id, token_hash, role, max_uses, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, link, query, arg.WorkspaceID, arg.TokenHash, arg.Role, arg.MaxUses, arg.ExpiresAt, arg.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (r *inviteRepository) ListInviteLinks(ctx context.Context, workspaceID uuid.UUID) ([]InviteLink, error) {
+	var links []InviteLink
+	query := `SELECT * FROM workspace_invite_links WHERE workspace_id = $1 ORDER BY created_at DESC`
+	err := r.db.SelectContext(ctx, &links, query, workspaceID)
+	return links, err
+}
+
+func (r *inviteRepository) RevokeInviteLink(ctx context.Context, workspaceID, linkID uuid.UUID) error {
+	query := `UPDATE workspace_invite_links SET revoked_at = NOW() WHERE id = $1 AND workspace_id = $2 AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, linkID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrInviteLinkNotFound
+	}
+	return nil
+}
+
+// ConsumeInviteLink claims one use of a link. The checks and the increment
+// happen in a single statement so concurrent joins cannot exceed max_uses.
+func (r *inviteRepository) ConsumeInviteLink(ctx context.Context, tokenHash string) (*InviteLink, error) {
+	var link InviteLink
+	query := `
+		UPDATE workspace_invite_links l
+		SET use_count = l.use_count + 1
+		FROM workspaces w
+		WHERE l.token_hash = $1
+			AND w.id = l.workspace_id
+			AND w.deleted_at IS NULL
+			AND l.revoked_at IS NULL
+			AND (l.expires_at IS NULL OR l.expires_at > NOW())
+			AND (l.max_uses IS NULL OR l.use_count < l.max_uses)
+		RETURNING l.*
+	`
+	err := r.db.GetContext(ctx, &link, query, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInviteLinkInvalid
+		}
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *inviteRepository) AddJoinDomain(ctx context.Context, workspaceID uuid.UUID, domain, role string, createdBy uuid.UUID) (*JoinDomain, error) {
+	joinDomain := &JoinDomain{}
+	query := `
+		INSERT INTO workspace_join_domains (workspace_id, domain, role, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, joinDomain, query, workspaceID, domain, role, createdBy)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrJoinDomainExists
+		}
+		return nil, err
+	}
+	return joinDomain, nil
+}
+
+func (r *inviteRepository) ListJoinDomains(ctx context.Context, workspaceID uuid.UUID) ([]JoinDomain, error) {
+	var domains []JoinDomain
+	query := `SELECT * FROM workspace_join_domains WHERE workspace_id = $1 ORDER BY domain ASC`
+	err := r.db.SelectContext(ctx, &domains, query, workspaceID)
+	return domains, err
+}
+
+func (r *inviteRepository) GetJoinDomain(ctx context.Context, workspaceID uuid.UUID, domain string) (*JoinDomain, error) {
+	var joinDomain JoinDomain
+	query := `SELECT * FROM workspace_join_domains WHERE workspace_id = $1 AND domain = $2`
+	err := r.db.GetContext(ctx, &joinDomain, query, workspaceID, domain)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrJoinDomainNotFound
+		}
+		return nil, err
+	}
+	return &joinDomain, nil
+}
+
+func (r *inviteRepository) DeleteJoinDomain(ctx context.Context, workspaceID uuid.UUID, domain string) error {
+	query := `DELETE FROM workspace_join_domains WHERE workspace_id = $1 AND domain = $2`
+	result, err := r.db.ExecContext(ctx, query, workspaceID, domain)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrJoinDomainNotFound
+	}
+	return nil
+}
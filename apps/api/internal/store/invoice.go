@@ -0,0 +1,567 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+// Invoice is a bill sent to a client. Amounts are in minor units of the
+// invoice currency; rates and discounts are percentages.
+type Invoice struct {
+	ID              uuid.UUID     `json:"id" db:"id"`
+	WorkspaceID     uuid.UUID     `json:"workspace_id" db:"workspace_id"`
+	ClientID        uuid.UUID     `json:"client_id" db:"client_id"`
+	ProjectID       *uuid.UUID    `json:"project_id" db:"project_id"`
+	SequenceNumber  *int64        `json:"-" db:"sequence_number"`
+	Number          *string       `json:"number" db:"number"`
+	Status          string        `json:"status" db:"status"`
+	Currency        string        `json:"currency" db:"currency"`
+	IssueDate       *time.Time    `json:"issue_date" db:"issue_date"`
+	DueDate         *time.Time    `json:"due_date" db:"due_date"`
+	DiscountPercent money.Decimal `json:"discount_percent" db:"discount_percent" swaggertype:"number"`
+	TaxRate         money.Decimal `json:"tax_rate" db:"tax_rate" swaggertype:"number"`
+	Subtotal        int64         `json:"subtotal" db:"subtotal"`
+	DiscountTotal   int64         `json:"discount_total" db:"discount_total"`
+	TaxTotal        int64         `json:"tax_total" db:"tax_total"`
+	Total           int64         `json:"total" db:"total"`
+	AmountPaid      int64         `json:"amount_paid" db:"amount_paid"`
+	BalanceDue      int64         `json:"balance_due" db:"balance_due"`
+	Notes           *string       `json:"notes" db:"notes"`
+	Terms           *string       `json:"terms" db:"terms"`
+	SentAt          *time.Time    `json:"sent_at" db:"sent_at"`
+	ViewedAt        *time.Time    `json:"viewed_at" db:"viewed_at"`
+	PaidAt          *time.Time    `json:"paid_at" db:"paid_at"`
+	CancelledAt     *time.Time    `json:"cancelled_at" db:"cancelled_at"`
+	PDFObject       *string       `json:"-" db:"pdf_object"`
+	PDFFingerprint  *string       `json:"-" db:"pdf_fingerprint"`
+	PDFRenderedAt   *time.Time    `json:"pdf_rendered_at" db:"pdf_rendered_at"`
+	ReminderOffset  *int          `json:"-" db:"last_reminder_offset"`
+	LastRemindedAt  *time.Time    `json:"last_reminded_at" db:"last_reminded_at"`
+	RecurrenceID    *uuid.UUID    `json:"recurrence_id" db:"recurrence_id"`
+	OccurrenceDate  *time.Time    `json:"occurrence_date" db:"occurrence_date"`
+	BaseCurrency    *string       `json:"base_currency" db:"base_currency"`
+	ExchangeRate    *money.Rate   `json:"exchange_rate" db:"exchange_rate" swaggertype:"number"`
+	CreatedBy       *uuid.UUID    `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time    `json:"-" db:"deleted_at"`
+	ClientName      string        `json:"client_name" db:"client_name"`
+	ProjectName     *string       `json:"project_name" db:"project_name"`
+	Items           []InvoiceItem `json:"items,omitempty" db:"-"`
+}
+
+// InvoiceItem is a line of an invoice. Amount is quantity × unit price and
+// DiscountAmount includes the line's share of the invoice discount, so the
+// invoice totals are the sums of its lines.
+type InvoiceItem struct {
+	ID              uuid.UUID      `json:"id" db:"id"`
+	InvoiceID       uuid.UUID      `json:"invoice_id" db:"invoice_id"`
+	Position        int            `json:"position" db:"position"`
+	Description     string         `json:"description" db:"description"`
+	Quantity        money.Decimal  `json:"quantity" db:"quantity" swaggertype:"number"`
+	UnitPrice       int64          `json:"unit_price" db:"unit_price"`
+	DiscountPercent money.Decimal  `json:"discount_percent" db:"discount_percent" swaggertype:"number"`
+	TaxRate         *money.Decimal `json:"tax_rate" db:"tax_rate" swaggertype:"number"`
+	Amount          int64          `json:"amount" db:"amount"`
+	DiscountAmount  int64          `json:"discount_amount" db:"discount_amount"`
+	TaxAmount       int64          `json:"tax_amount" db:"tax_amount"`
+	Total           int64          `json:"total" db:"total"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+}
+
+// InvoiceTotals are the amounts computed from an invoice's lines.
+type InvoiceTotals struct {
+	Subtotal      int64
+	DiscountTotal int64
+	TaxTotal      int64
+	Total         int64
+}
+
+type CreateInvoiceParams struct {
+	WorkspaceID     uuid.UUID
+	ClientID        uuid.UUID
+	ProjectID       *uuid.UUID
+	Currency        string
+	IssueDate       *time.Time
+	DueDate         *time.Time
+	DiscountPercent money.Decimal
+	TaxRate         money.Decimal
+	Notes           *string
+	Terms           *string
+	Totals          InvoiceTotals
+	RecurrenceID    *uuid.UUID
+	OccurrenceDate  *time.Time
+	CreatedBy       *uuid.UUID
+}
+
+type UpdateInvoiceParams struct {
+	ID              uuid.UUID
+	WorkspaceID     uuid.UUID
+	ClientID        uuid.UUID
+	ProjectID       *uuid.UUID
+	Currency        string
+	IssueDate       *time.Time
+	DueDate         *time.Time
+	DiscountPercent money.Decimal
+	TaxRate         money.Decimal
+	Notes           *string
+	Terms           *string
+	Totals          InvoiceTotals
+}
+
+type InvoiceItemParams struct {
+	Description     string
+	Quantity        money.Decimal
+	UnitPrice       int64
+	DiscountPercent money.Decimal
+	TaxRate         *money.Decimal
+	Amount          int64
+	DiscountAmount  int64
+	TaxAmount       int64
+	Total           int64
+}
+
+type IssueInvoiceParams struct {
+	ID             uuid.UUID
+	WorkspaceID    uuid.UUID
+	SequenceNumber int64
+	Number         string
+	IssueDate      time.Time
+	DueDate        time.Time
+}
+
+// InvoiceFilter narrows an invoice listing. Empty fields are ignored.
+type InvoiceFilter struct {
+	Statuses     []string
+	ClientID     *uuid.UUID
+	ProjectID    *uuid.UUID
+	RecurrenceID *uuid.UUID
+	IssuedFrom   *time.Time
+	IssuedTo     *time.Time
+}
+
+// InvoiceReminder is a payment reminder that fell due for an unpaid invoice.
+// DaysFromDue is how far today is from the due date: negative before it,
+// positive once the invoice is overdue.
+type InvoiceReminder struct {
+	InvoiceID     uuid.UUID  `db:"id"`
+	WorkspaceID   uuid.UUID  `db:"workspace_id"`
+	ClientID      uuid.UUID  `db:"client_id"`
+	Number        string     `db:"number"`
+	Status        string     `db:"status"`
+	Currency      string     `db:"currency"`
+	BalanceDue    int64      `db:"balance_due"`
+	DueDate       time.Time  `db:"due_date"`
+	DaysFromDue   int        `db:"days_from_due"`
+	CreatedBy     *uuid.UUID `db:"created_by"`
+	ClientName    string     `db:"client_name"`
+	ClientEmail   *string    `db:"client_email"`
+	WorkspaceName string     `db:"workspace_name"`
+}
+
+type InvoiceRepository interface {
+	CreateInvoice(ctx context.Context, arg CreateInvoiceParams) (*Invoice, error)
+	GetInvoice(ctx context.Context, workspaceID, invoiceID uuid.UUID) (*Invoice, error)
+	LockInvoice(ctx context.Context, workspaceID, invoiceID uuid.UUID) (*Invoice, error)
+	ListInvoices(ctx context.Context, workspaceID uuid.UUID, filter InvoiceFilter, filters FilterParams) ([]Invoice, int64, error)
+	UpdateInvoice(ctx context.Context, arg UpdateInvoiceParams) (*Invoice, error)
+	DeleteInvoice(ctx context.Context, workspaceID, invoiceID uuid.UUID) error
+
+	NextInvoiceNumber(ctx context.Context, workspaceID uuid.UUID) (int64, error)
+	IssueInvoice(ctx context.Context, arg IssueInvoiceParams) (*Invoice, error)
+	SetInvoiceStatus(ctx context.Context, workspaceID, invoiceID uuid.UUID, status string, at time.Time) (*Invoice, error)
+	SetInvoicePDF(ctx context.Context, workspaceID, invoiceID uuid.UUID, objectName, fingerprint string) error
+	MarkOverdueInvoices(ctx context.Context, limit int) ([]Invoice, error)
+	ClaimInvoiceReminders(ctx context.Context, limit int) ([]InvoiceReminder, error)
+
+	ListInvoiceItems(ctx context.Context, invoiceID uuid.UUID) ([]InvoiceItem, error)
+	ReplaceInvoiceItems(ctx context.Context, invoiceID uuid.UUID, items []InvoiceItemParams) error
+}
+
+type invoiceRepository struct {
+	db DBTX
+}
+
+func NewInvoiceRepository(db DBTX) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+const invoiceColumns = `
+	i.*,
+	c.name AS client_name,
+	p.name AS project_name
+`
+
+const invoiceFrom = `
+	FROM invoices i
+	JOIN clients c ON c.id = i.client_id
+	LEFT JOIN projects p ON p.id = i.project_id
+`
+
+func (r *invoiceRepository) CreateInvoice(ctx context.Context, arg CreateInvoiceParams) (*Invoice, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO invoices (
+			workspace_id, client_id, project_id, currency, issue_date, due_date,
+			discount_percent, tax_rate, notes, terms,
+			subtotal, discount_total, tax_total, total, recurrence_id, occurrence_date, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query,
+		arg.WorkspaceID, arg.ClientID, arg.ProjectID, arg.Currency, arg.IssueDate, arg.DueDate,
+		arg.DiscountPercent, arg.TaxRate, arg.Notes, arg.Terms,
+		arg.Totals.Subtotal, arg.Totals.DiscountTotal, arg.Totals.TaxTotal, arg.Totals.Total,
+		arg.RecurrenceID, arg.OccurrenceDate, arg.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetInvoice(ctx, arg.WorkspaceID, id)
+}
+
+func (r *invoiceRepository) GetInvoice(ctx context.Context, workspaceID, invoiceID uuid.UUID) (*Invoice, error) {
+	var invoice Invoice
+	query := `SELECT ` + invoiceColumns + invoiceFrom + ` WHERE i.id = $1 AND i.workspace_id = $2 AND i.deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &invoice, query, invoiceID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// LockInvoice reads the invoice and holds its row lock until the surrounding
+// transaction ends, so status changes on one invoice are applied one at a time.
+func (r *invoiceRepository) LockInvoice(ctx context.Context, workspaceID, invoiceID uuid.UUID) (*Invoice, error) {
+	var invoice Invoice
+	query := `SELECT ` + invoiceColumns + invoiceFrom + ` WHERE i.id = $1 AND i.workspace_id = $2 AND i.deleted_at IS NULL FOR UPDATE OF i`
+	err := r.db.GetContext(ctx, &invoice, query, invoiceID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) ListInvoices(ctx context.Context, workspaceID uuid.UUID, filter InvoiceFilter, filters FilterParams) ([]Invoice, int64, error) {
+	var invoices []Invoice
+	var args []any
+	argPos := 1
+
+	where := fmt.Sprintf(` WHERE i.workspace_id = $%d AND i.deleted_at IS NULL`, argPos)
+	args = append(args, workspaceID)
+	argPos++
+
+	if len(filter.Statuses) > 0 {
+		where += fmt.Sprintf(` AND i.status::text = ANY($%d::text[])`, argPos)
+		args = append(args, filter.Statuses)
+		argPos++
+	}
+	if filter.ClientID != nil {
+		where += fmt.Sprintf(` AND i.client_id = $%d`, argPos)
+		args = append(args, *filter.ClientID)
+		argPos++
+	}
+	if filter.ProjectID != nil {
+		where += fmt.Sprintf(` AND i.project_id = $%d`, argPos)
+		args = append(args, *filter.ProjectID)
+		argPos++
+	}
+	if filter.RecurrenceID != nil {
+		where += fmt.Sprintf(` AND i.recurrence_id = $%d`, argPos)
+		args = append(args, *filter.RecurrenceID)
+		argPos++
+	}
+	if filter.IssuedFrom != nil {
+		where += fmt.Sprintf(` AND i.issue_date >= $%d`, argPos)
+		args = append(args, *filter.IssuedFrom)
+		argPos++
+	}
+	if filter.IssuedTo != nil {
+		where += fmt.Sprintf(` AND i.issue_date <= $%d`, argPos)
+		args = append(args, *filter.IssuedTo)
+		argPos++
+	}
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND (i.number ILIKE $%d OR c.name ILIKE $%d)`, argPos, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := filters.SortBy
+	switch sortBy {
+	case "number":
+		sortBy = "i.sequence_number"
+	case "status", "issue_date", "due_date", "total", "balance_due", "updated_at":
+		sortBy = "i." + sortBy
+	default:
+		sortBy = "i.created_at"
+	}
+
+	query := `SELECT ` + invoiceColumns + invoiceFrom + where +
+		fmt.Sprintf(` ORDER BY %s %s NULLS LAST, i.id LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &invoices, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*)`+invoiceFrom+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return invoices, total, nil
+}
+
+// UpdateInvoice rewrites the content of a draft. Invoices that have been sent
+// are left untouched and reported as not found.
+func (r *invoiceRepository) UpdateInvoice(ctx context.Context, arg UpdateInvoiceParams) (*Invoice, error) {
+	query := `
+		UPDATE invoices
+		SET client_id = $1, project_id = $2, currency = $3, issue_date = $4, due_date = $5,
+			discount_percent = $6, tax_rate = $7, notes = $8, terms = $9,
+			subtotal = $10, discount_total = $11, tax_total = $12, total = $13, updated_at = NOW()
+		WHERE id = $14 AND workspace_id = $15 AND status = 'draft' AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		arg.ClientID, arg.ProjectID, arg.Currency, arg.IssueDate, arg.DueDate,
+		arg.DiscountPercent, arg.TaxRate, arg.Notes, arg.Terms,
+		arg.Totals.Subtotal, arg.Totals.DiscountTotal, arg.Totals.TaxTotal, arg.Totals.Total,
+		arg.ID, arg.WorkspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrInvoiceNotFound
+	}
+	return r.GetInvoice(ctx, arg.WorkspaceID, arg.ID)
+}
+
+// DeleteInvoice soft-deletes a draft. Issued invoices are kept so the number
+// sequence stays complete; they are cancelled instead.
+func (r *invoiceRepository) DeleteInvoice(ctx context.Context, workspaceID, invoiceID uuid.UUID) error {
+	query := `UPDATE invoices SET deleted_at = NOW() WHERE id = $1 AND workspace_id = $2 AND status = 'draft' AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, invoiceID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrInvoiceNotFound
+	}
+	return nil
+}
+
+// NextInvoiceNumber hands out the workspace's next invoice number. It must run
+// in the transaction that issues the invoice: the sequence row stays locked
+// until that transaction ends and a rollback returns the number.
+func (r *invoiceRepository) NextInvoiceNumber(ctx context.Context, workspaceID uuid.UUID) (int64, error) {
+	var number int64
+	query := `
+		INSERT INTO invoice_sequences (workspace_id, last_number)
+		VALUES ($1, 1)
+		ON CONFLICT (workspace_id) DO UPDATE
+		SET last_number = invoice_sequences.last_number + 1, updated_at = NOW()
+		RETURNING last_number
+	`
+	err := r.db.GetContext(ctx, &number, query, workspaceID)
+	return number, err
+}
+
+func (r *invoiceRepository) IssueInvoice(ctx context.Context, arg IssueInvoiceParams) (*Invoice, error) {
+	query := `
+		UPDATE invoices
+		SET status = 'sent', sequence_number = $1, number = $2, issue_date = $3, due_date = $4,
+			sent_at = NOW(), updated_at = NOW()
+		WHERE id = $5 AND workspace_id = $6 AND status = 'draft' AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, arg.SequenceNumber, arg.Number, arg.IssueDate, arg.DueDate, arg.ID, arg.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrInvoiceNotFound
+	}
+	return r.GetInvoice(ctx, arg.WorkspaceID, arg.ID)
+}
+
+// SetInvoiceStatus moves an issued invoice to status and stamps the matching
+// timestamp. An invoice that leaves paid, because a refund reopened it, loses
+// its paid_at.
+func (r *invoiceRepository) SetInvoiceStatus(ctx context.Context, workspaceID, invoiceID uuid.UUID, status string, at time.Time) (*Invoice, error) {
+	query := `
+		UPDATE invoices
+		SET status = $1,
+			viewed_at = CASE WHEN $1 = 'viewed' THEN $2 ELSE viewed_at END,
+			paid_at = CASE WHEN $1 = 'paid' THEN $2 END,
+			cancelled_at = CASE WHEN $1 = 'cancelled' THEN $2 ELSE cancelled_at END,
+			updated_at = NOW()
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, status, at, invoiceID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrInvoiceNotFound
+	}
+	return r.GetInvoice(ctx, workspaceID, invoiceID)
+}
+
+// SetInvoicePDF records the rendered PDF of an invoice. It leaves updated_at
+// alone: rendering is not a change to the invoice.
+func (r *invoiceRepository) SetInvoicePDF(ctx context.Context, workspaceID, invoiceID uuid.UUID, objectName, fingerprint string) error {
+	query := `
+		UPDATE invoices
+		SET pdf_object = $1, pdf_fingerprint = $2, pdf_rendered_at = NOW()
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, objectName, fingerprint, invoiceID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrInvoiceNotFound
+	}
+	return nil
+}
+
+// MarkOverdueInvoices moves sent and viewed invoices whose due date has
+// passed, in each workspace's timezone, to overdue and returns them.
+func (r *invoiceRepository) MarkOverdueInvoices(ctx context.Context, limit int) ([]Invoice, error) {
+	var ids []uuid.UUID
+	query := `
+		UPDATE invoices
+		SET status = 'overdue', updated_at = NOW()
+		WHERE id IN (
+			SELECT i.id
+			FROM invoices i
+			LEFT JOIN workspace_settings s ON s.workspace_id = i.workspace_id
+			WHERE i.status IN ('sent', 'viewed') AND i.deleted_at IS NULL
+				AND i.due_date < (NOW() AT TIME ZONE COALESCE(s.settings->>'timezone', 'UTC'))::date
+			ORDER BY i.due_date
+			LIMIT $1
+		)
+			AND status IN ('sent', 'viewed')
+		RETURNING id
+	`
+	if err := r.db.SelectContext(ctx, &ids, query, limit); err != nil {
+		return nil, err
+	}
+
+	invoices := []Invoice{}
+	if len(ids) == 0 {
+		return invoices, nil
+	}
+	err := r.db.SelectContext(ctx, &invoices, `SELECT `+invoiceColumns+invoiceFrom+` WHERE i.id = ANY($1::uuid[]) ORDER BY i.due_date`, uuidStrings(ids))
+	return invoices, err
+}
+
+// ClaimInvoiceReminders finds unpaid invoices for which a reminder fell due
+// today, in each workspace's timezone, going by the reminder days and the
+// repeat interval of its settings. A reminder is claimed by recording it as
+// the invoice's last one, and only reminders later than that are found, so
+// concurrent callers never send the same reminder twice. Reminders missed by
+// more than a few days are skipped rather than sent late. Workspaces that
+// never saved the reminder settings get the DefaultSettingsDocument values.
+func (r *invoiceRepository) ClaimInvoiceReminders(ctx context.Context, limit int) ([]InvoiceReminder, error) {
+	var reminders []InvoiceReminder
+	query := `
+		WITH unpaid AS (
+			SELECT i.id,
+				(NOW() AT TIME ZONE COALESCE(s.settings->>'timezone', 'UTC'))::date - i.due_date AS days,
+				CASE WHEN jsonb_typeof(s.settings->'invoice_reminder_days') = 'array'
+					THEN s.settings->'invoice_reminder_days' ELSE '[-3, 0]'::jsonb END AS reminder_days,
+				COALESCE((s.settings->>'invoice_reminder_repeat_days')::int, 7) AS repeat_days
+			FROM invoices i
+			LEFT JOIN workspace_settings s ON s.workspace_id = i.workspace_id
+			WHERE i.status IN ('sent', 'viewed', 'overdue') AND i.deleted_at IS NULL
+				AND i.due_date IS NOT NULL AND i.balance_due > 0
+		),
+		due AS (
+			SELECT o.id, o.days, GREATEST(
+				(SELECT MAX(d::int) FROM jsonb_array_elements_text(o.reminder_days) d WHERE d::int <= o.days),
+				CASE WHEN o.repeat_days > 0 AND o.days >= o.repeat_days THEN o.days / o.repeat_days * o.repeat_days END
+			) AS reminder
+			FROM unpaid o
+		),
+		claim AS (
+			SELECT d.id, d.days, d.reminder
+			FROM due d
+			JOIN invoices i ON i.id = d.id
+			WHERE d.reminder IS NOT NULL AND d.days - d.reminder <= 3
+				AND (i.last_reminder_offset IS NULL OR i.last_reminder_offset < d.reminder)
+			ORDER BY i.due_date
+			LIMIT $1
+		)
+		UPDATE invoices i
+		SET last_reminder_offset = cl.reminder, last_reminded_at = NOW()
+		FROM claim cl, clients c, workspaces w
+		WHERE i.id = cl.id AND c.id = i.client_id AND w.id = i.workspace_id
+			AND (i.last_reminder_offset IS NULL OR i.last_reminder_offset < cl.reminder)
+			AND i.status IN ('sent', 'viewed', 'overdue') AND i.balance_due > 0
+		RETURNING i.id, i.workspace_id, i.client_id, COALESCE(i.number, '') AS number, i.status, i.currency,
+			i.balance_due, i.due_date, cl.days AS days_from_due, i.created_by,
+			c.name AS client_name, c.email AS client_email, w.name AS workspace_name
+	`
+	err := r.db.SelectContext(ctx, &reminders, query, limit)
+	return reminders, err
+}
+
+func (r *invoiceRepository) ListInvoiceItems(ctx context.Context, invoiceID uuid.UUID) ([]InvoiceItem, error) {
+	items := []InvoiceItem{}
+	query := `SELECT * FROM invoice_items WHERE invoice_id = $1 ORDER BY position`
+	err := r.db.SelectContext(ctx, &items, query, invoiceID)
+	return items, err
+}
+
+// ReplaceInvoiceItems swaps the lines of an invoice for items, numbered in
+// the order given.
+func (r *invoiceRepository) ReplaceInvoiceItems(ctx context.Context, invoiceID uuid.UUID, items []InvoiceItemParams) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM invoice_items WHERE invoice_id = $1`, invoiceID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO invoice_items (
+			invoice_id, position, description, quantity, unit_price, discount_percent, tax_rate,
+			amount, discount_amount, tax_amount, total
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	for i, item := range items {
+		_, err := r.db.ExecContext(ctx, query,
+			invoiceID, i+1, item.Description, item.Quantity, item.UnitPrice, item.DiscountPercent, item.TaxRate,
+			item.Amount, item.DiscountAmount, item.TaxAmount, item.Total,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,368 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+var ErrInvoiceRecurrenceNotFound = errors.New("recurring invoice not found")
+
+// InvoiceRecurrence is an invoice template that is copied into a new invoice
+// for every occurrence of its RRULE.
+type InvoiceRecurrence struct {
+	ID                 uuid.UUID               `json:"id" db:"id"`
+	WorkspaceID        uuid.UUID               `json:"workspace_id" db:"workspace_id"`
+	ClientID           uuid.UUID               `json:"client_id" db:"client_id"`
+	ProjectID          *uuid.UUID              `json:"project_id" db:"project_id"`
+	Name               string                  `json:"name" db:"name"`
+	Currency           string                  `json:"currency" db:"currency"`
+	DiscountPercent    money.Decimal           `json:"discount_percent" db:"discount_percent" swaggertype:"number"`
+	TaxRate            money.Decimal           `json:"tax_rate" db:"tax_rate" swaggertype:"number"`
+	Notes              *string                 `json:"notes" db:"notes"`
+	Terms              *string                 `json:"terms" db:"terms"`
+	RRule              string                  `json:"rrule" db:"rrule"`
+	StartDate          time.Time               `json:"start_date" db:"start_date"`
+	NextDate           *time.Time              `json:"next_date" db:"next_date"`
+	DueDays            int                     `json:"due_days" db:"due_days"`
+	EndDate            *time.Time              `json:"end_date" db:"end_date"`
+	MaxInvoices        *int32                  `json:"max_invoices" db:"max_invoices"`
+	AutoSend           bool                    `json:"auto_send" db:"auto_send"`
+	CreatedBy          *uuid.UUID              `json:"created_by" db:"created_by"`
+	CreatedAt          time.Time               `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time               `json:"updated_at" db:"updated_at"`
+	DeletedAt          *time.Time              `json:"-" db:"deleted_at"`
+	ClientName         string                  `json:"client_name" db:"client_name"`
+	ProjectName        *string                 `json:"project_name" db:"project_name"`
+	InvoiceCount       int64                   `json:"invoice_count" db:"invoice_count"`
+	LastOccurrenceDate *time.Time              `json:"last_occurrence_date" db:"last_occurrence_date"`
+	Items              []InvoiceRecurrenceItem `json:"items,omitempty" db:"-"`
+}
+
+// InvoiceRecurrenceItem is a template line copied onto every invoice of a
+// recurrence. A line without a tax rate uses the invoice rate.
+type InvoiceRecurrenceItem struct {
+	ID              uuid.UUID      `json:"id" db:"id"`
+	RecurrenceID    uuid.UUID      `json:"recurrence_id" db:"recurrence_id"`
+	Position        int            `json:"position" db:"position"`
+	Description     string         `json:"description" db:"description"`
+	Quantity        money.Decimal  `json:"quantity" db:"quantity" swaggertype:"number"`
+	UnitPrice       int64          `json:"unit_price" db:"unit_price"`
+	DiscountPercent money.Decimal  `json:"discount_percent" db:"discount_percent" swaggertype:"number"`
+	TaxRate         *money.Decimal `json:"tax_rate" db:"tax_rate" swaggertype:"number"`
+}
+
+type InvoiceRecurrenceItemParams struct {
+	Description     string
+	Quantity        money.Decimal
+	UnitPrice       int64
+	DiscountPercent money.Decimal
+	TaxRate         *money.Decimal
+}
+
+type CreateInvoiceRecurrenceParams struct {
+	WorkspaceID     uuid.UUID
+	ClientID        uuid.UUID
+	ProjectID       *uuid.UUID
+	Name            string
+	Currency        string
+	DiscountPercent money.Decimal
+	TaxRate         money.Decimal
+	Notes           *string
+	Terms           *string
+	RRule           string
+	StartDate       time.Time
+	NextDate        *time.Time
+	DueDays         int
+	EndDate         *time.Time
+	MaxInvoices     *int32
+	AutoSend        bool
+	CreatedBy       uuid.UUID
+}
+
+type UpdateInvoiceRecurrenceParams struct {
+	ID              uuid.UUID
+	WorkspaceID     uuid.UUID
+	ClientID        uuid.UUID
+	ProjectID       *uuid.UUID
+	Name            string
+	Currency        string
+	DiscountPercent money.Decimal
+	TaxRate         money.Decimal
+	Notes           *string
+	Terms           *string
+	RRule           string
+	StartDate       time.Time
+	DueDays         int
+	EndDate         *time.Time
+	MaxInvoices     *int32
+	AutoSend        bool
+}
+
+// InvoiceRecurrenceFilter narrows a recurring invoice listing. Empty fields
+// are ignored.
+type InvoiceRecurrenceFilter struct {
+	ClientID *uuid.UUID
+	Active   *bool
+}
+
+type InvoiceRecurrenceRepository interface {
+	CreateInvoiceRecurrence(ctx context.Context, arg CreateInvoiceRecurrenceParams) (*InvoiceRecurrence, error)
+	GetInvoiceRecurrence(ctx context.Context, workspaceID, recurrenceID uuid.UUID) (*InvoiceRecurrence, error)
+	GetInvoiceRecurrenceForUpdate(ctx context.Context, workspaceID, recurrenceID uuid.UUID) (*InvoiceRecurrence, error)
+	ListInvoiceRecurrences(ctx context.Context, workspaceID uuid.UUID, filter InvoiceRecurrenceFilter, filters FilterParams) ([]InvoiceRecurrence, int64, error)
+	UpdateInvoiceRecurrence(ctx context.Context, arg UpdateInvoiceRecurrenceParams) (*InvoiceRecurrence, error)
+	DeleteInvoiceRecurrence(ctx context.Context, workspaceID, recurrenceID uuid.UUID) error
+
+	ListInvoiceRecurrenceItems(ctx context.Context, recurrenceID uuid.UUID) ([]InvoiceRecurrenceItem, error)
+	ReplaceInvoiceRecurrenceItems(ctx context.Context, recurrenceID uuid.UUID, items []InvoiceRecurrenceItemParams) error
+
+	ListDueInvoiceRecurrenceIDs(ctx context.Context, through time.Time, limit int) ([]uuid.UUID, error)
+	LockInvoiceRecurrence(ctx context.Context, recurrenceID uuid.UUID) (*InvoiceRecurrence, error)
+	SetInvoiceRecurrenceNextDate(ctx context.Context, recurrenceID uuid.UUID, next *time.Time) error
+	InvoiceRecurrenceOccurrenceExists(ctx context.Context, recurrenceID uuid.UUID, date time.Time) (bool, error)
+}
+
+type invoiceRecurrenceRepository struct {
+	db DBTX
+}
+
+func NewInvoiceRecurrenceRepository(db DBTX) InvoiceRecurrenceRepository {
+	return &invoiceRecurrenceRepository{db: db}
+}
+
+const invoiceRecurrenceColumns = `
+	r.*,
+	c.name AS client_name,
+	p.name AS project_name,
+	(SELECT COUNT(*) FROM invoices i WHERE i.recurrence_id = r.id) AS invoice_count,
+	(SELECT MAX(i.occurrence_date) FROM invoices i WHERE i.recurrence_id = r.id) AS last_occurrence_date
+`
+
+const invoiceRecurrenceFrom = `
+	FROM invoice_recurrences r
+	JOIN clients c ON c.id = r.client_id
+	LEFT JOIN projects p ON p.id = r.project_id
+`
+
+func (r *invoiceRecurrenceRepository) CreateInvoiceRecurrence(ctx context.Context, arg CreateInvoiceRecurrenceParams) (*InvoiceRecurrence, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO invoice_recurrences (
+			workspace_id, client_id, project_id, name, currency, discount_percent, tax_rate,
+			notes, terms, rrule, start_date, next_date, due_days, end_date, max_invoices,
+			auto_send, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query,
+		arg.WorkspaceID, arg.ClientID, arg.ProjectID, arg.Name, arg.Currency, arg.DiscountPercent, arg.TaxRate,
+		arg.Notes, arg.Terms, arg.RRule, arg.StartDate, arg.NextDate, arg.DueDays, arg.EndDate, arg.MaxInvoices,
+		arg.AutoSend, arg.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetInvoiceRecurrence(ctx, arg.WorkspaceID, id)
+}
+
+func (r *invoiceRecurrenceRepository) GetInvoiceRecurrence(ctx context.Context, workspaceID, recurrenceID uuid.UUID) (*InvoiceRecurrence, error) {
+	var recurrence InvoiceRecurrence
+	query := `SELECT ` + invoiceRecurrenceColumns + invoiceRecurrenceFrom + ` WHERE r.id = $1 AND r.workspace_id = $2 AND r.deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &recurrence, query, recurrenceID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvoiceRecurrenceNotFound
+		}
+		return nil, err
+	}
+	return &recurrence, nil
+}
+
+// GetInvoiceRecurrenceForUpdate loads a live recurrence and locks it until
+// the surrounding transaction ends, waiting for the scheduler if it holds it.
+func (r *invoiceRecurrenceRepository) GetInvoiceRecurrenceForUpdate(ctx context.Context, workspaceID, recurrenceID uuid.UUID) (*InvoiceRecurrence, error) {
+	var recurrence InvoiceRecurrence
+	query := `SELECT ` + invoiceRecurrenceColumns + invoiceRecurrenceFrom + ` WHERE r.id = $1 AND r.workspace_id = $2 AND r.deleted_at IS NULL FOR UPDATE OF r`
+	err := r.db.GetContext(ctx, &recurrence, query, recurrenceID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvoiceRecurrenceNotFound
+		}
+		return nil, err
+	}
+	return &recurrence, nil
+}
+
+func (r *invoiceRecurrenceRepository) ListInvoiceRecurrences(ctx context.Context, workspaceID uuid.UUID, filter InvoiceRecurrenceFilter, filters FilterParams) ([]InvoiceRecurrence, int64, error) {
+	var recurrences []InvoiceRecurrence
+	var args []any
+	argPos := 1
+
+	where := fmt.Sprintf(` WHERE r.workspace_id = $%d AND r.deleted_at IS NULL`, argPos)
+	args = append(args, workspaceID)
+	argPos++
+
+	if filter.ClientID != nil {
+		where += fmt.Sprintf(` AND r.client_id = $%d`, argPos)
+		args = append(args, *filter.ClientID)
+		argPos++
+	}
+	if filter.Active != nil {
+		if *filter.Active {
+			where += ` AND r.next_date IS NOT NULL`
+		} else {
+			where += ` AND r.next_date IS NULL`
+		}
+	}
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND (r.name ILIKE $%d OR c.name ILIKE $%d)`, argPos, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := filters.SortBy
+	switch sortBy {
+	case "name", "next_date", "updated_at":
+		sortBy = "r." + sortBy
+	default:
+		sortBy = "r.created_at"
+	}
+
+	query := `SELECT ` + invoiceRecurrenceColumns + invoiceRecurrenceFrom + where +
+		fmt.Sprintf(` ORDER BY %s %s NULLS LAST, r.id LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &recurrences, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*)`+invoiceRecurrenceFrom+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return recurrences, total, nil
+}
+
+func (r *invoiceRecurrenceRepository) UpdateInvoiceRecurrence(ctx context.Context, arg UpdateInvoiceRecurrenceParams) (*InvoiceRecurrence, error) {
+	query := `
+		UPDATE invoice_recurrences
+		SET client_id = $1, project_id = $2, name = $3, currency = $4, discount_percent = $5, tax_rate = $6,
+			notes = $7, terms = $8, rrule = $9, start_date = $10, due_days = $11,
+			end_date = $12, max_invoices = $13, auto_send = $14, updated_at = NOW()
+		WHERE id = $15 AND workspace_id = $16 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		arg.ClientID, arg.ProjectID, arg.Name, arg.Currency, arg.DiscountPercent, arg.TaxRate,
+		arg.Notes, arg.Terms, arg.RRule, arg.StartDate, arg.DueDays,
+		arg.EndDate, arg.MaxInvoices, arg.AutoSend, arg.ID, arg.WorkspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrInvoiceRecurrenceNotFound
+	}
+	return r.GetInvoiceRecurrence(ctx, arg.WorkspaceID, arg.ID)
+}
+
+// DeleteInvoiceRecurrence stops the schedule. Invoices already created are
+// kept.
+func (r *invoiceRecurrenceRepository) DeleteInvoiceRecurrence(ctx context.Context, workspaceID, recurrenceID uuid.UUID) error {
+	query := `UPDATE invoice_recurrences SET deleted_at = NOW(), next_date = NULL WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, recurrenceID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrInvoiceRecurrenceNotFound
+	}
+	return nil
+}
+
+func (r *invoiceRecurrenceRepository) ListInvoiceRecurrenceItems(ctx context.Context, recurrenceID uuid.UUID) ([]InvoiceRecurrenceItem, error) {
+	items := []InvoiceRecurrenceItem{}
+	query := `SELECT * FROM invoice_recurrence_items WHERE recurrence_id = $1 ORDER BY position`
+	err := r.db.SelectContext(ctx, &items, query, recurrenceID)
+	return items, err
+}
+
+func (r *invoiceRecurrenceRepository) ReplaceInvoiceRecurrenceItems(ctx context.Context, recurrenceID uuid.UUID, items []InvoiceRecurrenceItemParams) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM invoice_recurrence_items WHERE recurrence_id = $1`, recurrenceID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO invoice_recurrence_items (
+			recurrence_id, position, description, quantity, unit_price, discount_percent, tax_rate
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for i, item := range items {
+		_, err := r.db.ExecContext(ctx, query,
+			recurrenceID, i+1, item.Description, item.Quantity, item.UnitPrice, item.DiscountPercent, item.TaxRate,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListDueInvoiceRecurrenceIDs returns the live recurrences whose next invoice
+// falls on or before through. The caller decides per workspace timezone
+// whether a date has arrived.
+func (r *invoiceRecurrenceRepository) ListDueInvoiceRecurrenceIDs(ctx context.Context, through time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := `
+		SELECT id
+		FROM invoice_recurrences
+		WHERE deleted_at IS NULL AND next_date IS NOT NULL AND next_date <= $1
+		ORDER BY next_date
+		LIMIT $2
+	`
+	err := r.db.SelectContext(ctx, &ids, query, through, limit)
+	return ids, err
+}
+
+// LockInvoiceRecurrence locks a live recurrence until the surrounding
+// transaction ends. A recurrence another transaction holds is reported as not
+// found, so concurrent schedulers skip it instead of waiting.
+func (r *invoiceRecurrenceRepository) LockInvoiceRecurrence(ctx context.Context, recurrenceID uuid.UUID) (*InvoiceRecurrence, error) {
+	var recurrence InvoiceRecurrence
+	query := `SELECT ` + invoiceRecurrenceColumns + invoiceRecurrenceFrom + ` WHERE r.id = $1 AND r.deleted_at IS NULL FOR UPDATE OF r SKIP LOCKED`
+	err := r.db.GetContext(ctx, &recurrence, query, recurrenceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvoiceRecurrenceNotFound
+		}
+		return nil, err
+	}
+	return &recurrence, nil
+}
+
+func (r *invoiceRecurrenceRepository) SetInvoiceRecurrenceNextDate(ctx context.Context, recurrenceID uuid.UUID, next *time.Time) error {
+	query := `UPDATE invoice_recurrences SET next_date = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, next, recurrenceID)
+	return err
+}
+
+// InvoiceRecurrenceOccurrenceExists reports whether an invoice was already
+// created for an occurrence of a recurrence.
+func (r *invoiceRecurrenceRepository) InvoiceRecurrenceOccurrenceExists(ctx context.Context, recurrenceID uuid.UUID, date time.Time) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM invoices WHERE recurrence_id = $1 AND occurrence_date = $2)`
+	err := r.db.GetContext(ctx, &exists, query, recurrenceID, date)
+	return exists, err
+}
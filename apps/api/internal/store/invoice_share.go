@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvoiceShareLinkNotFound = errors.New("invoice share link not found")
+	ErrInvoiceShareLinkInvalid  = errors.New("invoice share link is invalid, expired or revoked")
+)
+
+// InvoiceShareLink lets a client open an invoice without an account.
+type InvoiceShareLink struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID   uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	InvoiceID     uuid.UUID  `json:"invoice_id" db:"invoice_id"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at" db:"revoked_at"`
+	ViewCount     int32      `json:"view_count" db:"view_count"`
+	FirstViewedAt *time.Time `json:"first_viewed_at" db:"first_viewed_at"`
+	LastViewedAt  *time.Time `json:"last_viewed_at" db:"last_viewed_at"`
+	CreatedBy     *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CreateInvoiceShareLinkParams struct {
+	WorkspaceID uuid.UUID
+	InvoiceID   uuid.UUID
+	ExpiresAt   time.Time
+	CreatedBy   uuid.UUID
+}
+
+type InvoiceShareRepository interface {
+	CreateInvoiceShareLink(ctx context.Context, arg CreateInvoiceShareLinkParams) (*InvoiceShareLink, error)
+	ListInvoiceShareLinks(ctx context.Context, invoiceID uuid.UUID) ([]InvoiceShareLink, error)
+	RevokeInvoiceShareLink(ctx context.Context, invoiceID, linkID uuid.UUID) error
+	RecordInvoiceShareLinkView(ctx context.Context, linkID uuid.UUID) (*InvoiceShareLink, error)
+}
+
+type invoiceShareRepository struct {
+	db DBTX
+}
+
+func NewInvoiceShareRepository(db DBTX) InvoiceShareRepository {
+	return &invoiceShareRepository{db: db}
+}
+
+func (r *invoiceShareRepository) CreateInvoiceShareLink(ctx context.Context, arg CreateInvoiceShareLinkParams) (*InvoiceShareLink, error) {
+	link := &InvoiceShareLink{}
+	query := `
+		INSERT INTO invoice_share_links (workspace_id, invoice_id, expires_at, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, link, query, arg.WorkspaceID, arg.InvoiceID, arg.ExpiresAt, arg.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (r *invoiceShareRepository) ListInvoiceShareLinks(ctx context.Context, invoiceID uuid.UUID) ([]InvoiceShareLink, error) {
+	links := []InvoiceShareLink{}
+	query := `SELECT * FROM invoice_share_links WHERE invoice_id = $1 ORDER BY created_at DESC`
+	err := r.db.SelectContext(ctx, &links, query, invoiceID)
+	return links, err
+}
+
+func (r *invoiceShareRepository) RevokeInvoiceShareLink(ctx context.Context, invoiceID, linkID uuid.UUID) error {
+	query := `UPDATE invoice_share_links SET revoked_at = NOW() WHERE id = $1 AND invoice_id = $2 AND revoked_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, linkID, invoiceID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrInvoiceShareLinkNotFound
+	}
+	return nil
+}
+
+// RecordInvoiceShareLinkView counts a view of a link that is still usable.
+// The first view is the one that leaves view_count at 1.
+func (r *invoiceShareRepository) RecordInvoiceShareLinkView(ctx context.Context, linkID uuid.UUID) (*InvoiceShareLink, error) {
+	var link InvoiceShareLink
+	query := `
+		UPDATE invoice_share_links l
+		SET view_count = l.view_count + 1,
+			first_viewed_at = COALESCE(l.first_viewed_at, NOW()),
+			last_viewed_at = NOW()
+		FROM workspaces w
+		WHERE l.id = $1
+			AND w.id = l.workspace_id
+			AND w.deleted_at IS NULL
+			AND l.revoked_at IS NULL
+			AND l.expires_at > NOW()
+		RETURNING l.*
+	`
+	err := r.db.GetContext(ctx, &link, query, linkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvoiceShareLinkInvalid
+		}
+		return nil, err
+	}
+	return &link, nil
+}
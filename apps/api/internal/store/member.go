@@ -0,0 +1,158 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// memberContentReassignments moves workspace content held by one member to
+// another, keyed by resource name. Every statement takes the workspace ID, the
+// previous holder and the new holder as $1, $2 and $3.
+var memberContentReassignments = []struct {
+	resource string
+	query    string
+}{
+	{"projects", `UPDATE projects SET owner_id = $3, updated_at = NOW() WHERE workspace_id = $1 AND owner_id = $2 AND deleted_at IS NULL`},
+	{"tasks", `UPDATE tasks SET assignee_id = $3, updated_at = NOW() WHERE workspace_id = $1 AND assignee_id = $2 AND status <> 'done' AND deleted_at IS NULL`},
+	{"recurring_tasks", `UPDATE task_recurrences SET assignee_id = $3, updated_at = NOW() WHERE workspace_id = $1 AND assignee_id = $2 AND deleted_at IS NULL`},
+	{"clients", `UPDATE clients SET owner_id = $3, updated_at = NOW() WHERE workspace_id = $1 AND owner_id = $2 AND deleted_at IS NULL`},
+}
+
+// GetWorkspaceMember returns the membership including deactivated members, so
+// callers can tell a deactivated member apart from a user who never joined.
+func (r *workspaceRepository) GetWorkspaceMember(ctx context.Context, workspaceID, userID uuid.UUID) (*WorkspaceMember, error) {
+	var member WorkspaceMember
+	query := `
+		SELECT wm.*, u.name, u.email, u.avatar_url
+		FROM workspace_members wm
+		JOIN users u ON wm.user_id = u.id
+		WHERE wm.workspace_id = $1 AND wm.user_id = $2 AND wm.deleted_at IS NULL AND u.deleted_at IS NULL
+	`
+	err := r.db.GetContext(ctx, &member, query, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// LockWorkspaceMember returns the membership like GetWorkspaceMember and locks
+// it until the surrounding transaction ends.
+func (r *workspaceRepository) LockWorkspaceMember(ctx context.Context, workspaceID, userID uuid.UUID) (*WorkspaceMember, error) {
+	var member WorkspaceMember
+	query := `
+		SELECT wm.*, u.name, u.email, u.avatar_url
+		FROM workspace_members wm
+		JOIN users u ON wm.user_id = u.id
+		WHERE wm.workspace_id = $1 AND wm.user_id = $2 AND wm.deleted_at IS NULL AND u.deleted_at IS NULL
+		FOR UPDATE OF wm
+	`
+	err := r.db.GetContext(ctx, &member, query, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListMembersByEmailHandle returns the active members whose email address or
+// its local part matches one of the lowercase handles.
+func (r *workspaceRepository) ListMembersByEmailHandle(ctx context.Context, workspaceID uuid.UUID, handles []string) ([]WorkspaceMember, error) {
+	var members []WorkspaceMember
+	query := `
+		SELECT wm.*, u.name, u.email, u.avatar_url
+		FROM workspace_members wm
+		JOIN users u ON wm.user_id = u.id
+		WHERE wm.workspace_id = $1 AND wm.deleted_at IS NULL AND wm.deactivated_at IS NULL AND u.deleted_at IS NULL
+			AND (LOWER(u.email) = ANY($2::text[]) OR LOWER(SPLIT_PART(u.email, '@', 1)) = ANY($2::text[]))
+	`
+	err := r.db.SelectContext(ctx, &members, query, workspaceID, handles)
+	return members, err
+}
+
+func (r *workspaceRepository) DeactivateWorkspaceMember(ctx context.Context, workspaceID, userID, deactivatedBy uuid.UUID) error {
+	query := `
+		UPDATE workspace_members
+		SET deactivated_at = NOW(), deactivated_by = $3
+		WHERE workspace_id = $1 AND user_id = $2 AND deleted_at IS NULL AND deactivated_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, workspaceID, userID, deactivatedBy)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotMember
+	}
+	return nil
+}
+
+func (r *workspaceRepository) ReactivateWorkspaceMember(ctx context.Context, workspaceID, userID uuid.UUID) error {
+	query := `
+		UPDATE workspace_members
+		SET deactivated_at = NULL, deactivated_by = NULL
+		WHERE workspace_id = $1 AND user_id = $2 AND deleted_at IS NULL AND deactivated_at IS NOT NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// SetWorkspaceMemberRate sets or, with a nil rate, clears the hourly rate
+// the member's time is priced at. Deactivated members keep their rate.
+func (r *workspaceRepository) SetWorkspaceMemberRate(ctx context.Context, workspaceID, userID uuid.UUID, hourlyRate *int64) error {
+	query := `
+		UPDATE workspace_members
+		SET hourly_rate = $3
+		WHERE workspace_id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, workspaceID, userID, hourlyRate)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// ReassignMemberContent runs every registered reassignment and reports how many
+// rows moved per resource.
+func (r *workspaceRepository) ReassignMemberContent(ctx context.Context, workspaceID, fromUserID, toUserID uuid.UUID) (map[string]int64, error) {
+	moved := make(map[string]int64, len(memberContentReassignments))
+	for _, reassignment := range memberContentReassignments {
+		result, err := r.db.ExecContext(ctx, reassignment.query, workspaceID, fromUserID, toUserID)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		moved[reassignment.resource] += rows
+	}
+	return moved, nil
+}
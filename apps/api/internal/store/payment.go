@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// Payment is money received for an invoice, or a refund of such a payment.
+// AppliedAmount is the part counted against the invoice; the rest of a
+// payment became client credit, and the rest of a refund came out of it.
+type Payment struct {
+	ID              uuid.UUID   `json:"id" db:"id"`
+	WorkspaceID     uuid.UUID   `json:"workspace_id" db:"workspace_id"`
+	ClientID        uuid.UUID   `json:"client_id" db:"client_id"`
+	InvoiceID       uuid.UUID   `json:"invoice_id" db:"invoice_id"`
+	Kind            string      `json:"kind" db:"kind"`
+	RefundOf        *uuid.UUID  `json:"refund_of" db:"refund_of"`
+	Method          string      `json:"method" db:"method"`
+	Amount          int64       `json:"amount" db:"amount"`
+	AppliedAmount   int64       `json:"applied_amount" db:"applied_amount"`
+	Currency        string      `json:"currency" db:"currency"`
+	BaseCurrency    *string     `json:"base_currency" db:"base_currency"`
+	ExchangeRate    *money.Rate `json:"exchange_rate" db:"exchange_rate" swaggertype:"number"`
+	Reference       *string     `json:"reference" db:"reference"`
+	PaidOn          time.Time   `json:"paid_on" db:"paid_on"`
+	Notes           *string     `json:"notes" db:"notes"`
+	CreatedBy       *uuid.UUID  `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time   `json:"created_at" db:"created_at"`
+	RefundedAmount  int64       `json:"refunded_amount" db:"refunded_amount"`
+	RefundedApplied int64       `json:"-" db:"refunded_applied"`
+}
+
+type CreatePaymentParams struct {
+	WorkspaceID   uuid.UUID
+	ClientID      uuid.UUID
+	InvoiceID     uuid.UUID
+	Kind          string
+	RefundOf      *uuid.UUID
+	Method        string
+	Amount        int64
+	AppliedAmount int64
+	Currency      string
+	Reference     *string
+	PaidOn        time.Time
+	Notes         *string
+	CreatedBy     uuid.UUID
+}
+
+// ClientCredit is an entry of a client's credit ledger. Positive amounts add
+// credit and negative amounts spend it.
+type ClientCredit struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID   uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	ClientID      uuid.UUID  `json:"client_id" db:"client_id"`
+	Currency      string     `json:"currency" db:"currency"`
+	Amount        int64      `json:"amount" db:"amount"`
+	PaymentID     *uuid.UUID `json:"payment_id" db:"payment_id"`
+	InvoiceID     *uuid.UUID `json:"invoice_id" db:"invoice_id"`
+	InvoiceNumber *string    `json:"invoice_number" db:"invoice_number"`
+	Description   string     `json:"description" db:"description"`
+	CreatedBy     *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CreateClientCreditParams struct {
+	WorkspaceID uuid.UUID
+	ClientID    uuid.UUID
+	Currency    string
+	Amount      int64
+	PaymentID   *uuid.UUID
+	InvoiceID   *uuid.UUID
+	Description string
+	CreatedBy   uuid.UUID
+}
+
+type CreditBalance struct {
+	Currency string `json:"currency" db:"currency"`
+	Amount   int64  `json:"amount" db:"amount"`
+}
+
+type PaymentRepository interface {
+	CreatePayment(ctx context.Context, arg CreatePaymentParams) (*Payment, error)
+	GetPayment(ctx context.Context, workspaceID, paymentID uuid.UUID) (*Payment, error)
+	ListInvoicePayments(ctx context.Context, invoiceID uuid.UUID) ([]Payment, error)
+	SyncInvoiceAmountPaid(ctx context.Context, invoiceID uuid.UUID) error
+
+	LockClientCredit(ctx context.Context, workspaceID, clientID uuid.UUID) error
+	GetClientCreditBalance(ctx context.Context, clientID uuid.UUID, currency string) (int64, error)
+	ListClientCreditBalances(ctx context.Context, clientID uuid.UUID) ([]CreditBalance, error)
+	CreateClientCredit(ctx context.Context, arg CreateClientCreditParams) error
+	ListClientCredits(ctx context.Context, clientID uuid.UUID) ([]ClientCredit, error)
+}
+
+type paymentRepository struct {
+	db DBTX
+}
+
+func NewPaymentRepository(db DBTX) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+const paymentColumns = `
+	p.*,
+	(SELECT COALESCE(SUM(r.amount), 0)::bigint FROM payments r WHERE r.refund_of = p.id) AS refunded_amount,
+	(SELECT COALESCE(SUM(r.applied_amount), 0)::bigint FROM payments r WHERE r.refund_of = p.id) AS refunded_applied
+`
+
+func (r *paymentRepository) CreatePayment(ctx context.Context, arg CreatePaymentParams) (*Payment, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO payments (
+			workspace_id, client_id, invoice_id, kind, refund_of, method,
+			amount, applied_amount, currency, reference, paid_on, notes, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query,
+		arg.WorkspaceID, arg.ClientID, arg.InvoiceID, arg.Kind, arg.RefundOf, arg.Method,
+		arg.Amount, arg.AppliedAmount, arg.Currency, arg.Reference, arg.PaidOn, arg.Notes, arg.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetPayment(ctx, arg.WorkspaceID, id)
+}
+
+func (r *paymentRepository) GetPayment(ctx context.Context, workspaceID, paymentID uuid.UUID) (*Payment, error) {
+	var payment Payment
+	query := `SELECT ` + paymentColumns + ` FROM payments p WHERE p.id = $1 AND p.workspace_id = $2`
+	err := r.db.GetContext(ctx, &payment, query, paymentID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// ListInvoicePayments returns the payments and refunds of an invoice, oldest
+// first.
+func (r *paymentRepository) ListInvoicePayments(ctx context.Context, invoiceID uuid.UUID) ([]Payment, error) {
+	payments := []Payment{}
+	query := `SELECT ` + paymentColumns + ` FROM payments p WHERE p.invoice_id = $1 ORDER BY p.paid_on, p.created_at, p.id`
+	err := r.db.SelectContext(ctx, &payments, query, invoiceID)
+	return payments, err
+}
+
+// SyncInvoiceAmountPaid sets the amount paid on an invoice to what its
+// payments applied, less what was refunded.
+func (r *paymentRepository) SyncInvoiceAmountPaid(ctx context.Context, invoiceID uuid.UUID) error {
+	query := `
+		UPDATE invoices
+		SET amount_paid = (
+				SELECT COALESCE(SUM(CASE WHEN kind = 'refund' THEN -applied_amount ELSE applied_amount END), 0)
+				FROM payments
+				WHERE invoice_id = $1
+			),
+			updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, invoiceID)
+	return err
+}
+
+// LockClientCredit holds the client's row lock until the surrounding
+// transaction ends, so spending credit is applied one change at a time and
+// a balance cannot be spent twice.
+func (r *paymentRepository) LockClientCredit(ctx context.Context, workspaceID, clientID uuid.UUID) error {
+	var id uuid.UUID
+	query := `SELECT id FROM clients WHERE id = $1 AND workspace_id = $2 FOR UPDATE`
+	err := r.db.GetContext(ctx, &id, query, clientID, workspaceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrClientNotFound
+	}
+	return err
+}
+
+func (r *paymentRepository) GetClientCreditBalance(ctx context.Context, clientID uuid.UUID, currency string) (int64, error) {
+	var balance int64
+	query := `SELECT COALESCE(SUM(amount), 0)::bigint FROM client_credits WHERE client_id = $1 AND currency = $2`
+	err := r.db.GetContext(ctx, &balance, query, clientID, currency)
+	return balance, err
+}
+
+// ListClientCreditBalances returns the credit a client holds in each
+// currency, leaving out currencies that are spent.
+func (r *paymentRepository) ListClientCreditBalances(ctx context.Context, clientID uuid.UUID) ([]CreditBalance, error) {
+	balances := []CreditBalance{}
+	query := `
+		SELECT currency, SUM(amount)::bigint AS amount
+		FROM client_credits
+		WHERE client_id = $1
+		GROUP BY currency
+		HAVING SUM(amount) <> 0
+		ORDER BY currency
+	`
+	err := r.db.SelectContext(ctx, &balances, query, clientID)
+	return balances, err
+}
+
+func (r *paymentRepository) CreateClientCredit(ctx context.Context, arg CreateClientCreditParams) error {
+	query := `
+		INSERT INTO client_credits (workspace_id, client_id, currency, amount, payment_id, invoice_id, description, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		arg.WorkspaceID, arg.ClientID, arg.Currency, arg.Amount, arg.PaymentID, arg.InvoiceID, arg.Description, arg.CreatedBy,
+	)
+	return err
+}
+
+// ListClientCredits returns the credit ledger of a client, newest first.
+func (r *paymentRepository) ListClientCredits(ctx context.Context, clientID uuid.UUID) ([]ClientCredit, error) {
+	credits := []ClientCredit{}
+	query := `
+		SELECT cc.*, i.number AS invoice_number
+		FROM client_credits cc
+		LEFT JOIN invoices i ON i.id = cc.invoice_id
+		WHERE cc.client_id = $1
+		ORDER BY cc.created_at DESC, cc.id
+	`
+	err := r.db.SelectContext(ctx, &credits, query, clientID)
+	return credits, err
+}
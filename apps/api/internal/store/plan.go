@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrPlanNotFound = errors.New("plan not found")
+
+// Plan describes the limits a workspace is entitled to. A nil limit means unlimited.
+type Plan struct {
+	ID              string    `json:"id" db:"id"`
+	Name            string    `json:"name" db:"name"`
+	MaxMembers      *int64    `json:"max_members" db:"max_members"`
+	MaxStorageBytes *int64    `json:"max_storage_bytes" db:"max_storage_bytes"`
+	MaxProjects     *int64    `json:"max_projects" db:"max_projects"`
+	MaxAPICalls     *int64    `json:"max_api_calls" db:"max_api_calls"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+func (p *Plan) Limit(metric UsageMetric) *int64 {
+	switch metric {
+	case UsageMembers:
+		return p.MaxMembers
+	case UsageStorageBytes:
+		return p.MaxStorageBytes
+	case UsageProjects:
+		return p.MaxProjects
+	case UsageAPICalls:
+		return p.MaxAPICalls
+	default:
+		return nil
+	}
+}
+
+type PlanRepository interface {
+	GetPlan(ctx context.Context, id string) (*Plan, error)
+	ListPlans(ctx context.Context) ([]Plan, error)
+	GetWorkspacePlan(ctx context.Context, workspaceID uuid.UUID) (*Plan, error)
+	SetWorkspacePlan(ctx context.Context, workspaceID uuid.UUID, planID string) error
+}
+
+type planRepository struct {
+	db DBTX
+}
+
+func NewPlanRepository(db DBTX) PlanRepository {
+	return &planRepository{db: db}
+}
+
+func (r *planRepository) GetPlan(ctx context.Context, id string) (*Plan, error) {
+	var plan Plan
+	query := `SELECT * FROM plans WHERE id = $1`
+	err := r.db.GetContext(ctx, &plan, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPlanNotFound
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *planRepository) ListPlans(ctx context.Context) ([]Plan, error) {
+	var plans []Plan
+	query := `SELECT * FROM plans ORDER BY max_members ASC NULLS LAST`
+	err := r.db.SelectContext(ctx, &plans, query)
+	return plans, err
+}
+
+func (r *planRepository) GetWorkspacePlan(ctx context.Context, workspaceID uuid.UUID) (*Plan, error) {
+	var plan Plan
+	query := `
+		SELECT p.*
+		FROM plans p
+		JOIN workspaces w ON w.plan_id = p.id
+		WHERE w.id = $1 AND w.deleted_at IS NULL
+	`
+	err := r.db.GetContext(ctx, &plan, query, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *planRepository) SetWorkspacePlan(ctx context.Context, workspaceID uuid.UUID, planID string) error {
+	query := `UPDATE workspaces SET plan_id = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, planID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrWorkspaceNotFound
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+var ErrProjectNotFound = errors.New("project not found")
+
+type Project struct {
+	ID                   uuid.UUID      `json:"id" db:"id"`
+	WorkspaceID          uuid.UUID      `json:"workspace_id" db:"workspace_id"`
+	Name                 string         `json:"name" db:"name"`
+	Description          *string        `json:"description" db:"description"`
+	Status               string         `json:"status" db:"status"`
+	Priority             string         `json:"priority" db:"priority"`
+	StartDate            *time.Time     `json:"start_date" db:"start_date"`
+	DueDate              *time.Time     `json:"due_date" db:"due_date"`
+	Budget               *int64         `json:"budget" db:"budget"`
+	BudgetType           string         `json:"budget_type" db:"budget_type"`
+	BudgetHours          *money.Decimal `json:"budget_hours" db:"budget_hours" swaggertype:"number"`
+	BudgetAlertedPercent *int           `json:"budget_alerted_percent" db:"budget_alerted_percent"`
+	// Spent is the burn of the budget as of SpentAt, refreshed by the budget
+	// alerts job and whenever the budget is viewed.
+	Spent      *int64     `json:"spent" db:"budget_spent"`
+	SpentAt    *time.Time `json:"spent_at" db:"budget_spent_at"`
+	HourlyRate *int64     `json:"hourly_rate" db:"hourly_rate"`
+	Currency   string     `json:"currency" db:"currency"`
+	Color      string     `json:"color" db:"color"`
+	Tags       Tags       `json:"tags" db:"tags"`
+	TeamID     *uuid.UUID `json:"team_id" db:"team_id"`
+	OwnerID    *uuid.UUID `json:"owner_id" db:"owner_id"`
+	ClientID   *uuid.UUID `json:"client_id" db:"client_id"`
+	CreatedBy  *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt  *time.Time `json:"-" db:"deleted_at"`
+}
+
+type CreateProjectParams struct {
+	WorkspaceID uuid.UUID
+	Name        string
+	Description *string
+	Status      string
+	Priority    string
+	StartDate   *time.Time
+	DueDate     *time.Time
+	Budget      *int64
+	BudgetType  string
+	BudgetHours *money.Decimal
+	HourlyRate  *int64
+	Currency    string
+	Color       string
+	Tags        Tags
+	TeamID      *uuid.UUID
+	OwnerID     *uuid.UUID
+	ClientID    *uuid.UUID
+	CreatedBy   uuid.UUID
+}
+
+type UpdateProjectParams struct {
+	ID          uuid.UUID
+	WorkspaceID uuid.UUID
+	Name        string
+	Description *string
+	Status      string
+	Priority    string
+	StartDate   *time.Time
+	DueDate     *time.Time
+	Budget      *int64
+	BudgetType  string
+	BudgetHours *money.Decimal
+	HourlyRate  *int64
+	Currency    string
+	Color       string
+	Tags        Tags
+	TeamID      *uuid.UUID
+	OwnerID     *uuid.UUID
+	ClientID    *uuid.UUID
+}
+
+// ProjectFilter narrows a project listing. Empty fields are ignored.
+type ProjectFilter struct {
+	Status   string
+	Priority string
+	Tag      string
+	TeamID   *uuid.UUID
+	OwnerID  *uuid.UUID
+	ClientID *uuid.UUID
+}
+
+type ProjectRepository interface {
+	CreateProject(ctx context.Context, arg CreateProjectParams) (*Project, error)
+	GetProject(ctx context.Context, workspaceID, projectID uuid.UUID) (*Project, error)
+	ListProjects(ctx context.Context, workspaceID uuid.UUID, filter ProjectFilter, filters FilterParams) ([]Project, int64, error)
+	UpdateProject(ctx context.Context, arg UpdateProjectParams) (*Project, error)
+	DeleteProject(ctx context.Context, workspaceID, projectID uuid.UUID) error
+
+	ListProjectBudgetTime(ctx context.Context, workspaceID, projectID uuid.UUID) ([]ProjectBudgetTime, error)
+	ListProjectBudgetExpenses(ctx context.Context, workspaceID, projectID uuid.UUID) ([]ProjectBudgetExpense, error)
+	ListBudgetedProjects(ctx context.Context, after uuid.UUID, limit int) ([]Project, error)
+	SetProjectBudgetAlert(ctx context.Context, projectID uuid.UUID, from, to *int) (bool, error)
+	SetProjectBudgetSpent(ctx context.Context, projectID uuid.UUID, spent int64) error
+}
+
+type projectRepository struct {
+	db DBTX
+}
+
+func NewProjectRepository(db DBTX) ProjectRepository {
+	return &projectRepository{db: db}
+}
+
+func (r *projectRepository) CreateProject(ctx context.Context, arg CreateProjectParams) (*Project, error) {
+	project := &Project{}
+	query := `
+		INSERT INTO projects (
+			workspace_id, name, description, status, priority, start_date, due_date,
+			budget, budget_type, budget_hours, hourly_rate, currency, color, tags, team_id, owner_id, client_id, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, project, query,
+		arg.WorkspaceID, arg.Name, arg.Description, arg.Status, arg.Priority, arg.StartDate, arg.DueDate,
+		arg.Budget, arg.BudgetType, arg.BudgetHours, arg.HourlyRate, arg.Currency, arg.Color, arg.Tags, arg.TeamID, arg.OwnerID, arg.ClientID, arg.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (r *projectRepository) GetProject(ctx context.Context, workspaceID, projectID uuid.UUID) (*Project, error) {
+	var project Project
+	query := `SELECT * FROM projects WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &project, query, projectID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *projectRepository) ListProjects(ctx context.Context, workspaceID uuid.UUID, filter ProjectFilter, filters FilterParams) ([]Project, int64, error) {
+	var projects []Project
+	var args []any
+	argPos := 1
+
+	where := fmt.Sprintf(` WHERE p.workspace_id = $%d AND p.deleted_at IS NULL`, argPos)
+	args = append(args, workspaceID)
+	argPos++
+
+	if filter.Status != "" {
+		where += fmt.Sprintf(` AND p.status = $%d`, argPos)
+		args = append(args, filter.Status)
+		argPos++
+	}
+	if filter.Priority != "" {
+		where += fmt.Sprintf(` AND p.priority = $%d`, argPos)
+		args = append(args, filter.Priority)
+		argPos++
+	}
+	if filter.Tag != "" {
+		where += fmt.Sprintf(` AND p.tags @> jsonb_build_array($%d::text)`, argPos)
+		args = append(args, filter.Tag)
+		argPos++
+	}
+	if filter.TeamID != nil {
+		where += fmt.Sprintf(` AND p.team_id = $%d`, argPos)
+		args = append(args, *filter.TeamID)
+		argPos++
+	}
+	if filter.OwnerID != nil {
+		where += fmt.Sprintf(` AND p.owner_id = $%d`, argPos)
+		args = append(args, *filter.OwnerID)
+		argPos++
+	}
+	if filter.ClientID != nil {
+		where += fmt.Sprintf(` AND p.client_id = $%d`, argPos)
+		args = append(args, *filter.ClientID)
+		argPos++
+	}
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND (p.name ILIKE $%d OR p.description ILIKE $%d)`, argPos, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := filters.SortBy
+	switch sortBy {
+	case "name", "status", "priority", "start_date", "due_date", "budget", "updated_at":
+		sortBy = "p." + sortBy
+	default:
+		sortBy = "p.created_at"
+	}
+
+	query := `SELECT p.* FROM projects p` + where +
+		fmt.Sprintf(` ORDER BY %s %s NULLS LAST, p.id LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &projects, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM projects p`+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return projects, total, nil
+}
+
+func (r *projectRepository) UpdateProject(ctx context.Context, arg UpdateProjectParams) (*Project, error) {
+	project := &Project{}
+	query := `
+		UPDATE projects
+		SET name = $1, description = $2, status = $3, priority = $4, start_date = $5, due_date = $6,
+			budget = $7, budget_type = $8, budget_hours = $9, hourly_rate = $10, currency = $11, color = $12,
+			tags = $13, team_id = $14, owner_id = $15, client_id = $16, updated_at = NOW()
+		WHERE id = $17 AND workspace_id = $18 AND deleted_at IS NULL
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, project, query,
+		arg.Name, arg.Description, arg.Status, arg.Priority, arg.StartDate, arg.DueDate,
+		arg.Budget, arg.BudgetType, arg.BudgetHours, arg.HourlyRate, arg.Currency, arg.Color,
+		arg.Tags, arg.TeamID, arg.OwnerID, arg.ClientID, arg.ID, arg.WorkspaceID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+func (r *projectRepository) DeleteProject(ctx context.Context, workspaceID, projectID uuid.UUID) error {
+	query := `UPDATE projects SET deleted_at = NOW() WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, projectID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
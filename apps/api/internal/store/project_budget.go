@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+// ProjectBudgetTime is the approved time logged on a project on one day at
+// one hourly rate. RateSource says where the rate came from: the task, the
+// member or the project are priced by Rate, while "workspace" time falls back
+// to the workspace default rate.
+type ProjectBudgetTime struct {
+	WorkDate   time.Time `db:"work_date"`
+	RateSource string    `db:"rate_source"`
+	Rate       *int64    `db:"rate"`
+	Seconds    int64     `db:"seconds"`
+}
+
+// ProjectBudgetExpense is an approved or reimbursed expense charged to a
+// project, with the exchange rate recorded for it if any.
+type ProjectBudgetExpense struct {
+	ID           uuid.UUID   `db:"id"`
+	Currency     string      `db:"currency"`
+	SpentOn      time.Time   `db:"spent_on"`
+	Amount       int64       `db:"amount"`
+	BaseCurrency *string     `db:"base_currency"`
+	ExchangeRate *money.Rate `db:"exchange_rate"`
+}
+
+// ListProjectBudgetTime sums the approved time on a project by day and rate.
+// Days are taken in UTC. Members who left the workspace keep pricing the time
+// they logged while they were in it.
+func (r *projectRepository) ListProjectBudgetTime(ctx context.Context, workspaceID, projectID uuid.UUID) ([]ProjectBudgetTime, error) {
+	entries := []ProjectBudgetTime{}
+	query := `
+		SELECT
+			(te.started_at AT TIME ZONE 'UTC')::date AS work_date,
+			CASE
+				WHEN t.hourly_rate IS NOT NULL THEN 'task'
+				WHEN wm.hourly_rate IS NOT NULL THEN 'member'
+				WHEN p.hourly_rate IS NOT NULL THEN 'project'
+				ELSE 'workspace'
+			END AS rate_source,
+			COALESCE(t.hourly_rate, wm.hourly_rate, p.hourly_rate) AS rate,
+			SUM(te.duration_seconds) AS seconds
+		FROM time_entries te
+		JOIN projects p ON p.id = te.project_id
+		LEFT JOIN tasks t ON t.id = te.task_id
+		LEFT JOIN workspace_members wm ON wm.workspace_id = te.workspace_id AND wm.user_id = te.user_id
+		WHERE te.workspace_id = $1
+			AND te.project_id = $2
+			AND te.approved_at IS NOT NULL
+			AND te.deleted_at IS NULL
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2
+	`
+	err := r.db.SelectContext(ctx, &entries, query, workspaceID, projectID)
+	return entries, err
+}
+
+// ListProjectBudgetExpenses returns the approved and reimbursed expenses
+// charged to a project.
+func (r *projectRepository) ListProjectBudgetExpenses(ctx context.Context, workspaceID, projectID uuid.UUID) ([]ProjectBudgetExpense, error) {
+	expenses := []ProjectBudgetExpense{}
+	query := `
+		SELECT e.id, e.currency, e.spent_on, e.amount, e.base_currency, e.exchange_rate
+		FROM expenses e
+		WHERE e.workspace_id = $1
+			AND e.project_id = $2
+			AND e.status IN ('approved', 'reimbursed')
+			AND e.deleted_at IS NULL
+		ORDER BY e.spent_on, e.id
+	`
+	err := r.db.SelectContext(ctx, &expenses, query, workspaceID, projectID)
+	return expenses, err
+}
+
+// ListBudgetedProjects pages through the projects that have a budget of
+// either type, or had one alerted, in ID order after the given ID.
+func (r *projectRepository) ListBudgetedProjects(ctx context.Context, after uuid.UUID, limit int) ([]Project, error) {
+	projects := []Project{}
+	query := `
+		SELECT * FROM projects
+		WHERE deleted_at IS NULL AND (budget > 0 OR budget_hours > 0 OR budget_alerted_percent IS NOT NULL) AND id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+	err := r.db.SelectContext(ctx, &projects, query, after, limit)
+	return projects, err
+}
+
+// SetProjectBudgetAlert moves the highest alerted threshold of a project from
+// one value to another. It reports false when another run already moved it,
+// so each threshold is only alerted once.
+func (r *projectRepository) SetProjectBudgetAlert(ctx context.Context, projectID uuid.UUID, from, to *int) (bool, error) {
+	query := `
+		UPDATE projects
+		SET budget_alerted_percent = $3
+		WHERE id = $1 AND budget_alerted_percent IS NOT DISTINCT FROM $2 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, projectID, from, to)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// SetProjectBudgetSpent records the latest computed burn of a project.
+func (r *projectRepository) SetProjectBudgetSpent(ctx context.Context, projectID uuid.UUID, spent int64) error {
+	query := `
+		UPDATE projects
+		SET budget_spent = $2, budget_spent_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, projectID, spent)
+	return err
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+// RevenueDocument is an invoice, payment or expense counted by a revenue
+// report, in its own currency, with the exchange rate recorded for it if any.
+// Expenses carry no client.
+type RevenueDocument struct {
+	ID           uuid.UUID   `db:"id"`
+	ClientID     uuid.UUID   `db:"client_id"`
+	ClientName   string      `db:"client_name"`
+	Currency     string      `db:"currency"`
+	Date         time.Time   `db:"document_date"`
+	Amount       int64       `db:"amount"`
+	Outstanding  int64       `db:"outstanding"`
+	BaseCurrency *string     `db:"base_currency"`
+	ExchangeRate *money.Rate `db:"exchange_rate"`
+}
+
+type ReportRepository interface {
+	ListRevenueInvoices(ctx context.Context, workspaceID uuid.UUID, from, to time.Time) ([]RevenueDocument, error)
+	ListRevenuePayments(ctx context.Context, workspaceID uuid.UUID, from, to time.Time) ([]RevenueDocument, error)
+	ListRevenueExpenses(ctx context.Context, workspaceID uuid.UUID, from, to time.Time) ([]RevenueDocument, error)
+}
+
+type reportRepository struct {
+	db DBTX
+}
+
+func NewReportRepository(db DBTX) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// ListRevenueInvoices returns the invoices issued between from and to,
+// inclusive, that were not cancelled. Outstanding is the balance still due on
+// open invoices.
+func (r *reportRepository) ListRevenueInvoices(ctx context.Context, workspaceID uuid.UUID, from, to time.Time) ([]RevenueDocument, error) {
+	documents := []RevenueDocument{}
+	query := `
+		SELECT
+			i.id, i.client_id, c.name AS client_name, i.currency, i.issue_date AS document_date,
+			i.total AS amount,
+			CASE WHEN i.status IN ('sent', 'viewed', 'overdue') THEN i.balance_due ELSE 0 END AS outstanding,
+			i.base_currency, i.exchange_rate
+		FROM invoices i
+		JOIN clients c ON c.id = i.client_id
+		WHERE i.workspace_id = $1
+			AND i.deleted_at IS NULL
+			AND i.status NOT IN ('draft', 'cancelled')
+			AND i.issue_date BETWEEN $2 AND $3
+		ORDER BY i.issue_date, i.id
+	`
+	err := r.db.SelectContext(ctx, &documents, query, workspaceID, from, to)
+	return documents, err
+}
+
+// ListRevenuePayments returns the money received between from and to,
+// inclusive, with refunds as negative amounts. Payments made with client
+// credit are left out: that money was counted when it was received.
+func (r *reportRepository) ListRevenuePayments(ctx context.Context, workspaceID uuid.UUID, from, to time.Time) ([]RevenueDocument, error) {
+	documents := []RevenueDocument{}
+	query := `
+		SELECT
+			p.id, p.client_id, c.name AS client_name, p.currency, p.paid_on AS document_date,
+			CASE WHEN p.kind = 'refund' THEN -p.amount ELSE p.amount END AS amount,
+			0::bigint AS outstanding,
+			p.base_currency, p.exchange_rate
+		FROM payments p
+		JOIN clients c ON c.id = p.client_id
+		WHERE p.workspace_id = $1
+			AND p.method <> 'credit'
+			AND p.paid_on BETWEEN $2 AND $3
+		ORDER BY p.paid_on, p.id
+	`
+	err := r.db.SelectContext(ctx, &documents, query, workspaceID, from, to)
+	return documents, err
+}
+
+// ListRevenueExpenses returns the approved and reimbursed expenses spent
+// between from and to, inclusive.
+func (r *reportRepository) ListRevenueExpenses(ctx context.Context, workspaceID uuid.UUID, from, to time.Time) ([]RevenueDocument, error) {
+	documents := []RevenueDocument{}
+	query := `
+		SELECT
+			e.id, e.currency, e.spent_on AS document_date, e.amount, 0::bigint AS outstanding,
+			e.base_currency, e.exchange_rate
+		FROM expenses e
+		WHERE e.workspace_id = $1
+			AND e.deleted_at IS NULL
+			AND e.status IN ('approved', 'reimbursed')
+			AND e.spent_on BETWEEN $2 AND $3
+		ORDER BY e.spent_on, e.id
+	`
+	err := r.db.SelectContext(ctx, &documents, query, workspaceID, from, to)
+	return documents, err
+}
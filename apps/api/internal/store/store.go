@@ -15,20 +15,66 @@ type DBTX interface {
 }
 
 type Store struct {
-	db         *sqlx.DB
-	Users      UserRepository
-	Sessions   SessionRepository
-	Workspaces WorkspaceRepository
-	AuditLogs  AuditLogRepository
+	db                 *sqlx.DB
+	Users              UserRepository
+	EmailVerifications EmailVerificationRepository
+	Sessions           SessionRepository
+	Workspaces         WorkspaceRepository
+	AuditLogs          AuditLogRepository
+	WorkspaceSettings  WorkspaceSettingsRepository
+	Plans              PlanRepository
+	Usage              UsageRepository
+	Invites            InviteRepository
+	Teams              TeamRepository
+	Projects           ProjectRepository
+	Tasks              TaskRepository
+	TaskRecurrences    TaskRecurrenceRepository
+	Comments           CommentRepository
+	TimeEntries        TimeEntryRepository
+	Timesheets         TimesheetRepository
+	Clients            ClientRepository
+	Invoices           InvoiceRepository
+	Payments           PaymentRepository
+	InvoiceShares      InvoiceShareRepository
+	InvoiceRecurrences InvoiceRecurrenceRepository
+	ExchangeRates      ExchangeRateRepository
+	Reports            ReportRepository
+	Expenses           ExpenseRepository
 }
 
 func New(db *sqlx.DB) *Store {
+	return newStore(db, db)
+}
+
+// newStore wires every repository to q, which is either the pool itself or an
+// open transaction.
+func newStore(db *sqlx.DB, q DBTX) *Store {
 	return &Store{
-		db:         db,
-		Users:      NewUserRepository(db),
-		Sessions:   NewSessionRepository(db),
-		Workspaces: NewWorkspaceRepository(db),
-		AuditLogs:  NewAuditLogRepository(db),
+		db:                 db,
+		Users:              NewUserRepository(q),
+		EmailVerifications: NewEmailVerificationRepository(q),
+		Sessions:           NewSessionRepository(q),
+		Workspaces:         NewWorkspaceRepository(q),
+		AuditLogs:          NewAuditLogRepository(q),
+		WorkspaceSettings:  NewWorkspaceSettingsRepository(q),
+		Plans:              NewPlanRepository(q),
+		Usage:              NewUsageRepository(q),
+		Invites:            NewInviteRepository(q),
+		Teams:              NewTeamRepository(q),
+		Projects:           NewProjectRepository(q),
+		Tasks:              NewTaskRepository(q),
+		TaskRecurrences:    NewTaskRecurrenceRepository(q),
+		Comments:           NewCommentRepository(q),
+		TimeEntries:        NewTimeEntryRepository(q),
+		Timesheets:         NewTimesheetRepository(q),
+		Clients:            NewClientRepository(q),
+		Invoices:           NewInvoiceRepository(q),
+		Payments:           NewPaymentRepository(q),
+		InvoiceShares:      NewInvoiceShareRepository(q),
+		InvoiceRecurrences: NewInvoiceRecurrenceRepository(q),
+		ExchangeRates:      NewExchangeRateRepository(q),
+		Reports:            NewReportRepository(q),
+		Expenses:           NewExpenseRepository(q),
 	}
 }
 
@@ -38,13 +84,7 @@ func (s *Store) ExecTx(ctx context.Context, fn func(*Store) error) error {
 		return fmt.Errorf("begin tx: %w", err)
 	}
 
-	txStore := &Store{
-		db:         s.db,
-		Users:      NewUserRepository(tx),
-		Sessions:   NewSessionRepository(tx),
-		Workspaces: NewWorkspaceRepository(tx),
-		AuditLogs:  NewAuditLogRepository(tx),
-	}
+	txStore := newStore(s.db, tx)
 
 	if err := fn(txStore); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
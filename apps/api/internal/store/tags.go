@@ -0,0 +1,35 @@
+package store
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Tags is a list of free-form labels stored as a JSONB array.
+type Tags []string
+
+func (t *Tags) Scan(src any) error {
+	*t = Tags{}
+
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported tags type %T", src)
+	}
+
+	return json.Unmarshal(data, t)
+}
+
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
@@ -0,0 +1,403 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTaskNotFound       = errors.New("task not found")
+	ErrTaskStatusConflict = errors.New("task status was changed concurrently")
+	ErrSubtaskNotFound    = errors.New("subtask not found")
+)
+
+type Task struct {
+	ID                uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID       uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	ProjectID         uuid.UUID  `json:"project_id" db:"project_id"`
+	Title             string     `json:"title" db:"title"`
+	Description       *string    `json:"description" db:"description"`
+	Status            string     `json:"status" db:"status"`
+	Priority          string     `json:"priority" db:"priority"`
+	Rank              string     `json:"rank" db:"rank"`
+	AssigneeID        *uuid.UUID `json:"assignee_id" db:"assignee_id"`
+	DueDate           *time.Time `json:"due_date" db:"due_date"`
+	EstimatedHours    *float64   `json:"estimated_hours" db:"estimated_hours"`
+	LoggedHours       float64    `json:"logged_hours" db:"logged_hours"`
+	HourlyRate        *int64     `json:"hourly_rate" db:"hourly_rate"`
+	Tags              Tags       `json:"tags" db:"tags"`
+	CreatedBy         *uuid.UUID `json:"created_by" db:"created_by"`
+	RecurrenceID      *uuid.UUID `json:"recurrence_id" db:"recurrence_id"`
+	OccurrenceDate    *time.Time `json:"occurrence_date" db:"occurrence_date"`
+	CompletedAt       *time.Time `json:"completed_at" db:"completed_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt         *time.Time `json:"-" db:"deleted_at"`
+	SubtaskCount      int64      `json:"subtask_count" db:"subtask_count"`
+	SubtasksCompleted int64      `json:"subtasks_completed" db:"subtasks_completed"`
+	CommentCount      int64      `json:"comment_count" db:"comment_count"`
+	Blocked           bool       `json:"blocked" db:"blocked"`
+	Subtasks          []Subtask  `json:"subtasks,omitempty" db:"-"`
+}
+
+type Subtask struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	TaskID      uuid.UUID  `json:"task_id" db:"task_id"`
+	Title       string     `json:"title" db:"title"`
+	Completed   bool       `json:"completed" db:"completed"`
+	Position    int32      `json:"position" db:"position"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type TagUsage struct {
+	Tag   string `json:"tag" db:"tag"`
+	Count int64  `json:"count" db:"count"`
+}
+
+type CreateTaskParams struct {
+	WorkspaceID    uuid.UUID
+	ProjectID      uuid.UUID
+	Title          string
+	Description    *string
+	Status         string
+	Priority       string
+	Rank           string
+	AssigneeID     *uuid.UUID
+	DueDate        *time.Time
+	EstimatedHours *float64
+	HourlyRate     *int64
+	Tags           Tags
+	CompletedAt    *time.Time
+	CreatedBy      *uuid.UUID
+	RecurrenceID   *uuid.UUID
+	OccurrenceDate *time.Time
+}
+
+type UpdateTaskParams struct {
+	ID             uuid.UUID
+	WorkspaceID    uuid.UUID
+	Title          string
+	Description    *string
+	Priority       string
+	AssigneeID     *uuid.UUID
+	DueDate        *time.Time
+	EstimatedHours *float64
+	HourlyRate     *int64
+	Tags           Tags
+}
+
+// TaskFilter narrows a task listing. Empty fields are ignored.
+type TaskFilter struct {
+	Status     string
+	Priority   string
+	Tag        string
+	AssigneeID *uuid.UUID
+	Unassigned bool
+	Blocked    *bool
+}
+
+type TaskRepository interface {
+	CreateTask(ctx context.Context, arg CreateTaskParams) (*Task, error)
+	GetTask(ctx context.Context, workspaceID, taskID uuid.UUID) (*Task, error)
+	ListTasks(ctx context.Context, workspaceID, projectID uuid.UUID, filter TaskFilter, filters FilterParams) ([]Task, int64, error)
+	UpdateTask(ctx context.Context, arg UpdateTaskParams) (*Task, error)
+	UpdateTaskStatus(ctx context.Context, workspaceID, taskID uuid.UUID, from, to, rank string) (*Task, error)
+	DeleteTask(ctx context.Context, workspaceID, taskID uuid.UUID) error
+	ListWorkspaceTaskTags(ctx context.Context, workspaceID uuid.UUID) ([]TagUsage, error)
+
+	LockTaskColumn(ctx context.Context, projectID uuid.UUID, status string) error
+	LastTaskRank(ctx context.Context, projectID uuid.UUID, status string, exclude []uuid.UUID) (string, error)
+	AdjacentTaskRank(ctx context.Context, projectID uuid.UUID, status, rank string, previous bool, exclude []uuid.UUID) (string, error)
+	MoveTask(ctx context.Context, workspaceID, taskID uuid.UUID, status, rank string) (*Task, error)
+	ListLongRankColumns(ctx context.Context, maxLength int) ([]TaskColumn, error)
+	ListColumnTaskIDs(ctx context.Context, projectID uuid.UUID, status string) ([]uuid.UUID, error)
+	SetTaskRanks(ctx context.Context, ids []uuid.UUID, ranks []string) error
+
+	LockProjectDependencies(ctx context.Context, projectID uuid.UUID) error
+	DependencyCreatesCycle(ctx context.Context, taskID, dependsOnID uuid.UUID) (bool, error)
+	AddTaskDependency(ctx context.Context, projectID, taskID, dependsOnID, createdBy uuid.UUID) (*TaskDependency, error)
+	RemoveTaskDependency(ctx context.Context, taskID, dependsOnID uuid.UUID) error
+	ListBlockingTasks(ctx context.Context, taskID uuid.UUID) ([]Task, error)
+	ListDependentTasks(ctx context.Context, taskID uuid.UUID) ([]Task, error)
+	ListProjectDependencies(ctx context.Context, projectID uuid.UUID) ([]TaskDependency, error)
+	ListOpenProjectTasks(ctx context.Context, projectID uuid.UUID) ([]Task, error)
+
+	ListSubtasks(ctx context.Context, taskID uuid.UUID) ([]Subtask, error)
+	CreateSubtask(ctx context.Context, taskID uuid.UUID, title string) (*Subtask, error)
+	UpdateSubtask(ctx context.Context, taskID, subtaskID uuid.UUID, title *string, completed *bool) (*Subtask, error)
+	DeleteSubtask(ctx context.Context, taskID, subtaskID uuid.UUID) error
+}
+
+type taskRepository struct {
+	db DBTX
+}
+
+func NewTaskRepository(db DBTX) TaskRepository {
+	return &taskRepository{db: db}
+}
+
+const taskColumns = `
+	t.*,
+	(SELECT COUNT(*) FROM task_subtasks st WHERE st.task_id = t.id) AS subtask_count,
+	(SELECT COUNT(*) FROM task_subtasks st WHERE st.task_id = t.id AND st.completed) AS subtasks_completed,
+	(SELECT COUNT(*) FROM comments c WHERE c.workspace_id = t.workspace_id AND c.resource_type = 'task' AND c.resource_id = t.id AND c.deleted_at IS NULL) AS comment_count,
+	EXISTS (
+		SELECT 1 FROM task_dependencies d
+		JOIN tasks b ON b.id = d.depends_on_id
+		WHERE d.task_id = t.id AND b.status <> 'done' AND b.deleted_at IS NULL
+	) AS blocked
+`
+
+func (r *taskRepository) CreateTask(ctx context.Context, arg CreateTaskParams) (*Task, error) {
+	task := &Task{}
+	query := `
+		INSERT INTO tasks (
+			workspace_id, project_id, title, description, status, priority, rank,
+			assignee_id, due_date, estimated_hours, hourly_rate, tags, completed_at, created_by,
+			recurrence_id, occurrence_date
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, task, query,
+		arg.WorkspaceID, arg.ProjectID, arg.Title, arg.Description, arg.Status, arg.Priority, arg.Rank,
+		arg.AssigneeID, arg.DueDate, arg.EstimatedHours, arg.HourlyRate, arg.Tags, arg.CompletedAt, arg.CreatedBy,
+		arg.RecurrenceID, arg.OccurrenceDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (r *taskRepository) GetTask(ctx context.Context, workspaceID, taskID uuid.UUID) (*Task, error) {
+	var task Task
+	query := `SELECT ` + taskColumns + ` FROM tasks t WHERE t.id = $1 AND t.workspace_id = $2 AND t.deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &task, query, taskID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *taskRepository) ListTasks(ctx context.Context, workspaceID, projectID uuid.UUID, filter TaskFilter, filters FilterParams) ([]Task, int64, error) {
+	var tasks []Task
+	var args []any
+	argPos := 1
+
+	where := fmt.Sprintf(` WHERE t.workspace_id = $%d AND t.project_id = $%d AND t.deleted_at IS NULL`, argPos, argPos+1)
+	args = append(args, workspaceID, projectID)
+	argPos += 2
+
+	if filter.Status != "" {
+		where += fmt.Sprintf(` AND t.status = $%d`, argPos)
+		args = append(args, filter.Status)
+		argPos++
+	}
+	if filter.Priority != "" {
+		where += fmt.Sprintf(` AND t.priority = $%d`, argPos)
+		args = append(args, filter.Priority)
+		argPos++
+	}
+	if filter.Tag != "" {
+		where += fmt.Sprintf(` AND t.tags @> jsonb_build_array($%d::text)`, argPos)
+		args = append(args, filter.Tag)
+		argPos++
+	}
+	if filter.AssigneeID != nil {
+		where += fmt.Sprintf(` AND t.assignee_id = $%d`, argPos)
+		args = append(args, *filter.AssigneeID)
+		argPos++
+	} else if filter.Unassigned {
+		where += ` AND t.assignee_id IS NULL`
+	}
+	if filter.Blocked != nil {
+		where += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM task_dependencies d
+			JOIN tasks b ON b.id = d.depends_on_id
+			WHERE d.task_id = t.id AND b.status <> 'done' AND b.deleted_at IS NULL
+		) = $%d`, argPos)
+		args = append(args, *filter.Blocked)
+		argPos++
+	}
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND (t.title ILIKE $%d OR t.description ILIKE $%d)`, argPos, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := filters.SortBy
+	switch sortBy {
+	case "status", "priority", "rank", "due_date", "updated_at":
+		sortBy = "t." + sortBy
+	case "name", "title":
+		sortBy = "t.title"
+	default:
+		sortBy = "t.created_at"
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks t` + where +
+		fmt.Sprintf(` ORDER BY %s %s NULLS LAST, t.id LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &tasks, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM tasks t`+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tasks, total, nil
+}
+
+func (r *taskRepository) UpdateTask(ctx context.Context, arg UpdateTaskParams) (*Task, error) {
+	query := `
+		UPDATE tasks
+		SET title = $1, description = $2, priority = $3, assignee_id = $4, due_date = $5,
+			estimated_hours = $6, hourly_rate = $7, tags = $8, updated_at = NOW()
+		WHERE id = $9 AND workspace_id = $10 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		arg.Title, arg.Description, arg.Priority, arg.AssigneeID, arg.DueDate,
+		arg.EstimatedHours, arg.HourlyRate, arg.Tags, arg.ID, arg.WorkspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrTaskNotFound
+	}
+	return r.GetTask(ctx, arg.WorkspaceID, arg.ID)
+}
+
+// UpdateTaskStatus only applies the change while the task is still in the from
+// status, so two concurrent transitions cannot both succeed. The task takes the
+// given rank in its new column.
+func (r *taskRepository) UpdateTaskStatus(ctx context.Context, workspaceID, taskID uuid.UUID, from, to, rank string) (*Task, error) {
+	query := `
+		UPDATE tasks
+		SET status = $1::task_status,
+			rank = $2,
+			completed_at = CASE WHEN $1::task_status = 'done' THEN NOW() ELSE NULL END,
+			updated_at = NOW()
+		WHERE id = $3 AND workspace_id = $4 AND status = $5 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, to, rank, taskID, workspaceID, from)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrTaskStatusConflict
+	}
+	return r.GetTask(ctx, workspaceID, taskID)
+}
+
+func (r *taskRepository) DeleteTask(ctx context.Context, workspaceID, taskID uuid.UUID) error {
+	query := `UPDATE tasks SET deleted_at = NOW(), rank = NULL WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, taskID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+func (r *taskRepository) ListWorkspaceTaskTags(ctx context.Context, workspaceID uuid.UUID) ([]TagUsage, error) {
+	var tags []TagUsage
+	query := `
+		SELECT tag, COUNT(*) AS count
+		FROM tasks t, jsonb_array_elements_text(t.tags) AS tag
+		WHERE t.workspace_id = $1 AND t.deleted_at IS NULL
+		GROUP BY tag
+		ORDER BY count DESC, tag ASC
+	`
+	err := r.db.SelectContext(ctx, &tags, query, workspaceID)
+	return tags, err
+}
+
+func (r *taskRepository) ListSubtasks(ctx context.Context, taskID uuid.UUID) ([]Subtask, error) {
+	var subtasks []Subtask
+	query := `SELECT * FROM task_subtasks WHERE task_id = $1 ORDER BY position ASC`
+	err := r.db.SelectContext(ctx, &subtasks, query, taskID)
+	return subtasks, err
+}
+
+func (r *taskRepository) CreateSubtask(ctx context.Context, taskID uuid.UUID, title string) (*Subtask, error) {
+	subtask := &Subtask{}
+	query := `
+		INSERT INTO task_subtasks (task_id, title, position)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position), 0) + 1 FROM task_subtasks WHERE task_id = $1))
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, subtask, query, taskID, title)
+	if err != nil {
+		return nil, err
+	}
+	return subtask, nil
+}
+
+// UpdateSubtask changes the title and completion state. Nil values are left
+// unchanged; completed_at is only stamped when the subtask becomes completed.
+func (r *taskRepository) UpdateSubtask(ctx context.Context, taskID, subtaskID uuid.UUID, title *string, completed *bool) (*Subtask, error) {
+	subtask := &Subtask{}
+	query := `
+		UPDATE task_subtasks
+		SET title = COALESCE($1::text, title),
+			completed = COALESCE($2::boolean, completed),
+			completed_at = CASE
+				WHEN $2::boolean IS NULL THEN completed_at
+				WHEN $2::boolean AND NOT completed THEN NOW()
+				WHEN $2::boolean THEN completed_at
+				ELSE NULL
+			END,
+			updated_at = NOW()
+		WHERE id = $3 AND task_id = $4
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, subtask, query, title, completed, subtaskID, taskID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSubtaskNotFound
+		}
+		return nil, err
+	}
+	return subtask, nil
+}
+
+func (r *taskRepository) DeleteSubtask(ctx context.Context, taskID, subtaskID uuid.UUID) error {
+	query := `DELETE FROM task_subtasks WHERE id = $1 AND task_id = $2`
+	result, err := r.db.ExecContext(ctx, query, subtaskID, taskID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrSubtaskNotFound
+	}
+	return nil
+}
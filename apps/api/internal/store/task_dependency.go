@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDependencyExists   = errors.New("dependency already exists")
+	ErrDependencyNotFound = errors.New("dependency not found")
+)
+
+// TaskDependency records that TaskID is blocked by DependsOnID.
+type TaskDependency struct {
+	TaskID      uuid.UUID  `json:"task_id" db:"task_id"`
+	DependsOnID uuid.UUID  `json:"depends_on_id" db:"depends_on_id"`
+	ProjectID   uuid.UUID  `json:"project_id" db:"project_id"`
+	CreatedBy   *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LockProjectDependencies serialises dependency changes within a project until
+// the surrounding transaction ends, so two inserts cannot close a cycle together.
+func (r *taskRepository) LockProjectDependencies(ctx context.Context, projectID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "task_dependencies:"+projectID.String())
+	return err
+}
+
+// DependencyCreatesCycle reports whether making taskID depend on dependsOnID
+// would close a cycle, that is whether dependsOnID already depends on taskID
+// directly or transitively.
+func (r *taskRepository) DependencyCreatesCycle(ctx context.Context, taskID, dependsOnID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `
+		WITH RECURSIVE chain(id) AS (
+			SELECT depends_on_id FROM task_dependencies WHERE task_id = $1
+			UNION
+			SELECT d.depends_on_id FROM task_dependencies d JOIN chain c ON d.task_id = c.id
+		)
+		SELECT EXISTS (SELECT 1 FROM chain WHERE id = $2)
+	`
+	err := r.db.GetContext(ctx, &exists, query, dependsOnID, taskID)
+	return exists, err
+}
+
+func (r *taskRepository) AddTaskDependency(ctx context.Context, projectID, taskID, dependsOnID, createdBy uuid.UUID) (*TaskDependency, error) {
+	dependency := &TaskDependency{}
+	query := `
+		INSERT INTO task_dependencies (task_id, depends_on_id, project_id, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, dependency, query, taskID, dependsOnID, projectID, createdBy)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrDependencyExists
+		}
+		return nil, err
+	}
+	return dependency, nil
+}
+
+func (r *taskRepository) RemoveTaskDependency(ctx context.Context, taskID, dependsOnID uuid.UUID) error {
+	query := `DELETE FROM task_dependencies WHERE task_id = $1 AND depends_on_id = $2`
+	result, err := r.db.ExecContext(ctx, query, taskID, dependsOnID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrDependencyNotFound
+	}
+	return nil
+}
+
+// ListBlockingTasks returns the live tasks taskID depends on.
+func (r *taskRepository) ListBlockingTasks(ctx context.Context, taskID uuid.UUID) ([]Task, error) {
+	var tasks []Task
+	query := `
+		SELECT ` + taskColumns + `
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.depends_on_id
+		WHERE d.task_id = $1 AND t.deleted_at IS NULL
+		ORDER BY t.rank ASC
+	`
+	err := r.db.SelectContext(ctx, &tasks, query, taskID)
+	return tasks, err
+}
+
+// ListDependentTasks returns the live tasks that depend on taskID.
+func (r *taskRepository) ListDependentTasks(ctx context.Context, taskID uuid.UUID) ([]Task, error) {
+	var tasks []Task
+	query := `
+		SELECT ` + taskColumns + `
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.task_id
+		WHERE d.depends_on_id = $1 AND t.deleted_at IS NULL
+		ORDER BY t.rank ASC
+	`
+	err := r.db.SelectContext(ctx, &tasks, query, taskID)
+	return tasks, err
+}
+
+// ListProjectDependencies returns the dependencies between live tasks of the
+// project.
+func (r *taskRepository) ListProjectDependencies(ctx context.Context, projectID uuid.UUID) ([]TaskDependency, error) {
+	var dependencies []TaskDependency
+	query := `
+		SELECT d.*
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.task_id AND t.deleted_at IS NULL
+		JOIN tasks b ON b.id = d.depends_on_id AND b.deleted_at IS NULL
+		WHERE d.project_id = $1
+	`
+	err := r.db.SelectContext(ctx, &dependencies, query, projectID)
+	return dependencies, err
+}
+
+// ListOpenProjectTasks returns the live tasks of the project that are not done,
+// in board order.
+func (r *taskRepository) ListOpenProjectTasks(ctx context.Context, projectID uuid.UUID) ([]Task, error) {
+	var tasks []Task
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks t
+		WHERE t.project_id = $1 AND t.status <> 'done' AND t.deleted_at IS NULL
+		ORDER BY t.rank ASC
+	`
+	err := r.db.SelectContext(ctx, &tasks, query, projectID)
+	return tasks, err
+}
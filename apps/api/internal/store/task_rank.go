@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// TaskColumn is one column of a project board.
+type TaskColumn struct {
+	ProjectID uuid.UUID `db:"project_id"`
+	Status    string    `db:"status"`
+}
+
+// LockTaskColumn serialises rank changes within a board column until the
+// surrounding transaction ends.
+func (r *taskRepository) LockTaskColumn(ctx context.Context, projectID uuid.UUID, status string) error {
+	_, err := r.db.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "task_column:"+projectID.String()+":"+status)
+	return err
+}
+
+// LastTaskRank returns the highest rank in the column, or an empty string for
+// an empty column. Tasks in exclude are ignored.
+func (r *taskRepository) LastTaskRank(ctx context.Context, projectID uuid.UUID, status string, exclude []uuid.UUID) (string, error) {
+	var rank string
+	query := `
+		SELECT COALESCE(MAX(rank), '')
+		FROM tasks
+		WHERE project_id = $1 AND status = $2 AND deleted_at IS NULL AND NOT (id::text = ANY($3::text[]))
+	`
+	err := r.db.GetContext(ctx, &rank, query, projectID, status, uuidStrings(exclude))
+	return rank, err
+}
+
+// AdjacentTaskRank returns the rank right after the given one in the column,
+// or right before it when previous is set. It returns an empty string when the
+// rank is at the end of the column. Tasks in exclude are ignored.
+func (r *taskRepository) AdjacentTaskRank(ctx context.Context, projectID uuid.UUID, status, rank string, previous bool, exclude []uuid.UUID) (string, error) {
+	query := `
+		SELECT rank FROM tasks
+		WHERE project_id = $1 AND status = $2 AND rank > $3 AND deleted_at IS NULL AND NOT (id::text = ANY($4::text[]))
+		ORDER BY rank ASC
+		LIMIT 1
+	`
+	if previous {
+		query = `
+			SELECT rank FROM tasks
+			WHERE project_id = $1 AND status = $2 AND rank < $3 AND deleted_at IS NULL AND NOT (id::text = ANY($4::text[]))
+			ORDER BY rank DESC
+			LIMIT 1
+		`
+	}
+
+	var adjacent string
+	err := r.db.GetContext(ctx, &adjacent, query, projectID, status, rank, uuidStrings(exclude))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return adjacent, nil
+}
+
+// MoveTask places the task in a column at the given rank. completed_at is set
+// when the task enters done and cleared when it leaves.
+func (r *taskRepository) MoveTask(ctx context.Context, workspaceID, taskID uuid.UUID, status, rank string) (*Task, error) {
+	query := `
+		UPDATE tasks
+		SET rank = $1,
+			completed_at = CASE
+				WHEN $2::task_status <> 'done' THEN NULL
+				WHEN status <> 'done' THEN NOW()
+				ELSE completed_at
+			END,
+			status = $2::task_status,
+			updated_at = NOW()
+		WHERE id = $3 AND workspace_id = $4 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, rank, status, taskID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrTaskNotFound
+	}
+	return r.GetTask(ctx, workspaceID, taskID)
+}
+
+// ListLongRankColumns returns the board columns holding a rank longer than
+// maxLength.
+func (r *taskRepository) ListLongRankColumns(ctx context.Context, maxLength int) ([]TaskColumn, error) {
+	var columns []TaskColumn
+	query := `
+		SELECT DISTINCT project_id, status
+		FROM tasks
+		WHERE deleted_at IS NULL AND LENGTH(rank) > $1
+	`
+	err := r.db.SelectContext(ctx, &columns, query, maxLength)
+	return columns, err
+}
+
+func (r *taskRepository) ListColumnTaskIDs(ctx context.Context, projectID uuid.UUID, status string) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := `SELECT id FROM tasks WHERE project_id = $1 AND status = $2 AND deleted_at IS NULL ORDER BY rank ASC`
+	err := r.db.SelectContext(ctx, &ids, query, projectID, status)
+	return ids, err
+}
+
+// SetTaskRanks assigns ranks[i] to ids[i] in a single statement.
+func (r *taskRepository) SetTaskRanks(ctx context.Context, ids []uuid.UUID, ranks []string) error {
+	query := `
+		UPDATE tasks t
+		SET rank = v.rank
+		FROM UNNEST($1::uuid[], $2::text[]) AS v(id, rank)
+		WHERE t.id = v.id
+	`
+	_, err := r.db.ExecContext(ctx, query, uuidStrings(ids), ranks)
+	return err
+}
@@ -0,0 +1,381 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRecurrenceNotFound          = errors.New("recurring task not found")
+	ErrRecurrenceExceptionNotFound = errors.New("occurrence override not found")
+)
+
+// TaskRecurrence is a task template that is copied into a new task for every
+// occurrence of its RRULE.
+type TaskRecurrence struct {
+	ID                 uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID        uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	ProjectID          uuid.UUID  `json:"project_id" db:"project_id"`
+	Title              string     `json:"title" db:"title"`
+	Description        *string    `json:"description" db:"description"`
+	Priority           string     `json:"priority" db:"priority"`
+	AssigneeID         *uuid.UUID `json:"assignee_id" db:"assignee_id"`
+	EstimatedHours     *float64   `json:"estimated_hours" db:"estimated_hours"`
+	Tags               Tags       `json:"tags" db:"tags"`
+	RRule              string     `json:"rrule" db:"rrule"`
+	StartDate          time.Time  `json:"start_date" db:"start_date"`
+	NextDate           *time.Time `json:"next_date" db:"next_date"`
+	CreatedBy          *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt          *time.Time `json:"-" db:"deleted_at"`
+	OccurrenceCount    int64      `json:"occurrence_count" db:"occurrence_count"`
+	OpenOccurrences    int64      `json:"open_occurrences" db:"open_occurrences"`
+	LastOccurrenceDate *time.Time `json:"last_occurrence_date" db:"last_occurrence_date"`
+}
+
+// RecurrenceException overrides a single occurrence before it is created. Nil
+// fields keep the template's value.
+type RecurrenceException struct {
+	RecurrenceID   uuid.UUID  `json:"recurrence_id" db:"recurrence_id"`
+	OccurrenceDate time.Time  `json:"occurrence_date" db:"occurrence_date"`
+	Skipped        bool       `json:"skipped" db:"skipped"`
+	Title          *string    `json:"title" db:"title"`
+	Description    *string    `json:"description" db:"description"`
+	Priority       *string    `json:"priority" db:"priority"`
+	AssigneeID     *uuid.UUID `json:"assignee_id" db:"assignee_id"`
+	EstimatedHours *float64   `json:"estimated_hours" db:"estimated_hours"`
+	DueDate        *time.Time `json:"due_date" db:"due_date"`
+	CreatedBy      *uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type CreateTaskRecurrenceParams struct {
+	WorkspaceID    uuid.UUID
+	ProjectID      uuid.UUID
+	Title          string
+	Description    *string
+	Priority       string
+	AssigneeID     *uuid.UUID
+	EstimatedHours *float64
+	Tags           Tags
+	RRule          string
+	StartDate      time.Time
+	NextDate       *time.Time
+	CreatedBy      uuid.UUID
+}
+
+type UpdateTaskRecurrenceParams struct {
+	ID             uuid.UUID
+	WorkspaceID    uuid.UUID
+	Title          string
+	Description    *string
+	Priority       string
+	AssigneeID     *uuid.UUID
+	EstimatedHours *float64
+	Tags           Tags
+	RRule          string
+	StartDate      time.Time
+}
+
+type UpsertRecurrenceExceptionParams struct {
+	RecurrenceID   uuid.UUID
+	OccurrenceDate time.Time
+	Skipped        bool
+	Title          *string
+	Description    *string
+	Priority       *string
+	AssigneeID     *uuid.UUID
+	EstimatedHours *float64
+	DueDate        *time.Time
+	CreatedBy      uuid.UUID
+}
+
+type TaskRecurrenceRepository interface {
+	CreateRecurrence(ctx context.Context, arg CreateTaskRecurrenceParams) (*TaskRecurrence, error)
+	GetRecurrence(ctx context.Context, workspaceID, recurrenceID uuid.UUID) (*TaskRecurrence, error)
+	GetRecurrenceForUpdate(ctx context.Context, workspaceID, recurrenceID uuid.UUID) (*TaskRecurrence, error)
+	ListRecurrences(ctx context.Context, workspaceID, projectID uuid.UUID, filters FilterParams) ([]TaskRecurrence, int64, error)
+	UpdateRecurrence(ctx context.Context, arg UpdateTaskRecurrenceParams) (*TaskRecurrence, error)
+	DeleteRecurrence(ctx context.Context, workspaceID, recurrenceID uuid.UUID) error
+
+	ListDueRecurrenceIDs(ctx context.Context, through time.Time, limit int) ([]uuid.UUID, error)
+	LockRecurrence(ctx context.Context, recurrenceID uuid.UUID) (*TaskRecurrence, error)
+	SetRecurrenceNextDate(ctx context.Context, recurrenceID uuid.UUID, next *time.Time) error
+	RecurrenceOccurrenceExists(ctx context.Context, recurrenceID uuid.UUID, date time.Time) (bool, error)
+
+	UpsertRecurrenceException(ctx context.Context, arg UpsertRecurrenceExceptionParams) (*RecurrenceException, error)
+	GetRecurrenceException(ctx context.Context, recurrenceID uuid.UUID, date time.Time) (*RecurrenceException, error)
+	ListRecurrenceExceptions(ctx context.Context, recurrenceID uuid.UUID, from time.Time) ([]RecurrenceException, error)
+	DeleteRecurrenceException(ctx context.Context, recurrenceID uuid.UUID, date time.Time) error
+}
+
+type taskRecurrenceRepository struct {
+	db DBTX
+}
+
+func NewTaskRecurrenceRepository(db DBTX) TaskRecurrenceRepository {
+	return &taskRecurrenceRepository{db: db}
+}
+
+const recurrenceColumns = `
+	r.*,
+	(SELECT COUNT(*) FROM tasks t WHERE t.recurrence_id = r.id) AS occurrence_count,
+	(SELECT COUNT(*) FROM tasks t WHERE t.recurrence_id = r.id AND t.status <> 'done' AND t.deleted_at IS NULL) AS open_occurrences,
+	(SELECT MAX(t.occurrence_date) FROM tasks t WHERE t.recurrence_id = r.id) AS last_occurrence_date
+`
+
+func (r *taskRecurrenceRepository) CreateRecurrence(ctx context.Context, arg CreateTaskRecurrenceParams) (*TaskRecurrence, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO task_recurrences (
+			workspace_id, project_id, title, description, priority, assignee_id,
+			estimated_hours, tags, rrule, start_date, next_date, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query,
+		arg.WorkspaceID, arg.ProjectID, arg.Title, arg.Description, arg.Priority, arg.AssigneeID,
+		arg.EstimatedHours, arg.Tags, arg.RRule, arg.StartDate, arg.NextDate, arg.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetRecurrence(ctx, arg.WorkspaceID, id)
+}
+
+func (r *taskRecurrenceRepository) GetRecurrence(ctx context.Context, workspaceID, recurrenceID uuid.UUID) (*TaskRecurrence, error) {
+	var recurrence TaskRecurrence
+	query := `SELECT ` + recurrenceColumns + ` FROM task_recurrences r WHERE r.id = $1 AND r.workspace_id = $2 AND r.deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &recurrence, query, recurrenceID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecurrenceNotFound
+		}
+		return nil, err
+	}
+	return &recurrence, nil
+}
+
+// GetRecurrenceForUpdate loads a live recurrence and locks it until the
+// surrounding transaction ends, waiting for the scheduler if it holds it.
+func (r *taskRecurrenceRepository) GetRecurrenceForUpdate(ctx context.Context, workspaceID, recurrenceID uuid.UUID) (*TaskRecurrence, error) {
+	var recurrence TaskRecurrence
+	query := `SELECT ` + recurrenceColumns + ` FROM task_recurrences r WHERE r.id = $1 AND r.workspace_id = $2 AND r.deleted_at IS NULL FOR UPDATE OF r`
+	err := r.db.GetContext(ctx, &recurrence, query, recurrenceID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecurrenceNotFound
+		}
+		return nil, err
+	}
+	return &recurrence, nil
+}
+
+func (r *taskRecurrenceRepository) ListRecurrences(ctx context.Context, workspaceID, projectID uuid.UUID, filters FilterParams) ([]TaskRecurrence, int64, error) {
+	var recurrences []TaskRecurrence
+	var args []any
+	argPos := 1
+
+	where := fmt.Sprintf(` WHERE r.workspace_id = $%d AND r.project_id = $%d AND r.deleted_at IS NULL`, argPos, argPos+1)
+	args = append(args, workspaceID, projectID)
+	argPos += 2
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND (r.title ILIKE $%d OR r.description ILIKE $%d)`, argPos, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := filters.SortBy
+	switch sortBy {
+	case "title", "priority", "next_date", "updated_at":
+		sortBy = "r." + sortBy
+	case "name":
+		sortBy = "r.title"
+	default:
+		sortBy = "r.created_at"
+	}
+
+	query := `SELECT ` + recurrenceColumns + ` FROM task_recurrences r` + where +
+		fmt.Sprintf(` ORDER BY %s %s NULLS LAST, r.id LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &recurrences, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM task_recurrences r`+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return recurrences, total, nil
+}
+
+func (r *taskRecurrenceRepository) UpdateRecurrence(ctx context.Context, arg UpdateTaskRecurrenceParams) (*TaskRecurrence, error) {
+	query := `
+		UPDATE task_recurrences
+		SET title = $1, description = $2, priority = $3, assignee_id = $4, estimated_hours = $5,
+			tags = $6, rrule = $7, start_date = $8, updated_at = NOW()
+		WHERE id = $9 AND workspace_id = $10 AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		arg.Title, arg.Description, arg.Priority, arg.AssigneeID, arg.EstimatedHours,
+		arg.Tags, arg.RRule, arg.StartDate, arg.ID, arg.WorkspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrRecurrenceNotFound
+	}
+	return r.GetRecurrence(ctx, arg.WorkspaceID, arg.ID)
+}
+
+// DeleteRecurrence stops the schedule. Tasks already created are kept.
+func (r *taskRecurrenceRepository) DeleteRecurrence(ctx context.Context, workspaceID, recurrenceID uuid.UUID) error {
+	query := `UPDATE task_recurrences SET deleted_at = NOW(), next_date = NULL WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, recurrenceID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecurrenceNotFound
+	}
+	return nil
+}
+
+// ListDueRecurrenceIDs returns the live recurrences of live projects whose next
+// occurrence falls on or before through, or whose previous occurrences are all
+// done. The caller decides per workspace timezone whether a date has arrived.
+func (r *taskRecurrenceRepository) ListDueRecurrenceIDs(ctx context.Context, through time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := `
+		SELECT r.id
+		FROM task_recurrences r
+		JOIN projects p ON p.id = r.project_id AND p.deleted_at IS NULL
+		WHERE r.deleted_at IS NULL AND r.next_date IS NOT NULL
+			AND (
+				r.next_date <= $1
+				OR NOT EXISTS (
+					SELECT 1 FROM tasks t
+					WHERE t.recurrence_id = r.id AND t.status <> 'done' AND t.deleted_at IS NULL
+				)
+			)
+		ORDER BY r.next_date
+		LIMIT $2
+	`
+	err := r.db.SelectContext(ctx, &ids, query, through, limit)
+	return ids, err
+}
+
+// LockRecurrence locks a live recurrence until the surrounding transaction
+// ends. A recurrence another transaction holds is reported as not found, so
+// concurrent schedulers skip it instead of waiting.
+func (r *taskRecurrenceRepository) LockRecurrence(ctx context.Context, recurrenceID uuid.UUID) (*TaskRecurrence, error) {
+	var recurrence TaskRecurrence
+	query := `SELECT ` + recurrenceColumns + ` FROM task_recurrences r WHERE r.id = $1 AND r.deleted_at IS NULL FOR UPDATE OF r SKIP LOCKED`
+	err := r.db.GetContext(ctx, &recurrence, query, recurrenceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecurrenceNotFound
+		}
+		return nil, err
+	}
+	return &recurrence, nil
+}
+
+func (r *taskRecurrenceRepository) SetRecurrenceNextDate(ctx context.Context, recurrenceID uuid.UUID, next *time.Time) error {
+	query := `UPDATE task_recurrences SET next_date = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, next, recurrenceID)
+	return err
+}
+
+// RecurrenceOccurrenceExists reports whether a task was already created for
+// an occurrence of a recurrence, deleted tasks included.
+func (r *taskRecurrenceRepository) RecurrenceOccurrenceExists(ctx context.Context, recurrenceID uuid.UUID, date time.Time) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM tasks WHERE recurrence_id = $1 AND occurrence_date = $2)`
+	err := r.db.GetContext(ctx, &exists, query, recurrenceID, date)
+	return exists, err
+}
+
+func (r *taskRecurrenceRepository) UpsertRecurrenceException(ctx context.Context, arg UpsertRecurrenceExceptionParams) (*RecurrenceException, error) {
+	exception := &RecurrenceException{}
+	query := `
+		INSERT INTO task_recurrence_exceptions (
+			recurrence_id, occurrence_date, skipped, title, description, priority,
+			assignee_id, estimated_hours, due_date, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (recurrence_id, occurrence_date) DO UPDATE
+		SET skipped = EXCLUDED.skipped, title = EXCLUDED.title, description = EXCLUDED.description,
+			priority = EXCLUDED.priority, assignee_id = EXCLUDED.assignee_id,
+			estimated_hours = EXCLUDED.estimated_hours, due_date = EXCLUDED.due_date, updated_at = NOW()
+		RETURNING *
+	`
+	err := r.db.GetContext(ctx, exception, query,
+		arg.RecurrenceID, arg.OccurrenceDate, arg.Skipped, arg.Title, arg.Description, arg.Priority,
+		arg.AssigneeID, arg.EstimatedHours, arg.DueDate, arg.CreatedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return exception, nil
+}
+
+func (r *taskRecurrenceRepository) GetRecurrenceException(ctx context.Context, recurrenceID uuid.UUID, date time.Time) (*RecurrenceException, error) {
+	var exception RecurrenceException
+	query := `SELECT * FROM task_recurrence_exceptions WHERE recurrence_id = $1 AND occurrence_date = $2`
+	err := r.db.GetContext(ctx, &exception, query, recurrenceID, date)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecurrenceExceptionNotFound
+		}
+		return nil, err
+	}
+	return &exception, nil
+}
+
+// ListRecurrenceExceptions returns the overrides of occurrences on or after
+// from, in date order.
+func (r *taskRecurrenceRepository) ListRecurrenceExceptions(ctx context.Context, recurrenceID uuid.UUID, from time.Time) ([]RecurrenceException, error) {
+	var exceptions []RecurrenceException
+	query := `
+		SELECT * FROM task_recurrence_exceptions
+		WHERE recurrence_id = $1 AND occurrence_date >= $2
+		ORDER BY occurrence_date
+	`
+	err := r.db.SelectContext(ctx, &exceptions, query, recurrenceID, from)
+	return exceptions, err
+}
+
+func (r *taskRecurrenceRepository) DeleteRecurrenceException(ctx context.Context, recurrenceID uuid.UUID, date time.Time) error {
+	query := `DELETE FROM task_recurrence_exceptions WHERE recurrence_id = $1 AND occurrence_date = $2`
+	result, err := r.db.ExecContext(ctx, query, recurrenceID, date)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecurrenceExceptionNotFound
+	}
+	return nil
+}
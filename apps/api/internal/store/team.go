@@ -0,0 +1,257 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrTeamNotFound  = errors.New("team not found")
+	ErrTeamNameTaken = errors.New("team name already taken")
+	ErrNotTeamMember = errors.New("user is not a member of the team")
+)
+
+type Team struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	WorkspaceID uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	Name        string    `json:"name" db:"name"`
+	Description *string   `json:"description" db:"description"`
+	RoleGrant   *string   `json:"role_grant" db:"role_grant"`
+	MemberCount int64     `json:"member_count" db:"member_count"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type TeamMember struct {
+	TeamID    uuid.UUID `json:"team_id" db:"team_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	AddedAt   time.Time `json:"added_at" db:"added_at"`
+	Name      string    `json:"name" db:"name"`
+	Email     *string   `json:"email" db:"email"`
+	AvatarURL *string   `json:"avatar_url" db:"avatar_url"`
+}
+
+type CreateTeamParams struct {
+	WorkspaceID uuid.UUID
+	Name        string
+	Description *string
+	RoleGrant   *string
+}
+
+type UpdateTeamParams struct {
+	ID          uuid.UUID
+	WorkspaceID uuid.UUID
+	Name        string
+	Description *string
+	RoleGrant   *string
+}
+
+type TeamRepository interface {
+	CreateTeam(ctx context.Context, arg CreateTeamParams) (*Team, error)
+	GetTeam(ctx context.Context, workspaceID, teamID uuid.UUID) (*Team, error)
+	ListTeams(ctx context.Context, workspaceID uuid.UUID, filters FilterParams) ([]Team, int64, error)
+	UpdateTeam(ctx context.Context, arg UpdateTeamParams) (*Team, error)
+	DeleteTeam(ctx context.Context, workspaceID, teamID uuid.UUID) error
+	AddTeamMember(ctx context.Context, teamID, userID uuid.UUID) error
+	RemoveTeamMember(ctx context.Context, teamID, userID uuid.UUID) error
+	ListTeamMembers(ctx context.Context, teamID uuid.UUID) ([]TeamMember, error)
+	ListUserTeamIDs(ctx context.Context, workspaceID, userID uuid.UUID) ([]uuid.UUID, error)
+	GetTeamRoleGrant(ctx context.Context, workspaceID, userID uuid.UUID) (string, error)
+}
+
+type teamRepository struct {
+	db DBTX
+}
+
+func NewTeamRepository(db DBTX) TeamRepository {
+	return &teamRepository{db: db}
+}
+
+const teamColumns = `
+	t.id, t.workspace_id, t.name, t.description, t.role_grant, t.created_at, t.updated_at,
+	(SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id) AS member_count
+`
+
+func (r *teamRepository) CreateTeam(ctx context.Context, arg CreateTeamParams) (*Team, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO teams (workspace_id, name, description, role_grant)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query, arg.WorkspaceID, arg.Name, arg.Description, arg.RoleGrant)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrTeamNameTaken
+		}
+		return nil, err
+	}
+	return r.GetTeam(ctx, arg.WorkspaceID, id)
+}
+
+func (r *teamRepository) GetTeam(ctx context.Context, workspaceID, teamID uuid.UUID) (*Team, error) {
+	var team Team
+	query := `SELECT ` + teamColumns + ` FROM teams t WHERE t.id = $1 AND t.workspace_id = $2`
+	err := r.db.GetContext(ctx, &team, query, teamID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (r *teamRepository) ListTeams(ctx context.Context, workspaceID uuid.UUID, filters FilterParams) ([]Team, int64, error) {
+	var teams []Team
+	var args []any
+	argPos := 1
+
+	where := fmt.Sprintf(` WHERE t.workspace_id = $%d`, argPos)
+	args = append(args, workspaceID)
+	argPos++
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND (t.name ILIKE $%d OR t.description ILIKE $%d)`, argPos, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := filters.SortBy
+	switch sortBy {
+	case "name", "updated_at":
+		sortBy = "t." + sortBy
+	default:
+		sortBy = "t.created_at"
+	}
+
+	query := `SELECT ` + teamColumns + ` FROM teams t` + where +
+		fmt.Sprintf(` ORDER BY %s %s LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &teams, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM teams t`+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return teams, total, nil
+}
+
+func (r *teamRepository) UpdateTeam(ctx context.Context, arg UpdateTeamParams) (*Team, error) {
+	query := `
+		UPDATE teams
+		SET name = $1, description = $2, role_grant = $3, updated_at = NOW()
+		WHERE id = $4 AND workspace_id = $5
+	`
+	result, err := r.db.ExecContext(ctx, query, arg.Name, arg.Description, arg.RoleGrant, arg.ID, arg.WorkspaceID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrTeamNameTaken
+		}
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, ErrTeamNotFound
+	}
+	return r.GetTeam(ctx, arg.WorkspaceID, arg.ID)
+}
+
+func (r *teamRepository) DeleteTeam(ctx context.Context, workspaceID, teamID uuid.UUID) error {
+	query := `DELETE FROM teams WHERE id = $1 AND workspace_id = $2`
+	result, err := r.db.ExecContext(ctx, query, teamID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrTeamNotFound
+	}
+	return nil
+}
+
+func (r *teamRepository) AddTeamMember(ctx context.Context, teamID, userID uuid.UUID) error {
+	query := `
+		INSERT INTO team_members (team_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (team_id, user_id) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, teamID, userID)
+	return err
+}
+
+func (r *teamRepository) RemoveTeamMember(ctx context.Context, teamID, userID uuid.UUID) error {
+	query := `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`
+	result, err := r.db.ExecContext(ctx, query, teamID, userID)
+	if err != nil {
+		return err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return ErrNotTeamMember
+	}
+	return nil
+}
+
+func (r *teamRepository) ListTeamMembers(ctx context.Context, teamID uuid.UUID) ([]TeamMember, error) {
+	var members []TeamMember
+	query := `
+		SELECT tm.team_id, tm.user_id, tm.added_at, u.name, u.email, u.avatar_url
+		FROM team_members tm
+		JOIN users u ON u.id = tm.user_id
+		WHERE tm.team_id = $1 AND u.deleted_at IS NULL
+		ORDER BY u.name ASC
+	`
+	err := r.db.SelectContext(ctx, &members, query, teamID)
+	return members, err
+}
+
+func (r *teamRepository) ListUserTeamIDs(ctx context.Context, workspaceID, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := `
+		SELECT t.id
+		FROM teams t
+		JOIN team_members tm ON tm.team_id = t.id
+		WHERE t.workspace_id = $1 AND tm.user_id = $2
+	`
+	err := r.db.SelectContext(ctx, &ids, query, workspaceID, userID)
+	return ids, err
+}
+
+// GetTeamRoleGrant returns the strongest role granted to the user through any of
+// their teams in the workspace, or an empty string if none grants a role.
+func (r *teamRepository) GetTeamRoleGrant(ctx context.Context, workspaceID, userID uuid.UUID) (string, error) {
+	var role string
+	query := `
+		SELECT t.role_grant
+		FROM teams t
+		JOIN team_members tm ON tm.team_id = t.id
+		WHERE t.workspace_id = $1 AND tm.user_id = $2 AND t.role_grant IS NOT NULL
+		ORDER BY t.role_grant ASC
+		LIMIT 1
+	`
+	err := r.db.GetContext(ctx, &role, query, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,398 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTimeEntryNotFound = errors.New("time entry not found")
+	ErrTimeEntryBilled   = errors.New("time entry is billed")
+	ErrTimerRunning      = errors.New("a timer is already running")
+)
+
+type TimeEntry struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID     uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	ProjectID       uuid.UUID  `json:"project_id" db:"project_id"`
+	TaskID          *uuid.UUID `json:"task_id" db:"task_id"`
+	Description     *string    `json:"description" db:"description"`
+	StartedAt       time.Time  `json:"started_at" db:"started_at"`
+	EndedAt         *time.Time `json:"ended_at" db:"ended_at"`
+	DurationSeconds int64      `json:"duration_seconds" db:"duration_seconds"`
+	Billable        bool       `json:"billable" db:"billable"`
+	Billed          bool       `json:"billed" db:"billed"`
+	InvoiceID       *uuid.UUID `json:"invoice_id" db:"invoice_id"`
+	ApprovedAt      *time.Time `json:"approved_at" db:"approved_at"`
+	Tags            Tags       `json:"tags" db:"tags"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time `json:"-" db:"deleted_at"`
+	UserName        *string    `json:"user_name" db:"user_name"`
+	ProjectName     string     `json:"project_name" db:"project_name"`
+	TaskTitle       *string    `json:"task_title" db:"task_title"`
+}
+
+type CreateTimeEntryParams struct {
+	WorkspaceID     uuid.UUID
+	UserID          uuid.UUID
+	ProjectID       uuid.UUID
+	TaskID          *uuid.UUID
+	Description     *string
+	StartedAt       time.Time
+	EndedAt         *time.Time
+	DurationSeconds int64
+	Billable        bool
+	Tags            Tags
+}
+
+type UpdateTimeEntryParams struct {
+	ID              uuid.UUID
+	WorkspaceID     uuid.UUID
+	ProjectID       uuid.UUID
+	TaskID          *uuid.UUID
+	Description     *string
+	StartedAt       time.Time
+	EndedAt         *time.Time
+	DurationSeconds int64
+	Billable        bool
+	Tags            Tags
+}
+
+// TimeEntryFilter narrows a time entry listing. From and To bound the start
+// time, To being exclusive. Empty fields are ignored.
+type TimeEntryFilter struct {
+	UserID    *uuid.UUID
+	ProjectID *uuid.UUID
+	TaskID    *uuid.UUID
+	Billable  *bool
+	Billed    *bool
+	Running   *bool
+	From      *time.Time
+	To        *time.Time
+}
+
+// UnbilledTimeEntryFilter selects the billable time of a client or project
+// that has not been invoiced yet. From and To bound the start time, To being
+// exclusive.
+type UnbilledTimeEntryFilter struct {
+	ClientID     *uuid.UUID
+	ProjectID    *uuid.UUID
+	From         time.Time
+	To           time.Time
+	ApprovedOnly bool
+}
+
+type TimeEntryRepository interface {
+	CreateTimeEntry(ctx context.Context, arg CreateTimeEntryParams) (*TimeEntry, error)
+	GetTimeEntry(ctx context.Context, workspaceID, entryID uuid.UUID) (*TimeEntry, error)
+	GetRunningTimeEntry(ctx context.Context, userID uuid.UUID) (*TimeEntry, error)
+	ListTimeEntries(ctx context.Context, workspaceID uuid.UUID, filter TimeEntryFilter, filters FilterParams) ([]TimeEntry, int64, error)
+	UpdateTimeEntry(ctx context.Context, arg UpdateTimeEntryParams) (*TimeEntry, error)
+	DeleteTimeEntry(ctx context.Context, workspaceID, entryID uuid.UUID) error
+
+	LockUserTimeEntries(ctx context.Context, userID uuid.UUID) error
+	HasOverlappingTimeEntry(ctx context.Context, userID uuid.UUID, start time.Time, end *time.Time, exclude *uuid.UUID) (bool, error)
+	RecalculateTaskLoggedHours(ctx context.Context, taskID uuid.UUID) error
+
+	ListUnbilledTimeEntries(ctx context.Context, workspaceID uuid.UUID, filter UnbilledTimeEntryFilter) ([]TimeEntry, error)
+	MarkTimeEntriesBilled(ctx context.Context, invoiceID uuid.UUID, entryIDs []uuid.UUID) (int64, error)
+	ReleaseInvoiceTimeEntries(ctx context.Context, invoiceID uuid.UUID) error
+}
+
+type timeEntryRepository struct {
+	db DBTX
+}
+
+func NewTimeEntryRepository(db DBTX) TimeEntryRepository {
+	return &timeEntryRepository{db: db}
+}
+
+const timeEntryColumns = `e.*, u.name AS user_name, p.name AS project_name, t.title AS task_title`
+
+const timeEntryJoins = `
+	FROM time_entries e
+	JOIN projects p ON p.id = e.project_id
+	LEFT JOIN users u ON u.id = e.user_id
+	LEFT JOIN tasks t ON t.id = e.task_id
+`
+
+func (r *timeEntryRepository) CreateTimeEntry(ctx context.Context, arg CreateTimeEntryParams) (*TimeEntry, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO time_entries (
+			workspace_id, user_id, project_id, task_id, description, started_at, ended_at,
+			duration_seconds, billable, tags
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query,
+		arg.WorkspaceID, arg.UserID, arg.ProjectID, arg.TaskID, arg.Description, arg.StartedAt, arg.EndedAt,
+		arg.DurationSeconds, arg.Billable, arg.Tags,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrTimerRunning
+		}
+		return nil, err
+	}
+	return r.GetTimeEntry(ctx, arg.WorkspaceID, id)
+}
+
+func (r *timeEntryRepository) GetTimeEntry(ctx context.Context, workspaceID, entryID uuid.UUID) (*TimeEntry, error) {
+	var entry TimeEntry
+	query := `SELECT ` + timeEntryColumns + timeEntryJoins + ` WHERE e.id = $1 AND e.workspace_id = $2 AND e.deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &entry, query, entryID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTimeEntryNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetRunningTimeEntry returns the user's running timer in any workspace.
+func (r *timeEntryRepository) GetRunningTimeEntry(ctx context.Context, userID uuid.UUID) (*TimeEntry, error) {
+	var entry TimeEntry
+	query := `SELECT ` + timeEntryColumns + timeEntryJoins + ` WHERE e.user_id = $1 AND e.ended_at IS NULL AND e.deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &entry, query, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTimeEntryNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *timeEntryRepository) ListTimeEntries(ctx context.Context, workspaceID uuid.UUID, filter TimeEntryFilter, filters FilterParams) ([]TimeEntry, int64, error) {
+	var entries []TimeEntry
+	var args []any
+	argPos := 1
+
+	where := fmt.Sprintf(` WHERE e.workspace_id = $%d AND e.deleted_at IS NULL`, argPos)
+	args = append(args, workspaceID)
+	argPos++
+
+	if filter.UserID != nil {
+		where += fmt.Sprintf(` AND e.user_id = $%d`, argPos)
+		args = append(args, *filter.UserID)
+		argPos++
+	}
+	if filter.ProjectID != nil {
+		where += fmt.Sprintf(` AND e.project_id = $%d`, argPos)
+		args = append(args, *filter.ProjectID)
+		argPos++
+	}
+	if filter.TaskID != nil {
+		where += fmt.Sprintf(` AND e.task_id = $%d`, argPos)
+		args = append(args, *filter.TaskID)
+		argPos++
+	}
+	if filter.Billable != nil {
+		where += fmt.Sprintf(` AND e.billable = $%d`, argPos)
+		args = append(args, *filter.Billable)
+		argPos++
+	}
+	if filter.Billed != nil {
+		where += fmt.Sprintf(` AND e.billed = $%d`, argPos)
+		args = append(args, *filter.Billed)
+		argPos++
+	}
+	if filter.Running != nil {
+		if *filter.Running {
+			where += ` AND e.ended_at IS NULL`
+		} else {
+			where += ` AND e.ended_at IS NOT NULL`
+		}
+	}
+	if filter.From != nil {
+		where += fmt.Sprintf(` AND e.started_at >= $%d`, argPos)
+		args = append(args, *filter.From)
+		argPos++
+	}
+	if filter.To != nil {
+		where += fmt.Sprintf(` AND e.started_at < $%d`, argPos)
+		args = append(args, *filter.To)
+		argPos++
+	}
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND e.description ILIKE $%d`, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := filters.SortBy
+	switch sortBy {
+	case "started_at", "updated_at":
+		sortBy = "e." + sortBy
+	case "duration_seconds":
+		sortBy = "e.duration_seconds"
+	default:
+		sortBy = "e.created_at"
+	}
+
+	query := `SELECT ` + timeEntryColumns + timeEntryJoins + where +
+		fmt.Sprintf(` ORDER BY %s %s NULLS LAST, e.id LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &entries, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM time_entries e`+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+func (r *timeEntryRepository) UpdateTimeEntry(ctx context.Context, arg UpdateTimeEntryParams) (*TimeEntry, error) {
+	query := `
+		UPDATE time_entries
+		SET project_id = $1, task_id = $2, description = $3, started_at = $4, ended_at = $5,
+			duration_seconds = $6, billable = $7, tags = $8, updated_at = NOW()
+		WHERE id = $9 AND workspace_id = $10 AND deleted_at IS NULL AND NOT billed
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		arg.ProjectID, arg.TaskID, arg.Description, arg.StartedAt, arg.EndedAt,
+		arg.DurationSeconds, arg.Billable, arg.Tags, arg.ID, arg.WorkspaceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, r.unchangedTimeEntry(ctx, arg.WorkspaceID, arg.ID)
+	}
+	return r.GetTimeEntry(ctx, arg.WorkspaceID, arg.ID)
+}
+
+func (r *timeEntryRepository) DeleteTimeEntry(ctx context.Context, workspaceID, entryID uuid.UUID) error {
+	query := `UPDATE time_entries SET deleted_at = NOW() WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL AND NOT billed`
+	result, err := r.db.ExecContext(ctx, query, entryID, workspaceID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return r.unchangedTimeEntry(ctx, workspaceID, entryID)
+	}
+	return nil
+}
+
+// unchangedTimeEntry explains why a write to an entry matched no row: the
+// entry was billed, possibly by an invoice created while the write waited
+// for its row lock, or it is gone.
+func (r *timeEntryRepository) unchangedTimeEntry(ctx context.Context, workspaceID, entryID uuid.UUID) error {
+	var billed bool
+	query := `SELECT billed FROM time_entries WHERE id = $1 AND workspace_id = $2 AND deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &billed, query, entryID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTimeEntryNotFound
+		}
+		return err
+	}
+	if billed {
+		return ErrTimeEntryBilled
+	}
+	return ErrTimeEntryNotFound
+}
+
+// LockUserTimeEntries serialises changes to one user's time entries until the
+// surrounding transaction ends, so overlap checks cannot race each other.
+func (r *timeEntryRepository) LockUserTimeEntries(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "time_entries:"+userID.String())
+	return err
+}
+
+// HasOverlappingTimeEntry reports whether any of the user's entries, in any
+// workspace, overlaps [start, end). A nil end and a running timer both extend
+// indefinitely.
+func (r *timeEntryRepository) HasOverlappingTimeEntry(ctx context.Context, userID uuid.UUID, start time.Time, end *time.Time, exclude *uuid.UUID) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM time_entries
+			WHERE user_id = $1 AND deleted_at IS NULL
+				AND ($4::uuid IS NULL OR id <> $4)
+				AND started_at < COALESCE($3, 'infinity'::timestamptz)
+				AND COALESCE(ended_at, 'infinity'::timestamptz) > $2
+		)
+	`
+	err := r.db.GetContext(ctx, &exists, query, userID, start, end, exclude)
+	return exists, err
+}
+
+// RecalculateTaskLoggedHours sets the task's logged hours to the total of its
+// finished time entries.
+func (r *timeEntryRepository) RecalculateTaskLoggedHours(ctx context.Context, taskID uuid.UUID) error {
+	query := `
+		UPDATE tasks
+		SET logged_hours = ROUND(COALESCE((
+			SELECT SUM(duration_seconds) FROM time_entries
+			WHERE task_id = $1 AND ended_at IS NOT NULL AND deleted_at IS NULL
+		), 0) / 3600.0, 2)
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, taskID)
+	return err
+}
+
+// ListUnbilledTimeEntries returns the finished, billable, unbilled entries
+// matching filter and locks them until the surrounding transaction ends. An
+// invoice built concurrently from the same entries waits and then no longer
+// sees them.
+func (r *timeEntryRepository) ListUnbilledTimeEntries(ctx context.Context, workspaceID uuid.UUID, filter UnbilledTimeEntryFilter) ([]TimeEntry, error) {
+	entries := []TimeEntry{}
+	query := `SELECT ` + timeEntryColumns + timeEntryJoins + `
+		WHERE e.workspace_id = $1 AND e.deleted_at IS NULL AND p.deleted_at IS NULL
+			AND e.billable AND NOT e.billed AND e.ended_at IS NOT NULL
+			AND e.started_at >= $2 AND e.started_at < $3
+			AND ($4::uuid IS NULL OR p.client_id = $4)
+			AND ($5::uuid IS NULL OR e.project_id = $5)
+			AND (NOT $6 OR e.approved_at IS NOT NULL)
+		ORDER BY e.started_at, e.id
+		FOR UPDATE OF e
+	`
+	err := r.db.SelectContext(ctx, &entries, query, workspaceID, filter.From, filter.To, filter.ClientID, filter.ProjectID, filter.ApprovedOnly)
+	return entries, err
+}
+
+// MarkTimeEntriesBilled charges the entries to the invoice. It returns how
+// many were marked; entries billed in the meantime are skipped.
+func (r *timeEntryRepository) MarkTimeEntriesBilled(ctx context.Context, invoiceID uuid.UUID, entryIDs []uuid.UUID) (int64, error) {
+	query := `
+		UPDATE time_entries
+		SET billed = TRUE, invoice_id = $1, updated_at = NOW()
+		WHERE id = ANY($2::uuid[]) AND NOT billed AND deleted_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, invoiceID, uuidStrings(entryIDs))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ReleaseInvoiceTimeEntries makes the entries charged to the invoice unbilled
+// again.
+func (r *timeEntryRepository) ReleaseInvoiceTimeEntries(ctx context.Context, invoiceID uuid.UUID) error {
+	query := `UPDATE time_entries SET billed = FALSE, invoice_id = NULL, updated_at = NOW() WHERE invoice_id = $1`
+	_, err := r.db.ExecContext(ctx, query, invoiceID)
+	return err
+}
@@ -0,0 +1,305 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTimesheetNotFound     = errors.New("timesheet not found")
+	ErrTimesheetNotEditable  = errors.New("timesheet has already been submitted")
+	ErrTimesheetNotSubmitted = errors.New("timesheet is not awaiting review")
+)
+
+// Timesheet is one user's week of time entries in a workspace. Weeks start on
+// Monday in the workspace timezone.
+type Timesheet struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	WorkspaceID     uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	WeekStart       time.Time  `json:"week_start" db:"week_start"`
+	Status          string     `json:"status" db:"status"`
+	TotalSeconds    int64      `json:"total_seconds" db:"total_seconds"`
+	BillableSeconds int64      `json:"billable_seconds" db:"billable_seconds"`
+	SubmittedAt     *time.Time `json:"submitted_at" db:"submitted_at"`
+	ReviewedBy      *uuid.UUID `json:"reviewed_by" db:"reviewed_by"`
+	ReviewedAt      *time.Time `json:"reviewed_at" db:"reviewed_at"`
+	RejectionReason *string    `json:"rejection_reason" db:"rejection_reason"`
+	ReminderSentAt  *time.Time `json:"-" db:"reminder_sent_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	UserName        *string    `json:"user_name" db:"user_name"`
+}
+
+// TimesheetTotals sums the entries of a week. Running counts timers that have
+// not been stopped yet.
+type TimesheetTotals struct {
+	TotalSeconds    int64 `db:"total_seconds"`
+	BillableSeconds int64 `db:"billable_seconds"`
+	Running         int64 `db:"running"`
+}
+
+type SubmitTimesheetParams struct {
+	WorkspaceID     uuid.UUID
+	UserID          uuid.UUID
+	WeekStart       time.Time
+	TotalSeconds    int64
+	BillableSeconds int64
+}
+
+type ReviewTimesheetParams struct {
+	ID              uuid.UUID
+	WorkspaceID     uuid.UUID
+	Status          string
+	ReviewedBy      uuid.UUID
+	RejectionReason *string
+}
+
+// TimesheetFilter narrows a timesheet listing. Empty fields are ignored.
+type TimesheetFilter struct {
+	UserID    *uuid.UUID
+	Status    string
+	WeekStart *time.Time
+}
+
+// TimesheetReminder is a finished week whose timesheet has not been submitted.
+type TimesheetReminder struct {
+	TimesheetID uuid.UUID `db:"id"`
+	WorkspaceID uuid.UUID `db:"workspace_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	WeekStart   time.Time `db:"week_start"`
+}
+
+type TimesheetRepository interface {
+	GetTimesheet(ctx context.Context, workspaceID, timesheetID uuid.UUID) (*Timesheet, error)
+	GetTimesheetForWeek(ctx context.Context, workspaceID, userID uuid.UUID, weekStart time.Time) (*Timesheet, error)
+	ListTimesheets(ctx context.Context, workspaceID uuid.UUID, filter TimesheetFilter, filters FilterParams) ([]Timesheet, int64, error)
+	SubmitTimesheet(ctx context.Context, arg SubmitTimesheetParams) (*Timesheet, error)
+	ReviewTimesheet(ctx context.Context, arg ReviewTimesheetParams) (*Timesheet, error)
+
+	SumTimesheetEntries(ctx context.Context, workspaceID, userID uuid.UUID, from, to time.Time) (*TimesheetTotals, error)
+	ApproveTimesheetEntries(ctx context.Context, workspaceID, userID uuid.UUID, from, to time.Time) error
+	ClaimTimesheetReminders(ctx context.Context, limit int) ([]TimesheetReminder, error)
+}
+
+type timesheetRepository struct {
+	db DBTX
+}
+
+func NewTimesheetRepository(db DBTX) TimesheetRepository {
+	return &timesheetRepository{db: db}
+}
+
+const timesheetColumns = `ts.*, u.name AS user_name`
+
+const timesheetJoins = `
+	FROM timesheets ts
+	LEFT JOIN users u ON u.id = ts.user_id
+`
+
+func (r *timesheetRepository) GetTimesheet(ctx context.Context, workspaceID, timesheetID uuid.UUID) (*Timesheet, error) {
+	var timesheet Timesheet
+	query := `SELECT ` + timesheetColumns + timesheetJoins + ` WHERE ts.id = $1 AND ts.workspace_id = $2`
+	err := r.db.GetContext(ctx, &timesheet, query, timesheetID, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTimesheetNotFound
+		}
+		return nil, err
+	}
+	return &timesheet, nil
+}
+
+func (r *timesheetRepository) GetTimesheetForWeek(ctx context.Context, workspaceID, userID uuid.UUID, weekStart time.Time) (*Timesheet, error) {
+	var timesheet Timesheet
+	query := `SELECT ` + timesheetColumns + timesheetJoins + ` WHERE ts.workspace_id = $1 AND ts.user_id = $2 AND ts.week_start = $3`
+	err := r.db.GetContext(ctx, &timesheet, query, workspaceID, userID, weekStart)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTimesheetNotFound
+		}
+		return nil, err
+	}
+	return &timesheet, nil
+}
+
+func (r *timesheetRepository) ListTimesheets(ctx context.Context, workspaceID uuid.UUID, filter TimesheetFilter, filters FilterParams) ([]Timesheet, int64, error) {
+	var timesheets []Timesheet
+	var args []any
+	argPos := 1
+
+	where := fmt.Sprintf(` WHERE ts.workspace_id = $%d`, argPos)
+	args = append(args, workspaceID)
+	argPos++
+
+	if filter.UserID != nil {
+		where += fmt.Sprintf(` AND ts.user_id = $%d`, argPos)
+		args = append(args, *filter.UserID)
+		argPos++
+	}
+	if filter.Status != "" {
+		where += fmt.Sprintf(` AND ts.status = $%d`, argPos)
+		args = append(args, filter.Status)
+		argPos++
+	}
+	if filter.WeekStart != nil {
+		where += fmt.Sprintf(` AND ts.week_start = $%d`, argPos)
+		args = append(args, *filter.WeekStart)
+		argPos++
+	}
+
+	if filters.HasSearch() {
+		where += fmt.Sprintf(` AND LOWER(u.name) LIKE $%d`, argPos)
+		args = append(args, filters.GetSearchPattern())
+		argPos++
+	}
+
+	sortBy := filters.SortBy
+	switch sortBy {
+	case "status", "updated_at":
+		sortBy = "ts." + sortBy
+	default:
+		sortBy = "ts.week_start"
+	}
+
+	query := `SELECT ` + timesheetColumns + timesheetJoins + where +
+		fmt.Sprintf(` ORDER BY %s %s, u.name, ts.id LIMIT $%d OFFSET $%d`, sortBy, filters.Order, argPos, argPos+1)
+
+	err := r.db.SelectContext(ctx, &timesheets, query, append(args, filters.Limit, filters.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	err = r.db.GetContext(ctx, &total, `SELECT COUNT(*)`+timesheetJoins+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return timesheets, total, nil
+}
+
+// SubmitTimesheet creates or resubmits the week's timesheet. Only open and
+// rejected timesheets can be submitted.
+func (r *timesheetRepository) SubmitTimesheet(ctx context.Context, arg SubmitTimesheetParams) (*Timesheet, error) {
+	var id uuid.UUID
+	query := `
+		INSERT INTO timesheets (workspace_id, user_id, week_start, status, total_seconds, billable_seconds, submitted_at)
+		VALUES ($1, $2, $3, 'submitted', $4, $5, NOW())
+		ON CONFLICT (workspace_id, user_id, week_start) DO UPDATE
+		SET status = 'submitted',
+			total_seconds = EXCLUDED.total_seconds,
+			billable_seconds = EXCLUDED.billable_seconds,
+			submitted_at = NOW(),
+			reviewed_by = NULL,
+			reviewed_at = NULL,
+			rejection_reason = NULL,
+			updated_at = NOW()
+		WHERE timesheets.status IN ('open', 'rejected')
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, query, arg.WorkspaceID, arg.UserID, arg.WeekStart, arg.TotalSeconds, arg.BillableSeconds)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTimesheetNotEditable
+		}
+		return nil, err
+	}
+	return r.GetTimesheet(ctx, arg.WorkspaceID, id)
+}
+
+// ReviewTimesheet approves or rejects a submitted timesheet.
+func (r *timesheetRepository) ReviewTimesheet(ctx context.Context, arg ReviewTimesheetParams) (*Timesheet, error) {
+	query := `
+		UPDATE timesheets
+		SET status = $1, reviewed_by = $2, reviewed_at = NOW(), rejection_reason = $3, updated_at = NOW()
+		WHERE id = $4 AND workspace_id = $5 AND status = 'submitted'
+	`
+	result, err := r.db.ExecContext(ctx, query, arg.Status, arg.ReviewedBy, arg.RejectionReason, arg.ID, arg.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		if _, err := r.GetTimesheet(ctx, arg.WorkspaceID, arg.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrTimesheetNotSubmitted
+	}
+	return r.GetTimesheet(ctx, arg.WorkspaceID, arg.ID)
+}
+
+// SumTimesheetEntries totals the user's entries in the workspace that started
+// in [from, to).
+func (r *timesheetRepository) SumTimesheetEntries(ctx context.Context, workspaceID, userID uuid.UUID, from, to time.Time) (*TimesheetTotals, error) {
+	var totals TimesheetTotals
+	query := `
+		SELECT
+			COALESCE(SUM(duration_seconds), 0) AS total_seconds,
+			COALESCE(SUM(duration_seconds) FILTER (WHERE billable), 0) AS billable_seconds,
+			COUNT(*) FILTER (WHERE ended_at IS NULL) AS running
+		FROM time_entries
+		WHERE workspace_id = $1 AND user_id = $2 AND deleted_at IS NULL
+			AND started_at >= $3 AND started_at < $4
+	`
+	err := r.db.GetContext(ctx, &totals, query, workspaceID, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// ApproveTimesheetEntries stamps the user's entries that started in [from, to)
+// as approved.
+func (r *timesheetRepository) ApproveTimesheetEntries(ctx context.Context, workspaceID, userID uuid.UUID, from, to time.Time) error {
+	query := `
+		UPDATE time_entries
+		SET approved_at = NOW()
+		WHERE workspace_id = $1 AND user_id = $2 AND deleted_at IS NULL
+			AND started_at >= $3 AND started_at < $4
+	`
+	_, err := r.db.ExecContext(ctx, query, workspaceID, userID, from, to)
+	return err
+}
+
+// ClaimTimesheetReminders finds recently finished weeks, in each workspace's
+// timezone, in which an active member logged time but has not submitted the
+// timesheet. Each week is claimed once by stamping reminder_sent_at, so
+// concurrent callers never remind the same member twice.
+func (r *timesheetRepository) ClaimTimesheetReminders(ctx context.Context, limit int) ([]TimesheetReminder, error) {
+	var reminders []TimesheetReminder
+	query := `
+		WITH weeks AS (
+			SELECT DISTINCT e.workspace_id, e.user_id,
+				DATE_TRUNC('week', e.started_at AT TIME ZONE COALESCE(s.settings->>'timezone', 'UTC'))::date AS week_start,
+				(NOW() AT TIME ZONE COALESCE(s.settings->>'timezone', 'UTC'))::date AS today
+			FROM time_entries e
+			JOIN workspace_members wm ON wm.workspace_id = e.workspace_id AND wm.user_id = e.user_id
+				AND wm.deleted_at IS NULL AND wm.deactivated_at IS NULL
+			LEFT JOIN workspace_settings s ON s.workspace_id = e.workspace_id
+			WHERE e.deleted_at IS NULL AND e.started_at >= NOW() - INTERVAL '15 days'
+		)
+		INSERT INTO timesheets (workspace_id, user_id, week_start, reminder_sent_at)
+		SELECT workspace_id, user_id, week_start, NOW()
+		FROM weeks w
+		WHERE w.week_start + 7 <= w.today
+			AND NOT EXISTS (
+				SELECT 1 FROM timesheets ts
+				WHERE ts.workspace_id = w.workspace_id AND ts.user_id = w.user_id AND ts.week_start = w.week_start
+					AND (ts.reminder_sent_at IS NOT NULL OR ts.status IN ('submitted', 'approved'))
+			)
+		ORDER BY w.week_start
+		LIMIT $1
+		ON CONFLICT (workspace_id, user_id, week_start) DO UPDATE
+		SET reminder_sent_at = NOW(), updated_at = NOW()
+		WHERE timesheets.reminder_sent_at IS NULL AND timesheets.status IN ('open', 'rejected')
+		RETURNING id, workspace_id, user_id, week_start
+	`
+	err := r.db.SelectContext(ctx, &reminders, query, limit)
+	return reminders, err
+}
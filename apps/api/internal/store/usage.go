@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrUsageLimitReached = errors.New("usage limit reached")
+
+type UsageMetric string
+
+const (
+	UsageMembers      UsageMetric = "members"
+	UsageStorageBytes UsageMetric = "storage_bytes"
+	UsageProjects     UsageMetric = "projects"
+	UsageAPICalls     UsageMetric = "api_calls"
+)
+
+func (m UsageMetric) column() (string, error) {
+	switch m {
+	case UsageMembers, UsageStorageBytes, UsageProjects, UsageAPICalls:
+		return string(m), nil
+	default:
+		return "", fmt.Errorf("unknown usage metric %q", m)
+	}
+}
+
+type WorkspaceUsage struct {
+	WorkspaceID  uuid.UUID `json:"workspace_id" db:"workspace_id"`
+	Members      int64     `json:"members" db:"members"`
+	StorageBytes int64     `json:"storage_bytes" db:"storage_bytes"`
+	Projects     int64     `json:"projects" db:"projects"`
+	APICalls     int64     `json:"api_calls" db:"api_calls"`
+	PeriodStart  time.Time `json:"period_start" db:"period_start"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UsageRepository interface {
+	GetWorkspaceUsage(ctx context.Context, workspaceID uuid.UUID) (*WorkspaceUsage, error)
+	LockUsage(ctx context.Context, workspaceID uuid.UUID) error
+	IncrementUsage(ctx context.Context, workspaceID uuid.UUID, metric UsageMetric, delta int64, limit *int64) error
+	ReserveAPICall(ctx context.Context, workspaceID uuid.UUID) (bool, error)
+	ReleaseAPICall(ctx context.Context, workspaceID uuid.UUID) error
+	GetStorageObjectSize(ctx context.Context, workspaceID uuid.UUID, objectName string) (int64, error)
+	UpsertStorageObject(ctx context.Context, workspaceID uuid.UUID, objectName string, size int64) error
+	DeleteStorageObject(ctx context.Context, workspaceID uuid.UUID, objectName string) (int64, error)
+}
+
+type usageRepository struct {
+	db DBTX
+}
+
+func NewUsageRepository(db DBTX) UsageRepository {
+	return &usageRepository{db: db}
+}
+
+func (r *usageRepository) ensureRow(ctx context.Context, workspaceID uuid.UUID) error {
+	query := `INSERT INTO workspace_usage (workspace_id) VALUES ($1) ON CONFLICT (workspace_id) DO NOTHING`
+	_, err := r.db.ExecContext(ctx, query, workspaceID)
+	return err
+}
+
+func (r *usageRepository) GetWorkspaceUsage(ctx context.Context, workspaceID uuid.UUID) (*WorkspaceUsage, error) {
+	if err := r.ensureRow(ctx, workspaceID); err != nil {
+		return nil, err
+	}
+
+	var usage WorkspaceUsage
+	query := `
+		SELECT workspace_id, members, storage_bytes, projects,
+			CASE WHEN period_start < date_trunc('month', NOW())::date THEN 0 ELSE api_calls END AS api_calls,
+			GREATEST(period_start, date_trunc('month', NOW())::date) AS period_start,
+			updated_at
+		FROM workspace_usage
+		WHERE workspace_id = $1
+	`
+	err := r.db.GetContext(ctx, &usage, query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
+
+// LockUsage locks the workspace's usage counters until the surrounding
+// transaction ends. Every change to a counter takes the same lock, so callers
+// that must check state before reserving quota take it first.
+func (r *usageRepository) LockUsage(ctx context.Context, workspaceID uuid.UUID) error {
+	if err := r.ensureRow(ctx, workspaceID); err != nil {
+		return err
+	}
+	query := `SELECT 1 FROM workspace_usage WHERE workspace_id = $1 FOR UPDATE`
+	_, err := r.db.ExecContext(ctx, query, workspaceID)
+	return err
+}
+
+// IncrementUsage atomically adds delta to the metric. Positive deltas are
+// rejected with ErrUsageLimitReached when they would push the counter past
+// limit; decrements always succeed and never drop below zero.
+func (r *usageRepository) IncrementUsage(ctx context.Context, workspaceID uuid.UUID, metric UsageMetric, delta int64, limit *int64) error {
+	column, err := metric.column()
+	if err != nil {
+		return err
+	}
+
+	if err := r.ensureRow(ctx, workspaceID); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE workspace_usage
+		SET %[1]s = GREATEST(%[1]s + $2, 0), updated_at = NOW()
+		WHERE workspace_id = $1 AND ($2 <= 0 OR $3::BIGINT IS NULL OR %[1]s + $2 <= $3::BIGINT)
+	`, column)
+	result, err := r.db.ExecContext(ctx, query, workspaceID, delta, limit)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUsageLimitReached
+	}
+	return nil
+}
+
+// ReserveAPICall counts a request against the current calendar month,
+// resetting the counter when a new month starts. It reports false, without
+// counting, once the month's calls reach the limit of the workspace plan.
+// Requests for a workspace that does not exist are not counted.
+func (r *usageRepository) ReserveAPICall(ctx context.Context, workspaceID uuid.UUID) (bool, error) {
+	var limit *int64
+	query := `SELECT p.max_api_calls FROM workspaces w JOIN plans p ON p.id = w.plan_id WHERE w.id = $1 AND w.deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &limit, query, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return true, nil
+		}
+		return false, err
+	}
+	if limit != nil && *limit <= 0 {
+		return false, nil
+	}
+
+	query = `
+		INSERT INTO workspace_usage (workspace_id, api_calls)
+		VALUES ($1, 1)
+		ON CONFLICT (workspace_id) DO UPDATE
+		SET api_calls = CASE
+				WHEN workspace_usage.period_start < date_trunc('month', NOW())::date THEN 1
+				ELSE workspace_usage.api_calls + 1
+			END,
+			period_start = date_trunc('month', NOW())::date,
+			updated_at = NOW()
+		WHERE $2::BIGINT IS NULL
+			OR workspace_usage.period_start < date_trunc('month', NOW())::date
+			OR workspace_usage.api_calls < $2::BIGINT
+	`
+	result, err := r.db.ExecContext(ctx, query, workspaceID, limit)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ReleaseAPICall gives back a call reserved in the current month.
+func (r *usageRepository) ReleaseAPICall(ctx context.Context, workspaceID uuid.UUID) error {
+	query := `
+		UPDATE workspace_usage
+		SET api_calls = GREATEST(api_calls - 1, 0), updated_at = NOW()
+		WHERE workspace_id = $1 AND period_start = date_trunc('month', NOW())::date
+	`
+	_, err := r.db.ExecContext(ctx, query, workspaceID)
+	return err
+}
+
+func (r *usageRepository) GetStorageObjectSize(ctx context.Context, workspaceID uuid.UUID, objectName string) (int64, error) {
+	var size int64
+	query := `SELECT size_bytes FROM workspace_storage_objects WHERE workspace_id = $1 AND object_name = $2 FOR UPDATE`
+	err := r.db.GetContext(ctx, &size, query, workspaceID, objectName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return size, nil
+}
+
+func (r *usageRepository) UpsertStorageObject(ctx context.Context, workspaceID uuid.UUID, objectName string, size int64) error {
+	query := `
+		INSERT INTO workspace_storage_objects (workspace_id, object_name, size_bytes)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, object_name)
+		DO UPDATE SET size_bytes = EXCLUDED.size_bytes, updated_at = NOW()
+	`
+	_, err := r.db.ExecContext(ctx, query, workspaceID, objectName, size)
+	return err
+}
+
+// DeleteStorageObject forgets an object and returns the size it accounted for.
+func (r *usageRepository) DeleteStorageObject(ctx context.Context, workspaceID uuid.UUID, objectName string) (int64, error) {
+	var size int64
+	query := `DELETE FROM workspace_storage_objects WHERE workspace_id = $1 AND object_name = $2 RETURNING size_bytes`
+	err := r.db.GetContext(ctx, &size, query, workspaceID, objectName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return size, nil
+}
@@ -12,14 +12,15 @@ import (
 var ErrUserNotFound = errors.New("user not found")
 
 type User struct {
-	ID           uuid.UUID  `json:"id" db:"id"`
-	Email        *string    `json:"email" db:"email"`
-	PasswordHash string     `json:"-" db:"password_hash"`
-	Name         string     `json:"name" db:"name"`
-	AvatarURL    *string    `json:"avatar_url" db:"avatar_url"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt    *time.Time `json:"-" db:"deleted_at"`
+	ID              uuid.UUID  `json:"id" db:"id"`
+	Email           *string    `json:"email" db:"email"`
+	PasswordHash    string     `json:"-" db:"password_hash"`
+	Name            string     `json:"name" db:"name"`
+	AvatarURL       *string    `json:"avatar_url" db:"avatar_url"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time `json:"-" db:"deleted_at"`
 }
 
 type CreateUserParams struct {
@@ -58,7 +59,7 @@ func (r *userRepository) CreateUser(ctx context.Context, arg CreateUserParams) (
 	query := `
 		INSERT INTO users (email, password_hash, name, avatar_url)
 		VALUES ($1, $2, $3, $4)
-		RETURNING id, email, password_hash, name, avatar_url, created_at, updated_at, deleted_at
+		RETURNING id, email, password_hash, name, avatar_url, email_verified_at, created_at, updated_at, deleted_at
 	`
 	err := r.db.GetContext(ctx, user, query, arg.Email, arg.PasswordHash, arg.Name, arg.AvatarURL)
 	if err != nil {
@@ -97,9 +98,10 @@ func (r *userRepository) UpdateUser(ctx context.Context, arg UpdateUserParams) (
 	var user User
 	query := `
 		UPDATE users 
-		SET email = $1, name = $2, avatar_url = $3, password_hash = $4, updated_at = NOW()
+		SET email = $1, name = $2, avatar_url = $3, password_hash = $4, updated_at = NOW(),
+			email_verified_at = CASE WHEN email IS DISTINCT FROM $1 THEN NULL ELSE email_verified_at END
 		WHERE id = $5 AND deleted_at IS NULL
-		RETURNING id, email, password_hash, name, avatar_url, created_at, updated_at, deleted_at
+		RETURNING id, email, password_hash, name, avatar_url, email_verified_at, created_at, updated_at, deleted_at
 	`
 	err := r.db.GetContext(ctx, &user, query, arg.Email, arg.Name, arg.AvatarURL, arg.PasswordHash, arg.ID)
 	if err != nil {
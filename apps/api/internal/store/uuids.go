@@ -0,0 +1,46 @@
+package store
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// UUIDs is a list of IDs stored as a JSONB array.
+type UUIDs []uuid.UUID
+
+func (u *UUIDs) Scan(src any) error {
+	*u = UUIDs{}
+
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported uuids type %T", src)
+	}
+
+	return json.Unmarshal(data, u)
+}
+
+func (u UUIDs) Value() (driver.Value, error) {
+	if u == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(u)
+}
+
+// uuidStrings converts ids for use as a text[] query argument.
+func uuidStrings(ids []uuid.UUID) []string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return values
+}
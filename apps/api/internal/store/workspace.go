@@ -20,20 +20,24 @@ type Workspace struct {
 	ID        uuid.UUID  `json:"id" db:"id"`
 	Name      string     `json:"name" db:"name"`
 	AvatarURL *string    `json:"avatar_url" db:"avatar_url"`
+	PlanID    string     `json:"plan_id" db:"plan_id"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
 	DeletedAt *time.Time `json:"-" db:"deleted_at"`
 }
 
 type WorkspaceMember struct {
-	WorkspaceID uuid.UUID  `json:"workspace_id" db:"workspace_id"`
-	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
-	Role        string     `json:"role" db:"role"`
-	JoinedAt    time.Time  `json:"joined_at" db:"joined_at"`
-	Name        string     `json:"name" db:"name"`
-	Email       string     `json:"email" db:"email"`
-	AvatarURL   *string    `json:"avatar_url" db:"avatar_url"`
-	DeletedAt   *time.Time `json:"-" db:"deleted_at"`
+	WorkspaceID   uuid.UUID  `json:"workspace_id" db:"workspace_id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	Role          string     `json:"role" db:"role"`
+	JoinedAt      time.Time  `json:"joined_at" db:"joined_at"`
+	Name          string     `json:"name" db:"name"`
+	Email         string     `json:"email" db:"email"`
+	AvatarURL     *string    `json:"avatar_url" db:"avatar_url"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
+	DeactivatedBy *uuid.UUID `json:"deactivated_by,omitempty" db:"deactivated_by"`
+	HourlyRate    *int64     `json:"hourly_rate" db:"hourly_rate"`
+	DeletedAt     *time.Time `json:"-" db:"deleted_at"`
 }
 
 type WorkspaceWithRole struct {
@@ -56,6 +60,13 @@ type WorkspaceRepository interface {
 	RemoveWorkspaceMember(ctx context.Context, workspaceID, userID uuid.UUID) error
 	GetUserWorkspaces(ctx context.Context, userID uuid.UUID, filters FilterParams) ([]WorkspaceWithRole, int64, error)
 	GetWorkspaceMemberRole(ctx context.Context, workspaceID, userID uuid.UUID) (string, error)
+	GetWorkspaceMember(ctx context.Context, workspaceID, userID uuid.UUID) (*WorkspaceMember, error)
+	LockWorkspaceMember(ctx context.Context, workspaceID, userID uuid.UUID) (*WorkspaceMember, error)
+	ListMembersByEmailHandle(ctx context.Context, workspaceID uuid.UUID, handles []string) ([]WorkspaceMember, error)
+	DeactivateWorkspaceMember(ctx context.Context, workspaceID, userID, deactivatedBy uuid.UUID) error
+	ReactivateWorkspaceMember(ctx context.Context, workspaceID, userID uuid.UUID) error
+	SetWorkspaceMemberRate(ctx context.Context, workspaceID, userID uuid.UUID, hourlyRate *int64) error
+	ReassignMemberContent(ctx context.Context, workspaceID, fromUserID, toUserID uuid.UUID) (map[string]int64, error)
 	UpdateWorkspaceAvatar(ctx context.Context, id uuid.UUID, avatarURL string) (*Workspace, error)
 	CountUserWorkspaces(ctx context.Context, userID uuid.UUID) (int64, error)
 }
@@ -73,7 +84,7 @@ func (r *workspaceRepository) CreateWorkspace(ctx context.Context, arg CreateWor
 	query := `
 		INSERT INTO workspaces (name, avatar_url)
 		VALUES ($1, $2)
-		RETURNING id, name, avatar_url, created_at, updated_at, deleted_at
+		RETURNING id, name, avatar_url, plan_id, created_at, updated_at, deleted_at
 	`
 	err := r.db.GetContext(ctx, workspace, query, arg.Name, arg.AvatarURL)
 	if err != nil {
@@ -101,7 +112,7 @@ func (r *workspaceRepository) UpdateWorkspace(ctx context.Context, id uuid.UUID,
 		UPDATE workspaces
 		SET name = $1, updated_at = NOW()
 		WHERE id = $2 AND deleted_at IS NULL
-		RETURNING id, name, avatar_url, created_at, updated_at, deleted_at
+		RETURNING id, name, avatar_url, plan_id, created_at, updated_at, deleted_at
 	`
 	err := r.db.GetContext(ctx, &workspace, query, name, id)
 	if err != nil {
@@ -119,7 +130,7 @@ func (r *workspaceRepository) UpdateWorkspaceAvatar(ctx context.Context, id uuid
 		UPDATE workspaces
 		SET avatar_url = $1, updated_at = NOW()
 		WHERE id = $2 AND deleted_at IS NULL
-		RETURNING id, name, avatar_url, created_at, updated_at, deleted_at
+		RETURNING id, name, avatar_url, plan_id, created_at, updated_at, deleted_at
 	`
 	err := r.db.GetContext(ctx, &workspace, query, avatarURL, id)
 	if err != nil {
@@ -149,7 +160,7 @@ func (r *workspaceRepository) AddWorkspaceMember(ctx context.Context, workspaceI
 		INSERT INTO workspace_members (workspace_id, user_id, role)
 		VALUES ($1, $2, $3)
 		ON CONFLICT (workspace_id, user_id)
-		DO UPDATE SET role = $3, deleted_at = NULL
+		DO UPDATE SET role = $3, deleted_at = NULL, deactivated_at = NULL, deactivated_by = NULL
 	`
 	_, err := r.db.ExecContext(ctx, query, workspaceID, userID, role)
 	return err
@@ -226,7 +237,15 @@ func (r *workspaceRepository) RemoveWorkspaceMember(ctx context.Context, workspa
 	if rows == 0 {
 		return ErrNotMember
 	}
-	return nil
+
+	// Team memberships only make sense while the user belongs to the workspace.
+	query = `
+		DELETE FROM team_members tm
+		USING teams t
+		WHERE tm.team_id = t.id AND t.workspace_id = $1 AND tm.user_id = $2
+	`
+	_, err = r.db.ExecContext(ctx, query, workspaceID, userID)
+	return err
 }
 
 func (r *workspaceRepository) GetUserWorkspaces(ctx context.Context, userID uuid.UUID, filters FilterParams) ([]WorkspaceWithRole, int64, error) {
@@ -238,7 +257,7 @@ func (r *workspaceRepository) GetUserWorkspaces(ctx context.Context, userID uuid
 		SELECT w.*, wm.role
 		FROM workspaces w
 		JOIN workspace_members wm ON w.id = wm.workspace_id
-		WHERE wm.user_id = $` + fmt.Sprintf("%d", argPos) + ` AND w.deleted_at IS NULL AND wm.deleted_at IS NULL`
+		WHERE wm.user_id = $` + fmt.Sprintf("%d", argPos) + ` AND w.deleted_at IS NULL AND wm.deleted_at IS NULL AND wm.deactivated_at IS NULL`
 	args = append(args, userID)
 	argPos++
 
@@ -271,7 +290,7 @@ func (r *workspaceRepository) GetUserWorkspaces(ctx context.Context, userID uuid
 
 	var totalArgs []any
 	totalArgPos := 1
-	countQuery := `SELECT COUNT(*) FROM workspace_members wm JOIN workspaces w ON w.id = wm.workspace_id WHERE wm.user_id = $` + fmt.Sprintf("%d", totalArgPos) + ` AND w.deleted_at IS NULL AND wm.deleted_at IS NULL`
+	countQuery := `SELECT COUNT(*) FROM workspace_members wm JOIN workspaces w ON w.id = wm.workspace_id WHERE wm.user_id = $` + fmt.Sprintf("%d", totalArgPos) + ` AND w.deleted_at IS NULL AND wm.deleted_at IS NULL AND wm.deactivated_at IS NULL`
 	totalArgs = append(totalArgs, userID)
 	totalArgPos++
 
@@ -298,7 +317,7 @@ func (r *workspaceRepository) CountUserWorkspaces(ctx context.Context, userID uu
 
 func (r *workspaceRepository) GetWorkspaceMemberRole(ctx context.Context, workspaceID, userID uuid.UUID) (string, error) {
 	var role string
-	query := `SELECT role FROM workspace_members WHERE workspace_id = $1 AND user_id = $2 AND deleted_at IS NULL`
+	query := `SELECT role FROM workspace_members WHERE workspace_id = $1 AND user_id = $2 AND deleted_at IS NULL AND deactivated_at IS NULL`
 	err := r.db.GetContext(ctx, &role, query, workspaceID, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
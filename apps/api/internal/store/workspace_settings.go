@@ -0,0 +1,179 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWorkspaceSettingsNotFound = errors.New("workspace settings not found")
+	ErrSettingsVersionConflict   = errors.New("workspace settings were modified concurrently")
+)
+
+// SettingsSchemaVersion is bumped whenever the shape of SettingsDocument changes
+// so older documents can be upgraded when they are read back.
+const SettingsSchemaVersion = 7
+
+// SettingsDocument holds the workspace-level defaults used by invoicing,
+// reporting and time tracking. It is stored as a single JSONB document.
+type SettingsDocument struct {
+	SchemaVersion        int     `json:"schema_version"`
+	DefaultCurrency      string  `json:"default_currency"`
+	Timezone             string  `json:"timezone"`
+	Locale               string  `json:"locale"`
+	DateFormat           string  `json:"date_format"`
+	FiscalYearStartMonth int     `json:"fiscal_year_start_month"`
+	InvoicePrefix        string  `json:"invoice_prefix"`
+	BrandColor           string  `json:"brand_color"`
+	LogoURL              *string `json:"logo_url"`
+	DefaultJoinRole      string  `json:"default_join_role"`
+	WorkingHoursPerDay   float64 `json:"working_hours_per_day"`
+	TimeRoundingMinutes  int     `json:"time_rounding_minutes"`
+	TimeRoundingMode     string  `json:"time_rounding_mode"`
+	// TimesheetApproverIDs lists members who may review timesheets in
+	// addition to the workspace owner and admins.
+	TimesheetApproverIDs []uuid.UUID `json:"timesheet_approver_ids"`
+	// DefaultHourlyRate bills time on projects without their own rate, in
+	// minor units of the default currency.
+	DefaultHourlyRate int64 `json:"default_hourly_rate"`
+	// InvoiceReminderDays schedules payment reminders relative to the due
+	// date: -3 is three days before and 0 the due date itself.
+	InvoiceReminderDays []int `json:"invoice_reminder_days"`
+	// InvoiceReminderRepeatDays repeats the reminder every so many days after
+	// the due date while the invoice is unpaid; 0 turns repeats off.
+	InvoiceReminderRepeatDays int `json:"invoice_reminder_repeat_days"`
+	// BudgetAlertThresholds are the percentages of a project budget at which
+	// the project owner is warned; an empty list turns the warnings off.
+	BudgetAlertThresholds []int `json:"budget_alert_thresholds"`
+}
+
+func DefaultSettingsDocument() SettingsDocument {
+	return SettingsDocument{
+		SchemaVersion:             SettingsSchemaVersion,
+		DefaultCurrency:           "USD",
+		Timezone:                  "UTC",
+		Locale:                    "en-US",
+		DateFormat:                "YYYY-MM-DD",
+		FiscalYearStartMonth:      1,
+		InvoicePrefix:             "INV-",
+		BrandColor:                "#2563eb",
+		DefaultJoinRole:           "member",
+		WorkingHoursPerDay:        8,
+		TimeRoundingMinutes:       0,
+		TimeRoundingMode:          "nearest",
+		TimesheetApproverIDs:      []uuid.UUID{},
+		DefaultHourlyRate:         0,
+		InvoiceReminderDays:       []int{-3, 0},
+		InvoiceReminderRepeatDays: 7,
+		BudgetAlertThresholds:     []int{50, 80, 100},
+	}
+}
+
+// Scan starts from the defaults so keys missing from older documents are filled in.
+func (d *SettingsDocument) Scan(src any) error {
+	*d = DefaultSettingsDocument()
+
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported settings document type %T", src)
+	}
+
+	if err := json.Unmarshal(data, d); err != nil {
+		return err
+	}
+	d.SchemaVersion = SettingsSchemaVersion
+	return nil
+}
+
+func (d SettingsDocument) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+type WorkspaceSettings struct {
+	WorkspaceID uuid.UUID        `json:"workspace_id" db:"workspace_id"`
+	Version     int              `json:"version" db:"version"`
+	Settings    SettingsDocument `json:"settings" db:"settings"`
+	UpdatedBy   *uuid.UUID       `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+type UpsertWorkspaceSettingsParams struct {
+	WorkspaceID     uuid.UUID
+	Settings        SettingsDocument
+	ExpectedVersion int
+	UpdatedBy       uuid.UUID
+}
+
+type WorkspaceSettingsRepository interface {
+	GetWorkspaceSettings(ctx context.Context, workspaceID uuid.UUID) (*WorkspaceSettings, error)
+	UpsertWorkspaceSettings(ctx context.Context, arg UpsertWorkspaceSettingsParams) (*WorkspaceSettings, error)
+}
+
+type workspaceSettingsRepository struct {
+	db DBTX
+}
+
+func NewWorkspaceSettingsRepository(db DBTX) WorkspaceSettingsRepository {
+	return &workspaceSettingsRepository{db: db}
+}
+
+func (r *workspaceSettingsRepository) GetWorkspaceSettings(ctx context.Context, workspaceID uuid.UUID) (*WorkspaceSettings, error) {
+	var settings WorkspaceSettings
+	query := `SELECT * FROM workspace_settings WHERE workspace_id = $1`
+	err := r.db.GetContext(ctx, &settings, query, workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkspaceSettingsNotFound
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpsertWorkspaceSettings writes the document only if the stored version still
+// matches ExpectedVersion, so concurrent edits cannot silently overwrite each other.
+// Version 0 stands for a workspace that has never saved settings: the first
+// save inserts the row and loses to any save that inserted it first.
+func (r *workspaceSettingsRepository) UpsertWorkspaceSettings(ctx context.Context, arg UpsertWorkspaceSettingsParams) (*WorkspaceSettings, error) {
+	var settings WorkspaceSettings
+	var err error
+	if arg.ExpectedVersion == 0 {
+		query := `
+			INSERT INTO workspace_settings (workspace_id, version, settings, updated_by)
+			VALUES ($1, 1, $2, $3)
+			ON CONFLICT (workspace_id) DO NOTHING
+			RETURNING *
+		`
+		err = r.db.GetContext(ctx, &settings, query, arg.WorkspaceID, arg.Settings, arg.UpdatedBy)
+	} else {
+		query := `
+			UPDATE workspace_settings
+			SET settings = $2, version = version + 1, updated_by = $3, updated_at = NOW()
+			WHERE workspace_id = $1 AND version = $4
+			RETURNING *
+		`
+		err = r.db.GetContext(ctx, &settings, query, arg.WorkspaceID, arg.Settings, arg.UpdatedBy, arg.ExpectedVersion)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSettingsVersionConflict
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
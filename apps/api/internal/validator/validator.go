@@ -3,22 +3,75 @@ package validator
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
+	"regexp"
 	"strings"
+	_ "time/tzdata"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/lukabrkovic/artemis/pkg/money"
 )
 
 var Validate = validator.New()
 
 func init() {
 	Validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("json"), ",", 1)[0]
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
 		if name == "-" {
 			return ""
 		}
 		return name
 	})
+
+	Validate.RegisterValidation("date_format", validateDateFormat)
+	Validate.RegisterValidation("invoice_prefix", validateInvoicePrefix)
+	Validate.RegisterValidation("percent", validatePercent)
+	Validate.RegisterValidation("line_amount", validateLineAmount)
+	// An empty string is how a client clears an optional URL.
+	Validate.RegisterAlias("https_url_or_empty", "eq=|https_url")
+}
+
+// MaxLineAmount bounds unit_price × quantity of an invoice line, in minor
+// units. Even 200 such lines with 100% tax stay far from overflowing the
+// invoice totals.
+const MaxLineAmount = 10_000_000_000_000
+
+// DateFormats lists the display formats a workspace may choose from.
+var DateFormats = []string{"YYYY-MM-DD", "DD/MM/YYYY", "MM/DD/YYYY", "DD.MM.YYYY", "DD-MM-YYYY"}
+
+var invoicePrefixPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_/-]{0,19}$`)
+
+func validateDateFormat(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	for _, format := range DateFormats {
+		if value == format {
+			return true
+		}
+	}
+	return false
+}
+
+func validateInvoicePrefix(fl validator.FieldLevel) bool {
+	return invoicePrefixPattern.MatchString(fl.Field().String())
+}
+
+// validatePercent accepts a money.Decimal between 0 and 100.
+func validatePercent(fl validator.FieldLevel) bool {
+	value := fl.Field().Int()
+	return value >= 0 && value <= int64(money.NewDecimal(100))
+}
+
+// validateLineAmount accepts a unit price whose product with the Quantity
+// field next to it is at most MaxLineAmount.
+func validateLineAmount(fl validator.FieldLevel) bool {
+	quantity := fl.Parent().FieldByName("Quantity")
+	if !quantity.IsValid() {
+		return false
+	}
+	amount := new(big.Int).Mul(big.NewInt(fl.Field().Int()), big.NewInt(quantity.Int()))
+	limit := new(big.Int).Mul(big.NewInt(MaxLineAmount), big.NewInt(int64(money.NewDecimal(1))))
+	return amount.CmpAbs(limit) <= 0
 }
 
 type ValidationError struct {
@@ -104,6 +157,29 @@ func getErrorMessage(e validator.FieldError) string {
 		return "must contain only ASCII characters"
 	case "printascii":
 		return "must contain only printable ASCII characters"
+	case "iso4217":
+		return "must be a valid ISO 4217 currency code"
+	case "timezone":
+		return "must be a valid IANA time zone"
+	case "bcp47_language_tag":
+		return "must be a valid BCP 47 locale (e.g. en-US)"
+	case "hexcolor":
+		return "must be a hex colour (e.g. #2563eb)"
+	case "datetime":
+		if e.Param() == "2006-01-02" {
+			return "must be a date formatted as YYYY-MM-DD"
+		}
+		return fmt.Sprintf("must match the layout %s", e.Param())
+	case "date_format":
+		return fmt.Sprintf("must be one of: %s", strings.Join(DateFormats, ", "))
+	case "https_url_or_empty":
+		return "must be an https URL, or empty to clear it"
+	case "percent":
+		return "must be a percentage between 0 and 100"
+	case "line_amount":
+		return fmt.Sprintf("unit_price × quantity must be at most %d", MaxLineAmount)
+	case "invoice_prefix":
+		return "must start with a letter or digit and contain only letters, digits, '-', '_' or '/' (max 20)"
 	default:
 		return fmt.Sprintf("failed validation: %s", e.Tag())
 	}
@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE plans (
+    id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    max_members BIGINT,
+    max_storage_bytes BIGINT,
+    max_projects BIGINT,
+    max_api_calls BIGINT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- NULL limits mean unlimited.
+INSERT INTO plans (id, name, max_members, max_storage_bytes, max_projects, max_api_calls) VALUES
+    ('free', 'Free', 5, 1073741824, 3, 10000),
+    ('pro', 'Pro', 50, 53687091200, 100, 1000000),
+    ('business', 'Business', NULL, 536870912000, NULL, NULL);
+
+ALTER TABLE workspaces ADD COLUMN plan_id VARCHAR(50) NOT NULL DEFAULT 'free' REFERENCES plans(id);
+
+CREATE TABLE workspace_usage (
+    workspace_id UUID PRIMARY KEY REFERENCES workspaces(id) ON DELETE CASCADE,
+    members BIGINT NOT NULL DEFAULT 0,
+    storage_bytes BIGINT NOT NULL DEFAULT 0,
+    projects BIGINT NOT NULL DEFAULT 0,
+    api_calls BIGINT NOT NULL DEFAULT 0,
+    period_start DATE NOT NULL DEFAULT date_trunc('month', NOW())::date,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+INSERT INTO workspace_usage (workspace_id, members)
+SELECT w.id, COUNT(wm.user_id) FILTER (WHERE wm.deleted_at IS NULL)
+FROM workspaces w
+LEFT JOIN workspace_members wm ON wm.workspace_id = w.id
+GROUP BY w.id;
+
+CREATE TABLE workspace_storage_objects (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    object_name TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, object_name)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS workspace_storage_objects;
+DROP TABLE IF EXISTS workspace_usage;
+ALTER TABLE workspaces DROP COLUMN IF EXISTS plan_id;
+DROP TABLE IF EXISTS plans;
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+CREATE TABLE workspace_invite_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    role workspace_role NOT NULL DEFAULT 'member',
+    max_uses INTEGER,
+    use_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT chk_invite_links_role CHECK (role <> 'owner'),
+    CONSTRAINT chk_invite_links_max_uses CHECK (max_uses IS NULL OR max_uses > 0)
+);
+
+CREATE INDEX idx_invite_links_workspace_id ON workspace_invite_links(workspace_id);
+
+CREATE TABLE workspace_join_domains (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    domain VARCHAR(255) NOT NULL,
+    role workspace_role NOT NULL DEFAULT 'member',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, domain),
+    CONSTRAINT chk_join_domains_role CHECK (role <> 'owner')
+);
+
+CREATE INDEX idx_join_domains_domain ON workspace_join_domains(domain);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS workspace_join_domains;
+DROP TABLE IF EXISTS workspace_invite_links;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
+-- +goose StatementEnd
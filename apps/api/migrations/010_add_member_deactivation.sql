@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE workspace_members ADD COLUMN deactivated_at TIMESTAMPTZ;
+ALTER TABLE workspace_members ADD COLUMN deactivated_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_workspace_members_deactivated_at ON workspace_members(deactivated_at) WHERE deactivated_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_workspace_members_deactivated_at;
+
+ALTER TABLE workspace_members DROP COLUMN IF EXISTS deactivated_by;
+ALTER TABLE workspace_members DROP COLUMN IF EXISTS deactivated_at;
+-- +goose StatementEnd
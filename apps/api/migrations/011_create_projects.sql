@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE project_status AS ENUM ('planning', 'in-progress', 'review', 'completed', 'on-hold');
+CREATE TYPE priority_level AS ENUM ('low', 'medium', 'high');
+
+CREATE TABLE projects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    status project_status NOT NULL DEFAULT 'planning',
+    priority priority_level NOT NULL DEFAULT 'medium',
+    start_date DATE,
+    due_date DATE,
+    -- Amounts are stored in minor units of the currency.
+    budget BIGINT CHECK (budget IS NULL OR budget >= 0),
+    currency CHAR(3) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '#2563eb',
+    tags JSONB NOT NULL DEFAULT '[]',
+    team_id UUID REFERENCES teams(id) ON DELETE SET NULL,
+    owner_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT chk_projects_dates CHECK (start_date IS NULL OR due_date IS NULL OR due_date >= start_date)
+);
+
+CREATE INDEX idx_projects_workspace_id ON projects(workspace_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_projects_owner_id ON projects(owner_id);
+CREATE INDEX idx_projects_team_id ON projects(team_id);
+CREATE INDEX idx_projects_tags ON projects USING GIN (tags);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS projects;
+DROP TYPE IF EXISTS priority_level;
+DROP TYPE IF EXISTS project_status;
+-- +goose StatementEnd
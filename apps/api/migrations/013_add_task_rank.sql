@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN rank TEXT COLLATE "C";
+
+-- Existing tasks keep their creation order within each board column.
+UPDATE tasks t
+SET rank = ranked.rank
+FROM (
+    SELECT id, LPAD(TO_HEX(ROW_NUMBER() OVER (PARTITION BY project_id, status ORDER BY created_at, id)), 8, '0') || 'V' AS rank
+    FROM tasks
+    WHERE deleted_at IS NULL
+) ranked
+WHERE t.id = ranked.id;
+
+-- Deleted tasks have no rank. The constraint is deferred so a column can be
+-- rewritten with a single statement while rebalancing.
+ALTER TABLE tasks ADD CONSTRAINT tasks_column_rank_key UNIQUE (project_id, status, rank) DEFERRABLE INITIALLY DEFERRED;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_column_rank_key;
+ALTER TABLE tasks DROP COLUMN IF EXISTS rank;
+-- +goose StatementEnd
@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE task_recurrences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    priority task_priority NOT NULL DEFAULT 'medium',
+    assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    estimated_hours NUMERIC(8, 2) CHECK (estimated_hours IS NULL OR estimated_hours >= 0),
+    tags JSONB NOT NULL DEFAULT '[]',
+    rrule TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    -- The next occurrence to create; NULL once the rule is exhausted.
+    next_date DATE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_task_recurrences_project_id ON task_recurrences(project_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_task_recurrences_next_date ON task_recurrences(next_date) WHERE deleted_at IS NULL AND next_date IS NOT NULL;
+
+-- Overrides for a single future occurrence, keyed by its scheduled date.
+CREATE TABLE task_recurrence_exceptions (
+    recurrence_id UUID NOT NULL REFERENCES task_recurrences(id) ON DELETE CASCADE,
+    occurrence_date DATE NOT NULL,
+    skipped BOOLEAN NOT NULL DEFAULT FALSE,
+    title VARCHAR(255),
+    description TEXT,
+    priority task_priority,
+    assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    estimated_hours NUMERIC(8, 2) CHECK (estimated_hours IS NULL OR estimated_hours >= 0),
+    due_date DATE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (recurrence_id, occurrence_date)
+);
+
+-- An occurrence is created at most once, even when several schedulers race.
+ALTER TABLE tasks
+    ADD COLUMN recurrence_id UUID REFERENCES task_recurrences(id) ON DELETE SET NULL,
+    ADD COLUMN occurrence_date DATE;
+
+CREATE UNIQUE INDEX tasks_recurrence_occurrence_key ON tasks(recurrence_id, occurrence_date) WHERE recurrence_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS tasks_recurrence_occurrence_key;
+ALTER TABLE tasks DROP COLUMN IF EXISTS occurrence_date;
+ALTER TABLE tasks DROP COLUMN IF EXISTS recurrence_id;
+DROP TABLE IF EXISTS task_recurrence_exceptions;
+DROP TABLE IF EXISTS task_recurrences;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE time_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    task_id UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    description TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    -- NULL while the timer is running.
+    ended_at TIMESTAMPTZ,
+    -- The rounded duration that is reported and billed.
+    duration_seconds INTEGER NOT NULL DEFAULT 0 CHECK (duration_seconds >= 0),
+    billable BOOLEAN NOT NULL DEFAULT TRUE,
+    billed BOOLEAN NOT NULL DEFAULT FALSE,
+    tags JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+-- A user has at most one running timer across all workspaces.
+CREATE UNIQUE INDEX time_entries_running_timer_key ON time_entries(user_id) WHERE ended_at IS NULL AND deleted_at IS NULL;
+
+CREATE INDEX idx_time_entries_workspace_started_at ON time_entries(workspace_id, started_at) WHERE deleted_at IS NULL;
+CREATE INDEX idx_time_entries_user_started_at ON time_entries(user_id, started_at) WHERE deleted_at IS NULL;
+CREATE INDEX idx_time_entries_project_id ON time_entries(project_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_time_entries_task_id ON time_entries(task_id) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS time_entries;
+-- +goose StatementEnd
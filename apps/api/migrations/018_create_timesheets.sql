@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE timesheet_status AS ENUM ('open', 'submitted', 'approved', 'rejected');
+
+CREATE TABLE timesheets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- The Monday of the week, in the workspace timezone.
+    week_start DATE NOT NULL CHECK (EXTRACT(ISODOW FROM week_start) = 1),
+    status timesheet_status NOT NULL DEFAULT 'open',
+    -- Totals of the week's entries, captured on submission.
+    total_seconds BIGINT NOT NULL DEFAULT 0,
+    billable_seconds BIGINT NOT NULL DEFAULT 0,
+    submitted_at TIMESTAMPTZ,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    rejection_reason TEXT,
+    reminder_sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (workspace_id, user_id, week_start)
+);
+
+CREATE INDEX idx_timesheets_workspace_status ON timesheets(workspace_id, status, week_start);
+
+ALTER TABLE time_entries ADD COLUMN approved_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE time_entries DROP COLUMN IF EXISTS approved_at;
+DROP TABLE IF EXISTS timesheets;
+DROP TYPE IF EXISTS timesheet_status;
+-- +goose StatementEnd
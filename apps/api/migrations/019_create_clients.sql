@@ -0,0 +1,69 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE client_status AS ENUM ('lead', 'active', 'inactive', 'churned');
+
+CREATE TABLE clients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    phone VARCHAR(50),
+    website VARCHAR(500),
+    address TEXT,
+    notes TEXT,
+    status client_status NOT NULL DEFAULT 'lead',
+    -- Revenue and balances are reported in this currency.
+    currency CHAR(3) NOT NULL,
+    tags JSONB NOT NULL DEFAULT '[]',
+    owner_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_clients_workspace_status ON clients(workspace_id, status) WHERE deleted_at IS NULL;
+CREATE INDEX idx_clients_owner_id ON clients(owner_id);
+CREATE INDEX idx_clients_tags ON clients USING GIN (tags);
+
+CREATE TABLE client_contacts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    phone VARCHAR(50),
+    title VARCHAR(255),
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- A client has at most one primary contact.
+CREATE UNIQUE INDEX client_contacts_primary_key ON client_contacts(client_id) WHERE is_primary;
+CREATE INDEX idx_client_contacts_client_id ON client_contacts(client_id);
+
+CREATE TABLE client_status_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    -- NULL for the status the client was created with.
+    from_status client_status,
+    to_status client_status NOT NULL,
+    note TEXT,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_client_status_changes_client_id ON client_status_changes(client_id, created_at);
+
+ALTER TABLE projects ADD COLUMN client_id UUID REFERENCES clients(id) ON DELETE SET NULL;
+CREATE INDEX idx_projects_client_id ON projects(client_id) WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE projects DROP COLUMN IF EXISTS client_id;
+DROP TABLE IF EXISTS client_status_changes;
+DROP TABLE IF EXISTS client_contacts;
+DROP TABLE IF EXISTS clients;
+DROP TYPE IF EXISTS client_status;
+-- +goose StatementEnd
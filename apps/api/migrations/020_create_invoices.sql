@@ -0,0 +1,82 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE invoice_status AS ENUM ('draft', 'sent', 'viewed', 'paid', 'overdue', 'cancelled');
+
+-- One row per workspace holding the last invoice number handed out. Issuing an
+-- invoice increments it in the same transaction, so the row lock serialises
+-- concurrent issuers and a rolled-back issue leaves no gap.
+CREATE TABLE invoice_sequences (
+    workspace_id UUID PRIMARY KEY REFERENCES workspaces(id) ON DELETE CASCADE,
+    last_number BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Amounts are in minor units of the invoice currency. Drafts have no number;
+-- it is assigned when the invoice is sent.
+CREATE TABLE invoices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES clients(id),
+    project_id UUID REFERENCES projects(id) ON DELETE SET NULL,
+    sequence_number BIGINT,
+    number VARCHAR(50),
+    status invoice_status NOT NULL DEFAULT 'draft',
+    currency CHAR(3) NOT NULL,
+    issue_date DATE,
+    due_date DATE,
+    discount_percent NUMERIC(7, 4) NOT NULL DEFAULT 0 CHECK (discount_percent BETWEEN 0 AND 100),
+    tax_rate NUMERIC(7, 4) NOT NULL DEFAULT 0 CHECK (tax_rate BETWEEN 0 AND 100),
+    subtotal BIGINT NOT NULL DEFAULT 0,
+    discount_total BIGINT NOT NULL DEFAULT 0,
+    tax_total BIGINT NOT NULL DEFAULT 0,
+    total BIGINT NOT NULL DEFAULT 0,
+    amount_paid BIGINT NOT NULL DEFAULT 0,
+    balance_due BIGINT GENERATED ALWAYS AS (total - amount_paid) STORED,
+    notes TEXT,
+    terms TEXT,
+    sent_at TIMESTAMPTZ,
+    viewed_at TIMESTAMPTZ,
+    paid_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CHECK (status IN ('draft', 'cancelled') OR number IS NOT NULL),
+    CHECK (due_date IS NULL OR issue_date IS NULL OR due_date >= issue_date)
+);
+
+CREATE UNIQUE INDEX invoices_workspace_sequence_key ON invoices(workspace_id, sequence_number) WHERE sequence_number IS NOT NULL;
+CREATE INDEX idx_invoices_workspace_status ON invoices(workspace_id, status) WHERE deleted_at IS NULL;
+CREATE INDEX idx_invoices_client_id ON invoices(client_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_invoices_project_id ON invoices(project_id) WHERE deleted_at IS NULL;
+
+-- Line amounts are stored as computed when the invoice was last saved so the
+-- document never changes under a client. discount_amount includes the line's
+-- share of the invoice discount.
+CREATE TABLE invoice_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    description TEXT NOT NULL,
+    quantity NUMERIC(14, 4) NOT NULL CHECK (quantity > 0),
+    unit_price BIGINT NOT NULL,
+    discount_percent NUMERIC(7, 4) NOT NULL DEFAULT 0 CHECK (discount_percent BETWEEN 0 AND 100),
+    -- NULL applies the invoice tax rate.
+    tax_rate NUMERIC(7, 4) CHECK (tax_rate BETWEEN 0 AND 100),
+    amount BIGINT NOT NULL,
+    discount_amount BIGINT NOT NULL,
+    tax_amount BIGINT NOT NULL,
+    total BIGINT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (invoice_id, position)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS invoice_items;
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS invoice_sequences;
+DROP TYPE IF EXISTS invoice_status;
+-- +goose StatementEnd
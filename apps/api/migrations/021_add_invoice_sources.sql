@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Minor units of the project currency per hour of billable time. NULL falls
+-- back to the workspace default rate.
+ALTER TABLE projects ADD COLUMN hourly_rate BIGINT CHECK (hourly_rate >= 0);
+
+-- The invoice a billed entry was charged on. Cancelling or deleting the
+-- invoice releases its entries.
+ALTER TABLE time_entries ADD COLUMN invoice_id UUID REFERENCES invoices(id) ON DELETE SET NULL;
+CREATE INDEX idx_time_entries_invoice_id ON time_entries(invoice_id) WHERE invoice_id IS NOT NULL;
+CREATE INDEX idx_time_entries_unbilled ON time_entries(workspace_id, started_at) WHERE billable AND NOT billed AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE time_entries DROP COLUMN IF EXISTS invoice_id;
+ALTER TABLE projects DROP COLUMN IF EXISTS hourly_rate;
+-- +goose StatementEnd
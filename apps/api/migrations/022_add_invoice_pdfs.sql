@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The rendered PDF of an invoice in the private bucket. The fingerprint
+-- identifies what it was rendered from; a PDF whose fingerprint no longer
+-- matches is rendered again on the next request.
+ALTER TABLE invoices
+    ADD COLUMN pdf_object TEXT,
+    ADD COLUMN pdf_fingerprint TEXT,
+    ADD COLUMN pdf_rendered_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE invoices
+    DROP COLUMN IF EXISTS pdf_rendered_at,
+    DROP COLUMN IF EXISTS pdf_fingerprint,
+    DROP COLUMN IF EXISTS pdf_object;
+-- +goose StatementEnd
@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE payment_kind AS ENUM ('payment', 'refund');
+CREATE TYPE payment_method AS ENUM ('bank_transfer', 'card', 'cash', 'check', 'credit', 'other');
+
+-- Money received for an invoice, or returned. amount is what changed hands in
+-- the invoice currency; applied_amount is the part counted against the
+-- invoice. A payment larger than the balance due applies the balance and
+-- leaves the rest as client credit. Payments with the credit method spend
+-- client credit instead of money.
+CREATE TABLE payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES clients(id),
+    invoice_id UUID NOT NULL REFERENCES invoices(id),
+    kind payment_kind NOT NULL DEFAULT 'payment',
+    -- The payment a refund returns.
+    refund_of UUID REFERENCES payments(id),
+    method payment_method NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    applied_amount BIGINT NOT NULL CHECK (applied_amount BETWEEN 0 AND amount),
+    currency CHAR(3) NOT NULL,
+    reference VARCHAR(255),
+    paid_on DATE NOT NULL,
+    notes TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK ((kind = 'refund') = (refund_of IS NOT NULL))
+);
+
+CREATE INDEX idx_payments_invoice_id ON payments(invoice_id);
+CREATE INDEX idx_payments_client_id ON payments(client_id);
+CREATE INDEX idx_payments_refund_of ON payments(refund_of) WHERE refund_of IS NOT NULL;
+
+-- Client credit ledger. Overpayments add credit; applying credit to an
+-- invoice or refunding it takes credit away. The balance of a client in a
+-- currency is the sum of its entries.
+CREATE TABLE client_credits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES clients(id),
+    currency CHAR(3) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount <> 0),
+    payment_id UUID REFERENCES payments(id),
+    invoice_id UUID REFERENCES invoices(id),
+    description TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_client_credits_client ON client_credits(client_id, currency);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS client_credits;
+DROP TABLE IF EXISTS payments;
+DROP TYPE IF EXISTS payment_method;
+DROP TYPE IF EXISTS payment_kind;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The last payment reminder sent for an invoice, as its distance in days
+-- from the due date (-3 is three days before). The reminder job only sends a
+-- reminder scheduled later than this one, so each is sent once.
+ALTER TABLE invoices
+    ADD COLUMN last_reminder_offset INT,
+    ADD COLUMN last_reminded_at TIMESTAMPTZ;
+
+CREATE INDEX idx_invoices_awaiting_payment ON invoices(due_date) WHERE status IN ('sent', 'viewed', 'overdue') AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_invoices_awaiting_payment;
+ALTER TABLE invoices
+    DROP COLUMN IF EXISTS last_reminded_at,
+    DROP COLUMN IF EXISTS last_reminder_offset;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Links that let a client open an invoice without an account. The token
+-- handed out is signed and carries the link id and expiry, so it is not
+-- stored; revoking the link here invalidates it.
+CREATE TABLE invoice_share_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    view_count INTEGER NOT NULL DEFAULT 0,
+    first_viewed_at TIMESTAMPTZ,
+    last_viewed_at TIMESTAMPTZ,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_invoice_share_links_invoice_id ON invoice_share_links(invoice_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS invoice_share_links;
+-- +goose StatementEnd
@@ -0,0 +1,65 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Invoice templates issued on a schedule, such as monthly retainers. Every
+-- occurrence of the RRULE creates an invoice dated on the occurrence and due
+-- due_days later; auto_send sends it straight away instead of leaving a
+-- draft. The schedule ends after end_date or once max_invoices were created.
+CREATE TABLE invoice_recurrences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES clients(id),
+    project_id UUID REFERENCES projects(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    discount_percent NUMERIC(7, 4) NOT NULL DEFAULT 0 CHECK (discount_percent BETWEEN 0 AND 100),
+    tax_rate NUMERIC(7, 4) NOT NULL DEFAULT 0 CHECK (tax_rate BETWEEN 0 AND 100),
+    notes TEXT,
+    terms TEXT,
+    rrule TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    -- The next invoice to create; NULL once the schedule has ended.
+    next_date DATE,
+    due_days INTEGER NOT NULL DEFAULT 30 CHECK (due_days >= 0),
+    end_date DATE,
+    max_invoices INTEGER CHECK (max_invoices IS NULL OR max_invoices > 0),
+    auto_send BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX idx_invoice_recurrences_workspace_id ON invoice_recurrences(workspace_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_invoice_recurrences_next_date ON invoice_recurrences(next_date) WHERE deleted_at IS NULL AND next_date IS NOT NULL;
+
+-- Line items copied onto every invoice of the schedule.
+CREATE TABLE invoice_recurrence_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recurrence_id UUID NOT NULL REFERENCES invoice_recurrences(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    description TEXT NOT NULL,
+    quantity NUMERIC(14, 4) NOT NULL CHECK (quantity > 0),
+    unit_price BIGINT NOT NULL,
+    discount_percent NUMERIC(7, 4) NOT NULL DEFAULT 0 CHECK (discount_percent BETWEEN 0 AND 100),
+    -- NULL applies the invoice tax rate.
+    tax_rate NUMERIC(7, 4) CHECK (tax_rate BETWEEN 0 AND 100),
+    UNIQUE (recurrence_id, position)
+);
+
+-- An occurrence is invoiced at most once, even when several schedulers race.
+ALTER TABLE invoices
+    ADD COLUMN recurrence_id UUID REFERENCES invoice_recurrences(id) ON DELETE SET NULL,
+    ADD COLUMN occurrence_date DATE;
+
+CREATE UNIQUE INDEX invoices_recurrence_occurrence_key ON invoices(recurrence_id, occurrence_date) WHERE recurrence_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS invoices_recurrence_occurrence_key;
+ALTER TABLE invoices DROP COLUMN IF EXISTS occurrence_date;
+ALTER TABLE invoices DROP COLUMN IF EXISTS recurrence_id;
+DROP TABLE IF EXISTS invoice_recurrence_items;
+DROP TABLE IF EXISTS invoice_recurrences;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Exchange rates as a provider reported them for a date. Once stored, a rate
+-- never changes, so converted amounts stay the same no matter when they are
+-- reported or what the provider says later. published_on is the day the
+-- provider published the rate, which may be earlier than rate_date.
+CREATE TABLE exchange_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    published_on DATE NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (base_currency, quote_currency, rate_date)
+);
+
+-- The rate from the document currency to the workspace base currency on the
+-- document date: the issue date of an invoice, the day a payment was made.
+ALTER TABLE invoices
+    ADD COLUMN base_currency CHAR(3),
+    ADD COLUMN exchange_rate NUMERIC(20, 10) CHECK (exchange_rate > 0);
+
+ALTER TABLE payments
+    ADD COLUMN base_currency CHAR(3),
+    ADD COLUMN exchange_rate NUMERIC(20, 10) CHECK (exchange_rate > 0);
+
+CREATE INDEX idx_invoices_missing_exchange_rate ON invoices(issue_date) WHERE exchange_rate IS NULL AND issue_date IS NOT NULL;
+CREATE INDEX idx_payments_missing_exchange_rate ON payments(paid_on) WHERE exchange_rate IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_payments_missing_exchange_rate;
+DROP INDEX IF EXISTS idx_invoices_missing_exchange_rate;
+ALTER TABLE payments DROP COLUMN IF EXISTS exchange_rate;
+ALTER TABLE payments DROP COLUMN IF EXISTS base_currency;
+ALTER TABLE invoices DROP COLUMN IF EXISTS exchange_rate;
+ALTER TABLE invoices DROP COLUMN IF EXISTS base_currency;
+DROP TABLE IF EXISTS exchange_rates;
+-- +goose StatementEnd
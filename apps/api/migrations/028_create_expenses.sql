@@ -0,0 +1,79 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE expense_status AS ENUM ('pending', 'approved', 'rejected', 'reimbursed');
+CREATE TYPE expense_payment_method AS ENUM ('personal_card', 'company_card', 'cash', 'bank_transfer', 'other');
+
+CREATE TABLE expense_categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    color VARCHAR(7),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_expense_categories_workspace_name ON expense_categories(workspace_id, LOWER(name));
+
+-- Money a member spent for the workspace. amount is in minor units of
+-- currency. Billable expenses are charged to client_id, which defaults to
+-- the project's client, and billed on an invoice in that client's currency.
+-- Expenses paid out of pocket are reimbursed once approved.
+CREATE TABLE expenses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES expense_categories(id) ON DELETE SET NULL,
+    project_id UUID REFERENCES projects(id) ON DELETE SET NULL,
+    client_id UUID REFERENCES clients(id),
+    merchant VARCHAR(255) NOT NULL,
+    description TEXT,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    spent_on DATE NOT NULL,
+    payment_method expense_payment_method NOT NULL,
+    reimbursable BOOLEAN NOT NULL DEFAULT TRUE,
+    billable BOOLEAN NOT NULL DEFAULT FALSE,
+    billed BOOLEAN NOT NULL DEFAULT FALSE,
+    -- The invoice a billed expense was charged on. Cancelling or deleting
+    -- the invoice releases it.
+    invoice_id UUID REFERENCES invoices(id) ON DELETE SET NULL,
+    notes TEXT,
+    status expense_status NOT NULL DEFAULT 'pending',
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    rejection_reason TEXT,
+    reimbursed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reimbursed_at TIMESTAMPTZ,
+    reimbursement_reference VARCHAR(255),
+    -- The receipt, kept in the private bucket.
+    receipt_object VARCHAR(500),
+    receipt_name VARCHAR(255),
+    receipt_content_type VARCHAR(100),
+    receipt_size BIGINT,
+    -- Rate from currency to the workspace base currency on spent_on.
+    base_currency CHAR(3),
+    exchange_rate NUMERIC(20, 10) CHECK (exchange_rate > 0),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    deleted_at TIMESTAMPTZ,
+    CHECK (NOT billable OR client_id IS NOT NULL),
+    CHECK (status <> 'reimbursed' OR reimbursable)
+);
+
+CREATE INDEX idx_expenses_workspace_spent_on ON expenses(workspace_id, spent_on) WHERE deleted_at IS NULL;
+CREATE INDEX idx_expenses_user_id ON expenses(user_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_expenses_project_id ON expenses(project_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_expenses_category_id ON expenses(category_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_expenses_invoice_id ON expenses(invoice_id) WHERE invoice_id IS NOT NULL;
+CREATE INDEX idx_expenses_unbilled ON expenses(client_id, spent_on) WHERE billable AND NOT billed AND deleted_at IS NULL;
+CREATE INDEX idx_expenses_missing_exchange_rate ON expenses(spent_on) WHERE exchange_rate IS NULL AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS expenses;
+DROP TABLE IF EXISTS expense_categories;
+DROP TYPE IF EXISTS expense_payment_method;
+DROP TYPE IF EXISTS expense_status;
+-- +goose StatementEnd
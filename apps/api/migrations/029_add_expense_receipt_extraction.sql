@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE receipt_extraction_status AS ENUM ('pending', 'completed', 'unsupported', 'failed', 'confirmed', 'dismissed');
+
+-- What was read off the receipt of an expense. Uploading a receipt queues an
+-- extraction; the suggestions it finds are only applied once the submitter
+-- confirms them. suggested_amount is in minor units of suggested_currency.
+ALTER TABLE expenses
+    ADD COLUMN receipt_uploaded_at TIMESTAMPTZ,
+    ADD COLUMN receipt_extraction_status receipt_extraction_status,
+    ADD COLUMN receipt_extraction_error TEXT,
+    ADD COLUMN receipt_extracted_at TIMESTAMPTZ,
+    ADD COLUMN receipt_extractor VARCHAR(50),
+    ADD COLUMN suggested_merchant VARCHAR(255),
+    ADD COLUMN suggested_amount BIGINT CHECK (suggested_amount > 0),
+    ADD COLUMN suggested_currency CHAR(3),
+    ADD COLUMN suggested_spent_on DATE;
+
+UPDATE expenses SET receipt_uploaded_at = updated_at WHERE receipt_object IS NOT NULL;
+
+CREATE INDEX idx_expenses_pending_receipt_extraction ON expenses(receipt_uploaded_at)
+    WHERE receipt_extraction_status = 'pending' AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_expenses_pending_receipt_extraction;
+ALTER TABLE expenses
+    DROP COLUMN IF EXISTS suggested_spent_on,
+    DROP COLUMN IF EXISTS suggested_currency,
+    DROP COLUMN IF EXISTS suggested_amount,
+    DROP COLUMN IF EXISTS suggested_merchant,
+    DROP COLUMN IF EXISTS receipt_extractor,
+    DROP COLUMN IF EXISTS receipt_extracted_at,
+    DROP COLUMN IF EXISTS receipt_extraction_error,
+    DROP COLUMN IF EXISTS receipt_extraction_status,
+    DROP COLUMN IF EXISTS receipt_uploaded_at;
+DROP TYPE IF EXISTS receipt_extraction_status;
+-- +goose StatementEnd
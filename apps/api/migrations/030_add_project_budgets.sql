@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE project_budget_type AS ENUM ('fixed_fee', 'hourly');
+
+-- A fixed-fee budget is burned by the cost of approved time and expenses
+-- against budget; an hourly budget by approved hours against budget_hours.
+-- budget_alerted_percent is the highest alert threshold the owner has been
+-- warned about, lowered again when the burn drops back below it.
+ALTER TABLE projects
+    ADD COLUMN budget_type project_budget_type NOT NULL DEFAULT 'fixed_fee',
+    ADD COLUMN budget_hours NUMERIC(10, 2) CHECK (budget_hours IS NULL OR budget_hours >= 0),
+    ADD COLUMN budget_alerted_percent INTEGER;
+
+CREATE INDEX idx_projects_budgeted ON projects(id)
+    WHERE deleted_at IS NULL AND (budget > 0 OR budget_hours > 0 OR budget_alerted_percent IS NOT NULL);
+
+-- Minor units of the project currency per hour of time on the task. Wins
+-- over the member and project rates when pricing a project's burn.
+ALTER TABLE tasks ADD COLUMN hourly_rate BIGINT CHECK (hourly_rate >= 0);
+
+-- Minor units of the workspace default currency per hour of the member's
+-- time on projects whose tasks have no rate of their own.
+ALTER TABLE workspace_members ADD COLUMN hourly_rate BIGINT CHECK (hourly_rate >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE workspace_members DROP COLUMN IF EXISTS hourly_rate;
+ALTER TABLE tasks DROP COLUMN IF EXISTS hourly_rate;
+DROP INDEX IF EXISTS idx_projects_budgeted;
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS budget_alerted_percent,
+    DROP COLUMN IF EXISTS budget_hours,
+    DROP COLUMN IF EXISTS budget_type;
+DROP TYPE IF EXISTS project_budget_type;
+-- +goose StatementEnd
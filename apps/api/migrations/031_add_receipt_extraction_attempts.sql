@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- How many times reading the current receipt was started. It is counted
+-- before the receipt is read, so a receipt that takes the process down is
+-- given up on instead of being retried forever.
+ALTER TABLE expenses ADD COLUMN receipt_extraction_attempts INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE expenses DROP COLUMN IF EXISTS receipt_extraction_attempts;
+-- +goose StatementEnd
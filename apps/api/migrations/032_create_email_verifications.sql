@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A link sent to a user to prove they own an email address. Only the hash of
+-- the token is kept; the link verifies the address it was sent to, so one
+-- sent before the email changed verifies nothing.
+CREATE TABLE email_verifications (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_email_verifications_user_id ON email_verifications(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS email_verifications;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- budget_spent is the last computed burn of the project budget in minor units
+-- of the project currency, kept so project listings can show it without
+-- pricing every project's time and expenses on read.
+ALTER TABLE projects
+    ADD COLUMN budget_spent BIGINT,
+    ADD COLUMN budget_spent_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE projects
+    DROP COLUMN IF EXISTS budget_spent_at,
+    DROP COLUMN IF EXISTS budget_spent;
+-- +goose StatementEnd
@@ -14,6 +14,7 @@ var (
 	ErrNotFound           = New(http.StatusNotFound, "resource not found")
 	ErrConflict           = New(http.StatusConflict, "resource already exists")
 	ErrValidationFailed   = New(http.StatusBadRequest, "validation failed")
+	ErrQuotaExceeded      = New(http.StatusPaymentRequired, "plan limit reached")
 	ErrInternal           = New(http.StatusInternalServerError, "internal server error")
 	ErrServiceUnavailable = New(http.StatusServiceUnavailable, "service temporarily unavailable")
 )
@@ -97,6 +98,10 @@ func Conflict(message string) *AppError {
 	return New(http.StatusConflict, message)
 }
 
+func QuotaExceeded(message string) *AppError {
+	return New(http.StatusPaymentRequired, message)
+}
+
 func Internal(err error) *AppError {
 	return Wrap(err, http.StatusInternalServerError, "internal server error")
 }
@@ -124,3 +129,11 @@ func IsValidation(err error) bool {
 	}
 	return false
 }
+
+func IsQuotaExceeded(err error) bool {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code == http.StatusPaymentRequired
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+// Package exchange looks up currency exchange rates. Providers are
+// pluggable; the static provider reads a rates file and works offline.
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lukabrkovic/artemis/internal/config"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// Quote is the rate from one currency to another that applied on a date.
+// Date is the day the rate was published, which may be earlier than the day
+// asked for when no rate was published on it, such as on weekends.
+type Quote struct {
+	Rate   money.Rate
+	Date   time.Time
+	Source string
+}
+
+type Provider interface {
+	// Rate returns the units of to that one unit of from bought on date.
+	// It returns ErrRateUnavailable when the provider has no such rate.
+	Rate(ctx context.Context, from, to string, date time.Time) (*Quote, error)
+}
+
+// New returns the provider named by the configuration.
+func New(cfg config.ExchangeRateConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "", "static":
+		if cfg.File == "" {
+			return NewStaticProvider(StaticRates{}), nil
+		}
+		return LoadStaticProvider(cfg.File)
+	default:
+		return nil, fmt.Errorf("unknown exchange rate provider %q", cfg.Provider)
+	}
+}
@@ -0,0 +1,109 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+const staticSource = "static"
+
+// StaticRates is the content of a rates file: for every date, how many units
+// of each currency one unit of the base currency buys.
+//
+//	{
+//	  "base": "EUR",
+//	  "rates": {
+//	    "2026-03-02": {"USD": "1.0825", "GBP": "0.8532"},
+//	    "2026-03-03": {"USD": "1.0811", "GBP": "0.8527"}
+//	  }
+//	}
+type StaticRates struct {
+	Base  string                           `json:"base"`
+	Rates map[string]map[string]money.Rate `json:"rates"`
+}
+
+type staticDay struct {
+	date  time.Time
+	rates map[string]money.Rate
+}
+
+// StaticProvider answers from a fixed table of daily rates. A date without
+// rates uses the closest earlier date that has them, and rates between two
+// quoted currencies are crossed through the base currency.
+type StaticProvider struct {
+	base string
+	days []staticDay
+}
+
+// LoadStaticProvider reads a rates file in the StaticRates format.
+func LoadStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates StaticRates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("parse exchange rates file: %w", err)
+	}
+	if len(rates.Rates) > 0 && len(rates.Base) != 3 {
+		return nil, fmt.Errorf("exchange rates file needs a three-letter base currency")
+	}
+	return NewStaticProvider(rates), nil
+}
+
+func NewStaticProvider(rates StaticRates) *StaticProvider {
+	p := &StaticProvider{base: strings.ToUpper(rates.Base)}
+	for day, quotes := range rates.Rates {
+		date, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			continue
+		}
+		normalized := make(map[string]money.Rate, len(quotes))
+		for currency, rate := range quotes {
+			normalized[strings.ToUpper(currency)] = rate
+		}
+		p.days = append(p.days, staticDay{date: date, rates: normalized})
+	}
+	slices.SortFunc(p.days, func(a, b staticDay) int { return a.date.Compare(b.date) })
+	return p
+}
+
+var _ Provider = (*StaticProvider)(nil)
+
+func (p *StaticProvider) Rate(ctx context.Context, from, to string, date time.Time) (*Quote, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	// The latest day on or before date that quotes both currencies.
+	i := sort.Search(len(p.days), func(i int) bool { return p.days[i].date.After(day) })
+	for i--; i >= 0; i-- {
+		fromRate, ok := p.quote(p.days[i], from)
+		if !ok {
+			continue
+		}
+		toRate, ok := p.quote(p.days[i], to)
+		if !ok {
+			continue
+		}
+		return &Quote{Rate: money.Cross(fromRate, toRate), Date: p.days[i].date, Source: staticSource}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s to %s on %s", ErrRateUnavailable, from, to, day.Format(time.DateOnly))
+}
+
+func (p *StaticProvider) quote(day staticDay, currency string) (money.Rate, bool) {
+	if currency == p.base {
+		return money.OneRate, true
+	}
+	rate, ok := day.rates[currency]
+	return rate, ok && rate > 0
+}
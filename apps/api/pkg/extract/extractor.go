@@ -0,0 +1,58 @@
+// Package extract reads expense details off receipts. Extractors are
+// pluggable; the heuristic extractor reads the text of PDF receipts and
+// works offline, without OCR.
+package extract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lukabrkovic/artemis/internal/config"
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+// ErrUnsupported is returned for receipts an extractor cannot read, such as
+// images given to an extractor that only reads PDFs.
+var ErrUnsupported = errors.New("receipt format not supported")
+
+// Receipt is what an extractor read off a receipt. Fields it could not find
+// are nil. Total is in major units of Currency, or of an unknown currency
+// when Currency is nil.
+type Receipt struct {
+	Merchant *string
+	Total    *money.Decimal
+	Currency *string
+	Date     *time.Time
+}
+
+type ReceiptExtractor interface {
+	// Name identifies the extractor in stored results.
+	Name() string
+	// Extract reads a receipt file of the given content type. It returns
+	// ErrUnsupported when it cannot read the file.
+	Extract(ctx context.Context, r io.Reader, contentType string) (*Receipt, error)
+}
+
+// New returns the extractor named by the configuration.
+func New(cfg config.ReceiptExtractionConfig) (ReceiptExtractor, error) {
+	switch cfg.Extractor {
+	case "", "heuristic":
+		return NewHeuristicExtractor(), nil
+	case "none":
+		return noopExtractor{}, nil
+	default:
+		return nil, fmt.Errorf("unknown receipt extractor %q", cfg.Extractor)
+	}
+}
+
+// noopExtractor reads nothing, for deployments that turn extraction off.
+type noopExtractor struct{}
+
+func (noopExtractor) Name() string { return "none" }
+
+func (noopExtractor) Extract(ctx context.Context, r io.Reader, contentType string) (*Receipt, error) {
+	return nil, ErrUnsupported
+}
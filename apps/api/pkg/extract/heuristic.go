@@ -0,0 +1,347 @@
+package extract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/lukabrkovic/artemis/pkg/money"
+)
+
+// maxReceiptSize bounds how much of a receipt is read.
+const maxReceiptSize = 20 << 20
+
+// HeuristicExtractor reads the text of PDF receipts and picks the merchant,
+// total, currency and date out of it by the way receipts usually lay them
+// out. It does no OCR, so photographed and scanned receipts are unsupported.
+type HeuristicExtractor struct{}
+
+func NewHeuristicExtractor() *HeuristicExtractor {
+	return &HeuristicExtractor{}
+}
+
+var _ ReceiptExtractor = (*HeuristicExtractor)(nil)
+
+func (e *HeuristicExtractor) Name() string {
+	return "heuristic"
+}
+
+func (e *HeuristicExtractor) Extract(ctx context.Context, r io.Reader, contentType string) (*Receipt, error) {
+	if contentType != "application/pdf" {
+		return nil, ErrUnsupported
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxReceiptSize))
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	lines, err := pdfText(data)
+	if err != nil {
+		if errors.Is(err, errNotPDF) {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+		}
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: the PDF has no text, it may be a scan", ErrUnsupported)
+	}
+
+	return parseReceipt(lines), nil
+}
+
+// parseReceipt picks the receipt fields out of its lines of text.
+func parseReceipt(lines []string) *Receipt {
+	receipt := &Receipt{
+		Merchant: findMerchant(lines),
+		Date:     findDate(lines),
+	}
+
+	currency := documentCurrency(lines)
+	total, line := findTotal(lines, currency)
+	if line >= 0 {
+		if c := lineCurrency(lines[line]); c != "" {
+			currency = c
+		}
+	}
+	receipt.Total = total
+	if currency != "" {
+		receipt.Currency = &currency
+	}
+	return receipt
+}
+
+var (
+	// amountPattern matches amounts written with two decimals, grouped with
+	// commas, dots or apostrophes: 45.00, 1,234.56, 1.234,56, 1'234.50.
+	amountPattern = regexp.MustCompile(`(\d{1,3}(?:[,.']\d{3})+|\d+)[.,](\d{2})`)
+	// wholeAmountPattern matches amounts of currencies without decimals.
+	wholeAmountPattern = regexp.MustCompile(`\d{1,3}(?:[,.']\d{3})+|\d+`)
+
+	digitGrouping = strings.NewReplacer(",", "", ".", "", "'", "")
+)
+
+// lineAmounts returns the amounts written on a line, in order. Numbers that
+// run on into more digits, such as dates and times, are not amounts.
+func lineAmounts(line string, currency string) []money.Decimal {
+	pattern := amountPattern
+	if currency != "" && money.MinorUnits(currency) == 0 {
+		pattern = wholeAmountPattern
+	}
+
+	var amounts []money.Decimal
+	for _, m := range pattern.FindAllStringSubmatchIndex(line, -1) {
+		start, end := m[0], m[1]
+		if start > 0 && (isDigit(line[start-1]) || start > 1 && (line[start-1] == '.' || line[start-1] == ',') && isDigit(line[start-2])) {
+			continue
+		}
+		if end < len(line) && (isDigit(line[end]) || line[end] == '%' ||
+			end+1 < len(line) && strings.IndexByte(".,:/", line[end]) >= 0 && isDigit(line[end+1])) {
+			continue
+		}
+
+		value := digitGrouping.Replace(line[start:end])
+		if len(m) == 6 {
+			value = digitGrouping.Replace(line[m[2]:m[3]]) + "." + line[m[4]:m[5]]
+		}
+		amount, err := money.ParseDecimal(value)
+		if err != nil || amount <= 0 {
+			continue
+		}
+		amounts = append(amounts, amount)
+	}
+	return amounts
+}
+
+var (
+	// Labels of the amount paid, strongest first. Subtotals and tax lines
+	// are not totals.
+	finalTotalLabel = regexp.MustCompile(`(?i)grand\s*total|amount\s+(?:due|paid|charged)|balance\s+due|total\s+(?:due|paid|to\s+pay|amount)|zu\s+zahlen|gesamtbetrag|à\s+payer|za\s+platiti|montant\s+total`)
+	totalLabel      = regexp.MustCompile(`(?i)\btotal\b|\bgesamt|\bsumme\b|\bukupno\b|\btotale\b|\bimporte\b|\bbetrag\b`)
+	notTotalLabel   = regexp.MustCompile(`(?i)sub\s*-?\s*total|zwischensumme|total\s+(?:tax|vat|net|excl)|(?:tax|vat)\s+total|\bnet\s+total|\bnetto\b`)
+)
+
+// findTotal returns the total of a receipt and the line it was found on.
+// Lines labelled as the amount due win over lines labelled total; among
+// equals the largest amount is taken, since item lines under a "Total"
+// column header are smaller than the total itself. A label alone on its
+// line takes the amount on the next line. Without any label the largest
+// amount on the receipt is the best guess.
+func findTotal(lines []string, currency string) (*money.Decimal, int) {
+	bestRank, bestLine := 0, -1
+	var best money.Decimal
+
+	consider := func(rank, line int, amount money.Decimal) {
+		if rank > bestRank || rank == bestRank && amount > best {
+			bestRank, bestLine, best = rank, line, amount
+		}
+	}
+
+	for i, line := range lines {
+		rank := 0
+		switch {
+		case notTotalLabel.MatchString(line):
+		case finalTotalLabel.MatchString(line):
+			rank = 3
+		case totalLabel.MatchString(line):
+			rank = 2
+		}
+
+		amounts := lineAmounts(line, currency)
+		switch {
+		case len(amounts) > 0:
+			if rank == 0 {
+				rank = 1
+			}
+			consider(rank, i, amounts[len(amounts)-1])
+		case rank > 1 && len(strings.Fields(line)) <= 4 && i+1 < len(lines):
+			if next := lineAmounts(lines[i+1], currency); len(next) > 0 {
+				consider(rank, i+1, next[len(next)-1])
+			}
+		}
+	}
+
+	if bestLine < 0 {
+		return nil, -1
+	}
+	return &best, bestLine
+}
+
+var (
+	currencyCode    = regexp.MustCompile(`\b(USD|EUR|GBP|CHF|JPY|CAD|AUD|NZD|SEK|NOK|DKK|ISK|PLN|CZK|HUF|RON|BGN|RSD|BAM|MKD|TRY|CNY|HKD|SGD|INR|ZAR|BRL|MXN|AED|ILS|KRW)\b`)
+	currencySymbols = []struct {
+		symbol   string
+		currency string
+	}{
+		{"US$", "USD"}, {"CA$", "CAD"}, {"C$", "CAD"}, {"AU$", "AUD"}, {"A$", "AUD"}, {"NZ$", "NZD"},
+		{"€", "EUR"}, {"£", "GBP"}, {"¥", "JPY"}, {"₩", "KRW"}, {"₹", "INR"}, {"zł", "PLN"}, {"Kč", "CZK"}, {"$", "USD"},
+	}
+)
+
+// lineCurrency returns the currency written on a line, by code or by
+// symbol, or "" when there is none.
+func lineCurrency(line string) string {
+	if m := currencyCode.FindString(line); m != "" {
+		return m
+	}
+	for _, s := range currencySymbols {
+		if strings.Contains(line, s.symbol) {
+			return s.currency
+		}
+	}
+	return ""
+}
+
+// documentCurrency returns the currency written most often on a receipt.
+func documentCurrency(lines []string) string {
+	counts := map[string]int{}
+	best := ""
+	for _, line := range lines {
+		c := lineCurrency(line)
+		if c == "" {
+			continue
+		}
+		counts[c]++
+		if best == "" || counts[c] > counts[best] {
+			best = c
+		}
+	}
+	return best
+}
+
+var (
+	isoDate     = regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	numericDate = regexp.MustCompile(`\b(\d{1,2})([./-])(\d{1,2})([./-])(\d{4}|\d{2})\b`)
+	dayMonth    = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?\.?\s+(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?,?\s+(\d{4})\b`)
+	monthDay    = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?,?\s+(\d{4})\b`)
+	dateLabel   = regexp.MustCompile(`(?i)\bdate\b|\bdatum\b|\bfecha\b|\bdata\b`)
+)
+
+var monthNames = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// findDate returns the date of a receipt: the first date on a line labelled
+// as the date, else the first date on the receipt.
+func findDate(lines []string) *time.Time {
+	var first *time.Time
+	for _, line := range lines {
+		date := lineDate(line)
+		if date == nil {
+			continue
+		}
+		if dateLabel.MatchString(line) {
+			return date
+		}
+		if first == nil {
+			first = date
+		}
+	}
+	return first
+}
+
+// lineDate returns the first date written on a line. Dates with dots or
+// dashes are read day first; dates with slashes month first. Either is
+// swapped when only the other order is a valid date.
+func lineDate(line string) *time.Time {
+	if m := isoDate.FindStringSubmatch(line); m != nil {
+		if date := makeDate(m[1], m[2], m[3]); date != nil {
+			return date
+		}
+	}
+	for _, m := range numericDate.FindAllStringSubmatch(line, -1) {
+		if m[2] != m[4] {
+			continue
+		}
+		first, second, year := m[1], m[3], m[5]
+		if len(year) == 2 {
+			year = "20" + year
+		}
+		day, month := first, second
+		if m[2] == "/" {
+			day, month = second, first
+		}
+		if date := makeDate(year, month, day); date != nil {
+			return date
+		}
+		if date := makeDate(year, day, month); date != nil {
+			return date
+		}
+	}
+	if m := dayMonth.FindStringSubmatch(line); m != nil {
+		if date := makeDate(m[3], strconv.Itoa(int(monthNames[strings.ToLower(m[2])])), m[1]); date != nil {
+			return date
+		}
+	}
+	if m := monthDay.FindStringSubmatch(line); m != nil {
+		if date := makeDate(m[3], strconv.Itoa(int(monthNames[strings.ToLower(m[1])])), m[2]); date != nil {
+			return date
+		}
+	}
+	return nil
+}
+
+func makeDate(year, month, day string) *time.Time {
+	y, _ := strconv.Atoi(year)
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	if y < 2000 || y > 2099 || m < 1 || m > 12 || d < 1 {
+		return nil
+	}
+	date := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if date.Day() != d {
+		return nil
+	}
+	return &date
+}
+
+// Lines near the top of a receipt that are not the merchant's name.
+var notMerchant = regexp.MustCompile(`(?i)receipt|invoice|rechnung|quittung|račun|facture|factura|\border\b|\bdate\b|total|\btel\b|phone|\bfax\b|www\.|https?:|@|\bvat\b|\btax\b|\bpage\b|thank|welcome|\bno\.|#`)
+
+// findMerchant returns the first line near the top of a receipt that reads
+// like a name rather than a heading, an address detail or a number.
+func findMerchant(lines []string) *string {
+	for i, line := range lines {
+		if i >= 10 {
+			break
+		}
+		if notMerchant.MatchString(line) || lineDate(line) != nil {
+			continue
+		}
+
+		letters, digits := 0, 0
+		for _, r := range line {
+			switch {
+			case unicode.IsLetter(r):
+				letters++
+			case unicode.IsDigit(r):
+				digits++
+			}
+		}
+		if letters < 3 || digits > letters {
+			continue
+		}
+
+		merchant := strings.TrimSpace(line)
+		if runes := []rune(merchant); len(runes) > 255 {
+			merchant = string(runes[:255])
+		}
+		return &merchant
+	}
+	return nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,452 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Limits that keep a hostile PDF from using unbounded memory. Streams are
+// decoded one at a time, each to at most maxStreamSize bytes, and no more
+// than maxDecodedSize bytes are decoded from one PDF in total.
+const (
+	maxStreamSize  = 8 << 20
+	maxDecodedSize = 32 << 20
+	maxTextSize    = 1 << 20
+)
+
+var errNotPDF = errors.New("not a PDF file")
+
+var (
+	flateFilter   = regexp.MustCompile(`/Filter\s*(?:/FlateDecode|\[\s*/FlateDecode\s*\])`)
+	skippedStream = regexp.MustCompile(`/Subtype\s*/(?:Image|Type1C|CIDFontType0C|OpenType|XML)|/Type\s*/(?:XRef|ObjStm|Metadata|EmbeddedFile)|/Length[123]\b`)
+)
+
+// pdfText returns the lines of text a PDF draws, in the order it draws them.
+// It reads uncompressed and Flate-compressed content streams drawn with
+// simple fonts, which covers receipts made by billing and point-of-sale
+// software. Scanned receipts are images and have no text to read.
+func pdfText(data []byte) ([]string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return nil, errNotPDF
+	}
+
+	var lines []string
+	size := 0
+	pdfStreams(data, func(content []byte) bool {
+		for _, line := range contentText(content) {
+			if size += len(line); size > maxTextSize {
+				return false
+			}
+			lines = append(lines, line)
+		}
+		return true
+	})
+	return lines, nil
+}
+
+// pdfStreams calls fn with each decoded stream of a PDF that can hold page
+// content, until fn returns false or the decoding budget is spent. Images,
+// fonts and streams with filters other than Flate are left out.
+func pdfStreams(data []byte, fn func(content []byte) bool) {
+	budget := maxDecodedSize
+	pos := 0
+	for budget > 0 {
+		i := bytes.Index(data[pos:], []byte("stream"))
+		if i < 0 {
+			return
+		}
+		start := pos + i
+		pos = start + len("stream")
+		if start >= 3 && string(data[start-3:start]) == "end" {
+			continue
+		}
+
+		// The keyword ends its line; anything else is not a stream.
+		body := pos
+		if body < len(data) && data[body] == '\r' {
+			body++
+		}
+		if body >= len(data) || data[body] != '\n' {
+			continue
+		}
+		body++
+
+		end := bytes.Index(data[body:], []byte("endstream"))
+		if end < 0 {
+			return
+		}
+		raw := data[body : body+end]
+		pos = body + end + len("endstream")
+
+		dict := data[:start]
+		if obj := bytes.LastIndex(dict, []byte("obj")); obj >= 0 {
+			dict = dict[obj:]
+		}
+		if skippedStream.Match(dict) {
+			continue
+		}
+		if !bytes.Contains(dict, []byte("/Filter")) {
+			budget -= len(raw)
+			if !fn(raw) {
+				return
+			}
+			continue
+		}
+		if !flateFilter.Match(dict) {
+			continue
+		}
+
+		zr, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			continue
+		}
+		// Streams cut short still give the text before the cut.
+		decoded, _ := io.ReadAll(io.LimitReader(zr, int64(min(maxStreamSize, budget))))
+		zr.Close()
+		budget -= len(decoded)
+		if len(decoded) > 0 && !fn(decoded) {
+			return
+		}
+	}
+}
+
+// operand is a value pushed before a content stream operator.
+type operand struct {
+	kind  byte // 's' string, 'n' number, 'a' array, '[' array start, '/' name
+	str   string
+	num   float64
+	items []operand
+}
+
+// textWriter gathers drawn text into lines. Text drawn at the same height
+// is one line, however many times the position moves along it.
+type textWriter struct {
+	lines []string
+	line  strings.Builder
+	lastY float64
+	moved bool
+}
+
+func (w *textWriter) write(s string, y float64) {
+	if s == "" {
+		return
+	}
+	if w.line.Len() > 0 {
+		if math.Abs(y-w.lastY) > 1 {
+			w.newline()
+		} else if w.moved {
+			w.line.WriteByte(' ')
+		}
+	}
+	w.line.WriteString(s)
+	w.lastY = y
+	w.moved = false
+}
+
+func (w *textWriter) newline() {
+	if line := strings.Join(strings.Fields(w.line.String()), " "); line != "" {
+		w.lines = append(w.lines, line)
+	}
+	w.line.Reset()
+}
+
+// contentText returns the lines of text drawn by a content stream. Positions
+// are tracked by height only, ignoring scaling and rotation, which is enough
+// to tell the lines of a receipt apart.
+func contentText(content []byte) []string {
+	var w textWriter
+	var operands []operand
+	var stack []float64 // heights saved by q
+	var ctmY, lineY float64
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case isPDFSpace(c):
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, n := literalString(content[i:])
+			operands = append(operands, operand{kind: 's', str: s})
+			i += n
+		case c == '<' && i+1 < len(content) && content[i+1] == '<', c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+		case c == '<':
+			s, n := hexString(content[i:])
+			operands = append(operands, operand{kind: 's', str: s})
+			i += n
+		case c == '[':
+			operands = append(operands, operand{kind: '['})
+			i++
+		case c == ']':
+			start := len(operands) - 1
+			for start >= 0 && operands[start].kind != '[' {
+				start--
+			}
+			if start < 0 {
+				i++
+				continue
+			}
+			items := append([]operand(nil), operands[start+1:]...)
+			operands = append(operands[:start], operand{kind: 'a', items: items})
+			i++
+		case c == '/':
+			j := i + 1
+			for j < len(content) && !isPDFSpace(content[j]) && !isPDFDelimiter(content[j]) {
+				j++
+			}
+			operands = append(operands, operand{kind: '/', str: string(content[i+1 : j])})
+			i = j
+		case c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.':
+			j := i + 1
+			for j < len(content) && (content[j] >= '0' && content[j] <= '9' || content[j] == '.') {
+				j++
+			}
+			num, _ := strconv.ParseFloat(string(content[i:j]), 64)
+			operands = append(operands, operand{kind: 'n', num: num})
+			i = j
+		default:
+			j := i + 1
+			for j < len(content) && !isPDFSpace(content[j]) && !isPDFDelimiter(content[j]) {
+				j++
+			}
+			if j == i+1 && isPDFDelimiter(c) {
+				i = j
+				continue
+			}
+			op := string(content[i:j])
+			i = j
+
+			switch op {
+			case "q":
+				stack = append(stack, ctmY)
+			case "Q":
+				if len(stack) > 0 {
+					ctmY = stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+				}
+			case "cm":
+				if nums := trailingNumbers(operands, 6); nums != nil {
+					ctmY += nums[5]
+				}
+			case "BT":
+				lineY = 0
+				w.moved = true
+			case "Td", "TD":
+				if nums := trailingNumbers(operands, 2); nums != nil {
+					lineY += nums[1]
+				}
+				w.moved = true
+			case "Tm":
+				if nums := trailingNumbers(operands, 6); nums != nil {
+					lineY = nums[5]
+				}
+				w.moved = true
+			case "T*":
+				w.newline()
+				lineY -= 1000
+			case "Tj":
+				if s, ok := lastString(operands); ok {
+					w.write(s, ctmY+lineY)
+				}
+			case "'", "\"":
+				w.newline()
+				lineY -= 1000
+				if s, ok := lastString(operands); ok {
+					w.write(s, ctmY+lineY)
+				}
+			case "TJ":
+				if len(operands) > 0 && operands[len(operands)-1].kind == 'a' {
+					for _, item := range operands[len(operands)-1].items {
+						switch {
+						case item.kind == 's':
+							w.write(item.str, ctmY+lineY)
+						case item.kind == 'n' && item.num < -200:
+							// A gap wider than a fifth of the font size
+							// separates words.
+							w.moved = true
+						}
+					}
+				}
+			case "ID":
+				// Inline image data runs up to EI and is not text.
+				end := bytes.Index(content[i:], []byte("EI"))
+				if end < 0 {
+					i = len(content)
+				} else {
+					i += end + 2
+				}
+			}
+			operands = operands[:0]
+		}
+	}
+	w.newline()
+	return w.lines
+}
+
+func trailingNumbers(operands []operand, n int) []float64 {
+	if len(operands) < n {
+		return nil
+	}
+	nums := make([]float64, n)
+	for i, op := range operands[len(operands)-n:] {
+		if op.kind != 'n' {
+			return nil
+		}
+		nums[i] = op.num
+	}
+	return nums
+}
+
+func lastString(operands []operand) (string, bool) {
+	if len(operands) == 0 || operands[len(operands)-1].kind != 's' {
+		return "", false
+	}
+	return operands[len(operands)-1].str, true
+}
+
+// literalString decodes the (string) at the start of b and returns it with
+// the number of bytes it took.
+func literalString(b []byte) (string, int) {
+	var out []byte
+	depth := 0
+	i := 1
+	for ; i < len(b); i++ {
+		c := b[i]
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			if depth == 0 {
+				return decodeText(out), i + 1
+			}
+			depth--
+			out = append(out, c)
+		case '\\':
+			i++
+			if i >= len(b) {
+				break
+			}
+			switch e := b[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r':
+				if i+1 < len(b) && b[i+1] == '\n' {
+					i++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					j := i
+					for ; j < len(b) && j < i+3 && b[j] >= '0' && b[j] <= '7'; j++ {
+						n = n*8 + int(b[j]-'0')
+					}
+					out = append(out, byte(n))
+					i = j - 1
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return decodeText(out), i
+}
+
+// hexString decodes the <hex string> at the start of b and returns it with
+// the number of bytes it took.
+func hexString(b []byte) (string, int) {
+	var digits []byte
+	i := 1
+	for ; i < len(b) && b[i] != '>'; i++ {
+		if c := b[i]; c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F' {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for j := range out {
+		n, _ := strconv.ParseUint(string(digits[2*j:2*j+2]), 16, 8)
+		out[j] = byte(n)
+	}
+
+	// Two-byte codes whose high bytes are all zero are usually the
+	// character codes of a simple font written wide.
+	if len(out) >= 2 && len(out)%2 == 0 && !(out[0] == 0xFE && out[1] == 0xFF) {
+		wide := true
+		for j := 0; j < len(out); j += 2 {
+			if out[j] != 0 {
+				wide = false
+				break
+			}
+		}
+		if wide {
+			narrow := make([]byte, 0, len(out)/2)
+			for j := 1; j < len(out); j += 2 {
+				narrow = append(narrow, out[j])
+			}
+			out = narrow
+		}
+	}
+	return decodeText(out), min(i+1, len(b))
+}
+
+// winAnsi maps the WinAnsiEncoding codes that differ from Latin-1 and can
+// appear on a receipt.
+var winAnsi = map[byte]rune{
+	0x80: '€', 0x85: '…', 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”', 0x96: '–', 0x97: '—', 0x99: '™',
+}
+
+// decodeText turns the bytes of a PDF string into text. Strings starting
+// with a byte order mark are UTF-16; others are read as WinAnsiEncoding,
+// the encoding of the standard fonts.
+func decodeText(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		units := make([]uint16, 0, len(b)/2)
+		for j := 2; j+1 < len(b); j += 2 {
+			units = append(units, uint16(b[j])<<8|uint16(b[j+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	var sb strings.Builder
+	for _, c := range b {
+		switch {
+		case winAnsi[c] != 0:
+			sb.WriteRune(winAnsi[c])
+		case c < 0x20:
+			sb.WriteByte(' ')
+		default:
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
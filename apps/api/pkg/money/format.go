@@ -0,0 +1,152 @@
+package money
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// minorUnits lists the currencies whose minor unit is not a hundredth, by
+// the number of decimals they are written with.
+var minorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// MinorUnits returns the number of decimals of a currency, 2 for most.
+func MinorUnits(currency string) int {
+	if n, ok := minorUnits[strings.ToUpper(currency)]; ok {
+		return n
+	}
+	return 2
+}
+
+// ToMinor returns d, an amount in major units of currency, in minor units
+// rounded to a whole one: 12.345 EUR is 1235.
+func ToMinor(d Decimal, currency string) int64 {
+	factor := int64(1)
+	for range MinorUnits(currency) {
+		factor *= 10
+	}
+	// No currency has more minor units than a Decimal has fractional
+	// digits, so the result is never larger than d and cannot overflow.
+	amount, _ := Mul(factor, d)
+	return amount
+}
+
+var currencySymbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥",
+}
+
+// NumberFormat is the way a locale writes numbers and amounts.
+type NumberFormat struct {
+	decimal     string
+	group       string
+	symbolAfter bool
+	symbolSpace bool
+}
+
+// Locales that group digits with dots and use a decimal comma, and those
+// that group with a space. Every other language writes 1,234.56.
+var (
+	dotGroupLanguages   = []string{"da", "de", "el", "es", "hr", "id", "it", "nl", "pt", "ro", "sl", "sr", "tr", "vi"}
+	spaceGroupLanguages = []string{"bg", "cs", "et", "fi", "fr", "hu", "lt", "lv", "nb", "nn", "no", "pl", "ru", "sk", "sv", "uk"}
+)
+
+// FormatFor returns the number format of a BCP 47 locale such as "de-DE".
+// Currency symbols follow the number in the languages that write
+// "1.234,56 €" and are set apart by a space in those that write "€ 1.234,56".
+func FormatFor(locale string) NumberFormat {
+	language, region, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	language = strings.ToLower(language)
+	region = strings.ToUpper(region)
+
+	switch {
+	case region == "CH" && (language == "de" || language == "it" || language == "fr"):
+		return NumberFormat{decimal: ".", group: "’", symbolAfter: language == "fr", symbolSpace: true}
+	case language == "pt" && region == "BR", language == "nl":
+		return NumberFormat{decimal: ",", group: ".", symbolSpace: true}
+	case slices.Contains(dotGroupLanguages, language):
+		return NumberFormat{decimal: ",", group: ".", symbolAfter: true}
+	case slices.Contains(spaceGroupLanguages, language):
+		return NumberFormat{decimal: ",", group: "\u202f", symbolAfter: true}
+	}
+	return NumberFormat{decimal: ".", group: ","}
+}
+
+// Amount formats an amount in minor units with its currency symbol, or its
+// code when the currency has no widely understood symbol.
+func (f NumberFormat) Amount(amount int64, currency string) string {
+	currency = strings.ToUpper(currency)
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	decimals := MinorUnits(currency)
+	divisor := int64(1)
+	for range decimals {
+		divisor *= 10
+	}
+	number := f.group3(amount / divisor)
+	if decimals > 0 {
+		frac := amount % divisor
+		number += f.decimal + leftPad(frac, decimals)
+	}
+
+	symbol, ok := currencySymbols[currency]
+	if !ok {
+		symbol = currency
+	}
+	switch {
+	case f.symbolAfter:
+		return sign + number + "\u00a0" + symbol
+	case f.symbolSpace || !ok:
+		return sign + symbol + "\u00a0" + number
+	default:
+		return sign + symbol + number
+	}
+}
+
+// Number formats a decimal such as a quantity or a percentage, without
+// trailing fractional zeros.
+func (f NumberFormat) Number(d Decimal) string {
+	s := d.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	var n int64
+	for _, c := range whole {
+		n = n*10 + int64(c-'0')
+	}
+	s = f.group3(n)
+	if frac != "" {
+		s += f.decimal + frac
+	}
+	return sign + s
+}
+
+func (f NumberFormat) group3(n int64) string {
+	digits := leftPad(n, 1)
+	var b strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func leftPad(n int64, width int) string {
+	s := strconv.FormatInt(n, 10)
+	if len(s) < width {
+		s = strings.Repeat("0", width-len(s)) + s
+	}
+	return s
+}
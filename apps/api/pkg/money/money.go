@@ -0,0 +1,191 @@
+// Package money does exact arithmetic on amounts held in minor units of a
+// currency (cents for USD) and on decimal quantities and rates. Floats are
+// never involved: a Decimal is a fixed-point number with four fractional
+// digits and every product is rounded once, half away from zero.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of fractional digits a Decimal holds.
+const Scale = 4
+
+const unit = 10000
+
+var (
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	// ErrOverflow is returned when a result does not fit in an int64.
+	ErrOverflow = errors.New("amount out of range")
+)
+
+// Decimal is a fixed-point number stored as an integer count of 1/10000ths.
+// It is used for quantities (1.5 hours) and percentages (8.875 %).
+type Decimal int64
+
+// NewDecimal returns the Decimal for a whole number.
+func NewDecimal(n int64) Decimal {
+	return Decimal(n * unit)
+}
+
+// ParseDecimal parses a plain decimal string such as "12", "-0.5" or
+// "8.875". More than four fractional digits are rejected rather than rounded.
+func ParseDecimal(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidDecimal
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || len(frac) > Scale || !isDigits(whole) || !isDigits(frac) {
+		return 0, ErrInvalidDecimal
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	n, err := strconv.ParseInt(whole+frac+strings.Repeat("0", Scale-len(frac)), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidDecimal
+	}
+	if negative {
+		n = -n
+	}
+	return Decimal(n), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the value without trailing fractional zeros, e.g. "1.5".
+func (d Decimal) String() string {
+	n := int64(d)
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	whole, frac := n/unit, n%unit
+	if frac == 0 {
+		return fmt.Sprintf("%s%d", sign, whole)
+	}
+	return strings.TrimRight(fmt.Sprintf("%s%d.%04d", sign, whole, frac), "0")
+}
+
+// MarshalJSON writes the value as a JSON number so clients can read it
+// without a parsing step.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts both numbers and strings.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Scan reads a NUMERIC column.
+func (d *Decimal) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d = 0
+		return nil
+	case int64:
+		*d = NewDecimal(v)
+		return nil
+	case []byte:
+		return d.scanString(string(v))
+	case string:
+		return d.scanString(v)
+	default:
+		return fmt.Errorf("unsupported decimal type %T", src)
+	}
+}
+
+// scanString also accepts the trailing zeros Postgres pads NUMERIC(p,s)
+// values with beyond the Decimal scale.
+func (d *Decimal) scanString(s string) error {
+	if whole, frac, ok := strings.Cut(s, "."); ok && len(frac) > Scale {
+		s = whole + "." + strings.TrimRight(frac, "0")
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Mul returns amount × d rounded to a whole minor unit.
+func Mul(amount int64, d Decimal) (int64, error) {
+	return divRound(new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(d))), unit)
+}
+
+// Percent returns pct percent of amount rounded to a whole minor unit.
+func Percent(amount int64, pct Decimal) (int64, error) {
+	return divRound(new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(pct))), unit*100)
+}
+
+// divRound divides n by d, rounding half away from zero. It returns
+// ErrOverflow when the quotient does not fit in an int64.
+func divRound(n *big.Int, d int64) (int64, error) {
+	q, r := new(big.Int).QuoRem(n, big.NewInt(d), new(big.Int))
+	if r.Abs(r).Lsh(r, 1).Cmp(big.NewInt(d)) >= 0 {
+		if n.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if !q.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return q.Int64(), nil
+}
+
+// Ratio returns n/d as a Decimal, rounded half away from zero. Hours worked
+// are Ratio(seconds, 3600).
+func Ratio(n, d int64) (Decimal, error) {
+	q, err := divRound(new(big.Int).Mul(big.NewInt(n), big.NewInt(unit)), d)
+	return Decimal(q), err
+}
@@ -0,0 +1,202 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func dec(t *testing.T, s string) Decimal {
+	t.Helper()
+	d, err := ParseDecimal(s)
+	if err != nil {
+		t.Fatalf("ParseDecimal(%q) error = %v", s, err)
+	}
+	return d
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Decimal
+		str  string
+		err  error
+	}{
+		{in: "12", want: 120000, str: "12"},
+		{in: "-0.5", want: -5000, str: "-0.5"},
+		{in: "+8.875", want: 88750, str: "8.875"},
+		{in: ".25", want: 2500, str: "0.25"},
+		{in: "3.", want: 30000, str: "3"},
+		{in: " 0.0001 ", want: 1, str: "0.0001"},
+		{in: "-0", want: 0, str: "0"},
+		{in: "", err: ErrInvalidDecimal},
+		{in: ".", err: ErrInvalidDecimal},
+		{in: "-", err: ErrInvalidDecimal},
+		{in: "1.23456", err: ErrInvalidDecimal},
+		{in: "1e3", err: ErrInvalidDecimal},
+		{in: "1,5", err: ErrInvalidDecimal},
+		{in: "--1", err: ErrInvalidDecimal},
+		{in: "99999999999999999999", err: ErrInvalidDecimal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDecimal(tt.in)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ParseDecimal(%q) error = %v, want %v", tt.in, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDecimal(%q) error = %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDecimal(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+			if got.String() != tt.str {
+				t.Errorf("String() = %q, want %q", got.String(), tt.str)
+			}
+		})
+	}
+}
+
+// The rounding tests put exact halves on both sides of zero. Rounding half
+// to even or toward zero would pass the other cases but not these.
+func TestMul(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		d      string
+		want   int64
+	}{
+		{name: "exact", amount: 10000, d: "1.5", want: 15000},
+		{name: "zero", amount: 0, d: "3.25", want: 0},
+		{name: "half rounds up", amount: 1, d: "0.5", want: 1},
+		{name: "negative half rounds down", amount: -1, d: "0.5", want: -1},
+		{name: "negative factor half rounds down", amount: 1, d: "-0.5", want: -1},
+		{name: "even half rounds up", amount: 5, d: "0.5", want: 3},
+		{name: "below half", amount: 4, d: "0.1", want: 0},
+		{name: "negative below half", amount: -4, d: "0.1", want: 0},
+		{name: "above half", amount: 3, d: "0.1667", want: 1},
+		{name: "hours times rate", amount: 12550, d: "2.3333", want: 29283},
+		{name: "large amount", amount: 900_000_000_000_000, d: "1.0001", want: 900_090_000_000_000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Mul(tt.amount, dec(t, tt.d))
+			if err != nil {
+				t.Fatalf("Mul(%d, %s) error = %v", tt.amount, tt.d, err)
+			}
+			if got != tt.want {
+				t.Errorf("Mul(%d, %s) = %d, want %d", tt.amount, tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		pct    string
+		want   int64
+	}{
+		{name: "exact", amount: 250, pct: "10", want: 25},
+		{name: "half rounds up", amount: 5, pct: "10", want: 1},
+		{name: "negative half rounds down", amount: -5, pct: "10", want: -1},
+		{name: "odd half rounds up", amount: 15, pct: "10", want: 2},
+		{name: "below half", amount: 14, pct: "10", want: 1},
+		{name: "fractional rate below half", amount: 1999, pct: "8.875", want: 177},
+		{name: "fractional rate half", amount: 2000, pct: "8.875", want: 178},
+		{name: "negative fractional rate half", amount: -2000, pct: "8.875", want: -178},
+		{name: "zero rate", amount: 12345, pct: "0", want: 0},
+		{name: "hundred percent", amount: 12345, pct: "100", want: 12345},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Percent(tt.amount, dec(t, tt.pct))
+			if err != nil {
+				t.Fatalf("Percent(%d, %s) error = %v", tt.amount, tt.pct, err)
+			}
+			if got != tt.want {
+				t.Errorf("Percent(%d, %s) = %d, want %d", tt.amount, tt.pct, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		n, d int64
+		want string
+	}{
+		{name: "exact hours", n: 5400, d: 3600, want: "1.5"},
+		{name: "third", n: 1, d: 3, want: "0.3333"},
+		{name: "two thirds", n: 2, d: 3, want: "0.6667"},
+		{name: "half of the last digit", n: 1, d: 20000, want: "0.0001"},
+		{name: "negative half of the last digit", n: -1, d: 20000, want: "-0.0001"},
+		{name: "below half of the last digit", n: 1, d: 30000, want: "0"},
+		{name: "one second", n: 1, d: 3600, want: "0.0003"},
+		{name: "zero", n: 0, d: 3600, want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Ratio(tt.n, tt.d)
+			if err != nil {
+				t.Fatalf("Ratio(%d, %d) error = %v", tt.n, tt.d, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Ratio(%d, %d) = %s, want %s", tt.n, tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	const maxInt64 = 1<<63 - 1
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{name: "mul", fn: func() error { _, err := Mul(maxInt64, NewDecimal(2)); return err }},
+		{name: "negative mul", fn: func() error { _, err := Mul(-maxInt64, NewDecimal(2)); return err }},
+		{name: "percent", fn: func() error { _, err := Percent(maxInt64, NewDecimal(200)); return err }},
+		{name: "ratio", fn: func() error { _, err := Ratio(maxInt64, 1); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn(); !errors.Is(err, ErrOverflow) {
+				t.Errorf("error = %v, want %v", err, ErrOverflow)
+			}
+		})
+	}
+}
+
+func TestToMinor(t *testing.T) {
+	tests := []struct {
+		d        string
+		currency string
+		want     int64
+	}{
+		{d: "12.34", currency: "EUR", want: 1234},
+		{d: "12.345", currency: "EUR", want: 1235},
+		{d: "-12.345", currency: "EUR", want: -1235},
+		{d: "12.344", currency: "usd", want: 1234},
+		{d: "12.5", currency: "JPY", want: 13},
+		{d: "-12.5", currency: "JPY", want: -13},
+		{d: "1.2345", currency: "KWD", want: 1235},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.d+" "+tt.currency, func(t *testing.T) {
+			if got := ToMinor(dec(t, tt.d), tt.currency); got != tt.want {
+				t.Errorf("ToMinor(%s, %s) = %d, want %d", tt.d, tt.currency, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RateScale is the number of fractional digits a Rate holds. Exchange rates
+// need more precision than quantities: 1 JPY is about 0.0064 USD.
+const RateScale = 10
+
+const rateUnit = 10_000_000_000
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// Rate is a positive exchange rate, the units of one currency that a single
+// unit of another buys, stored as an integer count of 1/10^10ths.
+type Rate int64
+
+// OneRate converts a currency to itself.
+const OneRate = Rate(rateUnit)
+
+// ParseRate parses a plain decimal string such as "1.0825". Rates must be
+// positive, and more than ten fractional digits are rounded.
+func ParseRate(s string) (Rate, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || r.Sign() <= 0 || strings.ContainsAny(s, "eE/") {
+		return 0, ErrInvalidRate
+	}
+	rate := ratToRate(r)
+	if rate <= 0 {
+		return 0, ErrInvalidRate
+	}
+	return rate, nil
+}
+
+// ratToRate rounds r to the Rate scale, half away from zero.
+func ratToRate(r *big.Rat) Rate {
+	n := new(big.Int).Mul(r.Num(), big.NewInt(rateUnit))
+	q, rem := new(big.Int).QuoRem(n, r.Denom(), new(big.Int))
+	if rem.Abs(rem).Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if !q.IsInt64() {
+		return 0
+	}
+	return Rate(q.Int64())
+}
+
+// String formats the rate without trailing fractional zeros, e.g. "1.0825".
+func (r Rate) String() string {
+	whole, frac := int64(r)/rateUnit, int64(r)%rateUnit
+	if frac == 0 {
+		return strconv.FormatInt(whole, 10)
+	}
+	return strings.TrimRight(fmt.Sprintf("%d.%010d", whole, frac), "0")
+}
+
+// Inverse returns the rate of the opposite direction.
+func (r Rate) Inverse() Rate {
+	if r <= 0 {
+		return 0
+	}
+	return ratToRate(new(big.Rat).SetFrac(big.NewInt(rateUnit), big.NewInt(int64(r))))
+}
+
+// Cross returns the rate from A to B given the rates from a common base to A
+// and to B.
+func Cross(baseToA, baseToB Rate) Rate {
+	if baseToA <= 0 {
+		return 0
+	}
+	return ratToRate(new(big.Rat).SetFrac(big.NewInt(int64(baseToB)), big.NewInt(int64(baseToA))))
+}
+
+// Convert returns amount, in minor units of from, in minor units of to. The
+// rate is the units of to that one unit of from buys; the result is rounded
+// once, half away from zero.
+func Convert(amount int64, from, to string, rate Rate) int64 {
+	n := new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(rate)))
+	d := big.NewInt(rateUnit)
+
+	shift := MinorUnits(to) - MinorUnits(from)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil)
+	if shift > 0 {
+		n.Mul(n, scale)
+	} else {
+		d.Mul(d, scale)
+	}
+
+	q, rem := new(big.Int).QuoRem(n, d, new(big.Int))
+	if rem.Abs(rem).Lsh(rem, 1).Cmp(d) >= 0 {
+		if n.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q.Int64()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// MarshalJSON writes the rate as a JSON number.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON accepts both numbers and strings.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+
+	v, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// Scan reads a NUMERIC column.
+func (r *Rate) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*r = 0
+		return nil
+	case int64:
+		*r = Rate(v * rateUnit)
+		return nil
+	case []byte:
+		return r.scanString(string(v))
+	case string:
+		return r.scanString(v)
+	default:
+		return fmt.Errorf("unsupported rate type %T", src)
+	}
+}
+
+func (r *Rate) scanString(s string) error {
+	v, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
@@ -0,0 +1,133 @@
+package pdf
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Glyph widths of the printable ASCII characters (32–126) in 1/1000 em, from
+// the Adobe font metrics of the standard Helvetica faces.
+var asciiWidths = [...][95]uint16{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// latinBase maps the accented letters of Latin-1 (0xC0–0xFF) to the ASCII
+// letter whose width they share. Æ, ×, ß, æ and ÷ are measured separately.
+const latinBase = "AAAAAA CEEEEIIIIDNOOOOO OUUUUYP aaaaaa ceeeeiiiidnooooo ouuuuypy"
+
+// winAnsi maps the characters of the Windows-1252 range 0x80–0x9F that PDF
+// readers know by their WinAnsiEncoding code.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+var specialWidths = map[rune]uint16{
+	'€': 556, '‚': 222, '„': 333, '…': 1000, '‘': 222, '’': 222, '“': 333, '”': 333,
+	'•': 350, '–': 556, '—': 1000, '™': 1000, 'Æ': 1000, 'æ': 889, '×': 584, '÷': 584,
+	'ß': 611, '·': 278, '\u00a0': 278,
+}
+
+// encode converts s to WinAnsiEncoding. Spaces that only differ in width,
+// such as the narrow no-break space some locales group digits with, become
+// plain spaces, and characters the encoding lacks become "?".
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		b.WriteByte(encodeRune(r))
+	}
+	return b.String()
+}
+
+func encodeRune(r rune) byte {
+	switch {
+	case r >= 32 && r < 127, r >= 0xa0 && r <= 0xff:
+		return byte(r)
+	case r == '\u202f' || r == '\u2009' || r == '\t':
+		return ' '
+	}
+	if c, ok := winAnsi[r]; ok {
+		return c
+	}
+	return '?'
+}
+
+func runeWidth(font Font, r rune) uint16 {
+	if r == '\u202f' || r == '\u2009' || r == '\t' {
+		r = ' '
+	}
+	if w, ok := specialWidths[r]; ok {
+		return w
+	}
+	if r >= 0xc0 && r <= 0xff && latinBase[r-0xc0] != ' ' {
+		r = rune(latinBase[r-0xc0])
+	}
+	if r >= 32 && r < 127 {
+		return asciiWidths[font][r-32]
+	}
+	return 556
+}
+
+// TextWidth returns the width of s in points when set in font at size.
+func TextWidth(font Font, size float64, s string) float64 {
+	var units int
+	for _, r := range s {
+		if encodeRune(r) == '?' && r != '?' {
+			r = '?'
+		}
+		units += int(runeWidth(font, r))
+	}
+	return float64(units) * size / 1000
+}
+
+// WrapText breaks s into lines no wider than width, at spaces where possible
+// and inside words that are too long on their own. Line breaks in s are kept.
+func WrapText(font Font, size float64, s string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if TextWidth(font, size, candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for TextWidth(font, size, word) > width && utf8.RuneCountInString(word) > 1 {
+				cut := len(word)
+				for cut > 0 && TextWidth(font, size, word[:cut]) > width {
+					_, n := utf8.DecodeLastRuneInString(word[:cut])
+					cut -= n
+				}
+				if cut == 0 {
+					_, cut = utf8.DecodeRuneInString(word)
+				}
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,282 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica
+// faces, filled rectangles, lines and JPEG or PNG images. Coordinates are in
+// points with the origin at the top left corner of the page, and text is
+// positioned by its baseline.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxImagePixels bounds the width times height of an image, so a small
+// file cannot decode into a huge bitmap.
+const MaxImagePixels = 4096 * 4096
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+var (
+	ErrInvalidColor  = errors.New("invalid color")
+	ErrInvalidImage  = errors.New("unsupported image; use JPEG or PNG")
+	ErrImageTooLarge = errors.New("image is too large")
+)
+
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+var fontNames = [...]string{Helvetica: "Helvetica", HelveticaBold: "Helvetica-Bold"}
+
+type Color struct {
+	R, G, B uint8
+}
+
+var (
+	Black = Color{}
+	White = Color{255, 255, 255}
+)
+
+// ParseHexColor parses "#rrggbb" or "#rgb".
+func ParseHexColor(s string) (Color, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return Color{}, ErrInvalidColor
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return Color{}, ErrInvalidColor
+	}
+	return Color{uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// Lighten mixes c with white; amount 0 keeps the color and 1 gives white.
+func (c Color) Lighten(amount float64) Color {
+	mix := func(v uint8) uint8 {
+		return uint8(float64(v) + (255-float64(v))*amount + 0.5)
+	}
+	return Color{mix(c.R), mix(c.G), mix(c.B)}
+}
+
+func (c Color) operands() string {
+	return fmt.Sprintf("%s %s %s", num(float64(c.R)/255), num(float64(c.G)/255), num(float64(c.B)/255))
+}
+
+// Image is a picture that can be drawn on any page of the document it was
+// added to.
+type Image struct {
+	id     int
+	width  int
+	height int
+	filter string
+	data   []byte
+}
+
+// Size returns the image dimensions in pixels.
+func (img *Image) Size() (width, height int) {
+	return img.width, img.height
+}
+
+type Document struct {
+	title  string
+	pages  []*Page
+	images []*Image
+}
+
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+type Page struct {
+	content bytes.Buffer
+	images  []*Image
+}
+
+func (d *Document) AddPage() *Page {
+	page := &Page{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// AddImage decodes a JPEG or PNG image. JPEGs are embedded as they are; other
+// images are flattened onto white, since transparency is not supported.
+// Images of more than MaxImagePixels are refused before they are decoded.
+func (d *Document) AddImage(data []byte) (*Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img := &Image{id: len(d.images), width: config.Width, height: config.Height}
+	switch {
+	case format == "jpeg" && config.ColorModel == color.YCbCrModel:
+		img.filter = "DCTDecode"
+		img.data = data
+	default:
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, ErrInvalidImage
+		}
+		bounds := decoded.Bounds()
+		flat := image.NewRGBA(bounds)
+		draw.Draw(flat, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flat, bounds, decoded, bounds.Min, draw.Over)
+
+		rgb := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+		for i := 0; i < len(flat.Pix); i += 4 {
+			rgb = append(rgb, flat.Pix[i], flat.Pix[i+1], flat.Pix[i+2])
+		}
+		img.filter = "FlateDecode"
+		img.data = deflate(rgb)
+	}
+
+	d.images = append(d.images, img)
+	return img, nil
+}
+
+// Text draws s with its baseline starting at x, y.
+func (p *Page) Text(x, y float64, font Font, size float64, c Color, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s rg %s %s Td (%s) Tj ET\n",
+		font+1, num(size), c.operands(), num(x), num(PageHeight-y), escape(encode(s)))
+}
+
+// TextRight draws s so that it ends at x.
+func (p *Page) TextRight(x, y float64, font Font, size float64, c Color, s string) {
+	p.Text(x-TextWidth(font, size, s), y, font, size, c, s)
+}
+
+// Rect fills a rectangle whose top left corner is at x, y.
+func (p *Page) Rect(x, y, width, height float64, c Color) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n",
+		c.operands(), num(x), num(PageHeight-y-height), num(width), num(height))
+}
+
+func (p *Page) Line(x1, y1, x2, y2, width float64, c Color) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n",
+		c.operands(), num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Image draws img into the box whose top left corner is at x, y.
+func (p *Page) Image(img *Image, x, y, width, height float64) {
+	if !slices.Contains(p.images, img) {
+		p.images = append(p.images, img)
+	}
+	fmt.Fprintf(&p.content, "q %s 0 0 %s %s %s cm /Im%d Do Q\n",
+		num(width), num(height), num(x), num(PageHeight-y-height), img.id)
+}
+
+// WriteTo writes the document as a PDF file.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	// Objects are numbered in the order they are written: the catalog, the
+	// page tree, the info dictionary, the fonts, the images and then each
+	// page followed by its content stream.
+	const firstFont = 4
+	firstImage := firstFont + len(fontNames)
+	firstPage := firstImage + len(d.images)
+
+	object := func(body string, stream []byte) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\n", len(offsets), body)
+		if stream != nil {
+			buf.WriteString("stream\n")
+			buf.Write(stream)
+			buf.WriteString("\nendstream\n")
+		}
+		buf.WriteString("endobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	object("<< /Type /Catalog /Pages 2 0 R >>", nil)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)), nil)
+
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Artemis) >>", escape(encode(d.title))), nil)
+
+	for _, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name), nil)
+	}
+
+	for _, img := range d.images {
+		object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /%s /Length %d >>",
+			img.width, img.height, img.filter, len(img.data)), img.data)
+	}
+
+	var fonts strings.Builder
+	for i := range fontNames {
+		fmt.Fprintf(&fonts, "/F%d %d 0 R ", i+1, firstFont+i)
+	}
+	for i, page := range d.pages {
+		var xobjects strings.Builder
+		for _, img := range page.images {
+			fmt.Fprintf(&xobjects, "/Im%d %d 0 R ", img.id, firstImage+img.id)
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> /XObject << %s>> >> /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), fonts.String(), xobjects.String(), firstPage+2*i+1), nil)
+
+		content := deflate(page.content.Bytes())
+		object(fmt.Sprintf("<< /Filter /FlateDecode /Length %d >>", len(content)), content)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// num formats a coordinate with at most two decimals.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", `\r`, "\n", `\n`)
+	return r.Replace(s)
+}
@@ -0,0 +1,144 @@
+// Package rank generates lexicographic fractional indexes. A rank is a base62
+// string read as a fraction in (0, 1), so a new rank can always be placed
+// between two others without touching any other row. Ranks compare correctly
+// with plain byte ordering (COLLATE "C" in Postgres).
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var (
+	ErrInvalidRank  = errors.New("invalid rank")
+	ErrInvalidOrder = errors.New("lower rank must sort before upper rank")
+)
+
+// Between returns a rank that sorts strictly between a and b. An empty a means
+// no lower bound and an empty b means no upper bound.
+func Between(a, b string) (string, error) {
+	if err := validateBounds(a, b); err != nil {
+		return "", err
+	}
+	return midpoint(a, b), nil
+}
+
+// NBetween returns n ascending ranks between a and b. The ranks are spread so
+// that their length grows with log(n) rather than n.
+func NBetween(a, b string, n int) ([]string, error) {
+	if err := validateBounds(a, b); err != nil {
+		return nil, err
+	}
+	return nBetween(a, b, n), nil
+}
+
+// Spread returns n ascending ranks of equal length spaced evenly over the
+// whole range. It is used to rebalance a list whose ranks have grown long.
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+
+	width, space := 1, int64(len(digits))
+	for space <= int64(n) {
+		width++
+		space *= int64(len(digits))
+	}
+	step := space / int64(n+1)
+
+	ranks := make([]string, n)
+	for i := range ranks {
+		ranks[i] = strings.TrimRight(encode(int64(i+1)*step, width), "0")
+	}
+	return ranks
+}
+
+// Valid reports whether r is a well-formed rank.
+func Valid(r string) bool {
+	if r == "" || r[len(r)-1] == digits[0] {
+		return false
+	}
+	for i := 0; i < len(r); i++ {
+		if strings.IndexByte(digits, r[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func validateBounds(a, b string) error {
+	if (a != "" && !Valid(a)) || (b != "" && !Valid(b)) {
+		return ErrInvalidRank
+	}
+	if a != "" && b != "" && a >= b {
+		return ErrInvalidOrder
+	}
+	return nil
+}
+
+func nBetween(a, b string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	mid := midpoint(a, b)
+	ranks := make([]string, 0, n)
+	ranks = append(ranks, nBetween(a, mid, n/2)...)
+	ranks = append(ranks, mid)
+	return append(ranks, nBetween(mid, b, n-n/2-1)...)
+}
+
+// midpoint expects a < b. Missing trailing digits of a count as zeros, which
+// is why valid ranks never end in the zero digit.
+func midpoint(a, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(suffix(a, n), b[n:])
+		}
+	}
+
+	lo := 0
+	if a != "" {
+		lo = strings.IndexByte(digits, a[0])
+	}
+	hi := len(digits)
+	if b != "" {
+		hi = strings.IndexByte(digits, b[0])
+	}
+
+	if hi-lo > 1 {
+		return string(digits[(lo+hi)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(digits[lo]) + midpoint(suffix(a, 1), "")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
+
+func suffix(s string, i int) string {
+	if i < len(s) {
+		return s[i:]
+	}
+	return ""
+}
+
+func encode(v int64, width int) string {
+	buf := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		buf[i] = digits[v%int64(len(digits))]
+		v /= int64(len(digits))
+	}
+	return string(buf)
+}
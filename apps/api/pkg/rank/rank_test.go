@@ -0,0 +1,188 @@
+package rank
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		err  error
+	}{
+		{name: "unbounded", a: "", b: ""},
+		{name: "no upper bound", a: "V", b: ""},
+		{name: "no lower bound", a: "", b: "V"},
+		{name: "wide gap", a: "1", b: "z"},
+		{name: "adjacent digits", a: "A", b: "B"},
+		{name: "shared prefix", a: "AB", b: "AC"},
+		{name: "prefix of upper", a: "A", b: "A1"},
+		{name: "lowest digit", a: "", b: "1"},
+		{name: "highest digit", a: "z", b: ""},
+		{name: "long lower", a: "Azzzz", b: "B"},
+		{name: "invalid lower", a: "A0", b: "B", err: ErrInvalidRank},
+		{name: "invalid upper", a: "A", b: "B-", err: ErrInvalidRank},
+		{name: "equal bounds", a: "A", b: "A", err: ErrInvalidOrder},
+		{name: "reversed bounds", a: "B", b: "A", err: ErrInvalidOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Between(tt.a, tt.b)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Between(%q, %q) error = %v, want %v", tt.a, tt.b, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Between(%q, %q) error = %v", tt.a, tt.b, err)
+			}
+			if !Valid(got) {
+				t.Fatalf("Between(%q, %q) = %q, not a valid rank", tt.a, tt.b, got)
+			}
+			if tt.a != "" && got <= tt.a {
+				t.Errorf("Between(%q, %q) = %q, want after %q", tt.a, tt.b, got, tt.a)
+			}
+			if tt.b != "" && got >= tt.b {
+				t.Errorf("Between(%q, %q) = %q, want before %q", tt.a, tt.b, got, tt.b)
+			}
+		})
+	}
+}
+
+func TestBetweenRepeatedInsert(t *testing.T) {
+	// Inserting again and again at the same spot is the worst case for
+	// fractional indexes; every result must still sort between its bounds.
+	tests := []struct {
+		name  string
+		first bool
+	}{
+		{name: "always first", first: true},
+		{name: "always last", first: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Between("", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 200; i++ {
+				a, b := r, ""
+				if tt.first {
+					a, b = "", r
+				}
+				next, err := Between(a, b)
+				if err != nil {
+					t.Fatalf("step %d: Between(%q, %q) error = %v", i, a, b, err)
+				}
+				if (tt.first && next >= r) || (!tt.first && next <= r) {
+					t.Fatalf("step %d: Between(%q, %q) = %q out of order", i, a, b, next)
+				}
+				r = next
+			}
+		})
+	}
+}
+
+func TestNBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		n    int
+		err  error
+	}{
+		{name: "none", a: "A", b: "B", n: 0},
+		{name: "one", a: "A", b: "B", n: 1},
+		{name: "unbounded", a: "", b: "", n: 10},
+		{name: "narrow gap", a: "A", b: "A1", n: 25},
+		{name: "many", a: "1", b: "z", n: 1000},
+		{name: "invalid rank", a: "0", b: "", n: 3, err: ErrInvalidRank},
+		{name: "reversed bounds", a: "z", b: "1", n: 3, err: ErrInvalidOrder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NBetween(tt.a, tt.b, tt.n)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("NBetween(%q, %q, %d) error = %v, want %v", tt.a, tt.b, tt.n, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NBetween(%q, %q, %d) error = %v", tt.a, tt.b, tt.n, err)
+			}
+			if len(got) != tt.n {
+				t.Fatalf("NBetween(%q, %q, %d) returned %d ranks", tt.a, tt.b, tt.n, len(got))
+			}
+			prev := tt.a
+			for i, r := range got {
+				if !Valid(r) {
+					t.Fatalf("rank %d = %q, not a valid rank", i, r)
+				}
+				if prev != "" && r <= prev {
+					t.Fatalf("rank %d = %q, want after %q", i, r, prev)
+				}
+				prev = r
+			}
+			if tt.b != "" && len(got) > 0 && got[len(got)-1] >= tt.b {
+				t.Fatalf("last rank %q, want before %q", got[len(got)-1], tt.b)
+			}
+		})
+	}
+}
+
+func TestSpread(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+	}{
+		{name: "none", n: 0},
+		{name: "one", n: 1},
+		{name: "single digit", n: 61},
+		{name: "two digits", n: 62},
+		{name: "many", n: 5000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Spread(tt.n)
+			if len(got) != tt.n {
+				t.Fatalf("Spread(%d) returned %d ranks", tt.n, len(got))
+			}
+			for i, r := range got {
+				if !Valid(r) {
+					t.Fatalf("rank %d = %q, not a valid rank", i, r)
+				}
+				if i > 0 && r <= got[i-1] {
+					t.Fatalf("rank %d = %q, want after %q", i, r, got[i-1])
+				}
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		rank string
+		want bool
+	}{
+		{rank: "", want: false},
+		{rank: "0", want: false},
+		{rank: "A0", want: false},
+		{rank: "A-", want: false},
+		{rank: "a b", want: false},
+		{rank: "1", want: true},
+		{rank: "z", want: true},
+		{rank: "A0B", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.rank); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.rank, got, tt.want)
+		}
+	}
+}
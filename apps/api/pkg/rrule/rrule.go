@@ -0,0 +1,545 @@
+// Package rrule evaluates iCalendar recurrence rules (RFC 5545) at day
+// granularity. Occurrences are calendar dates represented as UTC midnights, so
+// the caller decides which timezone a date is read in. Rule parts below a day
+// (BYHOUR, BYMINUTE, BYSECOND) and the rarely used BYWEEKNO and BYYEARDAY are
+// not supported.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+var (
+	ErrInvalidRule     = errors.New("invalid recurrence rule")
+	ErrUnsupportedRule = errors.New("unsupported recurrence rule")
+)
+
+// maxEmptyPeriods bounds the search for the next occurrence of a rule that
+// can never match again, such as the 30th of February.
+const maxEmptyPeriods = 1000
+
+const untilLayout = "20060102"
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Weekday is a BYDAY entry. N selects the nth such weekday of the month or
+// year, counting from the end when negative; zero means every one.
+type Weekday struct {
+	Day time.Weekday
+	N   int
+}
+
+func (w Weekday) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Day]
+	}
+	return strconv.Itoa(w.N) + weekdayNames[w.Day]
+}
+
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByMonth    []int
+	ByMonthDay []int
+	ByDay      []Weekday
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+// Parse reads a rule such as "FREQ=MONTHLY;BYDAY=1MO". A leading "RRULE:" is
+// accepted.
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s given twice", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+			switch rule.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			case "SECONDLY", "MINUTELY", "HOURLY":
+				return nil, fmt.Errorf("%w: FREQ=%s", ErrUnsupportedRule, value)
+			default:
+				return nil, fmt.Errorf("%w: unknown FREQ %q", ErrInvalidRule, value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseInt(name, value, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseInt(name, value, 1, 10000)
+		case "UNTIL":
+			if len(value) < len(untilLayout) {
+				return nil, fmt.Errorf("%w: malformed UNTIL %q", ErrInvalidRule, value)
+			}
+			until, perr := time.Parse(untilLayout, value[:len(untilLayout)])
+			if perr != nil {
+				return nil, fmt.Errorf("%w: malformed UNTIL %q", ErrInvalidRule, value)
+			}
+			rule.Until = &until
+		case "BYMONTH":
+			rule.ByMonth, err = parseIntList(name, value, 1, 12, false)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(name, value, 1, 31, true)
+		case "BYSETPOS":
+			rule.BySetPos, err = parseIntList(name, value, 1, 366, true)
+		case "BYDAY":
+			rule.ByDay, err = parseWeekdays(value)
+		case "WKST":
+			day, ok := weekdays[value]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown WKST %q", ErrInvalidRule, value)
+			}
+			rule.WeekStart = day
+		case "BYHOUR", "BYMINUTE", "BYSECOND", "BYWEEKNO", "BYYEARDAY":
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedRule, name)
+		default:
+			return nil, fmt.Errorf("%w: unknown part %s", ErrInvalidRule, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rule.validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *Rule) validate() error {
+	if r.Freq == "" {
+		return fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if r.Count > 0 && r.Until != nil {
+		return fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalidRule)
+	}
+	if len(r.BySetPos) > 0 && len(r.ByMonth)+len(r.ByMonthDay)+len(r.ByDay) == 0 {
+		return fmt.Errorf("%w: BYSETPOS needs another BY part", ErrInvalidRule)
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return fmt.Errorf("%w: BYMONTHDAY cannot be used with FREQ=WEEKLY", ErrInvalidRule)
+	}
+	for _, day := range r.ByDay {
+		if day.N == 0 {
+			continue
+		}
+		switch {
+		case r.Freq != Monthly && r.Freq != Yearly:
+			return fmt.Errorf("%w: numbered BYDAY needs FREQ=MONTHLY or FREQ=YEARLY", ErrInvalidRule)
+		case r.Freq == Monthly && (day.N > 5 || day.N < -5):
+			return fmt.Errorf("%w: BYDAY %s is out of range", ErrInvalidRule, day)
+		}
+	}
+	return nil
+}
+
+// String returns the rule in a canonical form.
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format(untilLayout))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Iterator walks the occurrences of a rule in ascending order.
+type Iterator struct {
+	rule    *Rule
+	start   time.Time
+	period  time.Time
+	pending []time.Time
+	emitted int
+	done    bool
+}
+
+// Iterator returns an iterator over the occurrences starting on start. Only
+// the date of start is used.
+func (r *Rule) Iterator(start time.Time) *Iterator {
+	start = Date(start)
+	return &Iterator{rule: r, start: start, period: r.periodStart(start)}
+}
+
+// Next returns the next occurrence, or false once the rule is exhausted.
+func (it *Iterator) Next() (time.Time, bool) {
+	for empty := 0; !it.done; {
+		if len(it.pending) == 0 {
+			if empty >= maxEmptyPeriods {
+				it.done = true
+				break
+			}
+			for _, day := range it.rule.candidates(it.period, it.start) {
+				if !day.Before(it.start) {
+					it.pending = append(it.pending, day)
+				}
+			}
+			it.period = it.rule.nextPeriod(it.period)
+			if len(it.pending) == 0 {
+				empty++
+				continue
+			}
+			empty = 0
+		}
+
+		day := it.pending[0]
+		it.pending = it.pending[1:]
+		if it.rule.Until != nil && day.After(*it.rule.Until) {
+			it.done = true
+			break
+		}
+		it.emitted++
+		if it.rule.Count > 0 && it.emitted >= it.rule.Count {
+			it.done = true
+		}
+		return day, true
+	}
+	return time.Time{}, false
+}
+
+// After returns the first occurrence on or after day, or false when there is
+// none.
+func (r *Rule) After(start, day time.Time) (time.Time, bool) {
+	day = Date(day)
+	it := r.Iterator(start)
+	for {
+		next, ok := it.Next()
+		if !ok || !next.Before(day) {
+			return next, ok
+		}
+	}
+}
+
+// Includes reports whether day is an occurrence of the rule.
+func (r *Rule) Includes(start, day time.Time) bool {
+	next, ok := r.After(start, day)
+	return ok && next.Equal(Date(day))
+}
+
+// Date truncates t to its calendar date as a UTC midnight.
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (r *Rule) periodStart(start time.Time) time.Time {
+	switch r.Freq {
+	case Weekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		return start.AddDate(0, 0, -offset)
+	case Monthly:
+		return time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case Yearly:
+		return time.Date(start.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return start
+	}
+}
+
+func (r *Rule) nextPeriod(period time.Time) time.Time {
+	switch r.Freq {
+	case Weekly:
+		return period.AddDate(0, 0, 7*r.Interval)
+	case Monthly:
+		return period.AddDate(0, r.Interval, 0)
+	case Yearly:
+		return period.AddDate(r.Interval, 0, 0)
+	default:
+		return period.AddDate(0, 0, r.Interval)
+	}
+}
+
+// candidates returns the sorted dates the rule selects within the period
+// beginning on period, before BYSETPOS.
+func (r *Rule) candidates(period, start time.Time) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		if r.matchesMonth(period) && r.matchesMonthDay(period) && r.matchesWeekday(period) {
+			days = []time.Time{period}
+		}
+	case Weekly:
+		for i := 0; i < 7; i++ {
+			day := period.AddDate(0, 0, i)
+			if !r.matchesMonth(day) {
+				continue
+			}
+			if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+				continue
+			}
+			if len(r.ByDay) > 0 && !r.matchesWeekday(day) {
+				continue
+			}
+			days = append(days, day)
+		}
+	case Monthly:
+		if r.matchesMonth(period) {
+			days = r.monthCandidates(period, start)
+		}
+	case Yearly:
+		if len(r.ByDay) > 0 && len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 {
+			days = nthWeekdays(period, period.AddDate(1, 0, 0), r.ByDay)
+			break
+		}
+		months := r.ByMonth
+		if len(months) == 0 {
+			if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+				months = []int{int(start.Month())}
+			} else {
+				months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			}
+		}
+		for _, month := range slices.Sorted(slices.Values(months)) {
+			first := time.Date(period.Year(), time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+			days = append(days, r.monthCandidates(first, start)...)
+		}
+	}
+
+	return r.applySetPos(days)
+}
+
+// monthCandidates returns the sorted dates selected within the month beginning
+// on first. Without BYMONTHDAY or BYDAY the day of the month of start is used,
+// and months too short for it are skipped.
+func (r *Rule) monthCandidates(first, start time.Time) []time.Time {
+	next := first.AddDate(0, 1, 0)
+	length := next.AddDate(0, 0, -1).Day()
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if start.Day() > length {
+			return nil
+		}
+		return []time.Time{first.AddDate(0, 0, start.Day()-1)}
+	}
+
+	selected := make(map[int]bool)
+	if len(r.ByMonthDay) > 0 {
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = length + 1 + day
+			}
+			if day >= 1 && day <= length {
+				selected[day] = true
+			}
+		}
+	}
+	if len(r.ByDay) > 0 {
+		byDay := make(map[int]bool)
+		for _, day := range nthWeekdays(first, next, r.ByDay) {
+			byDay[day.Day()] = true
+		}
+		if len(r.ByMonthDay) > 0 {
+			for day := range selected {
+				if !byDay[day] {
+					delete(selected, day)
+				}
+			}
+		} else {
+			selected = byDay
+		}
+	}
+
+	days := make([]time.Time, 0, len(selected))
+	for _, day := range slices.Sorted(maps.Keys(selected)) {
+		days = append(days, first.AddDate(0, 0, day-1))
+	}
+	return days
+}
+
+// nthWeekdays returns the sorted dates in [from, to) matching the weekdays,
+// where a numbered weekday counts within that range.
+func nthWeekdays(from, to time.Time, weekdays []Weekday) []time.Time {
+	var days []time.Time
+	for _, weekday := range weekdays {
+		var matches []time.Time
+		first := from.AddDate(0, 0, (int(weekday.Day)-int(from.Weekday())+7)%7)
+		for day := first; day.Before(to); day = day.AddDate(0, 0, 7) {
+			matches = append(matches, day)
+		}
+		switch {
+		case weekday.N == 0:
+			days = append(days, matches...)
+		case weekday.N > 0 && weekday.N <= len(matches):
+			days = append(days, matches[weekday.N-1])
+		case weekday.N < 0 && -weekday.N <= len(matches):
+			days = append(days, matches[len(matches)+weekday.N])
+		}
+	}
+	return sortDates(days)
+}
+
+func (r *Rule) applySetPos(days []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+	var selected []time.Time
+	for _, pos := range r.BySetPos {
+		switch {
+		case pos > 0 && pos <= len(days):
+			selected = append(selected, days[pos-1])
+		case pos < 0 && -pos <= len(days):
+			selected = append(selected, days[len(days)+pos])
+		}
+	}
+	return sortDates(selected)
+}
+
+func (r *Rule) matchesMonth(day time.Time) bool {
+	return len(r.ByMonth) == 0 || slices.Contains(r.ByMonth, int(day.Month()))
+}
+
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	length := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay == day.Day() || length+1+monthDay == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if weekday.Day == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// sortDates sorts and removes duplicate dates.
+func sortDates(days []time.Time) []time.Time {
+	slices.SortFunc(days, func(a, b time.Time) int { return a.Compare(b) })
+	return slices.CompactFunc(days, func(a, b time.Time) bool { return a.Equal(b) })
+}
+
+func parseInt(name, value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%w: %s must be between %d and %d", ErrInvalidRule, name, min, max)
+	}
+	return n, nil
+}
+
+// parseIntList parses a comma separated list of values whose magnitude lies in
+// [min, max], negative values being allowed when signed is set.
+func parseIntList(name, value string, min, max int, signed bool) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		magnitude := n
+		if signed && n < 0 {
+			magnitude = -n
+		}
+		if err != nil || magnitude < min || magnitude > max {
+			return nil, fmt.Errorf("%w: invalid %s value %q", ErrInvalidRule, name, item)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func parseWeekdays(value string) ([]Weekday, error) {
+	var days []Weekday
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("%w: invalid BYDAY value %q", ErrInvalidRule, item)
+		}
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid BYDAY value %q", ErrInvalidRule, item)
+		}
+		weekday := Weekday{Day: day}
+		if prefix := item[:len(item)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, fmt.Errorf("%w: invalid BYDAY value %q", ErrInvalidRule, item)
+			}
+			weekday.N = n
+		}
+		days = append(days, weekday)
+	}
+	return days, nil
+}
+
+func joinInts(values []int) string {
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = strconv.Itoa(value)
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,374 @@
+package rrule
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func date(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// TestIteratorRFC5545 runs the examples of RFC 5545 section 3.8.5.3 that do
+// not rely on times of day or unsupported rule parts. Unbounded rules are cut
+// after the occurrences the RFC lists.
+func TestIteratorRFC5545(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		want  []string
+	}{
+		{
+			name:  "daily for 10 occurrences",
+			rule:  "FREQ=DAILY;COUNT=10",
+			start: "1997-09-02",
+			want: []string{
+				"1997-09-02", "1997-09-03", "1997-09-04", "1997-09-05", "1997-09-06",
+				"1997-09-07", "1997-09-08", "1997-09-09", "1997-09-10", "1997-09-11",
+			},
+		},
+		{
+			name:  "every other day",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: "1997-09-02",
+			want:  []string{"1997-09-02", "1997-09-04", "1997-09-06", "1997-09-08", "1997-09-10"},
+		},
+		{
+			name:  "every 10 days, 5 occurrences",
+			rule:  "FREQ=DAILY;INTERVAL=10;COUNT=5",
+			start: "1997-09-02",
+			want:  []string{"1997-09-02", "1997-09-12", "1997-09-22", "1997-10-02", "1997-10-12"},
+		},
+		{
+			name:  "every day in January",
+			rule:  "RRULE:FREQ=DAILY;UNTIL=20000131T140000Z;BYMONTH=1",
+			start: "1998-01-30",
+			want:  []string{"1998-01-30", "1998-01-31", "1999-01-01", "1999-01-02"},
+		},
+		{
+			name:  "weekly for 10 occurrences",
+			rule:  "FREQ=WEEKLY;COUNT=10",
+			start: "1997-09-02",
+			want: []string{
+				"1997-09-02", "1997-09-09", "1997-09-16", "1997-09-23", "1997-09-30",
+				"1997-10-07", "1997-10-14", "1997-10-21", "1997-10-28", "1997-11-04",
+			},
+		},
+		{
+			name:  "weekly on Tuesday and Thursday for five weeks",
+			rule:  "FREQ=WEEKLY;UNTIL=19971007T000000Z;WKST=SU;BYDAY=TU,TH",
+			start: "1997-09-02",
+			want: []string{
+				"1997-09-02", "1997-09-04", "1997-09-09", "1997-09-11", "1997-09-16",
+				"1997-09-18", "1997-09-23", "1997-09-25", "1997-09-30", "1997-10-02",
+			},
+		},
+		{
+			name:  "every other week on Monday, Wednesday and Friday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;UNTIL=19971224T000000Z;WKST=SU;BYDAY=MO,WE,FR",
+			start: "1997-09-01",
+			want: []string{
+				"1997-09-01", "1997-09-03", "1997-09-05", "1997-09-15", "1997-09-17",
+				"1997-09-19", "1997-09-29", "1997-10-01", "1997-10-03", "1997-10-13",
+				"1997-10-15", "1997-10-17", "1997-10-27", "1997-10-29", "1997-10-31",
+				"1997-11-10", "1997-11-12", "1997-11-14", "1997-11-24", "1997-11-26",
+				"1997-11-28", "1997-12-08", "1997-12-10", "1997-12-12", "1997-12-22",
+			},
+		},
+		{
+			name:  "every other week on Tuesday and Thursday for 8 occurrences",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;COUNT=8;WKST=SU;BYDAY=TU,TH",
+			start: "1997-09-02",
+			want: []string{
+				"1997-09-02", "1997-09-04", "1997-09-16", "1997-09-18",
+				"1997-09-30", "1997-10-02", "1997-10-14", "1997-10-16",
+			},
+		},
+		{
+			name:  "monthly on the first Friday for 10 occurrences",
+			rule:  "FREQ=MONTHLY;COUNT=10;BYDAY=1FR",
+			start: "1997-09-05",
+			want: []string{
+				"1997-09-05", "1997-10-03", "1997-11-07", "1997-12-05", "1998-01-02",
+				"1998-02-06", "1998-03-06", "1998-04-03", "1998-05-01", "1998-06-05",
+			},
+		},
+		{
+			name:  "every other month on the first and last Sunday",
+			rule:  "FREQ=MONTHLY;INTERVAL=2;COUNT=10;BYDAY=1SU,-1SU",
+			start: "1997-09-07",
+			want: []string{
+				"1997-09-07", "1997-09-28", "1997-11-02", "1997-11-30", "1998-01-04",
+				"1998-01-25", "1998-03-01", "1998-03-29", "1998-05-03", "1998-05-31",
+			},
+		},
+		{
+			name:  "monthly on the second-to-last Monday for 6 months",
+			rule:  "FREQ=MONTHLY;COUNT=6;BYDAY=-2MO",
+			start: "1997-09-22",
+			want: []string{
+				"1997-09-22", "1997-10-20", "1997-11-17",
+				"1997-12-22", "1998-01-19", "1998-02-16",
+			},
+		},
+		{
+			name:  "monthly on the third-to-last day",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-3",
+			start: "1997-09-28",
+			want:  []string{"1997-09-28", "1997-10-29", "1997-11-28", "1997-12-29", "1998-01-29", "1998-02-26"},
+		},
+		{
+			name:  "monthly on the 2nd and 15th for 10 occurrences",
+			rule:  "FREQ=MONTHLY;COUNT=10;BYMONTHDAY=2,15",
+			start: "1997-09-02",
+			want: []string{
+				"1997-09-02", "1997-09-15", "1997-10-02", "1997-10-15", "1997-11-02",
+				"1997-11-15", "1997-12-02", "1997-12-15", "1998-01-02", "1998-01-15",
+			},
+		},
+		{
+			name:  "monthly on the first and last day for 10 occurrences",
+			rule:  "FREQ=MONTHLY;COUNT=10;BYMONTHDAY=1,-1",
+			start: "1997-09-30",
+			want: []string{
+				"1997-09-30", "1997-10-01", "1997-10-31", "1997-11-01", "1997-11-30",
+				"1997-12-01", "1997-12-31", "1998-01-01", "1998-01-31", "1998-02-01",
+			},
+		},
+		{
+			name:  "every 18 months on the 10th to 15th for 10 occurrences",
+			rule:  "FREQ=MONTHLY;INTERVAL=18;COUNT=10;BYMONTHDAY=10,11,12,13,14,15",
+			start: "1997-09-10",
+			want: []string{
+				"1997-09-10", "1997-09-11", "1997-09-12", "1997-09-13", "1997-09-14",
+				"1997-09-15", "1999-03-10", "1999-03-11", "1999-03-12", "1999-03-13",
+			},
+		},
+		{
+			name:  "every Tuesday, every other month",
+			rule:  "FREQ=MONTHLY;INTERVAL=2;BYDAY=TU",
+			start: "1997-09-02",
+			want: []string{
+				"1997-09-02", "1997-09-09", "1997-09-16", "1997-09-23", "1997-09-30",
+				"1997-11-04", "1997-11-11", "1997-11-18", "1997-11-25", "1998-01-06",
+			},
+		},
+		{
+			name:  "yearly in June and July for 10 occurrences",
+			rule:  "FREQ=YEARLY;COUNT=10;BYMONTH=6,7",
+			start: "1997-06-10",
+			want: []string{
+				"1997-06-10", "1997-07-10", "1998-06-10", "1998-07-10", "1999-06-10",
+				"1999-07-10", "2000-06-10", "2000-07-10", "2001-06-10", "2001-07-10",
+			},
+		},
+		{
+			name:  "every third year in January, February and March for 10 occurrences",
+			rule:  "FREQ=YEARLY;INTERVAL=3;COUNT=10;BYMONTH=1,2,3",
+			start: "1997-03-10",
+			want: []string{
+				"1997-03-10", "2000-01-10", "2000-02-10", "2000-03-10", "2003-01-10",
+				"2003-02-10", "2003-03-10", "2006-01-10", "2006-02-10", "2006-03-10",
+			},
+		},
+		{
+			name:  "20th Monday of the year",
+			rule:  "FREQ=YEARLY;BYDAY=20MO",
+			start: "1997-05-19",
+			want:  []string{"1997-05-19", "1998-05-18", "1999-05-17"},
+		},
+		{
+			name:  "every Thursday in March",
+			rule:  "FREQ=YEARLY;BYMONTH=3;BYDAY=TH",
+			start: "1997-03-13",
+			want: []string{
+				"1997-03-13", "1997-03-20", "1997-03-27", "1998-03-05",
+				"1998-03-12", "1998-03-19", "1998-03-26",
+			},
+		},
+		{
+			name:  "every Friday the 13th",
+			rule:  "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			start: "1997-09-02",
+			want:  []string{"1998-02-13", "1998-03-13", "1998-11-13", "1999-08-13", "2000-10-13"},
+		},
+		{
+			name:  "first Saturday that follows the first Sunday",
+			rule:  "FREQ=MONTHLY;BYDAY=SA;BYMONTHDAY=7,8,9,10,11,12,13",
+			start: "1997-09-13",
+			want: []string{
+				"1997-09-13", "1997-10-11", "1997-11-08", "1997-12-13", "1998-01-10",
+				"1998-02-07", "1998-03-07", "1998-04-11", "1998-05-09", "1998-06-13",
+			},
+		},
+		{
+			name:  "US presidential election day",
+			rule:  "FREQ=YEARLY;INTERVAL=4;BYMONTH=11;BYDAY=TU;BYMONTHDAY=2,3,4,5,6,7,8",
+			start: "1996-11-05",
+			want:  []string{"1996-11-05", "2000-11-07", "2004-11-02"},
+		},
+		{
+			name:  "third Tuesday, Wednesday or Thursday for the next 3 months",
+			rule:  "FREQ=MONTHLY;COUNT=3;BYDAY=TU,WE,TH;BYSETPOS=3",
+			start: "1997-09-04",
+			want:  []string{"1997-09-04", "1997-10-07", "1997-11-06"},
+		},
+		{
+			name:  "second-to-last weekday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-2",
+			start: "1997-09-29",
+			want: []string{
+				"1997-09-29", "1997-10-30", "1997-11-27", "1997-12-30",
+				"1998-01-29", "1998-02-26", "1998-03-30",
+			},
+		},
+		{
+			name:  "invalid dates are skipped",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=15,30;COUNT=5",
+			start: "2007-01-15",
+			want:  []string{"2007-01-15", "2007-01-30", "2007-02-15", "2007-03-15", "2007-03-30"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			it := rule.Iterator(date(t, tt.start))
+			var got []string
+			for len(got) < len(tt.want) {
+				day, ok := it.Next()
+				if !ok {
+					break
+				}
+				got = append(got, day.Format(time.DateOnly))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("occurrences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIteratorExhausted(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		want  int
+	}{
+		{name: "count", rule: "FREQ=WEEKLY;COUNT=3", start: "2026-01-05", want: 3},
+		{name: "until is inclusive", rule: "FREQ=DAILY;UNTIL=20260110", start: "2026-01-05", want: 6},
+		{name: "until before start", rule: "FREQ=DAILY;UNTIL=20260101", start: "2026-01-05", want: 0},
+		{name: "never matches", rule: "FREQ=MONTHLY;BYMONTH=2;BYMONTHDAY=30", start: "2026-01-01", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			it := rule.Iterator(date(t, tt.start))
+			got := 0
+			for _, ok := it.Next(); ok; _, ok = it.Next() {
+				got++
+			}
+			if got != tt.want {
+				t.Errorf("%d occurrences, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAfterAndIncludes(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		start    string
+		day      string
+		want     string
+		includes bool
+	}{
+		{name: "on an occurrence", rule: "FREQ=MONTHLY;BYDAY=1FR", start: "1997-09-05", day: "1997-11-07", want: "1997-11-07", includes: true},
+		{name: "between occurrences", rule: "FREQ=MONTHLY;BYDAY=1FR", start: "1997-09-05", day: "1997-11-08", want: "1997-12-05"},
+		{name: "before start", rule: "FREQ=WEEKLY", start: "2026-03-04", day: "2026-01-01", want: "2026-03-04"},
+		{name: "past the last occurrence", rule: "FREQ=DAILY;COUNT=2", start: "2026-03-04", day: "2026-03-06"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			start, day := date(t, tt.start), date(t, tt.day)
+			next, ok := rule.After(start, day)
+			switch {
+			case tt.want == "" && ok:
+				t.Errorf("After = %s, want none", next.Format(time.DateOnly))
+			case tt.want != "" && (!ok || next.Format(time.DateOnly) != tt.want):
+				t.Errorf("After = %s, %v, want %s", next.Format(time.DateOnly), ok, tt.want)
+			}
+			if got := rule.Includes(start, day); got != tt.includes {
+				t.Errorf("Includes = %v, want %v", got, tt.includes)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+		err  error
+	}{
+		{rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{rule: "rrule:freq=weekly;interval=1;wkst=mo", want: "FREQ=WEEKLY"},
+		{rule: "FREQ=MONTHLY;BYDAY=1MO,-1FR;COUNT=4", want: "FREQ=MONTHLY;COUNT=4;BYDAY=1MO,-1FR"},
+		{rule: "FREQ=YEARLY;UNTIL=20301231T235959Z;BYMONTH=6", want: "FREQ=YEARLY;UNTIL=20301231;BYMONTH=6"},
+		{rule: "FREQ=WEEKLY;WKST=SU;BYDAY=TU,TH", want: "FREQ=WEEKLY;BYDAY=TU,TH;WKST=SU"},
+		{rule: "", err: ErrInvalidRule},
+		{rule: "INTERVAL=2", err: ErrInvalidRule},
+		{rule: "FREQ=DAILY;FREQ=WEEKLY", err: ErrInvalidRule},
+		{rule: "FREQ=FORTNIGHTLY", err: ErrInvalidRule},
+		{rule: "FREQ=DAILY;INTERVAL=0", err: ErrInvalidRule},
+		{rule: "FREQ=DAILY;COUNT=2;UNTIL=20300101", err: ErrInvalidRule},
+		{rule: "FREQ=WEEKLY;BYMONTHDAY=1", err: ErrInvalidRule},
+		{rule: "FREQ=WEEKLY;BYDAY=1MO", err: ErrInvalidRule},
+		{rule: "FREQ=MONTHLY;BYDAY=6MO", err: ErrInvalidRule},
+		{rule: "FREQ=MONTHLY;BYSETPOS=1", err: ErrInvalidRule},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", err: ErrInvalidRule},
+		{rule: "FREQ=HOURLY", err: ErrUnsupportedRule},
+		{rule: "FREQ=DAILY;BYHOUR=9", err: ErrUnsupportedRule},
+		{rule: "FREQ=YEARLY;BYWEEKNO=20", err: ErrUnsupportedRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Parse(%q) error = %v, want %v", tt.rule, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
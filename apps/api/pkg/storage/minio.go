@@ -125,6 +125,14 @@ func (m *MinIO) Delete(ctx context.Context, bucketType BucketType, objectName st
 	return m.client.RemoveObject(ctx, m.bucket(bucketType), objectName, minio.RemoveObjectOptions{})
 }
 
+func (m *MinIO) Download(ctx context.Context, bucketType BucketType, objectName string) (io.ReadCloser, error) {
+	object, err := m.client.GetObject(ctx, m.bucket(bucketType), objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
 func (m *MinIO) UploadAvatar(ctx context.Context, workspaceID string, reader io.Reader, size int64, contentType string) (string, error) {
 	objectName := fmt.Sprintf("avatars/%s", workspaceID)
 	_, err := m.Upload(ctx, BucketPublic, objectName, reader, size, contentType)
@@ -11,6 +11,7 @@ type Provider interface {
 	GetPublicURL(objectName string) string
 	GetPresignedURL(ctx context.Context, objectName string, expiry time.Duration) (string, error)
 	Delete(ctx context.Context, bucketType BucketType, objectName string) error
+	Download(ctx context.Context, bucketType BucketType, objectName string) (io.ReadCloser, error)
 	UploadAvatar(ctx context.Context, userID string, reader io.Reader, size int64, contentType string) (string, error)
 	DeleteAvatar(ctx context.Context, workspaceID string) error
 	UploadProjectFile(ctx context.Context, projectID, fileName string, reader io.Reader, size int64, contentType string) (string, error)
@@ -40,11 +40,44 @@ func main() {
 	subjects := []string{
 		"artemis.user.registered",
 		"artemis.user.logged_in",
+		"artemis.user.email_verification_sent",
+		"artemis.user.email_verified",
 		"artemis.workspace.created",
 		"artemis.workspace.updated",
 		"artemis.workspace.deleted",
+		"artemis.workspace.settings_updated",
 		"artemis.member.added",
 		"artemis.member.removed",
+		"artemis.member.left",
+		"artemis.member.deactivated",
+		"artemis.member.reactivated",
+		"artemis.member.content_reassigned",
+		"artemis.project.created",
+		"artemis.project.updated",
+		"artemis.project.status_changed",
+		"artemis.project.deleted",
+		"artemis.project.budget_threshold",
+		"artemis.task.assigned",
+		"artemis.task.status_changed",
+		"artemis.comment.mentioned",
+		"artemis.timesheet.submitted",
+		"artemis.timesheet.approved",
+		"artemis.timesheet.rejected",
+		"artemis.timesheet.reminder_due",
+		"artemis.invoice.sent",
+		"artemis.invoice.paid",
+		"artemis.invoice.cancelled",
+		"artemis.payment.recorded",
+		"artemis.payment.refunded",
+		"artemis.invoice.reminder_due",
+		"artemis.invoice.viewed",
+		"artemis.invoice.recurring_created",
+		"artemis.expense.submitted",
+		"artemis.expense.approved",
+		"artemis.expense.rejected",
+		"artemis.expense.reimbursed",
+		"artemis.expense.receipt_uploaded",
+		"artemis.expense.receipt_extracted",
 		"artemis.email.send_requested",
 	}
 
@@ -93,16 +126,88 @@ func handleEvent(log zerolog.Logger, msg *nats.Msg) {
 		logger.Info().Interface("payload", event.Payload).Msg("user registered - would send welcome email")
 	case "user.logged_in":
 		logger.Info().Interface("payload", event.Payload).Msg("user logged in")
+	case "user.email_verification_sent":
+		// The payload carries the verification token, which is not logged.
+		logger.Info().Msg("email verification requested - would email the verification link")
+	case "user.email_verified":
+		logger.Info().Interface("payload", event.Payload).Msg("email verified")
 	case "workspace.created":
 		logger.Info().Interface("payload", event.Payload).Msg("workspace created")
 	case "workspace.updated":
 		logger.Info().Interface("payload", event.Payload).Msg("workspace updated")
 	case "workspace.deleted":
 		logger.Info().Interface("payload", event.Payload).Msg("workspace deleted")
+	case "workspace.settings_updated":
+		logger.Info().Interface("payload", event.Payload).Msg("workspace settings updated")
 	case "member.added":
 		logger.Info().Interface("payload", event.Payload).Msg("member added - would send invitation email")
 	case "member.removed":
 		logger.Info().Interface("payload", event.Payload).Msg("member removed")
+	case "member.left":
+		logger.Info().Interface("payload", event.Payload).Msg("member left - would notify workspace admins")
+	case "member.deactivated":
+		logger.Info().Interface("payload", event.Payload).Msg("member deactivated - would send deactivation email")
+	case "member.reactivated":
+		logger.Info().Interface("payload", event.Payload).Msg("member reactivated - would send welcome back email")
+	case "member.content_reassigned":
+		logger.Info().Interface("payload", event.Payload).Msg("member content reassigned - would send handover email to new assignee")
+	case "project.created":
+		logger.Info().Interface("payload", event.Payload).Msg("project created")
+	case "project.updated":
+		logger.Info().Interface("payload", event.Payload).Msg("project updated")
+	case "project.status_changed":
+		logger.Info().Interface("payload", event.Payload).Msg("project status changed - would notify project owner")
+	case "project.deleted":
+		logger.Info().Interface("payload", event.Payload).Msg("project deleted")
+	case "project.budget_threshold":
+		logger.Info().Interface("payload", event.Payload).Msg("project budget threshold reached - would warn project owner")
+	case "task.assigned":
+		logger.Info().Interface("payload", event.Payload).Msg("task assigned - would notify assignee")
+	case "task.status_changed":
+		logger.Info().Interface("payload", event.Payload).Msg("task status changed - would notify assignee")
+	case "comment.mentioned":
+		logger.Info().Interface("payload", event.Payload).Msg("comment mentioned - would notify mentioned member")
+	case "timesheet.submitted":
+		logger.Info().Interface("payload", event.Payload).Msg("timesheet submitted - would notify timesheet approvers")
+	case "timesheet.approved":
+		logger.Info().Interface("payload", event.Payload).Msg("timesheet approved - would send approval email")
+	case "timesheet.rejected":
+		logger.Info().Interface("payload", event.Payload).Msg("timesheet rejected - would send rejection email with reason")
+	case "timesheet.reminder_due":
+		logger.Info().Interface("payload", event.Payload).Msg("timesheet reminder due - would send submission reminder email")
+	case "invoice.sent":
+		logger.Info().Interface("payload", event.Payload).Msg("invoice sent - would email the invoice to the client")
+	case "invoice.paid":
+		logger.Info().Interface("payload", event.Payload).Msg("invoice paid - would send paid in full confirmation")
+	case "invoice.cancelled":
+		logger.Info().Interface("payload", event.Payload).Msg("invoice cancelled - would notify the client")
+	case "payment.recorded":
+		logger.Info().Interface("payload", event.Payload).Msg("payment recorded - would send payment receipt and refresh the client balance")
+	case "payment.refunded":
+		logger.Info().Interface("payload", event.Payload).Msg("payment refunded - would send refund confirmation and refresh the client balance")
+	case "invoice.reminder_due":
+		email, err := renderInvoiceReminder(event.Payload)
+		if err != nil {
+			logger.Warn().Err(err).Interface("payload", event.Payload).Msg("invoice reminder due - not emailed")
+			return
+		}
+		logger.Info().Str("to", email.To).Str("email_subject", email.Subject).Str("body", email.Body).Msg("invoice reminder due - would email the client")
+	case "invoice.viewed":
+		logger.Info().Interface("payload", event.Payload).Msg("invoice viewed - would notify the invoice sender")
+	case "invoice.recurring_created":
+		logger.Info().Interface("payload", event.Payload).Msg("recurring invoice draft created - would notify the workspace admins")
+	case "expense.submitted":
+		logger.Info().Interface("payload", event.Payload).Msg("expense submitted - would notify the workspace admins")
+	case "expense.approved":
+		logger.Info().Interface("payload", event.Payload).Msg("expense approved - would notify the submitter")
+	case "expense.rejected":
+		logger.Info().Interface("payload", event.Payload).Msg("expense rejected - would notify the submitter with the reason")
+	case "expense.reimbursed":
+		logger.Info().Interface("payload", event.Payload).Msg("expense reimbursed - would notify the submitter")
+	case "expense.receipt_uploaded":
+		logger.Info().Interface("payload", event.Payload).Msg("expense receipt uploaded - extraction runs in the api")
+	case "expense.receipt_extracted":
+		logger.Info().Interface("payload", event.Payload).Msg("expense receipt read - would notify the submitter that suggestions are ready")
 	case "email.send_requested":
 		logger.Info().Interface("payload", event.Payload).Msg("email send requested")
 	default:
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Email is a rendered notification email.
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// invoiceReminder is the payload of an invoice.reminder_due event.
+type invoiceReminder struct {
+	WorkspaceName string  `json:"workspace_name"`
+	ClientName    string  `json:"client_name"`
+	ClientEmail   *string `json:"client_email"`
+	Number        string  `json:"number"`
+	AmountDue     string  `json:"amount_due"`
+	DueOn         string  `json:"due_on"`
+	DaysFromDue   int     `json:"days_from_due"`
+}
+
+// DaysOverdue is how many days past the due date the reminder is sent.
+func (r invoiceReminder) DaysOverdue() int {
+	return r.DaysFromDue
+}
+
+// DaysLeft is how many days before the due date the reminder is sent.
+func (r invoiceReminder) DaysLeft() int {
+	return -r.DaysFromDue
+}
+
+type emailTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func newEmailTemplate(name, subject, body string) emailTemplate {
+	return emailTemplate{
+		subject: template.Must(template.New(name + ".subject").Parse(subject)),
+		body:    template.Must(template.New(name + ".body").Parse(body)),
+	}
+}
+
+func (t emailTemplate) render(data any) (string, string, error) {
+	var subject, body strings.Builder
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
+
+var (
+	invoiceUpcomingTemplate = newEmailTemplate("invoice_upcoming",
+		`Invoice {{.Number}} from {{.WorkspaceName}} is due on {{.DueOn}}`,
+		`Hello {{.ClientName}},
+
+This is a friendly reminder that invoice {{.Number}} for {{.AmountDue}} is due in {{.DaysLeft}} day{{if ne .DaysLeft 1}}s{{end}}, on {{.DueOn}}.
+
+If you have already arranged payment, please disregard this message.
+
+Kind regards,
+{{.WorkspaceName}}
+`)
+
+	invoiceDueTemplate = newEmailTemplate("invoice_due",
+		`Invoice {{.Number}} from {{.WorkspaceName}} is due today`,
+		`Hello {{.ClientName}},
+
+Invoice {{.Number}} for {{.AmountDue}} is due today, {{.DueOn}}.
+
+If you have already arranged payment, please disregard this message.
+
+Kind regards,
+{{.WorkspaceName}}
+`)
+
+	invoiceOverdueTemplate = newEmailTemplate("invoice_overdue",
+		`Invoice {{.Number}} from {{.WorkspaceName}} is overdue`,
+		`Hello {{.ClientName}},
+
+Invoice {{.Number}} was due on {{.DueOn}} and is now {{.DaysOverdue}} day{{if ne .DaysOverdue 1}}s{{end}} overdue. The outstanding balance is {{.AmountDue}}.
+
+Please arrange payment at your earliest convenience, or let us know if there is a problem with the invoice.
+
+Kind regards,
+{{.WorkspaceName}}
+`)
+)
+
+// renderInvoiceReminder picks the reminder email for where the invoice stands
+// relative to its due date.
+func renderInvoiceReminder(payload any) (*Email, error) {
+	var reminder invoiceReminder
+	if err := decodePayload(payload, &reminder); err != nil {
+		return nil, err
+	}
+	if reminder.ClientEmail == nil || *reminder.ClientEmail == "" {
+		return nil, fmt.Errorf("client %q has no email address", reminder.ClientName)
+	}
+
+	tmpl := invoiceDueTemplate
+	switch {
+	case reminder.DaysFromDue < 0:
+		tmpl = invoiceUpcomingTemplate
+	case reminder.DaysFromDue > 0:
+		tmpl = invoiceOverdueTemplate
+	}
+
+	subject, body, err := tmpl.render(reminder)
+	if err != nil {
+		return nil, err
+	}
+	return &Email{To: *reminder.ClientEmail, Subject: subject, Body: body}, nil
+}
+
+// decodePayload converts an event payload, decoded as generic JSON, into v.
+func decodePayload(payload any, v any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}